@@ -12,11 +12,27 @@ import * as common from '@grafana/schema';
 
 export const pluginVersion = "%VERSION%";
 
-export type PyroscopeQueryType = ('metrics' | 'profile' | 'both');
+export type PyroscopeQueryType = ('metrics' | 'profile' | 'diff' | 'both');
 
 export const defaultPyroscopeQueryType: PyroscopeQueryType = 'both';
 
 export interface GrafanaPyroscopeDataQuery extends common.DataQuery {
+  /**
+   * Start of the baseline time range in diff queries, in milliseconds since epoch. Takes precedence over baselineTimeShift.
+   */
+  baselineFrom?: number;
+  /**
+   * Specifies the label selectors of the baseline profile in diff queries. Defaults to labelSelector.
+   */
+  baselineLabelSelector?: string;
+  /**
+   * Shifts the time range of the baseline profile in diff queries back by the given duration, e.g. 1d.
+   */
+  baselineTimeShift?: string;
+  /**
+   * End of the baseline time range in diff queries, in milliseconds since epoch. Takes precedence over baselineTimeShift.
+   */
+  baselineTo?: number;
   /**
    * Allows to group the results.
    */
@@ -19,6 +19,8 @@ export enum QueryEditorMode {
 
 export enum LokiQueryType {
   Instant = 'instant',
+  LogVolume = 'logVolume',
+  Patterns = 'patterns',
   Range = 'range',
   Stream = 'stream',
 }
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /admin/notifications/outbox admin adminSearchNotificationOutbox
+//
+// Search the emails and webhooks queued for delivery.
+//
+// Delivered messages are kept without their content until the retention configured in the notification_outbox section expires.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminSearchNotificationOutboxResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminSearchNotificationOutbox(c *contextmodel.ReqContext) response.Response {
+	status := c.Query("status")
+	if status != "" && status != notifications.OutboxStatusPending && status != notifications.OutboxStatusSent && status != notifications.OutboxStatusFailed {
+		return response.Error(http.StatusBadRequest, "Invalid status, must be one of pending, sent or failed", nil)
+	}
+
+	result, err := hs.notificationOutbox.SearchOutboxMessages(c.Req.Context(), &notifications.SearchOutboxMessagesQuery{
+		Status:    status,
+		Kind:      c.Query("kind"),
+		Recipient: c.Query("recipient"),
+		Page:      c.QueryInt("page"),
+		Limit:     c.QueryInt("perpage"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search notification outbox", err)
+	}
+
+	dto := NotificationOutboxSearchResultDTO{
+		TotalCount: result.TotalCount,
+		Messages:   make([]NotificationOutboxMessageDTO, 0, len(result.Messages)),
+		Page:       result.Page,
+		PerPage:    result.PerPage,
+	}
+	for _, msg := range result.Messages {
+		dto.Messages = append(dto.Messages, NotificationOutboxMessageDTO{
+			ID:          msg.ID,
+			Kind:        msg.Kind,
+			Recipient:   msg.Recipient,
+			Subject:     msg.Subject,
+			Status:      msg.Status,
+			Attempts:    msg.Attempts,
+			LastError:   msg.LastError,
+			NextAttempt: time.Unix(msg.NextAttempt, 0),
+			Created:     time.Unix(msg.Created, 0),
+			Updated:     time.Unix(msg.Updated, 0),
+		})
+	}
+
+	return response.JSON(http.StatusOK, dto)
+}
+
+// swagger:route GET /admin/notifications/outbox/recipients admin adminGetNotificationOutboxRecipients
+//
+// Get the delivery statistics of the notification outbox by recipient, the recipients with the most failed messages first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminGetNotificationOutboxRecipientsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetNotificationOutboxRecipients(c *contextmodel.ReqContext) response.Response {
+	stats, err := hs.notificationOutbox.GetOutboxRecipientStats(c.Req.Context(), &notifications.GetOutboxRecipientStatsQuery{
+		Recipient: c.Query("recipient"),
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get notification outbox statistics", err)
+	}
+	return response.JSON(http.StatusOK, stats)
+}
+
+// swagger:route POST /admin/notifications/outbox/resend admin adminResendNotificationOutboxMessages
+//
+// Queue failed messages for delivery again.
+//
+// The failed messages with the given ids are queued again, or all of them, of the recipient if set, when no id is given.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminResendNotificationOutboxResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResendNotificationOutboxMessages(c *contextmodel.ReqContext) response.Response {
+	cmd := notifications.ResendOutboxMessagesCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return hs.resendNotificationOutboxMessages(c, &cmd)
+}
+
+// swagger:route POST /admin/notifications/outbox/{id}/resend admin adminResendNotificationOutboxMessage
+//
+// Queue a failed message for delivery again.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminResendNotificationOutboxResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResendNotificationOutboxMessage(c *contextmodel.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	return hs.resendNotificationOutboxMessages(c, &notifications.ResendOutboxMessagesCommand{IDs: []int64{id}})
+}
+
+func (hs *HTTPServer) resendNotificationOutboxMessages(c *contextmodel.ReqContext, cmd *notifications.ResendOutboxMessagesCommand) response.Response {
+	count, err := hs.notificationOutbox.ResendOutboxMessages(c.Req.Context(), cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to resend notification outbox messages", err)
+	}
+	if count == 0 && len(cmd.IDs) == 1 {
+		return response.Error(http.StatusNotFound, "Failed message not found", notifications.ErrOutboxMessageNotFound)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Messages queued for delivery",
+		"count":   count,
+	})
+}
+
+// NotificationOutboxMessageDTO is an email or a webhook queued for delivery to a recipient.
+type NotificationOutboxMessageDTO struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	Recipient   string    `json:"recipient"`
+	Subject     string    `json:"subject,omitempty"`
+	Status      string    `json:"status"`
+	Attempts    int64     `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type NotificationOutboxSearchResultDTO struct {
+	TotalCount int64                          `json:"totalCount"`
+	Messages   []NotificationOutboxMessageDTO `json:"messages"`
+	Page       int                            `json:"page"`
+	PerPage    int                            `json:"perPage"`
+}
+
+// swagger:parameters adminSearchNotificationOutbox
+type AdminSearchNotificationOutboxParams struct {
+	// in:query
+	// required:false
+	// Enum: pending,sent,failed
+	Status string `json:"status"`
+	// in:query
+	// required:false
+	// Enum: email,webhook
+	Kind string `json:"kind"`
+	// Only the messages of the recipients containing the given text are returned
+	// in:query
+	// required:false
+	Recipient string `json:"recipient"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:parameters adminGetNotificationOutboxRecipients
+type AdminGetNotificationOutboxRecipientsParams struct {
+	// in:query
+	// required:false
+	Recipient string `json:"recipient"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters adminResendNotificationOutboxMessages
+type AdminResendNotificationOutboxMessagesParams struct {
+	// in:body
+	// required:true
+	Body notifications.ResendOutboxMessagesCommand `json:"body"`
+}
+
+// swagger:parameters adminResendNotificationOutboxMessage
+type AdminResendNotificationOutboxMessageParams struct {
+	// in:path
+	// required:true
+	ID int64 `json:"id"`
+}
+
+// swagger:response adminSearchNotificationOutboxResponse
+type AdminSearchNotificationOutboxResponse struct {
+	// in: body
+	Body NotificationOutboxSearchResultDTO `json:"body"`
+}
+
+// swagger:response adminGetNotificationOutboxRecipientsResponse
+type AdminGetNotificationOutboxRecipientsResponse struct {
+	// in: body
+	Body []*notifications.OutboxRecipientStats `json:"body"`
+}
+
+// swagger:response adminResendNotificationOutboxResponse
+type AdminResendNotificationOutboxResponse struct {
+	// in: body
+	Body struct {
+		Message string `json:"message"`
+		Count   int64  `json:"count"`
+	} `json:"body"`
+}
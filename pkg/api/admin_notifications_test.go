@@ -0,0 +1,98 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_AdminNotificationOutbox(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	outbox := notifications.MockNotificationService()
+	outbox.OutboxMessages = []*notifications.OutboxMessage{{
+		ID:          1,
+		Kind:        notifications.OutboxKindEmail,
+		Recipient:   "invited@example.com",
+		Subject:     "You're invited",
+		Payload:     `{"To": ["invited@example.com"]}`,
+		Status:      notifications.OutboxStatusFailed,
+		Attempts:    10,
+		LastError:   "dial tcp: connection refused",
+		NextAttempt: created.Unix(),
+		Created:     created.Unix(),
+		Updated:     created.Unix(),
+	}}
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.notificationOutbox = outbox
+	})
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1, IsGrafanaAdmin: true}
+
+	send := func(t *testing.T, req *http.Request, signedInUser *user.SignedInUser) (int, string) {
+		t.Helper()
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, signedInUser))
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(body)
+	}
+
+	t.Run("should fail for users who are not server admins", func(t *testing.T) {
+		code, _ := send(t, server.NewGetRequest("/api/admin/notifications/outbox"), userWithPermissions(1, nil))
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("should list the messages without their content", func(t *testing.T) {
+		code, body := send(t, server.NewGetRequest("/api/admin/notifications/outbox?status=failed&perpage=10"), admin)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{
+			"totalCount": 1,
+			"page": 0,
+			"perPage": 10,
+			"messages": [{
+				"id": 1,
+				"kind": "email",
+				"recipient": "invited@example.com",
+				"subject": "You're invited",
+				"status": "failed",
+				"attempts": 10,
+				"lastError": "dial tcp: connection refused",
+				"nextAttempt": "`+time.Unix(created.Unix(), 0).Format(time.RFC3339)+`",
+				"created": "`+time.Unix(created.Unix(), 0).Format(time.RFC3339)+`",
+				"updated": "`+time.Unix(created.Unix(), 0).Format(time.RFC3339)+`"
+			}]
+		}`, body)
+	})
+
+	t.Run("should fail for an unknown status", func(t *testing.T) {
+		code, _ := send(t, server.NewGetRequest("/api/admin/notifications/outbox?status=bounced"), admin)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("should resend the failed messages of a recipient", func(t *testing.T) {
+		outbox.OutboxResendCount = 2
+		req := server.NewPostRequest("/api/admin/notifications/outbox/resend", strings.NewReader(`{"recipient": "invited@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		code, body := send(t, req, admin)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"message": "Messages queued for delivery", "count": 2}`, body)
+		assert.Equal(t, notifications.ResendOutboxMessagesCommand{Recipient: "invited@example.com"}, outbox.OutboxResend)
+	})
+
+	t.Run("should return not found when the message to resend didn't fail", func(t *testing.T) {
+		outbox.OutboxResendCount = 0
+		code, _ := send(t, server.NewPostRequest("/api/admin/notifications/outbox/3/resend", nil), admin)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, []int64{3}, outbox.OutboxResend.IDs)
+	})
+}
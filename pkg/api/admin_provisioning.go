@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 )
 
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
@@ -32,6 +33,24 @@ func (hs *HTTPServer) AdminProvisioningReloadDashboards(c *contextmodel.ReqConte
 	return response.Success("Dashboards config reloaded")
 }
 
+// swagger:route GET /admin/provisioning/dashboards/status admin_provisioning adminProvisioningDashboardsStatus
+//
+// Get the status of the dashboard providers.
+//
+// Returns the result of the last sync of every dashboard provider, including the revision checked out by git providers and the errors of the dashboard files which could not be provisioned.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:dashboards`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningDashboardsStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminProvisioningDashboardsStatus(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.ProvisioningService.GetDashboardProvisionersStatus())
+}
+
 // swagger:route POST /admin/provisioning/datasources/reload admin_provisioning adminProvisioningReloadDatasources
 //
 // Reload datasource provisioning configurations.
@@ -85,3 +104,9 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:response adminProvisioningDashboardsStatusResponse
+type AdminProvisioningDashboardsStatusResponse struct {
+	// in: body
+	Body []dashboards.ProvisionerStatus `json:"body"`
+}
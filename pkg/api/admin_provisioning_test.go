@@ -5,12 +5,14 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -165,3 +167,45 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminProvisioningDashboardsStatus(t *testing.T) {
+	pService := provisioning.NewProvisioningServiceMock(context.Background())
+	pService.GetDashboardProvisionersStatusFunc = func() []dashboards.ProvisionerStatus {
+		return []dashboards.ProvisionerStatus{{
+			Name:       "git",
+			Type:       "git",
+			Revision:   "6d3c1f2",
+			LastSync:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			FileErrors: map[string]string{"team-a/broken.json": "failed to load dashboard: invalid character"},
+		}}
+	}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.ProvisioningService = pService
+	})
+
+	t.Run("should fail without permission", func(t *testing.T) {
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/dashboards/status"), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should return the status of the providers", func(t *testing.T) {
+		permissions := []accesscontrol.Permission{{Action: ActionProvisioningReload, Scope: ScopeProvisionersDashboards}}
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/dashboards/status"), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.JSONEq(t, `[{
+			"name": "git",
+			"type": "git",
+			"revision": "6d3c1f2",
+			"lastSync": "2024-01-01T00:00:00Z",
+			"fileErrors": {"team-a/broken.json": "failed to load dashboard: invalid character"}
+		}]`, string(body))
+	})
+}
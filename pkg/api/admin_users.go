@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
@@ -66,6 +67,8 @@ func (hs *HTTPServer) AdminCreateUser(c *contextmodel.ReqContext) response.Respo
 	}
 
 	metrics.MApiAdminUserCreate.Inc()
+	auditlog.RecordResourceID(c.Req.Context(), strconv.FormatInt(usr.ID, 10))
+	hs.recordUserState(c.Req.Context(), usr.ID, auditlog.RecordAfter)
 
 	result := user.AdminCreateUserResponse{
 		Message: "User created",
@@ -111,9 +114,11 @@ func (hs *HTTPServer) AdminUpdateUserPassword(c *contextmodel.ReqContext) respon
 		return response
 	}
 
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordBefore)
 	if err := hs.userService.Update(c.Req.Context(), &user.UpdateUserCommand{UserID: userID, Password: &form.Password}); err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update user password", err)
 	}
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordAfter)
 
 	usr, err := hs.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID})
 	if err != nil {
@@ -163,6 +168,7 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *contextmodel.ReqContext) res
 		}
 	}
 
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordBefore)
 	err = hs.userService.Update(c.Req.Context(), &user.UpdateUserCommand{
 		UserID:         userID,
 		IsGrafanaAdmin: &form.IsGrafanaAdmin,
@@ -174,6 +180,7 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *contextmodel.ReqContext) res
 
 		return response.Error(http.StatusInternalServerError, "Failed to update user permissions", err)
 	}
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordAfter)
 
 	return response.Success("User permissions updated")
 }
@@ -201,6 +208,7 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 
 	cmd := user.DeleteUserCommand{UserID: userID}
 
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordBefore)
 	if err := hs.userService.Delete(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
@@ -292,12 +300,14 @@ func (hs *HTTPServer) AdminDisableUser(c *contextmodel.ReqContext) response.Resp
 	}
 
 	isDisabled := true
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordBefore)
 	if err := hs.userService.Update(c.Req.Context(), &user.UpdateUserCommand{UserID: userID, IsDisabled: &isDisabled}); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to disable user", err)
 	}
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordAfter)
 
 	err = hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), userID)
 	if err != nil {
@@ -335,12 +345,14 @@ func (hs *HTTPServer) AdminEnableUser(c *contextmodel.ReqContext) response.Respo
 	}
 
 	isDisabled := false
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordBefore)
 	if err := hs.userService.Update(c.Req.Context(), &user.UpdateUserCommand{UserID: userID, IsDisabled: &isDisabled}); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to enable user", err)
 	}
+	hs.recordUserState(c.Req.Context(), userID, auditlog.RecordAfter)
 
 	return response.Success("User enabled")
 }
@@ -35,6 +35,28 @@ const defaultAnnotationsLimit = 100
 // 401: unauthorisedError
 // 500: internalServerError
 func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Response {
+	query, resp := hs.newAnnotationsQuery(c, defaultAnnotationsLimit)
+	if resp != nil {
+		return resp
+	}
+
+	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get annotations", err)
+	}
+
+	for _, item := range items {
+		if item.Email != "" {
+			item.AvatarURL = dtos.GetGravatarUrl(hs.Cfg, item.Email)
+		}
+	}
+	hs.setAnnotationsDashboardUID(c, items)
+
+	return response.JSON(http.StatusOK, items)
+}
+
+// newAnnotationsQuery returns the query of the annotations matching the filters of the request.
+func (hs *HTTPServer) newAnnotationsQuery(c *contextmodel.ReqContext, defaultLimit int64) (*annotations.ItemQuery, response.Response) {
 	query := &annotations.ItemQuery{
 		From:         c.QueryInt64("from"),
 		To:           c.QueryInt64("to"),
@@ -51,7 +73,7 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		SignedInUser: c.SignedInUser,
 	}
 	if query.Limit == 0 {
-		query.Limit = defaultAnnotationsLimit
+		query.Limit = defaultLimit
 	}
 
 	// When dashboard UID present in the request, we ignore dashboard ID
@@ -59,24 +81,19 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		dq := dashboards.GetDashboardQuery{UID: query.DashboardUID, OrgID: c.SignedInUser.GetOrgID()}
 		dqResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dq)
 		if err != nil {
-			return response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
+			return nil, response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
 		} else {
 			query.DashboardID = dqResult.ID
 		}
 	}
 
-	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to get annotations", err)
-	}
+	return query, nil
+}
 
+func (hs *HTTPServer) setAnnotationsDashboardUID(c *contextmodel.ReqContext, items []*annotations.ItemDTO) {
 	// since there are several annotations per dashboard, we can cache dashboard uid
 	dashboardCache := make(map[int64]*string)
 	for _, item := range items {
-		if item.Email != "" {
-			item.AvatarURL = dtos.GetGravatarUrl(hs.Cfg, item.Email)
-		}
-
 		if item.DashboardID != 0 {
 			if val, ok := dashboardCache[item.DashboardID]; ok {
 				item.DashboardUID = val
@@ -90,8 +107,6 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 			}
 		}
 	}
-
-	return response.JSON(http.StatusOK, items)
 }
 
 type AnnotationError struct {
@@ -0,0 +1,449 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultAnnotationsExportLimit = 10000
+	annotationsMergeLimit         = 10000
+	// annotationImportMaxLineSize is the maximum size of an annotation in an import
+	annotationImportMaxLineSize = 1024 * 1024
+	// annotationImportMaxErrors is the maximum number of errors reported for an import
+	annotationImportMaxErrors = 100
+	// annotationImportKeyMaxLength is the length of the import_key column
+	annotationImportKeyMaxLength = 190
+)
+
+// swagger:route POST /annotations/import annotations importAnnotations
+//
+// Import annotations.
+//
+// Creates the annotations of the request body, one JSON object per line in the format of the export.
+// The annotations with a key are only created once: importing an annotation with the key of an annotation imported before in the organization skips it.
+// The lines which fail don't stop the import, they are reported in the response.
+//
+// Consumes:
+// - application/x-ndjson
+//
+// Responses:
+// 200: importAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) ImportAnnotations(c *contextmodel.ReqContext) response.Response {
+	result := dtos.ImportAnnotationsResult{Errors: make([]dtos.ImportAnnotationsError, 0)}
+	fail := func(line int, key string, err error) {
+		result.Failed++
+		if len(result.Errors) < annotationImportMaxErrors {
+			result.Errors = append(result.Errors, dtos.ImportAnnotationsError{Line: line, Key: key, Message: err.Error()})
+		}
+	}
+
+	dashboardIDs := make(map[string]int64)
+	scanner := bufio.NewScanner(c.Req.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), annotationImportMaxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var line dtos.AnnotationExportLine
+		if err := json.Unmarshal(text, &line); err != nil {
+			fail(lineNumber, "", fmt.Errorf("invalid annotation: %w", err))
+			continue
+		}
+
+		saved, err := hs.importAnnotation(c, &line, dashboardIDs)
+		switch {
+		case err != nil:
+			fail(lineNumber, line.Key, err)
+		case saved:
+			result.Imported++
+		default:
+			result.Skipped++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Failed to read annotations after line %d", lineNumber), err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+func (hs *HTTPServer) importAnnotation(c *contextmodel.ReqContext, line *dtos.AnnotationExportLine, dashboardIDs map[string]int64) (bool, error) {
+	if len(line.Key) > annotationImportKeyMaxLength {
+		return false, fmt.Errorf("key is longer than %d characters", annotationImportKeyMaxLength)
+	}
+	if line.Text == "" {
+		return false, &AnnotationError{"text field should not be empty"}
+	}
+
+	var dashboardID int64
+	if line.DashboardUID != "" {
+		id, ok := dashboardIDs[line.DashboardUID]
+		if !ok {
+			query := dashboards.GetDashboardQuery{OrgID: c.SignedInUser.GetOrgID(), UID: line.DashboardUID}
+			queryResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &query)
+			if err != nil {
+				return false, fmt.Errorf("dashboard %s: %w", line.DashboardUID, err)
+			}
+			id = queryResult.ID
+			dashboardIDs[line.DashboardUID] = id
+		}
+		dashboardID = id
+	}
+
+	if canSave, err := hs.canCreateAnnotation(c, dashboardID); err != nil {
+		return false, err
+	} else if !canSave {
+		return false, errors.New("access denied to save the annotation")
+	}
+
+	userID, _ := identity.UserIdentifier(c.GetID())
+	item := annotations.Item{
+		OrgID:       c.SignedInUser.GetOrgID(),
+		UserID:      userID,
+		DashboardID: dashboardID,
+		PanelID:     line.PanelId,
+		Epoch:       line.Time,
+		EpochEnd:    line.TimeEnd,
+		Text:        line.Text,
+		Data:        line.Data,
+		Tags:        line.Tags,
+	}
+	return hs.annotationsRepo.Import(c.Req.Context(), &item, line.Key)
+}
+
+// swagger:route GET /annotations/export annotations exportAnnotations
+//
+// Export annotations.
+//
+// Exports the annotations matching the filters, which are the ones of the annotations search, as NDJSON or CSV.
+// The NDJSON export can be imported again.
+//
+// Produces:
+// - application/x-ndjson
+// - text/csv
+//
+// Responses:
+// 200: exportAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) ExportAnnotations(c *contextmodel.ReqContext) response.Response {
+	format := c.Query("format")
+	if format == "" {
+		format = "ndjson"
+	}
+	if format != "ndjson" && format != "csv" {
+		return response.Error(http.StatusBadRequest, "Invalid format, must be ndjson or csv", nil)
+	}
+
+	query, resp := hs.newAnnotationsQuery(c, defaultAnnotationsExportLimit)
+	if resp != nil {
+		return resp
+	}
+
+	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get annotations", err)
+	}
+	hs.setAnnotationsDashboardUID(c, items)
+
+	var buf bytes.Buffer
+	contentType := "application/x-ndjson"
+	if format == "csv" {
+		contentType = "text/csv"
+		err = writeAnnotationsCSV(&buf, items)
+	} else {
+		err = writeAnnotationsNDJSON(&buf, items)
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to export annotations", err)
+	}
+
+	return response.Respond(http.StatusOK, buf.Bytes()).
+		SetHeader("Content-Type", contentType).
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename="annotations.%s"`, format))
+}
+
+func annotationExportLine(item *annotations.ItemDTO) dtos.AnnotationExportLine {
+	line := dtos.AnnotationExportLine{
+		ID:      item.ID,
+		PanelId: item.PanelID,
+		AlertId: item.AlertID,
+		Time:    item.Time,
+		TimeEnd: item.TimeEnd,
+		Text:    item.Text,
+		Tags:    item.Tags,
+		Data:    item.Data,
+		Login:   item.Login,
+		Created: item.Created,
+		Updated: item.Updated,
+	}
+	if item.DashboardUID != nil {
+		line.DashboardUID = *item.DashboardUID
+	}
+	if line.Tags == nil {
+		line.Tags = []string{}
+	}
+	return line
+}
+
+func writeAnnotationsNDJSON(buf *bytes.Buffer, items []*annotations.ItemDTO) error {
+	enc := json.NewEncoder(buf)
+	for _, item := range items {
+		if err := enc.Encode(annotationExportLine(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAnnotationsCSV(buf *bytes.Buffer, items []*annotations.ItemDTO) error {
+	w := csv.NewWriter(buf)
+	if err := w.Write([]string{"id", "dashboardUID", "panelId", "alertId", "time", "timeEnd", "text", "tags", "login", "created", "updated"}); err != nil {
+		return err
+	}
+	for _, item := range items {
+		line := annotationExportLine(item)
+		if err := w.Write([]string{
+			strconv.FormatInt(line.ID, 10),
+			line.DashboardUID,
+			strconv.FormatInt(line.PanelId, 10),
+			strconv.FormatInt(line.AlertId, 10),
+			strconv.FormatInt(line.Time, 10),
+			strconv.FormatInt(line.TimeEnd, 10),
+			line.Text,
+			strings.Join(line.Tags, ","),
+			line.Login,
+			strconv.FormatInt(line.Created, 10),
+			strconv.FormatInt(line.Updated, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// swagger:route POST /annotations/merge annotations mergeAnnotations
+//
+// Merge overlapping region annotations.
+//
+// Collapses the region annotations of the same dashboard panel with the same tags which overlap into the earliest one, which is extended to cover all of them and gets their texts.
+// The other ones are deleted. Alert annotations are left as they are.
+//
+// Responses:
+// 200: mergeAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) MergeAnnotations(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.MergeAnnotationsCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query := &annotations.ItemQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		From:         cmd.From,
+		To:           cmd.To,
+		PanelID:      cmd.PanelId,
+		Tags:         cmd.Tags,
+		MatchAny:     cmd.MatchAny,
+		Type:         "annotation",
+		Limit:        annotationsMergeLimit,
+		SignedInUser: c.SignedInUser,
+	}
+	if cmd.DashboardUID != "" {
+		dq := dashboards.GetDashboardQuery{UID: cmd.DashboardUID, OrgID: c.SignedInUser.GetOrgID()}
+		dqResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dq)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
+		}
+		query.DashboardID = dqResult.ID
+		query.DashboardUID = cmd.DashboardUID
+	}
+
+	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get annotations", err)
+	}
+
+	merges := annotations.MergeRegions(items)
+	result := dtos.MergeAnnotationsResult{
+		Message: "Annotations merged",
+		Regions: make([]dtos.MergedAnnotationRegion, 0, len(merges)),
+	}
+	checked := make(map[int64]bool)
+	for _, merge := range merges {
+		if !checked[merge.Region.DashboardID] {
+			canMerge, err := hs.canMergeAnnotations(c, merge.Region.DashboardID)
+			if err != nil {
+				return response.Error(http.StatusInternalServerError, "Error while checking annotation permissions", err)
+			}
+			if !canMerge {
+				return response.Error(http.StatusForbidden, "Access denied to merge the annotations", nil)
+			}
+			checked[merge.Region.DashboardID] = true
+		}
+
+		region := dtos.MergedAnnotationRegion{
+			ID:        merge.Region.ID,
+			Time:      merge.Region.Time,
+			TimeEnd:   merge.Region.TimeEnd,
+			Text:      merge.Region.Text,
+			MergedIDs: make([]int64, 0, len(merge.Merged)),
+		}
+		for _, merged := range merge.Merged {
+			region.MergedIDs = append(region.MergedIDs, merged.ID)
+		}
+		result.Regions = append(result.Regions, region)
+	}
+
+	if cmd.DryRun {
+		result.Message = "Annotations to merge"
+		return response.JSON(http.StatusOK, result)
+	}
+
+	for _, merge := range merges {
+		// the region is extended before the merged annotations are deleted, so that none is lost if it fails
+		if err := hs.annotationsRepo.Update(c.Req.Context(), &annotations.Item{
+			OrgID:    c.SignedInUser.GetOrgID(),
+			ID:       merge.Region.ID,
+			Epoch:    merge.Region.Time,
+			EpochEnd: merge.Region.TimeEnd,
+			Text:     merge.Region.Text,
+			Tags:     merge.Region.Tags,
+			Data:     merge.Region.Data,
+		}); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update annotation", err)
+		}
+		for _, merged := range merge.Merged {
+			if err := hs.annotationsRepo.Delete(c.Req.Context(), &annotations.DeleteParams{
+				OrgID: c.SignedInUser.GetOrgID(),
+				ID:    merged.ID,
+			}); err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to delete annotation", err)
+			}
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// canMergeAnnotations checks that the user can update and delete the annotations of the dashboard,
+// or the organization annotations if the dashboard ID is 0.
+func (hs *HTTPServer) canMergeAnnotations(c *contextmodel.ReqContext, dashboardID int64) (bool, error) {
+	scope := accesscontrol.ScopeAnnotationsTypeOrganization
+	if dashboardID != 0 {
+		scope = accesscontrol.ScopeAnnotationsTypeDashboard
+		if hs.Features.IsEnabled(c.Req.Context(), featuremgmt.FlagAnnotationPermissionUpdate) {
+			scope = dashboards.ScopeDashboardsProvider.GetResourceScope(strconv.FormatInt(dashboardID, 10))
+		}
+	}
+
+	evaluator := accesscontrol.EvalPermission(accesscontrol.ActionAnnotationsWrite, scope)
+	if canWrite, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); err != nil || !canWrite {
+		return false, err
+	}
+	return hs.canMassDeleteAnnotations(c, dashboardID)
+}
+
+// swagger:parameters importAnnotations
+type ImportAnnotationsParams struct {
+	// The annotations, one JSON object per line
+	// in:body
+	// required:true
+	Body []dtos.AnnotationExportLine `json:"body"`
+}
+
+// swagger:parameters exportAnnotations
+type ExportAnnotationsParams struct {
+	// in:query
+	// required:false
+	// default:ndjson
+	// enum: ndjson,csv
+	Format string `json:"format"`
+	// Export annotations created after specific epoch datetime in milliseconds.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Export annotations created before specific epoch datetime in milliseconds.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// in:query
+	// required:false
+	DashboardUID string `json:"dashboardUID"`
+	// in:query
+	// required:false
+	PanelID int64 `json:"panelId"`
+	// Max number of exported annotations.
+	// in:query
+	// required:false
+	// default:10000
+	Limit int64 `json:"limit"`
+	// in:query
+	// required:false
+	// type: array
+	// collectionFormat: multi
+	Tags []string `json:"tags"`
+	// in:query
+	// required:false
+	// enum: alert,annotation
+	Type string `json:"type"`
+	// in:query
+	// required:false
+	MatchAny bool `json:"matchAny"`
+}
+
+// swagger:parameters mergeAnnotations
+type MergeAnnotationsParams struct {
+	// in:body
+	// required:true
+	Body dtos.MergeAnnotationsCmd `json:"body"`
+}
+
+// swagger:response importAnnotationsResponse
+type ImportAnnotationsResponse struct {
+	// in: body
+	Body dtos.ImportAnnotationsResult `json:"body"`
+}
+
+// swagger:response exportAnnotationsResponse
+type ExportAnnotationsResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
+
+// swagger:response mergeAnnotationsResponse
+type MergeAnnotationsResponse struct {
+	// in: body
+	Body dtos.MergeAnnotationsResult `json:"body"`
+}
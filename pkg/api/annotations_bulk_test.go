@@ -0,0 +1,144 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_AnnotationsBulk(t *testing.T) {
+	setupServer := func(t *testing.T, repo annotations.Repository) *webtest.Server {
+		t.Helper()
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.annotationsRepo = repo
+			hs.Features = featuremgmt.WithFeatures()
+			hs.AccessControl = acimpl.ProvideAccessControl(featuremgmt.WithFeatures())
+		})
+	}
+
+	send := func(t *testing.T, server *webtest.Server, req *http.Request, signedInUser *user.SignedInUser) (*http.Response, string) {
+		t.Helper()
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, signedInUser))
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res, string(body)
+	}
+
+	orgAnnotations := func(actions ...string) *user.SignedInUser {
+		permissions := make([]accesscontrol.Permission, 0, len(actions))
+		for _, action := range actions {
+			permissions = append(permissions, accesscontrol.Permission{Action: action, Scope: accesscontrol.ScopeAnnotationsTypeOrganization})
+		}
+		return authedUserWithPermissions(1, 1, permissions)
+	}
+
+	t.Run("should import annotations once per key and report the failed lines", func(t *testing.T) {
+		repo := annotationstest.NewFakeAnnotationsRepo()
+		server := setupServer(t, repo)
+
+		body := strings.Join([]string{
+			`{"key": "incident-1", "time": 1000, "timeEnd": 2000, "text": "Outage", "tags": ["incident"]}`,
+			``,
+			`{"key": "incident-1", "time": 1000, "timeEnd": 2000, "text": "Outage", "tags": ["incident"]}`,
+			`{"key": "incident-2", "time": 3000}`,
+			`not json`,
+			`{"time": 4000, "text": "No key"}`,
+		}, "\n")
+		req := server.NewPostRequest("/api/annotations/import", strings.NewReader(body))
+		res, resBody := send(t, server, req, orgAnnotations(accesscontrol.ActionAnnotationsCreate))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{
+			"imported": 2,
+			"skipped": 1,
+			"failed": 2,
+			"errors": [
+				{"line": 4, "key": "incident-2", "message": "text field should not be empty"},
+				{"line": 5, "message": "invalid annotation: invalid character 'o' in literal null (expecting 'u')"}
+			]
+		}`, resBody)
+		assert.Equal(t, 2, repo.Len())
+	})
+
+	t.Run("should not import annotations without permission", func(t *testing.T) {
+		repo := annotationstest.NewFakeAnnotationsRepo()
+		server := setupServer(t, repo)
+
+		req := server.NewPostRequest("/api/annotations/import", strings.NewReader(`{"time": 1000, "text": "Outage"}`))
+		res, _ := send(t, server, req, orgAnnotations(accesscontrol.ActionAnnotationsRead))
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Zero(t, repo.Len())
+	})
+
+	t.Run("should export annotations", func(t *testing.T) {
+		repo := annotations.NewFakeAnnotationsRepo(t)
+		repo.On("Find", mock.Anything, mock.MatchedBy(func(query *annotations.ItemQuery) bool {
+			return query.Limit == defaultAnnotationsExportLimit && query.Tags[0] == "incident"
+		})).Return([]*annotations.ItemDTO{
+			{ID: 1, Time: 1000, TimeEnd: 2000, Text: "Outage, again", Tags: []string{"incident", "sev:1"}, Login: "admin", Created: 5000, Updated: 5000},
+		}, nil)
+		server := setupServer(t, repo)
+		signedInUser := orgAnnotations(accesscontrol.ActionAnnotationsRead)
+
+		res, body := send(t, server, server.NewGetRequest("/api/annotations/export?tags=incident"), signedInUser)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+		assert.Equal(t, `{"id":1,"time":1000,"timeEnd":2000,"text":"Outage, again","tags":["incident","sev:1"],"login":"admin","created":5000,"updated":5000}`+"\n", body)
+
+		res, body = send(t, server, server.NewGetRequest("/api/annotations/export?tags=incident&format=csv"), signedInUser)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+		assert.Equal(t, "id,dashboardUID,panelId,alertId,time,timeEnd,text,tags,login,created,updated\n"+
+			`1,,0,0,1000,2000,"Outage, again","incident,sev:1",admin,5000,5000`+"\n", body)
+
+		res, _ = send(t, server, server.NewGetRequest("/api/annotations/export?format=xml"), signedInUser)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should merge the overlapping regions", func(t *testing.T) {
+		repo := annotations.NewFakeAnnotationsRepo(t)
+		repo.On("Find", mock.Anything, mock.MatchedBy(func(query *annotations.ItemQuery) bool {
+			return query.Type == "annotation" && query.Tags[0] == "deploy"
+		})).Return([]*annotations.ItemDTO{
+			{ID: 1, Time: 10, TimeEnd: 20, Text: "deploy api", Tags: []string{"deploy"}},
+			{ID: 2, Time: 15, TimeEnd: 30, Text: "deploy web", Tags: []string{"deploy"}},
+			{ID: 3, Time: 40, TimeEnd: 50, Text: "deploy api", Tags: []string{"deploy"}},
+		}, nil)
+		server := setupServer(t, repo)
+		merge := func(body string, signedInUser *user.SignedInUser) (*http.Response, string) {
+			req := server.NewPostRequest("/api/annotations/merge", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			return send(t, server, req, signedInUser)
+		}
+		expected := `{"id": 1, "time": 10, "timeEnd": 30, "text": "deploy api\ndeploy web", "mergedIds": [2]}`
+
+		res, _ := merge(`{"tags": ["deploy"]}`, orgAnnotations(accesscontrol.ActionAnnotationsWrite))
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		signedInUser := orgAnnotations(accesscontrol.ActionAnnotationsWrite, accesscontrol.ActionAnnotationsDelete)
+		res, body := merge(`{"tags": ["deploy"], "dryRun": true}`, signedInUser)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"message": "Annotations to merge", "regions": [`+expected+`]}`, body)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+		repo.On("Update", mock.Anything, &annotations.Item{OrgID: 1, ID: 1, Epoch: 10, EpochEnd: 30, Text: "deploy api\ndeploy web", Tags: []string{"deploy"}}).Return(nil).Once()
+		repo.On("Delete", mock.Anything, &annotations.DeleteParams{OrgID: 1, ID: 2}).Return(nil).Once()
+		res, body = merge(`{"tags": ["deploy"]}`, signedInUser)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"message": "Annotations merged", "regions": [`+expected+`]}`, body)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -86,6 +87,12 @@ func (hs *HTTPServer) DeleteAPIKey(c *contextmodel.ReqContext) response.Response
 		return response.Error(http.StatusBadRequest, "id is invalid", err)
 	}
 
+	if auditlog.Recording(c.Req.Context()) {
+		if key, err := hs.apiKeyService.GetApiKeyById(c.Req.Context(), &apikey.GetByIDQuery{ApiKeyID: id}); err == nil && key.OrgID == c.SignedInUser.GetOrgID() {
+			auditlog.RecordBefore(c.Req.Context(), &dtos.ApiKeyDTO{ID: key.ID, Name: key.Name, Role: key.Role, LastUsedAt: key.LastUsedAt})
+		}
+	}
+
 	cmd := &apikey.DeleteCommand{ID: id, OrgID: c.SignedInUser.GetOrgID()}
 	err = hs.apiKeyService.DeleteApiKey(c.Req.Context(), cmd)
 	if err != nil {
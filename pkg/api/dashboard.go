@@ -30,7 +30,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	pref "github.com/grafana/grafana/pkg/services/preference"
+	provisioningdashboards "github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -243,6 +245,15 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 		}
 	}
 
+	// apply the defaults inherited from the folders the dashboard does not set itself
+	if meta.FolderUid != "" {
+		defaults, err := hs.folderDefaultsService.Resolve(ctx, c.SignedInUser.GetOrgID(), meta.FolderUid)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to resolve the folder defaults", err)
+		}
+		defaults.ApplyOnLoad(dash.Data)
+	}
+
 	// make sure db version is in sync with json model version
 	dash.Data.Set("version", dash.Version)
 
@@ -564,7 +575,6 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	ctx = c.Req.Context()
-	var err error
 
 	var userID int64
 	if id, err := identity.UserIdentifier(c.SignedInUser.GetID()); err == nil {
@@ -577,12 +587,16 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	dash := cmd.GetDashboardModel()
 	newDashboard := dash.ID == 0
 	if newDashboard {
-		limitReached, err := hs.QuotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
+		limitReached, err := hs.QuotaService.CheckQuotaReached(ctx, dashboards.QuotaTargetSrv, &quota.ScopeParameters{
+			OrgID:     cmd.OrgID,
+			UserID:    userID,
+			FolderUID: cmd.FolderUID,
+		})
 		if err != nil {
 			return response.Error(http.StatusInternalServerError, "Failed to get quota", err)
 		}
 		if limitReached {
-			return response.Error(http.StatusForbidden, "Quota reached", nil)
+			return response.Err(quota.NewErrQuotaReached(dashboards.QuotaTarget))
 		}
 	}
 
@@ -607,25 +621,35 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	dashItem := &dashboards.SaveDashboardDTO{
-		Dashboard: dash,
-		Message:   cmd.Message,
-		OrgID:     c.SignedInUser.GetOrgID(),
-		User:      c.SignedInUser,
-		Overwrite: cmd.Overwrite,
+		Dashboard:    dash,
+		Message:      cmd.Message,
+		OrgID:        c.SignedInUser.GetOrgID(),
+		User:         c.SignedInUser,
+		Overwrite:    cmd.Overwrite,
+		RestoredFrom: cmd.RestoredFrom,
 	}
 
 	dashboard, saveErr := hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
+	return hs.dashboardSaved(c, dash, dashboard, saveErr, cmd.Message, newDashboard, provisioningData)
+}
+
+// dashboardSaved broadcasts the save of a dashboard, connects its library panels and writes it back
+// to its provisioning source, then responds with the saved dashboard or the error of the save.
+// requested is the dashboard as sent by the user and is broadcast when the save failed.
+func (hs *HTTPServer) dashboardSaved(c *contextmodel.ReqContext, requested, dashboard *dashboards.Dashboard, saveErr error,
+	message string, newDashboard bool, provisioningData *dashboards.DashboardProvisioning) response.Response {
+	ctx := c.Req.Context()
 
 	if hs.Live != nil {
 		// Tell everyone listening that the dashboard changed
 		if dashboard == nil {
-			dashboard = dash // the original request
+			dashboard = requested // the original request
 		}
 
 		// This will broadcast all save requests only if a `gitops` observer exists.
 		// gitops is useful when trying to save dashboards in an environment where the user can not save
 		channel := hs.Live.GrafanaScope.Dashboards
-		liveerr := channel.DashboardSaved(c.SignedInUser.GetOrgID(), c.SignedInUser, cmd.Message, dashboard, saveErr)
+		liveerr := channel.DashboardSaved(c.SignedInUser.GetOrgID(), c.SignedInUser, message, dashboard, saveErr)
 
 		// When an error exists, but the value broadcast to a gitops listener return 202
 		if liveerr == nil && saveErr != nil && channel.HasGitOpsObserver(c.SignedInUser.GetOrgID()) {
@@ -651,11 +675,37 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	// connect library panels for this dashboard after the dashboard is stored and has an ID
-	err = hs.LibraryPanelService.ConnectLibraryPanelsForDashboard(ctx, c.SignedInUser, dashboard)
+	err := hs.LibraryPanelService.ConnectLibraryPanelsForDashboard(ctx, c.SignedInUser, dashboard)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
+	// write the change back to the source of provisioned dashboards, providers without write back ignore it.
+	// Writing back pushes to a remote repository, so it runs in the background and failures are only logged.
+	if provisioningData != nil {
+		if message == "" {
+			message = fmt.Sprintf("Update dashboard %s", dashboard.Title)
+		}
+		writeBackCmd := &provisioningdashboards.WriteBackDashboardCommand{
+			Name:       provisioningData.Name,
+			ExternalID: provisioningData.ExternalID,
+			Dashboard:  dashboard.Data,
+			Author:     c.SignedInUser.GetLogin(),
+			Message:    message,
+		}
+		go func(ctx context.Context, uid string) {
+			defer func() {
+				if err := recover(); err != nil {
+					hs.log.Error("Panic while writing back provisioned dashboard", "uid", uid, "error", err)
+				}
+			}()
+
+			if err := hs.ProvisioningService.WriteBackDashboard(ctx, writeBackCmd); err != nil {
+				hs.log.Warn("Failed to write back provisioned dashboard", "uid", uid, "provisioner", writeBackCmd.Name, "error", err)
+			}
+		}(context.WithoutCancel(ctx), dashboard.UID)
+	}
+
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"status":    "success",
@@ -985,6 +1035,135 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 	return response.JSON(http.StatusOK, dashVersionMeta)
 }
 
+// swagger:route GET /dashboards/uid/{uid}/diff dashboard_versions getDashboardVersionsDiffByUID
+//
+// Get the JSON patch between two versions of a dashboard.
+//
+// Responses:
+// 200: dashboardVersionsDiffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardVersionsDiff(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.GetDashboardVersionsDiff")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	base, err := strconv.ParseInt(c.Query("base"), 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "base version is invalid", err)
+	}
+	newVersion, err := strconv.ParseInt(c.Query("new"), 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "new version is invalid", err)
+	}
+
+	diff, err := hs.dashboardVersionService.Diff(c.Req.Context(), &dashver.DiffDashboardVersionsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+		BaseVersion:  base,
+		NewVersion:   newVersion,
+	})
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard version not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to compute the diff between the dashboard versions", err)
+	}
+
+	return response.JSON(http.StatusOK, diff)
+}
+
+// swagger:route GET /dashboards/uid/{uid}/blame dashboard_versions getDashboardVersionsBlameByUID
+//
+// Get the version and the user which last changed each panel of a dashboard.
+//
+// Responses:
+// 200: dashboardVersionsBlameResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardVersionsBlame(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.GetDashboardVersionsBlame")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	blame, err := hs.dashboardVersionService.Blame(c.Req.Context(), &dashver.BlameDashboardVersionsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+	})
+	if err != nil {
+		if errors.Is(err, dashver.ErrNoVersionsForDashboardID) {
+			return response.Error(http.StatusNotFound, fmt.Sprintf("No versions found for dashboard %s", dash.UID), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to compute the blame of the dashboard", err)
+	}
+
+	loginMem := make(map[int64]string)
+	res := dashver.DashboardVersionBlameMeta{
+		DashboardUID:  blame.DashboardUID,
+		Version:       blame.Version,
+		OldestVersion: blame.OldestVersion,
+		Panels:        make([]dashver.PanelBlameMeta, 0, len(blame.Panels)),
+	}
+	for _, panel := range blame.Panels {
+		creator := anonString
+		if panel.CreatedBy > 0 {
+			login, found := loginMem[panel.CreatedBy]
+			if found {
+				creator = login
+			} else {
+				creator = hs.getIdentityName(c.Req.Context(), c.SignedInUser.GetOrgID(), panel.CreatedBy)
+				if creator != anonString {
+					loginMem[panel.CreatedBy] = creator
+				}
+			}
+		}
+
+		res.Panels = append(res.Panels, dashver.PanelBlameMeta{
+			PanelID:   panel.PanelID,
+			Title:     panel.Title,
+			Version:   panel.Version,
+			Created:   panel.Created,
+			CreatedBy: creator,
+			Message:   panel.Message,
+		})
+	}
+
+	return response.JSON(http.StatusOK, res)
+}
+
 // swagger:route POST /dashboards/calculate-diff dashboards calculateDashboardDiff
 //
 // Perform diff on two dashboards.
@@ -1148,31 +1327,35 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *contextmodel.ReqContext) respon
 		return dashboardGuardianResponse(err)
 	}
 
-	versionQuery := dashver.GetDashboardVersionQuery{DashboardID: dashID, DashboardUID: dash.UID, Version: apiCmd.Version, OrgID: c.SignedInUser.GetOrgID()}
-	version, err := hs.dashboardVersionService.Get(c.Req.Context(), &versionQuery)
+	provisioningData, err := hs.dashboardProvisioningService.GetProvisionedDashboardDataByDashboardID(c.Req.Context(), dash.ID)
 	if err != nil {
-		return response.Error(http.StatusNotFound, "Dashboard version not found", nil)
+		return response.Error(http.StatusInternalServerError, "Error while checking if dashboard is provisioned using ID", err)
+	}
+	allowUiUpdate := true
+	if provisioningData != nil {
+		allowUiUpdate = hs.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
 	}
 
-	var userID int64
-	if id, err := identity.UserIdentifier(c.SignedInUser.GetID()); err == nil {
-		userID = id
+	message := apiCmd.Message
+	if message == "" {
+		message = dashverimpl.DashboardRestoreMessage(int(apiCmd.Version))
 	}
 
-	saveCmd := dashboards.SaveDashboardCommand{}
-	saveCmd.RestoredFrom = version.Version
-	saveCmd.OrgID = c.SignedInUser.GetOrgID()
-	saveCmd.UserID = userID
-	saveCmd.Dashboard = version.Data
-	saveCmd.Dashboard.Set("version", dash.Version)
-	saveCmd.Dashboard.Set("uid", dash.UID)
-	saveCmd.Message = dashverimpl.DashboardRestoreMessage(version.Version)
-	// nolint:staticcheck
-	saveCmd.FolderID = dash.FolderID
 	metrics.MFolderIDsAPICount.WithLabelValues(metrics.RestoreDashboardVersion).Inc()
-	saveCmd.FolderUID = dash.FolderUID
+	restored, err := hs.dashboardVersionService.Restore(c.Req.Context(), &dashver.RestoreDashboardVersionCommand{
+		DashboardID:   dash.ID,
+		DashboardUID:  dash.UID,
+		OrgID:         c.SignedInUser.GetOrgID(),
+		Version:       apiCmd.Version,
+		User:          c.SignedInUser,
+		Message:       message,
+		AllowUIUpdate: allowUiUpdate,
+	})
+	if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+		return response.Error(http.StatusNotFound, "Dashboard version not found", nil)
+	}
 
-	return hs.postDashboard(c, saveCmd)
+	return hs.dashboardSaved(c, dash, restored, err, message, false, provisioningData)
 }
 
 // swagger:route GET /dashboards/tags dashboards getDashboardTags
@@ -1255,6 +1438,28 @@ type RestoreDashboardVersionByUIDParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters getDashboardVersionsDiffByUID
+type GetDashboardVersionsDiffByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Version to compare from
+	// in:query
+	// required:true
+	Base int64 `json:"base"`
+	// Version to compare to
+	// in:query
+	// required:true
+	New int64 `json:"new"`
+}
+
+// swagger:parameters getDashboardVersionsBlameByUID
+type GetDashboardVersionsBlameByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
 // swagger:parameters getDashboardVersionByID
 type GetDashboardVersionByIDParams struct {
 	// in:path
@@ -1436,6 +1641,18 @@ type DashboardVersionsResponse struct {
 	Body []dashver.DashboardVersionMeta `json:"body"`
 }
 
+// swagger:response dashboardVersionsDiffResponse
+type DashboardVersionsDiffResponse struct {
+	// in: body
+	Body *dashver.DashboardVersionDiff `json:"body"`
+}
+
+// swagger:response dashboardVersionsBlameResponse
+type DashboardVersionsBlameResponse struct {
+	// in: body
+	Body *dashver.DashboardVersionBlameMeta `json:"body"`
+}
+
 // swagger:response dashboardVersionResponse
 type DashboardVersionResponse struct {
 	// in: body
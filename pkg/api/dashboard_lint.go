@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards/lint"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /dashboards/lint dashboards lintDashboard
+//
+// Check a dashboard against the lint rules.
+//
+// Checks the dashboard against the lint rules of the folder without saving it, so that the
+// dashboards can be checked before they are provisioned or saved, e.g. in CI.
+//
+// Responses:
+// 200: lintDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) LintDashboard(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.LintDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Dashboard == nil {
+		return response.Error(http.StatusBadRequest, "dashboard is required", nil)
+	}
+
+	linter, err := lint.New(hs.Cfg.DashboardLintRules, lint.BuiltinRules())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to load the lint rules", err)
+	}
+
+	return response.JSON(http.StatusOK, linter.Lint(cmd.FolderUID, cmd.Dashboard))
+}
+
+// swagger:parameters lintDashboard
+type LintDashboardParams struct {
+	// in:body
+	// required:true
+	Body dtos.LintDashboardCommand
+}
+
+// swagger:response lintDashboardResponse
+type LintDashboardResponse struct {
+	// in: body
+	Body lint.Result `json:"body"`
+}
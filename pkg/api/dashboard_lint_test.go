@@ -0,0 +1,63 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/lint"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_LintDashboard(t *testing.T) {
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.Cfg.DashboardLintRules = []setting.DashboardLintRule{
+			{Name: "refresh", Rule: lint.RuleMinRefreshInterval, Mode: setting.DashboardLintModeBlock, FolderUIDs: []string{"ops"}, Params: map[string]string{"min_interval": "1m"}},
+			{Name: "tags", Rule: lint.RuleRequiredTags, Mode: setting.DashboardLintModeWarn},
+		}
+	})
+
+	lintDashboard := func(t *testing.T, body string, permissions ...accesscontrol.Permission) (*http.Response, string) {
+		t.Helper()
+		req := server.NewPostRequest("/api/dashboards/lint", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, authedUserWithPermissions(1, 1, permissions)))
+		require.NoError(t, err)
+		resBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res, string(resBody)
+	}
+	canCreate := accesscontrol.Permission{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersAll}
+
+	t.Run("should return the findings of the rules of the folder", func(t *testing.T) {
+		res, body := lintDashboard(t, `{"folderUid": "ops", "dashboard": {"title": "Services", "refresh": "5s"}}`, canCreate)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"blocked": true, "findings": [
+			{"name": "refresh", "rule": "min_refresh_interval", "mode": "block", "message": "refresh interval 5s is shorter than the minimum 1m"},
+			{"name": "tags", "rule": "required_tags", "mode": "warn", "message": "dashboard must have at least one tag"}
+		]}`, body)
+
+		res, body = lintDashboard(t, `{"dashboard": {"title": "Services", "refresh": "5s", "tags": ["ops"]}}`, canCreate)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"blocked": false, "findings": []}`, body)
+	})
+
+	t.Run("should require a dashboard", func(t *testing.T) {
+		res, _ := lintDashboard(t, `{"folderUid": "ops"}`, canCreate)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should not lint without permission to save dashboards", func(t *testing.T) {
+		res, _ := lintDashboard(t, `{"dashboard": {"title": "Services"}}`)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
@@ -168,14 +169,40 @@ func (hs *HTTPServer) UpdateDashboardPermissions(c *contextmodel.ReqContext) res
 	}
 
 	items = append(items, hs.filterHiddenACL(c.SignedInUser, acl)...)
+	auditlog.RecordBefore(c.Req.Context(), aclState(acl))
 
 	if err := hs.updateDashboardAccessControl(c.Req.Context(), dash.OrgID, dash.UID, false, items, acl); err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update permissions", err)
 	}
 
+	if auditlog.Recording(c.Req.Context()) {
+		if after, err := hs.getDashboardACL(c.Req.Context(), c.SignedInUser, dash); err == nil {
+			auditlog.RecordAfter(c.Req.Context(), aclState(after))
+		}
+	}
+
 	return response.Success("Dashboard permissions updated")
 }
 
+// aclState keys the permissions of a dashboard or folder by user login, team name or basic role,
+// the way they are recorded in the audit log.
+func aclState(acl []*dashboards.DashboardACLInfoDTO) map[string]string {
+	state := make(map[string]string, len(acl))
+	for _, item := range acl {
+		switch {
+		case item.Inherited:
+			continue
+		case item.UserLogin != "":
+			state["user:"+item.UserLogin] = item.PermissionName
+		case item.Team != "":
+			state["team:"+item.Team] = item.PermissionName
+		case item.Role != nil:
+			state["role:"+string(*item.Role)] = item.PermissionName
+		}
+	}
+	return state
+}
+
 var dashboardPermissionMap = map[string]dashboardaccess.PermissionType{
 	"View":  dashboardaccess.PERMISSION_VIEW,
 	"Edit":  dashboardaccess.PERMISSION_EDIT,
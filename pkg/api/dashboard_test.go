@@ -565,23 +565,13 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 
 		dashboardService := dashboards.NewFakeDashboardService(t)
 		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
-		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
-			cmd := args.Get(1).(*dashboards.SaveDashboardDTO)
-			cmd.Dashboard = &dashboards.Dashboard{
-				ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 1,
-			}
-		}).Return(nil, nil)
 
 		cmd := dtos.RestoreDashboardVersionCommand{
 			Version: 1,
 		}
 		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
-		fakeDashboardVersionService.ExpectedDashboardVersions = []*dashver.DashboardVersionDTO{
-			{
-				DashboardID: 2,
-				Version:     1,
-				Data:        fakeDash.Data,
-			},
+		fakeDashboardVersionService.ExpectedDashboard = &dashboards.Dashboard{
+			ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 2,
 		}
 		mockSQLStore := dbtest.NewFakeDB()
 		origNewGuardian := guardian.New
@@ -596,6 +586,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 
 				callRestoreDashboardVersion(sc)
 				assert.Equal(t, http.StatusOK, sc.resp.Code)
+				assert.Equal(t, int64(2), sc.ToJSON().Get("version").MustInt64())
 			}, mockSQLStore)
 	})
 
@@ -606,20 +597,10 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 
 		dashboardService := dashboards.NewFakeDashboardService(t)
 		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
-		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
-			cmd := args.Get(1).(*dashboards.SaveDashboardDTO)
-			cmd.Dashboard = &dashboards.Dashboard{
-				ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 1,
-			}
-		}).Return(nil, nil)
 
 		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
-		fakeDashboardVersionService.ExpectedDashboardVersions = []*dashver.DashboardVersionDTO{
-			{
-				DashboardID: 2,
-				Version:     1,
-				Data:        fakeDash.Data,
-			},
+		fakeDashboardVersionService.ExpectedDashboard = &dashboards.Dashboard{
+			ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 2,
 		}
 
 		cmd := dtos.RestoreDashboardVersionCommand{
@@ -633,6 +614,23 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}, mockSQLStore)
 	})
 
+	t.Run("Given a dashboard version that does not exist", func(t *testing.T) {
+		fakeDash := dashboards.NewDashboard("Child dash")
+		fakeDash.ID = 2
+
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedError = dashver.ErrDashboardVersionNotFound
+
+		restoreDashboardVersionScenario(t, "When calling POST on", "/api/dashboards/id/1/restore",
+			"/api/dashboards/id/:dashboardId/restore", dashboardService, fakeDashboardVersionService, dtos.RestoreDashboardVersionCommand{Version: 5}, func(sc *scenarioContext) {
+				callRestoreDashboardVersion(sc)
+				assert.Equal(t, http.StatusNotFound, sc.resp.Code)
+			}, dbtest.NewFakeDB())
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		mockSQLStore := dbtest.NewFakeDB()
 		dashboardStore := dashboards.NewFakeDashboardStore(t)
@@ -802,6 +800,54 @@ func TestDashboardVersionsAPIEndpoint(t *testing.T) {
 				assert.Equal(t, anonString, v.CreatedBy)
 			}
 		}, mockSQLStore)
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/uid/uid/diff",
+		"/api/dashboards/uid/:uid/diff", org.RoleEditor, func(sc *scenarioContext) {
+			setUp()
+			fakeDashboardVersionService.ExpectedDiff = &dashver.DashboardVersionDiff{
+				DashboardUID: "uid",
+				BaseVersion:  1,
+				NewVersion:   2,
+				Patch:        []dashver.JSONPatchOperation{{Op: "replace", Path: "/title", Value: "new"}},
+			}
+			hs := getHS(&usertest.FakeUserService{})
+
+			sc.handlerFunc = hs.GetDashboardVersionsDiff
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"base": "1"}).exec()
+			assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"base": "1", "new": "2"}).exec()
+			assert.Equal(t, http.StatusOK, sc.resp.Code)
+			assert.JSONEq(t, `{"dashboardUid": "uid", "baseVersion": 1, "newVersion": 2, "patch": [{"op": "replace", "path": "/title", "value": "new"}]}`, sc.resp.Body.String())
+		}, mockSQLStore)
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/uid/uid/blame",
+		"/api/dashboards/uid/:uid/blame", org.RoleEditor, func(sc *scenarioContext) {
+			setUp()
+			fakeDashboardVersionService.ExpectedBlame = &dashver.DashboardVersionBlame{
+				DashboardUID:  "uid",
+				Version:       3,
+				OldestVersion: 1,
+				Panels: []dashver.PanelBlame{
+					{PanelID: 1, Title: "CPU", Version: 1, CreatedBy: 1},
+					{PanelID: 2, Title: "Memory", Version: 3},
+				},
+			}
+			hs := getHS(&usertest.FakeUserService{
+				ExpectedSignedInUser: &user.SignedInUser{Login: "test-user"},
+			})
+
+			sc.handlerFunc = hs.GetDashboardVersionsBlame
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+			assert.Equal(t, http.StatusOK, sc.resp.Code)
+			var blame dashver.DashboardVersionBlameMeta
+			err := json.NewDecoder(sc.resp.Body).Decode(&blame)
+			require.NoError(t, err)
+			require.Len(t, blame.Panels, 2)
+			assert.Equal(t, "test-user", blame.Panels[0].CreatedBy)
+			assert.Equal(t, anonString, blame.Panels[1].CreatedBy)
+		}, mockSQLStore)
 }
 
 func getDashboardShouldReturn200WithConfig(t *testing.T, sc *scenarioContext, provisioningService provisioning.ProvisioningService, dashboardStore dashboards.Store, dashboardService dashboards.DashboardService, folderStore folder.FolderStore) dtos.DashboardFullWithMeta {
@@ -982,19 +1028,20 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 		folderSvc.ExpectedFolder = &folder.Folder{}
 
 		hs := HTTPServer{
-			Cfg:                     cfg,
-			ProvisioningService:     provisioning.NewProvisioningServiceMock(context.Background()),
-			Live:                    newTestLive(t, db.InitTestDB(t)),
-			QuotaService:            quotatest.New(false, nil),
-			LibraryPanelService:     &mockLibraryPanelService{},
-			LibraryElementService:   &libraryelementsfake.LibraryElementService{},
-			DashboardService:        mock,
-			SQLStore:                sqlStore,
-			Features:                featuremgmt.WithFeatures(),
-			dashboardVersionService: fakeDashboardVersionService,
-			accesscontrolService:    actest.FakeService{},
-			folderService:           folderSvc,
-			tracer:                  tracing.InitializeTracerForTest(),
+			Cfg:                          cfg,
+			ProvisioningService:          provisioning.NewProvisioningServiceMock(context.Background()),
+			Live:                         newTestLive(t, db.InitTestDB(t)),
+			QuotaService:                 quotatest.New(false, nil),
+			LibraryPanelService:          &mockLibraryPanelService{},
+			LibraryElementService:        &libraryelementsfake.LibraryElementService{},
+			DashboardService:             mock,
+			SQLStore:                     sqlStore,
+			Features:                     featuremgmt.WithFeatures(),
+			dashboardVersionService:      fakeDashboardVersionService,
+			dashboardProvisioningService: mockDashboardProvisioningService{},
+			accesscontrolService:         actest.FakeService{},
+			folderService:                folderSvc,
+			tracer:                       tracing.InitializeTracerForTest(),
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -174,6 +175,7 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	auditlog.RecordBefore(c.Req.Context(), hs.convertModelToDtos(c.Req.Context(), ds))
 
 	cmd := &datasources.DeleteDataSourceCommand{ID: id, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
@@ -253,6 +255,7 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	auditlog.RecordBefore(c.Req.Context(), hs.convertModelToDtos(c.Req.Context(), ds))
 
 	cmd := &datasources.DeleteDataSourceCommand{UID: uid, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
@@ -420,6 +423,8 @@ func (hs *HTTPServer) AddDataSource(c *contextmodel.ReqContext) response.Respons
 	hs.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
 
 	ds := hs.convertModelToDtos(c.Req.Context(), dataSource)
+	auditlog.RecordResourceID(c.Req.Context(), ds.UID)
+	auditlog.RecordAfter(c.Req.Context(), ds)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message":    "Datasource added",
 		"id":         dataSource.ID,
@@ -524,6 +529,7 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot update read-only data source", nil)
 	}
+	auditlog.RecordBefore(c.Req.Context(), hs.convertModelToDtos(c.Req.Context(), ds))
 
 	_, err := hs.DataSourcesService.UpdateDataSource(c.Req.Context(), &cmd)
 	if err != nil {
@@ -556,6 +562,7 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	}
 
 	datasourceDTO := hs.convertModelToDtos(c.Req.Context(), dataSource)
+	auditlog.RecordAfter(c.Req.Context(), datasourceDTO)
 
 	hs.Live.HandleDatasourceUpdate(c.SignedInUser.GetOrgID(), datasourceDTO.UID)
 
@@ -45,3 +45,59 @@ type PostGraphiteAnnotationsCmd struct {
 	Data string `json:"data"`
 	Tags any    `json:"tags"`
 }
+
+// AnnotationExportLine is an annotation in an import or an export, one JSON object per line.
+type AnnotationExportLine struct {
+	// Key identifies the annotation in the organization, so that importing it again doesn't duplicate it.
+	// Optional, not exported.
+	Key          string           `json:"key,omitempty"`
+	ID           int64            `json:"id,omitempty"`
+	DashboardUID string           `json:"dashboardUID,omitempty"`
+	PanelId      int64            `json:"panelId,omitempty"`
+	AlertId      int64            `json:"alertId,omitempty"`
+	Time         int64            `json:"time"`
+	TimeEnd      int64            `json:"timeEnd,omitempty"`
+	Text         string           `json:"text"`
+	Tags         []string         `json:"tags"`
+	Data         *simplejson.Json `json:"data,omitempty"`
+	Login        string           `json:"login,omitempty"`
+	Created      int64            `json:"created,omitempty"`
+	Updated      int64            `json:"updated,omitempty"`
+}
+
+type ImportAnnotationsResult struct {
+	Imported int                      `json:"imported"`
+	Skipped  int                      `json:"skipped"`
+	Failed   int                      `json:"failed"`
+	Errors   []ImportAnnotationsError `json:"errors"`
+}
+
+type ImportAnnotationsError struct {
+	Line    int    `json:"line"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+type MergeAnnotationsCmd struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	PanelId      int64    `json:"panelId"`
+	From         int64    `json:"from"`
+	To           int64    `json:"to"`
+	Tags         []string `json:"tags"`
+	MatchAny     bool     `json:"matchAny"`
+	// DryRun returns the regions which would be merged without merging them
+	DryRun bool `json:"dryRun"`
+}
+
+type MergedAnnotationRegion struct {
+	ID        int64   `json:"id"`
+	Time      int64   `json:"time"`
+	TimeEnd   int64   `json:"timeEnd"`
+	Text      string  `json:"text"`
+	MergedIDs []int64 `json:"mergedIds"`
+}
+
+type MergeAnnotationsResult struct {
+	Message string                   `json:"message"`
+	Regions []MergedAnnotationRegion `json:"regions"`
+}
@@ -58,6 +58,13 @@ type CalculateDiffTarget struct {
 	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
 }
 
+type LintDashboardCommand struct {
+	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
+	// FolderUID selects the lint rules of the folder, the ones of the general folder if empty
+	FolderUID string `json:"folderUid"`
+}
+
 type RestoreDashboardVersionCommand struct {
-	Version int64 `json:"version" binding:"Required"`
+	Version int64  `json:"version" binding:"Required"`
+	Message string `json:"message"`
 }
@@ -50,7 +50,17 @@ func (hs *HTTPServer) registerFolderAPI(apiRoute routing.RouteRegister, authoriz
 			folderUidRoute.Delete("/", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersDelete, uidScope)), routing.Wrap(hs.DeleteFolder))
 			folderUidRoute.Get("/", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.GetFolderByUID))
 			folderUidRoute.Get("/counts", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.GetFolderDescendantCounts))
+			folderUidRoute.Get("/quotas", authorize(accesscontrol.EvalAll(
+				accesscontrol.EvalPermission(dashboards.ActionFoldersRead, uidScope),
+				accesscontrol.EvalPermission(accesscontrol.ActionOrgsQuotasRead),
+			)), routing.Wrap(hs.GetFolderQuotas))
 			folderUidRoute.Post("/move", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersWrite, uidScope)), routing.Wrap(hs.MoveFolder))
+			folderUidRoute.Group("/defaults", func(folderDefaultsRoute routing.RouteRegister) {
+				folderDefaultsRoute.Get("/", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.GetFolderDefaults))
+				folderDefaultsRoute.Put("/", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersWrite, uidScope)), routing.Wrap(hs.SetFolderDefaults))
+				folderDefaultsRoute.Delete("/", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersWrite, uidScope)), routing.Wrap(hs.DeleteFolderDefaults))
+				folderDefaultsRoute.Get("/resolved", authorize(accesscontrol.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.GetResolvedFolderDefaults))
+			})
 		})
 	})
 }
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folderdefaults"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /folders/{folder_uid}/defaults folders getFolderDefaults
+//
+// Get the defaults set on a folder.
+//
+// Returns the defaults set on the folder itself, not the ones inherited from its parent folders.
+//
+// Responses:
+// 200: folderDefaultsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderDefaults(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	defaults, err := hs.folderDefaultsService.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get folder defaults", err)
+	}
+	return response.JSON(http.StatusOK, defaults)
+}
+
+// swagger:route PUT /folders/{folder_uid}/defaults folders setFolderDefaults
+//
+// Set the defaults of a folder.
+//
+// Replaces the defaults set on the folder. The empty defaults are inherited from the parent folders.
+//
+// Responses:
+// 200: folderDefaultsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) SetFolderDefaults(c *contextmodel.ReqContext) response.Response {
+	cmd := folderdefaults.SetFolderDefaultsCommand{}
+	// the defaults are validated when bound
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.ErrOrFallback(http.StatusBadRequest, "bad request data", err)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.FolderUID = uid
+	defaults, err := hs.folderDefaultsService.Set(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set folder defaults", err)
+	}
+	return response.JSON(http.StatusOK, defaults)
+}
+
+// swagger:route DELETE /folders/{folder_uid}/defaults folders deleteFolderDefaults
+//
+// Delete the defaults of a folder.
+//
+// The dashboards of the folder inherit the defaults of the parent folders only.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteFolderDefaults(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	if err := hs.folderDefaultsService.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete folder defaults", err)
+	}
+	return response.Success("Folder defaults deleted")
+}
+
+// swagger:route GET /folders/{folder_uid}/defaults/resolved folders getResolvedFolderDefaults
+//
+// Get the defaults of the dashboards of a folder.
+//
+// Returns the defaults inherited by the dashboards of the folder, from the folder and its parent folders,
+// with the UIDs of the folders they are inherited from.
+//
+// Responses:
+// 200: resolvedFolderDefaultsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetResolvedFolderDefaults(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	defaults, err := hs.folderDefaultsService.Resolve(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to resolve folder defaults", err)
+	}
+	return response.JSON(http.StatusOK, defaults)
+}
+
+// swagger:parameters getFolderDefaults deleteFolderDefaults getResolvedFolderDefaults
+type FolderDefaultsParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+}
+
+// swagger:parameters setFolderDefaults
+type SetFolderDefaultsParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:body
+	// required:true
+	Body folderdefaults.SetFolderDefaultsCommand
+}
+
+// swagger:response folderDefaultsResponse
+type FolderDefaultsResponse struct {
+	// in: body
+	Body folderdefaults.FolderDefaults `json:"body"`
+}
+
+// swagger:response resolvedFolderDefaultsResponse
+type ResolvedFolderDefaultsResponse struct {
+	// in: body
+	Body folderdefaults.ResolvedDefaults `json:"body"`
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/folderdefaults"
+	"github.com/grafana/grafana/pkg/services/folderdefaults/folderdefaultstest"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/star/startest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_FolderDefaults(t *testing.T) {
+	setup := func(t *testing.T, defaultsSvc *folderdefaultstest.FakeService) *webtest.Server {
+		t.Helper()
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.folderService = &foldertest.FakeService{ExpectedFolder: &folder.Folder{UID: "team", Title: "Team"}}
+			hs.folderDefaultsService = defaultsSvc
+		})
+	}
+
+	send := func(t *testing.T, server *webtest.Server, req *http.Request, permissions ...accesscontrol.Permission) (*http.Response, string) {
+		t.Helper()
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res, string(body)
+	}
+
+	readFolder := accesscontrol.Permission{Action: dashboards.ActionFoldersRead, Scope: "folders:uid:team"}
+	writeFolder := accesscontrol.Permission{Action: dashboards.ActionFoldersWrite, Scope: "folders:uid:team"}
+
+	t.Run("should set the defaults of the folder", func(t *testing.T) {
+		defaultsSvc := &folderdefaultstest.FakeService{ExpectedDefaults: &folderdefaults.FolderDefaults{FolderUID: "team", Timezone: "utc"}}
+		server := setup(t, defaultsSvc)
+		put := func(body string, permissions ...accesscontrol.Permission) (*http.Response, string) {
+			req := server.NewRequest(http.MethodPut, "/api/folders/team/defaults", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			return send(t, server, req, permissions...)
+		}
+
+		res, _ := put(`{"timezone": "utc"}`, readFolder)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = put(`{"timezone": "utc", "requiredTags": ["team"]}`, writeFolder)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, &folderdefaults.SetFolderDefaultsCommand{OrgID: 1, FolderUID: "team", Timezone: "utc", RequiredTags: []string{"team"}}, defaultsSvc.LastSetCommand)
+
+		res, body := put(`{"timeFrom": "now-1h"}`, writeFolder)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Contains(t, body, "timeFrom and timeTo must be set together")
+	})
+
+	t.Run("should get the defaults of the folder", func(t *testing.T) {
+		server := setup(t, &folderdefaultstest.FakeService{
+			ExpectedResolved: &folderdefaults.ResolvedDefaults{Timezone: "utc", RequiredTags: []string{}, Origins: map[string]string{folderdefaults.FieldTimezone: "company"}},
+		})
+
+		res, body := send(t, server, server.NewGetRequest("/api/folders/team/defaults/resolved"), readFolder)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"timezone": "utc", "timeFrom": "", "timeTo": "", "minRefreshInterval": "", "defaultDatasourceUid": "", "requiredTags": [], "origins": {"timezone": "company"}}`, body)
+
+		server = setup(t, &folderdefaultstest.FakeService{ExpectedError: folderdefaults.ErrFolderDefaultsNotFound.Errorf("no defaults")})
+		res, _ = send(t, server, server.NewGetRequest("/api/folders/team/defaults"), readFolder)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should apply the defaults to the dashboards of the folder when they are loaded", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			folderDash := dashboards.NewDashboardFolder("Team")
+			folderDash.ID = 2
+			folderDash.UID = "team"
+			dash := dashboards.NewDashboardFromJson(simplejson.MustJson([]byte(`{"title": "Services", "refresh": "5s"}`)))
+			dash.ID = 1
+			dash.UID = "1"
+			dash.FolderID = 2 // nolint:staticcheck
+			dash.FolderUID = "team"
+
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool { return q.UID == "1" })).Return(dash, nil)
+			dashSvc.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool { return q.ID == 2 })).Return(folderDash, nil)
+			hs.DashboardService = dashSvc
+			hs.folderService = foldertest.NewFakeService()
+			hs.folderDefaultsService = &folderdefaultstest.FakeService{
+				ExpectedResolved: &folderdefaults.ResolvedDefaults{Timezone: "utc", MinRefreshInterval: "1m"},
+			}
+			hs.Cfg = setting.NewCfg()
+			hs.AccessControl = acimpl.ProvideAccessControl(featuremgmt.WithFeatures())
+			hs.starService = startest.NewStarServiceFake()
+			hs.dashboardProvisioningService = mockDashboardProvisioningService{}
+
+			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService, hs.folderService, log.NewNopLogger())
+		})
+
+		res, body := send(t, server, server.NewGetRequest("/api/dashboards/uid/1"), accesscontrol.Permission{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var data dtos.DashboardFullWithMeta
+		require.NoError(t, json.Unmarshal([]byte(body), &data))
+		assert.Equal(t, "team", data.Meta.FolderUid)
+		assert.Equal(t, "utc", data.Dashboard.Get("timezone").MustString())
+		assert.Equal(t, "1m", data.Dashboard.Get("refresh").MustString())
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
@@ -113,11 +114,18 @@ func (hs *HTTPServer) UpdateFolderPermissions(c *contextmodel.ReqContext) respon
 	}
 
 	items = append(items, hs.filterHiddenACL(c.SignedInUser, acl)...)
+	auditlog.RecordBefore(c.Req.Context(), aclState(acl))
 
 	if err := hs.updateDashboardAccessControl(c.Req.Context(), c.SignedInUser.GetOrgID(), folder.UID, true, items, acl); err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to create permission", err)
 	}
 
+	if auditlog.Recording(c.Req.Context()) {
+		if after, err := hs.getFolderACL(c.Req.Context(), c.SignedInUser, folder); err == nil {
+			auditlog.RecordAfter(c.Req.Context(), aclState(after))
+		}
+	}
+
 	return response.Success("Folder permissions updated")
 }
 
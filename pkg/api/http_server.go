@@ -64,6 +64,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folderdefaults"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
@@ -183,9 +184,11 @@ type HTTPServer struct {
 	serviceAccountsService       serviceaccounts.Service
 	authInfoService              login.AuthInfoService
 	NotificationService          notifications.Service
+	notificationOutbox           notifications.Outbox
 	DashboardService             dashboards.DashboardService
 	dashboardProvisioningService dashboards.DashboardProvisioningService
 	folderService                folder.Service
+	folderDefaultsService        folderdefaults.Service
 	dsGuardian                   guardian.DatasourceGuardianProvider
 	dashboardsnapshotsService    dashboardsnapshots.Service
 	PluginSettings               pluginSettings.Service
@@ -255,8 +258,9 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	dataSourcesService datasources.DataSourceService, queryDataService query.Service, pluginFileStore plugins.FileStore,
 	serviceaccountsService serviceaccounts.Service, pluginAssets *pluginassets.Service,
 	authInfoService login.AuthInfoService, storageService store.StorageService,
-	notificationService notifications.Service, dashboardService dashboards.DashboardService,
+	notificationService notifications.Service, notificationOutbox notifications.Outbox, dashboardService dashboards.DashboardService,
 	dashboardProvisioningService dashboards.DashboardProvisioningService, folderService folder.Service,
+	folderDefaultsService folderdefaults.Service,
 	dsGuardian guardian.DatasourceGuardianProvider,
 	dashboardsnapshotsService dashboardsnapshots.Service, pluginSettings pluginSettings.Service,
 	avatarCacheServer *avatar.AvatarCacheServer, preferenceService pref.Service,
@@ -339,9 +343,11 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		serviceAccountsService:       serviceaccountsService,
 		authInfoService:              authInfoService,
 		NotificationService:          notificationService,
+		notificationOutbox:           notificationOutbox,
 		DashboardService:             dashboardService,
 		dashboardProvisioningService: dashboardProvisioningService,
 		folderService:                folderService,
+		folderDefaultsService:        folderDefaultsService,
 		dsGuardian:                   dsGuardian,
 		dashboardsnapshotsService:    dashboardsnapshotsService,
 		PluginSettings:               pluginSettings,
@@ -924,6 +930,19 @@ func (hs *HTTPServer) configureTLS() error {
 		CipherSuites: tlsCiphers,
 	}
 
+	// Client certificates are verified again by the mTLS authn client, which also accepts them from a proxy
+	if hs.Cfg.AuthMTLS.Enabled {
+		clientCAs, err := os.ReadFile(hs.Cfg.AuthMTLS.CACertPath)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		tlsCfg.ClientCAs = x509.NewCertPool()
+		if !tlsCfg.ClientCAs.AppendCertsFromPEM(clientCAs) {
+			return fmt.Errorf("no certificates found in client CA bundle %s", hs.Cfg.AuthMTLS.CACertPath)
+		}
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
 	hs.httpSrv.TLSConfig = tlsCfg
 
 	if hs.Cfg.Protocol == setting.HTTP2Scheme {
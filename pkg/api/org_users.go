@@ -9,7 +9,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -95,6 +97,8 @@ func (hs *HTTPServer) addOrgUserHelper(c *contextmodel.ReqContext, cmd org.AddOr
 		}
 		return response.Error(http.StatusInternalServerError, "Could not add user to organization", err)
 	}
+	auditlog.RecordResourceID(c.Req.Context(), strconv.FormatInt(cmd.UserID, 10))
+	hs.recordOrgUserState(c.Req.Context(), cmd.OrgID, cmd.UserID, auditlog.RecordAfter)
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "User added to organization",
@@ -432,12 +436,14 @@ func (hs *HTTPServer) updateOrgUserHelper(c *contextmodel.ReqContext, cmd org.Up
 		}
 	}
 
+	hs.recordOrgUserState(c.Req.Context(), cmd.OrgID, cmd.UserID, auditlog.RecordBefore)
 	if err := hs.orgService.UpdateOrgUser(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, org.ErrLastOrgAdmin) {
 			return response.Error(http.StatusBadRequest, "Cannot change role so that there is no organization admin left", nil)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed update org user", err)
 	}
+	hs.recordOrgUserState(c.Req.Context(), cmd.OrgID, cmd.UserID, auditlog.RecordAfter)
 
 	hs.accesscontrolService.ClearUserPermissionCache(&user.SignedInUser{
 		UserID: cmd.UserID,
@@ -502,6 +508,7 @@ func (hs *HTTPServer) RemoveOrgUser(c *contextmodel.ReqContext) response.Respons
 }
 
 func (hs *HTTPServer) removeOrgUserHelper(ctx context.Context, cmd *org.RemoveOrgUserCommand) response.Response {
+	hs.recordOrgUserState(ctx, cmd.OrgID, cmd.UserID, auditlog.RecordBefore)
 	if err := hs.orgService.RemoveOrgUser(ctx, cmd); err != nil {
 		if errors.Is(err, org.ErrLastOrgAdmin) {
 			return response.Error(http.StatusBadRequest, "Cannot remove last organization admin", nil)
@@ -633,3 +640,22 @@ type SearchOrgUsersResponse struct {
 	// in: body
 	Body *org.SearchOrgUsersQueryResult `json:"body"`
 }
+
+// recordOrgUserState records the membership of the user in the organization as the before or
+// after state of an audited request.
+func (hs *HTTPServer) recordOrgUserState(ctx context.Context, orgID, userID int64, record func(context.Context, any)) {
+	if !auditlog.Recording(ctx) {
+		return
+	}
+	requester, _ := identity.GetRequester(ctx)
+	users, err := hs.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID:                    orgID,
+		UserID:                   userID,
+		DontEnforceAccessControl: true,
+		User:                     requester,
+	})
+	if err != nil || len(users) == 0 {
+		return
+	}
+	record(ctx, users[0])
+}
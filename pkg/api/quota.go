@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -56,6 +58,35 @@ func (hs *HTTPServer) getOrgQuotasHelper(c *contextmodel.ReqContext, orgID int64
 	return response.JSON(http.StatusOK, q)
 }
 
+// swagger:route GET /folders/{folder_uid}/quotas folders getFolderQuota
+//
+// Fetch folder quota.
+//
+// Returns the limits and the usage of the folder scope quotas, like the number of dashboards
+// and alert rules per folder.
+//
+// Responses:
+// 200: getQuotaResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderQuotas(c *contextmodel.ReqContext) response.Response {
+	ctx, span := hs.tracer.Start(c.Req.Context(), "api.GetFolderQuotas")
+	defer span.End()
+	uid := web.Params(c.Req)[":uid"]
+	f, err := hs.folderService.Get(ctx, &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	q, err := hs.QuotaService.GetFolderQuotas(ctx, f.OrgID, f.UID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get quota", err)
+	}
+	return response.JSON(http.StatusOK, q)
+}
+
 // swagger:route PUT /orgs/{org_id}/quotas/{quota_target} orgs updateOrgQuota
 //
 // Update user quota.
@@ -188,6 +219,13 @@ type GetUserQuotaParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// swagger:parameters getFolderQuota
+type GetFolderQuotaParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+}
+
 // swagger:parameters getOrgQuota
 type GetOrgQuotaParams struct {
 	// in:path
@@ -15,6 +15,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
@@ -188,3 +191,32 @@ func getFirstOrgPermissions(p map[int64][]accesscontrol.Permission) []accesscont
 	}
 	return []accesscontrol.Permission{}
 }
+
+func TestAPIEndpoint_GetFolderQuotas(t *testing.T) {
+	folderService := foldertest.NewFakeService()
+	folderService.ExpectedFolder = &folder.Folder{UID: "folder-uid", OrgID: 1}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.folderService = folderService
+	})
+
+	t.Run("AccessControl allows viewing folder quotas with correct permissions", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/folders/folder-uid/quotas"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("folder-uid")},
+			{Action: accesscontrol.ActionOrgsQuotasRead},
+		}))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("AccessControl prevents viewing folder quotas without quota permissions", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/folders/folder-uid/quotas"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("folder-uid")},
+		}))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
@@ -11,6 +11,7 @@ import (
 	claims "github.com/grafana/authlib/types"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -220,6 +221,18 @@ func (hs *HTTPServer) UpdateUserActiveOrg(c *contextmodel.ReqContext) response.R
 	return response.Success("Active organization changed")
 }
 
+// recordUserState records the profile of the user as the before or after state of an audited request.
+func (hs *HTTPServer) recordUserState(ctx context.Context, userID int64, record func(context.Context, any)) {
+	if !auditlog.Recording(ctx) {
+		return
+	}
+	profile, err := hs.userService.GetProfile(ctx, &user.GetUserProfileQuery{UserID: userID})
+	if err != nil {
+		return
+	}
+	record(ctx, profile)
+}
+
 func (hs *HTTPServer) handleUpdateUser(ctx context.Context, cmd user.UpdateUserCommand) response.Response {
 	// external user -> user data cannot be updated
 	if response := hs.errOnExternalUser(ctx, cmd.UserID); response != nil {
@@ -265,12 +278,14 @@ func (hs *HTTPServer) handleUpdateUser(ctx context.Context, cmd user.UpdateUserC
 		}
 	}
 
+	hs.recordUserState(ctx, cmd.UserID, auditlog.RecordBefore)
 	if err := hs.userService.Update(ctx, &cmd); err != nil {
 		if errors.Is(err, user.ErrCaseInsensitive) {
 			return response.Error(http.StatusConflict, "Update would result in user login conflict", err)
 		}
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update user", err)
 	}
+	hs.recordUserState(ctx, cmd.UserID, auditlog.RecordAfter)
 
 	return response.Success("User updated")
 }
@@ -0,0 +1,129 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchOperation is a single RFC 6902 operation.
+type JSONPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON omits the value of remove operations, other operations always have a value even if it is null.
+func (o JSONPatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+
+	type Alias JSONPatchOperation
+	return json.Marshal(Alias(o))
+}
+
+// JSONPatch computes the RFC 6902 operations transforming base into target. Objects are compared
+// key by key, arrays are compared index by index.
+func JSONPatch(base, target any) []JSONPatchOperation {
+	ops := make([]JSONPatchOperation, 0)
+	return appendPatch(ops, "", normalizeJSON(base), normalizeJSON(target))
+}
+
+func appendPatch(ops []JSONPatchOperation, path string, base, target any) []JSONPatchOperation {
+	switch b := base.(type) {
+	case map[string]any:
+		t, ok := target.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(b)+len(t))
+		for k := range b {
+			keys = append(keys, k)
+		}
+		for k := range t {
+			if _, found := b[k]; !found {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			childPath := path + "/" + escapePointerToken(k)
+			bv, inBase := b[k]
+			tv, inTarget := t[k]
+			switch {
+			case !inTarget:
+				ops = append(ops, JSONPatchOperation{Op: "remove", Path: childPath})
+			case !inBase:
+				ops = append(ops, JSONPatchOperation{Op: "add", Path: childPath, Value: tv})
+			default:
+				ops = appendPatch(ops, childPath, bv, tv)
+			}
+		}
+		return ops
+
+	case []any:
+		t, ok := target.([]any)
+		if !ok {
+			break
+		}
+
+		common := min(len(b), len(t))
+		for i := 0; i < common; i++ {
+			ops = appendPatch(ops, path+"/"+strconv.Itoa(i), b[i], t[i])
+		}
+		for i := common; i < len(t); i++ {
+			ops = append(ops, JSONPatchOperation{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: t[i]})
+		}
+		// remove from the end so the indexes of the remaining elements don't change
+		for i := len(b) - 1; i >= common; i-- {
+			ops = append(ops, JSONPatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		return ops
+	}
+
+	if !reflect.DeepEqual(base, target) {
+		ops = append(ops, JSONPatchOperation{Op: "replace", Path: path, Value: target})
+	}
+	return ops
+}
+
+// normalizeJSON converts the numbers to float64 so values decoded in different ways can be compared
+func normalizeJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = normalizeJSON(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = normalizeJSON(item)
+		}
+		return result
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return value
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,50 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPatch(t *testing.T) {
+	base := map[string]any{
+		"title": "old",
+		"tags":  []any{"a", "b", "c"},
+		"a/b":   1,
+		"panels": []any{
+			map[string]any{"id": 1, "title": "CPU"},
+		},
+		"removed": true,
+	}
+	target := map[string]any{
+		"title": "new",
+		"tags":  []any{"a"},
+		"a/b":   float64(1),
+		"panels": []any{
+			map[string]any{"id": 1, "title": "CPU", "type": "graph"},
+			map[string]any{"id": 2, "title": "Memory"},
+		},
+		"refresh": nil,
+	}
+
+	ops := JSONPatch(base, target)
+
+	require.Equal(t, []JSONPatchOperation{
+		{Op: "add", Path: "/panels/0/type", Value: "graph"},
+		{Op: "add", Path: "/panels/1", Value: map[string]any{"id": float64(2), "title": "Memory"}},
+		{Op: "add", Path: "/refresh", Value: nil},
+		{Op: "remove", Path: "/removed"},
+		{Op: "remove", Path: "/tags/2"},
+		{Op: "remove", Path: "/tags/1"},
+		{Op: "replace", Path: "/title", Value: "new"},
+	}, ops)
+
+	encoded, err := json.Marshal(ops[2:4])
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"op": "add", "path": "/refresh", "value": null}, {"op": "remove", "path": "/removed"}]`, string(encoded))
+
+	require.Equal(t, []JSONPatchOperation{{Op: "replace", Path: "/a~1b~0", Value: "x"}},
+		JSONPatch(map[string]any{"a/b~": 1}, map[string]any{"a/b~": "x"}))
+	require.Empty(t, JSONPatch(base, base))
+}
@@ -0,0 +1,217 @@
+package connectors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/login/social"
+	ssoModels "github.com/grafana/grafana/pkg/services/ssosettings/models"
+)
+
+const (
+	connectionTestTimeout = 15 * time.Second
+	openIDConfigPath      = "/.well-known/openid-configuration"
+)
+
+type openIDConfiguration struct {
+	TokenEndpoint string `json:"token_endpoint"`
+	JwksURI       string `json:"jwks_uri"`
+}
+
+// TestConnection checks that the endpoints configured in the settings can be reached. For OpenID Connect
+// providers the discovery document of the issuer is fetched and its signing keys are checked as well.
+func (s *SocialBase) TestConnection(ctx context.Context, settings ssoModels.SSOSettings) error {
+	info, err := CreateOAuthInfoFromKeyValues(settings.Settings)
+	if err != nil {
+		return err
+	}
+
+	client, err := connectionTestClient(info)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, connectionTestTimeout)
+	defer cancel()
+
+	endpoints := []struct {
+		name   string
+		url    string
+		method string
+	}{
+		{name: "auth URL", url: info.AuthUrl, method: http.MethodGet},
+		{name: "token URL", url: info.TokenUrl, method: http.MethodPost},
+		{name: "API URL", url: info.ApiUrl, method: http.MethodGet},
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.url == "" {
+			continue
+		}
+		if err := checkEndpoint(ctx, client, endpoint.method, endpoint.url); err != nil {
+			return fmt.Errorf("%s %s: %w", endpoint.name, endpoint.url, err)
+		}
+	}
+
+	if info.TokenUrl != "" && slices.Contains(info.Scopes, "openid") {
+		return s.checkOpenIDConfiguration(ctx, client, info.TokenUrl)
+	}
+
+	return nil
+}
+
+// checkEndpoint sends a request without credentials to the endpoint. The identity provider is expected
+// to reject the request, but a missing endpoint or a server error means the URL is wrong.
+func checkEndpoint(ctx context.Context, client *http.Client, method, endpointURL string) error {
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(url.Values{"grant_type": {"authorization_code"}}.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpointURL, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected response status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// checkOpenIDConfiguration looks for the discovery document advertising the token URL, starting from
+// the token URL itself and walking up its path. Providers that don't publish a discovery document are
+// not considered as failing.
+func (s *SocialBase) checkOpenIDConfiguration(ctx context.Context, client *http.Client, tokenURL string) error {
+	u, err := url.Parse(tokenURL)
+	if err != nil {
+		return err
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	for {
+		path = path[:max(strings.LastIndex(path, "/"), 0)]
+
+		issuer := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path}
+		config, err := fetchOpenIDConfiguration(ctx, client, issuer.String()+openIDConfigPath)
+		if err == nil && config.TokenEndpoint == tokenURL {
+			if config.JwksURI == "" {
+				return nil
+			}
+			if err := checkJWKS(ctx, client, config.JwksURI); err != nil {
+				return fmt.Errorf("JWKS %s: %w", config.JwksURI, err)
+			}
+			return nil
+		}
+
+		if path == "" {
+			break
+		}
+	}
+
+	s.log.Debug("No OpenID Connect discovery document found for the token URL", "token_url", tokenURL)
+	return nil
+}
+
+func fetchOpenIDConfiguration(ctx context.Context, client *http.Client, configURL string) (*openIDConfiguration, error) {
+	body, err := getJSON(ctx, client, configURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var config openIDConfiguration
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func checkJWKS(ctx context.Context, client *http.Client, jwksURL string) error {
+	body, err := getJSON(ctx, client, jwksURL)
+	if err != nil {
+		return err
+	}
+
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &jwks); err != nil {
+		return err
+	}
+
+	if len(jwks.Keys) == 0 {
+		return fmt.Errorf("no signing keys found")
+	}
+
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+}
+
+// connectionTestClient creates a client using the TLS settings of the provider being tested
+func connectionTestClient(info *social.OAuthInfo) (*http.Client, error) {
+	tr := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: info.TlsSkipVerify,
+		},
+	}
+
+	if info.TlsClientCert != "" || info.TlsClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(info.TlsClientCert, info.TlsClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup TlsClientCert: %w", err)
+		}
+		tr.TLSClientConfig.Certificates = append(tr.TLSClientConfig.Certificates, cert)
+	}
+
+	if info.TlsClientCa != "" {
+		caCert, err := os.ReadFile(info.TlsClientCa)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup TlsClientCa: %w", err)
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tr.TLSClientConfig.RootCAs = caCertPool
+	}
+
+	return &http.Client{Transport: tr, Timeout: connectionTestTimeout}, nil
+}
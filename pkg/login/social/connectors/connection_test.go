@@ -0,0 +1,111 @@
+package connectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	ssoModels "github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSocialBase_TestConnection(t *testing.T) {
+	// a stand-in for an OpenID Connect provider serving two realms, the second one without signing keys
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, realm := range []string{"grafana", "nokeys"} {
+			prefix := "/realms/" + realm
+			switch {
+			case r.URL.Path == prefix+"/protocol/openid-connect/auth" && r.Method == http.MethodGet:
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			case r.URL.Path == prefix+"/protocol/openid-connect/token" && r.Method == http.MethodPost:
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			case r.URL.Path == prefix+"/protocol/openid-connect/userinfo":
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			case r.URL.Path == prefix+"/.well-known/openid-configuration":
+				_, _ = w.Write([]byte(`{"token_endpoint": "` + server.URL + prefix + `/protocol/openid-connect/token", "jwks_uri": "` + server.URL + prefix + `/certs"}`))
+				return
+			case r.URL.Path == "/realms/grafana/certs":
+				_, _ = w.Write([]byte(`{"keys": [{"kty": "RSA", "kid": "key-1"}]}`))
+				return
+			case r.URL.Path == "/realms/nokeys/certs":
+				_, _ = w.Write([]byte(`{"keys": []}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	baseURL := server.URL + "/realms/grafana/protocol/openid-connect"
+	noKeysURL := server.URL + "/realms/nokeys/protocol/openid-connect"
+
+	testCases := []struct {
+		desc        string
+		settings    map[string]any
+		expectedErr string
+	}{
+		{
+			desc: "succeeds when the endpoints and the discovery document are valid",
+			settings: map[string]any{
+				"auth_url":  baseURL + "/auth",
+				"token_url": baseURL + "/token",
+				"api_url":   baseURL + "/userinfo",
+				"scopes":    "openid email",
+			},
+		},
+		{
+			desc: "succeeds without discovery when the openid scope is not requested",
+			settings: map[string]any{
+				"auth_url":  baseURL + "/auth",
+				"token_url": baseURL + "/token",
+			},
+		},
+		{
+			desc: "fails when the token URL has a typo",
+			settings: map[string]any{
+				"auth_url":  baseURL + "/auth",
+				"token_url": baseURL + "/tokne",
+			},
+			expectedErr: "token URL " + baseURL + "/tokne: unexpected response status code 404",
+		},
+		{
+			desc: "fails when the host cannot be reached",
+			settings: map[string]any{
+				"auth_url": "http://127.0.0.1:1/auth",
+			},
+			expectedErr: "auth URL http://127.0.0.1:1/auth",
+		},
+		{
+			desc: "fails when the issuer publishes no signing keys",
+			settings: map[string]any{
+				"auth_url":  noKeysURL + "/auth",
+				"token_url": noKeysURL + "/token",
+				"scopes":    "openid",
+			},
+			expectedErr: "no signing keys found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewGenericOAuthProvider(&social.OAuthInfo{}, &setting.Cfg{}, nil, &ssosettingstests.MockService{}, featuremgmt.WithFeatures())
+
+			err := s.TestConnection(context.Background(), ssoModels.SSOSettings{Settings: tc.settings})
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
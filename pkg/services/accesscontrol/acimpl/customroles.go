@@ -0,0 +1,200 @@
+package acimpl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var _ accesscontrol.CustomRoleService = new(Service)
+
+var errCustomRolesNotSupported = errors.New("custom roles are not supported by the access control store")
+
+func (s *Service) ListCustomRoles(ctx context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.ListCustomRoles")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return nil, errCustomRolesNotSupported
+	}
+	return s.customRoles.ListCustomRoles(ctx, orgID)
+}
+
+func (s *Service) GetCustomRole(ctx context.Context, orgID int64, uid string) (*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.GetCustomRole")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return nil, errCustomRolesNotSupported
+	}
+	return s.customRoles.GetCustomRole(ctx, orgID, uid)
+}
+
+func (s *Service) CreateCustomRole(ctx context.Context, orgID int64, cmd accesscontrol.SaveCustomRoleCommand) (*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.CreateCustomRole")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return nil, errCustomRolesNotSupported
+	}
+	if err := s.validateCustomRole(&cmd); err != nil {
+		return nil, err
+	}
+	// A new role has no assignments yet, there is nothing to invalidate
+	return s.customRoles.CreateCustomRole(ctx, orgID, cmd)
+}
+
+func (s *Service) UpdateCustomRole(ctx context.Context, orgID int64, cmd accesscontrol.SaveCustomRoleCommand) (*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.UpdateCustomRole")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return nil, errCustomRolesNotSupported
+	}
+	if cmd.UID == "" {
+		return nil, accesscontrol.ErrCustomRoleInvalid.Build(accesscontrol.ErrCustomRoleInvalidData("uid is required"))
+	}
+	if err := s.validateCustomRole(&cmd); err != nil {
+		return nil, err
+	}
+
+	role, err := s.customRoles.UpdateCustomRole(ctx, orgID, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.customRoles.GetCustomRoleAssignments(ctx, orgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	return role, s.clearCustomRoleCache(ctx, orgID, assignments)
+}
+
+func (s *Service) DeleteCustomRole(ctx context.Context, orgID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.DeleteCustomRole")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return errCustomRolesNotSupported
+	}
+
+	// Fetch the assignments first, they are removed together with the role
+	assignments, err := s.customRoles.GetCustomRoleAssignments(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if err := s.customRoles.DeleteCustomRole(ctx, orgID, uid); err != nil {
+		return err
+	}
+	return s.clearCustomRoleCache(ctx, orgID, assignments)
+}
+
+func (s *Service) GetCustomRoleAssignments(ctx context.Context, orgID int64, uid string) (*accesscontrol.CustomRoleAssignments, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.GetCustomRoleAssignments")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return nil, errCustomRolesNotSupported
+	}
+	return s.customRoles.GetCustomRoleAssignments(ctx, orgID, uid)
+}
+
+func (s *Service) AssignCustomRoleToUser(ctx context.Context, orgID, userID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.AssignCustomRoleToUser")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return errCustomRolesNotSupported
+	}
+	if err := s.customRoles.AssignCustomRoleToUser(ctx, orgID, userID, uid); err != nil {
+		return err
+	}
+	s.clearUserCache(orgID, userID)
+	return nil
+}
+
+func (s *Service) UnassignCustomRoleFromUser(ctx context.Context, orgID, userID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.UnassignCustomRoleFromUser")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return errCustomRolesNotSupported
+	}
+	if err := s.customRoles.UnassignCustomRoleFromUser(ctx, orgID, userID, uid); err != nil {
+		return err
+	}
+	s.clearUserCache(orgID, userID)
+	return nil
+}
+
+func (s *Service) AssignCustomRoleToTeam(ctx context.Context, orgID, teamID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.AssignCustomRoleToTeam")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return errCustomRolesNotSupported
+	}
+	if err := s.customRoles.AssignCustomRoleToTeam(ctx, orgID, teamID, uid); err != nil {
+		return err
+	}
+	return s.clearCustomRoleCache(ctx, orgID, &accesscontrol.CustomRoleAssignments{TeamIDs: []int64{teamID}})
+}
+
+func (s *Service) UnassignCustomRoleFromTeam(ctx context.Context, orgID, teamID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.UnassignCustomRoleFromTeam")
+	defer span.End()
+
+	if s.customRoles == nil {
+		return errCustomRolesNotSupported
+	}
+	if err := s.customRoles.UnassignCustomRoleFromTeam(ctx, orgID, teamID, uid); err != nil {
+		return err
+	}
+	return s.clearCustomRoleCache(ctx, orgID, &accesscontrol.CustomRoleAssignments{TeamIDs: []int64{teamID}})
+}
+
+// validateCustomRole validates the command and makes sure every permission is known to the registry
+func (s *Service) validateCustomRole(cmd *accesscontrol.SaveCustomRoleCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	for _, p := range cmd.Permissions {
+		if err := s.permRegistry.IsPermissionValid(p.Action, p.Scope); err != nil {
+			return accesscontrol.ErrCustomRoleInvalid.Build(accesscontrol.ErrCustomRoleInvalidData(err.Error()))
+		}
+	}
+	return nil
+}
+
+// clearCustomRoleCache removes the cached permissions of every identity that gets the role,
+// either directly or through a team.
+func (s *Service) clearCustomRoleCache(ctx context.Context, orgID int64, assignments *accesscontrol.CustomRoleAssignments) error {
+	members, err := s.customRoles.GetTeamMemberIDs(ctx, orgID, assignments.TeamIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, teamID := range assignments.TeamIDs {
+		s.cache.Delete(accesscontrol.GetTeamPermissionCacheKey(teamID, orgID))
+	}
+	for _, userIDs := range [][]int64{assignments.UserIDs, members} {
+		for _, userID := range userIDs {
+			s.clearUserCache(orgID, userID)
+		}
+	}
+	return nil
+}
+
+// clearUserCache clears the cache entries of a user id. The cache key depends on the identity
+// type, so entries of both a user and a service account with that id are removed.
+func (s *Service) clearUserCache(orgID, userID int64) {
+	for _, isServiceAccount := range []bool{false, true} {
+		s.ClearUserPermissionCache(&user.SignedInUser{
+			OrgID:            orgID,
+			UserID:           userID,
+			IsServiceAccount: isServiceAccount,
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package acimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_CustomRoles(t *testing.T) {
+	setup := func(t *testing.T) (*Service, *fakeCustomRoleStore) {
+		s := setupTestEnv(t)
+		store := &fakeCustomRoleStore{
+			assignments: &accesscontrol.CustomRoleAssignments{UserIDs: []int64{1}, TeamIDs: []int64{10}},
+			members:     []int64{2},
+		}
+		s.customRoles = store
+		require.NoError(t, s.permRegistry.RegisterPermission(accesscontrol.ActionAlertingRuleUpdate, "folders:*"))
+		return s, store
+	}
+
+	cacheKeys := func(s *Service) []string {
+		keys := []string{accesscontrol.GetTeamPermissionCacheKey(10, 1)}
+		for _, userID := range []int64{1, 2} {
+			for _, isServiceAccount := range []bool{false, true} {
+				usr := &user.SignedInUser{OrgID: 1, UserID: userID, IsServiceAccount: isServiceAccount}
+				keys = append(keys, accesscontrol.GetUserPermissionCacheKey(usr), accesscontrol.GetUserDirectPermissionCacheKey(usr))
+			}
+		}
+		for _, key := range keys {
+			s.cache.Set(key, []accesscontrol.Permission{}, cacheTTL)
+		}
+		return keys
+	}
+
+	cmd := accesscontrol.SaveCustomRoleCommand{
+		UID:         "alerteditor",
+		Name:        "custom:alert:editor",
+		Permissions: []accesscontrol.Permission{{Action: accesscontrol.ActionAlertingRuleUpdate, Scope: "folders:uid:abc"}},
+	}
+
+	t.Run("should reject unknown permissions", func(t *testing.T) {
+		s, _ := setup(t)
+		invalid := cmd
+		invalid.Permissions = []accesscontrol.Permission{{Action: "dashboards:fly"}}
+		_, err := s.CreateCustomRole(context.Background(), 1, invalid)
+		require.ErrorIs(t, err, accesscontrol.ErrCustomRoleInvalid)
+	})
+
+	t.Run("should clear the cache of every assignee on update", func(t *testing.T) {
+		s, store := setup(t)
+		keys := cacheKeys(s)
+
+		_, err := s.UpdateCustomRole(context.Background(), 1, cmd)
+		require.NoError(t, err)
+		assert.Equal(t, "update", store.called)
+		for _, key := range keys {
+			_, ok := s.cache.Get(key)
+			assert.False(t, ok, key)
+		}
+	})
+
+	t.Run("should clear the cache of every assignee on delete", func(t *testing.T) {
+		s, _ := setup(t)
+		keys := cacheKeys(s)
+
+		require.NoError(t, s.DeleteCustomRole(context.Background(), 1, cmd.UID))
+		for _, key := range keys {
+			_, ok := s.cache.Get(key)
+			assert.False(t, ok, key)
+		}
+	})
+
+	t.Run("should only clear the cache of the assigned user", func(t *testing.T) {
+		s, _ := setup(t)
+		cacheKeys(s)
+
+		require.NoError(t, s.AssignCustomRoleToUser(context.Background(), 1, 2, cmd.UID))
+		_, ok := s.cache.Get(accesscontrol.GetUserPermissionCacheKey(&user.SignedInUser{OrgID: 1, UserID: 2}))
+		assert.False(t, ok)
+		_, ok = s.cache.Get(accesscontrol.GetUserPermissionCacheKey(&user.SignedInUser{OrgID: 1, UserID: 1}))
+		assert.True(t, ok)
+	})
+}
+
+type fakeCustomRoleStore struct {
+	accesscontrol.CustomRoleStore
+	assignments *accesscontrol.CustomRoleAssignments
+	members     []int64
+	called      string
+}
+
+func (f *fakeCustomRoleStore) UpdateCustomRole(_ context.Context, _ int64, cmd accesscontrol.SaveCustomRoleCommand) (*accesscontrol.RoleDTO, error) {
+	f.called = "update"
+	return &accesscontrol.RoleDTO{UID: cmd.UID, Name: cmd.Name, Permissions: cmd.Permissions}, nil
+}
+
+func (f *fakeCustomRoleStore) DeleteCustomRole(context.Context, int64, string) error {
+	f.called = "delete"
+	return nil
+}
+
+func (f *fakeCustomRoleStore) AssignCustomRoleToUser(context.Context, int64, int64, string) error {
+	f.called = "assign"
+	return nil
+}
+
+func (f *fakeCustomRoleStore) GetCustomRoleAssignments(context.Context, int64, string) (*accesscontrol.CustomRoleAssignments, error) {
+	return f.assignments, nil
+}
+
+func (f *fakeCustomRoleStore) GetTeamMemberIDs(context.Context, int64, []int64) ([]int64, error) {
+	return f.members, nil
+}
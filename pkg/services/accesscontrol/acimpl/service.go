@@ -49,7 +49,7 @@ var SharedWithMeFolderPermission = accesscontrol.Permission{
 	Scope:  dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.SharedWithMeFolderUID),
 }
 
-var OSSRolesPrefixes = []string{accesscontrol.ManagedRolePrefix, accesscontrol.ExternalServiceRolePrefix}
+var OSSRolesPrefixes = []string{accesscontrol.ManagedRolePrefix, accesscontrol.ExternalServiceRolePrefix, accesscontrol.CustomRolePrefix}
 
 func ProvideService(
 	cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, cache *localcache.CacheService,
@@ -72,6 +72,7 @@ func ProvideService(
 	)
 
 	api.NewAccessControlAPI(routeRegister, accessControl, service, userService, features).RegisterAPIEndpoints()
+	api.NewCustomRolesAPI(routeRegister, accessControl, service).RegisterAPIEndpoints()
 	if err := accesscontrol.DeclareFixedRoles(service, cfg); err != nil {
 		return nil, err
 	}
@@ -105,6 +106,10 @@ func ProvideOSSService(
 		permRegistry:   permRegistry,
 	}
 
+	if customRoles, ok := store.(accesscontrol.CustomRoleStore); ok {
+		s.customRoles = customRoles
+	}
+
 	return s
 }
 
@@ -118,6 +123,7 @@ type Service struct {
 	registrations  accesscontrol.RegistrationList
 	roles          map[string]*accesscontrol.RoleDTO
 	store          accesscontrol.Store
+	customRoles    accesscontrol.CustomRoleStore
 	reconciler     *dualwrite.ZanzanaReconciler
 	permRegistry   permreg.PermissionRegistry
 }
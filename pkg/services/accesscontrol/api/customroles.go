@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func NewCustomRolesAPI(router routing.RouteRegister, accesscontrol ac.AccessControl, roles ac.CustomRoleService) *CustomRolesAPI {
+	return &CustomRolesAPI{
+		RouteRegister: router,
+		AccessControl: accesscontrol,
+		roles:         roles,
+	}
+}
+
+type CustomRolesAPI struct {
+	AccessControl ac.AccessControl
+	RouteRegister routing.RouteRegister
+	roles         ac.CustomRoleService
+}
+
+type assignCustomRoleCommand struct {
+	RoleUID string `json:"roleUid"`
+}
+
+func (api *CustomRolesAPI) RegisterAPIEndpoints() {
+	authorize := ac.Middleware(api.AccessControl)
+	roleScope := ac.ScopeRolesProvider.GetResourceScopeUID(ac.Parameter(":roleUID"))
+	userScope := ac.Scope("users", "id", ac.Parameter(":userId"))
+	teamScope := ac.Scope("teams", "id", ac.Parameter(":teamId"))
+
+	api.RouteRegister.Group("/api/access-control", func(rr routing.RouteRegister) {
+		rr.Get("/roles", authorize(ac.EvalPermission(ac.ActionRolesRead)), routing.Wrap(api.listRoles))
+		rr.Post("/roles", authorize(ac.EvalPermission(ac.ActionRolesWrite)), routing.Wrap(api.createRole))
+		rr.Get("/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionRolesRead, roleScope)), routing.Wrap(api.getRole))
+		rr.Put("/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionRolesWrite, roleScope)), routing.Wrap(api.updateRole))
+		rr.Delete("/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionRolesDelete, roleScope)), routing.Wrap(api.deleteRole))
+		rr.Get("/roles/:roleUID/assignments", authorize(ac.EvalAll(
+			ac.EvalPermission(ac.ActionRolesRead, roleScope),
+			ac.EvalPermission(ac.ActionUsersRolesRead),
+			ac.EvalPermission(ac.ActionTeamsRolesRead),
+		)), routing.Wrap(api.getRoleAssignments))
+
+		rr.Post("/users/:userId/roles", authorize(ac.EvalPermission(ac.ActionUsersRolesAdd, userScope)), routing.Wrap(api.addUserRole))
+		rr.Delete("/users/:userId/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionUsersRolesRemove, userScope)), routing.Wrap(api.removeUserRole))
+		rr.Post("/teams/:teamId/roles", authorize(ac.EvalPermission(ac.ActionTeamsRolesAdd, teamScope)), routing.Wrap(api.addTeamRole))
+		rr.Delete("/teams/:teamId/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionTeamsRolesRemove, teamScope)), routing.Wrap(api.removeTeamRole))
+	}, requestmeta.SetOwner(requestmeta.TeamAuth))
+}
+
+// GET /api/access-control/roles
+func (api *CustomRolesAPI) listRoles(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.listRoles")
+	defer span.End()
+
+	roles, err := api.roles.ListCustomRoles(ctx, c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list roles", err)
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+// GET /api/access-control/roles/:roleUID
+func (api *CustomRolesAPI) getRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.getRole")
+	defer span.End()
+
+	role, err := api.roles.GetCustomRole(ctx, c.SignedInUser.GetOrgID(), web.Params(c.Req)[":roleUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get role", err)
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+// POST /api/access-control/roles
+func (api *CustomRolesAPI) createRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.createRole")
+	defer span.End()
+
+	var cmd ac.SaveCustomRoleCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := api.checkEscalation(ctx, c.SignedInUser, cmd.Permissions); err != nil {
+		return response.Err(err)
+	}
+
+	role, err := api.roles.CreateCustomRole(ctx, c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create role", err)
+	}
+	return response.JSON(http.StatusCreated, role)
+}
+
+// PUT /api/access-control/roles/:roleUID
+func (api *CustomRolesAPI) updateRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.updateRole")
+	defer span.End()
+
+	var cmd ac.SaveCustomRoleCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":roleUID"]
+	if err := api.checkEscalation(ctx, c.SignedInUser, cmd.Permissions); err != nil {
+		return response.Err(err)
+	}
+
+	role, err := api.roles.UpdateCustomRole(ctx, c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update role", err)
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+// DELETE /api/access-control/roles/:roleUID
+func (api *CustomRolesAPI) deleteRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.deleteRole")
+	defer span.End()
+
+	if err := api.roles.DeleteCustomRole(ctx, c.SignedInUser.GetOrgID(), web.Params(c.Req)[":roleUID"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete role", err)
+	}
+	return response.Success("Role deleted")
+}
+
+// GET /api/access-control/roles/:roleUID/assignments
+func (api *CustomRolesAPI) getRoleAssignments(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.getRoleAssignments")
+	defer span.End()
+
+	assignments, err := api.roles.GetCustomRoleAssignments(ctx, c.SignedInUser.GetOrgID(), web.Params(c.Req)[":roleUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get role assignments", err)
+	}
+	return response.JSON(http.StatusOK, assignments)
+}
+
+// POST /api/access-control/users/:userId/roles
+func (api *CustomRolesAPI) addUserRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.addUserRole")
+	defer span.End()
+
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":userId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "userId is invalid", err)
+	}
+	var cmd assignCustomRoleCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if resp := api.checkRoleEscalation(ctx, c.SignedInUser, cmd.RoleUID); resp != nil {
+		return resp
+	}
+
+	if err := api.roles.AssignCustomRoleToUser(ctx, c.SignedInUser.GetOrgID(), userID, cmd.RoleUID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to assign role", err)
+	}
+	return response.Success("Role added to the user")
+}
+
+// DELETE /api/access-control/users/:userId/roles/:roleUID
+func (api *CustomRolesAPI) removeUserRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.removeUserRole")
+	defer span.End()
+
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":userId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "userId is invalid", err)
+	}
+
+	if err := api.roles.UnassignCustomRoleFromUser(ctx, c.SignedInUser.GetOrgID(), userID, web.Params(c.Req)[":roleUID"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove role", err)
+	}
+	return response.Success("Role removed from the user")
+}
+
+// POST /api/access-control/teams/:teamId/roles
+func (api *CustomRolesAPI) addTeamRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.addTeamRole")
+	defer span.End()
+
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+	var cmd assignCustomRoleCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if resp := api.checkRoleEscalation(ctx, c.SignedInUser, cmd.RoleUID); resp != nil {
+		return resp
+	}
+
+	if err := api.roles.AssignCustomRoleToTeam(ctx, c.SignedInUser.GetOrgID(), teamID, cmd.RoleUID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to assign role", err)
+	}
+	return response.Success("Role added to the team")
+}
+
+// DELETE /api/access-control/teams/:teamId/roles/:roleUID
+func (api *CustomRolesAPI) removeTeamRole(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.removeTeamRole")
+	defer span.End()
+
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	if err := api.roles.UnassignCustomRoleFromTeam(ctx, c.SignedInUser.GetOrgID(), teamID, web.Params(c.Req)[":roleUID"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove role", err)
+	}
+	return response.Success("Role removed from the team")
+}
+
+// checkRoleEscalation prevents assigning a role granting more than what the caller has
+func (api *CustomRolesAPI) checkRoleEscalation(ctx context.Context, user identity.Requester, roleUID string) response.Response {
+	role, err := api.roles.GetCustomRole(ctx, user.GetOrgID(), roleUID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get role", err)
+	}
+	if err := api.checkEscalation(ctx, user, role.Permissions); err != nil {
+		return response.Err(err)
+	}
+	return nil
+}
+
+// checkEscalation returns an error if the user does not hold every one of the permissions
+func (api *CustomRolesAPI) checkEscalation(ctx context.Context, user identity.Requester, permissions []ac.Permission) error {
+	for _, p := range permissions {
+		evaluator := ac.EvalPermission(p.Action)
+		if p.Scope != "" {
+			evaluator = ac.EvalPermission(p.Action, p.Scope)
+		}
+		hasAccess, err := api.AccessControl.Evaluate(ctx, user, evaluator)
+		if err != nil {
+			return err
+		}
+		if !hasAccess {
+			return ac.ErrCustomRoleEscalation.Errorf("user is missing permission %s on %q", p.Action, p.Scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestCustomRolesAPI(t *testing.T) {
+	alertEditor := &ac.RoleDTO{
+		UID:         "alerteditor",
+		Name:        "custom:alert:editor",
+		Permissions: []ac.Permission{{Action: ac.ActionAlertingRuleUpdate, Scope: "folders:*"}},
+	}
+
+	type testCase struct {
+		desc         string
+		method       string
+		url          string
+		body         string
+		permissions  map[string][]string
+		expectedCode int
+		expectedCall string
+	}
+
+	tests := []testCase{
+		{
+			desc:         "should list roles",
+			method:       http.MethodGet,
+			url:          "/api/access-control/roles",
+			permissions:  map[string][]string{ac.ActionRolesRead: {ac.ScopeRolesAll}},
+			expectedCode: http.StatusOK,
+			expectedCall: "list",
+		},
+		{
+			desc:         "should not list roles without permission",
+			method:       http.MethodGet,
+			url:          "/api/access-control/roles",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:   "should create a role with permissions the user has",
+			method: http.MethodPost,
+			url:    "/api/access-control/roles",
+			body:   `{"name": "custom:alert:editor", "permissions": [{"action": "alert.rules:write", "scope": "folders:uid:abc"}]}`,
+			permissions: map[string][]string{
+				ac.ActionRolesWrite:         {ac.ScopeRolesAll},
+				ac.ActionAlertingRuleUpdate: {"folders:*"},
+			},
+			expectedCode: http.StatusCreated,
+			expectedCall: "create",
+		},
+		{
+			desc:   "should not create a role with permissions the user does not have",
+			method: http.MethodPost,
+			url:    "/api/access-control/roles",
+			body:   `{"name": "custom:alert:editor", "permissions": [{"action": "alert.rules:write", "scope": "folders:*"}]}`,
+			permissions: map[string][]string{
+				ac.ActionRolesWrite:         {ac.ScopeRolesAll},
+				ac.ActionAlertingRuleUpdate: {"folders:uid:abc"},
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:   "should assign a role to a user",
+			method: http.MethodPost,
+			url:    "/api/access-control/users/2/roles",
+			body:   `{"roleUid": "alerteditor"}`,
+			permissions: map[string][]string{
+				ac.ActionUsersRolesAdd:      {"users:id:2"},
+				ac.ActionAlertingRuleUpdate: {"folders:*"},
+			},
+			expectedCode: http.StatusOK,
+			expectedCall: "assign user 2",
+		},
+		{
+			desc:   "should not assign a role to another user",
+			method: http.MethodPost,
+			url:    "/api/access-control/users/3/roles",
+			body:   `{"roleUid": "alerteditor"}`,
+			permissions: map[string][]string{
+				ac.ActionUsersRolesAdd:      {"users:id:2"},
+				ac.ActionAlertingRuleUpdate: {"folders:*"},
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:   "should not assign a role granting more than the user has",
+			method: http.MethodPost,
+			url:    "/api/access-control/teams/1/roles",
+			body:   `{"roleUid": "alerteditor"}`,
+			permissions: map[string][]string{
+				ac.ActionTeamsRolesAdd: {ac.ScopeTeamsAll},
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:   "should remove a role from a team",
+			method: http.MethodDelete,
+			url:    "/api/access-control/teams/1/roles/alerteditor",
+			permissions: map[string][]string{
+				ac.ActionTeamsRolesRemove: {ac.ScopeTeamsAll},
+			},
+			expectedCode: http.StatusOK,
+			expectedCall: "unassign team 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			roles := &fakeCustomRoleService{role: alertEditor}
+			api := NewCustomRolesAPI(routing.NewRouteRegister(), evaluatingAccessControl{}, roles)
+			api.RegisterAPIEndpoints()
+
+			server := webtest.NewServer(t, api.RouteRegister)
+			req := server.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgID:       1,
+				Permissions: map[int64]map[string][]string{1: tt.permissions},
+			})
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			assert.Equal(t, tt.expectedCall, roles.called)
+		})
+	}
+}
+
+// evaluatingAccessControl evaluates against the permissions of the signed in user
+type evaluatingAccessControl struct{}
+
+func (evaluatingAccessControl) Evaluate(_ context.Context, user identity.Requester, evaluator ac.Evaluator) (bool, error) {
+	return evaluator.Evaluate(user.GetPermissions()), nil
+}
+
+func (evaluatingAccessControl) RegisterScopeAttributeResolver(string, ac.ScopeAttributeResolver) {}
+
+func (a evaluatingAccessControl) WithoutResolvers() ac.AccessControl {
+	return a
+}
+
+type fakeCustomRoleService struct {
+	role   *ac.RoleDTO
+	called string
+}
+
+func (f *fakeCustomRoleService) ListCustomRoles(context.Context, int64) ([]*ac.RoleDTO, error) {
+	f.called = "list"
+	return []*ac.RoleDTO{f.role}, nil
+}
+
+func (f *fakeCustomRoleService) GetCustomRole(context.Context, int64, string) (*ac.RoleDTO, error) {
+	return f.role, nil
+}
+
+func (f *fakeCustomRoleService) CreateCustomRole(_ context.Context, _ int64, cmd ac.SaveCustomRoleCommand) (*ac.RoleDTO, error) {
+	f.called = "create"
+	return &ac.RoleDTO{Name: cmd.Name, Permissions: cmd.Permissions}, nil
+}
+
+func (f *fakeCustomRoleService) UpdateCustomRole(context.Context, int64, ac.SaveCustomRoleCommand) (*ac.RoleDTO, error) {
+	f.called = "update"
+	return f.role, nil
+}
+
+func (f *fakeCustomRoleService) DeleteCustomRole(context.Context, int64, string) error {
+	f.called = "delete"
+	return nil
+}
+
+func (f *fakeCustomRoleService) GetCustomRoleAssignments(context.Context, int64, string) (*ac.CustomRoleAssignments, error) {
+	return &ac.CustomRoleAssignments{}, nil
+}
+
+func (f *fakeCustomRoleService) AssignCustomRoleToUser(_ context.Context, _, userID int64, _ string) error {
+	f.called = "assign user " + strconv.FormatInt(userID, 10)
+	return nil
+}
+
+func (f *fakeCustomRoleService) UnassignCustomRoleFromUser(_ context.Context, _, userID int64, _ string) error {
+	f.called = "unassign user " + strconv.FormatInt(userID, 10)
+	return nil
+}
+
+func (f *fakeCustomRoleService) AssignCustomRoleToTeam(_ context.Context, _, teamID int64, _ string) error {
+	f.called = "assign team " + strconv.FormatInt(teamID, 10)
+	return nil
+}
+
+func (f *fakeCustomRoleService) UnassignCustomRoleFromTeam(_ context.Context, _, teamID int64, _ string) error {
+	f.called = "unassign team " + strconv.FormatInt(teamID, 10)
+	return nil
+}
@@ -0,0 +1,324 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var _ accesscontrol.CustomRoleStore = new(AccessControlStore)
+
+func (s *AccessControlStore) ListCustomRoles(ctx context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.ListCustomRoles")
+	defer span.End()
+
+	roles := make([]*accesscontrol.RoleDTO, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		var stored []accesscontrol.Role
+		if err := sess.Where("org_id = ? AND name LIKE ?", orgID, accesscontrol.CustomRolePrefix+"%").Asc("name").Find(&stored); err != nil {
+			return err
+		}
+		for i := range stored {
+			role, err := roleDTO(ctx, sess, &stored[i])
+			if err != nil {
+				return err
+			}
+			roles = append(roles, role)
+		}
+		return nil
+	})
+	return roles, err
+}
+
+func (s *AccessControlStore) GetCustomRole(ctx context.Context, orgID int64, uid string) (*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetCustomRole")
+	defer span.End()
+
+	var role *accesscontrol.RoleDTO
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		stored, err := getCustomRole(ctx, sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		role, err = roleDTO(ctx, sess, stored)
+		return err
+	})
+	return role, err
+}
+
+func (s *AccessControlStore) CreateCustomRole(ctx context.Context, orgID int64, cmd accesscontrol.SaveCustomRoleCommand) (*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.CreateCustomRole")
+	defer span.End()
+
+	var role *accesscontrol.RoleDTO
+	err := s.sql.InTransaction(ctx, func(ctx context.Context) error {
+		return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+			exists, err := sess.Where("uid = ? OR (org_id = ? AND name = ?)", cmd.UID, orgID, cmd.Name).Exist(&accesscontrol.Role{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return accesscontrol.ErrCustomRoleAlreadyExists.Errorf("role %s (%s) already exists", cmd.Name, cmd.UID)
+			}
+
+			now := time.Now()
+			stored, err := s.saveRole(ctx, sess, &accesscontrol.Role{
+				OrgID:       orgID,
+				Version:     1,
+				UID:         cmd.UID,
+				Name:        cmd.Name,
+				DisplayName: cmd.DisplayName,
+				Description: cmd.Description,
+				Group:       cmd.Group,
+				Created:     now,
+				Updated:     now,
+			})
+			if err != nil {
+				return err
+			}
+			if err := s.savePermissions(ctx, sess, stored.ID, cmd.Permissions); err != nil {
+				return err
+			}
+			role, err = roleDTO(ctx, sess, stored)
+			return err
+		})
+	})
+	return role, err
+}
+
+func (s *AccessControlStore) UpdateCustomRole(ctx context.Context, orgID int64, cmd accesscontrol.SaveCustomRoleCommand) (*accesscontrol.RoleDTO, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.UpdateCustomRole")
+	defer span.End()
+
+	var role *accesscontrol.RoleDTO
+	err := s.sql.InTransaction(ctx, func(ctx context.Context) error {
+		return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+			existing, err := getCustomRole(ctx, sess, orgID, cmd.UID)
+			if err != nil {
+				return err
+			}
+			if cmd.Version != 0 && cmd.Version != existing.Version {
+				return accesscontrol.ErrCustomRoleVersionMismatch.Errorf("role %s is at version %d, got %d", cmd.UID, existing.Version, cmd.Version)
+			}
+			if cmd.Name != existing.Name {
+				exists, err := sess.Where("org_id = ? AND name = ? AND id != ?", orgID, cmd.Name, existing.ID).Exist(&accesscontrol.Role{})
+				if err != nil {
+					return err
+				}
+				if exists {
+					return accesscontrol.ErrCustomRoleAlreadyExists.Errorf("role %s already exists", cmd.Name)
+				}
+			}
+
+			// The version is compared as part of the update to guard against concurrent writers
+			count, err := sess.Where("id = ? AND version = ?", existing.ID, existing.Version).
+				MustCols("display_name", "description", "group_name").
+				Update(&accesscontrol.Role{
+					Version:     existing.Version + 1,
+					Name:        cmd.Name,
+					DisplayName: cmd.DisplayName,
+					Description: cmd.Description,
+					Group:       cmd.Group,
+					Updated:     time.Now(),
+				})
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return accesscontrol.ErrCustomRoleVersionMismatch.Errorf("role %s was updated concurrently", cmd.UID)
+			}
+			if err := s.savePermissions(ctx, sess, existing.ID, cmd.Permissions); err != nil {
+				return err
+			}
+
+			stored, err := getCustomRole(ctx, sess, orgID, cmd.UID)
+			if err != nil {
+				return err
+			}
+			role, err = roleDTO(ctx, sess, stored)
+			return err
+		})
+	})
+	return role, err
+}
+
+func (s *AccessControlStore) DeleteCustomRole(ctx context.Context, orgID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.DeleteCustomRole")
+	defer span.End()
+
+	return s.sql.InTransaction(ctx, func(ctx context.Context) error {
+		return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+			stored, err := getCustomRole(ctx, sess, orgID, uid)
+			if err != nil {
+				return err
+			}
+
+			// Delete the assignments and the permissions before the role itself
+			for _, table := range []string{"user_role", "team_role", "builtin_role", "permission"} {
+				if _, err := sess.Exec("DELETE FROM "+table+" WHERE role_id = ?", stored.ID); err != nil {
+					return err
+				}
+			}
+			_, err = sess.Exec("DELETE FROM role WHERE id = ?", stored.ID)
+			return err
+		})
+	})
+}
+
+func (s *AccessControlStore) GetCustomRoleAssignments(ctx context.Context, orgID int64, uid string) (*accesscontrol.CustomRoleAssignments, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetCustomRoleAssignments")
+	defer span.End()
+
+	assignments := &accesscontrol.CustomRoleAssignments{UserIDs: []int64{}, TeamIDs: []int64{}}
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		stored, err := getCustomRole(ctx, sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		if err := sess.Table("user_role").Where("role_id = ? AND org_id = ?", stored.ID, orgID).Asc("user_id").Cols("user_id").Find(&assignments.UserIDs); err != nil {
+			return err
+		}
+		return sess.Table("team_role").Where("role_id = ? AND org_id = ?", stored.ID, orgID).Asc("team_id").Cols("team_id").Find(&assignments.TeamIDs)
+	})
+	return assignments, err
+}
+
+func (s *AccessControlStore) GetTeamMemberIDs(ctx context.Context, orgID int64, teamIDs []int64) ([]int64, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetTeamMemberIDs")
+	defer span.End()
+
+	userIDs := make([]int64, 0)
+	if len(teamIDs) == 0 {
+		return userIDs, nil
+	}
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("team_member").Where("org_id = ?", orgID).In("team_id", teamIDs).Distinct("user_id").Find(&userIDs)
+	})
+	return userIDs, err
+}
+
+func (s *AccessControlStore) AssignCustomRoleToUser(ctx context.Context, orgID, userID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.AssignCustomRoleToUser")
+	defer span.End()
+
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		stored, err := getCustomRole(ctx, sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		member, err := sess.Table("org_user").Where("org_id = ? AND user_id = ?", orgID, userID).Exist()
+		if err != nil {
+			return err
+		}
+		if !member {
+			return accesscontrol.ErrAssignmentEntityNotFound.Build(accesscontrol.ErrAssignmentEntityNotFoundData("user"))
+		}
+
+		assignment := accesscontrol.UserRole{OrgID: orgID, RoleID: stored.ID, UserID: userID}
+		exists, err := sess.Exist(&assignment)
+		if err != nil || exists {
+			return err
+		}
+		assignment.Created = time.Now()
+		_, err = sess.Insert(&assignment)
+		return err
+	})
+}
+
+func (s *AccessControlStore) UnassignCustomRoleFromUser(ctx context.Context, orgID, userID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.UnassignCustomRoleFromUser")
+	defer span.End()
+
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		stored, err := getCustomRole(ctx, sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM user_role WHERE org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, stored.ID)
+		return err
+	})
+}
+
+func (s *AccessControlStore) AssignCustomRoleToTeam(ctx context.Context, orgID, teamID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.AssignCustomRoleToTeam")
+	defer span.End()
+
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		stored, err := getCustomRole(ctx, sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		exists, err := sess.Table("team").Where("org_id = ? AND id = ?", orgID, teamID).Exist()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return accesscontrol.ErrAssignmentEntityNotFound.Build(accesscontrol.ErrAssignmentEntityNotFoundData("team"))
+		}
+
+		assignment := accesscontrol.TeamRole{OrgID: orgID, RoleID: stored.ID, TeamID: teamID}
+		exists, err = sess.Exist(&assignment)
+		if err != nil || exists {
+			return err
+		}
+		assignment.Created = time.Now()
+		_, err = sess.Insert(&assignment)
+		return err
+	})
+}
+
+func (s *AccessControlStore) UnassignCustomRoleFromTeam(ctx context.Context, orgID, teamID int64, uid string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.UnassignCustomRoleFromTeam")
+	defer span.End()
+
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		stored, err := getCustomRole(ctx, sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM team_role WHERE org_id = ? AND team_id = ? AND role_id = ?", orgID, teamID, stored.ID)
+		return err
+	})
+}
+
+// getCustomRole returns the role if it exists in the organization and is a custom role.
+// Fixed, managed and other internal roles are reported as not found.
+func getCustomRole(ctx context.Context, sess *db.Session, orgID int64, uid string) (*accesscontrol.Role, error) {
+	stored, err := getRoleByUID(ctx, sess, uid)
+	if err != nil {
+		if errors.Is(err, accesscontrol.ErrRoleNotFound) {
+			return nil, accesscontrol.ErrCustomRoleNotFound.Errorf("role %s not found", uid)
+		}
+		return nil, err
+	}
+	if stored.OrgID != orgID || !stored.IsCustom() {
+		return nil, accesscontrol.ErrCustomRoleNotFound.Errorf("role %s not found", uid)
+	}
+	return stored, nil
+}
+
+func roleDTO(ctx context.Context, sess *db.Session, role *accesscontrol.Role) (*accesscontrol.RoleDTO, error) {
+	permissions, err := getRolePermissions(ctx, sess, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range permissions {
+		permissions[i] = accesscontrol.Permission{Action: permissions[i].Action, Scope: permissions[i].Scope}
+	}
+	return &accesscontrol.RoleDTO{
+		ID:          role.ID,
+		OrgID:       role.OrgID,
+		Version:     role.Version,
+		UID:         role.UID,
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Group:       role.Group,
+		Permissions: permissions,
+		Created:     role.Created,
+		Updated:     role.Updated,
+	}, nil
+}
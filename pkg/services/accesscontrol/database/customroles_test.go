@@ -0,0 +1,98 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+func TestAccessControlStore_CustomRoles(t *testing.T) {
+	ctx := context.Background()
+	store, _, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, team := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	cmd := accesscontrol.SaveCustomRoleCommand{
+		UID:  "alerteditor",
+		Name: "custom:alert:editor",
+		Permissions: []accesscontrol.Permission{
+			{Action: accesscontrol.ActionAlertingRuleRead, Scope: "folders:*"},
+			{Action: accesscontrol.ActionAlertingRuleUpdate, Scope: "folders:*"},
+		},
+	}
+	require.NoError(t, cmd.Validate())
+
+	role, err := store.CreateCustomRole(ctx, 1, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), role.Version)
+	assert.Len(t, role.Permissions, 2)
+
+	_, err = store.CreateCustomRole(ctx, 1, cmd)
+	require.ErrorIs(t, err, accesscontrol.ErrCustomRoleAlreadyExists)
+
+	t.Run("custom roles are scoped to their organization", func(t *testing.T) {
+		_, err := store.GetCustomRole(ctx, 2, cmd.UID)
+		require.ErrorIs(t, err, accesscontrol.ErrCustomRoleNotFound)
+
+		roles, err := store.ListCustomRoles(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, cmd.UID, roles[0].UID)
+	})
+
+	t.Run("assignments grant the role permissions", func(t *testing.T) {
+		require.NoError(t, store.AssignCustomRoleToUser(ctx, 1, user.ID, cmd.UID))
+		require.NoError(t, store.AssignCustomRoleToUser(ctx, 1, user.ID, cmd.UID))
+		require.NoError(t, store.AssignCustomRoleToTeam(ctx, 1, team.ID, cmd.UID))
+
+		err := store.AssignCustomRoleToTeam(ctx, 1, team.ID+100, cmd.UID)
+		require.ErrorIs(t, err, accesscontrol.ErrAssignmentEntityNotFound)
+
+		assignments, err := store.GetCustomRoleAssignments(ctx, 1, cmd.UID)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{user.ID}, assignments.UserIDs)
+		assert.Equal(t, []int64{team.ID}, assignments.TeamIDs)
+
+		members, err := store.GetTeamMemberIDs(ctx, 1, assignments.TeamIDs)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{user.ID}, members)
+
+		permissions, err := store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{
+			OrgID:        1,
+			UserID:       user.ID,
+			RolePrefixes: []string{accesscontrol.CustomRolePrefix},
+		})
+		require.NoError(t, err)
+		assert.Len(t, permissions, 2)
+	})
+
+	t.Run("update checks the version and replaces permissions", func(t *testing.T) {
+		update := cmd
+		update.Version = 1
+		update.Permissions = []accesscontrol.Permission{{Action: accesscontrol.ActionAlertingRuleRead, Scope: "folders:*"}}
+		role, err := store.UpdateCustomRole(ctx, 1, update)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), role.Version)
+		assert.Equal(t, update.Permissions, role.Permissions)
+
+		_, err = store.UpdateCustomRole(ctx, 1, update)
+		require.ErrorIs(t, err, accesscontrol.ErrCustomRoleVersionMismatch)
+	})
+
+	t.Run("delete removes the role and its assignments", func(t *testing.T) {
+		require.NoError(t, store.DeleteCustomRole(ctx, 1, cmd.UID))
+
+		_, err := store.GetCustomRole(ctx, 1, cmd.UID)
+		require.ErrorIs(t, err, accesscontrol.ErrCustomRoleNotFound)
+
+		permissions, err := store.GetTeamsPermissions(ctx, accesscontrol.GetUserPermissionsQuery{
+			OrgID:   1,
+			TeamIDs: []int64{team.ID},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, permissions[team.ID])
+	})
+}
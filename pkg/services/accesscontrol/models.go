@@ -55,6 +55,10 @@ func (r *Role) IsFixed() bool {
 	return strings.HasPrefix(r.Name, FixedRolePrefix)
 }
 
+func (r *Role) IsCustom() bool {
+	return strings.HasPrefix(r.Name, CustomRolePrefix)
+}
+
 func (r *Role) IsBasic() bool {
 	return strings.HasPrefix(r.Name, BasicRolePrefix) || strings.HasPrefix(r.UID, BasicRoleUIDPrefix)
 }
@@ -138,6 +142,10 @@ func (r *RoleDTO) IsPlugin() bool {
 	return strings.HasPrefix(r.Name, PluginRolePrefix)
 }
 
+func (r *RoleDTO) IsCustom() bool {
+	return strings.HasPrefix(r.Name, CustomRolePrefix)
+}
+
 func (r *RoleDTO) IsBasic() bool {
 	return strings.HasPrefix(r.Name, BasicRolePrefix) || strings.HasPrefix(r.UID, BasicRoleUIDPrefix)
 }
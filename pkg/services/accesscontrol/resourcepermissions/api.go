@@ -1,6 +1,7 @@
 package resourcepermissions
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,7 +12,9 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/team"
@@ -278,10 +281,12 @@ func (a *api) setUserPermission(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
+	a.recordPermissions(c.Req.Context(), c.SignedInUser, resourceID, auditlog.RecordBefore)
 	_, err = a.service.SetUserPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), accesscontrol.User{ID: userID}, resourceID, cmd.Permission)
 	if err != nil {
 		return response.Err(err)
 	}
+	a.recordPermissions(c.Req.Context(), c.SignedInUser, resourceID, auditlog.RecordAfter)
 
 	return permissionSetResponse(cmd)
 }
@@ -335,10 +340,12 @@ func (a *api) setTeamPermission(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
+	a.recordPermissions(c.Req.Context(), c.SignedInUser, resourceID, auditlog.RecordBefore)
 	_, err = a.service.SetTeamPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), teamID, resourceID, cmd.Permission)
 	if err != nil {
 		return response.Err(err)
 	}
+	a.recordPermissions(c.Req.Context(), c.SignedInUser, resourceID, auditlog.RecordAfter)
 
 	return permissionSetResponse(cmd)
 }
@@ -389,10 +396,12 @@ func (a *api) setBuiltinRolePermission(c *contextmodel.ReqContext) response.Resp
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
+	a.recordPermissions(c.Req.Context(), c.SignedInUser, resourceID, auditlog.RecordBefore)
 	_, err := a.service.SetBuiltInRolePermission(c.Req.Context(), c.SignedInUser.GetOrgID(), builtInRole, resourceID, cmd.Permission)
 	if err != nil {
 		return response.Err(err)
 	}
+	a.recordPermissions(c.Req.Context(), c.SignedInUser, resourceID, auditlog.RecordAfter)
 
 	return permissionSetResponse(cmd)
 }
@@ -437,14 +446,45 @@ func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "Bad request data: "+err.Error(), err)
 	}
 
+	a.recordPermissions(ctx, c.SignedInUser, resourceID, auditlog.RecordBefore)
 	_, err := a.service.SetPermissions(ctx, c.SignedInUser.GetOrgID(), resourceID, cmd.Permissions...)
 	if err != nil {
 		return response.Err(err)
 	}
+	a.recordPermissions(ctx, c.SignedInUser, resourceID, auditlog.RecordAfter)
 
 	return response.Success("Permissions updated")
 }
 
+// recordPermissions records the permissions granted directly on the resource as the before or after
+// state of an audited request. They are keyed by the user login, the team name or the basic role.
+func (a *api) recordPermissions(ctx context.Context, requester identity.Requester, resourceID string, record func(context.Context, any)) {
+	if !auditlog.Recording(ctx) {
+		return
+	}
+	permissions, err := a.service.GetPermissions(ctx, requester, resourceID)
+	if err != nil {
+		return
+	}
+
+	state := make(map[string]string, len(permissions))
+	for _, p := range permissions {
+		permission := a.service.MapActions(p)
+		if permission == "" || p.IsInherited {
+			continue
+		}
+		switch {
+		case p.UserID != 0:
+			state["user:"+p.UserLogin] = permission
+		case p.TeamID != 0:
+			state["team:"+p.Team] = permission
+		case p.BuiltInRole != "":
+			state["role:"+p.BuiltInRole] = permission
+		}
+	}
+	record(ctx, state)
+}
+
 func permissionSetResponse(cmd setPermissionCommand) response.Response {
 	message := "Permission updated"
 	if cmd.Permission == "" {
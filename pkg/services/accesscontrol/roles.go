@@ -24,6 +24,8 @@ const (
 
 	ManagedRolePrefix = "managed:"
 
+	CustomRolePrefix = "custom:"
+
 	PluginRolePrefix = "plugins:"
 
 	BasicRoleNoneUID  = "basic_none"
@@ -293,6 +295,33 @@ var (
 		},
 	}
 
+	rolesReaderRole = RoleDTO{
+		Name:        "fixed:roles:reader",
+		DisplayName: "Custom roles reader",
+		Description: "List custom roles and see who they are assigned to.",
+		Group:       "Access control",
+		Permissions: []Permission{
+			{Action: ActionRolesRead, Scope: ScopeRolesAll},
+			{Action: ActionUsersRolesRead, Scope: ScopeUsersAll},
+			{Action: ActionTeamsRolesRead, Scope: ScopeTeamsAll},
+		},
+	}
+
+	rolesWriterRole = RoleDTO{
+		Name:        "fixed:roles:writer",
+		DisplayName: "Custom roles writer",
+		Description: "Create, update and delete custom roles and assign them to users, service accounts and teams.",
+		Group:       "Access control",
+		Permissions: ConcatPermissions(rolesReaderRole.Permissions, []Permission{
+			{Action: ActionRolesWrite, Scope: ScopeRolesAll},
+			{Action: ActionRolesDelete, Scope: ScopeRolesAll},
+			{Action: ActionUsersRolesAdd, Scope: ScopeUsersAll},
+			{Action: ActionUsersRolesRemove, Scope: ScopeUsersAll},
+			{Action: ActionTeamsRolesAdd, Scope: ScopeTeamsAll},
+			{Action: ActionTeamsRolesRemove, Scope: ScopeTeamsAll},
+		}),
+	}
+
 	usagestatsReaderRole = RoleDTO{
 		Name:        "fixed:usagestats:reader",
 		DisplayName: "Usage stats report reader",
@@ -353,10 +382,20 @@ func DeclareFixedRoles(service Service, cfg *setting.Cfg) error {
 		Grants: []string{RoleGrafanaAdmin},
 	}
 
+	rolesReader := RoleRegistration{
+		Role:   rolesReaderRole,
+		Grants: []string{RoleGrafanaAdmin, string(org.RoleAdmin)},
+	}
+	rolesWriter := RoleRegistration{
+		Role:   rolesWriterRole,
+		Grants: []string{RoleGrafanaAdmin, string(org.RoleAdmin)},
+	}
+
 	return service.DeclareFixedRoles(
 		ldapReader, ldapWriter, orgUsersReader, orgUsersWriter,
 		settingsReader, statsReader, usersReader, usersWriter,
 		authenticationConfigWriter, generalAuthConfigWriter, usageStatsReader,
+		rolesReader, rolesWriter,
 	)
 }
 
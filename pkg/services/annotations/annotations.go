@@ -17,6 +17,9 @@ var (
 type Repository interface {
 	Save(ctx context.Context, item *Item) error
 	SaveMany(ctx context.Context, items []Item) error
+	// Import saves the annotation unless an annotation was imported with the same key in its organization already,
+	// in which case the item gets the ID of that annotation. It returns whether the annotation was saved.
+	Import(ctx context.Context, item *Item, key string) (bool, error)
 	Update(ctx context.Context, item *Item) error
 	Find(ctx context.Context, query *ItemQuery) ([]*ItemDTO, error)
 	Delete(ctx context.Context, params *DeleteParams) error
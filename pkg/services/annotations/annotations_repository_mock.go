@@ -72,6 +72,27 @@ func (_m *FakeAnnotationsRepo) FindTags(ctx context.Context, query *TagsQuery) (
 	return r0, r1
 }
 
+// Import provides a mock function with given fields: ctx, item, key
+func (_m *FakeAnnotationsRepo) Import(ctx context.Context, item *Item, key string) (bool, error) {
+	ret := _m.Called(ctx, item, key)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *Item, string) bool); ok {
+		r0 = rf(ctx, item, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *Item, string) error); ok {
+		r1 = rf(ctx, item, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, item
 func (_m *FakeAnnotationsRepo) Save(ctx context.Context, item *Item) error {
 	ret := _m.Called(ctx, item)
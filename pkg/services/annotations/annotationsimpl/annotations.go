@@ -69,6 +69,10 @@ func (r *RepositoryImpl) SaveMany(ctx context.Context, items []annotations.Item)
 	return r.writer.AddMany(ctx, items)
 }
 
+func (r *RepositoryImpl) Import(ctx context.Context, item *annotations.Item, key string) (bool, error) {
+	return r.writer.Import(ctx, item, key)
+}
+
 func (r *RepositoryImpl) Update(ctx context.Context, item *annotations.Item) error {
 	return r.writer.Update(ctx, item)
 }
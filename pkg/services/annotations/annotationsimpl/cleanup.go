@@ -2,20 +2,24 @@ package annotationsimpl
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 // CleanupServiceImpl is responsible for cleaning old annotations.
 type CleanupServiceImpl struct {
-	store store
+	store   store
+	dialect migrator.Dialect
 }
 
 func ProvideCleanupService(db db.DB, cfg *setting.Cfg) *CleanupServiceImpl {
 	return &CleanupServiceImpl{
-		store: NewXormStore(cfg, log.New("annotations"), db, nil),
+		store:   NewXormStore(cfg, log.New("annotations"), db, nil),
+		dialect: db.GetDialect(),
 	}
 }
 
@@ -25,32 +29,63 @@ const (
 	apiAnnotationType       = "alert_id = 0 AND dashboard_id = 0"
 )
 
-// Run deletes old annotations created by alert rules, API
-// requests and human made in the UI. It subsequently deletes orphaned rows
-// from the annotation_tag table. Cleanup actions are performed in batches
-// so that no query takes too long to complete.
+// Run deletes old annotations matched by the retention rules, then the ones
+// created by alert rules, API requests and human made in the UI. It
+// subsequently deletes orphaned rows from the annotation_tag table. Cleanup
+// actions are performed in batches so that no query takes too long to complete.
+//
+// An annotation is only cleaned up according to the first retention rule it
+// matches, or to the settings of its type if it matches none.
 //
 // Returns the number of annotation and annotation_tag rows deleted. If an
 // error occurs, it returns the number of rows affected so far.
 func (cs *CleanupServiceImpl) Run(ctx context.Context, cfg *setting.Cfg) (int64, int64, error) {
 	var totalCleanedAnnotations int64
-	affected, err := cs.store.CleanAnnotations(ctx, cfg.AlertingAnnotationCleanupSetting, alertAnnotationType)
-	totalCleanedAnnotations += affected
-	if err != nil {
-		return totalCleanedAnnotations, 0, err
-	}
 
-	affected, err = cs.store.CleanAnnotations(ctx, cfg.APIAnnotationCleanupSettings, apiAnnotationType)
-	totalCleanedAnnotations += affected
-	if err != nil {
-		return totalCleanedAnnotations, 0, err
+	// the annotations matched by the previous rules
+	excluded := ""
+	excludedArgs := make([]any, 0)
+	for _, rule := range cfg.AnnotationRetentionRules {
+		cond, args := retentionRuleCondition(cs.dialect, rule)
+		filter := annotationCleanupFilter{
+			Where: cond + excluded,
+			Args:  append(append([]any{}, args...), excludedArgs...),
+		}
+		if rule.ArchivePath != "" {
+			filter.Archive = archiveAnnotations(rule)
+		}
+
+		affected, err := cs.store.CleanMatchingAnnotations(ctx, rule.AnnotationCleanupSettings, filter)
+		totalCleanedAnnotations += affected
+		if err != nil {
+			return totalCleanedAnnotations, 0, fmt.Errorf("retention rule %s: %w", rule.Name, err)
+		}
+
+		excluded += " AND NOT " + cond
+		excludedArgs = append(excludedArgs, args...)
 	}
 
-	affected, err = cs.store.CleanAnnotations(ctx, cfg.DashboardAnnotationCleanupSettings, dashboardAnnotationType)
-	totalCleanedAnnotations += affected
-	if err != nil {
-		return totalCleanedAnnotations, 0, err
+	types := []struct {
+		settings       setting.AnnotationCleanupSettings
+		annotationType string
+	}{
+		{cfg.AlertingAnnotationCleanupSetting, alertAnnotationType},
+		{cfg.APIAnnotationCleanupSettings, apiAnnotationType},
+		{cfg.DashboardAnnotationCleanupSettings, dashboardAnnotationType},
+	}
+	for _, t := range types {
+		affected, err := cs.store.CleanMatchingAnnotations(ctx, t.settings, annotationCleanupFilter{
+			Where: t.annotationType + excluded,
+			Args:  excludedArgs,
+		})
+		totalCleanedAnnotations += affected
+		if err != nil {
+			return totalCleanedAnnotations, 0, err
+		}
 	}
+
+	var affected int64
+	var err error
 	if totalCleanedAnnotations > 0 {
 		affected, err = cs.store.CleanOrphanedAnnotationTags(ctx)
 	}
@@ -2,16 +2,24 @@ package annotationsimpl
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/tag"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -294,3 +302,198 @@ func createTestAnnotations(t *testing.T, store db.DB, expectedCount int, oldAnno
 func settingsFn(maxAge time.Duration, maxCount int64) setting.AnnotationCleanupSettings {
 	return setting.AnnotationCleanupSettings{MaxAge: maxAge, MaxCount: maxCount}
 }
+
+func TestIntegrationAnnotationRetentionRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	fakeSQL := db.InitTestDB(t)
+	now := time.Now()
+	old := now.AddDate(0, 0, -10)
+
+	var deployTag, incidentTag tag.Tag
+	err := fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+		deployTag = tag.Tag{Key: "deploy"}
+		incidentTag = tag.Tag{Key: "severity", Value: "critical"}
+		for _, tg := range []*tag.Tag{&deployTag, &incidentTag} {
+			if _, err := sess.Table("tag").Insert(tg); err != nil {
+				return err
+			}
+		}
+		for _, d := range []*dashboards.Dashboard{
+			{ID: 1, UID: "in-folder", OrgID: 1, FolderUID: "ops", Title: "In folder", Slug: "in-folder", Data: simplejson.New(), Created: now, Updated: now},
+			{ID: 2, UID: "general", OrgID: 1, Title: "General", Slug: "general", Data: simplejson.New(), Created: now, Updated: now},
+		} {
+			if _, err := sess.Insert(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	insert := func(t *testing.T, dashboardID int64, created time.Time, tags ...tag.Tag) int64 {
+		t.Helper()
+		a := &annotations.Item{OrgID: 1, DashboardID: dashboardID, Created: created.UnixNano() / int64(time.Millisecond)}
+		err := fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+			if _, err := sess.Insert(a); err != nil {
+				return err
+			}
+			for _, tg := range tags {
+				if _, err := sess.Insert(&annotationTag{AnnotationID: a.ID, TagID: tg.Id}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return a.ID
+	}
+
+	// created first, they would be pushed out by the deploy annotations without a retention rule
+	incidents := []int64{insert(t, 0, old, incidentTag), insert(t, 0, old, incidentTag)}
+	deploys := make([]int64, 0)
+	for i := 0; i < 5; i++ {
+		deploys = append(deploys, insert(t, 0, now, deployTag))
+	}
+	other := []int64{insert(t, 0, now), insert(t, 0, now)}
+	oldInFolder := insert(t, 1, old)
+	oldInGeneral := insert(t, 2, old)
+
+	archivePath := t.TempDir()
+	cfg := setting.NewCfg()
+	cfg.AnnotationCleanupJobBatchSize = 2
+	cfg.APIAnnotationCleanupSettings = settingsFn(0, 1)
+	cfg.AnnotationRetentionRules = []setting.AnnotationRetentionRule{
+		{Name: "incidents", Tags: []string{"severity:critical"}},
+		{Name: "deploys", Tags: []string{"deploy"}, Source: setting.AnnotationSourceAPI, AnnotationCleanupSettings: settingsFn(0, 2), ArchivePath: archivePath},
+		{Name: "ops", FolderUIDs: []string{"ops"}, AnnotationCleanupSettings: settingsFn(24*time.Hour, 0)},
+	}
+
+	cleaner := ProvideCleanupService(fakeSQL, cfg)
+	affected, affectedTags, err := cleaner.Run(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), affected)
+	assert.Equal(t, int64(3), affectedTags)
+
+	var remaining []int64
+	err = fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return sess.SQL("SELECT id FROM annotation ORDER BY id").Find(&remaining)
+	})
+	require.NoError(t, err)
+	expected := append(append([]int64{}, incidents...), deploys[3], deploys[4], other[1], oldInGeneral)
+	assert.Equal(t, expected, remaining)
+	assert.NotContains(t, remaining, oldInFolder)
+
+	t.Run("annotations are archived before they are deleted", func(t *testing.T) {
+		files, err := os.ReadDir(archivePath)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, fmt.Sprintf("annotations-deploys-%s.jsonl", time.Now().UTC().Format(time.DateOnly)), files[0].Name())
+
+		content, err := os.ReadFile(filepath.Join(archivePath, files[0].Name()))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		require.Len(t, lines, 3)
+
+		archived := make([]int64, 0, len(lines))
+		for _, line := range lines {
+			var item annotations.Item
+			require.NoError(t, json.Unmarshal([]byte(line), &item))
+			archived = append(archived, item.ID)
+		}
+		assert.ElementsMatch(t, deploys[:3], archived)
+	})
+
+	t.Run("annotations are not deleted when they cannot be archived", func(t *testing.T) {
+		deploy := insert(t, 0, now, deployTag)
+		blocked := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(blocked, nil, 0o600))
+		cfg.AnnotationRetentionRules[1].ArchivePath = blocked
+
+		_, _, err := cleaner.Run(context.Background(), cfg)
+		require.ErrorContains(t, err, "retention rule deploys")
+		assertAnnotationCount(t, fakeSQL, fmt.Sprintf("id = %d", deploy), 1)
+	})
+}
+
+func TestIntegrationAnnotationRetentionRulesAcrossOrgs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	fakeSQL := db.InitTestDB(t)
+	now := time.Now()
+	old := now.AddDate(0, 0, -10)
+
+	// both orgs have a dashboard with the same uid in a folder with the same uid
+	err := fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+		for _, d := range []*dashboards.Dashboard{
+			{ID: 1, UID: "shared", OrgID: 1, FolderUID: "ops", Title: "Shared", Slug: "shared", Data: simplejson.New(), Created: now, Updated: now},
+			{ID: 2, UID: "shared", OrgID: 2, FolderUID: "ops", Title: "Shared", Slug: "shared", Data: simplejson.New(), Created: now, Updated: now},
+		} {
+			if _, err := sess.Insert(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	insert := func(t *testing.T, orgID, dashboardID int64) int64 {
+		t.Helper()
+		a := &annotations.Item{OrgID: orgID, DashboardID: dashboardID, Created: old.UnixNano() / int64(time.Millisecond)}
+		err := fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(a)
+			return err
+		})
+		require.NoError(t, err)
+		return a.ID
+	}
+
+	testCases := []struct {
+		name       string
+		rule       setting.AnnotationRetentionRule
+		keepInOrg2 bool
+	}{
+		{name: "dashboard rule", rule: setting.AnnotationRetentionRule{Name: "shared", DashboardUIDs: []string{"shared"}}},
+		{name: "folder rule", rule: setting.AnnotationRetentionRule{Name: "ops", FolderUIDs: []string{"ops"}}},
+		{name: "dashboard rule of an org", rule: setting.AnnotationRetentionRule{Name: "shared", OrgID: 1, DashboardUIDs: []string{"shared"}}, keepInOrg2: true},
+		{name: "folder rule of an org", rule: setting.AnnotationRetentionRule{Name: "ops", OrgID: 1, FolderUIDs: []string{"ops"}}, keepInOrg2: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+				_, err := sess.Exec("DELETE FROM annotation")
+				return err
+			})
+			require.NoError(t, err)
+
+			inOrg1 := insert(t, 1, 1)
+			inOrg2 := insert(t, 2, 2)
+			// an annotation of org 2 referencing the dashboard of org 1 is not matched by the uid of that dashboard
+			crossOrg := insert(t, 2, 1)
+
+			cfg := setting.NewCfg()
+			cfg.AnnotationCleanupJobBatchSize = 10
+			tc.rule.AnnotationCleanupSettings = settingsFn(24*time.Hour, 0)
+			cfg.AnnotationRetentionRules = []setting.AnnotationRetentionRule{tc.rule}
+
+			_, _, err = ProvideCleanupService(fakeSQL, cfg).Run(context.Background(), cfg)
+			require.NoError(t, err)
+
+			var remaining []int64
+			err = fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+				return sess.SQL("SELECT id FROM annotation ORDER BY id").Find(&remaining)
+			})
+			require.NoError(t, err)
+			assert.NotContains(t, remaining, inOrg1)
+			expected := []int64{crossOrg}
+			if tc.keepInOrg2 {
+				expected = []int64{inOrg2, crossOrg}
+			}
+			assert.Equal(t, expected, remaining)
+		})
+	}
+}
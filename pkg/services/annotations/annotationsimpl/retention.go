@@ -0,0 +1,99 @@
+package annotationsimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/tag"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// retentionRuleCondition returns the condition on the annotation table matching the annotations of the rule.
+func retentionRuleCondition(dialect migrator.Dialect, rule setting.AnnotationRetentionRule) (string, []any) {
+	where := make([]string, 0)
+	args := make([]any, 0)
+
+	if rule.OrgID != 0 {
+		where = append(where, "org_id = ?")
+		args = append(args, rule.OrgID)
+	}
+
+	switch rule.Source {
+	case setting.AnnotationSourceAlert:
+		where = append(where, alertAnnotationType)
+	case setting.AnnotationSourceAPI:
+		where = append(where, apiAnnotationType)
+	case setting.AnnotationSourceDashboard:
+		where = append(where, dashboardAnnotationType)
+	}
+
+	// uids are only unique in an org, the dashboards are matched in the org of the annotation
+	if len(rule.DashboardUIDs) > 0 {
+		where = append(where, "dashboard_id IN (SELECT id FROM dashboard WHERE dashboard.org_id = annotation.org_id AND uid IN (?"+strings.Repeat(",?", len(rule.DashboardUIDs)-1)+"))")
+		for _, uid := range rule.DashboardUIDs {
+			args = append(args, uid)
+		}
+	}
+
+	if len(rule.FolderUIDs) > 0 {
+		where = append(where, "dashboard_id IN (SELECT id FROM dashboard WHERE dashboard.org_id = annotation.org_id AND folder_uid IN (?"+strings.Repeat(",?", len(rule.FolderUIDs)-1)+"))")
+		for _, uid := range rule.FolderUIDs {
+			args = append(args, uid)
+		}
+	}
+
+	if tags := tag.ParseTagPairs(rule.Tags); len(tags) > 0 {
+		tagFilters := make([]string, 0, len(tags))
+		for _, t := range tags {
+			if t.Value == "" {
+				tagFilters = append(tagFilters, "(tag."+dialect.Quote("key")+" = ?)")
+				args = append(args, t.Key)
+			} else {
+				tagFilters = append(tagFilters, "(tag."+dialect.Quote("key")+" = ? AND tag."+dialect.Quote("value")+" = ?)")
+				args = append(args, t.Key, t.Value)
+			}
+		}
+		where = append(where, fmt.Sprintf(`EXISTS (SELECT 1 FROM annotation_tag at INNER JOIN tag ON tag.id = at.tag_id WHERE at.annotation_id = annotation.id AND (%s))`, strings.Join(tagFilters, " OR ")))
+	}
+
+	return "(" + strings.Join(where, " AND ") + ")", args
+}
+
+// archiveAnnotations returns a function appending the annotations to a file of the directory, one JSON object
+// per line. A file is created every day for each rule.
+func archiveAnnotations(rule setting.AnnotationRetentionRule) func(ctx context.Context, items []*annotations.Item) error {
+	return func(ctx context.Context, items []*annotations.Item) error {
+		if err := os.MkdirAll(rule.ArchivePath, 0o750); err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("annotations-%s-%s.jsonl", rule.Name, timeNow().UTC().Format(time.DateOnly))
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the path comes from the configuration of the retention rule
+		f, err := os.OpenFile(filepath.Join(rule.ArchivePath, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(f)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				_ = f.Close()
+				return err
+			}
+		}
+		// the annotations are deleted only if the archive is complete
+		if err := f.Sync(); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
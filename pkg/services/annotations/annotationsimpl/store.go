@@ -28,8 +28,19 @@ type writeStore interface {
 	commonStore
 	Add(ctx context.Context, items *annotations.Item) error
 	AddMany(ctx context.Context, items []annotations.Item) error
+	Import(ctx context.Context, item *annotations.Item, key string) (bool, error)
 	Update(ctx context.Context, item *annotations.Item) error
 	Delete(ctx context.Context, params *annotations.DeleteParams) error
 	CleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error)
+	CleanMatchingAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, filter annotationCleanupFilter) (int64, error)
 	CleanOrphanedAnnotationTags(ctx context.Context) (int64, error)
 }
+
+// annotationCleanupFilter selects the annotations a cleanup applies to.
+type annotationCleanupFilter struct {
+	// Where is the condition on the annotation table, and Args its arguments
+	Where string
+	Args  []any
+	// Archive is called with the annotations before they are deleted, if set. They are not deleted if it fails.
+	Archive func(ctx context.Context, items []*annotations.Item) error
+}
@@ -133,6 +133,52 @@ func (r *xormRepositoryImpl) AddMany(ctx context.Context, items []annotations.It
 	})
 }
 
+func (r *xormRepositoryImpl) Import(ctx context.Context, item *annotations.Item, key string) (bool, error) {
+	if key == "" {
+		return true, r.Add(ctx, item)
+	}
+
+	saved := false
+	err := r.db.InTransaction(ctx, func(ctx context.Context) error {
+		return r.db.WithDbSession(ctx, func(sess *db.Session) error {
+			existing := annotationImport{}
+			has, err := sess.Where("org_id = ? AND import_key = ?", item.OrgID, key).Get(&existing)
+			if err != nil {
+				return err
+			}
+			if has {
+				exists, err := sess.Table("annotation").Where("id = ?", existing.AnnotationID).Exist()
+				if err != nil {
+					return err
+				}
+				if exists {
+					item.ID = existing.AnnotationID
+					return nil
+				}
+				// the annotation was deleted since it was imported, import it again
+				if _, err := sess.ID(existing.ID).Delete(&annotationImport{}); err != nil {
+					return err
+				}
+			}
+
+			if err := r.Add(ctx, item); err != nil {
+				return err
+			}
+			if _, err := sess.Insert(&annotationImport{
+				OrgID:        item.OrgID,
+				ImportKey:    key,
+				AnnotationID: item.ID,
+				Created:      timeNow().Unix(),
+			}); err != nil {
+				return err
+			}
+			saved = true
+			return nil
+		})
+	})
+	return saved, err
+}
+
 func (r *xormRepositoryImpl) Update(ctx context.Context, item *annotations.Item) error {
 	return r.db.InTransaction(ctx, func(ctx context.Context) error {
 		return r.update(ctx, item)
@@ -527,6 +573,10 @@ func (r *xormRepositoryImpl) validateTagsLength(item *annotations.Item) error {
 }
 
 func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error) {
+	return r.CleanMatchingAnnotations(ctx, cfg, annotationCleanupFilter{Where: annotationType})
+}
+
+func (r *xormRepositoryImpl) CleanMatchingAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, filter annotationCleanupFilter) (int64, error) {
 	var totalAffected int64
 	if cfg.MaxAge > 0 {
 		cutoffDate := timeNow().Add(-cfg.MaxAge).UnixNano() / int64(time.Millisecond)
@@ -536,13 +586,8 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 		//
 		// We execute the following batched operation repeatedly until either we run out of objects, the context is cancelled, or there is an error.
 		affected, err := untilDoneOrCancelled(ctx, func() (int64, error) {
-			cond := fmt.Sprintf(`%s AND created < %v ORDER BY id DESC %s`, annotationType, cutoffDate, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
-			ids, err := r.fetchIDs(ctx, "annotation", cond)
-			if err != nil {
-				return 0, err
-			}
-
-			return r.deleteByIDs(ctx, "annotation", ids)
+			cond := fmt.Sprintf(`%s AND created < %v ORDER BY id DESC %s`, filter.Where, cutoffDate, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
+			return r.cleanAnnotationsBatch(ctx, cond, filter)
 		})
 		totalAffected += affected
 		if err != nil {
@@ -553,13 +598,8 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 	if cfg.MaxCount > 0 {
 		// Similar strategy as the above cleanup process, to avoid deadlocks.
 		affected, err := untilDoneOrCancelled(ctx, func() (int64, error) {
-			cond := fmt.Sprintf(`%s ORDER BY id DESC %s`, annotationType, r.db.GetDialect().LimitOffset(r.cfg.AnnotationCleanupJobBatchSize, cfg.MaxCount))
-			ids, err := r.fetchIDs(ctx, "annotation", cond)
-			if err != nil {
-				return 0, err
-			}
-
-			return r.deleteByIDs(ctx, "annotation", ids)
+			cond := fmt.Sprintf(`%s ORDER BY id DESC %s`, filter.Where, r.db.GetDialect().LimitOffset(r.cfg.AnnotationCleanupJobBatchSize, cfg.MaxCount))
+			return r.cleanAnnotationsBatch(ctx, cond, filter)
 		})
 		totalAffected += affected
 		if err != nil {
@@ -570,6 +610,43 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 	return totalAffected, nil
 }
 
+// cleanAnnotationsBatch deletes the annotations matching the condition, after archiving them if the filter requires it.
+func (r *xormRepositoryImpl) cleanAnnotationsBatch(ctx context.Context, cond string, filter annotationCleanupFilter) (int64, error) {
+	ids, err := r.fetchIDs(ctx, "annotation", cond, filter.Args...)
+	if err != nil {
+		return 0, err
+	}
+
+	if filter.Archive != nil && len(ids) > 0 {
+		items, err := r.getByIDs(ctx, ids)
+		if err != nil {
+			return 0, err
+		}
+		if err := filter.Archive(ctx, items); err != nil {
+			return 0, fmt.Errorf("failed to archive annotations: %w", err)
+		}
+	}
+
+	return r.deleteByIDs(ctx, "annotation", ids)
+}
+
+func (r *xormRepositoryImpl) getByIDs(ctx context.Context, ids []int64) ([]*annotations.Item, error) {
+	// stay below the parameter limit of SQLite
+	const chunkSize = 500
+	items := make([]*annotations.Item, 0, len(ids))
+	err := r.db.WithDbSession(ctx, func(session *db.Session) error {
+		for i := 0; i < len(ids); i += chunkSize {
+			chunk := make([]*annotations.Item, 0)
+			if err := session.Table("annotation").In("id", asAny(ids[i:min(i+chunkSize, len(ids))])...).Asc("id").Find(&chunk); err != nil {
+				return err
+			}
+			items = append(items, chunk...)
+		}
+		return nil
+	})
+	return items, err
+}
+
 func (r *xormRepositoryImpl) CleanOrphanedAnnotationTags(ctx context.Context) (int64, error) {
 	return untilDoneOrCancelled(ctx, func() (int64, error) {
 		cond := fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM annotation a WHERE annotation_id = a.id) %s`, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
@@ -582,7 +659,7 @@ func (r *xormRepositoryImpl) CleanOrphanedAnnotationTags(ctx context.Context) (i
 	})
 }
 
-func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition string) ([]int64, error) {
+func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition string, args ...any) ([]int64, error) {
 	sql := fmt.Sprintf(`SELECT id FROM %s`, table)
 	if condition == "" {
 		return nil, fmt.Errorf("condition must be supplied; cannot fetch IDs from entire table")
@@ -590,7 +667,7 @@ func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition stri
 	sql += fmt.Sprintf(` WHERE %s`, condition)
 	ids := make([]int64, 0)
 	err := r.db.WithDbSession(ctx, func(session *db.Session) error {
-		return session.SQL(sql).Find(&ids)
+		return session.SQL(sql, args...).Find(&ids)
 	})
 	return ids, err
 }
@@ -661,6 +738,18 @@ func untilDoneOrCancelled(ctx context.Context, batchWork func() (int64, error))
 	}
 }
 
+type annotationImport struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	ImportKey    string `xorm:"import_key"`
+	AnnotationID int64  `xorm:"annotation_id"`
+	Created      int64
+}
+
+func (annotationImport) TableName() string {
+	return "annotation_import"
+}
+
 type annotationTag struct {
 	AnnotationID int64 `xorm:"annotation_id"`
 	TagID        int64 `xorm:"tag_id"`
@@ -574,6 +574,72 @@ func TestIntegrationAnnotations(t *testing.T) {
 	})
 }
 
+func TestIntegrationAnnotationImport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sql := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.AnnotationMaximumTagsLength = 60
+	store := NewXormStore(cfg, log.New("annotation.test"), sql, tagimpl.ProvideService(sql))
+	ctx := context.Background()
+
+	count := func(t *testing.T) int64 {
+		t.Helper()
+		var count int64
+		err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+			var err error
+			count, err = sess.Table("annotation").Count()
+			return err
+		})
+		require.NoError(t, err)
+		return count
+	}
+
+	item := &annotations.Item{OrgID: 1, Epoch: 10, EpochEnd: 20, Text: "outage", Tags: []string{"incident"}}
+	saved, err := store.Import(ctx, item, "incident-1")
+	require.NoError(t, err)
+	assert.True(t, saved)
+	id := item.ID
+
+	t.Run("annotations with the same key are imported once", func(t *testing.T) {
+		again := &annotations.Item{OrgID: 1, Epoch: 10, EpochEnd: 20, Text: "outage"}
+		saved, err := store.Import(ctx, again, "incident-1")
+		require.NoError(t, err)
+		assert.False(t, saved)
+		assert.Equal(t, id, again.ID)
+		assert.Equal(t, int64(1), count(t))
+	})
+
+	t.Run("keys are unique per organization", func(t *testing.T) {
+		other := &annotations.Item{OrgID: 2, Epoch: 10, Text: "outage"}
+		saved, err := store.Import(ctx, other, "incident-1")
+		require.NoError(t, err)
+		assert.True(t, saved)
+		assert.NotEqual(t, id, other.ID)
+	})
+
+	t.Run("annotations without key are always imported", func(t *testing.T) {
+		before := count(t)
+		for i := 0; i < 2; i++ {
+			saved, err := store.Import(ctx, &annotations.Item{OrgID: 1, Epoch: 10, Text: "outage"}, "")
+			require.NoError(t, err)
+			assert.True(t, saved)
+		}
+		assert.Equal(t, before+2, count(t))
+	})
+
+	t.Run("deleted annotations are imported again", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, &annotations.DeleteParams{OrgID: 1, ID: id}))
+
+		again := &annotations.Item{OrgID: 1, Epoch: 10, Text: "outage"}
+		saved, err := store.Import(ctx, again, "incident-1")
+		require.NoError(t, err)
+		assert.True(t, saved)
+		assert.NotEqual(t, id, again.ID)
+	})
+}
+
 func BenchmarkFindTags_10k(b *testing.B) {
 	benchmarkFindTags(b, 10000)
 }
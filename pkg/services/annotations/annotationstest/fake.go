@@ -10,11 +10,13 @@ import (
 type fakeAnnotationsRepo struct {
 	mtx         sync.Mutex
 	annotations map[int64]annotations.Item
+	importKeys  map[string]int64
 }
 
 func NewFakeAnnotationsRepo() *fakeAnnotationsRepo {
 	return &fakeAnnotationsRepo{
 		annotations: map[int64]annotations.Item{},
+		importKeys:  map[string]int64{},
 	}
 }
 
@@ -61,6 +63,27 @@ func (repo *fakeAnnotationsRepo) SaveMany(ctx context.Context, items []annotatio
 	return nil
 }
 
+func (repo *fakeAnnotationsRepo) Import(ctx context.Context, item *annotations.Item, key string) (bool, error) {
+	repo.mtx.Lock()
+	if id, ok := repo.importKeys[key]; ok && key != "" {
+		repo.mtx.Unlock()
+		item.ID = id
+		return false, nil
+	}
+	repo.mtx.Unlock()
+
+	if err := repo.Save(ctx, item); err != nil {
+		return false, err
+	}
+
+	repo.mtx.Lock()
+	defer repo.mtx.Unlock()
+	if key != "" {
+		repo.importKeys[key] = item.ID
+	}
+	return true, nil
+}
+
 func (repo *fakeAnnotationsRepo) Update(_ context.Context, item *annotations.Item) error {
 	return nil
 }
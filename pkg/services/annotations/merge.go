@@ -0,0 +1,72 @@
+package annotations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RegionMerge is a region annotation extended to cover the overlapping regions merged into it.
+type RegionMerge struct {
+	// Region is the earliest of the overlapping regions, with the time range and the texts of all of them
+	Region *ItemDTO
+	// Merged are the regions to delete once merged into Region
+	Merged []*ItemDTO
+}
+
+// MergeRegions finds the region annotations of the same dashboard panel with the same tags that overlap,
+// and merges them into the earliest one. Alert annotations and point annotations are left as they are.
+func MergeRegions(items []*ItemDTO) []*RegionMerge {
+	groups := make(map[string][]*ItemDTO)
+	keys := make([]string, 0)
+	for _, item := range items {
+		if item.AlertID != 0 || item.TimeEnd <= item.Time {
+			continue
+		}
+
+		tags := append([]string{}, item.Tags...)
+		sort.Strings(tags)
+		key := fmt.Sprintf("%d/%d/%q", item.DashboardID, item.PanelID, tags)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+
+	merges := make([]*RegionMerge, 0)
+	for _, key := range keys {
+		regions := groups[key]
+		sort.SliceStable(regions, func(i, j int) bool {
+			if regions[i].Time == regions[j].Time {
+				return regions[i].ID < regions[j].ID
+			}
+			return regions[i].Time < regions[j].Time
+		})
+
+		var current *RegionMerge
+		texts := make(map[string]bool)
+		for _, region := range regions {
+			if current != nil && region.Time <= current.Region.TimeEnd {
+				current.Region.TimeEnd = max(current.Region.TimeEnd, region.TimeEnd)
+				if region.Text != "" && !texts[region.Text] {
+					texts[region.Text] = true
+					current.Region.Text = strings.TrimPrefix(current.Region.Text+"\n"+region.Text, "\n")
+				}
+				current.Merged = append(current.Merged, region)
+				continue
+			}
+
+			if current != nil && len(current.Merged) > 0 {
+				merges = append(merges, current)
+			}
+			merged := *region
+			current = &RegionMerge{Region: &merged}
+			texts = map[string]bool{region.Text: true}
+		}
+		if current != nil && len(current.Merged) > 0 {
+			merges = append(merges, current)
+		}
+	}
+
+	return merges
+}
@@ -0,0 +1,41 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRegions(t *testing.T) {
+	items := []*ItemDTO{
+		{ID: 1, PanelID: 1, Time: 10, TimeEnd: 20, Text: "deploy", Tags: []string{"deploy", "api"}},
+		{ID: 2, PanelID: 1, Time: 20, TimeEnd: 25, Text: "deploy", Tags: []string{"api", "deploy"}},
+		{ID: 3, PanelID: 1, Time: 12, TimeEnd: 40, Text: "rollback", Tags: []string{"deploy", "api"}},
+		// not overlapping
+		{ID: 4, PanelID: 1, Time: 41, TimeEnd: 50, Text: "deploy", Tags: []string{"deploy", "api"}},
+		// other tags
+		{ID: 5, PanelID: 1, Time: 10, TimeEnd: 20, Text: "deploy", Tags: []string{"deploy"}},
+		// other panel
+		{ID: 6, PanelID: 2, Time: 10, TimeEnd: 20, Text: "deploy", Tags: []string{"deploy", "api"}},
+		// alert and point annotations
+		{ID: 7, PanelID: 1, AlertID: 1, Time: 10, TimeEnd: 20, Tags: []string{"deploy", "api"}},
+		{ID: 8, PanelID: 1, Time: 15, TimeEnd: 15, Tags: []string{"deploy", "api"}},
+		{ID: 9, PanelID: 2, Time: 5, TimeEnd: 15, Text: "deploy", Tags: []string{"api", "deploy"}},
+	}
+
+	merges := MergeRegions(items)
+	require.Len(t, merges, 2)
+
+	assert.Equal(t, int64(1), merges[0].Region.ID)
+	assert.Equal(t, int64(10), merges[0].Region.Time)
+	assert.Equal(t, int64(40), merges[0].Region.TimeEnd)
+	assert.Equal(t, "deploy\nrollback", merges[0].Region.Text)
+	assert.Equal(t, []*ItemDTO{items[2], items[1]}, merges[0].Merged)
+
+	assert.Equal(t, int64(9), merges[1].Region.ID)
+	assert.Equal(t, int64(20), merges[1].Region.TimeEnd)
+	assert.Equal(t, []*ItemDTO{items[5]}, merges[1].Merged)
+
+	assert.Equal(t, int64(20), items[0].TimeEnd, "the annotations should not be modified")
+}
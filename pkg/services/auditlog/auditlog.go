@@ -0,0 +1,114 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+const (
+	ActionRead = "auditlogs:read"
+)
+
+const (
+	SinkDatabase = "database"
+	SinkFile     = "file"
+	SinkSyslog   = "syslog"
+)
+
+var (
+	ErrSearchNotAvailable = errutil.BadRequest("auditlog.searchNotAvailable").Errorf("audit log search requires the database sink")
+	ErrInvalidQuery       = errutil.ValidationFailed("auditlog.invalidQuery")
+)
+
+type Service interface {
+	// Record writes the entry to every configured sink.
+	Record(ctx context.Context, entry *Entry) error
+	// Search returns a page of entries stored by the database sink, newest first.
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+}
+
+// Sink is a destination audit log entries are written to.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, entry *Entry) error
+}
+
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+)
+
+// Entry is a single security relevant action.
+type Entry struct {
+	ID         int64     `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID      int64     `json:"orgId" xorm:"org_id"`
+	ActorUID   string    `json:"actorUid" xorm:"actor_uid"`
+	ActorLogin string    `json:"actorLogin" xorm:"actor_login"`
+	Action     string    `json:"action" xorm:"action"`
+	Resource   string    `json:"resource" xorm:"resource"`
+	ResourceID string    `json:"resourceId" xorm:"resource_id"`
+	Method     string    `json:"method" xorm:"method"`
+	Path       string    `json:"path" xorm:"path"`
+	StatusCode int       `json:"statusCode" xorm:"status_code"`
+	Result     Result    `json:"result" xorm:"result"`
+	IPAddress  string    `json:"ipAddress" xorm:"ip_address"`
+	UserAgent  string    `json:"userAgent" xorm:"user_agent"`
+	Before     JSONText  `json:"before,omitempty" xorm:"state_before"`
+	After      JSONText  `json:"after,omitempty" xorm:"state_after"`
+	Diff       JSONText  `json:"diff,omitempty" xorm:"diff"`
+	Created    time.Time `json:"created" xorm:"'created'"`
+}
+
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// JSONText is a JSON document kept as text. It is embedded as is when the entry is marshaled.
+type JSONText string
+
+func (t JSONText) MarshalJSON() ([]byte, error) {
+	if t == "" {
+		return []byte("null"), nil
+	}
+	if json.Valid([]byte(t)) {
+		return []byte(t), nil
+	}
+	return json.Marshal(string(t))
+}
+
+func (t *JSONText) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = ""
+		return nil
+	}
+	*t = JSONText(data)
+	return nil
+}
+
+type SearchQuery struct {
+	// OrgID limits the search to a single organization, 0 searches all of them
+	OrgID      int64
+	ActorUID   string
+	ActorLogin string
+	// Action matches the action exactly, or every action of a resource when it ends with ":*"
+	Action     string
+	Resource   string
+	ResourceID string
+	Result     Result
+	From       time.Time
+	To         time.Time
+	Page       int
+	Limit      int
+}
+
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Entries    []*Entry `json:"entries"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
@@ -0,0 +1,135 @@
+package auditlogimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+type auditLogAPI struct {
+	service       auditlog.Service
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+}
+
+func newAPI(service auditlog.Service, accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *auditLogAPI {
+	return &auditLogAPI{
+		service:       service,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+	}
+}
+
+func (api *auditLogAPI) registerAPIEndpoints() {
+	authorize := accesscontrol.Middleware(api.accessControl)
+	api.routeRegister.Group("/api/audit-logs", func(router routing.RouteRegister) {
+		router.Get("/", authorize(accesscontrol.EvalPermission(auditlog.ActionRead)), routing.Wrap(api.search))
+	})
+}
+
+// swagger:route GET /audit-logs audit_log searchAuditLogs
+//
+// Search the audit log.
+//
+// Returns the entries matching the filters, newest first. Time ranges are given in epoch milliseconds.
+//
+// Responses:
+// 200: searchAuditLogsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *auditLogAPI) search(c *contextmodel.ReqContext) response.Response {
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	query := &auditlog.SearchQuery{
+		OrgID:      c.QueryInt64("orgId"),
+		ActorUID:   c.Query("actorUid"),
+		ActorLogin: c.Query("actorLogin"),
+		Action:     c.Query("action"),
+		Resource:   c.Query("resource"),
+		ResourceID: c.Query("resourceId"),
+		Result:     auditlog.Result(c.Query("result")),
+		Page:       page,
+		Limit:      perPage,
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+
+	result, err := api.service.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search audit log", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters searchAuditLogs
+type SearchAuditLogsParams struct {
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	ActorUID string `json:"actorUid"`
+	// in:query
+	// required:false
+	ActorLogin string `json:"actorLogin"`
+	// Exact action, or every action of a resource with resource:*
+	// in:query
+	// required:false
+	Action string `json:"action"`
+	// in:query
+	// required:false
+	Resource string `json:"resource"`
+	// in:query
+	// required:false
+	ResourceID string `json:"resourceId"`
+	// in:query
+	// required:false
+	// enum: success,failure
+	Result string `json:"result"`
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// in:query
+	// required:false
+	// default: 1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default: 100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:response searchAuditLogsResponse
+type SearchAuditLogsResponse struct {
+	// in:body
+	Body auditlog.SearchResult `json:"body"`
+}
@@ -0,0 +1,48 @@
+package auditlogimpl
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fileSink appends the entries as JSON lines to a file, which is rotated
+// the same way as the Grafana log file.
+type fileSink struct {
+	writer *log.FileLogWriter
+}
+
+func newFileSink(cfg *setting.Cfg) (*fileSink, error) {
+	path := cfg.AuditLog.FilePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfg.LogsPath, path)
+	}
+
+	w := log.NewFileWriter()
+	w.Filename = path
+	w.Maxlines = 0
+	w.Maxsize = 1 << uint(cfg.AuditLog.FileMaxSizeShift)
+	w.Daily = cfg.AuditLog.FileDailyRotate
+	w.Maxdays = cfg.AuditLog.FileMaxDays
+	if err := w.StartLogger(); err != nil {
+		return nil, err
+	}
+	return &fileSink{writer: w}, nil
+}
+
+func (s *fileSink) Name() string {
+	return auditlog.SinkFile
+}
+
+func (s *fileSink) Write(_ context.Context, entry *auditlog.Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
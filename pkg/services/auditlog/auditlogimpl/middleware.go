@@ -0,0 +1,277 @@
+package auditlogimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// auditedMethods maps the http methods that change state to the verb used in the action
+var auditedMethods = map[string]string{
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// addressingSegments are static route segments that only tell how a resource is addressed,
+// like /api/datasources/uid/:uid, they are left out of the resource name
+var addressingSegments = map[string]bool{
+	"uid":  true,
+	"id":   true,
+	"name": true,
+	"db":   true,
+}
+
+// sensitiveKeys are matched against lower cased JSON keys, values of matching keys are redacted
+var sensitiveKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"apikey",
+	"api_key",
+	"privatekey",
+	"private_key",
+	"credential",
+	"securejsondata",
+	"secure_json_data",
+}
+
+// Middleware records every mutating API call once it has been handled.
+func (s *Service) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqCtx := contexthandler.FromContext(r.Context())
+			if reqCtx == nil || !s.shouldAudit(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body := s.readBody(reqCtx.Req)
+			ctx, recorder := auditlog.WithRecorder(reqCtx.Req.Context())
+			// This modifies both r and reqCtx.Req since they point to the same value
+			*reqCtx.Req = *reqCtx.Req.WithContext(ctx)
+
+			next.ServeHTTP(w, reqCtx.Req)
+
+			// The route is only known once the router has matched the request
+			route, ok := middleware.RouteOperationName(reqCtx.Req)
+			if !ok {
+				return
+			}
+
+			entry := s.entryFromRequest(reqCtx, route, body, recorder)
+			// Do not lose the entry if the client has gone away in the meantime
+			if err := s.Record(context.WithoutCancel(ctx), entry); err != nil {
+				reqCtx.Logger.Error("Failed to record audit log entry", "action", entry.Action, "error", err)
+			}
+		})
+	}
+}
+
+func (s *Service) shouldAudit(r *http.Request) bool {
+	if _, ok := auditedMethods[r.Method]; !ok {
+		return false
+	}
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		return false
+	}
+	for _, prefix := range s.cfg.AuditLog.ExcludePaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// readBody returns a copy of a JSON request body and puts the body back on the request.
+// Bodies larger than the configured max size are not kept.
+func (s *Service) readBody(r *http.Request) []byte {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasSuffix(mediaType, "json") {
+		return nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r.Body, s.cfg.AuditLog.MaxBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+
+	if err != nil || int64(len(buf)) > s.cfg.AuditLog.MaxBodySize {
+		return nil
+	}
+	return buf
+}
+
+func (s *Service) entryFromRequest(c *contextmodel.ReqContext, route string, body []byte, recorder *auditlog.Recorder) *auditlog.Entry {
+	resource, resourceID := resourceFromRoute(route, web.Params(c.Req))
+	before, after, recordedID := recorder.State()
+	if recordedID != "" {
+		resourceID = recordedID
+	}
+
+	entry := &auditlog.Entry{
+		Action:     resource + ":" + auditedMethods[c.Req.Method],
+		Resource:   resource,
+		ResourceID: resourceID,
+		Method:     c.Req.Method,
+		Path:       c.Req.URL.Path,
+		StatusCode: c.Resp.Status(),
+		Result:     auditlog.ResultSuccess,
+		IPAddress:  c.RemoteAddr(),
+		UserAgent:  truncate(c.Req.UserAgent(), 255),
+	}
+	if entry.StatusCode >= http.StatusBadRequest {
+		entry.Result = auditlog.ResultFailure
+	}
+	if c.IsSignedIn && c.SignedInUser != nil {
+		entry.OrgID = c.SignedInUser.GetOrgID()
+		entry.ActorUID = c.SignedInUser.GetUID()
+		entry.ActorLogin = c.SignedInUser.GetLogin()
+	}
+
+	if before != nil {
+		entry.Before = marshalState(before)
+	}
+	if after != nil {
+		entry.After = marshalState(after)
+	} else if body != nil {
+		entry.After = redactJSON(body)
+	}
+	entry.Diff = diff(entry.Before, entry.After)
+
+	return entry
+}
+
+// resourceFromRoute names the resource after the static segments of the route, and
+// takes its identifier from the last parameter, /api/folders/:uid/permissions gives
+// folders.permissions and the folder uid.
+func resourceFromRoute(route string, params map[string]string) (string, string) {
+	segments := make([]string, 0)
+	lastParam := ""
+	for _, segment := range strings.Split(strings.TrimPrefix(route, "/api/"), "/") {
+		switch {
+		case segment == "" || segment == "*":
+			continue
+		case strings.HasPrefix(segment, ":"):
+			lastParam, _, _ = strings.Cut(segment, "(")
+		case addressingSegments[segment]:
+			continue
+		default:
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "."), params[lastParam]
+}
+
+func marshalState(state any) auditlog.JSONText {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return ""
+	}
+	return redactJSON(data)
+}
+
+// redactJSON replaces the values of sensitive keys, documents that are not
+// valid JSON are dropped since they cannot be redacted.
+func redactJSON(data []byte) auditlog.JSONText {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return ""
+	}
+	redacted, err := json.Marshal(redact(v))
+	if err != nil {
+		return ""
+	}
+	return auditlog.JSONText(redacted)
+}
+
+func redact(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if isSensitive(k) {
+				val[k] = setting.RedactedPassword
+				continue
+			}
+			val[k] = redact(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = redact(item)
+		}
+	}
+	return v
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+type change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// diff compares the top level keys of two JSON objects and returns the ones that changed.
+func diff(before, after auditlog.JSONText) auditlog.JSONText {
+	if before == "" || after == "" {
+		return ""
+	}
+
+	var b, a map[string]any
+	if err := json.Unmarshal([]byte(before), &b); err != nil {
+		return ""
+	}
+	if err := json.Unmarshal([]byte(after), &a); err != nil {
+		return ""
+	}
+
+	changes := make(map[string]change)
+	for k, v := range b {
+		if !reflect.DeepEqual(v, a[k]) {
+			changes[k] = change{Before: v, After: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = change{After: v}
+		}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	return auditlog.JSONText(data)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,204 @@
+package auditlogimpl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestMiddleware(t *testing.T) {
+	type testCase struct {
+		desc     string
+		method   string
+		url      string
+		body     string
+		expected *auditlog.Entry
+	}
+
+	tests := []testCase{
+		{
+			desc:   "should record the resource id set by the handler",
+			method: http.MethodPost,
+			url:    "/api/datasources",
+			body:   `{"name": "loki", "basicAuthPassword": "secret"}`,
+			expected: &auditlog.Entry{
+				OrgID:      1,
+				ActorUID:   "user:admin",
+				ActorLogin: "admin",
+				Action:     "datasources:create",
+				Resource:   "datasources",
+				ResourceID: "created",
+				Method:     http.MethodPost,
+				Path:       "/api/datasources",
+				StatusCode: http.StatusOK,
+				Result:     auditlog.ResultSuccess,
+				After:      `{"basicAuthPassword":"*********","name":"loki"}`,
+			},
+		},
+		{
+			desc:   "should record the state before the change and the diff",
+			method: http.MethodPut,
+			url:    "/api/datasources/uid/abc",
+			body:   `{"name": "b", "url": "http://loki", "secureJsonData": {"password": "secret"}}`,
+			expected: &auditlog.Entry{
+				OrgID:      1,
+				ActorUID:   "user:admin",
+				ActorLogin: "admin",
+				Action:     "datasources:update",
+				Resource:   "datasources",
+				ResourceID: "abc",
+				Method:     http.MethodPut,
+				Path:       "/api/datasources/uid/abc",
+				StatusCode: http.StatusOK,
+				Result:     auditlog.ResultSuccess,
+				Before:     `{"name":"a","url":"http://loki"}`,
+				After:      `{"name":"b","secureJsonData":"*********","url":"http://loki"}`,
+				Diff:       `{"name":{"before":"a","after":"b"},"secureJsonData":{"before":null,"after":"*********"}}`,
+			},
+		},
+		{
+			desc:   "should record failed calls",
+			method: http.MethodDelete,
+			url:    "/api/teams/2/members/3",
+			expected: &auditlog.Entry{
+				OrgID:      1,
+				ActorUID:   "user:admin",
+				ActorLogin: "admin",
+				Action:     "teams.members:delete",
+				Resource:   "teams.members",
+				ResourceID: "3",
+				Method:     http.MethodDelete,
+				Path:       "/api/teams/2/members/3",
+				StatusCode: http.StatusForbidden,
+				Result:     auditlog.ResultFailure,
+			},
+		},
+		{
+			desc:   "should not record reads",
+			method: http.MethodGet,
+			url:    "/api/datasources",
+		},
+		{
+			desc:   "should not record excluded paths",
+			method: http.MethodPost,
+			url:    "/api/ds/query",
+			body:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			sink := &fakeSink{}
+			cfg := setting.NewCfg()
+			cfg.AuditLog = setting.AuditLogSettings{
+				Enabled:      true,
+				ExcludePaths: []string{"/api/ds/query"},
+				MaxBodySize:  1024,
+			}
+			s := &Service{cfg: cfg, log: log.NewNopLogger(), sinks: []auditlog.Sink{sink}}
+
+			ok := func(c *contextmodel.ReqContext) response.Response {
+				return response.Success("ok")
+			}
+			routes := routing.NewRouteRegister(middleware.ProvideRouteOperationName)
+			routes.Get("/api/datasources", routing.Wrap(ok))
+			routes.Post("/api/datasources", routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+				auditlog.RecordResourceID(c.Req.Context(), "created")
+				return response.Success("created")
+			}))
+			routes.Put("/api/datasources/uid/:uid", routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+				auditlog.RecordBefore(c.Req.Context(), map[string]string{"name": "a", "url": "http://loki"})
+				return response.Success("updated")
+			}))
+			routes.Delete("/api/teams/:teamId/members/:userId", routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+				return response.Error(http.StatusForbidden, "forbidden", nil)
+			}))
+			routes.Post("/api/ds/query", routing.Wrap(ok))
+
+			server := webtest.NewServer(t, routes)
+			server.Mux.UseMiddleware(s.Middleware())
+
+			req := server.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, UserID: 1, UserUID: "admin", Login: "admin"})
+			// Serve the request synchronously, the entry is recorded once the handler has returned
+			server.Mux.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tt.expected == nil {
+				assert.Empty(t, sink.entries)
+				return
+			}
+			require.Len(t, sink.entries, 1)
+			actual := sink.entries[0]
+			assert.NotZero(t, actual.Created)
+			assert.NotEmpty(t, actual.IPAddress)
+			actual.Created, actual.IPAddress, actual.UserAgent = tt.expected.Created, "", ""
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestResourceFromRoute(t *testing.T) {
+	tests := []struct {
+		route      string
+		params     map[string]string
+		resource   string
+		resourceID string
+	}{
+		{route: "/api/datasources", resource: "datasources"},
+		{route: "/api/datasources/uid/:uid", params: map[string]string{":uid": "abc"}, resource: "datasources", resourceID: "abc"},
+		{route: "/api/folders/:folder_uid/permissions", params: map[string]string{":folder_uid": "f"}, resource: "folders.permissions", resourceID: "f"},
+		{route: "/api/access-control/teams/:teamId/roles/:roleUID", params: map[string]string{":teamId": "1", ":roleUID": "r"}, resource: "access-control.teams.roles", resourceID: "r"},
+		{route: "/api/dashboards/db/", resource: "dashboards"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			resource, resourceID := resourceFromRoute(tt.route, tt.params)
+			assert.Equal(t, tt.resource, resource)
+			assert.Equal(t, tt.resourceID, resourceID)
+		})
+	}
+}
+
+func TestService_Record(t *testing.T) {
+	failing := &fakeSink{err: assert.AnError}
+	working := &fakeSink{}
+	s := &Service{cfg: setting.NewCfg(), log: log.NewNopLogger(), sinks: []auditlog.Sink{failing, working}}
+
+	err := s.Record(context.Background(), &auditlog.Entry{Action: "teams:create"})
+	require.ErrorIs(t, err, assert.AnError)
+	require.Len(t, working.entries, 1, "a failing sink should not stop the others")
+	assert.NotZero(t, working.entries[0].Created)
+}
+
+type fakeSink struct {
+	entries []*auditlog.Entry
+	err     error
+}
+
+func (f *fakeSink) Name() string {
+	return "fake"
+}
+
+func (f *fakeSink) Write(_ context.Context, entry *auditlog.Entry) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entry)
+	return nil
+}
@@ -0,0 +1,23 @@
+package auditlogimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+var auditLogReaderRole = accesscontrol.RoleDTO{
+	Name:        "fixed:auditlogs:reader",
+	DisplayName: "Audit log reader",
+	Description: "Search the audit log of every organization.",
+	Group:       "Audit log",
+	Permissions: []accesscontrol.Permission{
+		{Action: auditlog.ActionRead},
+	},
+}
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	return service.DeclareFixedRoles(accesscontrol.RoleRegistration{
+		Role:   auditLogReaderRole,
+		Grants: []string{accesscontrol.RoleGrafanaAdmin},
+	})
+}
@@ -0,0 +1,150 @@
+package auditlogimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/grafana/grafana/pkg/api"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/auditlog/auditlogimpl")
+
+const cleanupInterval = time.Hour
+
+var _ auditlog.Service = (*Service)(nil)
+
+type Service struct {
+	cfg        *setting.Cfg
+	log        log.Logger
+	store      store
+	sinks      []auditlog.Sink
+	serverLock *serverlock.ServerLockService
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	sqlStore db.DB,
+	httpServer *api.HTTPServer,
+	routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl,
+	accessControlService accesscontrol.Service,
+	serverLock *serverlock.ServerLockService,
+) (*Service, error) {
+	s := &Service{
+		cfg:        cfg,
+		log:        log.New("auditlog"),
+		store:      &dbStore{db: sqlStore},
+		serverLock: serverLock,
+	}
+
+	if !cfg.AuditLog.Enabled {
+		return s, nil
+	}
+
+	for _, name := range cfg.AuditLog.Sinks {
+		sink, err := s.newSink(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize audit log sink %q: %w", name, err)
+		}
+		s.sinks = append(s.sinks, sink)
+	}
+
+	if err := declareFixedRoles(accessControlService); err != nil {
+		return nil, err
+	}
+
+	httpServer.AddMiddleware(s.Middleware())
+	newAPI(s, accessControl, routeRegister).registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) newSink(name string) (auditlog.Sink, error) {
+	switch name {
+	case auditlog.SinkDatabase:
+		return s.store.(auditlog.Sink), nil
+	case auditlog.SinkFile:
+		return newFileSink(s.cfg)
+	case auditlog.SinkSyslog:
+		return newSyslogSink(s.cfg)
+	default:
+		return nil, errors.New("unknown sink")
+	}
+}
+
+func (s *Service) Record(ctx context.Context, entry *auditlog.Entry) error {
+	ctx, span := tracer.Start(ctx, "auditlog.Record")
+	defer span.End()
+
+	if entry.Created.IsZero() {
+		entry.Created = time.Now()
+	}
+
+	// One failing sink must not prevent the entry from reaching the others
+	var errs []error
+	for _, sink := range s.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	ctx, span := tracer.Start(ctx, "auditlog.Search")
+	defer span.End()
+
+	if !s.hasDatabaseSink() {
+		return nil, auditlog.ErrSearchNotAvailable
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, auditlog.ErrInvalidQuery.Errorf("to must not be before from")
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) hasDatabaseSink() bool {
+	return s.cfg.AuditLog.Enabled && slices.Contains(s.cfg.AuditLog.Sinks, auditlog.SinkDatabase)
+}
+
+// IsDisabled returns true when there are no entries in the database to clean up.
+func (s *Service) IsDisabled() bool {
+	return !s.hasDatabaseSink() || s.cfg.AuditLog.Retention <= 0
+}
+
+// Run removes the entries older than the retention period from the database.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, "cleanup audit log", cleanupInterval/2, func(ctx context.Context) {
+				deleted, err := s.store.DeleteOlderThan(ctx, time.Now().Add(-s.cfg.AuditLog.Retention))
+				if err != nil {
+					s.log.Error("Failed to delete expired audit log entries", "error", err)
+					return
+				}
+				s.log.Debug("Deleted expired audit log entries", "count", deleted)
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute audit log cleanup", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package auditlogimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+type store interface {
+	Insert(ctx context.Context, entry *auditlog.Entry) error
+	Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error)
+	DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// dbStore keeps the entries in the audit_log table, it is used by the database sink.
+type dbStore struct {
+	db db.DB
+}
+
+var _ store = (*dbStore)(nil)
+var _ auditlog.Sink = (*dbStore)(nil)
+
+func (s *dbStore) Name() string {
+	return auditlog.SinkDatabase
+}
+
+func (s *dbStore) Write(ctx context.Context, entry *auditlog.Entry) error {
+	return s.Insert(ctx, entry)
+}
+
+func (s *dbStore) Insert(ctx context.Context, entry *auditlog.Entry) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+func (s *dbStore) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	result := &auditlog.SearchResult{
+		Entries: make([]*auditlog.Entry, 0),
+		Page:    query.Page,
+		PerPage: query.Limit,
+	}
+
+	filter := strings.Builder{}
+	params := make([]any, 0)
+	where := func(cond string, args ...any) {
+		if filter.Len() > 0 {
+			filter.WriteString(" AND ")
+		}
+		filter.WriteString(cond)
+		params = append(params, args...)
+	}
+
+	if query.OrgID != 0 {
+		where("org_id = ?", query.OrgID)
+	}
+	if query.ActorUID != "" {
+		where("actor_uid = ?", query.ActorUID)
+	}
+	if query.ActorLogin != "" {
+		where("actor_login = ?", query.ActorLogin)
+	}
+	if resource, ok := strings.CutSuffix(query.Action, ":*"); ok {
+		where("action "+s.db.GetDialect().LikeStr()+" ?", resource+":%")
+	} else if query.Action != "" {
+		where("action = ?", query.Action)
+	}
+	if query.Resource != "" {
+		where("resource = ?", query.Resource)
+	}
+	if query.ResourceID != "" {
+		where("resource_id = ?", query.ResourceID)
+	}
+	if query.Result != "" {
+		where("result = ?", query.Result)
+	}
+	if !query.From.IsZero() {
+		where("created >= ?", query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		where("created <= ?", query.To.UTC())
+	}
+
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		count := sess.Table("audit_log")
+		if filter.Len() > 0 {
+			count.Where(filter.String(), params...)
+		}
+		total, err := count.Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = total
+
+		find := sess.Table("audit_log")
+		if filter.Len() > 0 {
+			find.Where(filter.String(), params...)
+		}
+		if query.Limit > 0 {
+			find.Limit(query.Limit, query.Limit*(query.Page-1))
+		}
+		return find.Desc("created").Desc("id").Find(&result.Entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *dbStore) DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM audit_log WHERE created < ?", olderThan.UTC())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,87 @@
+package auditlogimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationAuditLogStore(t *testing.T) {
+	store := &dbStore{db: db.InitTestDB(t)}
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	entries := []*auditlog.Entry{
+		{OrgID: 1, ActorLogin: "admin", Action: "datasources:update", Resource: "datasources", ResourceID: "abc", Result: auditlog.ResultSuccess, Before: `{"name":"a"}`, After: `{"name":"b"}`, Created: now.Add(-48 * time.Hour)},
+		{OrgID: 1, ActorLogin: "admin", Action: "datasources:delete", Resource: "datasources", ResourceID: "abc", Result: auditlog.ResultFailure, Created: now.Add(-time.Hour)},
+		{OrgID: 2, ActorLogin: "editor", Action: "teams:create", Resource: "teams", Result: auditlog.ResultSuccess, Created: now},
+	}
+	for _, entry := range entries {
+		require.NoError(t, store.Insert(ctx, entry))
+	}
+
+	t.Run("should return every entry newest first", func(t *testing.T) {
+		res, err := store.Search(ctx, &auditlog.SearchQuery{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, res.Entries, 3)
+		assert.EqualValues(t, 3, res.TotalCount)
+		assert.Equal(t, "teams:create", res.Entries[0].Action)
+		assert.Equal(t, auditlog.JSONText(`{"name":"b"}`), res.Entries[2].After)
+	})
+
+	t.Run("should paginate", func(t *testing.T) {
+		res, err := store.Search(ctx, &auditlog.SearchQuery{Page: 2, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, res.Entries, 1)
+		assert.EqualValues(t, 3, res.TotalCount)
+		assert.Equal(t, "datasources:update", res.Entries[0].Action)
+	})
+
+	t.Run("should filter", func(t *testing.T) {
+		tests := []struct {
+			desc     string
+			query    auditlog.SearchQuery
+			expected []string
+		}{
+			{desc: "by org", query: auditlog.SearchQuery{OrgID: 2}, expected: []string{"teams:create"}},
+			{desc: "by actor", query: auditlog.SearchQuery{ActorLogin: "admin"}, expected: []string{"datasources:delete", "datasources:update"}},
+			{desc: "by action", query: auditlog.SearchQuery{Action: "datasources:delete"}, expected: []string{"datasources:delete"}},
+			{desc: "by resource action", query: auditlog.SearchQuery{Action: "datasources:*"}, expected: []string{"datasources:delete", "datasources:update"}},
+			{desc: "by result", query: auditlog.SearchQuery{Result: auditlog.ResultFailure}, expected: []string{"datasources:delete"}},
+			{desc: "by time", query: auditlog.SearchQuery{From: now.Add(-2 * time.Hour), To: now.Add(-time.Minute)}, expected: []string{"datasources:delete"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				res, err := store.Search(ctx, &tt.query)
+				require.NoError(t, err)
+				actions := make([]string, 0, len(res.Entries))
+				for _, entry := range res.Entries {
+					actions = append(actions, entry.Action)
+				}
+				assert.Equal(t, tt.expected, actions)
+				assert.EqualValues(t, len(tt.expected), res.TotalCount)
+			})
+		}
+	})
+
+	t.Run("should delete expired entries", func(t *testing.T) {
+		deleted, err := store.DeleteOlderThan(ctx, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, deleted)
+
+		res, err := store.Search(ctx, &auditlog.SearchQuery{})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, res.TotalCount)
+	})
+}
@@ -0,0 +1,60 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package auditlogimpl
+
+import (
+	"context"
+	"encoding/json"
+	"log/syslog"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var facilities = map[string]syslog.Priority{
+	"user":   syslog.LOG_USER,
+	"daemon": syslog.LOG_DAEMON,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// syslogSink sends the entries as JSON messages to syslog. Failed actions
+// are sent with the warning severity, everything else as info.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(cfg *setting.Cfg) (auditlog.Sink, error) {
+	facility, ok := facilities[cfg.AuditLog.SyslogFacility]
+	if !ok {
+		facility = syslog.LOG_LOCAL7
+	}
+
+	w, err := syslog.Dial(cfg.AuditLog.SyslogNetwork, cfg.AuditLog.SyslogAddress, facility, cfg.AuditLog.SyslogTag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: w}, nil
+}
+
+func (s *syslogSink) Name() string {
+	return auditlog.SinkSyslog
+}
+
+func (s *syslogSink) Write(_ context.Context, entry *auditlog.Entry) error {
+	msg, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if entry.Result == auditlog.ResultFailure {
+		return s.writer.Warning(string(msg))
+	}
+	return s.writer.Info(string(msg))
+}
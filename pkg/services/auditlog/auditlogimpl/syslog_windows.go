@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+package auditlogimpl
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newSyslogSink(_ *setting.Cfg) (auditlog.Sink, error) {
+	return nil, errors.New("the syslog audit log sink is not supported on windows")
+}
@@ -0,0 +1,72 @@
+package auditlog
+
+import (
+	"context"
+	"sync"
+)
+
+type recorderKey struct{}
+
+// Recorder collects the state of the resource changed while a request is handled.
+// Handlers use RecordBefore and RecordAfter, the audit log middleware reads it back
+// once the request has completed.
+type Recorder struct {
+	mu         sync.Mutex
+	before     any
+	after      any
+	resourceID string
+}
+
+// WithRecorder returns a copy of ctx carrying a new recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+func recorderFromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Recording reports whether the request is audited, handlers use it to skip loading
+// state that is only needed for the audit log.
+func Recording(ctx context.Context) bool {
+	return recorderFromContext(ctx) != nil
+}
+
+// RecordBefore records the state of the resource before it was changed.
+// It is a no-op when the request is not audited.
+func RecordBefore(ctx context.Context, state any) {
+	if r := recorderFromContext(ctx); r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.before = state
+	}
+}
+
+// RecordAfter records the state of the resource after it was changed.
+// When it is not called, the request body is used instead.
+func RecordAfter(ctx context.Context, state any) {
+	if r := recorderFromContext(ctx); r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.after = state
+	}
+}
+
+// RecordResourceID records the identifier of the changed resource, for requests
+// that do not carry it in the url, like resource creation.
+func RecordResourceID(ctx context.Context, id string) {
+	if r := recorderFromContext(ctx); r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.resourceID = id
+	}
+}
+
+// State returns what has been recorded so far.
+func (r *Recorder) State() (before any, after any, resourceID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.before, r.after, r.resourceID
+}
@@ -30,6 +30,7 @@ const (
 	ClientProxy        = "auth.client.proxy"
 	ClientSAML         = "auth.client.saml"
 	ClientPasswordless = "auth.client.passwordless"
+	ClientMTLS         = "auth.client.mtls"
 	ClientLDAP         = "ldap"
 )
 
@@ -40,6 +41,13 @@ const (
 	defaultRedirectToCookieKey = "redirect_to"
 )
 
+// Second factor submitted together with the password of a login.
+const (
+	MetaKeyMFACode         = "mfaCode"
+	MetaKeyMFARecoveryCode = "mfaRecoveryCode"
+	MetaKeyMFAWebAuthn     = "mfaWebAuthn"
+)
+
 // ClientParams are hints to the auth service about how to handle the identity management
 // from the authenticating client.
 type ClientParams struct {
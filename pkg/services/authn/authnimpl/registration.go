@@ -47,7 +47,7 @@ func ProvideRegistration(
 	logger := log.New("authn.registration")
 
 	authnSvc.RegisterClient(clients.ProvideRender(renderService))
-	authnSvc.RegisterClient(clients.ProvideAPIKey(apikeyService))
+	authnSvc.RegisterClient(clients.ProvideAPIKey(cfg, apikeyService, loginAttempts))
 
 	if cfg.LoginCookieName != "" {
 		authnSvc.RegisterClient(clients.ProvideSession(cfg, sessionService, authInfoService))
@@ -115,6 +115,15 @@ func ProvideRegistration(
 		authnSvc.RegisterClient(clients.ProvideJWT(jwtService, cfg))
 	}
 
+	if cfg.AuthMTLS.Enabled {
+		mtls, err := clients.ProvideMTLS(cfg, userService)
+		if err != nil {
+			logger.Error("Failed to configure mTLS client", "err", err)
+		} else {
+			authnSvc.RegisterClient(mtls)
+		}
+	}
+
 	if cfg.ExtJWTAuth.Enabled && features.IsEnabledGlobally(featuremgmt.FlagAuthAPIAccessTokenAuth) {
 		authnSvc.RegisterClient(clients.ProvideExtendedJWT(cfg))
 	}
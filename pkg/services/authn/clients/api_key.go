@@ -15,7 +15,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -26,6 +28,8 @@ var (
 	errAPIKeyOrgMismatch = errutil.Unauthorized("api-key.organization-mismatch", errutil.WithPublicMessage("API key does not belong to the requested organization"))
 
 	errAPIKeyInvalidType = errutil.BadRequest("api-key.invalid-type-id")
+
+	errAPIKeyTooManyAttempts = errutil.TooManyRequests("api-key.too-many-attempts", errutil.WithPublicMessage("Too many invalid API keys"))
 )
 
 var (
@@ -39,16 +43,21 @@ const (
 	metaKeySkipLastUsed = "keySkipLastUsed"
 )
 
-func ProvideAPIKey(apiKeyService apikey.Service) *APIKey {
+func ProvideAPIKey(cfg *setting.Cfg, apiKeyService apikey.Service, loginAttempts loginattempt.Service) *APIKey {
 	return &APIKey{
-		log:           log.New(authn.ClientAPIKey),
-		apiKeyService: apiKeyService,
+		log:                 log.New(authn.ClientAPIKey),
+		apiKeyService:       apiKeyService,
+		loginAttempts:       loginAttempts,
+		ipAddressProtection: !cfg.DisableBruteForceLoginProtection && !cfg.BruteForceLoginProtection.DisableIPAddressProtection,
 	}
 }
 
 type APIKey struct {
 	log           log.Logger
 	apiKeyService apikey.Service
+	loginAttempts loginattempt.Service
+	// ipAddressProtection is set when invalid keys count towards the ip address limits
+	ipAddressProtection bool
 }
 
 func (s *APIKey) Name() string {
@@ -56,8 +65,22 @@ func (s *APIKey) Name() string {
 }
 
 func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
-	key, err := s.getAPIKey(ctx, getTokenFromRequest(r))
+	token := getTokenFromRequest(r)
+	// malformed keys are rejected without a database lookup, so they are not counted as attempts
+	if err := decodeAPIKey(token); err != nil {
+		if errors.Is(err, apikeygen.ErrInvalidApiKey) {
+			return nil, errAPIKeyInvalid.Errorf("API key is invalid")
+		}
+		return nil, err
+	}
+
+	key, err := s.getAPIKey(ctx, token)
 	if err != nil {
+		if errors.Is(err, apikeygen.ErrInvalidApiKey) || errors.Is(err, apikey.ErrInvalid) || errors.Is(err, apikey.ErrNotFound) {
+			if err := s.addFailedAttempt(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 		if errors.Is(err, apikeygen.ErrInvalidApiKey) {
 			return nil, errAPIKeyInvalid.Errorf("API key is invalid")
 		}
@@ -92,6 +115,38 @@ func (s *APIKey) IsEnabled() bool {
 	return true
 }
 
+// addFailedAttempt records an invalid key against the ip address of the request. Keys that are
+// valid are never checked, so a blocked ip address only stops the guessing of new keys.
+func (s *APIKey) addFailedAttempt(ctx context.Context, r *authn.Request) error {
+	ip := remoteAddr(r)
+	if !s.ipAddressProtection || ip == "" {
+		return nil
+	}
+
+	ok, err := s.loginAttempts.ValidateIPAddress(ctx, ip)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errAPIKeyTooManyAttempts.Errorf("too many invalid API keys from ip address - authentication temporarily blocked")
+	}
+
+	// invalid keys are not bound to a user, so they only count towards the ip address limits
+	if err := s.loginAttempts.Add(ctx, "", ip); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to record invalid API key attempt", "err", err)
+	}
+	return nil
+}
+
+func decodeAPIKey(token string) error {
+	if strings.HasPrefix(token, satokengen.GrafanaPrefix) {
+		_, err := satokengen.Decode(token)
+		return err
+	}
+	_, err := apikeygen.Decode(token)
+	return err
+}
+
 func (s *APIKey) getAPIKey(ctx context.Context, token string) (*apikey.APIKey, error) {
 	fn := s.getFromToken
 	if !strings.HasPrefix(token, satokengen.GrafanaPrefix) {
@@ -16,7 +16,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/apikey/apikeytest"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -115,7 +117,7 @@ func TestAPIKey_Authenticate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{ExpectedAPIKey: tt.expectedKey})
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{ExpectedAPIKey: tt.expectedKey}, loginattempttest.FakeLoginAttemptService{ExpectedValid: true})
 
 			identity, err := c.Authenticate(context.Background(), tt.req)
 			if tt.expectedErr != nil {
@@ -131,6 +133,77 @@ func TestAPIKey_Authenticate(t *testing.T) {
 	}
 }
 
+func TestAPIKey_Authenticate_LoginAttempts(t *testing.T) {
+	newRequest := func(token string) *authn.Request {
+		return &authn.Request{HTTPRequest: &http.Request{
+			RemoteAddr: "10.0.0.1:1234",
+			Header:     map[string][]string{"Authorization": {"Bearer " + token}},
+		}}
+	}
+
+	newCfg := func(ipAddressProtection bool) *setting.Cfg {
+		cfg := setting.NewCfg()
+		cfg.BruteForceLoginProtection.DisableIPAddressProtection = !ipAddressProtection
+		return cfg
+	}
+
+	validKey := &apikey.APIKey{ID: 1, OrgID: 1, Key: hash, ServiceAccountId: intPtr(1)}
+
+	t.Run("should reject unknown keys from a blocked ip address", func(t *testing.T) {
+		attempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: false}
+		c := ProvideAPIKey(newCfg(true), &apikeytest.Service{ExpectedError: apikey.ErrNotFound}, attempts)
+
+		identity, err := c.Authenticate(context.Background(), newRequest(secret))
+		assert.Nil(t, identity)
+		assert.ErrorIs(t, err, errAPIKeyTooManyAttempts)
+		assert.True(t, attempts.ValidateIPAddressCalled)
+		assert.False(t, attempts.AddCalled)
+	})
+
+	t.Run("should accept valid keys without checking the ip address", func(t *testing.T) {
+		attempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: false}
+		c := ProvideAPIKey(newCfg(true), &apikeytest.Service{ExpectedAPIKey: validKey}, attempts)
+
+		identity, err := c.Authenticate(context.Background(), newRequest(secret))
+		assert.NoError(t, err)
+		assert.NotNil(t, identity)
+		assert.False(t, attempts.ValidateIPAddressCalled)
+		assert.False(t, attempts.AddCalled)
+	})
+
+	t.Run("should record an attempt for unknown keys", func(t *testing.T) {
+		attempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+		c := ProvideAPIKey(newCfg(true), &apikeytest.Service{ExpectedError: apikey.ErrInvalid}, attempts)
+
+		identity, err := c.Authenticate(context.Background(), newRequest(secret))
+		assert.Nil(t, identity)
+		assert.ErrorIs(t, err, apikey.ErrInvalid)
+		assert.True(t, attempts.AddCalled)
+	})
+
+	t.Run("should not record an attempt for keys that cannot be decoded", func(t *testing.T) {
+		attempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+		c := ProvideAPIKey(newCfg(true), &apikeytest.Service{ExpectedError: apikey.ErrNotFound}, attempts)
+
+		identity, err := c.Authenticate(context.Background(), newRequest("not-a-key"))
+		assert.Nil(t, identity)
+		assert.ErrorIs(t, err, errAPIKeyInvalid)
+		assert.False(t, attempts.ValidateIPAddressCalled)
+		assert.False(t, attempts.AddCalled)
+	})
+
+	t.Run("should not record an attempt when ip address protection is disabled", func(t *testing.T) {
+		attempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+		c := ProvideAPIKey(newCfg(false), &apikeytest.Service{ExpectedError: apikey.ErrNotFound}, attempts)
+
+		identity, err := c.Authenticate(context.Background(), newRequest(secret))
+		assert.Nil(t, identity)
+		assert.ErrorIs(t, err, apikey.ErrNotFound)
+		assert.False(t, attempts.ValidateIPAddressCalled)
+		assert.False(t, attempts.AddCalled)
+	})
+}
+
 func TestAPIKey_Test(t *testing.T) {
 	type TestCase struct {
 		desc     string
@@ -182,7 +255,7 @@ func TestAPIKey_Test(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{})
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{}, loginattempttest.FakeLoginAttemptService{ExpectedValid: true})
 			assert.Equal(t, tt.expected, c.Test(context.Background(), tt.req))
 		})
 	}
@@ -274,9 +347,9 @@ func TestAPIKey_ResolveIdentity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{
 				ExpectedAPIKey: tt.exptedApiKey,
-			})
+			}, loginattempttest.FakeLoginAttemptService{ExpectedValid: true})
 
 			identity, err := c.ResolveIdentity(context.Background(), 1, tt.typ, tt.id)
 			if tt.expectedErr != nil {
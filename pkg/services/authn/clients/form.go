@@ -2,6 +2,7 @@ package clients
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/services/authn"
@@ -23,6 +24,10 @@ type Form struct {
 type loginForm struct {
 	Username string `json:"user" binding:"Required"`
 	Password string `json:"password" binding:"Required"`
+	// Second factor, only checked for users that have set one up
+	MFACode      string          `json:"mfaCode"`
+	RecoveryCode string          `json:"recoveryCode"`
+	WebAuthn     json.RawMessage `json:"webauthn"`
 }
 
 func (c *Form) Name() string {
@@ -34,6 +39,11 @@ func (c *Form) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ident
 	if err := web.Bind(r.HTTPRequest, &form); err != nil {
 		return nil, errBadForm.Errorf("failed to parse request: %w", err)
 	}
+	r.SetMeta(authn.MetaKeyMFACode, form.MFACode)
+	r.SetMeta(authn.MetaKeyMFARecoveryCode, form.RecoveryCode)
+	if len(form.WebAuthn) > 0 && string(form.WebAuthn) != "null" {
+		r.SetMeta(authn.MetaKeyMFAWebAuthn, string(form.WebAuthn))
+	}
 	return c.client.AuthenticatePassword(ctx, r, form.Username, form.Password)
 }
 
@@ -46,3 +46,17 @@ func TestForm_Authenticate(t *testing.T) {
 		})
 	}
 }
+
+func TestForm_Authenticate_SecondFactor(t *testing.T) {
+	c := ProvideForm(&authntest.FakePasswordClient{})
+	req := &authn.Request{HTTPRequest: &http.Request{
+		Header: map[string][]string{"Content-Type": {"application/json"}},
+		Body:   io.NopCloser(strings.NewReader(`{"user": "test", "password": "test", "mfaCode": "123456", "webauthn": {"id": "abc"}}`)),
+	}}
+
+	_, err := c.Authenticate(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", req.GetMeta(authn.MetaKeyMFACode))
+	assert.Empty(t, req.GetMeta(authn.MetaKeyMFARecoveryCode))
+	assert.JSONEq(t, `{"id": "abc"}`, req.GetMeta(authn.MetaKeyMFAWebAuthn))
+}
@@ -0,0 +1,377 @@
+package clients
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	errMTLSInvalidCertificate = errutil.Unauthorized("mtls.invalid-certificate", errutil.WithPublicMessage("Invalid client certificate"))
+	errMTLSUntrustedProxy     = errutil.Unauthorized("mtls.untrusted-proxy", errutil.WithPublicMessage("Invalid client certificate"))
+	errMTLSNoMapping          = errutil.Unauthorized("mtls.no-mapping", errutil.WithPublicMessage("Client certificate is not mapped to an identity"))
+	errMTLSServiceAccount     = errutil.Unauthorized("mtls.service-account", errutil.WithPublicMessage("Client certificate is not mapped to an identity"))
+)
+
+// Certificate fields rules can match on
+const (
+	mtlsFieldSubjectCN = "subject.cn"
+	mtlsFieldSubjectO  = "subject.o"
+	mtlsFieldSubjectOU = "subject.ou"
+	mtlsFieldSANDNS    = "san.dns"
+	mtlsFieldSANEmail  = "san.email"
+	mtlsFieldSANURI    = "san.uri"
+)
+
+var _ authn.ContextAwareClient = new(MTLS)
+
+func ProvideMTLS(cfg *setting.Cfg, userService user.Service) (*MTLS, error) {
+	roots, err := loadMTLSRoots(cfg.AuthMTLS.CACertPath)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadMTLSRules(cfg.AuthMTLS.MappingFile)
+	if err != nil {
+		return nil, err
+	}
+	trustedProxies, err := parseAcceptList(cfg.AuthMTLS.HeaderTrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &MTLS{
+		cfg:            cfg,
+		log:            log.New(authn.ClientMTLS),
+		userService:    userService,
+		roots:          roots,
+		rules:          rules,
+		trustedProxies: trustedProxies,
+		now:            time.Now,
+	}, nil
+}
+
+// MTLS authenticates requests with a client certificate issued by one of the configured certificate authorities.
+// Revocation is not checked, certificates are expected to be short lived.
+type MTLS struct {
+	cfg            *setting.Cfg
+	log            log.Logger
+	userService    user.Service
+	roots          *x509.CertPool
+	rules          []*mtlsRule
+	trustedProxies []*net.IPNet
+	now            func() time.Time
+}
+
+type mtlsRules struct {
+	Rules []*mtlsRule `yaml:"rules"`
+}
+
+// mtlsRule maps the certificates with a field matching the pattern to a user or service account.
+// Login, email, name and service account are expanded with the groups of the pattern, like $1 or ${name}.
+type mtlsRule struct {
+	Field          string       `yaml:"field"`
+	Match          string       `yaml:"match"`
+	ServiceAccount string       `yaml:"service_account"`
+	Login          string       `yaml:"login"`
+	Email          string       `yaml:"email"`
+	Name           string       `yaml:"name"`
+	OrgID          int64        `yaml:"org_id"`
+	Role           org.RoleType `yaml:"role"`
+	GrafanaAdmin   *bool        `yaml:"grafana_admin"`
+
+	pattern *regexp.Regexp
+}
+
+// defaultMTLSRules map the common name to the login of a user, used when there is no mapping file.
+func defaultMTLSRules() []*mtlsRule {
+	return []*mtlsRule{{Field: mtlsFieldSubjectCN, Match: "^(.+)$", Login: "$1"}}
+}
+
+func loadMTLSRoots(path string) (*x509.CertPool, error) {
+	if path == "" {
+		return nil, errors.New("ca_cert_path is required")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return roots, nil
+}
+
+func loadMTLSRules(path string) ([]*mtlsRule, error) {
+	rules := defaultMTLSRules()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mapping file: %w", err)
+		}
+		var file mtlsRules
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse mapping file: %w", err)
+		}
+		rules = file.Rules
+	}
+
+	for i, rule := range rules {
+		switch rule.Field {
+		case mtlsFieldSubjectCN, mtlsFieldSubjectO, mtlsFieldSubjectOU, mtlsFieldSANDNS, mtlsFieldSANEmail, mtlsFieldSANURI:
+		default:
+			return nil, fmt.Errorf("rule %d: unknown field %q", i, rule.Field)
+		}
+		pattern, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: invalid match: %w", i, err)
+		}
+		rule.pattern = pattern
+
+		if rule.ServiceAccount != "" {
+			if rule.Login != "" || rule.Email != "" || rule.Role != "" || rule.GrafanaAdmin != nil {
+				return nil, fmt.Errorf("rule %d: service accounts cannot have a login, email, role or grafana_admin", i)
+			}
+			if rule.OrgID == 0 {
+				return nil, fmt.Errorf("rule %d: service accounts require an org_id", i)
+			}
+			continue
+		}
+		if rule.Login == "" && rule.Email == "" {
+			return nil, fmt.Errorf("rule %d: either service_account, login or email is required", i)
+		}
+		if rule.Role != "" && !rule.Role.IsValid() {
+			return nil, fmt.Errorf("rule %d: invalid role %q", i, rule.Role)
+		}
+	}
+	return rules, nil
+}
+
+func (c *MTLS) Name() string {
+	return authn.ClientMTLS
+}
+
+func (c *MTLS) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	certs, err := c.peerCertificates(r)
+	if err != nil {
+		return nil, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	leaf := certs[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		CurrentTime:   c.now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, errMTLSInvalidCertificate.Errorf("failed to verify client certificate: %w", err)
+	}
+
+	for _, rule := range c.rules {
+		for _, value := range mtlsFieldValues(leaf, rule.Field) {
+			match := rule.pattern.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			expand := func(template string) string {
+				return string(rule.pattern.ExpandString(nil, template, value, match))
+			}
+			if rule.ServiceAccount != "" {
+				return c.serviceAccountIdentity(ctx, rule, expand(rule.ServiceAccount), value)
+			}
+			return c.userIdentity(rule, expand, value)
+		}
+	}
+
+	c.log.FromContext(ctx).Debug("No rule matches client certificate", "subject", leaf.Subject.String())
+	return nil, errMTLSNoMapping.Errorf("no rule matches certificate %s", leaf.Subject.String())
+}
+
+func (c *MTLS) userIdentity(rule *mtlsRule, expand func(string) string, authID string) (*authn.Identity, error) {
+	id := &authn.Identity{
+		AuthenticatedBy: login.MTLSAuthModule,
+		AuthID:          authID,
+		OrgRoles:        map[int64]org.RoleType{},
+		ClientParams: authn.ClientParams{
+			SyncUser:        true,
+			AllowSignUp:     c.cfg.AuthMTLS.AutoSignUp,
+			FetchSyncedUser: true,
+			SyncPermissions: true,
+		},
+	}
+
+	if rule.Login != "" {
+		id.Login = expand(rule.Login)
+		id.ClientParams.LookUpParams.Login = &id.Login
+	}
+	if rule.Email != "" {
+		id.Email = expand(rule.Email)
+		id.ClientParams.LookUpParams.Email = &id.Email
+	}
+	if id.Login == "" && id.Email == "" {
+		return nil, errMTLSNoMapping.Errorf("rule for %s resulted in an empty login and email", rule.Field)
+	}
+	if rule.Name != "" {
+		id.Name = expand(rule.Name)
+	}
+
+	if rule.Role != "" {
+		orgID := rule.OrgID
+		if orgID == 0 {
+			orgID = int64(c.cfg.AutoAssignOrgId)
+		}
+		id.OrgRoles[orgID] = rule.Role
+		id.ClientParams.SyncOrgRoles = true
+	}
+	id.IsGrafanaAdmin = rule.GrafanaAdmin
+
+	return id, nil
+}
+
+func (c *MTLS) serviceAccountIdentity(ctx context.Context, rule *mtlsRule, name, authID string) (*authn.Identity, error) {
+	// Service accounts have a generated login, see the service account store
+	saLogin := strings.ReplaceAll(fmt.Sprintf("%s%d-%s", serviceaccounts.ServiceAccountPrefix, rule.OrgID, strings.ToLower(name)), " ", "-")
+	usr, err := c.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: saLogin})
+	if err != nil {
+		return nil, errMTLSServiceAccount.Errorf("failed to find service account %q: %w", name, err)
+	}
+	if !usr.IsServiceAccount || usr.OrgID != rule.OrgID {
+		return nil, errMTLSServiceAccount.Errorf("%q is not a service account of org %d", name, rule.OrgID)
+	}
+
+	return &authn.Identity{
+		ID:              strconv.FormatInt(usr.ID, 10),
+		Type:            claims.TypeServiceAccount,
+		OrgID:           usr.OrgID,
+		AuthenticatedBy: login.MTLSAuthModule,
+		AuthID:          authID,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+	}, nil
+}
+
+// peerCertificates returns the leaf certificate followed by the intermediates sent by the client.
+func (c *MTLS) peerCertificates(r *authn.Request) ([]*x509.Certificate, error) {
+	if r.HTTPRequest.TLS != nil && len(r.HTTPRequest.TLS.PeerCertificates) > 0 {
+		return r.HTTPRequest.TLS.PeerCertificates, nil
+	}
+
+	header := c.headerValue(r)
+	if header == "" {
+		return nil, errMTLSInvalidCertificate.Errorf("no client certificate")
+	}
+	if !c.isTrustedProxy(r) {
+		return nil, errMTLSUntrustedProxy.Errorf("client certificate header sent by untrusted address %s", r.HTTPRequest.RemoteAddr)
+	}
+
+	decoded, err := url.QueryUnescape(header)
+	if err != nil {
+		return nil, errMTLSInvalidCertificate.Errorf("failed to decode client certificate header: %w", err)
+	}
+	var certs []*x509.Certificate
+	rest := []byte(decoded)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errMTLSInvalidCertificate.Errorf("failed to parse client certificate header: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errMTLSInvalidCertificate.Errorf("no certificate in client certificate header")
+	}
+	return certs, nil
+}
+
+func (c *MTLS) headerValue(r *authn.Request) string {
+	if c.cfg.AuthMTLS.HeaderName == "" {
+		return ""
+	}
+	return r.HTTPRequest.Header.Get(c.cfg.AuthMTLS.HeaderName)
+}
+
+func (c *MTLS) isTrustedProxy(r *authn.Request) bool {
+	host, _, err := net.SplitHostPort(r.HTTPRequest.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	for _, v := range c.trustedProxies {
+		if v.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mtlsFieldValues(cert *x509.Certificate, field string) []string {
+	switch field {
+	case mtlsFieldSubjectCN:
+		if cert.Subject.CommonName == "" {
+			return nil
+		}
+		return []string{cert.Subject.CommonName}
+	case mtlsFieldSubjectO:
+		return cert.Subject.Organization
+	case mtlsFieldSubjectOU:
+		return cert.Subject.OrganizationalUnit
+	case mtlsFieldSANDNS:
+		return cert.DNSNames
+	case mtlsFieldSANEmail:
+		return cert.EmailAddresses
+	case mtlsFieldSANURI:
+		uris := make([]string, 0, len(cert.URIs))
+		for _, u := range cert.URIs {
+			uris = append(uris, u.String())
+		}
+		return uris
+	default:
+		return nil
+	}
+}
+
+func (c *MTLS) IsEnabled() bool {
+	return c.cfg.AuthMTLS.Enabled
+}
+
+func (c *MTLS) Test(ctx context.Context, r *authn.Request) bool {
+	if r.HTTPRequest == nil {
+		return false
+	}
+	if r.HTTPRequest.TLS != nil && len(r.HTTPRequest.TLS.PeerCertificates) > 0 {
+		return true
+	}
+	return c.headerValue(r) != ""
+}
+
+func (c *MTLS) Priority() uint {
+	return 25
+}
@@ -0,0 +1,282 @@
+package clients
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl.SerialNumber = big.NewInt(2)
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore, tmpl.NotAfter = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	}
+	if tmpl.ExtKeyUsage == nil {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func tlsRequest(cert *x509.Certificate) *authn.Request {
+	return &authn.Request{HTTPRequest: &http.Request{
+		Header: http.Header{},
+		TLS:    &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+	}}
+}
+
+const testMTLSMapping = `
+rules:
+  - field: san.uri
+    match: '^spiffe://example\.com/sa/(?P<name>[a-z-]+)$'
+    service_account: '${name}'
+    org_id: 2
+  - field: san.email
+    match: '^(?P<login>[a-z]+)@example\.com$'
+    login: '${login}'
+    email: '${login}@example.com'
+    name: '${login}'
+    org_id: 1
+    role: Editor
+`
+
+func TestMTLS_Authenticate(t *testing.T) {
+	ca := newTestCA(t)
+	spiffe, _ := url.Parse("spiffe://example.com/sa/metrics-pusher")
+
+	type testCase struct {
+		desc             string
+		mapping          string
+		cert             *x509.Certificate
+		untrustedCA      bool
+		expectedUser     *user.User
+		expectedIdentity *authn.Identity
+		expectedErr      error
+	}
+
+	tests := []testCase{
+		{
+			desc: "should map the common name to a user by default",
+			cert: ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}}),
+			expectedIdentity: &authn.Identity{
+				Login:           "alice",
+				AuthenticatedBy: login.MTLSAuthModule,
+				AuthID:          "alice",
+				OrgRoles:        map[int64]org.RoleType{},
+				ClientParams: authn.ClientParams{
+					SyncUser:        true,
+					FetchSyncedUser: true,
+					SyncPermissions: true,
+					LookUpParams:    login.UserLookupParams{Login: strPtr("alice")},
+				},
+			},
+		},
+		{
+			desc:    "should map a SAN email to a user with a role",
+			mapping: testMTLSMapping,
+			cert:    ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "ignored"}, EmailAddresses: []string{"bob@example.com"}}),
+			expectedIdentity: &authn.Identity{
+				Login:           "bob",
+				Email:           "bob@example.com",
+				Name:            "bob",
+				AuthenticatedBy: login.MTLSAuthModule,
+				AuthID:          "bob@example.com",
+				OrgRoles:        map[int64]org.RoleType{1: org.RoleEditor},
+				ClientParams: authn.ClientParams{
+					SyncUser:        true,
+					FetchSyncedUser: true,
+					SyncPermissions: true,
+					SyncOrgRoles:    true,
+					LookUpParams:    login.UserLookupParams{Login: strPtr("bob"), Email: strPtr("bob@example.com")},
+				},
+			},
+		},
+		{
+			desc:         "should map a SPIFFE id to a service account",
+			mapping:      testMTLSMapping,
+			cert:         ca.issue(t, &x509.Certificate{URIs: []*url.URL{spiffe}}),
+			expectedUser: &user.User{ID: 10, OrgID: 2, Login: "sa-2-metrics-pusher", IsServiceAccount: true},
+			expectedIdentity: &authn.Identity{
+				ID:              "10",
+				Type:            claims.TypeServiceAccount,
+				OrgID:           2,
+				AuthenticatedBy: login.MTLSAuthModule,
+				AuthID:          "spiffe://example.com/sa/metrics-pusher",
+				ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+			},
+		},
+		{
+			desc:         "should not map to a regular user with the service account login",
+			mapping:      testMTLSMapping,
+			cert:         ca.issue(t, &x509.Certificate{URIs: []*url.URL{spiffe}}),
+			expectedUser: &user.User{ID: 10, OrgID: 2, Login: "sa-2-metrics-pusher"},
+			expectedErr:  errMTLSServiceAccount,
+		},
+		{
+			desc:        "should fail when no rule matches",
+			mapping:     testMTLSMapping,
+			cert:        ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}}),
+			expectedErr: errMTLSNoMapping,
+		},
+		{
+			desc:        "should fail for certificates of another CA",
+			cert:        newTestCA(t).issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}}),
+			expectedErr: errMTLSInvalidCertificate,
+		},
+		{
+			desc:        "should fail for expired certificates",
+			cert:        ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}, NotBefore: time.Now().Add(-2 * time.Hour), NotAfter: time.Now().Add(-time.Hour)}),
+			expectedErr: errMTLSInvalidCertificate,
+		},
+		{
+			desc:        "should fail for certificates not meant for client auth",
+			cert:        ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}),
+			expectedErr: errMTLSInvalidCertificate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.AuthMTLS = setting.AuthMTLSSettings{Enabled: true, CACertPath: writeTestFile(t, "ca.pem", ca.pem)}
+			if tt.mapping != "" {
+				cfg.AuthMTLS.MappingFile = writeTestFile(t, "mapping.yaml", []byte(tt.mapping))
+			}
+			userService := &usertest.FakeUserService{ExpectedUser: tt.expectedUser}
+			if tt.expectedUser == nil {
+				userService.ExpectedError = user.ErrUserNotFound
+			}
+
+			c, err := ProvideMTLS(cfg, userService)
+			require.NoError(t, err)
+
+			identity, err := c.Authenticate(context.Background(), tlsRequest(tt.cert))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, identity)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIdentity, identity)
+		})
+	}
+}
+
+func TestMTLS_Header(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}})
+	header := url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})))
+
+	cfg := setting.NewCfg()
+	cfg.AuthMTLS = setting.AuthMTLSSettings{
+		Enabled:              true,
+		CACertPath:           writeTestFile(t, "ca.pem", ca.pem),
+		HeaderName:           "X-SSL-Client-Cert",
+		HeaderTrustedProxies: "10.0.0.0/8",
+	}
+	c, err := ProvideMTLS(cfg, &usertest.FakeUserService{})
+	require.NoError(t, err)
+
+	newRequest := func(remoteAddr string) *authn.Request {
+		return &authn.Request{HTTPRequest: &http.Request{
+			RemoteAddr: remoteAddr,
+			Header:     http.Header{"X-Ssl-Client-Cert": {header}},
+		}}
+	}
+
+	t.Run("should accept the certificate from a trusted proxy", func(t *testing.T) {
+		r := newRequest("10.0.0.1:1234")
+		require.True(t, c.Test(context.Background(), r))
+		identity, err := c.Authenticate(context.Background(), r)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", identity.Login)
+	})
+
+	t.Run("should reject the certificate from other addresses", func(t *testing.T) {
+		_, err := c.Authenticate(context.Background(), newRequest("192.168.0.1:1234"))
+		assert.ErrorIs(t, err, errMTLSUntrustedProxy)
+	})
+
+	t.Run("should ignore requests without a certificate", func(t *testing.T) {
+		assert.False(t, c.Test(context.Background(), &authn.Request{HTTPRequest: &http.Request{Header: http.Header{}}}))
+	})
+}
+
+func TestLoadMTLSRules(t *testing.T) {
+	tests := []struct {
+		desc    string
+		mapping string
+	}{
+		{desc: "unknown field", mapping: "rules: [{field: subject.c, match: '.*', login: $0}]"},
+		{desc: "invalid pattern", mapping: "rules: [{field: subject.cn, match: '(', login: $0}]"},
+		{desc: "no identity", mapping: "rules: [{field: subject.cn, match: '.*'}]"},
+		{desc: "invalid role", mapping: "rules: [{field: subject.cn, match: '.*', login: $0, role: Owner}]"},
+		{desc: "service account without org", mapping: "rules: [{field: subject.cn, match: '.*', service_account: $0}]"},
+		{desc: "service account with role", mapping: "rules: [{field: subject.cn, match: '.*', service_account: $0, org_id: 1, role: Admin}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := loadMTLSRules(writeTestFile(t, "mapping.yaml", []byte(tt.mapping)))
+			assert.Error(t, err)
+		})
+	}
+}
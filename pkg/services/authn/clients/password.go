@@ -8,7 +8,6 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
-	"github.com/grafana/grafana/pkg/web"
 )
 
 var (
@@ -39,6 +38,17 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 		return nil, errPasswordAuthFailed.Errorf("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
 	}
 
+	ip := remoteAddr(r)
+	if ip != "" {
+		ok, err = c.loginAttempts.ValidateIPAddress(ctx, ip)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errPasswordAuthFailed.Errorf("too many incorrect login attempts from ip address - login temporarily blocked")
+		}
+	}
+
 	if len(password) == 0 {
 		return nil, errPasswordAuthFailed.Errorf("no password provided")
 	}
@@ -57,7 +67,7 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 	}
 
 	if errors.Is(clientErrs, errInvalidPassword) {
-		_ = c.loginAttempts.Add(ctx, username, web.RemoteAddr(r.HTTPRequest))
+		_ = c.loginAttempts.Add(ctx, username, ip)
 	}
 
 	return nil, errPasswordAuthFailed.Errorf("failed to authenticate identity: %w", clientErrs)
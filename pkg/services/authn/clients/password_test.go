@@ -2,6 +2,7 @@ package clients
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -82,3 +83,17 @@ func TestPassword_AuthenticatePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestPassword_AuthenticatePassword_IPAddress(t *testing.T) {
+	attempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+	c := ProvidePassword(attempts, authntest.FakePasswordClient{ExpectedIdentity: &authn.Identity{ID: "1", Type: claims.TypeUser}})
+
+	_, err := c.AuthenticatePassword(context.Background(), &authn.Request{}, "test", "test")
+	assert.NoError(t, err)
+	assert.False(t, attempts.ValidateIPAddressCalled, "requests without an address should not be checked")
+
+	r := &authn.Request{HTTPRequest: &http.Request{RemoteAddr: "10.0.0.1:1234"}}
+	_, err = c.AuthenticatePassword(context.Background(), r, "test", "test")
+	assert.NoError(t, err)
+	assert.True(t, attempts.ValidateIPAddressCalled)
+}
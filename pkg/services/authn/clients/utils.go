@@ -1,8 +1,10 @@
 package clients
 
 import (
+	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 // roleExtractor should return the org role, optional isGrafanaAdmin or an error
@@ -24,3 +26,12 @@ func getRoles(cfg *setting.Cfg, extract roleExtractor) (map[int64]org.RoleType,
 
 	return orgRoles, isGrafanaAdmin, nil
 }
+
+// remoteAddr returns the address of the client that sent the request, or an empty string
+// when the request isn't an HTTP request.
+func remoteAddr(r *authn.Request) string {
+	if r.HTTPRequest == nil {
+		return ""
+	}
+	return web.RemoteAddr(r.HTTPRequest)
+}
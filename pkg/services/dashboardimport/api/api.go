@@ -73,7 +73,7 @@ func (api *ImportDashboardAPI) ImportDashboard(c *contextmodel.ReqContext) respo
 	}
 
 	if limitReached {
-		return response.Error(http.StatusForbidden, "Quota reached", nil)
+		return response.Err(quota.NewErrQuotaReached(dashboards.QuotaTarget))
 	}
 
 	req.User = c.SignedInUser
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -44,6 +45,15 @@ type PermissionsRegistrationService interface {
 	RegisterDashboardPermissions(service accesscontrol.DashboardPermissionsService)
 }
 
+// FolderDefaultsService applies the defaults of the folders to the dashboards saved in them.
+type FolderDefaultsService interface {
+	ApplyFolderDefaults(ctx context.Context, orgID int64, folderUID string, dashboard *simplejson.Json) error
+}
+
+type FolderDefaultsRegistrationService interface {
+	RegisterFolderDefaults(service FolderDefaultsService)
+}
+
 // PluginService is a service for operating on plugin dashboards.
 type PluginService interface {
 	GetDashboardsByPluginID(ctx context.Context, query *GetDashboardsByPluginIDQuery) ([]*Dashboard, error)
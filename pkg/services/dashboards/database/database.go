@@ -626,6 +626,9 @@ func (d *dashboardStore) deleteDashboard(cmd *dashboards.DeleteDashboardCommand,
 	}
 
 	if dashboard.IsFolder {
+		// a folder created later with the same uid must not inherit the defaults of this one
+		sqlStatements = append(sqlStatements, statement{SQL: "DELETE FROM folder_defaults WHERE org_id = ? AND folder_uid = ?", args: []any{dashboard.OrgID, dashboard.UID}})
+
 		if !d.features.IsEnabledGlobally(featuremgmt.FlagDashboardRestore) {
 			sqlStatements = append(sqlStatements, statement{SQL: "DELETE FROM dashboard WHERE org_id = ? AND folder_uid = ? AND is_folder = ? AND deleted IS NULL", args: []any{dashboard.OrgID, dashboard.UID, d.store.GetDialect().BooleanStr(false)}})
 
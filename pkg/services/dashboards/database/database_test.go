@@ -405,6 +405,28 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Should delete the folder defaults of a deleted folder", func(t *testing.T) {
+		setup()
+		folder := insertTestDashboard(t, dashboardStore, "folder with defaults", 1, 0, "", true)
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("INSERT INTO folder_defaults (org_id, folder_uid, timezone, time_from, time_to, min_refresh_interval, default_datasource_uid, created, updated) VALUES (?, ?, 'utc', '', '', '', '', ?, ?)",
+				folder.OrgID, folder.UID, time.Now(), time.Now())
+			return err
+		})
+		require.NoError(t, err)
+
+		err = dashboardStore.DeleteDashboard(context.Background(), &dashboards.DeleteDashboardCommand{ID: folder.ID, OrgID: folder.OrgID})
+		require.NoError(t, err)
+
+		var count int64
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			count, err = sess.Table("folder_defaults").Where("org_id = ? AND folder_uid = ?", folder.OrgID, folder.UID).Count()
+			return err
+		})
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
 	t.Run("Should return error if no dashboard is found for update when dashboard id is greater than zero", func(t *testing.T) {
 		cmd := dashboards.SaveDashboardCommand{
 			OrgID:     1,
@@ -82,6 +82,11 @@ var (
 		Reason:     "Dashboard refresh interval is too low",
 		StatusCode: 400,
 	}
+	ErrDashboardLintFailed = DashboardErr{
+		Reason:     "Dashboard does not comply with the lint rules",
+		StatusCode: 400,
+		Status:     "lint-failed",
+	}
 	ErrDashboardCannotDeleteProvisionedDashboard = DashboardErr{
 		Reason:     "provisioned dashboard cannot be deleted",
 		StatusCode: 400,
@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Rule checks a dashboard and returns a message for each problem found.
+type Rule interface {
+	Check(dashboard *simplejson.Json) []string
+}
+
+// RuleFactory creates a rule from the params of its configuration.
+type RuleFactory func(params map[string]string) (Rule, error)
+
+// Finding is a problem found in a dashboard by a lint rule.
+type Finding struct {
+	// Name is the name of the configured rule
+	Name string `json:"name"`
+	// Rule is the type of the rule
+	Rule    string `json:"rule"`
+	Mode    string `json:"mode"`
+	Message string `json:"message"`
+}
+
+// Result is the result of the lint rules checked on a dashboard.
+type Result struct {
+	Findings []Finding `json:"findings"`
+	Blocked  bool      `json:"blocked"`
+}
+
+type configuredRule struct {
+	setting.DashboardLintRule
+	rule Rule
+}
+
+// Linter checks dashboards against the configured lint rules.
+type Linter struct {
+	rules []configuredRule
+}
+
+// New returns a linter for the rules configured, created by the factories of their type.
+func New(rules []setting.DashboardLintRule, factories map[string]RuleFactory) (*Linter, error) {
+	l := &Linter{rules: make([]configuredRule, 0, len(rules))}
+	for _, r := range rules {
+		factory, ok := factories[r.Rule]
+		if !ok {
+			return nil, fmt.Errorf("lint rule %s: unknown rule %q", r.Name, r.Rule)
+		}
+		rule, err := factory(r.Params)
+		if err != nil {
+			return nil, fmt.Errorf("lint rule %s: %w", r.Name, err)
+		}
+		l.rules = append(l.rules, configuredRule{DashboardLintRule: r, rule: rule})
+	}
+	return l, nil
+}
+
+// Lint checks the dashboard against the rules of its folder. The dashboards at the root
+// are in the general folder.
+func (l *Linter) Lint(folderUID string, dashboard *simplejson.Json) *Result {
+	result := &Result{Findings: make([]Finding, 0)}
+	if l == nil {
+		return result
+	}
+
+	if folderUID == "" {
+		folderUID = folder.GeneralFolderUID
+	}
+	for _, r := range l.rules {
+		if len(r.FolderUIDs) > 0 && !slices.Contains(r.FolderUIDs, folderUID) {
+			continue
+		}
+		for _, message := range r.rule.Check(dashboard) {
+			result.Findings = append(result.Findings, Finding{Name: r.Name, Rule: r.Rule, Mode: r.Mode, Message: message})
+			if r.Mode == setting.DashboardLintModeBlock {
+				result.Blocked = true
+			}
+		}
+	}
+	return result
+}
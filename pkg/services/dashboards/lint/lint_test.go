@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLinter(t *testing.T) {
+	dashboard := simplejson.MustJson([]byte(`{
+		"title": "Services",
+		"tags": ["team:ops"],
+		"refresh": "5s",
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Latency", "datasource": {"type": "prometheus", "uid": "${ds}"}, "targets": [
+				{"datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"}}
+			]},
+			{"id": 2, "type": "row", "title": "Details", "collapsed": true, "panels": [
+				{"id": 3, "type": "graph", "datasource": {"type": "loki", "uid": "L4K1"}}
+			]}
+		],
+		"templating": {"list": [
+			{"name": "ds", "type": "datasource", "query": "prometheus"},
+			{"name": "job", "type": "query", "datasource": {"type": "prometheus", "uid": "$ds"}}
+		]},
+		"annotations": {"list": [
+			{"name": "Annotations & Alerts", "builtIn": 1, "datasource": {"type": "grafana", "uid": "-- Grafana --"}},
+			{"name": "Deploys", "datasource": {"type": "loki", "uid": "L4K1"}}
+		]}
+	}`))
+
+	lint := func(t *testing.T, folderUID string, rules ...setting.DashboardLintRule) *Result {
+		t.Helper()
+		l, err := New(rules, BuiltinRules())
+		require.NoError(t, err)
+		return l.Lint(folderUID, dashboard)
+	}
+
+	t.Run("required tags", func(t *testing.T) {
+		result := lint(t, "", setting.DashboardLintRule{Name: "tags", Rule: RuleRequiredTags, Mode: setting.DashboardLintModeWarn, Params: map[string]string{"tags": "team:ops service"}})
+		assert.Equal(t, &Result{Findings: []Finding{
+			{Name: "tags", Rule: RuleRequiredTags, Mode: setting.DashboardLintModeWarn, Message: `dashboard must have the tag "service"`},
+		}}, result)
+
+		result = lint(t, "", setting.DashboardLintRule{Name: "tags", Rule: RuleRequiredTags, Mode: setting.DashboardLintModeWarn})
+		assert.Empty(t, result.Findings)
+	})
+
+	t.Run("no hard-coded data source uids", func(t *testing.T) {
+		result := lint(t, "", setting.DashboardLintRule{Name: "datasources", Rule: RuleNoHardcodedDatasource, Mode: setting.DashboardLintModeWarn})
+		messages := make([]string, 0)
+		for _, f := range result.Findings {
+			messages = append(messages, f.Message)
+		}
+		assert.Equal(t, []string{
+			`query 1 of panel "Latency" uses the data source uid "P1809F7CD0C75ACF3" instead of a variable`,
+			`panel 3 uses the data source uid "L4K1" instead of a variable`,
+			`annotation "Deploys" uses the data source uid "L4K1" instead of a variable`,
+		}, messages)
+	})
+
+	t.Run("max panels", func(t *testing.T) {
+		result := lint(t, "", setting.DashboardLintRule{Name: "panels", Rule: RuleMaxPanels, Mode: setting.DashboardLintModeBlock, Params: map[string]string{"max": "1"}})
+		assert.Equal(t, &Result{Findings: []Finding{
+			{Name: "panels", Rule: RuleMaxPanels, Mode: setting.DashboardLintModeBlock, Message: "dashboard has 2 panels, the maximum is 1"},
+		}, Blocked: true}, result)
+
+		result = lint(t, "", setting.DashboardLintRule{Name: "panels", Rule: RuleMaxPanels, Mode: setting.DashboardLintModeBlock, Params: map[string]string{"max": "2"}})
+		assert.False(t, result.Blocked)
+	})
+
+	t.Run("min refresh interval", func(t *testing.T) {
+		result := lint(t, "", setting.DashboardLintRule{Name: "refresh", Rule: RuleMinRefreshInterval, Mode: setting.DashboardLintModeBlock, Params: map[string]string{"min_interval": "1m"}})
+		assert.Equal(t, &Result{Findings: []Finding{
+			{Name: "refresh", Rule: RuleMinRefreshInterval, Mode: setting.DashboardLintModeBlock, Message: "refresh interval 5s is shorter than the minimum 1m"},
+		}, Blocked: true}, result)
+	})
+
+	t.Run("deprecated panel types", func(t *testing.T) {
+		result := lint(t, "", setting.DashboardLintRule{Name: "panels", Rule: RuleDeprecatedPanelTypes, Mode: setting.DashboardLintModeWarn})
+		assert.Equal(t, &Result{Findings: []Finding{
+			{Name: "panels", Rule: RuleDeprecatedPanelTypes, Mode: setting.DashboardLintModeWarn, Message: `panel 3 uses the deprecated panel type "graph"`},
+		}}, result)
+
+		result = lint(t, "", setting.DashboardLintRule{Name: "panels", Rule: RuleDeprecatedPanelTypes, Mode: setting.DashboardLintModeWarn, Params: map[string]string{"types": "row"}})
+		assert.Len(t, result.Findings, 1)
+	})
+
+	t.Run("rules apply to the dashboards of their folders", func(t *testing.T) {
+		rule := setting.DashboardLintRule{Name: "refresh", Rule: RuleMinRefreshInterval, Mode: setting.DashboardLintModeBlock, FolderUIDs: []string{"ops", "general"}, Params: map[string]string{"min_interval": "1m"}}
+		assert.True(t, lint(t, "ops", rule).Blocked)
+		assert.True(t, lint(t, "", rule).Blocked)
+		assert.False(t, lint(t, "dev", rule).Blocked)
+	})
+
+	t.Run("invalid rules fail", func(t *testing.T) {
+		for _, rule := range []setting.DashboardLintRule{
+			{Name: "unknown", Rule: "no_graphs"},
+			{Name: "panels", Rule: RuleMaxPanels, Params: map[string]string{"max": "many"}},
+			{Name: "refresh", Rule: RuleMinRefreshInterval},
+		} {
+			_, err := New([]setting.DashboardLintRule{rule}, BuiltinRules())
+			assert.Error(t, err, rule.Name)
+		}
+	})
+
+	t.Run("a nil linter finds nothing", func(t *testing.T) {
+		var l *Linter
+		assert.Equal(t, &Result{Findings: []Finding{}}, l.Lint("", dashboard))
+	})
+}
@@ -0,0 +1,182 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	RuleRequiredTags          = "required_tags"
+	RuleNoHardcodedDatasource = "no_hardcoded_datasource_uids"
+	RuleMaxPanels             = "max_panels"
+	RuleMinRefreshInterval    = "min_refresh_interval"
+	RuleDeprecatedPanelTypes  = "deprecated_panel_types"
+)
+
+// deprecatedPanelTypes are the panels migrated by the frontend to their replacement when they are loaded
+var deprecatedPanelTypes = []string{"graph", "table-old", "singlestat", "grafana-singlestat-panel", "grafana-piechart-panel", "grafana-worldmap-panel"}
+
+// builtinDatasourceUIDs are the data sources available in every instance
+var builtinDatasourceUIDs = []string{"grafana", "-- Grafana --", "-- Mixed --", "-- Dashboard --", "__expr__"}
+
+// BuiltinRules returns the factories of the rules provided by Grafana.
+func BuiltinRules() map[string]RuleFactory {
+	return map[string]RuleFactory{
+		RuleRequiredTags:          newRequiredTagsRule,
+		RuleNoHardcodedDatasource: newNoHardcodedDatasourceRule,
+		RuleMaxPanels:             newMaxPanelsRule,
+		RuleMinRefreshInterval:    newMinRefreshIntervalRule,
+		RuleDeprecatedPanelTypes:  newDeprecatedPanelTypesRule,
+	}
+}
+
+type ruleFunc func(dashboard *simplejson.Json) []string
+
+func (f ruleFunc) Check(dashboard *simplejson.Json) []string {
+	return f(dashboard)
+}
+
+// newRequiredTagsRule requires the dashboards to have all the tags, or at least one tag if none are set.
+func newRequiredTagsRule(params map[string]string) (Rule, error) {
+	required := util.SplitString(params["tags"])
+	return ruleFunc(func(dashboard *simplejson.Json) []string {
+		tags := dashboard.Get("tags").MustStringArray()
+		if len(required) == 0 && len(tags) == 0 {
+			return []string{"dashboard must have at least one tag"}
+		}
+
+		messages := make([]string, 0)
+		for _, tag := range required {
+			if !slices.Contains(tags, tag) {
+				messages = append(messages, fmt.Sprintf("dashboard must have the tag %q", tag))
+			}
+		}
+		return messages
+	}), nil
+}
+
+// newNoHardcodedDatasourceRule requires the data sources of the panels, queries, variables and annotations
+// to be referenced through a variable, so that the dashboard works with the data sources of any instance.
+func newNoHardcodedDatasourceRule(_ map[string]string) (Rule, error) {
+	return ruleFunc(func(dashboard *simplejson.Json) []string {
+		messages := make([]string, 0)
+		for _, panel := range panels(dashboard) {
+			if uid, ok := hardcodedDatasourceUID(panel.Get("datasource")); ok {
+				messages = append(messages, fmt.Sprintf("%s uses the data source uid %q instead of a variable", panelName(panel), uid))
+			}
+			for i, target := range panel.Get("targets").MustArray() {
+				if uid, ok := hardcodedDatasourceUID(simplejson.NewFromAny(target).Get("datasource")); ok {
+					messages = append(messages, fmt.Sprintf("query %d of %s uses the data source uid %q instead of a variable", i+1, panelName(panel), uid))
+				}
+			}
+		}
+		for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+			variable := simplejson.NewFromAny(v)
+			if uid, ok := hardcodedDatasourceUID(variable.Get("datasource")); ok {
+				messages = append(messages, fmt.Sprintf("variable %q uses the data source uid %q instead of a variable", variable.Get("name").MustString(), uid))
+			}
+		}
+		for _, a := range dashboard.GetPath("annotations", "list").MustArray() {
+			annotation := simplejson.NewFromAny(a)
+			if uid, ok := hardcodedDatasourceUID(annotation.Get("datasource")); ok {
+				messages = append(messages, fmt.Sprintf("annotation %q uses the data source uid %q instead of a variable", annotation.Get("name").MustString(), uid))
+			}
+		}
+		return messages
+	}), nil
+}
+
+// newMaxPanelsRule limits the number of panels of the dashboards, rows excluded.
+func newMaxPanelsRule(params map[string]string) (Rule, error) {
+	maxPanels, err := strconv.Atoi(params["max"])
+	if err != nil || maxPanels <= 0 {
+		return nil, fmt.Errorf("invalid max %q, must be a positive number", params["max"])
+	}
+	return ruleFunc(func(dashboard *simplejson.Json) []string {
+		count := 0
+		for _, panel := range panels(dashboard) {
+			if panel.Get("type").MustString() != "row" {
+				count++
+			}
+		}
+		if count > maxPanels {
+			return []string{fmt.Sprintf("dashboard has %d panels, the maximum is %d", count, maxPanels)}
+		}
+		return nil
+	}), nil
+}
+
+// newMinRefreshIntervalRule requires the dashboards to refresh at most as often as the interval.
+func newMinRefreshIntervalRule(params map[string]string) (Rule, error) {
+	minInterval, err := gtime.ParseDuration(params["min_interval"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid min_interval %q: %w", params["min_interval"], err)
+	}
+	return ruleFunc(func(dashboard *simplejson.Json) []string {
+		refresh := dashboard.Get("refresh").MustString()
+		if refresh == "" || refresh == "auto" {
+			return nil
+		}
+		d, err := gtime.ParseDuration(refresh)
+		if err != nil {
+			return []string{fmt.Sprintf("invalid refresh interval %q", refresh)}
+		}
+		if d < minInterval {
+			return []string{fmt.Sprintf("refresh interval %s is shorter than the minimum %s", refresh, params["min_interval"])}
+		}
+		return nil
+	}), nil
+}
+
+// newDeprecatedPanelTypesRule forbids the panel types, the ones migrated by the frontend if none are set.
+func newDeprecatedPanelTypesRule(params map[string]string) (Rule, error) {
+	types := util.SplitString(params["types"])
+	if len(types) == 0 {
+		types = deprecatedPanelTypes
+	}
+	return ruleFunc(func(dashboard *simplejson.Json) []string {
+		messages := make([]string, 0)
+		for _, panel := range panels(dashboard) {
+			if t := panel.Get("type").MustString(); slices.Contains(types, t) {
+				messages = append(messages, fmt.Sprintf("%s uses the deprecated panel type %q", panelName(panel), t))
+			}
+		}
+		return messages
+	}), nil
+}
+
+// panels returns the panels of the dashboard, including the ones of the collapsed rows.
+func panels(dashboard *simplejson.Json) []*simplejson.Json {
+	result := make([]*simplejson.Json, 0)
+	for _, p := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		result = append(result, panel)
+		for _, nested := range panel.Get("panels").MustArray() {
+			result = append(result, simplejson.NewFromAny(nested))
+		}
+	}
+	return result
+}
+
+func panelName(panel *simplejson.Json) string {
+	if title := panel.Get("title").MustString(); title != "" {
+		return fmt.Sprintf("panel %q", title)
+	}
+	return fmt.Sprintf("panel %d", panel.Get("id").MustInt64())
+}
+
+// hardcodedDatasourceUID returns the uid of a data source reference that is neither a variable nor a built-in data source.
+func hardcodedDatasourceUID(ref *simplejson.Json) (string, bool) {
+	uid := ref.Get("uid").MustString()
+	if uid == "" || strings.HasPrefix(uid, "$") || slices.Contains(builtinDatasourceUIDs, uid) {
+		return "", false
+	}
+	return uid, true
+}
@@ -294,12 +294,13 @@ type GetDashboardRefByIDQuery struct {
 }
 
 type SaveDashboardDTO struct {
-	OrgID     int64
-	UpdatedAt time.Time
-	User      identity.Requester
-	Message   string
-	Overwrite bool
-	Dashboard *Dashboard
+	OrgID        int64
+	UpdatedAt    time.Time
+	User         identity.Requester
+	Message      string
+	Overwrite    bool
+	Dashboard    *Dashboard
+	RestoredFrom int
 }
 
 type DashboardSearchProjection struct {
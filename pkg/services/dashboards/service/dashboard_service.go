@@ -37,6 +37,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/dashboards/lint"
 	dashboardsearch "github.com/grafana/grafana/pkg/services/dashboards/service/search"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -77,11 +78,14 @@ type DashboardServiceImpl struct {
 	k8sclient              client.K8sHandler
 	metrics                *dashboardsMetrics
 	publicDashboardService publicdashboards.ServiceWrapper
+	linter                 *lint.Linter
+	folderDefaults         dashboards.FolderDefaultsService
 
 	dashboardPermissionsReady chan struct{}
 }
 
 var _ dashboards.PermissionsRegistrationService = (*DashboardServiceImpl)(nil)
+var _ dashboards.FolderDefaultsRegistrationService = (*DashboardServiceImpl)(nil)
 
 // This is the uber service that implements a three smaller services
 func ProvideDashboardServiceImpl(
@@ -93,6 +97,11 @@ func ProvideDashboardServiceImpl(
 ) (*DashboardServiceImpl, error) {
 	k8sHandler := client.NewK8sHandler(cfg, request.GetNamespaceMapper(cfg), v0alpha1.DashboardResourceInfo.GroupVersionResource(), restConfigProvider, unified, dashboardStore, userService)
 
+	linter, err := lint.New(cfg.DashboardLintRules, lint.BuiltinRules())
+	if err != nil {
+		return nil, err
+	}
+
 	dashSvc := &DashboardServiceImpl{
 		cfg:                       cfg,
 		log:                       log.New("dashboard-service"),
@@ -107,6 +116,7 @@ func ProvideDashboardServiceImpl(
 		metrics:                   newDashboardsMetrics(r),
 		dashboardPermissionsReady: make(chan struct{}),
 		publicDashboardService:    publicDashboardService,
+		linter:                    linter,
 	}
 
 	defaultLimits, err := readQuotaConfig(cfg)
@@ -136,6 +146,10 @@ func (dr *DashboardServiceImpl) RegisterDashboardPermissions(service accesscontr
 	close(dr.dashboardPermissionsReady)
 }
 
+func (dr *DashboardServiceImpl) RegisterFolderDefaults(service dashboards.FolderDefaultsService) {
+	dr.folderDefaults = service
+}
+
 func (dr *DashboardServiceImpl) getPermissionsService(isFolder bool) accesscontrol.PermissionsService {
 	if isFolder {
 		return dr.folderPermissions
@@ -145,6 +159,29 @@ func (dr *DashboardServiceImpl) getPermissionsService(isFolder bool) accesscontr
 }
 
 func (dr *DashboardServiceImpl) Count(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u, err := dr.count(ctx, scopeParams)
+	if err != nil {
+		return u, err
+	}
+
+	if scopeParams != nil && scopeParams.OrgID != 0 && scopeParams.FolderUID != "" {
+		folderCtx, requester := identity.WithServiceIdentitiy(ctx, scopeParams.OrgID)
+		folderDashboards, err := dr.CountInFolders(folderCtx, scopeParams.OrgID, []string{scopeParams.FolderUID}, requester)
+		if err != nil {
+			return u, err
+		}
+
+		tag, err := quota.NewTag(dashboards.QuotaTargetSrv, dashboards.QuotaTarget, quota.FolderScope)
+		if err != nil {
+			return nil, err
+		}
+		u.Set(tag, folderDashboards)
+	}
+
+	return u, nil
+}
+
+func (dr *DashboardServiceImpl) count(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
 	if dr.features.IsEnabledGlobally(featuremgmt.FlagKubernetesCliDashboards) {
 		u := &quota.Map{}
 		orgs, err := dr.orgService.Search(ctx, &org.SearchOrgsQuery{})
@@ -212,9 +249,14 @@ func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
 	if err != nil {
 		return &quota.Map{}, err
 	}
+	folderQuotaTag, err := quota.NewTag(dashboards.QuotaTargetSrv, dashboards.QuotaTarget, quota.FolderScope)
+	if err != nil {
+		return &quota.Map{}, err
+	}
 
 	limits.Set(globalQuotaTag, cfg.Quota.Global.Dashboard)
 	limits.Set(orgQuotaTag, cfg.Quota.Org.Dashboard)
+	limits.Set(folderQuotaTag, cfg.Quota.Folder.Dashboard)
 	return limits, nil
 }
 
@@ -393,6 +435,17 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		dash.FolderUID = folder.UID
 	}
 
+	if !dash.IsFolder {
+		if dr.folderDefaults != nil {
+			if err := dr.folderDefaults.ApplyFolderDefaults(ctx, dash.OrgID, dash.FolderUID, dash.Data); err != nil {
+				return nil, err
+			}
+		}
+		if err := dr.lintDashboard(dash); err != nil {
+			return nil, err
+		}
+	}
+
 	isParentFolderChanged, err := dr.ValidateDashboardBeforeSave(ctx, dash, dto.Overwrite)
 	if err != nil {
 		return nil, err
@@ -457,15 +510,16 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Dashboard).Inc()
 	cmd := &dashboards.SaveDashboardCommand{
-		Dashboard: dash.Data,
-		Message:   dto.Message,
-		OrgID:     dto.OrgID,
-		Overwrite: dto.Overwrite,
-		UserID:    userID,
-		FolderID:  dash.FolderID, // nolint:staticcheck
-		FolderUID: dash.FolderUID,
-		IsFolder:  dash.IsFolder,
-		PluginID:  dash.PluginID,
+		Dashboard:    dash.Data,
+		Message:      dto.Message,
+		OrgID:        dto.OrgID,
+		Overwrite:    dto.Overwrite,
+		UserID:       userID,
+		FolderID:     dash.FolderID, // nolint:staticcheck
+		FolderUID:    dash.FolderUID,
+		IsFolder:     dash.IsFolder,
+		PluginID:     dash.PluginID,
+		RestoredFrom: dto.RestoredFrom,
 	}
 
 	if !dto.UpdatedAt.IsZero() {
@@ -646,6 +700,29 @@ func validateDashboardRefreshInterval(minRefreshInterval string, dash *dashboard
 	return nil
 }
 
+// lintDashboard checks the dashboard against the lint rules of its folder. The findings of the rules
+// in warn mode are logged, the ones in block mode reject the dashboard.
+func (dr *DashboardServiceImpl) lintDashboard(dash *dashboards.Dashboard) error {
+	result := dr.linter.Lint(dash.FolderUID, dash.Data)
+
+	blocking := make([]string, 0)
+	for _, f := range result.Findings {
+		if f.Mode == setting.DashboardLintModeBlock {
+			blocking = append(blocking, f.Message)
+			continue
+		}
+		dr.log.Warn("Dashboard does not comply with lint rule", "dashboardUid", dash.UID, "dashboardTitle", dash.Title,
+			"rule", f.Name, "message", f.Message)
+	}
+
+	if len(blocking) > 0 {
+		err := dashboards.ErrDashboardLintFailed
+		err.Reason = fmt.Sprintf("%s: %s", err.Reason, strings.Join(blocking, "; "))
+		return err
+	}
+	return nil
+}
+
 func (dr *DashboardServiceImpl) SaveProvisionedDashboard(ctx context.Context, dto *dashboards.SaveDashboardDTO,
 	provisioning *dashboards.DashboardProvisioning) (*dashboards.Dashboard, error) {
 	ctx, span := tracer.Start(ctx, "dashboards.service.SaveProvisionedDashboard")
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/apiserver/client"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/lint"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/foldertest"
@@ -148,6 +149,43 @@ func TestDashboardService(t *testing.T) {
 				_, err := service.SaveDashboard(context.Background(), dto, true)
 				require.NoError(t, err)
 			})
+
+			t.Run("Should return validation error if dashboard does not comply with blocking lint rules", func(t *testing.T) {
+				linter, err := lint.New([]setting.DashboardLintRule{
+					{Name: "tags", Rule: lint.RuleRequiredTags, Mode: setting.DashboardLintModeWarn},
+					{Name: "refresh", Rule: lint.RuleMinRefreshInterval, Mode: setting.DashboardLintModeBlock, Params: map[string]string{"min_interval": "1m"}},
+				}, lint.BuiltinRules())
+				require.NoError(t, err)
+				service.linter = linter
+				defer func() { service.linter = nil }()
+
+				dto.Dashboard = dashboards.NewDashboard("Dash")
+				dto.Dashboard.Data.Set("refresh", "10s")
+				dto.User = &user.SignedInUser{UserID: 1}
+				_, err = service.SaveDashboard(context.Background(), dto, false)
+				var dashboardErr dashboards.DashboardErr
+				require.ErrorAs(t, err, &dashboardErr)
+				require.Equal(t, "lint-failed", dashboardErr.Status)
+				require.Equal(t, "Dashboard does not comply with the lint rules: refresh interval 10s is shorter than the minimum 1m", dashboardErr.Reason)
+
+				dto.Dashboard = dashboards.NewDashboard("Dash")
+				dto.Dashboard.Data.Set("refresh", "1m")
+				_, err = service.BuildSaveDashboardCommand(context.Background(), dto, false)
+				require.NoError(t, err)
+			})
+
+			t.Run("Should apply the folder defaults before the dashboard is saved", func(t *testing.T) {
+				service.RegisterFolderDefaults(fakeFolderDefaultsService(func(dashboard *simplejson.Json) {
+					dashboard.Set("tags", []any{"team"})
+				}))
+				defer func() { service.folderDefaults = nil }()
+
+				dto.Dashboard = dashboards.NewDashboard("Dash")
+				dto.User = &user.SignedInUser{UserID: 1}
+				cmd, err := service.BuildSaveDashboardCommand(context.Background(), dto, false)
+				require.NoError(t, err)
+				require.Equal(t, []string{"team"}, cmd.GetDashboardModel().GetTags())
+			})
 		})
 
 		t.Run("Save provisioned dashboard validation", func(t *testing.T) {
@@ -1803,6 +1841,25 @@ func TestQuotaCount(t *testing.T) {
 
 		fakeStore.AssertExpectations(t)
 	})
+
+	t.Run("Should report the folder usage if a folder is given", func(t *testing.T) {
+		service.features = featuremgmt.WithFeatures()
+		fakeStore.On("Count", mock.Anything, mock.Anything).Return(&quota.Map{}, nil).Once()
+		fakeStore.On("CountDashboardsInFolders", mock.Anything, &dashboards.CountDashboardsInFolderRequest{
+			FolderUIDs: []string{"folder1"},
+			OrgID:      1,
+		}).Return(int64(4), nil).Once()
+
+		result, err := service.Count(context.Background(), &quota.ScopeParameters{OrgID: 1, FolderUID: "folder1"})
+		require.NoError(t, err)
+
+		folderTag, err := quota.NewTag(dashboards.QuotaTargetSrv, dashboards.QuotaTarget, quota.FolderScope)
+		require.NoError(t, err)
+		c, ok := result.Get(folderTag)
+		require.True(t, ok)
+		require.Equal(t, int64(4), c)
+		fakeStore.AssertExpectations(t)
+	})
 }
 
 func TestCountDashboardsInOrg(t *testing.T) {
@@ -1931,3 +1988,10 @@ func TestLegacySaveCommandToUnstructured(t *testing.T) {
 		assert.Equal(t, result.GetAnnotations(), map[string]string(nil))
 	})
 }
+
+type fakeFolderDefaultsService func(dashboard *simplejson.Json)
+
+func (f fakeFolderDefaultsService) ApplyFolderDefaults(_ context.Context, _ int64, _ string, dashboard *simplejson.Json) error {
+	f(dashboard)
+	return nil
+}
@@ -2,10 +2,18 @@ package dashver
 
 import (
 	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
 )
 
 type Service interface {
 	Get(context.Context, *GetDashboardVersionQuery) (*DashboardVersionDTO, error)
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) (*DashboardVersionResponse, error)
+	// Diff returns the JSON patch transforming a version of a dashboard into another one.
+	Diff(context.Context, *DiffDashboardVersionsQuery) (*DashboardVersionDiff, error)
+	// Blame returns, for every panel of the latest version of a dashboard, the version which last changed it.
+	Blame(context.Context, *BlameDashboardVersionsQuery) (*DashboardVersionBlame, error)
+	// Restore saves a previous version of a dashboard as a new version.
+	Restore(context.Context, *RestoreDashboardVersionCommand) (*dashboards.Dashboard, error)
 }
@@ -0,0 +1,235 @@
+package dashverimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+)
+
+// blamePageSize is the number of versions fetched at once when computing the blame of a dashboard
+const blamePageSize = 100
+
+// Diff returns the JSON patch transforming the base version of the dashboard into the new version.
+func (s *Service) Diff(ctx context.Context, query *dashver.DiffDashboardVersionsQuery) (*dashver.DashboardVersionDiff, error) {
+	base, err := s.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  query.DashboardID,
+		DashboardUID: query.DashboardUID,
+		OrgID:        query.OrgID,
+		Version:      query.BaseVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newVersion, err := s.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  base.DashboardID,
+		DashboardUID: base.DashboardUID,
+		OrgID:        query.OrgID,
+		Version:      query.NewVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dashver.DashboardVersionDiff{
+		DashboardUID: base.DashboardUID,
+		BaseVersion:  base.Version,
+		NewVersion:   newVersion.Version,
+		Patch:        dashdiffs.JSONPatch(base.Data.Interface(), newVersion.Data.Interface()),
+	}, nil
+}
+
+// Blame walks through the versions of the dashboard, from the oldest to the latest, and returns
+// for every panel of the latest version the version in which it was last changed.
+func (s *Service) Blame(ctx context.Context, query *dashver.BlameDashboardVersionsQuery) (*dashver.DashboardVersionBlame, error) {
+	versions, err := s.listAll(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, dashver.ErrNoVersionsForDashboardID
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	type panelState struct {
+		panel map[string]any
+		blame dashver.PanelBlame
+	}
+	states := make(map[int64]*panelState)
+
+	var panels []map[string]any
+	for _, version := range versions {
+		panels = flattenPanels(version.Data)
+		for _, panel := range panels {
+			id, ok := panelID(panel)
+			if !ok {
+				continue
+			}
+
+			state, found := states[id]
+			if found && reflect.DeepEqual(state.panel, panel) {
+				continue
+			}
+
+			states[id] = &panelState{
+				panel: panel,
+				blame: dashver.PanelBlame{
+					PanelID:   id,
+					Version:   version.Version,
+					Created:   version.Created,
+					CreatedBy: version.CreatedBy,
+					Message:   version.Message,
+				},
+			}
+		}
+	}
+
+	latest := versions[len(versions)-1]
+	result := &dashver.DashboardVersionBlame{
+		DashboardUID:  latest.DashboardUID,
+		Version:       latest.Version,
+		OldestVersion: versions[0].Version,
+		Panels:        make([]dashver.PanelBlame, 0, len(panels)),
+	}
+
+	// only the panels of the latest version are reported, in the order they appear in the dashboard
+	for _, panel := range panels {
+		id, ok := panelID(panel)
+		if !ok {
+			continue
+		}
+		blame := states[id].blame
+		blame.Title, _ = panel["title"].(string)
+		result.Panels = append(result.Panels, blame)
+	}
+
+	return result, nil
+}
+
+// Restore saves a previous version of the dashboard as a new version.
+func (s *Service) Restore(ctx context.Context, cmd *dashver.RestoreDashboardVersionCommand) (*dashboards.Dashboard, error) {
+	dash, err := s.dashSvc.GetDashboard(ctx, &dashboards.GetDashboardQuery{
+		ID:    cmd.DashboardID, // nolint:staticcheck
+		UID:   cmd.DashboardUID,
+		OrgID: cmd.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+		OrgID:        cmd.OrgID,
+		Version:      cmd.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := version.Data
+	data.Set("id", dash.ID)
+	data.Set("uid", dash.UID)
+	data.Set("version", dash.Version)
+
+	restored := dashboards.NewDashboardFromJson(data)
+	restored.FolderID = dash.FolderID // nolint:staticcheck
+	restored.FolderUID = dash.FolderUID
+
+	message := cmd.Message
+	if message == "" {
+		message = DashboardRestoreMessage(version.Version)
+	}
+
+	return s.dashSvc.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:        cmd.OrgID,
+		User:         cmd.User,
+		Message:      message,
+		Dashboard:    restored,
+		RestoredFrom: version.Version,
+	}, cmd.AllowUIUpdate)
+}
+
+// listAll returns all the versions kept for the dashboard, with their data
+func (s *Service) listAll(ctx context.Context, query *dashver.BlameDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
+	listQuery := &dashver.ListDashboardVersionsQuery{
+		DashboardID:  query.DashboardID,
+		DashboardUID: query.DashboardUID,
+		OrgID:        query.OrgID,
+		Limit:        blamePageSize,
+	}
+
+	versions := make([]*dashver.DashboardVersionDTO, 0)
+	for {
+		res, err := s.List(ctx, listQuery)
+		if err != nil {
+			if errors.Is(err, dashver.ErrNoVersionsForDashboardID) {
+				break
+			}
+			return nil, err
+		}
+		versions = append(versions, res.Versions...)
+
+		if res.ContinueToken != "" {
+			listQuery.ContinueToken = res.ContinueToken
+			continue
+		}
+		if len(res.Versions) < blamePageSize {
+			break
+		}
+		listQuery.Start += blamePageSize
+	}
+
+	return versions, nil
+}
+
+// flattenPanels returns the panels of the dashboard, including the panels of collapsed rows
+func flattenPanels(data *simplejson.Json) []map[string]any {
+	result := make([]map[string]any, 0)
+	if data == nil {
+		return result
+	}
+
+	for _, p := range data.Get("panels").MustArray() {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		result = append(result, panel)
+
+		if nested, ok := panel["panels"].([]any); ok {
+			for _, n := range nested {
+				if nestedPanel, ok := n.(map[string]any); ok {
+					result = append(result, nestedPanel)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func panelID(panel map[string]any) (int64, bool) {
+	switch id := panel["id"].(type) {
+	case float64:
+		return int64(id), true
+	case int64:
+		return id, true
+	case int:
+		return int64(id), true
+	case json.Number:
+		v, err := id.Int64()
+		return v, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,184 @@
+package dashverimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestDashboardVersionHistory(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	versions := []*dashver.DashboardVersion{
+		{ID: 3, DashboardID: 42, Version: 3, CreatedBy: 2, Created: created.Add(2 * time.Hour), Message: "rename memory", Data: simplejson.NewFromAny(map[string]any{
+			"title": "Hosts",
+			"panels": []any{
+				map[string]any{"id": float64(1), "title": "CPU"},
+				map[string]any{"id": float64(3), "type": "row", "collapsed": true, "panels": []any{
+					map[string]any{"id": float64(2), "title": "RAM"},
+				}},
+			},
+		})},
+		{ID: 2, DashboardID: 42, Version: 2, CreatedBy: 1, Created: created.Add(time.Hour), Message: "add memory", Data: simplejson.NewFromAny(map[string]any{
+			"title": "Hosts",
+			"panels": []any{
+				map[string]any{"id": float64(1), "title": "CPU"},
+				map[string]any{"id": float64(2), "title": "Memory"},
+			},
+		})},
+		{ID: 1, DashboardID: 42, Version: 1, CreatedBy: 1, Created: created, Data: simplejson.NewFromAny(map[string]any{
+			"title": "Hosts",
+			"panels": []any{
+				map[string]any{"id": float64(1), "title": "CPU"},
+			},
+		})},
+	}
+
+	newService := func(t *testing.T) (*Service, *dashboards.FakeDashboardService) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		store := &historyStoreFake{versions: versions}
+		return &Service{store: store, dashSvc: dashboardService, features: featuremgmt.WithFeatures()}, dashboardService
+	}
+
+	t.Run("Diff returns the patch between two versions", func(t *testing.T) {
+		s, _ := newService(t)
+
+		diff, err := s.Diff(context.Background(), &dashver.DiffDashboardVersionsQuery{
+			DashboardID:  42,
+			DashboardUID: "uid",
+			OrgID:        1,
+			BaseVersion:  1,
+			NewVersion:   2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, &dashver.DashboardVersionDiff{
+			DashboardUID: "uid",
+			BaseVersion:  1,
+			NewVersion:   2,
+			Patch: []dashver.JSONPatchOperation{
+				{Op: "add", Path: "/panels/1", Value: map[string]any{"id": float64(2), "title": "Memory"}},
+			},
+		}, diff)
+	})
+
+	t.Run("Diff fails when a version does not exist", func(t *testing.T) {
+		s, _ := newService(t)
+
+		_, err := s.Diff(context.Background(), &dashver.DiffDashboardVersionsQuery{
+			DashboardID:  42,
+			DashboardUID: "uid",
+			OrgID:        1,
+			BaseVersion:  1,
+			NewVersion:   9,
+		})
+		require.ErrorIs(t, err, dashver.ErrDashboardVersionNotFound)
+	})
+
+	t.Run("Blame attributes every panel to the version which last changed it", func(t *testing.T) {
+		s, _ := newService(t)
+
+		blame, err := s.Blame(context.Background(), &dashver.BlameDashboardVersionsQuery{
+			DashboardID:  42,
+			DashboardUID: "uid",
+			OrgID:        1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, &dashver.DashboardVersionBlame{
+			DashboardUID:  "uid",
+			Version:       3,
+			OldestVersion: 1,
+			Panels: []dashver.PanelBlame{
+				{PanelID: 1, Title: "CPU", Version: 1, Created: created, CreatedBy: 1},
+				{PanelID: 3, Version: 3, Created: created.Add(2 * time.Hour), CreatedBy: 2, Message: "rename memory"},
+				{PanelID: 2, Title: "RAM", Version: 3, Created: created.Add(2 * time.Hour), CreatedBy: 2, Message: "rename memory"},
+			},
+		}, blame)
+	})
+
+	t.Run("Restore saves the version as a new version", func(t *testing.T) {
+		s, dashboardService := newService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).
+			Return(&dashboards.Dashboard{ID: 42, UID: "uid", OrgID: 1, Version: 3, FolderUID: "folder"}, nil)
+
+		var saved *dashboards.SaveDashboardDTO
+		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), false).
+			Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*dashboards.SaveDashboardDTO)
+			}).
+			Return(&dashboards.Dashboard{ID: 42, UID: "uid", Version: 4}, nil)
+
+		requester := &user.SignedInUser{UserID: 1, OrgID: 1}
+		dash, err := s.Restore(context.Background(), &dashver.RestoreDashboardVersionCommand{
+			DashboardUID: "uid",
+			OrgID:        1,
+			Version:      1,
+			User:         requester,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 4, dash.Version)
+
+		require.Equal(t, "Restored from version 1", saved.Message)
+		require.Equal(t, 1, saved.RestoredFrom)
+		require.Equal(t, requester, saved.User)
+		require.Equal(t, "folder", saved.Dashboard.FolderUID)
+		require.Equal(t, "uid", saved.Dashboard.UID)
+		require.Equal(t, 3, saved.Dashboard.Version)
+		require.Len(t, saved.Dashboard.Data.Get("panels").MustArray(), 1)
+	})
+
+	t.Run("Restore uses the given message", func(t *testing.T) {
+		s, dashboardService := newService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).
+			Return(&dashboards.Dashboard{ID: 42, UID: "uid", OrgID: 1, Version: 3}, nil)
+		dashboardService.On("SaveDashboard", mock.Anything, mock.MatchedBy(func(dto *dashboards.SaveDashboardDTO) bool {
+			return dto.Message == "roll back the memory panel" && dto.RestoredFrom == 2
+		}), true).Return(&dashboards.Dashboard{ID: 42, UID: "uid", Version: 4}, nil)
+
+		_, err := s.Restore(context.Background(), &dashver.RestoreDashboardVersionCommand{
+			DashboardUID:  "uid",
+			OrgID:         1,
+			Version:       2,
+			User:          &user.SignedInUser{UserID: 1, OrgID: 1},
+			Message:       "roll back the memory panel",
+			AllowUIUpdate: true,
+		})
+		require.NoError(t, err)
+	})
+}
+
+// historyStoreFake serves the versions it holds, the versions are expected to be ordered by version DESC
+type historyStoreFake struct {
+	FakeDashboardVersionStore
+	versions []*dashver.DashboardVersion
+}
+
+func (f *historyStoreFake) Get(ctx context.Context, query *dashver.GetDashboardVersionQuery) (*dashver.DashboardVersion, error) {
+	for _, v := range f.versions {
+		if int64(v.Version) == query.Version {
+			// return a copy, the service modifies the data of the version
+			data, err := v.Data.Encode()
+			if err != nil {
+				return nil, err
+			}
+			version := *v
+			version.Data, err = simplejson.NewJson(data)
+			return &version, err
+		}
+	}
+	return nil, dashver.ErrDashboardVersionNotFound
+}
+
+func (f *historyStoreFake) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error) {
+	if query.Start >= len(f.versions) {
+		return nil, dashver.ErrNoVersionsForDashboardID
+	}
+	return f.versions[query.Start:min(query.Start+query.Limit, len(f.versions))], nil
+}
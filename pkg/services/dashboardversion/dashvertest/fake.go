@@ -3,6 +3,7 @@ package dashvertest
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 )
 
@@ -11,6 +12,9 @@ type FakeDashboardVersionService struct {
 	ExpectedDashboardVersions    []*dashver.DashboardVersionDTO
 	ExpectedListDashboarVersions []*dashver.DashboardVersionDTO
 	ExpectedContinueToken        string
+	ExpectedDiff                 *dashver.DashboardVersionDiff
+	ExpectedBlame                *dashver.DashboardVersionBlame
+	ExpectedDashboard            *dashboards.Dashboard
 	counter                      int
 	ExpectedError                error
 }
@@ -37,3 +41,15 @@ func (f *FakeDashboardVersionService) List(ctx context.Context, query *dashver.L
 		Versions:      f.ExpectedListDashboarVersions,
 	}, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionService) Diff(ctx context.Context, query *dashver.DiffDashboardVersionsQuery) (*dashver.DashboardVersionDiff, error) {
+	return f.ExpectedDiff, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) Blame(ctx context.Context, query *dashver.BlameDashboardVersionsQuery) (*dashver.DashboardVersionBlame, error) {
+	return f.ExpectedBlame, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) Restore(ctx context.Context, cmd *dashver.RestoreDashboardVersionCommand) (*dashboards.Dashboard, error) {
+	return f.ExpectedDashboard, f.ExpectedError
+}
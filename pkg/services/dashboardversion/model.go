@@ -4,6 +4,8 @@ import (
 	"errors"
 	"time"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -55,6 +57,38 @@ type GetDashboardVersionQuery struct {
 	Version      int64
 }
 
+// DiffDashboardVersionsQuery is used to compare two versions of a dashboard.
+// Only one of DashboardID and DashboardUID are required.
+type DiffDashboardVersionsQuery struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+	BaseVersion  int64
+	NewVersion   int64
+}
+
+// BlameDashboardVersionsQuery is used to find the versions which last changed
+// the panels of a dashboard. Only one of DashboardID and DashboardUID are required.
+type BlameDashboardVersionsQuery struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+}
+
+// RestoreDashboardVersionCommand is used to save a previous version of a
+// dashboard as a new version. Only one of DashboardID and DashboardUID are required.
+type RestoreDashboardVersionCommand struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+	Version      int64
+	User         identity.Requester
+	// Message of the new version, defaults to "Restored from version <version>"
+	Message string
+	// AllowUIUpdate allows restoring a provisioned dashboard, see dashboards.DashboardService.SaveDashboard
+	AllowUIUpdate bool
+}
+
 type DeleteExpiredVersionsCommand struct {
 	DeletedRows int64
 }
@@ -106,3 +140,50 @@ type DashboardVersionResponseMeta struct {
 	ContinueToken string                 `json:"continueToken"`
 	Versions      []DashboardVersionMeta `json:"versions"`
 }
+
+// JSONPatchOperation is a single RFC 6902 operation.
+type JSONPatchOperation = dashdiffs.JSONPatchOperation
+
+// DashboardVersionDiff holds the JSON patch transforming the base version of
+// a dashboard into the new version.
+type DashboardVersionDiff struct {
+	DashboardUID string               `json:"dashboardUid"`
+	BaseVersion  int                  `json:"baseVersion"`
+	NewVersion   int                  `json:"newVersion"`
+	Patch        []JSONPatchOperation `json:"patch"`
+}
+
+// PanelBlame is the version which last changed a panel. Panels which did not
+// change since the oldest version kept are attributed to that version.
+type PanelBlame struct {
+	PanelID   int64     `json:"panelId"`
+	Title     string    `json:"title"`
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	CreatedBy int64     `json:"createdBy"`
+	Message   string    `json:"message"`
+}
+
+type DashboardVersionBlame struct {
+	DashboardUID  string       `json:"dashboardUid"`
+	Version       int          `json:"version"`
+	OldestVersion int          `json:"oldestVersion"`
+	Panels        []PanelBlame `json:"panels"`
+}
+
+// PanelBlameMeta extends the panel blame with the login of the user who created the version
+type PanelBlameMeta struct {
+	PanelID   int64     `json:"panelId"`
+	Title     string    `json:"title"`
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"createdBy"`
+	Message   string    `json:"message"`
+}
+
+type DashboardVersionBlameMeta struct {
+	DashboardUID  string           `json:"dashboardUid"`
+	Version       int              `json:"version"`
+	OldestVersion int              `json:"oldestVersion"`
+	Panels        []PanelBlameMeta `json:"panels"`
+}
@@ -0,0 +1,149 @@
+package folderdefaults
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Validate checks the defaults, and removes the blank and duplicated required tags.
+func (cmd *SetFolderDefaultsCommand) Validate() error {
+	switch cmd.Timezone {
+	case "", "browser", "utc":
+	default:
+		if _, err := time.LoadLocation(cmd.Timezone); err != nil {
+			return errInvalidFolderDefaults(fmt.Sprintf("unknown timezone %q", cmd.Timezone))
+		}
+	}
+
+	if (cmd.TimeFrom == "") != (cmd.TimeTo == "") {
+		return errInvalidFolderDefaults("timeFrom and timeTo must be set together")
+	}
+
+	if cmd.MinRefreshInterval != "" {
+		if _, err := gtime.ParseDuration(cmd.MinRefreshInterval); err != nil {
+			return errInvalidFolderDefaults(fmt.Sprintf("invalid minRefreshInterval %q", cmd.MinRefreshInterval))
+		}
+	}
+
+	tags := make([]string, 0, len(cmd.RequiredTags))
+	for _, tag := range cmd.RequiredTags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	cmd.RequiredTags = tags
+	return nil
+}
+
+// Resolve returns the defaults of the dashboards of a folder from the defaults of the folder and its
+// parents, ordered from the root folder to the folder.
+func Resolve(chain []*FolderDefaults) *ResolvedDefaults {
+	resolved := &ResolvedDefaults{RequiredTags: make([]string, 0), Origins: make(map[string]string)}
+	for _, d := range chain {
+		if d.Timezone != "" {
+			resolved.Timezone = d.Timezone
+			resolved.Origins[FieldTimezone] = d.FolderUID
+		}
+		if d.TimeFrom != "" {
+			resolved.TimeFrom, resolved.TimeTo = d.TimeFrom, d.TimeTo
+			resolved.Origins[FieldTimeRange] = d.FolderUID
+		}
+		if d.MinRefreshInterval != "" {
+			resolved.MinRefreshInterval = d.MinRefreshInterval
+			resolved.Origins[FieldMinRefreshInterval] = d.FolderUID
+		}
+		if d.DefaultDatasourceUID != "" {
+			resolved.DefaultDatasourceUID = d.DefaultDatasourceUID
+			resolved.Origins[FieldDefaultDatasourceUID] = d.FolderUID
+		}
+		if len(d.RequiredTags) > 0 {
+			resolved.RequiredTags = d.RequiredTags
+			resolved.Origins[FieldRequiredTags] = d.FolderUID
+		}
+	}
+	return resolved
+}
+
+// ApplyOnLoad sets the defaults the dashboard does not set itself: the time zone, the time range and the
+// data source of the panels with queries. The refresh interval is raised to the minimum refresh interval.
+func (d *ResolvedDefaults) ApplyOnLoad(dashboard *simplejson.Json) {
+	if d.Timezone != "" && dashboard.Get("timezone").MustString() == "" {
+		dashboard.Set("timezone", d.Timezone)
+	}
+
+	if d.TimeFrom != "" {
+		if _, ok := dashboard.CheckGet("time"); !ok {
+			dashboard.Set("time", map[string]any{"from": d.TimeFrom, "to": d.TimeTo})
+		}
+	}
+
+	if d.DefaultDatasourceUID != "" {
+		for _, p := range dashboard.Get("panels").MustArray() {
+			panel := simplejson.NewFromAny(p)
+			setPanelDatasource(panel, d.DefaultDatasourceUID)
+			for _, nested := range panel.Get("panels").MustArray() {
+				setPanelDatasource(simplejson.NewFromAny(nested), d.DefaultDatasourceUID)
+			}
+		}
+	}
+
+	d.applyMinRefreshInterval(dashboard)
+}
+
+// ApplyOnSave raises the refresh interval of the dashboard to the minimum refresh interval, and adds the
+// required tags it does not have.
+func (d *ResolvedDefaults) ApplyOnSave(dashboard *simplejson.Json) {
+	d.applyMinRefreshInterval(dashboard)
+
+	if len(d.RequiredTags) > 0 {
+		tags := dashboard.Get("tags").MustStringArray()
+		// the tags are set as []any like when the dashboard is unmarshalled, for them to be read back
+		updated := make([]any, 0, len(tags)+len(d.RequiredTags))
+		for _, tag := range tags {
+			updated = append(updated, tag)
+		}
+		for _, tag := range d.RequiredTags {
+			if !slices.Contains(tags, tag) {
+				updated = append(updated, tag)
+			}
+		}
+		dashboard.Set("tags", updated)
+	}
+}
+
+func (d *ResolvedDefaults) applyMinRefreshInterval(dashboard *simplejson.Json) {
+	if d.MinRefreshInterval == "" {
+		return
+	}
+
+	refresh := dashboard.Get("refresh").MustString()
+	if refresh == "" || refresh == "auto" {
+		return
+	}
+
+	minInterval, err := gtime.ParseDuration(d.MinRefreshInterval)
+	if err != nil {
+		return
+	}
+	if interval, err := gtime.ParseDuration(refresh); err != nil || interval < minInterval {
+		dashboard.Set("refresh", d.MinRefreshInterval)
+	}
+}
+
+// setPanelDatasource sets the data source of the panel when it has queries but no data source, which
+// would otherwise be the default data source of the organization.
+func setPanelDatasource(panel *simplejson.Json, uid string) {
+	if len(panel.Get("targets").MustArray()) == 0 {
+		return
+	}
+	if ds, ok := panel.CheckGet("datasource"); ok && ds.Interface() != nil {
+		return
+	}
+	panel.Set("datasource", map[string]any{"uid": uid})
+}
@@ -0,0 +1,108 @@
+package folderdefaults
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestSetFolderDefaultsCommand_Validate(t *testing.T) {
+	cmd := &SetFolderDefaultsCommand{Timezone: "Europe/Paris", TimeFrom: "now-24h", TimeTo: "now", MinRefreshInterval: "1m", RequiredTags: []string{"ops", " ", "team", "ops "}}
+	require.NoError(t, cmd.Validate())
+	assert.Equal(t, []string{"ops", "team"}, cmd.RequiredTags)
+
+	for _, cmd := range []*SetFolderDefaultsCommand{
+		{Timezone: "Mars/Olympus_Mons"},
+		{TimeFrom: "now-24h"},
+		{MinRefreshInterval: "often"},
+	} {
+		err := cmd.Validate()
+		assert.ErrorIs(t, err, ErrInvalidFolderDefaults)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	resolved := Resolve([]*FolderDefaults{
+		{FolderUID: "root", Timezone: "utc", TimeFrom: "now-7d", TimeTo: "now", MinRefreshInterval: "1m", RequiredTags: []string{"company"}},
+		{FolderUID: "team", TimeFrom: "now-24h", TimeTo: "now", DefaultDatasourceUID: "prom", RequiredTags: []string{"team"}},
+		{FolderUID: "service", MinRefreshInterval: "5m"},
+	})
+	assert.Equal(t, &ResolvedDefaults{
+		Timezone:             "utc",
+		TimeFrom:             "now-24h",
+		TimeTo:               "now",
+		MinRefreshInterval:   "5m",
+		DefaultDatasourceUID: "prom",
+		RequiredTags:         []string{"team"},
+		Origins: map[string]string{
+			FieldTimezone:             "root",
+			FieldTimeRange:            "team",
+			FieldMinRefreshInterval:   "service",
+			FieldDefaultDatasourceUID: "team",
+			FieldRequiredTags:         "team",
+		},
+	}, resolved)
+
+	assert.Equal(t, &ResolvedDefaults{RequiredTags: []string{}, Origins: map[string]string{}}, Resolve(nil))
+}
+
+func TestResolvedDefaults_Apply(t *testing.T) {
+	defaults := &ResolvedDefaults{
+		Timezone:             "utc",
+		TimeFrom:             "now-24h",
+		TimeTo:               "now",
+		MinRefreshInterval:   "1m",
+		DefaultDatasourceUID: "prom",
+		RequiredTags:         []string{"team"},
+	}
+
+	t.Run("on load the defaults are set when the dashboard does not set them", func(t *testing.T) {
+		dashboard := simplejson.MustJson([]byte(`{
+			"refresh": "10s",
+			"panels": [
+				{"id": 1, "type": "timeseries", "targets": [{"refId": "A"}]},
+				{"id": 2, "type": "timeseries", "datasource": {"uid": "loki"}, "targets": [{"refId": "A"}]},
+				{"id": 3, "type": "text"},
+				{"id": 4, "type": "row", "panels": [{"id": 5, "type": "stat", "targets": [{"refId": "A"}]}]}
+			]
+		}`))
+		defaults.ApplyOnLoad(dashboard)
+
+		expected := simplejson.MustJson([]byte(`{
+			"timezone": "utc",
+			"time": {"from": "now-24h", "to": "now"},
+			"refresh": "1m",
+			"panels": [
+				{"id": 1, "type": "timeseries", "datasource": {"uid": "prom"}, "targets": [{"refId": "A"}]},
+				{"id": 2, "type": "timeseries", "datasource": {"uid": "loki"}, "targets": [{"refId": "A"}]},
+				{"id": 3, "type": "text"},
+				{"id": 4, "type": "row", "panels": [{"id": 5, "type": "stat", "datasource": {"uid": "prom"}, "targets": [{"refId": "A"}]}]}
+			]
+		}`))
+		expectedJSON, err := expected.Encode()
+		require.NoError(t, err)
+		actualJSON, err := dashboard.Encode()
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+	})
+
+	t.Run("on load the settings of the dashboard are kept", func(t *testing.T) {
+		dashboard := simplejson.MustJson([]byte(`{"timezone": "browser", "time": {"from": "now-1h", "to": "now"}, "refresh": "5m"}`))
+		defaults.ApplyOnLoad(dashboard)
+		assert.Equal(t, "browser", dashboard.Get("timezone").MustString())
+		assert.Equal(t, "now-1h", dashboard.GetPath("time", "from").MustString())
+		assert.Equal(t, "5m", dashboard.Get("refresh").MustString())
+	})
+
+	t.Run("on save the refresh interval is raised and the required tags are added", func(t *testing.T) {
+		dashboard := simplejson.MustJson([]byte(`{"refresh": "5s", "tags": ["api"]}`))
+		defaults.ApplyOnSave(dashboard)
+		assert.Equal(t, "1m", dashboard.Get("refresh").MustString())
+		assert.Equal(t, []string{"api", "team"}, dashboard.Get("tags").MustStringArray())
+		_, ok := dashboard.CheckGet("timezone")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,85 @@
+package folderdefaults
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrFolderDefaultsNotFound = errutil.NotFound("folderdefaults.not-found", errutil.WithPublicMessage("Folder has no defaults"))
+	ErrInvalidFolderDefaults  = errutil.BadRequest("folderdefaults.invalid").MustTemplate(
+		"invalid folder defaults: {{ .Public.Reason }}",
+		errutil.WithPublic("Invalid folder defaults: {{ .Public.Reason }}"),
+	)
+)
+
+func errInvalidFolderDefaults(reason string) error {
+	return ErrInvalidFolderDefaults.Build(errutil.TemplateData{Public: map[string]any{"Reason": reason}})
+}
+
+// Service manages the defaults of the folders, inherited by the dashboards of the folders and of their
+// subfolders.
+type Service interface {
+	// Get returns the defaults set on the folder itself.
+	Get(ctx context.Context, orgID int64, folderUID string) (*FolderDefaults, error)
+	Set(ctx context.Context, cmd *SetFolderDefaultsCommand) (*FolderDefaults, error)
+	Delete(ctx context.Context, orgID int64, folderUID string) error
+	// Resolve returns the defaults of the dashboards of the folder, inherited from the folder and its parents.
+	Resolve(ctx context.Context, orgID int64, folderUID string) (*ResolvedDefaults, error)
+}
+
+// FolderDefaults are the defaults set on a folder. Empty values are inherited from the parent folders.
+type FolderDefaults struct {
+	ID        int64  `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID     int64  `xorm:"org_id" json:"-"`
+	FolderUID string `xorm:"folder_uid" json:"folderUid"`
+	// Timezone is browser, utc or a location of the IANA time zone database
+	Timezone             string   `xorm:"timezone" json:"timezone"`
+	TimeFrom             string   `xorm:"time_from" json:"timeFrom"`
+	TimeTo               string   `xorm:"time_to" json:"timeTo"`
+	MinRefreshInterval   string   `xorm:"min_refresh_interval" json:"minRefreshInterval"`
+	DefaultDatasourceUID string   `xorm:"default_datasource_uid" json:"defaultDatasourceUid"`
+	RequiredTags         []string `xorm:"required_tags" json:"requiredTags"`
+
+	Created time.Time `xorm:"created" json:"created"`
+	Updated time.Time `xorm:"updated" json:"updated"`
+}
+
+func (FolderDefaults) TableName() string {
+	return "folder_defaults"
+}
+
+// SetFolderDefaultsCommand replaces the defaults of a folder.
+type SetFolderDefaultsCommand struct {
+	OrgID                int64    `json:"-"`
+	FolderUID            string   `json:"-"`
+	Timezone             string   `json:"timezone"`
+	TimeFrom             string   `json:"timeFrom"`
+	TimeTo               string   `json:"timeTo"`
+	MinRefreshInterval   string   `json:"minRefreshInterval"`
+	DefaultDatasourceUID string   `json:"defaultDatasourceUid"`
+	RequiredTags         []string `json:"requiredTags"`
+}
+
+const (
+	FieldTimezone             = "timezone"
+	FieldTimeRange            = "timeRange"
+	FieldMinRefreshInterval   = "minRefreshInterval"
+	FieldDefaultDatasourceUID = "defaultDatasourceUid"
+	FieldRequiredTags         = "requiredTags"
+)
+
+// ResolvedDefaults are the defaults of the dashboards of a folder. Each default is the one of the
+// closest folder setting it, starting with the folder of the dashboards.
+type ResolvedDefaults struct {
+	Timezone             string   `json:"timezone"`
+	TimeFrom             string   `json:"timeFrom"`
+	TimeTo               string   `json:"timeTo"`
+	MinRefreshInterval   string   `json:"minRefreshInterval"`
+	DefaultDatasourceUID string   `json:"defaultDatasourceUid"`
+	RequiredTags         []string `json:"requiredTags"`
+	// Origins are the UIDs of the folders the defaults are inherited from, by field
+	Origins map[string]string `json:"origins"`
+}
@@ -0,0 +1,106 @@
+package folderdefaultsimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folderdefaults"
+)
+
+var timeNow = time.Now
+
+type Service struct {
+	store         store
+	folderService folder.Service
+}
+
+var _ folderdefaults.Service = (*Service)(nil)
+var _ dashboards.FolderDefaultsService = (*Service)(nil)
+
+func ProvideService(db db.DB, folderService folder.Service, dashboardsRegistration dashboards.FolderDefaultsRegistrationService) *Service {
+	s := &Service{
+		store:         &sqlStore{db: db},
+		folderService: folderService,
+	}
+	dashboardsRegistration.RegisterFolderDefaults(s)
+	return s
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64, folderUID string) (*folderdefaults.FolderDefaults, error) {
+	return s.store.get(ctx, orgID, folderUID)
+}
+
+func (s *Service) Set(ctx context.Context, cmd *folderdefaults.SetFolderDefaultsCommand) (*folderdefaults.FolderDefaults, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := timeNow()
+	defaults := &folderdefaults.FolderDefaults{
+		OrgID:                cmd.OrgID,
+		FolderUID:            cmd.FolderUID,
+		Timezone:             cmd.Timezone,
+		TimeFrom:             cmd.TimeFrom,
+		TimeTo:               cmd.TimeTo,
+		MinRefreshInterval:   cmd.MinRefreshInterval,
+		DefaultDatasourceUID: cmd.DefaultDatasourceUID,
+		RequiredTags:         cmd.RequiredTags,
+		Created:              now,
+		Updated:              now,
+	}
+	if err := s.store.upsert(ctx, defaults); err != nil {
+		return nil, err
+	}
+	return defaults, nil
+}
+
+func (s *Service) Delete(ctx context.Context, orgID int64, folderUID string) error {
+	return s.store.delete(ctx, orgID, folderUID)
+}
+
+func (s *Service) Resolve(ctx context.Context, orgID int64, folderUID string) (*folderdefaults.ResolvedDefaults, error) {
+	if folderUID == "" || folderUID == folder.GeneralFolderUID {
+		return folderdefaults.Resolve(nil), nil
+	}
+
+	parents, err := s.folderService.GetParents(ctx, folder.GetParentsQuery{UID: folderUID, OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	uids := make([]string, 0, len(parents)+1)
+	for _, p := range parents {
+		uids = append(uids, p.UID)
+	}
+	uids = append(uids, folderUID)
+
+	defaults, err := s.store.getForFolders(ctx, orgID, uids)
+	if err != nil {
+		return nil, err
+	}
+	byFolder := make(map[string]*folderdefaults.FolderDefaults, len(defaults))
+	for _, d := range defaults {
+		byFolder[d.FolderUID] = d
+	}
+
+	chain := make([]*folderdefaults.FolderDefaults, 0, len(defaults))
+	for _, uid := range uids {
+		if d, ok := byFolder[uid]; ok {
+			chain = append(chain, d)
+		}
+	}
+	return folderdefaults.Resolve(chain), nil
+}
+
+// ApplyFolderDefaults applies the defaults of the folder to a dashboard saved in it.
+func (s *Service) ApplyFolderDefaults(ctx context.Context, orgID int64, folderUID string, dashboard *simplejson.Json) error {
+	resolved, err := s.Resolve(ctx, orgID, folderUID)
+	if err != nil {
+		return err
+	}
+	resolved.ApplyOnSave(dashboard)
+	return nil
+}
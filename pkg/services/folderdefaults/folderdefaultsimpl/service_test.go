@@ -0,0 +1,100 @@
+package folderdefaultsimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/folderdefaults"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeDashboardsRegistration struct {
+	registered bool
+}
+
+func (f *fakeDashboardsRegistration) RegisterFolderDefaults(_ dashboards.FolderDefaultsService) {
+	f.registered = true
+}
+
+func TestIntegrationFolderDefaults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	folderSvc := foldertest.NewFakeService()
+	registration := &fakeDashboardsRegistration{}
+	svc := ProvideService(db.InitTestDB(t), folderSvc, registration)
+	require.True(t, registration.registered)
+
+	t.Run("defaults are set, replaced and deleted", func(t *testing.T) {
+		_, err := svc.Get(ctx, 1, "team")
+		require.ErrorIs(t, err, folderdefaults.ErrFolderDefaultsNotFound)
+
+		created, err := svc.Set(ctx, &folderdefaults.SetFolderDefaultsCommand{OrgID: 1, FolderUID: "team", Timezone: "utc", RequiredTags: []string{"team", ""}})
+		require.NoError(t, err)
+
+		defaults, err := svc.Get(ctx, 1, "team")
+		require.NoError(t, err)
+		assert.Equal(t, "utc", defaults.Timezone)
+		assert.Equal(t, []string{"team"}, defaults.RequiredTags)
+
+		_, err = svc.Set(ctx, &folderdefaults.SetFolderDefaultsCommand{OrgID: 1, FolderUID: "team", MinRefreshInterval: "1m"})
+		require.NoError(t, err)
+		defaults, err = svc.Get(ctx, 1, "team")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, defaults.ID)
+		assert.Equal(t, "", defaults.Timezone)
+		assert.Equal(t, "1m", defaults.MinRefreshInterval)
+
+		_, err = svc.Set(ctx, &folderdefaults.SetFolderDefaultsCommand{OrgID: 1, FolderUID: "team", Timezone: "Nowhere/Somewhere"})
+		require.ErrorIs(t, err, folderdefaults.ErrInvalidFolderDefaults)
+
+		require.NoError(t, svc.Delete(ctx, 1, "team"))
+		require.ErrorIs(t, svc.Delete(ctx, 1, "team"), folderdefaults.ErrFolderDefaultsNotFound)
+	})
+
+	t.Run("dashboards inherit the defaults of the folder and its parents", func(t *testing.T) {
+		_, err := svc.Set(ctx, &folderdefaults.SetFolderDefaultsCommand{OrgID: 1, FolderUID: "company", Timezone: "utc", MinRefreshInterval: "30s", RequiredTags: []string{"company"}})
+		require.NoError(t, err)
+		_, err = svc.Set(ctx, &folderdefaults.SetFolderDefaultsCommand{OrgID: 1, FolderUID: "ops", MinRefreshInterval: "1m", RequiredTags: []string{"ops"}})
+		require.NoError(t, err)
+		_, err = svc.Set(ctx, &folderdefaults.SetFolderDefaultsCommand{OrgID: 2, FolderUID: "api", Timezone: "browser"})
+		require.NoError(t, err)
+		folderSvc.ExpectedFolders = []*folder.Folder{{UID: "company"}, {UID: "ops"}}
+
+		resolved, err := svc.Resolve(ctx, 1, "api")
+		require.NoError(t, err)
+		assert.Equal(t, &folderdefaults.ResolvedDefaults{
+			Timezone:           "utc",
+			MinRefreshInterval: "1m",
+			RequiredTags:       []string{"ops"},
+			Origins: map[string]string{
+				folderdefaults.FieldTimezone:           "company",
+				folderdefaults.FieldMinRefreshInterval: "ops",
+				folderdefaults.FieldRequiredTags:       "ops",
+			},
+		}, resolved)
+
+		dashboard := simplejson.MustJson([]byte(`{"refresh": "10s"}`))
+		require.NoError(t, svc.ApplyFolderDefaults(ctx, 1, "api", dashboard))
+		assert.Equal(t, "1m", dashboard.Get("refresh").MustString())
+		assert.Equal(t, []string{"ops"}, dashboard.Get("tags").MustStringArray())
+
+		resolved, err = svc.Resolve(ctx, 1, folder.GeneralFolderUID)
+		require.NoError(t, err)
+		assert.Empty(t, resolved.Origins)
+	})
+}
@@ -0,0 +1,82 @@
+package folderdefaultsimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/folderdefaults"
+)
+
+type store interface {
+	get(ctx context.Context, orgID int64, folderUID string) (*folderdefaults.FolderDefaults, error)
+	getForFolders(ctx context.Context, orgID int64, folderUIDs []string) ([]*folderdefaults.FolderDefaults, error)
+	upsert(ctx context.Context, defaults *folderdefaults.FolderDefaults) error
+	delete(ctx context.Context, orgID int64, folderUID string) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) get(ctx context.Context, orgID int64, folderUID string) (*folderdefaults.FolderDefaults, error) {
+	var defaults folderdefaults.FolderDefaults
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND folder_uid = ?", orgID, folderUID).Get(&defaults)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return folderdefaults.ErrFolderDefaultsNotFound.Errorf("no defaults for folder %s", folderUID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &defaults, nil
+}
+
+func (s *sqlStore) getForFolders(ctx context.Context, orgID int64, folderUIDs []string) ([]*folderdefaults.FolderDefaults, error) {
+	defaults := make([]*folderdefaults.FolderDefaults, 0)
+	if len(folderUIDs) == 0 {
+		return defaults, nil
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).In("folder_uid", folderUIDs).Find(&defaults)
+	})
+	return defaults, err
+}
+
+func (s *sqlStore) upsert(ctx context.Context, defaults *folderdefaults.FolderDefaults) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			var existing folderdefaults.FolderDefaults
+			exists, err := sess.Where("org_id = ? AND folder_uid = ?", defaults.OrgID, defaults.FolderUID).Get(&existing)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				_, err := sess.Insert(defaults)
+				return err
+			}
+
+			defaults.ID = existing.ID
+			defaults.Created = existing.Created
+			_, err = sess.ID(existing.ID).AllCols().Update(defaults)
+			return err
+		})
+	})
+}
+
+func (s *sqlStore) delete(ctx context.Context, orgID int64, folderUID string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND folder_uid = ?", orgID, folderUID).Delete(&folderdefaults.FolderDefaults{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return folderdefaults.ErrFolderDefaultsNotFound.Errorf("no defaults for folder %s", folderUID)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,47 @@
+package folderdefaultstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/folderdefaults"
+)
+
+type FakeService struct {
+	ExpectedDefaults *folderdefaults.FolderDefaults
+	ExpectedResolved *folderdefaults.ResolvedDefaults
+	ExpectedError    error
+
+	LastSetCommand *folderdefaults.SetFolderDefaultsCommand
+}
+
+var _ folderdefaults.Service = (*FakeService)(nil)
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (s *FakeService) Get(ctx context.Context, orgID int64, folderUID string) (*folderdefaults.FolderDefaults, error) {
+	return s.ExpectedDefaults, s.ExpectedError
+}
+
+func (s *FakeService) Set(ctx context.Context, cmd *folderdefaults.SetFolderDefaultsCommand) (*folderdefaults.FolderDefaults, error) {
+	s.LastSetCommand = cmd
+	if s.ExpectedError != nil {
+		return nil, s.ExpectedError
+	}
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	return s.ExpectedDefaults, nil
+}
+
+func (s *FakeService) Delete(ctx context.Context, orgID int64, folderUID string) error {
+	return s.ExpectedError
+}
+
+func (s *FakeService) Resolve(ctx context.Context, orgID int64, folderUID string) (*folderdefaults.ResolvedDefaults, error) {
+	if s.ExpectedResolved == nil && s.ExpectedError == nil {
+		return folderdefaults.Resolve(nil), nil
+	}
+	return s.ExpectedResolved, s.ExpectedError
+}
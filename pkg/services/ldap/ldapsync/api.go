@@ -0,0 +1,87 @@
+package ldapsync
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin", func(adminRoute routing.RouteRegister) {
+		adminRoute.Get("/ldap-sync-status", authorize(ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(s.getSyncStatus))
+		adminRoute.Post("/ldap-sync", authorize(ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(s.postSync))
+	}, middleware.ReqSignedIn)
+}
+
+// SyncStatusDTO is returned by the LDAP sync status endpoint.
+type SyncStatusDTO struct {
+	Enabled    bool        `json:"enabled"`
+	Schedule   string      `json:"schedule"`
+	NextSync   *time.Time  `json:"nextSync,omitempty"`
+	LastReport *SyncReport `json:"lastReport,omitempty"`
+}
+
+// swagger:route GET /admin/ldap-sync-status admin_ldap getLDAPSyncStatus
+//
+// Returns the schedule and the report of the last LDAP background synchronization.
+//
+// You need to have a permission with action `ldap.status:read`.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getSyncStatus(c *contextmodel.ReqContext) response.Response {
+	report, err := s.LastReport(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the LDAP synchronization report", err)
+	}
+
+	return response.JSON(http.StatusOK, SyncStatusDTO{
+		Enabled:    !s.IsDisabled(),
+		Schedule:   s.cfg.LDAPBackgroundSyncCron,
+		NextSync:   s.NextRun(),
+		LastReport: report,
+	})
+}
+
+// swagger:route POST /admin/ldap-sync admin_ldap postLDAPSync
+//
+// Synchronizes all users that signed in through LDAP with the LDAP servers.
+//
+// You need to have a permission with action `ldap.user:sync`.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) postSync(c *contextmodel.ReqContext) response.Response {
+	if !s.ldapCfg.Enabled {
+		return response.Error(http.StatusBadRequest, "LDAP is not enabled", nil)
+	}
+
+	report, err := s.SyncNow(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, ErrSyncInProgress) {
+			return response.Error(http.StatusConflict, err.Error(), nil)
+		}
+		if errors.Is(err, ErrServerDown) {
+			return response.Error(http.StatusBadGateway, "LDAP synchronization aborted", err)
+		}
+		return response.Error(http.StatusInternalServerError, "LDAP synchronization failed", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,449 @@
+package ldapsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/ldap/ldapsync")
+
+const (
+	// TriggerSchedule is used for reports of syncs started by the background_sync_cron schedule.
+	TriggerSchedule = "schedule"
+	// TriggerManual is used for reports of syncs started through the admin API.
+	TriggerManual = "manual"
+
+	scheduledLockName = "ldap active sync"
+	manualLockName    = "ldap manual sync"
+	// manualLockTimeout is the time after which a lock held by a manual sync is considered stale.
+	manualLockTimeout = time.Hour
+
+	reportKey       = "last-report"
+	disabledKey     = "disabled-users"
+	defaultPageSize = 500
+)
+
+var (
+	ErrSyncInProgress = errors.New("an LDAP synchronization is already in progress")
+	ErrServerDown     = errors.New("LDAP server is not available")
+)
+
+// SyncReport describes the outcome of a synchronization of all LDAP users.
+type SyncReport struct {
+	Trigger    string        `json:"trigger"`
+	StartedAt  time.Time     `json:"startedAt"`
+	FinishedAt time.Time     `json:"finishedAt"`
+	Checked    int           `json:"checked"`
+	Synced     int           `json:"synced"`
+	Disabled   []ReportEntry `json:"disabled"`
+	Skipped    []ReportEntry `json:"skipped"`
+	Failed     []ReportEntry `json:"failed"`
+	// Error is set when the synchronization was aborted.
+	Error string `json:"error,omitempty"`
+}
+
+// ReportEntry is a single user listed in a SyncReport.
+type ReportEntry struct {
+	UserID  int64  `json:"userId"`
+	Login   string `json:"login"`
+	Message string `json:"message,omitempty"`
+}
+
+// Service periodically synchronizes every user that signed in through LDAP with the
+// configured LDAP servers. Org roles and teams are updated and, when
+// background_sync_disable_missing is set, users that are no longer found in LDAP are
+// disabled and logged out. Users disabled by the sync are enabled again once they are
+// found in LDAP, users disabled by an admin are left alone.
+type Service struct {
+	cfg                  *setting.Cfg
+	ldapCfg              *ldap.Config
+	log                  log.Logger
+	ldapService          service.LDAP
+	userService          user.Service
+	sessionService       auth.UserTokenService
+	identitySynchronizer authn.IdentitySynchronizer
+	serverLock           *serverlock.ServerLockService
+	kv                   *kvstore.NamespacedKVStore
+
+	schedule cron.Schedule
+	pageSize int
+
+	// mu serializes synchronizations started on this instance.
+	mu sync.Mutex
+}
+
+func ProvideService(
+	cfg *setting.Cfg, ldapService service.LDAP, userService user.Service,
+	sessionService auth.UserTokenService, identitySynchronizer authn.IdentitySynchronizer,
+	serverLock *serverlock.ServerLockService, kv kvstore.KVStore,
+	routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+) *Service {
+	s := &Service{
+		cfg:                  cfg,
+		ldapCfg:              ldap.GetLDAPConfig(cfg),
+		log:                  log.New("ldap.sync"),
+		ldapService:          ldapService,
+		userService:          userService,
+		sessionService:       sessionService,
+		identitySynchronizer: identitySynchronizer,
+		serverLock:           serverLock,
+		kv:                   kvstore.WithNamespace(kv, 0, "ldap.sync"),
+		pageSize:             defaultPageSize,
+	}
+
+	if cfg.LDAPAuthEnabled && cfg.LDAPBackgroundSyncCron != "" {
+		schedule, err := cron.ParseStandard(cfg.LDAPBackgroundSyncCron)
+		if err != nil {
+			s.log.Error("Invalid background_sync_cron, LDAP background synchronization is disabled", "background_sync_cron", cfg.LDAPBackgroundSyncCron, "error", err)
+		} else {
+			s.schedule = schedule
+		}
+	}
+
+	s.registerAPIEndpoints(routeRegister, ac)
+
+	return s
+}
+
+// IsDisabled returns true when LDAP is disabled or no valid sync schedule is configured.
+func (s *Service) IsDisabled() bool {
+	return s.schedule == nil
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	for {
+		next := s.schedule.Next(time.Now())
+		// the lock stays valid for half the time between two runs, so that only one
+		// instance performs each scheduled sync
+		interval := s.schedule.Next(next).Sub(next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			err := s.serverLock.LockAndExecute(ctx, scheduledLockName, interval/2, func(ctx context.Context) {
+				report, err := s.Sync(ctx, TriggerSchedule)
+				if err != nil {
+					s.log.Error("LDAP synchronization failed", "error", err)
+					return
+				}
+				s.log.Info("LDAP synchronization finished", "checked", report.Checked, "synced", report.Synced,
+					"disabled", len(report.Disabled), "failed", len(report.Failed))
+			})
+			if err != nil {
+				s.log.Error("Failed to acquire lock for LDAP synchronization", "error", err)
+			}
+		}
+	}
+}
+
+// NextRun returns when the next scheduled synchronization will happen.
+func (s *Service) NextRun() *time.Time {
+	if s.schedule == nil {
+		return nil
+	}
+	next := s.schedule.Next(time.Now())
+	return &next
+}
+
+// SyncNow runs a synchronization that is not part of the schedule. It returns
+// ErrSyncInProgress when another instance is already running a manual sync.
+func (s *Service) SyncNow(ctx context.Context) (*SyncReport, error) {
+	var (
+		report *SyncReport
+		err    error
+	)
+
+	lockErr := s.serverLock.LockExecuteAndRelease(ctx, manualLockName, manualLockTimeout, func(ctx context.Context) {
+		report, err = s.Sync(ctx, TriggerManual)
+	})
+	if lockErr != nil {
+		var existsErr *serverlock.ServerLockExistsError
+		if errors.As(lockErr, &existsErr) {
+			return nil, ErrSyncInProgress
+		}
+		return nil, lockErr
+	}
+
+	return report, err
+}
+
+// LastReport returns the report of the most recent synchronization, or nil if none has run yet.
+func (s *Service) LastReport(ctx context.Context) (*SyncReport, error) {
+	value, ok, err := s.kv.Get(ctx, reportKey)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var report SyncReport
+	if err := json.Unmarshal([]byte(value), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Sync walks every user that authenticated through LDAP and synchronizes it with the LDAP servers.
+// Callers are responsible for coordinating with other instances. The report is stored even when
+// the synchronization is aborted.
+func (s *Service) Sync(ctx context.Context, trigger string) (*SyncReport, error) {
+	ctx, span := tracer.Start(ctx, "ldapsync.Sync")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &SyncReport{
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+		Disabled:  []ReportEntry{},
+		Skipped:   []ReportEntry{},
+		Failed:    []ReportEntry{},
+	}
+
+	disabled, err := s.disabledUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sync(ctx, report, disabled)
+	if err != nil {
+		report.Error = err.Error()
+	}
+	report.FinishedAt = time.Now()
+
+	if saveErr := s.saveDisabledUsers(ctx, disabled); saveErr != nil {
+		s.log.Error("Failed to store the users disabled by the LDAP synchronization", "error", saveErr)
+	}
+
+	span.SetAttributes(
+		attribute.Int("checked", report.Checked),
+		attribute.Int("synced", report.Synced),
+		attribute.Int("disabled", len(report.Disabled)),
+	)
+
+	if saveErr := s.saveReport(ctx, report); saveErr != nil {
+		s.log.Error("Failed to store LDAP synchronization report", "error", saveErr)
+	}
+
+	return report, err
+}
+
+// sync synchronizes the users page by page. disabled holds the ids of the users disabled
+// by earlier synchronizations and is updated as users are disabled and enabled again.
+func (s *Service) sync(ctx context.Context, report *SyncReport, disabled map[int64]bool) error {
+	client := s.ldapService.Client()
+	if client == nil {
+		return service.ErrLDAPNotEnabled
+	}
+
+	// A server that cannot be reached is skipped by the LDAP client, which would make all of
+	// its users look like they were removed. Refuse to sync rather than disable them.
+	statuses, err := client.Ping()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if !status.Available {
+			return fmt.Errorf("%w: %s:%d", ErrServerDown, status.Host, status.Port)
+		}
+	}
+
+	requester := &user.SignedInUser{
+		Login:            "sa-ldapsync",
+		OrgRole:          "Admin",
+		IsGrafanaAdmin:   true,
+		IsServiceAccount: true,
+		Permissions:      map[int64]map[string][]string{accesscontrol.GlobalOrgID: {accesscontrol.ActionUsersRead: {accesscontrol.ScopeGlobalUsersAll}}},
+	}
+
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		res, err := s.userService.Search(ctx, &user.SearchUsersQuery{
+			SignedInUser: requester,
+			AuthModule:   login.LDAPAuthModule,
+			Page:         page,
+			Limit:        s.pageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.syncPage(ctx, res.Users, report, disabled); err != nil {
+			return err
+		}
+
+		if len(res.Users) < s.pageSize {
+			return nil
+		}
+	}
+}
+
+func (s *Service) syncPage(ctx context.Context, users []*user.UserSearchHitDTO, report *SyncReport, disabled map[int64]bool) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	logins := make([]string, 0, len(users))
+	for _, u := range users {
+		logins = append(logins, u.Login)
+	}
+
+	found, err := s.ldapService.Client().Users(logins)
+	if err != nil {
+		return err
+	}
+
+	byLogin := make(map[string]*login.ExternalUserInfo, len(found))
+	for _, info := range found {
+		byLogin[strings.ToLower(info.Login)] = info
+	}
+
+	for _, u := range users {
+		report.Checked++
+
+		info, ok := byLogin[strings.ToLower(u.Login)]
+		if !ok {
+			s.disableUser(ctx, u, report, disabled)
+			continue
+		}
+
+		if err := s.identitySynchronizer.SyncIdentity(ctx, s.identityFromLDAPUser(info, disabled[u.ID])); err != nil {
+			s.log.Warn("Failed to sync LDAP user", "login", u.Login, "error", err)
+			report.Failed = append(report.Failed, ReportEntry{UserID: u.ID, Login: u.Login, Message: err.Error()})
+			continue
+		}
+		delete(disabled, u.ID)
+		report.Synced++
+	}
+
+	return nil
+}
+
+func (s *Service) disableUser(ctx context.Context, u *user.UserSearchHitDTO, report *SyncReport, disabled map[int64]bool) {
+	entry := ReportEntry{UserID: u.ID, Login: u.Login}
+
+	if u.Login == s.cfg.AdminUser {
+		entry.Message = "user is the Grafana server admin and cannot be disabled"
+		report.Skipped = append(report.Skipped, entry)
+		return
+	}
+
+	if u.IsDisabled {
+		entry.Message = "user is already disabled"
+		report.Skipped = append(report.Skipped, entry)
+		return
+	}
+
+	if !s.cfg.LDAPBackgroundSyncDisableMissing {
+		entry.Message = "user not found in LDAP, background_sync_disable_missing is off"
+		report.Skipped = append(report.Skipped, entry)
+		return
+	}
+
+	isDisabled := true
+	if err := s.userService.Update(ctx, &user.UpdateUserCommand{UserID: u.ID, IsDisabled: &isDisabled}); err != nil {
+		entry.Message = fmt.Sprintf("failed to disable user: %s", err)
+		report.Failed = append(report.Failed, entry)
+		return
+	}
+
+	if err := s.sessionService.RevokeAllUserTokens(ctx, u.ID); err != nil {
+		s.log.Warn("Failed to revoke sessions of disabled LDAP user", "login", u.Login, "error", err)
+	}
+
+	disabled[u.ID] = true
+	s.log.Info("Disabled user no longer found in LDAP", "login", u.Login, "userId", u.ID)
+	entry.Message = "user not found in LDAP"
+	report.Disabled = append(report.Disabled, entry)
+}
+
+// identityFromLDAPUser returns the identity to sync for a user found in LDAP. enable is only
+// set for users disabled by the sync, so users disabled by an admin stay disabled.
+func (s *Service) identityFromLDAPUser(info *login.ExternalUserInfo, enable bool) *authn.Identity {
+	return &authn.Identity{
+		OrgRoles:        info.OrgRoles,
+		Login:           info.Login,
+		Name:            info.Name,
+		Email:           info.Email,
+		IsGrafanaAdmin:  info.IsGrafanaAdmin,
+		AuthenticatedBy: info.AuthModule,
+		AuthID:          info.AuthId,
+		Groups:          info.Groups,
+		ClientParams: authn.ClientParams{
+			SyncUser:     true,
+			SyncTeams:    true,
+			EnableUser:   enable,
+			SyncOrgRoles: !s.ldapCfg.SkipOrgRoleSync,
+			// only users that already exist in Grafana are synchronized
+			AllowSignUp: false,
+		},
+	}
+}
+
+// disabledUsers returns the ids of the users disabled by earlier synchronizations.
+func (s *Service) disabledUsers(ctx context.Context) (map[int64]bool, error) {
+	disabled := map[int64]bool{}
+	value, ok, err := s.kv.Get(ctx, disabledKey)
+	if err != nil || !ok {
+		return disabled, err
+	}
+
+	var ids []int64
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		disabled[id] = true
+	}
+	return disabled, nil
+}
+
+func (s *Service) saveDisabledUsers(ctx context.Context, disabled map[int64]bool) error {
+	ids := make([]int64, 0, len(disabled))
+	for id := range disabled {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, disabledKey, string(data))
+}
+
+func (s *Service) saveReport(ctx context.Context, report *SyncReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, reportKey, string(data))
+}
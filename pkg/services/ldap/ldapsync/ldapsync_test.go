@@ -0,0 +1,246 @@
+package ldapsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/multildap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeLDAPClient struct {
+	statuses []*multildap.ServerStatus
+	users    []*login.ExternalUserInfo
+}
+
+func (f *fakeLDAPClient) Ping() ([]*multildap.ServerStatus, error) {
+	return f.statuses, nil
+}
+
+func (f *fakeLDAPClient) Login(query *login.LoginUserQuery) (*login.ExternalUserInfo, error) {
+	return nil, multildap.ErrInvalidCredentials
+}
+
+func (f *fakeLDAPClient) Users(logins []string) ([]*login.ExternalUserInfo, error) {
+	return f.users, nil
+}
+
+func (f *fakeLDAPClient) User(login string) (*login.ExternalUserInfo, ldap.ServerConfig, error) {
+	return nil, ldap.ServerConfig{}, multildap.ErrDidNotFindUser
+}
+
+type testEnv struct {
+	service  *Service
+	synced   []*authn.Identity
+	disabled []int64
+	revoked  []int64
+}
+
+func setupTestEnv(t *testing.T, client *fakeLDAPClient, users ...*user.UserSearchHitDTO) *testEnv {
+	t.Helper()
+
+	env := &testEnv{}
+
+	cfg := setting.NewCfg()
+	cfg.AdminUser = "admin"
+	cfg.LDAPAuthEnabled = true
+	cfg.LDAPBackgroundSyncDisableMissing = true
+
+	ldapService := service.NewLDAPFakeService()
+	ldapService.ExpectedClient = client
+
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedSearchUsers = user.SearchUserQueryResult{Users: users, TotalCount: int64(len(users))}
+	userService.UpdateFn = func(ctx context.Context, cmd *user.UpdateUserCommand) error {
+		if cmd.IsDisabled != nil && *cmd.IsDisabled {
+			env.disabled = append(env.disabled, cmd.UserID)
+		}
+		return nil
+	}
+
+	sessionService := authtest.NewFakeUserAuthTokenService()
+	sessionService.RevokeAllUserTokensProvider = func(ctx context.Context, userID int64) error {
+		env.revoked = append(env.revoked, userID)
+		return nil
+	}
+
+	synchronizer := &authntest.MockService{
+		SyncIdentityFunc: func(ctx context.Context, identity *authn.Identity) error {
+			env.synced = append(env.synced, identity)
+			return nil
+		},
+	}
+
+	env.service = ProvideService(cfg, ldapService, userService, sessionService, synchronizer,
+		nil, kvstore.NewFakeKVStore(), routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	return env
+}
+
+func TestService_Sync(t *testing.T) {
+	client := &fakeLDAPClient{
+		statuses: []*multildap.ServerStatus{{Host: "ldap.example.org", Port: 389, Available: true}},
+		users: []*login.ExternalUserInfo{{
+			Login:      "Alice",
+			Email:      "alice@example.org",
+			AuthModule: login.LDAPAuthModule,
+			AuthId:     "cn=alice,dc=example,dc=org",
+			OrgRoles:   map[int64]org.RoleType{1: org.RoleEditor},
+			Groups:     []string{"cn=editors,dc=example,dc=org"},
+		}},
+	}
+
+	env := setupTestEnv(t, client,
+		&user.UserSearchHitDTO{ID: 1, Login: "admin"},
+		&user.UserSearchHitDTO{ID: 2, Login: "alice"},
+		&user.UserSearchHitDTO{ID: 3, Login: "bob"},
+		&user.UserSearchHitDTO{ID: 4, Login: "carol", IsDisabled: true},
+	)
+
+	report, err := env.service.Sync(context.Background(), TriggerManual)
+	require.NoError(t, err)
+
+	assert.Equal(t, TriggerManual, report.Trigger)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, 1, report.Synced)
+	assert.Empty(t, report.Failed)
+	assert.Equal(t, []ReportEntry{{UserID: 3, Login: "bob", Message: "user not found in LDAP"}}, report.Disabled)
+	require.Len(t, report.Skipped, 2)
+	assert.Equal(t, "admin", report.Skipped[0].Login)
+	assert.Equal(t, "carol", report.Skipped[1].Login)
+
+	assert.Equal(t, []int64{3}, env.disabled)
+	assert.Equal(t, []int64{3}, env.revoked)
+
+	require.Len(t, env.synced, 1)
+	assert.Equal(t, "Alice", env.synced[0].Login)
+	assert.Equal(t, map[int64]org.RoleType{1: org.RoleEditor}, env.synced[0].OrgRoles)
+	assert.True(t, env.synced[0].ClientParams.SyncOrgRoles)
+	assert.True(t, env.synced[0].ClientParams.SyncTeams)
+	assert.False(t, env.synced[0].ClientParams.AllowSignUp)
+	assert.False(t, env.synced[0].ClientParams.EnableUser)
+
+	stored, err := env.service.LastReport(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, report.Checked, stored.Checked)
+	assert.Equal(t, report.Disabled, stored.Disabled)
+}
+
+func TestService_Sync_DisableMissingOff(t *testing.T) {
+	client := &fakeLDAPClient{statuses: []*multildap.ServerStatus{{Host: "ldap.example.org", Port: 389, Available: true}}}
+
+	env := setupTestEnv(t, client, &user.UserSearchHitDTO{ID: 3, Login: "bob"})
+	env.service.cfg.LDAPBackgroundSyncDisableMissing = false
+
+	report, err := env.service.Sync(context.Background(), TriggerSchedule)
+	require.NoError(t, err)
+
+	assert.Empty(t, report.Disabled)
+	require.Len(t, report.Skipped, 1)
+	assert.Equal(t, "bob", report.Skipped[0].Login)
+	assert.Empty(t, env.disabled)
+	assert.Empty(t, env.revoked)
+}
+
+func TestService_Sync_EnablesUsersDisabledBySync(t *testing.T) {
+	client := &fakeLDAPClient{statuses: []*multildap.ServerStatus{{Host: "ldap.example.org", Port: 389, Available: true}}}
+
+	env := setupTestEnv(t, client, &user.UserSearchHitDTO{ID: 2, Login: "alice"})
+	_, err := env.service.Sync(context.Background(), TriggerSchedule)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2}, env.disabled)
+
+	// alice is back in LDAP, bob was disabled by an admin
+	client.users = []*login.ExternalUserInfo{
+		{Login: "alice", AuthModule: login.LDAPAuthModule},
+		{Login: "bob", AuthModule: login.LDAPAuthModule},
+	}
+	env.service.userService.(*usertest.FakeUserService).ExpectedSearchUsers = user.SearchUserQueryResult{Users: []*user.UserSearchHitDTO{
+		{ID: 2, Login: "alice", IsDisabled: true},
+		{ID: 3, Login: "bob", IsDisabled: true},
+	}}
+
+	_, err = env.service.Sync(context.Background(), TriggerSchedule)
+	require.NoError(t, err)
+	require.Len(t, env.synced, 2)
+	assert.True(t, env.synced[0].ClientParams.EnableUser, "alice was disabled by the sync")
+	assert.False(t, env.synced[1].ClientParams.EnableUser, "bob was disabled by an admin")
+
+	// alice is no longer tracked once enabled again
+	env.synced = nil
+	_, err = env.service.Sync(context.Background(), TriggerSchedule)
+	require.NoError(t, err)
+	require.Len(t, env.synced, 2)
+	assert.False(t, env.synced[0].ClientParams.EnableUser)
+}
+
+func TestService_Sync_ServerUnavailable(t *testing.T) {
+	client := &fakeLDAPClient{
+		statuses: []*multildap.ServerStatus{
+			{Host: "ldap1.example.org", Port: 389, Available: true},
+			{Host: "ldap2.example.org", Port: 389, Available: false},
+		},
+	}
+
+	env := setupTestEnv(t, client, &user.UserSearchHitDTO{ID: 2, Login: "alice"})
+
+	report, err := env.service.Sync(context.Background(), TriggerSchedule)
+	require.ErrorIs(t, err, ErrServerDown)
+
+	assert.Zero(t, report.Checked)
+	assert.Empty(t, env.disabled)
+	assert.Contains(t, report.Error, "ldap2.example.org")
+
+	stored, err := env.service.LastReport(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, report.Error, stored.Error)
+}
+
+func TestService_Schedule(t *testing.T) {
+	newService := func(t *testing.T, iniContent string) *Service {
+		cfg, err := setting.NewCfgFromBytes([]byte(iniContent))
+		require.NoError(t, err)
+		return ProvideService(cfg, service.NewLDAPFakeService(), usertest.NewUserServiceFake(),
+			authtest.NewFakeUserAuthTokenService(), &authntest.MockService{}, nil,
+			kvstore.NewFakeKVStore(), routing.NewRouteRegister(), actest.FakeAccessControl{})
+	}
+
+	t.Run("should be enabled with a valid schedule", func(t *testing.T) {
+		s := newService(t, "[auth.ldap]\nenabled = true\nbackground_sync_cron = \"*/10 * * * *\"\n")
+		assert.False(t, s.IsDisabled())
+		assert.NotNil(t, s.NextRun())
+	})
+
+	t.Run("should be disabled without a schedule", func(t *testing.T) {
+		s := newService(t, "[auth.ldap]\nenabled = true\n")
+		assert.True(t, s.IsDisabled())
+		assert.Nil(t, s.NextRun())
+	})
+
+	t.Run("should ignore the Enterprise active sync settings", func(t *testing.T) {
+		s := newService(t, "[auth.ldap]\nenabled = true\nsync_cron = \"*/10 * * * *\"\nactive_sync_enabled = true\n")
+		assert.True(t, s.IsDisabled())
+	})
+
+	t.Run("should be disabled with an invalid schedule", func(t *testing.T) {
+		s := newService(t, "[auth.ldap]\nenabled = true\nbackground_sync_cron = \"every day\"\n")
+		assert.True(t, s.IsDisabled())
+	})
+}
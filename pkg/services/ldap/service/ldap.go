@@ -44,6 +44,9 @@ type LDAPImpl struct {
 	features    featuremgmt.FeatureToggles
 	ssoSettings ssosettings.Service
 
+	// newServer creates the LDAP server used to test a connection, defaults to ldap.New
+	newServer func(*ldap.ServerConfig, *ldap.Config) ldap.IServer
+
 	// loadingMutex locks the reading of the config so multiple requests for reloading are sequential.
 	loadingMutex *sync.Mutex
 }
@@ -54,6 +57,7 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, ssoSe
 		loadingMutex: &sync.Mutex{},
 		features:     features,
 		ssoSettings:  ssoSettings,
+		newServer:    ldap.New,
 	}
 
 	if s.features.IsEnabledGlobally(featuremgmt.FlagSsoSettingsApi) && s.features.IsEnabledGlobally(featuremgmt.FlagSsoSettingsLDAP) {
@@ -99,8 +103,22 @@ func (s *LDAPImpl) Reload(ctx context.Context, settings models.SSOSettings) erro
 		return err
 	}
 
-	// calculate MinTLSVersionID and TLSCipherIDs from input text values
-	// also initialize Timeout and OrgID from group mappings with default values if they are not configured
+	s.prepareServers(ldapCfg)
+
+	s.loadingMutex.Lock()
+	defer s.loadingMutex.Unlock()
+
+	s.cfg = cfg
+	s.ldapCfg = ldapCfg
+	s.client = multildap.New(s.ldapCfg.Servers, s.cfg)
+
+	return nil
+}
+
+// prepareServers calculates MinTLSVersionID and TLSCipherIDs from input text values,
+// it also initializes Timeout and OrgID from group mappings with default values if they are not configured
+func (s *LDAPImpl) prepareServers(ldapCfg *ldap.ServersConfig) {
+	var err error
 	for _, server := range ldapCfg.Servers {
 		if server.MinTLSVersion != "" {
 			server.MinTLSVersionID, err = util.TlsNameToVersion(server.MinTLSVersion)
@@ -126,13 +144,40 @@ func (s *LDAPImpl) Reload(ctx context.Context, settings models.SSOSettings) erro
 			server.Timeout = ldap.DefaultTimeout
 		}
 	}
+}
 
-	s.loadingMutex.Lock()
-	defer s.loadingMutex.Unlock()
+// TestConnection dials every configured LDAP server and binds with the configured bind DN and password,
+// without changing the servers currently used for authentication.
+func (s *LDAPImpl) TestConnection(ctx context.Context, settings models.SSOSettings) error {
+	ldapCfg, err := resolveServerConfig(settings.Settings["config"])
+	if err != nil {
+		return err
+	}
 
-	s.cfg = cfg
-	s.ldapCfg = ldapCfg
-	s.client = multildap.New(s.ldapCfg.Servers, s.cfg)
+	if len(ldapCfg.Servers) == 0 {
+		return fmt.Errorf("no servers configured for LDAP")
+	}
+
+	s.prepareServers(ldapCfg)
+
+	newServer := s.newServer
+	if newServer == nil {
+		newServer = ldap.New
+	}
+
+	cfg := &ldap.Config{Enabled: true}
+	for i, serverCfg := range ldapCfg.Servers {
+		server := newServer(serverCfg, cfg)
+		if err := server.Dial(); err != nil {
+			return fmt.Errorf("failed to connect to server with index %d (%s:%d): %w", i, serverCfg.Host, serverCfg.Port, err)
+		}
+
+		err := server.Bind()
+		server.Close()
+		if err != nil {
+			return fmt.Errorf("failed to bind to server with index %d (%s:%d): %w", i, serverCfg.Host, serverCfg.Port, err)
+		}
+	}
 
 	return nil
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"sync"
 	"testing"
 
@@ -559,3 +560,107 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+// fakeServer is a local stand-in for an LDAP server, it records the bind credentials
+type fakeServer struct {
+	ldap.IServer
+	config  *ldap.ServerConfig
+	dialErr error
+	bindErr error
+	closed  bool
+}
+
+func (f *fakeServer) Dial() error {
+	return f.dialErr
+}
+
+func (f *fakeServer) Bind() error {
+	return f.bindErr
+}
+
+func (f *fakeServer) Close() {
+	f.closed = true
+}
+
+func TestTestConnection(t *testing.T) {
+	settingsWithServers := func(hosts ...string) models.SSOSettings {
+		servers := make([]any, 0, len(hosts))
+		for _, host := range hosts {
+			servers = append(servers, map[string]any{
+				"host":          host,
+				"port":          389,
+				"bind_dn":       "cn=admin,dc=grafana,dc=org",
+				"bind_password": "grafana",
+			})
+		}
+		return models.SSOSettings{
+			Provider: "ldap",
+			Settings: map[string]any{
+				"enabled": true,
+				"config":  map[string]any{"servers": servers},
+			},
+		}
+	}
+
+	testCases := []struct {
+		description   string
+		settings      models.SSOSettings
+		dialErr       map[string]error
+		bindErr       map[string]error
+		containsError string
+	}{
+		{
+			description: "binds to every configured server",
+			settings:    settingsWithServers("127.0.0.1", "127.0.0.2"),
+		},
+		{
+			description:   "fails when a server cannot be reached",
+			settings:      settingsWithServers("127.0.0.1", "127.0.0.2"),
+			dialErr:       map[string]error{"127.0.0.2": errors.New("connection refused")},
+			containsError: "failed to connect to server with index 1 (127.0.0.2:389): connection refused",
+		},
+		{
+			description:   "fails when the bind credentials are rejected",
+			settings:      settingsWithServers("127.0.0.1"),
+			bindErr:       map[string]error{"127.0.0.1": ldap.ErrInvalidCredentials},
+			containsError: "failed to bind to server with index 0 (127.0.0.1:389)",
+		},
+		{
+			description:   "fails when no servers are configured",
+			settings:      settingsWithServers(),
+			containsError: "no servers configured",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			servers := make([]*fakeServer, 0)
+			ldapImpl := &LDAPImpl{
+				features:     featuremgmt.WithManager(featuremgmt.FlagSsoSettingsApi),
+				loadingMutex: &sync.Mutex{},
+				newServer: func(config *ldap.ServerConfig, _ *ldap.Config) ldap.IServer {
+					server := &fakeServer{config: config, dialErr: tt.dialErr[config.Host], bindErr: tt.bindErr[config.Host]}
+					servers = append(servers, server)
+					return server
+				},
+			}
+
+			err := ldapImpl.TestConnection(context.Background(), tt.settings)
+			if tt.containsError != "" {
+				require.ErrorContains(t, err, tt.containsError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, servers, 2)
+			for _, server := range servers {
+				require.True(t, server.closed)
+				require.Equal(t, "cn=admin,dc=grafana,dc=org", server.config.BindDN)
+				require.Equal(t, "grafana", server.config.BindPassword)
+				require.Equal(t, ldap.DefaultTimeout, server.config.Timeout)
+			}
+			// the servers used for authentication are left untouched
+			require.Nil(t, ldapImpl.client)
+		})
+	}
+}
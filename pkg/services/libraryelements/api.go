@@ -1,8 +1,10 @@
 package libraryelements
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -30,6 +32,11 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/:uid/connections/", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getConnectionsHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.patchHandler))
+			entities.Get("/:uid/versions", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getVersionHandler))
+			entities.Post("/:uid/versions/:version/restore", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.restoreVersionHandler))
+			entities.Get("/:uid/diff", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.diffVersionsHandler))
+			entities.Post("/:uid/impact", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.previewImpactHandler))
 		} else {
 			entities.Post("/", routing.Wrap(l.createHandler))
 			entities.Delete("/:uid", routing.Wrap(l.deleteHandler))
@@ -38,6 +45,11 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/:uid/connections/", routing.Wrap(l.getConnectionsHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", routing.Wrap(l.patchHandler))
+			entities.Get("/:uid/versions", routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", routing.Wrap(l.getVersionHandler))
+			entities.Post("/:uid/versions/:version/restore", routing.Wrap(l.restoreVersionHandler))
+			entities.Get("/:uid/diff", routing.Wrap(l.diffVersionsHandler))
+			entities.Post("/:uid/impact", routing.Wrap(l.previewImpactHandler))
 		}
 	})
 }
@@ -311,6 +323,148 @@ func (l *LibraryElementService) getByNameHandler(c *contextmodel.ReqContext) res
 	}
 }
 
+// swagger:route GET /library-elements/{library_element_uid}/versions library_elements getLibraryElementVersions
+//
+// Get library element versions.
+//
+// Returns the versions of a library element, the latest first. The models of the versions are not included.
+//
+// Responses:
+// 200: getLibraryElementVersionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionsHandler(c *contextmodel.ReqContext) response.Response {
+	versions, err := l.getVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element versions")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionsResponse{Result: versions})
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/versions/{library_element_version} library_elements getLibraryElementVersion
+//
+// Get library element version.
+//
+// Returns a version of a library element, including its model.
+//
+// Responses:
+// 200: getLibraryElementVersionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionHandler(c *contextmodel.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	result, err := l.getVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element version")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionResponse{Result: result})
+}
+
+// swagger:route POST /library-elements/{library_element_uid}/versions/{library_element_version}/restore library_elements restoreLibraryElementVersion
+//
+// Restore library element version.
+//
+// Updates the library element with the name and the model of a previous version. The restored state is stored as a new version.
+//
+// Responses:
+// 200: getLibraryElementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) restoreVersionHandler(c *contextmodel.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	cmd := model.RestoreLibraryElementVersionCommand{}
+	if c.Req.ContentLength != 0 {
+		if err := web.Bind(c.Req, &cmd); err != nil {
+			return response.Error(http.StatusBadRequest, "bad request data", err)
+		}
+	}
+
+	element, err := l.restoreVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version, cmd)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to restore library element version")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementResponse{Result: element})
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/diff library_elements getLibraryElementVersionsDiff
+//
+// Compare library element versions.
+//
+// Returns the JSON patch (RFC 6902) transforming the model of the base version into the model of the new version.
+//
+// Responses:
+// 200: getLibraryElementVersionsDiffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) diffVersionsHandler(c *contextmodel.ReqContext) response.Response {
+	baseVersion := c.QueryInt64("base")
+	newVersion := c.QueryInt64("new")
+	if baseVersion <= 0 || newVersion <= 0 {
+		return response.Error(http.StatusBadRequest, "base and new versions are required", nil)
+	}
+
+	diff, err := l.diffVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], baseVersion, newVersion)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to compare library element versions")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionDiffResponse{Result: diff})
+}
+
+// swagger:route POST /library-elements/{library_element_uid}/impact library_elements previewLibraryElementImpact
+//
+// Preview the impact of a library element change.
+//
+// Lists the dashboards connected to the library element and, for each of them, the variables and the data
+// source variables the given model references which the dashboard doesn't define or defines with another type.
+// Nothing is saved.
+//
+// Responses:
+// 200: previewLibraryElementImpactResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) previewImpactHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := model.PreviewLibraryElementImpactCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if !json.Valid(cmd.Model) {
+		return response.Error(http.StatusBadRequest, "model is not valid JSON", nil)
+	}
+
+	impact, err := l.previewImpact(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to preview library element impact")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementImpactResponse{Result: impact})
+}
+
 func (l *LibraryElementService) filterLibraryPanelsByPermission(c *contextmodel.ReqContext, elements []model.LibraryElementDTO) ([]model.LibraryElementDTO, error) {
 	filteredPanels := make([]model.LibraryElementDTO, 0)
 	for _, p := range elements {
@@ -336,6 +490,9 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, model.ErrLibraryElementDashboardNotFound) {
 		return response.Error(http.StatusNotFound, model.ErrLibraryElementDashboardNotFound.Error(), err)
 	}
+	if errors.Is(err, model.ErrLibraryElementVersionNotFound) {
+		return response.Error(http.StatusNotFound, model.ErrLibraryElementVersionNotFound.Error(), err)
+	}
 	if errors.Is(err, model.ErrLibraryElementVersionMismatch) {
 		return response.Error(http.StatusPreconditionFailed, model.ErrLibraryElementVersionMismatch.Error(), err)
 	}
@@ -357,7 +514,7 @@ func toLibraryElementError(err error, message string) response.Response {
 	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
 }
 
-// swagger:parameters getLibraryElementByUID getLibraryElementConnections
+// swagger:parameters getLibraryElementByUID getLibraryElementConnections getLibraryElementVersions
 type LibraryElementByUID struct {
 	// in:path
 	// required:true
@@ -478,3 +635,75 @@ type GetLibraryElementConnectionsResponse struct {
 	// in: body
 	Body model.LibraryElementConnectionsResponse `json:"body"`
 }
+
+// swagger:parameters getLibraryElementVersion
+type GetLibraryElementVersionParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// in:path
+	// required:true
+	Version int64 `json:"library_element_version"`
+}
+
+// swagger:parameters restoreLibraryElementVersion
+type RestoreLibraryElementVersionParams struct {
+	// in:body
+	// required:false
+	Body model.RestoreLibraryElementVersionCommand `json:"body"`
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// in:path
+	// required:true
+	Version int64 `json:"library_element_version"`
+}
+
+// swagger:parameters getLibraryElementVersionsDiff
+type GetLibraryElementVersionsDiffParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// Version the patch applies to.
+	// in:query
+	// required:true
+	Base int64 `json:"base"`
+	// Version the patch results in.
+	// in:query
+	// required:true
+	New int64 `json:"new"`
+}
+
+// swagger:parameters previewLibraryElementImpact
+type PreviewLibraryElementImpactParams struct {
+	// in:body
+	// required:true
+	Body model.PreviewLibraryElementImpactCommand `json:"body"`
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+}
+
+// swagger:response getLibraryElementVersionsResponse
+type GetLibraryElementVersionsResponse struct {
+	// in: body
+	Body model.LibraryElementVersionsResponse `json:"body"`
+}
+
+// swagger:response getLibraryElementVersionResponse
+type GetLibraryElementVersionResponse struct {
+	// in: body
+	Body model.LibraryElementVersionResponse `json:"body"`
+}
+
+// swagger:response getLibraryElementVersionsDiffResponse
+type GetLibraryElementVersionsDiffResponse struct {
+	// in: body
+	Body model.LibraryElementVersionDiffResponse `json:"body"`
+}
+
+// swagger:response previewLibraryElementImpactResponse
+type PreviewLibraryElementImpactResponse struct {
+	// in: body
+	Body model.LibraryElementImpactResponse `json:"body"`
+}
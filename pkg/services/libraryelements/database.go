@@ -191,7 +191,7 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
-		return nil
+		return insertLibraryElementVersion(session, element, "", 0)
 	})
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
@@ -293,6 +293,9 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 		} else if rowsAffected != 1 {
 			return model.ErrLibraryElementNotFound
 		}
+		if _, err := session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", element.ID); err != nil {
+			return err
+		}
 
 		elementID = element.ID
 		return nil
@@ -663,6 +666,9 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		if err := syncFieldsWithModel(&libraryElement); err != nil {
 			return err
 		}
+		if err := backfillLibraryElementVersion(session, elementInDB); err != nil {
+			return err
+		}
 		if rowsAffected, err := session.ID(elementInDB.ID).Update(&libraryElement); err != nil {
 			if l.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
 				return model.ErrLibraryElementAlreadyExists
@@ -671,6 +677,9 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		} else if rowsAffected != 1 {
 			return model.ErrLibraryElementNotFound
 		}
+		if err := insertLibraryElementVersion(session, libraryElement, cmd.Message, cmd.RestoredFrom); err != nil {
+			return err
+		}
 
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
 		dto = model.LibraryElementDTO{
@@ -923,6 +932,10 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 			if err != nil {
 				return err
 			}
+			_, err = session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", elementID.ID)
+			if err != nil {
+				return err
+			}
 		}
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_uid=? AND org_id=?", folderUID, signedInUser.GetOrgID()); err != nil {
 			return err
@@ -0,0 +1,225 @@
+package libraryelements
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+// variableRegex matches the variable syntaxes supported by the template service of the frontend:
+// $var, [[var]], [[var:format]], ${var}, ${var.field} and ${var:format}.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::\w+)?\]\]|\$\{(\w+)(?:\.[^:^}]+)?(?::[^}]+)?\}`)
+
+// legacyBuiltInVariables are the global variables which don't start with __.
+var legacyBuiltInVariables = map[string]bool{
+	"timeFilter": true,
+	"interval":   true,
+}
+
+// previewImpact lists the dashboards connected to the library element and, for each of them, the references
+// of the given model which can't be resolved in the dashboard. Connected dashboards the user can't read are
+// only counted.
+func (l *LibraryElementService) previewImpact(c context.Context, signedInUser identity.Requester, uid string, cmd model.PreviewLibraryElementImpactCommand) (model.LibraryElementImpact, error) {
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		return model.LibraryElementImpact{}, err
+	}
+
+	impact := model.LibraryElementImpact{
+		ElementUID: element.UID,
+		Dashboards: make([]model.LibraryElementDashboardImpact, 0),
+	}
+
+	dashboardIDs := make([]int64, 0)
+	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		return session.SQL("SELECT connection_id FROM "+model.LibraryElementConnectionTableName+" WHERE element_id=? AND kind=?",
+			element.ID, model.Dashboard).Find(&dashboardIDs)
+	})
+	if err != nil {
+		return model.LibraryElementImpact{}, err
+	}
+	if len(dashboardIDs) == 0 {
+		return impact, nil
+	}
+
+	visible, err := l.findConnectedDashboards(c, signedInUser, dashboardIDs)
+	if err != nil {
+		return model.LibraryElementImpact{}, err
+	}
+
+	// connections of deleted dashboards are not cleaned up, count the dashboards which still exist
+	_, requester := identity.WithServiceIdentitiy(c, signedInUser.GetOrgID())
+	existing, err := l.findConnectedDashboards(c, requester, dashboardIDs)
+	if err != nil {
+		return model.LibraryElementImpact{}, err
+	}
+	impact.HiddenDashboards = len(existing) - len(visible)
+
+	for _, hit := range visible {
+		dash, err := l.dashboardsService.GetDashboard(c, &dashboards.GetDashboardQuery{UID: hit.UID, OrgID: signedInUser.GetOrgID()})
+		if err != nil {
+			return model.LibraryElementImpact{}, err
+		}
+
+		current := make(map[string]bool)
+		for _, p := range libraryPanelProblems(element.Model, dash.Data) {
+			current[p.Kind+"/"+p.Name] = true
+		}
+
+		problems := libraryPanelProblems(cmd.Model, dash.Data)
+		for i := range problems {
+			problems[i].New = !current[problems[i].Kind+"/"+problems[i].Name]
+		}
+
+		impact.Dashboards = append(impact.Dashboards, model.LibraryElementDashboardImpact{
+			DashboardUID: dash.UID,
+			Title:        dash.Title,
+			FolderUID:    dash.FolderUID,
+			Problems:     problems,
+		})
+	}
+
+	sort.Slice(impact.Dashboards, func(i, j int) bool {
+		return impact.Dashboards[i].Title < impact.Dashboards[j].Title
+	})
+
+	return impact, nil
+}
+
+// findConnectedDashboards returns the dashboards with the given ids the user can read, once each.
+func (l *LibraryElementService) findConnectedDashboards(c context.Context, signedInUser identity.Requester, dashboardIDs []int64) ([]dashboards.DashboardSearchProjection, error) {
+	hits, err := l.dashboardsService.FindDashboards(c, &dashboards.FindPersistedDashboardsQuery{
+		OrgId:        signedInUser.GetOrgID(),
+		DashboardIds: dashboardIDs,
+		Type:         searchstore.TypeDashboard,
+		SignedInUser: signedInUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(hits))
+	result := make([]dashboards.DashboardSearchProjection, 0, len(hits))
+	for _, hit := range hits {
+		if seen[hit.UID] {
+			continue
+		}
+		seen[hit.UID] = true
+		result = append(result, hit)
+	}
+	return result, nil
+}
+
+// libraryPanelProblems returns the references of the panel model to variables the dashboard doesn't define,
+// and the data source variables of the dashboard selecting another type of data source than the panel.
+// Only the data source and the queries of the panel are checked, they are what breaks when a variable is missing.
+func libraryPanelProblems(panelModel json.RawMessage, dashboard *simplejson.Json) []model.LibraryElementImpactProblem {
+	panel, err := simplejson.NewJson(panelModel)
+	if err != nil {
+		return nil
+	}
+
+	variables := make(map[string]*simplejson.Json)
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		if name := variable.Get("name").MustString(); name != "" {
+			variables[name] = variable
+		}
+	}
+
+	problems := make(map[string]model.LibraryElementImpactProblem)
+	add := func(p model.LibraryElementImpactProblem) {
+		if _, ok := problems[p.Kind+"/"+p.Name]; !ok {
+			problems[p.Kind+"/"+p.Name] = p
+		}
+	}
+
+	references := make([]string, 0)
+	for _, key := range []string{"datasource", "targets"} {
+		if value, ok := panel.CheckGet(key); ok {
+			encoded, err := value.Encode()
+			if err == nil {
+				references = append(references, variableReferences(string(encoded))...)
+			}
+		}
+	}
+	if repeat := panel.Get("repeat").MustString(); repeat != "" {
+		references = append(references, repeat)
+	}
+
+	for _, name := range references {
+		if _, ok := variables[name]; !ok {
+			add(model.LibraryElementImpactProblem{
+				Kind:    model.ImpactProblemVariable,
+				Name:    name,
+				Message: fmt.Sprintf("variable %q is not defined in the dashboard", name),
+			})
+		}
+	}
+
+	datasources := []*simplejson.Json{panel.Get("datasource")}
+	for _, target := range panel.Get("targets").MustArray() {
+		datasources = append(datasources, simplejson.NewFromAny(target).Get("datasource"))
+	}
+	for _, ds := range datasources {
+		dsUID, dsType := ds.Get("uid").MustString(), ds.Get("type").MustString()
+		names := variableReferences(dsUID)
+		if len(names) != 1 {
+			continue
+		}
+		variable, ok := variables[names[0]]
+		if !ok {
+			continue
+		}
+		if variable.Get("type").MustString() != "datasource" {
+			add(model.LibraryElementImpactProblem{
+				Kind:    model.ImpactProblemDatasource,
+				Name:    names[0],
+				Message: fmt.Sprintf("variable %q is not a data source variable", names[0]),
+			})
+			continue
+		}
+		if query := variable.Get("query").MustString(); dsType != "" && query != "" && query != dsType {
+			add(model.LibraryElementImpactProblem{
+				Kind:    model.ImpactProblemDatasource,
+				Name:    names[0],
+				Message: fmt.Sprintf("variable %q selects %s data sources, the panel queries a %s data source", names[0], query, dsType),
+			})
+		}
+	}
+
+	result := make([]model.LibraryElementImpactProblem, 0, len(problems))
+	for _, p := range problems {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// variableReferences returns the names of the variables referenced in s, global variables excluded.
+func variableReferences(s string) []string {
+	names := make([]string, 0)
+	for _, match := range variableRegex.FindAllStringSubmatch(s, -1) {
+		name := match[1] + match[2] + match[3]
+		if strings.HasPrefix(name, "__") || legacyBuiltInVariables[name] || strings.Trim(name, "0123456789") == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
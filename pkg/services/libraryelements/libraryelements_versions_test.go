@@ -0,0 +1,237 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	patch := func(t *testing.T, sc scenarioContext, version int64, title, message string) {
+		t.Helper()
+		cmd := model.PatchLibraryElementCommand{
+			FolderID: -1,
+			Model:    []byte(`{"datasource": "${DS_GDEV-TESTDATA}", "id": 1, "title": "` + title + `", "type": "text"}`),
+			Kind:     int64(model.PanelElement),
+			Version:  version,
+			Message:  message,
+		}
+		sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+		sc.reqContext.Req.Body = mockRequestBody(cmd)
+		resp := sc.service.patchHandler(sc.reqContext)
+		require.Equal(t, 200, resp.Status())
+	}
+
+	scenarioWithPanel(t, "When an admin updates a library panel, every version should be kept",
+		func(t *testing.T, sc scenarioContext) {
+			patch(t, sc, 1, "CPU", "rename to CPU")
+			patch(t, sc, 2, "CPU usage", "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var versions model.LibraryElementVersionsResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &versions))
+
+			require.Len(t, versions.Result, 3)
+			for i, v := range versions.Result {
+				require.Equal(t, int64(3-i), v.Version)
+				require.Equal(t, sc.initialResult.Result.UID, v.ElementUID)
+				require.Equal(t, userInDbName, v.CreatedBy.Name)
+				require.Empty(t, v.Model)
+			}
+			require.Equal(t, "rename to CPU", versions.Result[1].Message)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var version model.LibraryElementVersionResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &version))
+			require.Equal(t, "Text - Library Panel", simplejsonFromRaw(t, version.Result.Model).Get("title").MustString())
+		})
+
+	scenarioWithPanel(t, "When an admin updates a library panel many times, only the latest versions should be kept",
+		func(t *testing.T, sc scenarioContext) {
+			for i := int64(1); i <= versionsToKeep+5; i++ {
+				patch(t, sc, i, fmt.Sprintf("CPU %d", i), "")
+			}
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var versions model.LibraryElementVersionsResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &versions))
+
+			require.Len(t, versions.Result, versionsToKeep)
+			require.Equal(t, int64(versionsToKeep+6), versions.Result[0].Version)
+			require.Equal(t, int64(7), versions.Result[versionsToKeep-1].Version)
+		})
+
+	scenarioWithPanel(t, "When an admin compares two versions of a library panel, it should return the patch of the model",
+		func(t *testing.T, sc scenarioContext) {
+			patch(t, sc, 1, "CPU", "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.ctx.Req.URL, sc.ctx.Req.Form = &url.URL{RawQuery: "base=1&new=2"}, nil
+			resp := sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var diff model.LibraryElementVersionDiffResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &diff))
+
+			require.Equal(t, model.LibraryElementVersionDiff{
+				ElementUID:  sc.initialResult.Result.UID,
+				BaseVersion: 1,
+				NewVersion:  2,
+				Patch: []dashdiffs.JSONPatchOperation{
+					{Op: "replace", Path: "/title", Value: "CPU"},
+				},
+			}, diff.Result)
+
+			sc.ctx.Req.URL, sc.ctx.Req.Form = &url.URL{RawQuery: "base=1&new=7"}, nil
+			resp = sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+
+			sc.ctx.Req.URL, sc.ctx.Req.Form = &url.URL{RawQuery: "base=1"}, nil
+			resp = sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin restores a version of a library panel, it should be saved as a new version",
+		func(t *testing.T, sc scenarioContext) {
+			patch(t, sc, 1, "CPU", "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp := sc.service.restoreVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, int64(3), result.Result.Version)
+			require.Equal(t, "Text - Library Panel", result.Result.Model["title"])
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "3"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var version model.LibraryElementVersionResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &version))
+			require.Equal(t, int64(1), version.Result.RestoredFrom)
+			require.Equal(t, LibraryElementRestoreMessage(1), version.Result.Message)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "9"})
+			resp = sc.service.restoreVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin deletes a library panel, its versions should be deleted",
+		func(t *testing.T, sc scenarioContext) {
+			patch(t, sc, 1, "CPU", "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			err := sc.sqlStore.WithDbSession(sc.reqContext.Req.Context(), func(session *db.Session) error {
+				count, err := session.Table(model.LibraryElementVersionTableName).Count()
+				require.Zero(t, count)
+				return err
+			})
+			require.NoError(t, err)
+		})
+}
+
+func TestPreviewLibraryElementImpact(t *testing.T) {
+	scenarioWithPanel(t, "When an admin previews a change of a connected library panel, it should flag the unresolved references",
+		func(t *testing.T, sc scenarioContext) {
+			dash := dashboards.Dashboard{
+				Title: "Testing PreviewLibraryElementImpact",
+				Data: simplejson.NewFromAny(map[string]any{
+					"templating": map[string]any{
+						"list": []any{
+							map[string]any{"name": "job", "type": "query"},
+							map[string]any{"name": "ds", "type": "datasource", "query": "prometheus"},
+						},
+					},
+					"panels": []any{
+						map[string]any{"id": int64(1), "libraryPanel": map[string]any{"uid": sc.initialResult.Result.UID}},
+					},
+				}),
+			}
+			// nolint:staticcheck
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.ID, sc.folder.UID)
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, []string{sc.initialResult.Result.UID}, dashInDB.ID)
+			require.NoError(t, err)
+
+			preview := func() model.LibraryElementImpact {
+				sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+				sc.reqContext.Req.Body = mockRequestBody(model.PreviewLibraryElementImpactCommand{
+					Model: []byte(`{
+						"type": "timeseries",
+						"datasource": {"type": "loki", "uid": "${ds}"},
+						"targets": [{"expr": "rate(http_requests_total{job=\"$job\", instance=\"$instance\"}[$__rate_interval])"}]
+					}`),
+				})
+				resp := sc.service.previewImpactHandler(sc.reqContext)
+				require.Equal(t, 200, resp.Status())
+				var impact model.LibraryElementImpactResponse
+				require.NoError(t, json.Unmarshal(resp.Body(), &impact))
+				return impact.Result
+			}
+
+			// the dashboards the user can't read are only counted
+			impact := preview()
+			require.Empty(t, impact.Dashboards)
+			require.Equal(t, 1, impact.HiddenDashboards)
+
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionDashboardsRead] = []string{dashboards.ScopeDashboardsAll}
+
+			require.Equal(t, model.LibraryElementImpact{
+				ElementUID: sc.initialResult.Result.UID,
+				Dashboards: []model.LibraryElementDashboardImpact{
+					{
+						DashboardUID: dashInDB.UID,
+						Title:        dashInDB.Title,
+						FolderUID:    sc.folder.UID,
+						Problems: []model.LibraryElementImpactProblem{
+							{Kind: model.ImpactProblemDatasource, Name: "ds", Message: `variable "ds" selects prometheus data sources, the panel queries a loki data source`, New: true},
+							{Kind: model.ImpactProblemVariable, Name: "instance", Message: `variable "instance" is not defined in the dashboard`, New: true},
+						},
+					},
+				},
+			}, preview())
+		})
+
+	scenarioWithPanel(t, "When an admin previews a change of a library panel without connections, it should return no dashboards",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(model.PreviewLibraryElementImpactCommand{Model: []byte(`{"targets": [{"expr": "$missing"}]}`)})
+			resp := sc.service.previewImpactHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var impact model.LibraryElementImpactResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &impact))
+			require.Empty(t, impact.Result.Dashboards)
+		})
+}
+
+func TestVariableReferences(t *testing.T) {
+	require.Equal(t,
+		[]string{"job", "instance", "env", "region", "cluster"},
+		variableReferences(`{job="$job", instance=~"${instance:regex}", env="[[env]]", region="${region.text}", cluster="[[cluster:csv]]"} $1 $__interval $timeFilter`),
+	)
+	require.Empty(t, variableReferences(`${DS_GDEV-TESTDATA}`))
+}
+
+func simplejsonFromRaw(t *testing.T, raw json.RawMessage) *simplejson.Json {
+	t.Helper()
+	data, err := simplejson.NewJson(raw)
+	require.NoError(t, err)
+	return data
+}
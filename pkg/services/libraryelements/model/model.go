@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/kinds/librarypanel"
 )
 
@@ -137,6 +138,93 @@ type LibraryElementConnectionDTO struct {
 	CreatedBy     librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
 }
 
+// LibraryElementVersion is the model for the versions of library elements. A version
+// is stored every time an element is created, updated or restored.
+type LibraryElementVersion struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	ElementID    int64  `xorm:"element_id"`
+	Version      int64  `xorm:"'version'"`
+	RestoredFrom int64  `xorm:"restored_from"`
+	Name         string `xorm:"name"`
+	Model        json.RawMessage
+	Message      string
+	Created      time.Time
+	CreatedBy    int64
+}
+
+// LibraryElementVersionWithMeta is the model for library element versions with meta.
+type LibraryElementVersionWithMeta struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	OrgID          int64  `xorm:"org_id"`
+	ElementID      int64  `xorm:"element_id"`
+	Version        int64  `xorm:"'version'"`
+	RestoredFrom   int64  `xorm:"restored_from"`
+	Name           string `xorm:"name"`
+	Model          json.RawMessage
+	Message        string
+	Created        time.Time
+	CreatedBy      int64
+	CreatedByName  string
+	CreatedByEmail string
+}
+
+// LibraryElementVersionDTO is the frontend DTO for library element versions.
+type LibraryElementVersionDTO struct {
+	ID           int64                                  `json:"id"`
+	ElementUID   string                                 `json:"elementUid"`
+	Version      int64                                  `json:"version"`
+	RestoredFrom int64                                  `json:"restoredFrom,omitempty"`
+	Name         string                                 `json:"name"`
+	Model        json.RawMessage                        `json:"model,omitempty"`
+	Message      string                                 `json:"message"`
+	Created      time.Time                              `json:"created"`
+	CreatedBy    librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
+// LibraryElementVersionDiff holds the JSON patch transforming the model of the base version
+// of a library element into the model of the new version.
+type LibraryElementVersionDiff struct {
+	ElementUID  string                         `json:"elementUid"`
+	BaseVersion int64                          `json:"baseVersion"`
+	NewVersion  int64                          `json:"newVersion"`
+	NameChanged bool                           `json:"nameChanged"`
+	Patch       []dashdiffs.JSONPatchOperation `json:"patch"`
+}
+
+// LibraryElementImpact lists the dashboards connected to a library element and the problems
+// a change of the model of the element would cause in each of them.
+type LibraryElementImpact struct {
+	ElementUID string                          `json:"elementUid"`
+	Dashboards []LibraryElementDashboardImpact `json:"dashboards"`
+	// HiddenDashboards is the number of connected dashboards the user can't read.
+	HiddenDashboards int `json:"hiddenDashboards"`
+}
+
+// LibraryElementDashboardImpact is the impact of a change of a library element on a connected dashboard.
+type LibraryElementDashboardImpact struct {
+	DashboardUID string                        `json:"dashboardUid"`
+	Title        string                        `json:"title"`
+	FolderUID    string                        `json:"folderUid"`
+	Problems     []LibraryElementImpactProblem `json:"problems"`
+}
+
+// LibraryElementImpactProblem is a reference of the library element which can't be resolved in a dashboard.
+type LibraryElementImpactProblem struct {
+	// Kind is either variable, for a variable missing from the dashboard, or datasource, for a data source
+	// variable of another type than the data source of the panel.
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	// New is true when the problem is caused by the change, false when the current model already has it.
+	New bool `json:"new"`
+}
+
+const (
+	ImpactProblemVariable   = "variable"
+	ImpactProblemDatasource = "datasource"
+)
+
 var (
 	// errLibraryElementAlreadyExists is an error for when the user tries to add a library element that already exists.
 	ErrLibraryElementAlreadyExists = errors.New("library element with that name or UID already exists")
@@ -156,6 +244,8 @@ var (
 	ErrLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	ErrLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// ErrLibraryElementVersionNotFound is an error for when a version of a library element can't be found.
+	ErrLibraryElementVersionNotFound = errors.New("library element version could not be found")
 )
 
 // Commands
@@ -206,6 +296,25 @@ type PatchLibraryElementCommand struct {
 	Version int64 `json:"version" binding:"Required"`
 	// required: false
 	UID string `json:"uid"`
+	// Message describing the change, stored with the new version.
+	// required: false
+	Message string `json:"message"`
+
+	// RestoredFrom is the version restored by the patch.
+	RestoredFrom int64 `json:"-"`
+}
+
+// RestoreLibraryElementVersionCommand is the command for restoring a version of a library element.
+type RestoreLibraryElementVersionCommand struct {
+	// Message stored with the new version, defaults to "Restored from version <version>".
+	Message string `json:"message"`
+}
+
+// PreviewLibraryElementImpactCommand is the command for previewing the impact of a change of a library element.
+type PreviewLibraryElementImpactCommand struct {
+	// The JSON model the library element would be updated with.
+	// swagger:type object
+	Model json.RawMessage `json:"model" binding:"Required"`
 }
 
 // GetLibraryElementCommand is the command for getting a library element.
@@ -251,6 +360,26 @@ type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
 }
 
+// LibraryElementVersionsResponse is a response struct for an array of LibraryElementVersionDTO.
+type LibraryElementVersionsResponse struct {
+	Result []LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementVersionResponse is a response struct for LibraryElementVersionDTO.
+type LibraryElementVersionResponse struct {
+	Result LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementVersionDiffResponse is a response struct for LibraryElementVersionDiff.
+type LibraryElementVersionDiffResponse struct {
+	Result LibraryElementVersionDiff `json:"result"`
+}
+
+// LibraryElementImpactResponse is a response struct for LibraryElementImpact.
+type LibraryElementImpactResponse struct {
+	Result LibraryElementImpact `json:"result"`
+}
+
 // DeleteLibraryElementResponse is the response struct for deleting a library element.
 type DeleteLibraryElementResponse struct {
 	ID      int64  `json:"id"`
@@ -268,3 +397,5 @@ const (
 )
 
 const LibraryElementConnectionTableName = "library_element_connection"
+
+const LibraryElementVersionTableName = "library_element_version"
@@ -0,0 +1,214 @@
+package libraryelements
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/kinds/librarypanel"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+// versionsToKeep is the number of versions kept for each library element
+const versionsToKeep = 30
+
+// LibraryElementRestoreMessage returns the message of the version created when restoring a version.
+func LibraryElementRestoreMessage(version int64) string {
+	return fmt.Sprintf("Restored from version %d", version)
+}
+
+// insertLibraryElementVersion stores the current state of the element as a version and deletes the
+// versions older than the last versionsToKeep.
+func insertLibraryElementVersion(session *db.Session, element model.LibraryElement, message string, restoredFrom int64) error {
+	version := model.LibraryElementVersion{
+		OrgID:        element.OrgID,
+		ElementID:    element.ID,
+		Version:      element.Version,
+		RestoredFrom: restoredFrom,
+		Name:         element.Name,
+		Model:        element.Model,
+		Message:      message,
+		Created:      element.Updated,
+		CreatedBy:    element.UpdatedBy,
+	}
+	if _, err := session.Table(model.LibraryElementVersionTableName).Insert(&version); err != nil {
+		return err
+	}
+
+	_, err := session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=? AND version<=?",
+		element.ID, element.Version-versionsToKeep)
+	return err
+}
+
+// backfillLibraryElementVersion stores the current state of an element updated before versions were
+// kept, so the state can still be restored after the update.
+func backfillLibraryElementVersion(session *db.Session, element model.LibraryElementWithMeta) error {
+	exists, err := session.Table(model.LibraryElementVersionTableName).
+		Where("element_id=? AND version=?", element.ID, element.Version).
+		Exist()
+	if err != nil || exists {
+		return err
+	}
+
+	return insertLibraryElementVersion(session, model.LibraryElement{
+		ID:        element.ID,
+		OrgID:     element.OrgID,
+		Name:      element.Name,
+		Model:     element.Model,
+		Version:   element.Version,
+		Updated:   element.Updated,
+		UpdatedBy: element.UpdatedBy,
+	}, "", 0)
+}
+
+// getVersions returns the versions of a library element, the latest first. The models of the versions are omitted.
+func (l *LibraryElementService) getVersions(c context.Context, signedInUser identity.Requester, uid string) ([]model.LibraryElementVersionDTO, error) {
+	element, versions, err := l.findVersions(c, signedInUser, uid, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.LibraryElementVersionDTO, 0, len(versions))
+	for _, version := range versions {
+		dto := l.toVersionDTO(element, version)
+		dto.Model = nil
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+// getVersion returns a version of a library element.
+func (l *LibraryElementService) getVersion(c context.Context, signedInUser identity.Requester, uid string, version int64) (model.LibraryElementVersionDTO, error) {
+	element, versions, err := l.findVersions(c, signedInUser, uid, version)
+	if err != nil {
+		return model.LibraryElementVersionDTO{}, err
+	}
+	if len(versions) == 0 {
+		return model.LibraryElementVersionDTO{}, model.ErrLibraryElementVersionNotFound
+	}
+	return l.toVersionDTO(element, versions[0]), nil
+}
+
+// diffVersions returns the JSON patch transforming the model of the base version into the model of the new version.
+func (l *LibraryElementService) diffVersions(c context.Context, signedInUser identity.Requester, uid string, baseVersion, newVersion int64) (model.LibraryElementVersionDiff, error) {
+	base, err := l.getVersion(c, signedInUser, uid, baseVersion)
+	if err != nil {
+		return model.LibraryElementVersionDiff{}, err
+	}
+	target, err := l.getVersion(c, signedInUser, uid, newVersion)
+	if err != nil {
+		return model.LibraryElementVersionDiff{}, err
+	}
+
+	baseModel, err := simplejson.NewJson(base.Model)
+	if err != nil {
+		return model.LibraryElementVersionDiff{}, err
+	}
+	targetModel, err := simplejson.NewJson(target.Model)
+	if err != nil {
+		return model.LibraryElementVersionDiff{}, err
+	}
+
+	return model.LibraryElementVersionDiff{
+		ElementUID:  uid,
+		BaseVersion: base.Version,
+		NewVersion:  target.Version,
+		NameChanged: base.Name != target.Name,
+		Patch:       dashdiffs.JSONPatch(baseModel.Interface(), targetModel.Interface()),
+	}, nil
+}
+
+// restoreVersion updates the library element with the name and the model of a previous version.
+// The restored state is stored as a new version, the versions in between are kept.
+func (l *LibraryElementService) restoreVersion(c context.Context, signedInUser identity.Requester, uid string, version int64, cmd model.RestoreLibraryElementVersionCommand) (model.LibraryElementDTO, error) {
+	restored, err := l.getVersion(c, signedInUser, uid, version)
+	if err != nil {
+		return model.LibraryElementDTO{}, err
+	}
+
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		return model.LibraryElementDTO{}, err
+	}
+
+	message := cmd.Message
+	if message == "" {
+		message = LibraryElementRestoreMessage(restored.Version)
+	}
+
+	return l.patchLibraryElement(c, signedInUser, model.PatchLibraryElementCommand{
+		FolderID:     -1,
+		Name:         restored.Name,
+		Model:        restored.Model,
+		Kind:         element.Kind,
+		Version:      element.Version,
+		Message:      message,
+		RestoredFrom: restored.Version,
+	}, uid)
+}
+
+// findVersions returns the element with the given uid, if the user can read it, and its versions, or
+// only the given version if version isn't 0.
+func (l *LibraryElementService) findVersions(c context.Context, signedInUser identity.Requester, uid string, version int64) (model.LibraryElementDTO, []model.LibraryElementVersionWithMeta, error) {
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		return model.LibraryElementDTO{}, nil, err
+	}
+
+	versions := make([]model.LibraryElementVersionWithMeta, 0)
+	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		sql := "SELECT lev.*, u.login AS created_by_name, u.email AS created_by_email" +
+			" FROM " + model.LibraryElementVersionTableName + " AS lev" +
+			" LEFT JOIN " + l.SQLStore.GetDialect().Quote("user") + " AS u ON lev.created_by = u.id" +
+			" WHERE lev.element_id=? AND lev.org_id=?"
+		params := []any{element.ID, element.OrgID}
+		if version != 0 {
+			sql += " AND lev.version=?"
+			params = append(params, version)
+		}
+		sql += " ORDER BY lev.version DESC"
+		return session.SQL(sql, params...).Find(&versions)
+	})
+	if err != nil {
+		return model.LibraryElementDTO{}, nil, err
+	}
+
+	// elements updated before versions were kept have no versions yet, their current state is the only version
+	if len(versions) == 0 && (version == 0 || version == element.Version) {
+		versions = append(versions, model.LibraryElementVersionWithMeta{
+			OrgID:         element.OrgID,
+			ElementID:     element.ID,
+			Version:       element.Version,
+			Name:          element.Name,
+			Model:         element.Model,
+			Created:       element.Meta.Updated,
+			CreatedBy:     element.Meta.UpdatedBy.Id,
+			CreatedByName: element.Meta.UpdatedBy.Name,
+		})
+	}
+
+	return element, versions, nil
+}
+
+func (l *LibraryElementService) toVersionDTO(element model.LibraryElementDTO, version model.LibraryElementVersionWithMeta) model.LibraryElementVersionDTO {
+	return model.LibraryElementVersionDTO{
+		ID:           version.ID,
+		ElementUID:   element.UID,
+		Version:      version.Version,
+		RestoredFrom: version.RestoredFrom,
+		Name:         version.Name,
+		Model:        version.Model,
+		Message:      version.Message,
+		Created:      version.Created,
+		CreatedBy: librarypanel.LibraryElementDTOMetaUser{
+			Id:        version.CreatedBy,
+			Name:      version.CreatedByName,
+			AvatarUrl: dtos.GetGravatarUrl(l.Cfg, version.CreatedByEmail),
+		},
+	}
+}
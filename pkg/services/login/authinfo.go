@@ -31,6 +31,7 @@ const (
 	JWTModule              = "jwt"
 	ExtendedJWTModule      = "extendedjwt"
 	RenderModule           = "render"
+	MTLSAuthModule         = "mtls"
 	// OAuth provider modules
 	AzureADAuthModule    = "oauth_azuread"
 	GoogleAuthModule     = "oauth_google"
@@ -45,6 +46,7 @@ const (
 	SAMLLabel = "SAML"
 	LDAPLabel = "LDAP"
 	JWTLabel  = "JWT"
+	MTLSLabel = "mTLS"
 	// OAuth provider labels
 	AuthProxyLabel    = "Auth Proxy"
 	AzureADLabel      = "AzureAD"
@@ -79,6 +81,8 @@ func GetAuthProviderLabel(authModule string) string {
 		return JWTLabel
 	case AuthProxyAuthModule:
 		return AuthProxyLabel
+	case MTLSAuthModule:
+		return MTLSLabel
 	case GenericOAuthModule:
 		return GenericOAuthLabel
 	default:
@@ -7,9 +7,14 @@ import (
 type Service interface {
 	// Add adds a new login attempt record for provided username
 	Add(ctx context.Context, username, IPAddress string) error
-	// Validate checks if username has to many login attempts inside a window.
+	// Validate checks if username has to many login attempts inside a window, is backing off
+	// from recent failures or is locked out.
 	// Will return true if provided username do not have too many attempts.
 	Validate(ctx context.Context, username string) (bool, error)
+	// ValidateIPAddress checks if the IP address, or the subnet it belongs to, has too many
+	// failed login attempts inside a window.
+	// Will return true if provided address do not have too many attempts.
+	ValidateIPAddress(ctx context.Context, IPAddress string) (bool, error)
 	// Reset resets all login attempts attached to username
 	Reset(ctx context.Context, username string) error
 }
@@ -18,5 +23,17 @@ type LoginAttempt struct {
 	Id        int64
 	Username  string
 	IpAddress string
+	Subnet    string
 	Created   int64
 }
+
+// LoginLockout is stored when a username reached the lockout threshold.
+type LoginLockout struct {
+	Id        int64
+	Username  string
+	IpAddress string
+	Attempts  int64
+	Created   int64
+	// Expires is 0 for lockouts that can only be lifted by an admin
+	Expires int64
+}
@@ -0,0 +1,68 @@
+package loginattemptimpl
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin/login-lockouts", func(lockoutRoute routing.RouteRegister) {
+		lockoutRoute.Get("/", authorize(ac.EvalPermission(ac.ActionUsersRead, ac.ScopeGlobalUsersAll)), routing.Wrap(s.getLockouts))
+		lockoutRoute.Delete("/:username", authorize(ac.EvalPermission(ac.ActionUsersWrite, ac.ScopeGlobalUsersAll)), routing.Wrap(s.deleteLockout))
+	}, middleware.ReqSignedIn)
+}
+
+// LockoutDTO is an account locked after too many failed login attempts.
+type LockoutDTO struct {
+	Username  string     `json:"username"`
+	IPAddress string     `json:"ipAddress"`
+	Attempts  int64      `json:"attempts"`
+	Created   time.Time  `json:"created"`
+	Expires   *time.Time `json:"expires,omitempty"`
+}
+
+func (s *Service) getLockouts(c *contextmodel.ReqContext) response.Response {
+	lockouts, err := s.GetActiveLockouts(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get login lockouts", err)
+	}
+
+	result := make([]LockoutDTO, 0, len(lockouts))
+	for _, l := range lockouts {
+		dto := LockoutDTO{
+			Username:  l.Username,
+			IPAddress: l.IpAddress,
+			Attempts:  l.Attempts,
+			Created:   time.Unix(l.Created, 0),
+		}
+		if l.Expires > 0 {
+			expires := time.Unix(l.Expires, 0)
+			dto.Expires = &expires
+		}
+		result = append(result, dto)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) deleteLockout(c *contextmodel.ReqContext) response.Response {
+	username := web.Params(c.Req)[":username"]
+	if err := s.Unlock(c.Req.Context(), username); err != nil {
+		if errors.Is(err, ErrLockoutNotFound) {
+			return response.Error(http.StatusNotFound, "Login lockout not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to unlock user", err)
+	}
+
+	return response.Success("User unlocked")
+}
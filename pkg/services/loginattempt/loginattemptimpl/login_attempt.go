@@ -2,31 +2,58 @@ package loginattemptimpl
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-const loginAttemptsWindow = time.Minute * 5
+const (
+	loginAttemptsWindow = time.Minute * 5
+	// minRetention is how long attempts are kept at least, regardless of the configured windows
+	minRetention = time.Minute * 10
 
-func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService) *Service {
-	return &Service{
-		&xormStore{db: db, now: time.Now},
-		cfg,
-		lock,
-		log.New("login_attempt"),
+	tmplAccountLocked = "account_locked"
+)
+
+func ProvideService(
+	db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService, userService user.Service,
+	notificationService notifications.Service, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+) *Service {
+	s := &Service{
+		store:               &xormStore{db: db, now: time.Now},
+		cfg:                 cfg,
+		lock:                lock,
+		logger:              log.New("login_attempt"),
+		userService:         userService,
+		notificationService: notificationService,
+	}
+
+	if routeRegister != nil {
+		s.registerAPIEndpoints(routeRegister, ac)
 	}
+
+	return s
 }
 
 type Service struct {
-	store  store
-	cfg    *setting.Cfg
-	lock   *serverlock.ServerLockService
-	logger log.Logger
+	store               store
+	cfg                 *setting.Cfg
+	lock                *serverlock.ServerLockService
+	logger              log.Logger
+	userService         user.Service
+	notificationService notifications.Service
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -51,11 +78,21 @@ func (s *Service) Add(ctx context.Context, username, IPAddress string) error {
 		return nil
 	}
 
+	username = strings.ToLower(username)
 	_, err := s.store.CreateLoginAttempt(ctx, CreateLoginAttemptCommand{
-		Username:  strings.ToLower(username),
+		Username:  username,
 		IpAddress: IPAddress,
+		Subnet:    s.subnet(IPAddress),
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	if username == "" || s.cfg.BruteForceLoginProtection.LockoutThreshold <= 0 {
+		return nil
+	}
+
+	return s.lockoutIfNeeded(ctx, username, IPAddress)
 }
 
 func (s *Service) Reset(ctx context.Context, username string) error {
@@ -67,9 +104,19 @@ func (s *Service) Validate(ctx context.Context, username string) (bool, error) {
 		return true, nil
 	}
 
+	username = strings.ToLower(username)
+
+	locked, err := s.isLockedOut(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	if locked {
+		return false, nil
+	}
+
 	loginAttemptCountQuery := GetUserLoginAttemptCountQuery{
-		Username: strings.ToLower(username),
-		Since:    time.Now().Add(-loginAttemptsWindow),
+		Username: username,
+		Since:    time.Now().Add(-s.window()),
 	}
 
 	count, err := s.store.GetUserLoginAttemptCount(ctx, loginAttemptCountQuery)
@@ -81,19 +128,223 @@ func (s *Service) Validate(ctx context.Context, username string) (bool, error) {
 		return false, nil
 	}
 
+	if delay := s.backoff(count); delay > 0 {
+		latest, err := s.store.GetLatestUserLoginAttempt(ctx, GetLatestUserLoginAttemptQuery{Username: username})
+		if err != nil {
+			return false, err
+		}
+		if time.Now().Before(latest.Add(delay)) {
+			return false, nil
+		}
+	}
+
 	return true, nil
 }
 
+func (s *Service) ValidateIPAddress(ctx context.Context, IPAddress string) (bool, error) {
+	policy := s.cfg.BruteForceLoginProtection
+	if s.cfg.DisableBruteForceLoginProtection || policy.DisableIPAddressProtection {
+		return true, nil
+	}
+
+	since := time.Now().Add(-s.window())
+
+	if policy.IPAddressMaxAttempts > 0 {
+		count, err := s.store.GetIPLoginAttemptCount(ctx, GetIPLoginAttemptCountQuery{IpAddress: IPAddress, Since: since})
+		if err != nil {
+			return false, err
+		}
+		if count >= policy.IPAddressMaxAttempts {
+			return false, nil
+		}
+	}
+
+	if subnet := s.subnet(IPAddress); policy.SubnetMaxAttempts > 0 && subnet != "" {
+		count, err := s.store.GetSubnetLoginAttemptCount(ctx, GetSubnetLoginAttemptCountQuery{Subnet: subnet, Since: since})
+		if err != nil {
+			return false, err
+		}
+		if count >= policy.SubnetMaxAttempts {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// GetActiveLockouts returns all lockouts that have not expired yet.
+func (s *Service) GetActiveLockouts(ctx context.Context) ([]loginattempt.LoginLockout, error) {
+	return s.store.GetActiveLockouts(ctx)
+}
+
+// Unlock lifts the lockout of username and forgets its failed login attempts.
+func (s *Service) Unlock(ctx context.Context, username string) error {
+	username = strings.ToLower(username)
+	if err := s.store.DeleteLockout(ctx, DeleteLockoutCommand{Username: username}); err != nil {
+		return err
+	}
+	return s.Reset(ctx, username)
+}
+
+func (s *Service) window() time.Duration {
+	if s.cfg.BruteForceLoginProtection.Window > 0 {
+		return s.cfg.BruteForceLoginProtection.Window
+	}
+	return loginAttemptsWindow
+}
+
+// backoff returns how long to wait after the latest of count failed attempts.
+func (s *Service) backoff(count int64) time.Duration {
+	policy := s.cfg.BruteForceLoginProtection
+	if policy.Backoff <= 0 || count <= 0 {
+		return 0
+	}
+
+	delay := policy.Backoff
+	for i := int64(1); i < count; i++ {
+		delay *= 2
+		if policy.BackoffMax > 0 && delay >= policy.BackoffMax {
+			return policy.BackoffMax
+		}
+	}
+	return delay
+}
+
+// subnet returns the network of the address according to the configured prefixes,
+// or an empty string if the address can't be parsed.
+func (s *Service) subnet(IPAddress string) string {
+	ip := net.ParseIP(strings.Trim(IPAddress, "[]"))
+	if ip == nil {
+		return ""
+	}
+
+	policy := s.cfg.BruteForceLoginProtection
+	mask := net.CIDRMask(policy.SubnetIPv6Prefix, 128)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		mask = net.CIDRMask(policy.SubnetIPv4Prefix, 32)
+	}
+	if mask == nil {
+		return ""
+	}
+
+	ones, _ := mask.Size()
+	return fmt.Sprintf("%s/%d", ip.Mask(mask), ones)
+}
+
+func (s *Service) isLockedOut(ctx context.Context, username string) (bool, error) {
+	lockout, err := s.store.GetLockout(ctx, GetLockoutQuery{Username: username})
+	if err != nil {
+		if errors.Is(err, ErrLockoutNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return lockout.Expires == 0 || time.Now().Unix() < lockout.Expires, nil
+}
+
+func (s *Service) lockoutIfNeeded(ctx context.Context, username, IPAddress string) error {
+	policy := s.cfg.BruteForceLoginProtection
+
+	count, err := s.store.GetUserLoginAttemptCount(ctx, GetUserLoginAttemptCountQuery{
+		Username: username,
+		Since:    time.Now().Add(-policy.LockoutWindow),
+	})
+	if err != nil || count < policy.LockoutThreshold {
+		return err
+	}
+
+	locked, err := s.isLockedOut(ctx, username)
+	if err != nil || locked {
+		return err
+	}
+
+	cmd := CreateLockoutCommand{Username: username, IpAddress: IPAddress, Attempts: count}
+	if policy.LockoutDuration > 0 {
+		cmd.Expires = time.Now().Add(policy.LockoutDuration)
+	}
+
+	lockout, err := s.store.CreateLockout(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Warn("Locked out user after too many failed login attempts", "username", username, "attempts", count, "ip", IPAddress)
+
+	if policy.LockoutNotify {
+		s.notifyLockout(ctx, lockout)
+	}
+
+	return nil
+}
+
+func (s *Service) notifyLockout(ctx context.Context, lockout loginattempt.LoginLockout) {
+	if s.notificationService == nil || s.userService == nil || !s.cfg.Smtp.Enabled {
+		return
+	}
+
+	usr, err := s.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: lockout.Username})
+	if err != nil {
+		if !errors.Is(err, user.ErrUserNotFound) {
+			s.logger.Warn("Failed to find user to notify about lockout", "username", lockout.Username, "error", err)
+		}
+		return
+	}
+	if usr.Email == "" {
+		return
+	}
+
+	lockedUntil := ""
+	if lockout.Expires > 0 {
+		lockedUntil = time.Unix(lockout.Expires, 0).UTC().Format(time.RFC1123)
+	}
+
+	err = s.notificationService.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       []string{usr.Email},
+		Template: tmplAccountLocked,
+		Data: map[string]any{
+			"Name":        usr.NameOrFallback(),
+			"Login":       usr.Login,
+			"IPAddress":   lockout.IpAddress,
+			"Attempts":    lockout.Attempts,
+			"LockedUntil": lockedUntil,
+		},
+	})
+	if err != nil {
+		s.logger.Warn("Failed to send lockout notification", "username", lockout.Username, "error", err)
+	}
+}
+
+// retention returns how long login attempts need to be kept for the configured policies.
+func (s *Service) retention() time.Duration {
+	retention := minRetention
+	if w := s.window(); w > retention {
+		retention = w
+	}
+	policy := s.cfg.BruteForceLoginProtection
+	if policy.LockoutThreshold > 0 && policy.LockoutWindow > retention {
+		retention = policy.LockoutWindow
+	}
+	return retention
+}
+
 func (s *Service) cleanup(ctx context.Context) {
 	err := s.lock.LockAndExecute(ctx, "delete old login attempts", time.Minute*10, func(context.Context) {
 		cmd := DeleteOldLoginAttemptsCommand{
-			OlderThan: time.Now().Add(time.Minute * -10),
+			OlderThan: time.Now().Add(-s.retention()),
 		}
 		if deletedLogs, err := s.store.DeleteOldLoginAttempts(ctx, cmd); err != nil {
 			s.logger.Error("Problem deleting expired login attempts", "error", err.Error())
 		} else {
 			s.logger.Debug("Deleted expired login attempts", "rows affected", deletedLogs)
 		}
+
+		if deleted, err := s.store.DeleteExpiredLockouts(ctx, DeleteExpiredLockoutsCommand{Now: time.Now()}); err != nil {
+			s.logger.Error("Problem deleting expired login lockouts", "error", err.Error())
+		} else {
+			s.logger.Debug("Deleted expired login lockouts", "rows affected", deleted)
+		}
 	})
 
 	if err != nil {
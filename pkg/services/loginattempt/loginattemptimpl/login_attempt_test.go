@@ -3,8 +3,10 @@ package loginattemptimpl
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
@@ -83,13 +85,141 @@ func TestService_Validate(t *testing.T) {
 	}
 }
 
+func TestService_Validate_Policies(t *testing.T) {
+	newService := func(policy setting.BruteForceLoginProtectionSettings, store fakeStore) *Service {
+		cfg := setting.NewCfg()
+		cfg.BruteForceLoginProtectionMaxAttempts = 5
+		cfg.BruteForceLoginProtection = policy
+		return &Service{store: store, cfg: cfg}
+	}
+
+	t.Run("should reject locked out users", func(t *testing.T) {
+		s := newService(setting.BruteForceLoginProtectionSettings{}, fakeStore{
+			ExpectedLockout: &loginattempt.LoginLockout{Username: "test", Expires: time.Now().Add(time.Minute).Unix()},
+		})
+		ok, err := s.Validate(context.Background(), "test")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject users locked out until unlocked by an admin", func(t *testing.T) {
+		s := newService(setting.BruteForceLoginProtectionSettings{}, fakeStore{
+			ExpectedLockout: &loginattempt.LoginLockout{Username: "test"},
+		})
+		ok, err := s.Validate(context.Background(), "test")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should accept users with an expired lockout", func(t *testing.T) {
+		s := newService(setting.BruteForceLoginProtectionSettings{}, fakeStore{
+			ExpectedLockout: &loginattempt.LoginLockout{Username: "test", Expires: time.Now().Add(-time.Minute).Unix()},
+		})
+		ok, err := s.Validate(context.Background(), "test")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("should reject users during backoff", func(t *testing.T) {
+		s := newService(setting.BruteForceLoginProtectionSettings{Backoff: time.Second, BackoffMax: time.Minute}, fakeStore{
+			ExpectedCount:  3,
+			ExpectedLatest: time.Now().Add(-2 * time.Second),
+		})
+		// 3 failed attempts require waiting 4 seconds after the latest one
+		ok, err := s.Validate(context.Background(), "test")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should accept users after backoff", func(t *testing.T) {
+		s := newService(setting.BruteForceLoginProtectionSettings{Backoff: time.Second, BackoffMax: time.Minute}, fakeStore{
+			ExpectedCount:  3,
+			ExpectedLatest: time.Now().Add(-5 * time.Second),
+		})
+		ok, err := s.Validate(context.Background(), "test")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestService_backoff(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtection.Backoff = time.Second
+	cfg.BruteForceLoginProtection.BackoffMax = 10 * time.Second
+	s := &Service{cfg: cfg}
+
+	assert.Equal(t, time.Duration(0), s.backoff(0))
+	assert.Equal(t, time.Second, s.backoff(1))
+	assert.Equal(t, 2*time.Second, s.backoff(2))
+	assert.Equal(t, 8*time.Second, s.backoff(4))
+	assert.Equal(t, 10*time.Second, s.backoff(5))
+	assert.Equal(t, 10*time.Second, s.backoff(100))
+}
+
+func TestService_ValidateIPAddress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   setting.BruteForceLoginProtectionSettings
+		store    fakeStore
+		expected bool
+	}{
+		{
+			name:     "should accept when ip address protection is disabled",
+			policy:   setting.BruteForceLoginProtectionSettings{DisableIPAddressProtection: true, IPAddressMaxAttempts: 1},
+			store:    fakeStore{ExpectedIPCount: 10},
+			expected: true,
+		},
+		{
+			name:     "should accept when ip address attempt count is less than max",
+			policy:   setting.BruteForceLoginProtectionSettings{IPAddressMaxAttempts: 10},
+			store:    fakeStore{ExpectedIPCount: 9},
+			expected: true,
+		},
+		{
+			name:     "should reject when ip address attempt count equals max",
+			policy:   setting.BruteForceLoginProtectionSettings{IPAddressMaxAttempts: 10},
+			store:    fakeStore{ExpectedIPCount: 10},
+			expected: false,
+		},
+		{
+			name:     "should reject when subnet attempt count equals max",
+			policy:   setting.BruteForceLoginProtectionSettings{IPAddressMaxAttempts: 10, SubnetMaxAttempts: 20, SubnetIPv4Prefix: 24},
+			store:    fakeStore{ExpectedIPCount: 1, ExpectedSubnetCount: 20},
+			expected: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.BruteForceLoginProtection = tt.policy
+			s := &Service{store: tt.store, cfg: cfg}
+
+			ok, err := s.ValidateIPAddress(context.Background(), "10.0.0.1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+}
+
+func TestService_subnet(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtection.SubnetIPv4Prefix = 24
+	cfg.BruteForceLoginProtection.SubnetIPv6Prefix = 64
+	s := &Service{cfg: cfg}
+
+	assert.Equal(t, "192.168.1.0/24", s.subnet("192.168.1.42"))
+	assert.Equal(t, "2001:db8:1:2::/64", s.subnet("[2001:db8:1:2:3:4:5:6]"))
+	assert.Equal(t, "", s.subnet("not-an-ip"))
+}
+
 func TestLoginAttempts(t *testing.T) {
 	ctx := context.Background()
 	cfg := setting.NewCfg()
 	cfg.DisableBruteForceLoginProtection = false
 	cfg.BruteForceLoginProtectionMaxAttempts = 5
 	db := db.InitTestDB(t)
-	service := ProvideService(db, cfg, nil)
+	service := ProvideService(db, cfg, nil, nil, nil, nil, nil)
 
 	// add multiple login attempts with different uppercases, they all should be counted as the same user
 	_ = service.Add(ctx, "admin", "[::1]")
@@ -109,11 +239,48 @@ func TestLoginAttempts(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestLoginAttempts_Lockout(t *testing.T) {
+	ctx := context.Background()
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtectionMaxAttempts = 10
+	cfg.BruteForceLoginProtection.LockoutThreshold = 3
+	cfg.BruteForceLoginProtection.LockoutWindow = time.Hour
+	service := ProvideService(db.InitTestDB(t), cfg, nil, nil, nil, nil, nil)
+
+	for i := 0; i < 3; i++ {
+		ok, err := service.Validate(ctx, "admin")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NoError(t, service.Add(ctx, "admin", "192.168.0.1"))
+	}
+
+	ok, err := service.Validate(ctx, "admin")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	lockouts, err := service.GetActiveLockouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, lockouts, 1)
+	assert.Equal(t, "admin", lockouts[0].Username)
+	assert.Equal(t, int64(3), lockouts[0].Attempts)
+	assert.Equal(t, int64(0), lockouts[0].Expires)
+
+	require.NoError(t, service.Unlock(ctx, "Admin"))
+
+	ok, err = service.Validate(ctx, "admin")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
 var _ store = new(fakeStore)
 
 type fakeStore struct {
 	ExpectedErr         error
 	ExpectedCount       int64
+	ExpectedIPCount     int64
+	ExpectedSubnetCount int64
+	ExpectedLatest      time.Time
+	ExpectedLockout     *loginattempt.LoginLockout
 	ExpectedDeletedRows int64
 }
 
@@ -121,6 +288,18 @@ func (f fakeStore) GetUserLoginAttemptCount(ctx context.Context, query GetUserLo
 	return f.ExpectedCount, f.ExpectedErr
 }
 
+func (f fakeStore) GetIPLoginAttemptCount(ctx context.Context, query GetIPLoginAttemptCountQuery) (int64, error) {
+	return f.ExpectedIPCount, f.ExpectedErr
+}
+
+func (f fakeStore) GetSubnetLoginAttemptCount(ctx context.Context, query GetSubnetLoginAttemptCountQuery) (int64, error) {
+	return f.ExpectedSubnetCount, f.ExpectedErr
+}
+
+func (f fakeStore) GetLatestUserLoginAttempt(ctx context.Context, query GetLatestUserLoginAttemptQuery) (time.Time, error) {
+	return f.ExpectedLatest, f.ExpectedErr
+}
+
 func (f fakeStore) CreateLoginAttempt(ctx context.Context, command CreateLoginAttemptCommand) (loginattempt.LoginAttempt, error) {
 	return loginattempt.LoginAttempt{}, f.ExpectedErr
 }
@@ -132,3 +311,26 @@ func (f fakeStore) DeleteOldLoginAttempts(ctx context.Context, command DeleteOld
 func (f fakeStore) DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error {
 	return f.ExpectedErr
 }
+
+func (f fakeStore) CreateLockout(ctx context.Context, cmd CreateLockoutCommand) (loginattempt.LoginLockout, error) {
+	return loginattempt.LoginLockout{}, f.ExpectedErr
+}
+
+func (f fakeStore) GetLockout(ctx context.Context, query GetLockoutQuery) (loginattempt.LoginLockout, error) {
+	if f.ExpectedLockout == nil {
+		return loginattempt.LoginLockout{}, ErrLockoutNotFound
+	}
+	return *f.ExpectedLockout, f.ExpectedErr
+}
+
+func (f fakeStore) GetActiveLockouts(ctx context.Context) ([]loginattempt.LoginLockout, error) {
+	return nil, f.ExpectedErr
+}
+
+func (f fakeStore) DeleteLockout(ctx context.Context, cmd DeleteLockoutCommand) error {
+	return f.ExpectedErr
+}
+
+func (f fakeStore) DeleteExpiredLockouts(ctx context.Context, cmd DeleteExpiredLockoutsCommand) (int64, error) {
+	return f.ExpectedDeletedRows, f.ExpectedErr
+}
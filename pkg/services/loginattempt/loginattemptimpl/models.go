@@ -7,6 +7,7 @@ import (
 type CreateLoginAttemptCommand struct {
 	Username  string
 	IpAddress string
+	Subnet    string
 }
 
 type GetUserLoginAttemptCountQuery struct {
@@ -14,6 +15,20 @@ type GetUserLoginAttemptCountQuery struct {
 	Since    time.Time
 }
 
+type GetIPLoginAttemptCountQuery struct {
+	IpAddress string
+	Since     time.Time
+}
+
+type GetSubnetLoginAttemptCountQuery struct {
+	Subnet string
+	Since  time.Time
+}
+
+type GetLatestUserLoginAttemptQuery struct {
+	Username string
+}
+
 type DeleteOldLoginAttemptsCommand struct {
 	OlderThan time.Time
 }
@@ -21,3 +36,22 @@ type DeleteOldLoginAttemptsCommand struct {
 type DeleteLoginAttemptsCommand struct {
 	Username string
 }
+
+type CreateLockoutCommand struct {
+	Username  string
+	IpAddress string
+	Attempts  int64
+	Expires   time.Time
+}
+
+type GetLockoutQuery struct {
+	Username string
+}
+
+type DeleteLockoutCommand struct {
+	Username string
+}
+
+type DeleteExpiredLockoutsCommand struct {
+	Now time.Time
+}
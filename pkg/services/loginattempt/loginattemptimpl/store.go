@@ -2,6 +2,7 @@ package loginattemptimpl
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -13,11 +14,24 @@ type xormStore struct {
 	now func() time.Time
 }
 
+var ErrLockoutNotFound = errors.New("login lockout not found")
+
 type store interface {
 	CreateLoginAttempt(ctx context.Context, cmd CreateLoginAttemptCommand) (loginattempt.LoginAttempt, error)
 	DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLoginAttemptsCommand) (int64, error)
 	DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error
 	GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error)
+	GetIPLoginAttemptCount(ctx context.Context, query GetIPLoginAttemptCountQuery) (int64, error)
+	GetSubnetLoginAttemptCount(ctx context.Context, query GetSubnetLoginAttemptCountQuery) (int64, error)
+	// GetLatestUserLoginAttempt returns when the last attempt for the username was made, or the zero time.
+	GetLatestUserLoginAttempt(ctx context.Context, query GetLatestUserLoginAttemptQuery) (time.Time, error)
+
+	CreateLockout(ctx context.Context, cmd CreateLockoutCommand) (loginattempt.LoginLockout, error)
+	// GetLockout returns the lockout of the username, whether it is expired or not.
+	GetLockout(ctx context.Context, query GetLockoutQuery) (loginattempt.LoginLockout, error)
+	GetActiveLockouts(ctx context.Context) ([]loginattempt.LoginLockout, error)
+	DeleteLockout(ctx context.Context, cmd DeleteLockoutCommand) error
+	DeleteExpiredLockouts(ctx context.Context, cmd DeleteExpiredLockoutsCommand) (int64, error)
 }
 
 func (xs *xormStore) CreateLoginAttempt(ctx context.Context, cmd CreateLoginAttemptCommand) (result loginattempt.LoginAttempt, err error) {
@@ -25,6 +39,7 @@ func (xs *xormStore) CreateLoginAttempt(ctx context.Context, cmd CreateLoginAtte
 		loginAttempt := loginattempt.LoginAttempt{
 			Username:  cmd.Username,
 			IpAddress: cmd.IpAddress,
+			Subnet:    cmd.Subnet,
 			Created:   xs.now().Unix(),
 		}
 
@@ -82,3 +97,120 @@ func (xs *xormStore) GetUserLoginAttemptCount(ctx context.Context, query GetUser
 
 	return total, err
 }
+
+func (xs *xormStore) GetIPLoginAttemptCount(ctx context.Context, query GetIPLoginAttemptCountQuery) (int64, error) {
+	var total int64
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		var queryErr error
+		total, queryErr = dbSession.
+			Where("ip_address = ?", query.IpAddress).
+			And("created >= ?", query.Since.Unix()).
+			Count(new(loginattempt.LoginAttempt))
+		return queryErr
+	})
+
+	return total, err
+}
+
+func (xs *xormStore) GetSubnetLoginAttemptCount(ctx context.Context, query GetSubnetLoginAttemptCountQuery) (int64, error) {
+	var total int64
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		var queryErr error
+		total, queryErr = dbSession.
+			Where("subnet = ?", query.Subnet).
+			And("created >= ?", query.Since.Unix()).
+			Count(new(loginattempt.LoginAttempt))
+		return queryErr
+	})
+
+	return total, err
+}
+
+func (xs *xormStore) GetLatestUserLoginAttempt(ctx context.Context, query GetLatestUserLoginAttemptQuery) (time.Time, error) {
+	var latest time.Time
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		var attempt loginattempt.LoginAttempt
+		has, err := dbSession.Where("username = ?", query.Username).Desc("created").Limit(1).Get(&attempt)
+		if err != nil || !has {
+			return err
+		}
+		latest = time.Unix(attempt.Created, 0)
+		return nil
+	})
+
+	return latest, err
+}
+
+func (xs *xormStore) CreateLockout(ctx context.Context, cmd CreateLockoutCommand) (result loginattempt.LoginLockout, err error) {
+	err = xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM login_lockout WHERE username = ?", cmd.Username); err != nil {
+			return err
+		}
+
+		lockout := loginattempt.LoginLockout{
+			Username:  cmd.Username,
+			IpAddress: cmd.IpAddress,
+			Attempts:  cmd.Attempts,
+			Created:   xs.now().Unix(),
+		}
+		if !cmd.Expires.IsZero() {
+			lockout.Expires = cmd.Expires.Unix()
+		}
+
+		if _, err := sess.Insert(&lockout); err != nil {
+			return err
+		}
+
+		result = lockout
+		return nil
+	})
+	return result, err
+}
+
+func (xs *xormStore) GetLockout(ctx context.Context, query GetLockoutQuery) (result loginattempt.LoginLockout, err error) {
+	err = xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("username = ?", query.Username).Get(&result)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrLockoutNotFound
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (xs *xormStore) GetActiveLockouts(ctx context.Context) ([]loginattempt.LoginLockout, error) {
+	result := make([]loginattempt.LoginLockout, 0)
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("expires = 0 OR expires > ?", xs.now().Unix()).Asc("username").Find(&result)
+	})
+	return result, err
+}
+
+func (xs *xormStore) DeleteLockout(ctx context.Context, cmd DeleteLockoutCommand) error {
+	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM login_lockout WHERE username = ?", cmd.Username)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+			return ErrLockoutNotFound
+		}
+		return nil
+	})
+}
+
+func (xs *xormStore) DeleteExpiredLockouts(ctx context.Context, cmd DeleteExpiredLockoutsCommand) (int64, error) {
+	var deletedRows int64
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM login_lockout WHERE expires > 0 AND expires <= ?", cmd.Now.Unix())
+		if err != nil {
+			return err
+		}
+		deletedRows, err = res.RowsAffected()
+		return err
+	})
+	return deletedRows, err
+}
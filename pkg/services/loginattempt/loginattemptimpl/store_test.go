@@ -148,3 +148,89 @@ func TestIntegrationLoginAttemptsDelete(t *testing.T) {
 		require.Equal(t, test.DeletedRows, deletedRows, test.Name)
 	}
 }
+
+func TestIntegrationLoginAttemptsIPQuery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Date(2017, 10, 22, 8, 0, 0, 0, time.Local)
+	s := &xormStore{
+		db:  db.InitTestDB(t),
+		now: func() time.Time { return now },
+	}
+
+	for _, cmd := range []CreateLoginAttemptCommand{
+		{Username: "user1", IpAddress: "192.168.0.1", Subnet: "192.168.0.0/24"},
+		{Username: "user2", IpAddress: "192.168.0.1", Subnet: "192.168.0.0/24"},
+		{Username: "user3", IpAddress: "192.168.0.2", Subnet: "192.168.0.0/24"},
+		{Username: "user1", IpAddress: "10.0.0.1", Subnet: "10.0.0.0/24"},
+	} {
+		_, err := s.CreateLoginAttempt(context.Background(), cmd)
+		require.NoError(t, err)
+	}
+
+	count, err := s.GetIPLoginAttemptCount(context.Background(), GetIPLoginAttemptCountQuery{IpAddress: "192.168.0.1", Since: now})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	count, err = s.GetSubnetLoginAttemptCount(context.Background(), GetSubnetLoginAttemptCountQuery{Subnet: "192.168.0.0/24", Since: now})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	count, err = s.GetIPLoginAttemptCount(context.Background(), GetIPLoginAttemptCountQuery{IpAddress: "192.168.0.1", Since: now.Add(time.Second)})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), count)
+
+	latest, err := s.GetLatestUserLoginAttempt(context.Background(), GetLatestUserLoginAttemptQuery{Username: "user1"})
+	require.NoError(t, err)
+	require.Equal(t, now.Unix(), latest.Unix())
+
+	latest, err = s.GetLatestUserLoginAttempt(context.Background(), GetLatestUserLoginAttemptQuery{Username: "unknown"})
+	require.NoError(t, err)
+	require.True(t, latest.IsZero())
+}
+
+func TestIntegrationLoginLockouts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Date(2017, 10, 22, 8, 0, 0, 0, time.Local)
+	s := &xormStore{
+		db:  db.InitTestDB(t),
+		now: func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	_, err := s.GetLockout(ctx, GetLockoutQuery{Username: "user1"})
+	require.ErrorIs(t, err, ErrLockoutNotFound)
+
+	_, err = s.CreateLockout(ctx, CreateLockoutCommand{Username: "user1", IpAddress: "192.168.0.1", Attempts: 10, Expires: now.Add(time.Hour)})
+	require.NoError(t, err)
+	// creating a lockout for the same username replaces the previous one
+	_, err = s.CreateLockout(ctx, CreateLockoutCommand{Username: "user1", IpAddress: "192.168.0.2", Attempts: 12, Expires: now.Add(time.Minute)})
+	require.NoError(t, err)
+	_, err = s.CreateLockout(ctx, CreateLockoutCommand{Username: "user2", IpAddress: "192.168.0.1", Attempts: 10})
+	require.NoError(t, err)
+
+	lockout, err := s.GetLockout(ctx, GetLockoutQuery{Username: "user1"})
+	require.NoError(t, err)
+	require.Equal(t, int64(12), lockout.Attempts)
+	require.Equal(t, now.Add(time.Minute).Unix(), lockout.Expires)
+
+	active, err := s.GetActiveLockouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+
+	deleted, err := s.DeleteExpiredLockouts(ctx, DeleteExpiredLockoutsCommand{Now: now.Add(2 * time.Minute)})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	require.NoError(t, s.DeleteLockout(ctx, DeleteLockoutCommand{Username: "user2"}))
+	require.ErrorIs(t, s.DeleteLockout(ctx, DeleteLockoutCommand{Username: "user2"}), ErrLockoutNotFound)
+
+	active, err = s.GetActiveLockouts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, active)
+}
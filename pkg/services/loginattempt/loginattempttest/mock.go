@@ -13,6 +13,8 @@ type MockLoginAttemptService struct {
 	ResetCalled    bool
 	ValidateCalled bool
 
+	ValidateIPAddressCalled bool
+
 	ExpectedValid bool
 	ExpectedErr   error
 }
@@ -31,3 +33,8 @@ func (f *MockLoginAttemptService) Validate(ctx context.Context, username string)
 	f.ValidateCalled = true
 	return f.ExpectedValid, f.ExpectedErr
 }
+
+func (f *MockLoginAttemptService) ValidateIPAddress(ctx context.Context, IPAddress string) (bool, error) {
+	f.ValidateIPAddressCalled = true
+	return f.ExpectedValid, f.ExpectedErr
+}
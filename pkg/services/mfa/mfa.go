@@ -0,0 +1,147 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionPolicyRead  = "mfa.policy:read"
+	ActionPolicyWrite = "mfa.policy:write"
+	// ActionReset removes every second factor of a user, it is scoped to users
+	ActionReset = "users.mfa:reset"
+)
+
+const (
+	MethodTOTP         = "totp"
+	MethodRecoveryCode = "recovery_code"
+	MethodWebAuthn     = "webauthn"
+)
+
+var (
+	ErrMFARequired = errutil.Unauthorized("mfa.required").MustTemplate(
+		"second factor required",
+		errutil.WithPublic("A second factor is required to log in"),
+	)
+	ErrInvalidFactor       = errutil.Unauthorized("mfa.invalid", errutil.WithPublicMessage("Invalid second factor"))
+	ErrBasicAuthNotAllowed = errutil.Unauthorized("mfa.basicAuthNotAllowed", errutil.WithPublicMessage("Basic auth is not allowed for users with a second factor"))
+	ErrEnrollmentRequired  = errutil.Forbidden("mfa.enrollmentRequired", errutil.WithPublicMessage("Multi-factor authentication must be set up before using Grafana"))
+	ErrNotEnrolled         = errutil.BadRequest("mfa.notEnrolled", errutil.WithPublicMessage("No pending TOTP enrollment"))
+	ErrAlreadyEnrolled     = errutil.BadRequest("mfa.alreadyEnrolled", errutil.WithPublicMessage("TOTP is already set up"))
+	ErrInvalidCode         = errutil.BadRequest("mfa.invalidCode", errutil.WithPublicMessage("Invalid code"))
+	ErrWebAuthnDisabled    = errutil.BadRequest("mfa.webAuthnDisabled", errutil.WithPublicMessage("Security keys are disabled"))
+	ErrInvalidCredential   = errutil.BadRequest("mfa.invalidCredential")
+	ErrCredentialNotFound  = errutil.NotFound("mfa.credentialNotFound", errutil.WithPublicMessage("Security key not found"))
+	ErrInvalidPolicy       = errutil.ValidationFailed("mfa.invalidPolicy")
+)
+
+type Service interface {
+	// GetStatus returns the second factors the user has set up and whether one is required.
+	GetStatus(ctx context.Context, query *GetStatusQuery) (*Status, error)
+	// Reset removes every second factor of a user, e.g. when a device was lost.
+	Reset(ctx context.Context, userID int64) error
+	GetOrgPolicy(ctx context.Context, orgID int64) (*OrgPolicy, error)
+	SetOrgPolicy(ctx context.Context, policy *OrgPolicy) error
+}
+
+type GetStatusQuery struct {
+	UserID         int64
+	OrgID          int64
+	OrgRole        org.RoleType
+	IsGrafanaAdmin bool
+}
+
+type Status struct {
+	TOTP bool `json:"totp"`
+	// RecoveryCodes is the number of unused recovery codes
+	RecoveryCodes int                   `json:"recoveryCodes"`
+	WebAuthn      []*WebAuthnCredential `json:"webauthn"`
+	// Required is true when the server or organization policy requires a second factor
+	Required bool `json:"required"`
+}
+
+// Enrolled returns true when the user has at least one second factor to log in with.
+func (s *Status) Enrolled() bool {
+	return s.TOTP || len(s.WebAuthn) > 0
+}
+
+// OrgPolicy requires members of an organization to set up a second factor.
+type OrgPolicy struct {
+	ID       int64 `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID    int64 `json:"orgId" xorm:"org_id"`
+	Required bool  `json:"required" xorm:"required"`
+	// Roles limits the requirement to members with one of the roles, empty means every member
+	Roles   []org.RoleType `json:"roles" xorm:"-"`
+	RolesDB string         `json:"-" xorm:"roles"`
+	Updated time.Time      `json:"updated" xorm:"updated"`
+}
+
+func (OrgPolicy) TableName() string {
+	return "org_mfa_policy"
+}
+
+// Applies returns true when the policy requires a member with the role to set up a second factor.
+func (p *OrgPolicy) Applies(role org.RoleType) bool {
+	if !p.Required {
+		return false
+	}
+	if len(p.Roles) == 0 {
+		return true
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TOTP is the time-based one-time password secret of a user, the secret is encrypted.
+type TOTP struct {
+	ID        int64     `xorm:"pk autoincr 'id'"`
+	UserID    int64     `xorm:"user_id"`
+	Secret    string    `xorm:"secret"`
+	Confirmed bool      `xorm:"confirmed"`
+	LastStep  int64     `xorm:"last_step"`
+	Created   time.Time `xorm:"'created'"`
+	Updated   time.Time `xorm:"'updated'"`
+}
+
+func (TOTP) TableName() string {
+	return "user_mfa_totp"
+}
+
+type RecoveryCode struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	UserID   int64     `xorm:"user_id"`
+	CodeHash string    `xorm:"code_hash"`
+	Salt     string    `xorm:"salt"`
+	Created  time.Time `xorm:"'created'"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "user_mfa_recovery_code"
+}
+
+// WebAuthnCredential is a security key registered by a user.
+type WebAuthnCredential struct {
+	ID     int64 `json:"-" xorm:"pk autoincr 'id'"`
+	UserID int64 `json:"-" xorm:"user_id"`
+	// CredentialID is the base64url encoded id chosen by the authenticator
+	CredentialID string `json:"credentialId" xorm:"credential_id"`
+	Name         string `json:"name" xorm:"name"`
+	// PublicKey is the base64 encoded COSE key
+	PublicKey string `json:"-" xorm:"public_key"`
+	SignCount int64  `json:"-" xorm:"sign_count"`
+	// BackupEligible is set for keys that can be synced between devices, like passkeys, it cannot change
+	BackupEligible bool       `json:"-" xorm:"backup_eligible"`
+	Created        time.Time  `json:"created" xorm:"'created'"`
+	LastUsed       *time.Time `json:"lastUsed,omitempty" xorm:"last_used"`
+}
+
+func (WebAuthnCredential) TableName() string {
+	return "user_mfa_webauthn"
+}
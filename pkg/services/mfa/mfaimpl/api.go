@@ -0,0 +1,210 @@
+package mfaimpl
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type mfaAPI struct {
+	service       *Service
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+}
+
+func newAPI(service *Service, accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *mfaAPI {
+	return &mfaAPI{
+		service:       service,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+	}
+}
+
+func (api *mfaAPI) registerAPIEndpoints() {
+	authorize := accesscontrol.Middleware(api.accessControl)
+
+	api.routeRegister.Group("/api/user/mfa", func(router routing.RouteRegister) {
+		router.Get("/", routing.Wrap(api.getStatus))
+		router.Post("/totp/enroll", routing.Wrap(api.enrollTOTP))
+		router.Post("/totp/confirm", routing.Wrap(api.confirmTOTP))
+		router.Post("/totp/disable", routing.Wrap(api.disableTOTP))
+		router.Post("/recovery-codes", routing.Wrap(api.regenerateRecoveryCodes))
+		router.Post("/webauthn/register/begin", routing.Wrap(api.beginWebAuthnRegistration))
+		router.Post("/webauthn/register/finish", routing.Wrap(api.finishWebAuthnRegistration))
+		router.Delete("/webauthn/:credentialId", routing.Wrap(api.deleteWebAuthn))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	userIDScope := accesscontrol.Scope("global.users", "id", accesscontrol.Parameter(":id"))
+	api.routeRegister.Delete("/api/admin/users/:id/mfa", authorize(accesscontrol.EvalPermission(mfa.ActionReset, userIDScope)), routing.Wrap(api.resetUser))
+
+	api.routeRegister.Group("/api/org/mfa", func(router routing.RouteRegister) {
+		router.Get("/", authorize(accesscontrol.EvalPermission(mfa.ActionPolicyRead)), routing.Wrap(api.getOrgPolicy))
+		router.Put("/", authorize(accesscontrol.EvalPermission(mfa.ActionPolicyWrite)), routing.Wrap(api.updateOrgPolicy))
+	})
+}
+
+type codeForm struct {
+	Code string `json:"code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+func (api *mfaAPI) getStatus(c *contextmodel.ReqContext) response.Response {
+	status, err := api.service.GetStatus(c.Req.Context(), &mfa.GetStatusQuery{
+		UserID:         c.UserID,
+		OrgID:          c.OrgID,
+		OrgRole:        c.OrgRole,
+		IsGrafanaAdmin: c.IsGrafanaAdmin,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+func (api *mfaAPI) enrollTOTP(c *contextmodel.ReqContext) response.Response {
+	enrollment, err := api.service.beginTOTP(c.Req.Context(), c.UserID, c.Login)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set up TOTP", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+func (api *mfaAPI) confirmTOTP(c *contextmodel.ReqContext) response.Response {
+	form := codeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	codes, err := api.service.confirmTOTP(c.Req.Context(), c.UserID, form.Code)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to confirm TOTP", err)
+	}
+	return response.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (api *mfaAPI) disableTOTP(c *contextmodel.ReqContext) response.Response {
+	form := codeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := api.service.disableTOTP(c.Req.Context(), c.UserID, form.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable TOTP", err)
+	}
+	return response.Success("TOTP disabled")
+}
+
+func (api *mfaAPI) regenerateRecoveryCodes(c *contextmodel.ReqContext) response.Response {
+	status, err := api.service.GetStatus(c.Req.Context(), &mfa.GetStatusQuery{UserID: c.UserID})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication status", err)
+	}
+	if !status.Enrolled() {
+		return response.Err(mfa.ErrNotEnrolled.Errorf("user %d has no second factor", c.UserID))
+	}
+	codes, err := api.service.generateRecoveryCodes(c.Req.Context(), c.UserID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to generate recovery codes", err)
+	}
+	return response.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (api *mfaAPI) beginWebAuthnRegistration(c *contextmodel.ReqContext) response.Response {
+	options, err := api.service.beginWebAuthnRegistration(c.Req.Context(), c.UserID, c.UserUID, c.Login, c.Name)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin security key registration", err)
+	}
+	return response.JSON(http.StatusOK, options)
+}
+
+type finishWebAuthnRegistrationForm struct {
+	Name       string              `json:"name"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+type finishWebAuthnRegistrationResponse struct {
+	Credential *mfa.WebAuthnCredential `json:"credential"`
+	// RecoveryCodes are only returned when the key is the first second factor of the user
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+}
+
+func (api *mfaAPI) finishWebAuthnRegistration(c *contextmodel.ReqContext) response.Response {
+	form := finishWebAuthnRegistrationForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if form.Name == "" {
+		form.Name = "Security key"
+	}
+
+	ctx := c.Req.Context()
+	cred, err := api.service.finishWebAuthnRegistration(ctx, c.UserID, c.UserUID, form.Name, form.Credential)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to register security key", err)
+	}
+
+	res := finishWebAuthnRegistrationResponse{Credential: cred}
+	status, err := api.service.GetStatus(ctx, &mfa.GetStatusQuery{UserID: c.UserID})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication status", err)
+	}
+	if status.RecoveryCodes == 0 {
+		if res.RecoveryCodes, err = api.service.generateRecoveryCodes(ctx, c.UserID); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to generate recovery codes", err)
+		}
+	}
+	return response.JSON(http.StatusOK, res)
+}
+
+func (api *mfaAPI) deleteWebAuthn(c *contextmodel.ReqContext) response.Response {
+	if err := api.service.deleteWebAuthn(c.Req.Context(), c.UserID, web.Params(c.Req)[":credentialId"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete security key", err)
+	}
+	return response.Success("Security key deleted")
+}
+
+func (api *mfaAPI) resetUser(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if err := api.service.Reset(c.Req.Context(), userID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to reset multi-factor authentication", err)
+	}
+	return response.Success("Multi-factor authentication reset")
+}
+
+func (api *mfaAPI) getOrgPolicy(c *contextmodel.ReqContext) response.Response {
+	policy, err := api.service.GetOrgPolicy(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+type updateOrgPolicyForm struct {
+	Required bool           `json:"required"`
+	Roles    []org.RoleType `json:"roles"`
+}
+
+func (api *mfaAPI) updateOrgPolicy(c *contextmodel.ReqContext) response.Response {
+	form := updateOrgPolicyForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	policy := &mfa.OrgPolicy{OrgID: c.OrgID, Required: form.Required, Roles: form.Roles}
+	if err := api.service.SetOrgPolicy(c.Req.Context(), policy); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update multi-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
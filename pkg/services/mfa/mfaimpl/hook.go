@@ -0,0 +1,113 @@
+package mfaimpl
+
+import (
+	"context"
+	"errors"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// hook asks users that have set up a second factor for it when they log in with their Grafana password.
+func (s *Service) hook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	if !id.IsIdentityType(claims.TypeUser) || id.AuthenticatedBy != login.PasswordAuthModule {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, "mfa.hook")
+	defer span.End()
+
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return err
+	}
+
+	// Requests authenticated with a password that are not logins use basic auth, which cannot carry a second factor
+	if r.GetMeta(authn.MetaKeyIsLogin) != "true" {
+		if s.cfg.AuthMFA.AllowBasicAuth {
+			return nil
+		}
+		status, err := s.cachedStatus(ctx, &mfa.GetStatusQuery{UserID: userID})
+		if err != nil {
+			return err
+		}
+		if status.Enrolled() {
+			return mfa.ErrBasicAuthNotAllowed.Errorf("user %d has a second factor", userID)
+		}
+		return nil
+	}
+
+	status, err := s.GetStatus(ctx, &mfa.GetStatusQuery{UserID: userID})
+	if err != nil {
+		return err
+	}
+	// Users that are required to set up a second factor are asked to by the middleware once logged in
+	if !status.Enrolled() {
+		return nil
+	}
+
+	code := r.GetMeta(authn.MetaKeyMFACode)
+	recoveryCode := r.GetMeta(authn.MetaKeyMFARecoveryCode)
+	assertion := r.GetMeta(authn.MetaKeyMFAWebAuthn)
+
+	if code == "" && recoveryCode == "" && assertion == "" {
+		return s.requireFactor(ctx, userID, id.UID, status)
+	}
+
+	if err := s.verifyFactor(ctx, userID, id.UID, status, code, recoveryCode, assertion); err != nil {
+		if username := r.GetMeta(authn.MetaKeyUsername); username != "" {
+			_ = s.loginAttempts.Add(ctx, username, web.RemoteAddr(r.HTTPRequest))
+		}
+		return mfa.ErrInvalidFactor.Errorf("failed to verify second factor of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// requireFactor returns the error telling the client which second factors it can submit with the next login.
+func (s *Service) requireFactor(ctx context.Context, userID int64, userUID string, status *mfa.Status) error {
+	methods := make([]string, 0, 3)
+	public := map[string]any{}
+	if status.TOTP {
+		methods = append(methods, mfa.MethodTOTP)
+	}
+	if len(status.WebAuthn) > 0 {
+		options, err := s.beginWebAuthnLogin(ctx, userID, userUID, status.WebAuthn)
+		if err != nil {
+			return err
+		}
+		methods = append(methods, mfa.MethodWebAuthn)
+		public["webauthn"] = options
+	}
+	if status.RecoveryCodes > 0 {
+		methods = append(methods, mfa.MethodRecoveryCode)
+	}
+	public["methods"] = methods
+
+	return mfa.ErrMFARequired.Build(errutil.TemplateData{Public: public})
+}
+
+func (s *Service) verifyFactor(ctx context.Context, userID int64, userUID string, status *mfa.Status, code, recoveryCode, assertion string) error {
+	switch {
+	case code != "":
+		if !status.TOTP {
+			return errors.New("TOTP is not set up")
+		}
+		totp, err := s.store.GetTOTP(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return s.useTOTP(ctx, totp, code, false)
+	case recoveryCode != "":
+		return s.useRecoveryCode(ctx, userID, recoveryCode)
+	default:
+		if len(status.WebAuthn) == 0 {
+			return errors.New("no security key is registered")
+		}
+		return s.useWebAuthn(ctx, userID, userUID, status.WebAuthn, []byte(assertion))
+	}
+}
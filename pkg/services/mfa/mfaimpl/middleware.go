@@ -0,0 +1,74 @@
+package mfaimpl
+
+import (
+	"net/http"
+	"strings"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// enrollmentPaths can be used by users that still have to set up a second factor
+var enrollmentPaths = []string{
+	"/api/user/mfa",
+	"/api/frontend/settings",
+	"/api/login/ping",
+}
+
+// Middleware blocks API calls of local users that are required to set up a second factor but have not done so yet.
+func (s *Service) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqCtx := contexthandler.FromContext(r.Context())
+			if reqCtx == nil || !s.mustEnroll(reqCtx) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			status, err := s.cachedStatus(reqCtx.Req.Context(), &mfa.GetStatusQuery{
+				UserID:         reqCtx.UserID,
+				OrgID:          reqCtx.OrgID,
+				OrgRole:        reqCtx.OrgRole,
+				IsGrafanaAdmin: reqCtx.IsGrafanaAdmin,
+			})
+			if err != nil {
+				reqCtx.Logger.Error("Failed to get multi-factor authentication status", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if status.Required && !status.Enrolled() {
+				reqCtx.WriteErr(mfa.ErrEnrollmentRequired.Errorf("user %d has to set up a second factor", reqCtx.UserID))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *Service) mustEnroll(c *contextmodel.ReqContext) bool {
+	if !c.IsSignedIn || c.SignedInUser == nil || !c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		return false
+	}
+	// Sessions of local users have no auth module, users of other providers get their second factor from them
+	if c.SignedInUser.AuthenticatedBy != "" && c.SignedInUser.AuthenticatedBy != login.PasswordAuthModule {
+		return false
+	}
+	path := c.Req.URL.Path
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	if path == "/api/user" && c.Req.Method == http.MethodGet {
+		return false
+	}
+	for _, prefix := range enrollmentPaths {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
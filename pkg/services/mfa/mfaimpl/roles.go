@@ -0,0 +1,57 @@
+package mfaimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var (
+	policyReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:mfa.policy:reader",
+		DisplayName: "MFA policy reader",
+		Description: "Read the multi-factor authentication policy of the organization.",
+		Group:       "Multi-factor authentication",
+		Permissions: []accesscontrol.Permission{
+			{Action: mfa.ActionPolicyRead},
+		},
+	}
+
+	policyWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:mfa.policy:writer",
+		DisplayName: "MFA policy writer",
+		Description: "Read and update the multi-factor authentication policy of the organization.",
+		Group:       "Multi-factor authentication",
+		Permissions: []accesscontrol.Permission{
+			{Action: mfa.ActionPolicyRead},
+			{Action: mfa.ActionPolicyWrite},
+		},
+	}
+
+	resetterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:users.mfa:resetter",
+		DisplayName: "MFA resetter",
+		Description: "Remove the second factors of any user.",
+		Group:       "Multi-factor authentication",
+		Permissions: []accesscontrol.Permission{
+			{Action: mfa.ActionReset, Scope: accesscontrol.ScopeGlobalUsersAll},
+		},
+	}
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	return service.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{
+			Role:   policyReaderRole,
+			Grants: []string{string(org.RoleAdmin)},
+		},
+		accesscontrol.RoleRegistration{
+			Role:   policyWriterRole,
+			Grants: []string{string(org.RoleAdmin)},
+		},
+		accesscontrol.RoleRegistration{
+			Role:   resetterRole,
+			Grants: []string{accesscontrol.RoleGrafanaAdmin},
+		},
+	)
+}
@@ -0,0 +1,526 @@
+package mfaimpl
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.opentelemetry.io/otel"
+
+	"github.com/grafana/grafana/pkg/api"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/mfa/mfaimpl")
+
+const (
+	// hookPriority runs the hook once the user has been fetched and before permissions are synced
+	hookPriority = 105
+	// statusCacheTTL bounds how long a change of enrollment or policy takes to apply on other instances
+	statusCacheTTL = time.Minute
+
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+var _ mfa.Service = (*Service)(nil)
+
+type Service struct {
+	cfg           *setting.Cfg
+	log           log.Logger
+	store         store
+	secrets       secrets.Service
+	cache         remotecache.CacheStorage
+	statusCache   *localcache.CacheService
+	loginAttempts loginattempt.Service
+	webAuthn      *webauthn.WebAuthn
+	now           func() time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	sqlStore db.DB,
+	secretsService secrets.Service,
+	cache remotecache.CacheStorage,
+	authnService authn.Service,
+	loginAttempts loginattempt.Service,
+	httpServer *api.HTTPServer,
+	routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl,
+	accessControlService accesscontrol.Service,
+) (*Service, error) {
+	s := &Service{
+		cfg:           cfg,
+		log:           log.New("mfa"),
+		store:         &dbStore{db: sqlStore},
+		secrets:       secretsService,
+		cache:         cache,
+		statusCache:   localcache.New(statusCacheTTL, 2*statusCacheTTL),
+		loginAttempts: loginAttempts,
+		now:           time.Now,
+	}
+
+	if !cfg.AuthMFA.Enabled {
+		return s, nil
+	}
+
+	if err := s.initWebAuthn(); err != nil {
+		return nil, err
+	}
+	if err := declareFixedRoles(accessControlService); err != nil {
+		return nil, err
+	}
+
+	authnService.RegisterPostAuthHook(s.hook, hookPriority)
+	httpServer.AddMiddleware(s.Middleware())
+	newAPI(s, accessControl, routeRegister).registerAPIEndpoints()
+
+	return s, nil
+}
+
+// initWebAuthn defaults the relying party to the host of root_url, security keys are bound to it.
+func (s *Service) initWebAuthn() error {
+	if !s.cfg.AuthMFA.WebAuthnEnabled {
+		return nil
+	}
+	appURL, err := url.Parse(s.cfg.AppURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse root_url for webauthn: %w", err)
+	}
+	rpID := s.cfg.AuthMFA.WebAuthnRPID
+	if rpID == "" {
+		rpID = appURL.Hostname()
+	}
+	origin := strings.TrimSuffix(s.cfg.AuthMFA.WebAuthnOrigin, "/")
+	if origin == "" {
+		origin = appURL.Scheme + "://" + appURL.Host
+	}
+	s.webAuthn, err = newWebAuthn(rpID, s.cfg.AuthMFA.Issuer, origin, s.cfg.AuthMFA.ChallengeExpiration)
+	if err != nil {
+		return fmt.Errorf("failed to configure webauthn: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) GetStatus(ctx context.Context, query *mfa.GetStatusQuery) (*mfa.Status, error) {
+	ctx, span := tracer.Start(ctx, "mfa.GetStatus")
+	defer span.End()
+
+	status := &mfa.Status{WebAuthn: []*mfa.WebAuthnCredential{}}
+
+	totp, err := s.store.GetTOTP(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	status.TOTP = totp != nil && totp.Confirmed
+
+	codes, err := s.store.GetRecoveryCodes(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	status.RecoveryCodes = len(codes)
+
+	if s.cfg.AuthMFA.WebAuthnEnabled {
+		if status.WebAuthn, err = s.store.ListWebAuthn(ctx, query.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	status.Required, err = s.isRequired(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (s *Service) isRequired(ctx context.Context, query *mfa.GetStatusQuery) (bool, error) {
+	if s.cfg.AuthMFA.RequireServerAdmins && query.IsGrafanaAdmin {
+		return true, nil
+	}
+	if query.OrgID == 0 {
+		return false, nil
+	}
+	policy, err := s.store.GetOrgPolicy(ctx, query.OrgID)
+	if err != nil {
+		return false, err
+	}
+	return policy.Applies(query.OrgRole), nil
+}
+
+// cachedStatus is used on every request, so it trades freshness for not hitting the database.
+func (s *Service) cachedStatus(ctx context.Context, query *mfa.GetStatusQuery) (*mfa.Status, error) {
+	key := fmt.Sprintf("%s%d-%s-%t", statusCacheKeyPrefix(query.UserID), query.OrgID, query.OrgRole, query.IsGrafanaAdmin)
+	if cached, ok := s.statusCache.Get(key); ok {
+		return cached.(*mfa.Status), nil
+	}
+	status, err := s.GetStatus(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.statusCache.SetDefault(key, status)
+	return status, nil
+}
+
+func statusCacheKeyPrefix(userID int64) string {
+	return fmt.Sprintf("mfa-status-%d-", userID)
+}
+
+func (s *Service) invalidateStatus(userID int64) {
+	prefix := statusCacheKeyPrefix(userID)
+	for key := range s.statusCache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			s.statusCache.Delete(key)
+		}
+	}
+}
+
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	ctx, span := tracer.Start(ctx, "mfa.Reset")
+	defer span.End()
+
+	if err := s.store.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+	s.invalidateStatus(userID)
+	return nil
+}
+
+func (s *Service) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	ctx, span := tracer.Start(ctx, "mfa.GetOrgPolicy")
+	defer span.End()
+
+	return s.store.GetOrgPolicy(ctx, orgID)
+}
+
+func (s *Service) SetOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error {
+	ctx, span := tracer.Start(ctx, "mfa.SetOrgPolicy")
+	defer span.End()
+
+	for _, role := range policy.Roles {
+		if !role.IsValid() {
+			return mfa.ErrInvalidPolicy.Errorf("invalid role %q", role)
+		}
+	}
+	if err := s.store.SetOrgPolicy(ctx, policy); err != nil {
+		return err
+	}
+	s.statusCache.Flush()
+	return nil
+}
+
+type totpEnrollment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+// beginTOTP creates a new secret that has to be confirmed with a code before it is used for logins.
+func (s *Service) beginTOTP(ctx context.Context, userID int64, account string) (*totpEnrollment, error) {
+	existing, err := s.store.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Confirmed {
+		return nil, mfa.ErrAlreadyEnrolled.Errorf("user already has a confirmed TOTP secret")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SaveTOTP(ctx, &mfa.TOTP{UserID: userID, Secret: string(encrypted)}); err != nil {
+		return nil, err
+	}
+	return &totpEnrollment{Secret: secret, URL: totpURL(s.cfg.AuthMFA.Issuer, account, secret)}, nil
+}
+
+// confirmTOTP activates the pending secret and returns a fresh set of recovery codes.
+func (s *Service) confirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	totp, err := s.store.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil || totp.Confirmed {
+		return nil, mfa.ErrNotEnrolled.Errorf("no pending TOTP secret")
+	}
+	if err := s.useTOTP(ctx, totp, code, true); err != nil {
+		return nil, mfa.ErrInvalidCode.Errorf("failed to confirm TOTP: %w", err)
+	}
+	s.invalidateStatus(userID)
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+func (s *Service) useTOTP(ctx context.Context, totp *mfa.TOTP, code string, confirm bool) error {
+	secret, err := s.secrets.Decrypt(ctx, []byte(totp.Secret))
+	if err != nil {
+		return err
+	}
+	step, ok := validateTOTP(string(secret), code, s.now(), totp.LastStep)
+	if !ok {
+		return errors.New("invalid TOTP code")
+	}
+	ok, err = s.store.UseTOTPStep(ctx, totp.UserID, step, confirm)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("TOTP code already used")
+	}
+	return nil
+}
+
+// disableTOTP removes the secret after checking a code, recovery codes are removed with the last factor.
+func (s *Service) disableTOTP(ctx context.Context, userID int64, code string) error {
+	totp, err := s.store.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if totp == nil || !totp.Confirmed {
+		return mfa.ErrNotEnrolled.Errorf("no confirmed TOTP secret")
+	}
+	if err := s.useTOTP(ctx, totp, code, false); err != nil {
+		if err := s.useRecoveryCode(ctx, userID, code); err != nil {
+			return mfa.ErrInvalidCode.Errorf("failed to verify code: %w", err)
+		}
+	}
+	if err := s.store.DeleteTOTP(ctx, userID); err != nil {
+		return err
+	}
+	defer s.invalidateStatus(userID)
+	return s.deleteRecoveryCodesIfUnused(ctx, userID)
+}
+
+func (s *Service) deleteRecoveryCodesIfUnused(ctx context.Context, userID int64) error {
+	creds, err := s.store.ListWebAuthn(ctx, userID)
+	if err != nil {
+		return err
+	}
+	totp, err := s.store.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(creds) > 0 || (totp != nil && totp.Confirmed) {
+		return nil
+	}
+	return s.store.ReplaceRecoveryCodes(ctx, userID, nil)
+}
+
+// generateRecoveryCodes replaces the recovery codes of the user, only their hashes are stored.
+func (s *Service) generateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	plain := make([]string, 0, s.cfg.AuthMFA.RecoveryCodes)
+	codes := make([]*mfa.RecoveryCode, 0, s.cfg.AuthMFA.RecoveryCodes)
+	for i := 0; i < s.cfg.AuthMFA.RecoveryCodes; i++ {
+		code, err := util.GetRandomString(recoveryCodeLength, []byte(recoveryCodeAlphabet)...)
+		if err != nil {
+			return nil, err
+		}
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := util.EncodePassword(code, salt)
+		if err != nil {
+			return nil, err
+		}
+		plain = append(plain, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		codes = append(codes, &mfa.RecoveryCode{UserID: userID, CodeHash: hash, Salt: salt, Created: s.now()})
+	}
+	if err := s.store.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+	s.invalidateStatus(userID)
+	return plain, nil
+}
+
+func (s *Service) useRecoveryCode(ctx context.Context, userID int64, code string) error {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != recoveryCodeLength {
+		return errors.New("invalid recovery code")
+	}
+	codes, err := s.store.GetRecoveryCodes(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, rc := range codes {
+		hash, err := util.EncodePassword(code, rc.Salt)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(rc.CodeHash)) != 1 {
+			continue
+		}
+		// Deleting the code makes it single use, even with concurrent logins
+		ok, err := s.store.DeleteRecoveryCode(ctx, rc.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("recovery code already used")
+		}
+		s.invalidateStatus(userID)
+		return nil
+	}
+	return errors.New("invalid recovery code")
+}
+
+func (s *Service) saveSession(ctx context.Context, key string, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, key, data, s.cfg.AuthMFA.ChallengeExpiration)
+}
+
+// takeSession returns the pending ceremony and removes it, so every challenge is only used once.
+func (s *Service) takeSession(ctx context.Context, key string) (*webauthn.SessionData, error) {
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, errors.New("no pending challenge")
+		}
+		return nil, err
+	}
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func registrationChallengeKey(userID int64) string {
+	return fmt.Sprintf("mfa-webauthn-register-%d", userID)
+}
+
+func loginChallengeKey(userID int64) string {
+	return fmt.Sprintf("mfa-webauthn-login-%d", userID)
+}
+
+func (s *Service) beginWebAuthnRegistration(ctx context.Context, userID int64, userUID, login, name string) (*protocol.PublicKeyCredentialCreationOptions, error) {
+	if !s.cfg.AuthMFA.WebAuthnEnabled {
+		return nil, mfa.ErrWebAuthnDisabled.Errorf("webauthn is disabled")
+	}
+	creds, err := s.store.ListWebAuthn(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = login
+	}
+	user, err := newWebAuthnUser(userUID, login, name, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, cred := range user.credentials {
+		exclusions = append(exclusions, cred.Descriptor())
+	}
+	creation, session, err := s.webAuthn.BeginRegistration(user, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveSession(ctx, registrationChallengeKey(userID), session); err != nil {
+		return nil, err
+	}
+	return &creation.Response, nil
+}
+
+func (s *Service) finishWebAuthnRegistration(ctx context.Context, userID int64, userUID, name string, resp []byte) (*mfa.WebAuthnCredential, error) {
+	if !s.cfg.AuthMFA.WebAuthnEnabled {
+		return nil, mfa.ErrWebAuthnDisabled.Errorf("webauthn is disabled")
+	}
+	session, err := s.takeSession(ctx, registrationChallengeKey(userID))
+	if err != nil {
+		return nil, mfa.ErrInvalidCredential.Errorf("failed to register security key: %w", err)
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(resp)
+	if err != nil {
+		return nil, mfa.ErrInvalidCredential.Errorf("failed to register security key: %w", err)
+	}
+	registered, err := s.webAuthn.CreateCredential(&webAuthnUser{uid: userUID}, *session, parsed)
+	if err != nil {
+		return nil, mfa.ErrInvalidCredential.Errorf("failed to register security key: %w", err)
+	}
+
+	cred := fromWebAuthnCredential(userID, name, registered)
+	if err := s.store.InsertWebAuthn(ctx, cred); err != nil {
+		return nil, err
+	}
+	s.invalidateStatus(userID)
+	return cred, nil
+}
+
+func (s *Service) deleteWebAuthn(ctx context.Context, userID int64, credentialID string) error {
+	ok, err := s.store.DeleteWebAuthn(ctx, userID, credentialID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return mfa.ErrCredentialNotFound.Errorf("credential not found")
+	}
+	defer s.invalidateStatus(userID)
+	return s.deleteRecoveryCodesIfUnused(ctx, userID)
+}
+
+// beginWebAuthnLogin returns the options to sign the login challenge with one of the user's keys.
+func (s *Service) beginWebAuthnLogin(ctx context.Context, userID int64, userUID string, creds []*mfa.WebAuthnCredential) (*protocol.PublicKeyCredentialRequestOptions, error) {
+	user, err := newWebAuthnUser(userUID, "", "", creds)
+	if err != nil {
+		return nil, err
+	}
+	assertion, session, err := s.webAuthn.BeginLogin(user, webauthn.WithUserVerification(protocol.VerificationDiscouraged))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveSession(ctx, loginChallengeKey(userID), session); err != nil {
+		return nil, err
+	}
+	return &assertion.Response, nil
+}
+
+func (s *Service) useWebAuthn(ctx context.Context, userID int64, userUID string, creds []*mfa.WebAuthnCredential, resp []byte) error {
+	session, err := s.takeSession(ctx, loginChallengeKey(userID))
+	if err != nil {
+		return err
+	}
+	user, err := newWebAuthnUser(userUID, "", "", creds)
+	if err != nil {
+		return err
+	}
+	used, err := verifyAssertion(s.webAuthn, user, session, resp)
+	if err != nil {
+		return err
+	}
+	credentialID := base64.RawURLEncoding.EncodeToString(used.ID)
+	for _, cred := range creds {
+		if cred.CredentialID == credentialID {
+			return s.store.UpdateWebAuthnUsage(ctx, cred.ID, int64(used.Authenticator.SignCount))
+		}
+	}
+	return errors.New("unknown credential")
+}
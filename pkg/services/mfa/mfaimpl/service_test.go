@@ -0,0 +1,241 @@
+package mfaimpl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func setupTestService(t *testing.T) (*Service, *loginattempttest.MockLoginAttemptService) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = testOrigin + "/"
+	cfg.AuthMFA = setting.AuthMFASettings{
+		Enabled:             true,
+		Issuer:              "Grafana",
+		RecoveryCodes:       3,
+		WebAuthnEnabled:     true,
+		ChallengeExpiration: time.Minute,
+	}
+	loginAttempts := &loginattempttest.MockLoginAttemptService{}
+	s := &Service{
+		cfg:           cfg,
+		log:           log.NewNopLogger(),
+		store:         &dbStore{db: db.InitTestDB(t)},
+		secrets:       fakes.NewFakeSecretsService(),
+		cache:         remotecache.NewFakeCacheStorage(),
+		statusCache:   localcache.New(statusCacheTTL, 2*statusCacheTTL),
+		loginAttempts: loginAttempts,
+		now:           time.Now,
+	}
+	require.NoError(t, s.initWebAuthn())
+	return s, loginAttempts
+}
+
+func loginRequest(meta map[string]string) *authn.Request {
+	r := &authn.Request{HTTPRequest: httptest.NewRequest(http.MethodPost, "/login", nil)}
+	r.SetMeta(authn.MetaKeyIsLogin, "true")
+	r.SetMeta(authn.MetaKeyUsername, "admin")
+	for k, v := range meta {
+		r.SetMeta(k, v)
+	}
+	return r
+}
+
+func passwordIdentity(userID string) *authn.Identity {
+	return &authn.Identity{ID: userID, Type: claims.TypeUser, AuthenticatedBy: login.PasswordAuthModule}
+}
+
+// enrollTOTP sets up TOTP for the user and returns a function generating the code of a time step.
+func enrollTOTP(t *testing.T, s *Service, userID int64) (func(step int64) string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	enrollment, err := s.beginTOTP(ctx, userID, "admin")
+	require.NoError(t, err)
+	key, err := totpEncoding.DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+	code := func(step int64) string { return hotp(key, step) }
+
+	recoveryCodes, err := s.confirmTOTP(ctx, userID, code(totpStep(s.now())))
+	require.NoError(t, err)
+	return code, recoveryCodes
+}
+
+func TestIntegrationHook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should ignore users without a second factor", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		require.NoError(t, s.hook(ctx, passwordIdentity("1"), loginRequest(nil)))
+	})
+
+	t.Run("should ignore identities not authenticated with a Grafana password", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		enrollTOTP(t, s, 1)
+		id := passwordIdentity("1")
+		id.AuthenticatedBy = login.LDAPAuthModule
+		require.NoError(t, s.hook(ctx, id, loginRequest(nil)))
+	})
+
+	t.Run("should require a second factor from enrolled users", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		enrollTOTP(t, s, 1)
+
+		err := s.hook(ctx, passwordIdentity("1"), loginRequest(nil))
+		require.ErrorIs(t, err, mfa.ErrMFARequired)
+		var grafanaErr errutil.Error
+		require.True(t, errors.As(err, &grafanaErr))
+		assert.Equal(t, []string{mfa.MethodTOTP, mfa.MethodRecoveryCode}, grafanaErr.PublicPayload["methods"])
+	})
+
+	t.Run("should accept a TOTP code only once", func(t *testing.T) {
+		s, loginAttempts := setupTestService(t)
+		now := time.Now()
+		s.now = func() time.Time { return now.Add(-totpPeriod * time.Second) }
+		code, _ := enrollTOTP(t, s, 1)
+		s.now = func() time.Time { return now }
+
+		valid := code(totpStep(now))
+		require.NoError(t, s.hook(ctx, passwordIdentity("1"), loginRequest(map[string]string{authn.MetaKeyMFACode: valid})))
+		assert.False(t, loginAttempts.AddCalled)
+
+		err := s.hook(ctx, passwordIdentity("1"), loginRequest(map[string]string{authn.MetaKeyMFACode: valid}))
+		require.ErrorIs(t, err, mfa.ErrInvalidFactor)
+		assert.True(t, loginAttempts.AddCalled)
+	})
+
+	t.Run("should accept a recovery code only once", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		_, recoveryCodes := enrollTOTP(t, s, 1)
+		require.Len(t, recoveryCodes, 3)
+
+		req := loginRequest(map[string]string{authn.MetaKeyMFARecoveryCode: recoveryCodes[1]})
+		require.NoError(t, s.hook(ctx, passwordIdentity("1"), req))
+		err := s.hook(ctx, passwordIdentity("1"), loginRequest(map[string]string{authn.MetaKeyMFARecoveryCode: recoveryCodes[1]}))
+		require.ErrorIs(t, err, mfa.ErrInvalidFactor)
+
+		status, err := s.GetStatus(ctx, &mfa.GetStatusQuery{UserID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, status.RecoveryCodes)
+	})
+
+	t.Run("should accept a security key", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		authenticator := newFakeAuthenticator(t)
+		options, err := s.beginWebAuthnRegistration(ctx, 1, "uid", "admin", "Admin")
+		require.NoError(t, err)
+		_, err = s.finishWebAuthnRegistration(ctx, 1, "uid", "key", authenticator.create(testRPID, testOrigin, options.Challenge.String()))
+		require.NoError(t, err)
+
+		err = s.hook(ctx, passwordIdentity("1"), loginRequest(nil))
+		var grafanaErr errutil.Error
+		require.True(t, errors.As(err, &grafanaErr))
+		loginOptions, ok := grafanaErr.PublicPayload["webauthn"].(*protocol.PublicKeyCredentialRequestOptions)
+		require.True(t, ok)
+		require.Len(t, loginOptions.AllowedCredentials, 1)
+
+		assertion := authenticator.get(testRPID, testOrigin, loginOptions.Challenge.String())
+		require.NoError(t, s.hook(ctx, passwordIdentity("1"), loginRequest(map[string]string{authn.MetaKeyMFAWebAuthn: string(assertion)})))
+
+		// The challenge is single use
+		err = s.hook(ctx, passwordIdentity("1"), loginRequest(map[string]string{authn.MetaKeyMFAWebAuthn: string(assertion)}))
+		require.ErrorIs(t, err, mfa.ErrInvalidFactor)
+	})
+
+	t.Run("should reject basic auth of enrolled users", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		basic := &authn.Request{HTTPRequest: httptest.NewRequest(http.MethodGet, "/api/dashboards", nil)}
+		require.NoError(t, s.hook(ctx, passwordIdentity("1"), basic))
+
+		enrollTOTP(t, s, 1)
+		require.ErrorIs(t, s.hook(ctx, passwordIdentity("1"), basic), mfa.ErrBasicAuthNotAllowed)
+
+		s.cfg.AuthMFA.AllowBasicAuth = true
+		require.NoError(t, s.hook(ctx, passwordIdentity("1"), basic))
+	})
+
+	t.Run("should remove every factor on reset", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		enrollTOTP(t, s, 1)
+		require.NoError(t, s.Reset(ctx, 1))
+
+		status, err := s.GetStatus(ctx, &mfa.GetStatusQuery{UserID: 1})
+		require.NoError(t, err)
+		assert.False(t, status.Enrolled())
+		assert.Zero(t, status.RecoveryCodes)
+		require.NoError(t, s.hook(ctx, passwordIdentity("1"), loginRequest(nil)))
+	})
+}
+
+func TestIntegrationMiddleware(t *testing.T) {
+	s, _ := setupTestService(t)
+	ctx := context.Background()
+
+	routes := routing.NewRouteRegister()
+	ok := routing.Wrap(func(c *contextmodel.ReqContext) response.Response { return response.Success("ok") })
+	routes.Get("/api/dashboards/uid/:uid", ok)
+	routes.Get("/api/user", ok)
+	routes.Post("/api/user/mfa/totp/enroll", ok)
+	server := webtest.NewServer(t, routes)
+	server.Mux.UseMiddleware(s.Middleware())
+
+	do := func(method, url string, usr *user.SignedInUser) int {
+		req := server.NewRequest(method, url, nil)
+		webtest.RequestWithSignedInUser(req, usr)
+		rec := httptest.NewRecorder()
+		server.Mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	editor := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleEditor}
+	admin := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleAdmin}
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/dashboards/uid/abc", admin))
+
+	require.NoError(t, s.SetOrgPolicy(ctx, &mfa.OrgPolicy{OrgID: 1, Required: true, Roles: []org.RoleType{org.RoleAdmin}}))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/dashboards/uid/abc", admin))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/dashboards/uid/abc", editor), "the policy only applies to admins")
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/user", admin))
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/user/mfa/totp/enroll", admin))
+
+	oauthAdmin := *admin
+	oauthAdmin.AuthenticatedBy = login.GenericOAuthModule
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/dashboards/uid/abc", &oauthAdmin), "other providers are responsible for the second factor")
+
+	enrollTOTP(t, s, 2)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/dashboards/uid/abc", admin))
+
+	assert.ErrorIs(t, s.SetOrgPolicy(ctx, &mfa.OrgPolicy{OrgID: 1, Required: true, Roles: []org.RoleType{"Owner"}}), mfa.ErrInvalidPolicy)
+}
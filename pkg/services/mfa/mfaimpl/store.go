@@ -0,0 +1,223 @@
+package mfaimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+type store interface {
+	GetTOTP(ctx context.Context, userID int64) (*mfa.TOTP, error)
+	SaveTOTP(ctx context.Context, totp *mfa.TOTP) error
+	// UseTOTPStep records the time step of a used code, it returns false if a code of the same
+	// or a later step was used in the meantime
+	UseTOTPStep(ctx context.Context, userID, step int64, confirm bool) (bool, error)
+	DeleteTOTP(ctx context.Context, userID int64) error
+
+	GetRecoveryCodes(ctx context.Context, userID int64) ([]*mfa.RecoveryCode, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []*mfa.RecoveryCode) error
+	// DeleteRecoveryCode returns false if the code was already used
+	DeleteRecoveryCode(ctx context.Context, id int64) (bool, error)
+
+	ListWebAuthn(ctx context.Context, userID int64) ([]*mfa.WebAuthnCredential, error)
+	InsertWebAuthn(ctx context.Context, cred *mfa.WebAuthnCredential) error
+	UpdateWebAuthnUsage(ctx context.Context, id int64, signCount int64) error
+	DeleteWebAuthn(ctx context.Context, userID int64, credentialID string) (bool, error)
+
+	// DeleteUser removes every second factor of the user
+	DeleteUser(ctx context.Context, userID int64) error
+
+	GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error)
+	SetOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error
+}
+
+// dbStore keeps the second factors of users and the organization policies.
+type dbStore struct {
+	db db.DB
+}
+
+var _ store = (*dbStore)(nil)
+
+func (s *dbStore) GetTOTP(ctx context.Context, userID int64) (*mfa.TOTP, error) {
+	var totp mfa.TOTP
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("user_id = ?", userID).Get(&totp)
+		return err
+	})
+	if err != nil || !has {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+func (s *dbStore) SaveTOTP(ctx context.Context, totp *mfa.TOTP) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Exec("DELETE FROM user_mfa_totp WHERE user_id = ?", totp.UserID); err != nil {
+				return err
+			}
+			now := time.Now()
+			totp.Created, totp.Updated = now, now
+			_, err := sess.Insert(totp)
+			return err
+		})
+	})
+}
+
+func (s *dbStore) UseTOTPStep(ctx context.Context, userID, step int64, confirm bool) (bool, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := "UPDATE user_mfa_totp SET last_step = ?, updated = ?"
+		args := []any{step, time.Now()}
+		if confirm {
+			sql += ", confirmed = ?"
+			args = append(args, true)
+		}
+		sql += " WHERE user_id = ? AND last_step < ?"
+		args = append(args, userID, step)
+
+		res, err := sess.Exec(append([]any{sql}, args...)...)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected > 0, err
+}
+
+func (s *dbStore) DeleteTOTP(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_mfa_totp WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+func (s *dbStore) GetRecoveryCodes(ctx context.Context, userID int64) ([]*mfa.RecoveryCode, error) {
+	codes := make([]*mfa.RecoveryCode, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Find(&codes)
+	})
+	return codes, err
+}
+
+func (s *dbStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []*mfa.RecoveryCode) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Exec("DELETE FROM user_mfa_recovery_code WHERE user_id = ?", userID); err != nil {
+				return err
+			}
+			for _, code := range codes {
+				if _, err := sess.Insert(code); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (s *dbStore) DeleteRecoveryCode(ctx context.Context, id int64) (bool, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_mfa_recovery_code WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected > 0, err
+}
+
+func (s *dbStore) ListWebAuthn(ctx context.Context, userID int64) ([]*mfa.WebAuthnCredential, error) {
+	creds := make([]*mfa.WebAuthnCredential, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Asc("id").Find(&creds)
+	})
+	return creds, err
+}
+
+func (s *dbStore) InsertWebAuthn(ctx context.Context, cred *mfa.WebAuthnCredential) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		cred.Created = time.Now()
+		_, err := sess.Insert(cred)
+		return err
+	})
+}
+
+func (s *dbStore) UpdateWebAuthnUsage(ctx context.Context, id int64, signCount int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE user_mfa_webauthn SET sign_count = ?, last_used = ? WHERE id = ?", signCount, time.Now(), id)
+		return err
+	})
+}
+
+func (s *dbStore) DeleteWebAuthn(ctx context.Context, userID int64, credentialID string) (bool, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_mfa_webauthn WHERE user_id = ? AND credential_id = ?", userID, credentialID)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected > 0, err
+}
+
+func (s *dbStore) DeleteUser(ctx context.Context, userID int64) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			for _, table := range []string{"user_mfa_totp", "user_mfa_recovery_code", "user_mfa_webauthn"} {
+				if _, err := sess.Exec("DELETE FROM "+table+" WHERE user_id = ?", userID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (s *dbStore) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	policy := mfa.OrgPolicy{OrgID: orgID, Roles: []org.RoleType{}}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ?", orgID).Get(&policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if policy.RolesDB != "" {
+		for _, role := range strings.Split(policy.RolesDB, ",") {
+			policy.Roles = append(policy.Roles, org.RoleType(role))
+		}
+	}
+	return &policy, nil
+}
+
+func (s *dbStore) SetOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error {
+	roles := make([]string, 0, len(policy.Roles))
+	for _, role := range policy.Roles {
+		roles = append(roles, string(role))
+	}
+	policy.RolesDB = strings.Join(roles, ",")
+	policy.Updated = time.Now()
+
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Exec("DELETE FROM org_mfa_policy WHERE org_id = ?", policy.OrgID); err != nil {
+				return err
+			}
+			policy.ID = 0
+			_, err := sess.Insert(policy)
+			return err
+		})
+	})
+}
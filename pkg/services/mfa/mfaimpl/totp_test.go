@@ -0,0 +1,73 @@
+package mfaimpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors of RFC 6238 appendix B for SHA1, truncated to six digits
+var rfcSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestHOTP(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, hotp([]byte("12345678901234567890"), totpStep(time.Unix(tt.unix, 0))))
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	t.Run("should accept the current code", func(t *testing.T) {
+		step, ok := validateTOTP(rfcSecret, "081804", now, 0)
+		require.True(t, ok)
+		assert.Equal(t, totpStep(now), step)
+	})
+
+	t.Run("should accept codes with spaces", func(t *testing.T) {
+		_, ok := validateTOTP(rfcSecret, " 081 804", now, 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("should accept the code of the previous period", func(t *testing.T) {
+		_, ok := validateTOTP(rfcSecret, "081804", now.Add(totpPeriod*time.Second), 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("should reject the code of an older period", func(t *testing.T) {
+		_, ok := validateTOTP(rfcSecret, "081804", now.Add(2*totpPeriod*time.Second), 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject a code that was already used", func(t *testing.T) {
+		_, ok := validateTOTP(rfcSecret, "081804", now, totpStep(now))
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject invalid codes", func(t *testing.T) {
+		for _, code := range []string{"", "123", "081805", "0818044"} {
+			_, ok := validateTOTP(rfcSecret, code, now, 0)
+			assert.False(t, ok, code)
+		}
+	})
+}
+
+func TestTOTPURL(t *testing.T) {
+	assert.Equal(t,
+		"otpauth://totp/Grafana:admin@example.com?algorithm=SHA1&digits=6&issuer=Grafana&period=30&secret=ABC",
+		totpURL("Grafana", "admin@example.com", "ABC"),
+	)
+}
@@ -0,0 +1,114 @@
+package mfaimpl
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+// Security keys are verified with go-webauthn. Attestation is not requested, so the
+// attestation statement of a new credential is not verified.
+
+// newWebAuthn returns the relying party security keys are registered and verified with.
+func newWebAuthn(rpID, rpName, origin string, challengeExpiration time.Duration) (*webauthn.WebAuthn, error) {
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: challengeExpiration, TimeoutUVD: challengeExpiration}
+	return webauthn.New(&webauthn.Config{
+		RPID:                  rpID,
+		RPDisplayName:         rpName,
+		RPOrigins:             []string{origin},
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationDiscouraged,
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+}
+
+// webAuthnUser is the user account the security keys are registered for.
+type webAuthnUser struct {
+	uid         string
+	login       string
+	name        string
+	credentials []webauthn.Credential
+}
+
+func newWebAuthnUser(uid, login, name string, creds []*mfa.WebAuthnCredential) (*webAuthnUser, error) {
+	user := &webAuthnUser{uid: uid, login: login, name: name, credentials: make([]webauthn.Credential, 0, len(creds))}
+	for _, cred := range creds {
+		c, err := toWebAuthnCredential(cred)
+		if err != nil {
+			return nil, err
+		}
+		user.credentials = append(user.credentials, c)
+	}
+	return user, nil
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(u.uid)
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.login
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.name
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func toWebAuthnCredential(cred *mfa.WebAuthnCredential) (webauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(cred.CredentialID)
+	if err != nil {
+		return webauthn.Credential{}, fmt.Errorf("failed to decode credential id: %w", err)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(cred.PublicKey)
+	if err != nil {
+		return webauthn.Credential{}, fmt.Errorf("failed to decode credential public key: %w", err)
+	}
+	return webauthn.Credential{
+		ID:            id,
+		PublicKey:     publicKey,
+		Flags:         webauthn.CredentialFlags{BackupEligible: cred.BackupEligible},
+		Authenticator: webauthn.Authenticator{SignCount: uint32(cred.SignCount)},
+	}, nil
+}
+
+func fromWebAuthnCredential(userID int64, name string, cred *webauthn.Credential) *mfa.WebAuthnCredential {
+	return &mfa.WebAuthnCredential{
+		UserID:         userID,
+		CredentialID:   base64.RawURLEncoding.EncodeToString(cred.ID),
+		Name:           name,
+		PublicKey:      base64.StdEncoding.EncodeToString(cred.PublicKey),
+		SignCount:      int64(cred.Authenticator.SignCount),
+		BackupEligible: cred.Flags.BackupEligible,
+	}
+}
+
+// verifyAssertion checks the response of navigator.credentials.get against the keys of the user
+// and returns the key that signed it, with its new signature counter.
+func verifyAssertion(rp *webauthn.WebAuthn, user *webAuthnUser, session *webauthn.SessionData, raw []byte) (*webauthn.Credential, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := rp.ValidateLogin(user, *session, parsed)
+	if err != nil {
+		return nil, err
+	}
+	// A counter that does not increase points to a cloned authenticator,
+	// authenticators that do not implement it always return 0
+	if cred.Authenticator.CloneWarning {
+		return nil, errors.New("signature counter did not increase")
+	}
+	return cred, nil
+}
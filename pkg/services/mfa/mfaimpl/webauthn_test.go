@@ -0,0 +1,232 @@
+package mfaimpl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+const (
+	testRPID   = "grafana.example.com"
+	testOrigin = "https://grafana.example.com"
+)
+
+const (
+	flagUserPresent            = 0x01
+	flagBackupEligible         = 0x08
+	flagAttestedCredentialData = 0x40
+)
+
+// fakeAuthenticator is a security key with an ES256 key pair.
+type fakeAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+	flags        byte
+}
+
+func newFakeAuthenticator(t *testing.T) *fakeAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &fakeAuthenticator{t: t, key: key, credentialID: []byte("credential-" + t.Name()), flags: flagUserPresent}
+}
+
+func (a *fakeAuthenticator) authData(rpID string, withCredential bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, hash[:]...)
+	flags := a.flags
+	if withCredential {
+		flags |= flagAttestedCredentialData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if !withCredential {
+		return data
+	}
+
+	data = append(data, make([]byte, 16)...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+	data = append(data, a.credentialID...)
+	coseKey, err := webauthncbor.Marshal(map[int]any{
+		1:  int(webauthncose.EllipticKey),
+		3:  int(webauthncose.AlgES256),
+		-1: int(webauthncose.P256),
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(a.t, err)
+	return append(data, coseKey...)
+}
+
+func clientDataJSON(t *testing.T, typ, challenge, origin string) []byte {
+	data, err := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": origin})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *fakeAuthenticator) create(rpID, origin, challenge string) []byte {
+	att, err := webauthncbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": a.authData(rpID, true)})
+	require.NoError(a.t, err)
+
+	resp, err := json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientDataJSON(a.t, "webauthn.create", challenge, origin)),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(att),
+		},
+	})
+	require.NoError(a.t, err)
+	return resp
+}
+
+func (a *fakeAuthenticator) get(rpID, origin, challenge string) []byte {
+	a.signCount++
+	authData := a.authData(rpID, false)
+	clientData := clientDataJSON(a.t, "webauthn.get", challenge, origin)
+	clientDataHash := sha256.Sum256(clientData)
+	hash := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, hash[:])
+	require.NoError(a.t, err)
+
+	resp, err := json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"signature":         base64.RawURLEncoding.EncodeToString(sig),
+		},
+	})
+	require.NoError(a.t, err)
+	return resp
+}
+
+func newTestWebAuthn(t *testing.T) *webauthn.WebAuthn {
+	rp, err := newWebAuthn(testRPID, "Grafana", testOrigin, time.Minute)
+	require.NoError(t, err)
+	return rp
+}
+
+// register registers the authenticator for a user and returns the user with the stored credential.
+func register(t *testing.T, rp *webauthn.WebAuthn, authenticator *fakeAuthenticator) *webAuthnUser {
+	user := &webAuthnUser{uid: "uid", login: "admin", name: "Admin"}
+	creation, session, err := rp.BeginRegistration(user)
+	require.NoError(t, err)
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(authenticator.create(testRPID, testOrigin, creation.Response.Challenge.String()))
+	require.NoError(t, err)
+	cred, err := rp.CreateCredential(user, *session, parsed)
+	require.NoError(t, err)
+
+	user, err = newWebAuthnUser("uid", "admin", "Admin", []*mfa.WebAuthnCredential{fromWebAuthnCredential(1, "key", cred)})
+	require.NoError(t, err)
+	return user
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	rp := newTestWebAuthn(t)
+
+	t.Run("should return the credential", func(t *testing.T) {
+		authenticator := newFakeAuthenticator(t)
+		user := register(t, rp, authenticator)
+		require.Len(t, user.credentials, 1)
+		assert.Equal(t, authenticator.credentialID, user.credentials[0].ID)
+		assert.NotEmpty(t, user.credentials[0].PublicKey)
+	})
+
+	tests := []struct {
+		desc      string
+		rpID      string
+		origin    string
+		challenge string
+	}{
+		{desc: "should reject another challenge", rpID: testRPID, origin: testOrigin, challenge: "other"},
+		{desc: "should reject another origin", rpID: testRPID, origin: "https://evil.example.com"},
+		{desc: "should reject another relying party", rpID: "evil.example.com", origin: testOrigin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			user := &webAuthnUser{uid: "uid", login: "admin", name: "Admin"}
+			creation, session, err := rp.BeginRegistration(user)
+			require.NoError(t, err)
+			challenge := tt.challenge
+			if challenge == "" {
+				challenge = creation.Response.Challenge.String()
+			}
+
+			parsed, err := protocol.ParseCredentialCreationResponseBytes(newFakeAuthenticator(t).create(tt.rpID, tt.origin, challenge))
+			require.NoError(t, err)
+			_, err = rp.CreateCredential(user, *session, parsed)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	rp := newTestWebAuthn(t)
+	authenticator := newFakeAuthenticator(t)
+	user := register(t, rp, authenticator)
+
+	beginLogin := func(t *testing.T) (string, *webauthn.SessionData) {
+		assertion, session, err := rp.BeginLogin(user)
+		require.NoError(t, err)
+		return assertion.Response.Challenge.String(), session
+	}
+
+	t.Run("should verify the signature and return the counter", func(t *testing.T) {
+		challenge, session := beginLogin(t)
+		cred, err := verifyAssertion(rp, user, session, authenticator.get(testRPID, testOrigin, challenge))
+		require.NoError(t, err)
+		assert.Equal(t, authenticator.signCount, cred.Authenticator.SignCount)
+	})
+
+	t.Run("should reject a counter that did not increase", func(t *testing.T) {
+		user.credentials[0].Authenticator.SignCount = 100
+		defer func() { user.credentials[0].Authenticator.SignCount = 0 }()
+
+		challenge, session := beginLogin(t)
+		_, err := verifyAssertion(rp, user, session, authenticator.get(testRPID, testOrigin, challenge))
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject another challenge", func(t *testing.T) {
+		_, session := beginLogin(t)
+		_, err := verifyAssertion(rp, user, session, authenticator.get(testRPID, testOrigin, "other"))
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject a signature of another key", func(t *testing.T) {
+		other := newFakeAuthenticator(t)
+		other.credentialID = authenticator.credentialID
+		challenge, session := beginLogin(t)
+		_, err := verifyAssertion(rp, user, session, other.get(testRPID, testOrigin, challenge))
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject a key that became backup eligible", func(t *testing.T) {
+		authenticator.flags |= flagBackupEligible
+		defer func() { authenticator.flags = flagUserPresent }()
+
+		challenge, session := beginLogin(t)
+		_, err := verifyAssertion(rp, user, session, authenticator.get(testRPID, testOrigin, challenge))
+		assert.Error(t, err)
+	})
+}
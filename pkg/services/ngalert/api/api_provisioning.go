@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -361,13 +362,12 @@ func (srv *ProvisioningSrv) RoutePostAlertRule(c *contextmodel.ReqContext, ar de
 		if errors.Is(err, store.ErrOptimisticLock) {
 			return ErrResp(http.StatusConflict, err, "")
 		}
-		if errors.Is(err, alerting_models.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		}
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
 
 	resp := ProvisionedAlertRuleFromAlertRule(createdAlertRule, alerting_models.Provenance(provenance))
+	auditlog.RecordResourceID(c.Req.Context(), createdAlertRule.UID)
+	auditlog.RecordAfter(c.Req.Context(), resp)
 	return response.JSON(http.StatusCreated, resp)
 }
 
@@ -393,6 +393,7 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 	updated.OrgID = c.SignedInUser.GetOrgID()
 	updated.UID = UID
 	provenance := determineProvenance(c)
+	srv.recordAlertRule(c, UID)
 	updatedAlertRule, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), c.SignedInUser, updated, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -411,11 +412,13 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 	}
 
 	resp := ProvisionedAlertRuleFromAlertRule(updatedAlertRule, alerting_models.Provenance(provenance))
+	auditlog.RecordAfter(c.Req.Context(), resp)
 	return response.JSON(http.StatusOK, resp)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *contextmodel.ReqContext, UID string) response.Response {
 	provenance := determineProvenance(c)
+	srv.recordAlertRule(c, UID)
 	err := srv.alertRules.DeleteAlertRule(c.Req.Context(), c.SignedInUser, UID, alerting_models.Provenance(provenance))
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
@@ -511,6 +514,7 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 		ErrResp(http.StatusBadRequest, err, "")
 	}
 	provenance := determineProvenance(c)
+	srv.recordAlertRuleGroup(c, folderUID, group, auditlog.RecordBefore)
 	err = srv.alertRules.ReplaceRuleGroup(c.Req.Context(), c.SignedInUser, groupModel, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -524,11 +528,13 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
+	srv.recordAlertRuleGroup(c, folderUID, group, auditlog.RecordAfter)
 	return response.JSON(http.StatusOK, ag)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext, folderUID string, group string) response.Response {
 	provenance := determineProvenance(c)
+	srv.recordAlertRuleGroup(c, folderUID, group, auditlog.RecordBefore)
 	err := srv.alertRules.DeleteRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group, alerting_models.Provenance(provenance))
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
@@ -536,6 +542,30 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext
 	return response.JSON(http.StatusNoContent, "")
 }
 
+// recordAlertRule records the alert rule as the state of an audited request before it is changed.
+func (srv *ProvisioningSrv) recordAlertRule(c *contextmodel.ReqContext, UID string) {
+	if !auditlog.Recording(c.Req.Context()) {
+		return
+	}
+	rule, provenance, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.SignedInUser, UID)
+	if err != nil {
+		return
+	}
+	auditlog.RecordBefore(c.Req.Context(), ProvisionedAlertRuleFromAlertRule(rule, provenance))
+}
+
+// recordAlertRuleGroup records the rule group as the before or after state of an audited request.
+func (srv *ProvisioningSrv) recordAlertRuleGroup(c *contextmodel.ReqContext, folderUID string, group string, record func(context.Context, any)) {
+	if !auditlog.Recording(c.Req.Context()) {
+		return
+	}
+	g, err := srv.alertRules.GetRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group)
+	if err != nil {
+		return
+	}
+	record(c.Req.Context(), ApiAlertRuleGroupFromAlertRuleGroup(g))
+}
+
 func determineProvenance(ctx *contextmodel.ReqContext) definitions.Provenance {
 	if _, disabled := ctx.Req.Header[disableProvenanceHeaderName]; disabled {
 		return definitions.Provenance(alerting_models.ProvenanceNone)
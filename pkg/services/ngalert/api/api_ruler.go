@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -104,6 +105,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 		return ErrResp(http.StatusInternalServerError, err, "failed to fetch provenances of alert rules")
 	}
 
+	var deleted []*ngmodels.AlertRule
 	err = srv.xactManager.InTransaction(c.Req.Context(), func(ctx context.Context) error {
 		deletionCandidates := map[ngmodels.AlertRuleGroupKey]ngmodels.RulesGroup{}
 		if finalGroup != "" {
@@ -131,6 +133,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 			}
 		}
 		rulesToDelete := make([]string, 0)
+		deleted = make([]*ngmodels.AlertRule, 0)
 		provisioned := false
 		auth := true
 		for groupKey, rules := range deletionCandidates {
@@ -154,6 +157,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 				uid = append(uid, rule.UID)
 			}
 			rulesToDelete = append(rulesToDelete, uid...)
+			deleted = append(deleted, rules...)
 		}
 		if len(rulesToDelete) > 0 {
 			err := srv.store.DeleteAlertRulesByUID(ctx, c.SignedInUser.GetOrgID(), rulesToDelete...)
@@ -188,6 +192,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group")
 	}
+	recordRules(c.Req.Context(), auditlog.RecordBefore, deleted...)
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rules deleted"})
 }
 
@@ -473,14 +478,15 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		if len(finalChanges.New) > 0 {
 			userID, _ := identity.UserIdentifier(c.SignedInUser.GetID())
 			limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
-				OrgID:  c.SignedInUser.GetOrgID(),
-				UserID: userID,
+				OrgID:     c.SignedInUser.GetOrgID(),
+				UserID:    userID,
+				FolderUID: groupKey.NamespaceUID,
 			}) // alert rule is table name
 			if err != nil {
 				return fmt.Errorf("failed to get alert rules quota: %w", err)
 			}
 			if limitReached {
-				return ngmodels.ErrQuotaReached
+				return ngmodels.NewErrQuotaReached()
 			}
 		}
 		return nil
@@ -493,13 +499,12 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
 		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
 			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
 		} else if errors.Is(err, store.ErrOptimisticLock) {
 			return ErrResp(http.StatusConflict, err, "")
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
+	recordRuleChanges(c.Req.Context(), finalChanges)
 
 	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
 		// This isn't strictly necessary since the alertmanager config is periodically synced.
@@ -512,6 +517,35 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 	return changesToResponse(finalChanges)
 }
 
+// recordRuleChanges records the changed rules as the before and after state of an audited request.
+func recordRuleChanges(ctx context.Context, changes *store.GroupDelta) {
+	if !auditlog.Recording(ctx) || changes == nil {
+		return
+	}
+	before := make([]*ngmodels.AlertRule, 0, len(changes.Update)+len(changes.Delete))
+	after := make([]*ngmodels.AlertRule, 0, len(changes.Update)+len(changes.New))
+	for _, update := range changes.Update {
+		before = append(before, update.Existing)
+		after = append(after, update.New)
+	}
+	before = append(before, changes.Delete...)
+	after = append(after, changes.New...)
+	recordRules(ctx, auditlog.RecordBefore, before...)
+	recordRules(ctx, auditlog.RecordAfter, after...)
+}
+
+// recordRules records the rules, by UID, as the before or after state of an audited request.
+func recordRules(ctx context.Context, record func(context.Context, any), rules ...*ngmodels.AlertRule) {
+	if !auditlog.Recording(ctx) {
+		return
+	}
+	state := make(map[string]apimodels.ProvisionedAlertRule, len(rules))
+	for _, rule := range rules {
+		state[rule.UID] = ProvisionedAlertRuleFromAlertRule(*rule, ngmodels.ProvenanceNone)
+	}
+	record(ctx, state)
+}
+
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
 	body := apimodels.UpdateRuleGroupResponse{
 		Message: "rule group updated successfully",
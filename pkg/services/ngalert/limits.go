@@ -3,6 +3,7 @@ package ngalert
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/setting"
@@ -10,6 +11,7 @@ import (
 
 type RuleUsageReader interface {
 	Count(ctx context.Context, orgID int64) (int64, error)
+	CountInFolders(ctx context.Context, orgID int64, folderUIDs []string, user identity.Requester) (int64, error)
 }
 
 func RegisterQuotas(cfg *setting.Cfg, qs quota.Service, rules RuleUsageReader) error {
@@ -54,6 +56,18 @@ func UsageReporter(rules RuleUsageReader) quota.UsageReporterFunc {
 			u.Set(tag, globalUsage)
 		}
 
+		if scopeParams != nil && scopeParams.OrgID != 0 && scopeParams.FolderUID != "" {
+			folderUsage, err := rules.CountInFolders(ctx, scopeParams.OrgID, []string{scopeParams.FolderUID}, nil)
+			if err != nil {
+				return u, err
+			}
+			tag, err := quota.NewTag(models.QuotaTargetSrv, models.QuotaTarget, quota.FolderScope)
+			if err != nil {
+				return u, err
+			}
+			u.Set(tag, folderUsage)
+		}
+
 		return u, nil
 	}
 }
@@ -74,7 +88,13 @@ func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
 		return limits, err
 	}
 
+	folderQuotaTag, err := quota.NewTag(models.QuotaTargetSrv, models.QuotaTarget, quota.FolderScope)
+	if err != nil {
+		return limits, err
+	}
+
 	limits.Set(globalQuotaTag, cfg.Quota.Global.AlertRule)
 	limits.Set(orgQuotaTag, cfg.Quota.Org.AlertRule)
+	limits.Set(folderQuotaTag, cfg.Quota.Folder.AlertRule)
 	return limits, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/setting"
@@ -41,6 +42,23 @@ func TestUsageReporter(t *testing.T) {
 		require.Equal(t, int64(30), val)
 	})
 
+	t.Run("reports folder usage", func(t *testing.T) {
+		rules := newFakeUsageReader(map[int64]int64{1: 10, 2: 20})
+		rules.folderUsage = map[string]int64{"folder-1": 4}
+		params := quota.ScopeParameters{
+			OrgID:     1,
+			FolderUID: "folder-1",
+		}
+
+		res, err := UsageReporter(rules)(context.Background(), &params)
+
+		require.NoError(t, err)
+		rulesFolder, _ := quota.NewTag(models.QuotaTargetSrv, models.QuotaTarget, quota.FolderScope)
+		val, ok := res.Get(rulesFolder)
+		require.True(t, ok, "reporter did not report on folder rules usage")
+		require.Equal(t, int64(4), val)
+	})
+
 	t.Run("reports global usage if scope params are nil", func(t *testing.T) {
 		rules := newFakeUsageReader(map[int64]int64{1: 10, 2: 20})
 
@@ -60,6 +78,9 @@ func TestReadQuotaConfig(t *testing.T) {
 			Org: setting.OrgQuota{
 				AlertRule: 30,
 			},
+			Folder: setting.FolderQuota{
+				AlertRule: 5,
+			},
 			Global: setting.GlobalQuota{
 				AlertRule: 50,
 			},
@@ -76,6 +97,16 @@ func TestReadQuotaConfig(t *testing.T) {
 		require.Equal(t, int64(30), val)
 	})
 
+	t.Run("registers per-folder rule quota from config", func(t *testing.T) {
+		res, err := readQuotaConfig(cfg)
+
+		require.NoError(t, err)
+		rulesFolder, _ := quota.NewTag(models.QuotaTargetSrv, models.QuotaTarget, quota.FolderScope)
+		val, ok := res.Get(rulesFolder)
+		require.True(t, ok, "did not configure per-folder rules quota")
+		require.Equal(t, int64(5), val)
+	})
+
 	t.Run("registers global rule quota from config", func(t *testing.T) {
 		res, err := readQuotaConfig(cfg)
 
@@ -88,7 +119,8 @@ func TestReadQuotaConfig(t *testing.T) {
 }
 
 type fakeUsageReader struct {
-	usage map[int64]int64 // orgID -> count
+	usage       map[int64]int64  // orgID -> count
+	folderUsage map[string]int64 // folderUID -> count
 }
 
 func newFakeUsageReader(usage map[int64]int64) fakeUsageReader {
@@ -111,3 +143,11 @@ func (f fakeUsageReader) Count(_ context.Context, orgID int64) (int64, error) {
 	}
 	return 0, nil
 }
+
+func (f fakeUsageReader) CountInFolders(_ context.Context, _ int64, folderUIDs []string, _ identity.Requester) (int64, error) {
+	total := int64(0)
+	for _, uid := range folderUIDs {
+		total += f.folderUsage[uid]
+	}
+	return total, nil
+}
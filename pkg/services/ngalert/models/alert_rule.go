@@ -866,6 +866,12 @@ const (
 	QuotaTarget    quota.Target    = "alert_rule"
 )
 
+// NewErrQuotaReached returns ErrQuotaReached wrapped together with the quota error of the alert
+// rule target, so the API responds with the same error as other quotas.
+func NewErrQuotaReached() error {
+	return fmt.Errorf("%w: %w", ErrQuotaReached, quota.NewErrQuotaReached(QuotaTarget))
+}
+
 type ruleKeyContextKey struct{}
 
 func WithRuleKey(ctx context.Context, ruleKey AlertRuleKey) context.Context {
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -252,7 +253,7 @@ func createEmailSender(t *testing.T) *emailSender {
 	cfg.Smtp.Host = "localhost:1234"
 	mailer := notifications.NewFakeMailer()
 
-	ns, err := notifications.ProvideService(bus, cfg, mailer, nil)
+	ns, err := notifications.ProvideService(bus, cfg, mailer, nil, nil, fakes.NewFakeSecretsService())
 	require.NoError(t, err)
 
 	return &emailSender{ns: ns}
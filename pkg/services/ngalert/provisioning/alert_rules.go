@@ -250,7 +250,7 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 			return errors.New("couldn't find newly created id")
 		}
 
-		if err = service.checkLimitsTransactionCtx(ctx, user); err != nil {
+		if err = service.checkLimitsTransactionCtx(ctx, user, rule.NamespaceUID); err != nil {
 			return err
 		}
 
@@ -533,7 +533,7 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 			}
 		}
 
-		if err := service.checkLimitsTransactionCtx(ctx, user); err != nil {
+		if err := service.checkLimitsTransactionCtx(ctx, user, delta.GroupKey.NamespaceUID); err != nil {
 			return err
 		}
 
@@ -671,8 +671,9 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, user ident
 	})
 }
 
-// checkLimitsTransactionCtx checks whether the current transaction (as identified by the ctx) breaches configured alert rule limits.
-func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context, user identity.Requester) error {
+// checkLimitsTransactionCtx checks whether the current transaction (as identified by the ctx) breaches configured alert rule limits,
+// including the limits of the folder the rules are stored in.
+func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context, user identity.Requester, folderUID string) error {
 	// default to 0 if there is no user
 	var userID int64
 	if id, err := identity.UserIdentifier(user.GetID()); err == nil {
@@ -680,14 +681,15 @@ func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context,
 	}
 
 	limitReached, err := service.quotas.CheckQuotaReached(ctx, models.QuotaTargetSrv, &quota.ScopeParameters{
-		OrgID:  user.GetOrgID(),
-		UserID: userID,
+		OrgID:     user.GetOrgID(),
+		UserID:    userID,
+		FolderUID: folderUID,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to check alert rule quota: %w", err)
 	}
 	if limitReached {
-		return models.ErrQuotaReached
+		return models.NewErrQuotaReached()
 	}
 	return nil
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -608,6 +609,7 @@ func TestAlertRuleService(t *testing.T) {
 		_, err := ruleService.CreateAlertRule(context.Background(), u, dummyRule("test#1", orgID), models.ProvenanceNone)
 
 		require.ErrorIs(t, err, models.ErrQuotaReached)
+		require.ErrorIs(t, err, quota.ErrQuotaReached)
 	})
 
 	t.Run("quota met causes group write to be rejected", func(t *testing.T) {
@@ -620,6 +622,7 @@ func TestAlertRuleService(t *testing.T) {
 		err := ruleService.ReplaceRuleGroup(context.Background(), u, group, models.ProvenanceAPI)
 
 		require.ErrorIs(t, err, models.ErrQuotaReached)
+		require.ErrorIs(t, err, quota.ErrQuotaReached)
 	})
 }
 
@@ -39,19 +39,22 @@ type Mailer interface {
 }
 
 func (ns *NotificationService) Send(ctx context.Context, msg *Message) (int, error) {
-	messages := []*Message{}
+	return ns.mailer.Send(ctx, splitMessage(msg)...)
+}
 
+// splitMessage returns a message per recipient, unless the message is a single email.
+func splitMessage(msg *Message) []*Message {
 	if msg.SingleEmail {
-		messages = append(messages, msg)
-	} else {
-		for _, address := range msg.To {
-			copy := *msg
-			copy.To = []string{address}
-			messages = append(messages, &copy)
-		}
+		return []*Message{msg}
 	}
 
-	return ns.mailer.Send(ctx, messages...)
+	messages := make([]*Message, 0, len(msg.To))
+	for _, address := range msg.To {
+		copy := *msg
+		copy.To = []string{address}
+		messages = append(messages, &copy)
+	}
+	return messages
 }
 
 func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Message, error) {
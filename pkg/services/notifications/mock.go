@@ -14,6 +14,11 @@ type NotificationServiceMock struct {
 	EmailVerification SendVerifyEmailCommand
 	ShouldError       error
 
+	OutboxMessages       []*OutboxMessage
+	OutboxRecipientStats []*OutboxRecipientStats
+	OutboxResend         ResendOutboxMessagesCommand
+	OutboxResendCount    int64
+
 	WebhookHandler   func(context.Context, *SendWebhookSync) error
 	EmailHandlerSync func(context.Context, *SendEmailCommandSync) error
 	EmailHandler     func(context.Context, *SendEmailCommand) error
@@ -59,4 +64,22 @@ func (ns *NotificationServiceMock) SendVerificationEmail(ctx context.Context, cm
 	return ns.ShouldError
 }
 
+func (ns *NotificationServiceMock) SearchOutboxMessages(ctx context.Context, query *SearchOutboxMessagesQuery) (*SearchOutboxMessagesResult, error) {
+	return &SearchOutboxMessagesResult{
+		TotalCount: int64(len(ns.OutboxMessages)),
+		Messages:   ns.OutboxMessages,
+		Page:       query.Page,
+		PerPage:    query.Limit,
+	}, ns.ShouldError
+}
+
+func (ns *NotificationServiceMock) GetOutboxRecipientStats(ctx context.Context, query *GetOutboxRecipientStatsQuery) ([]*OutboxRecipientStats, error) {
+	return ns.OutboxRecipientStats, ns.ShouldError
+}
+
+func (ns *NotificationServiceMock) ResendOutboxMessages(ctx context.Context, cmd *ResendOutboxMessagesCommand) (int64, error) {
+	ns.OutboxResend = *cmd
+	return ns.OutboxResendCount, ns.ShouldError
+}
+
 func MockNotificationService() *NotificationServiceMock { return &NotificationServiceMock{} }
@@ -9,6 +9,7 @@ import (
 
 var ErrInvalidEmailCode = errors.New("invalid or expired email code")
 var ErrSmtpNotEnabled = errors.New("SMTP not configured, check your grafana.ini config file's [smtp] section")
+var ErrOutboxMessageNotFound = errors.New("failed outbox message not found")
 
 // SendEmailAttachFile is a definition of the attached files without path
 type SendEmailAttachFile struct {
@@ -46,6 +47,17 @@ type SendWebhookSync struct {
 	TLSConfig   *tls.Config
 }
 
+// SendWebhookCommand is the command for sending webhooks asynchronously
+type SendWebhookCommand struct {
+	Url         string
+	User        string
+	Password    string
+	Body        string
+	HttpMethod  string
+	HttpHeader  map[string]string
+	ContentType string
+}
+
 type SendResetPasswordEmailCommand struct {
 	User *user.User
 }
@@ -59,3 +71,86 @@ type SendVerifyEmailCommand struct {
 	Code  string
 	Email string
 }
+
+const (
+	OutboxKindEmail   = "email"
+	OutboxKindWebhook = "webhook"
+
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	// OutboxStatusFailed is the status of the messages which couldn't be delivered after the maximum number of attempts
+	OutboxStatusFailed = "failed"
+)
+
+// OutboxMessage is an email or a webhook queued for delivery. Emails sent to several recipients are
+// queued once per recipient, unless they are sent as a single email.
+type OutboxMessage struct {
+	ID        int64  `xorm:"pk autoincr 'id'" json:"id"`
+	Kind      string `json:"kind"`
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	// Payload is the encrypted email or webhook, cleared once the message is delivered
+	Payload string `json:"-"`
+	// AttachmentID references the files attached to the email, shared by all its recipients
+	AttachmentID int64  `xorm:"'attachment_id'" json:"-"`
+	Status       string `json:"status"`
+	Attempts     int64  `json:"attempts"`
+	LastError    string `json:"lastError"`
+	// NextAttempt, Created and Updated are unix timestamps
+	NextAttempt int64 `json:"nextAttempt"`
+	Created     int64 `json:"created"`
+	Updated     int64 `json:"updated"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "notification_outbox"
+}
+
+// OutboxAttachment holds the encrypted files attached to a queued email, once for all its recipients.
+// It is deleted by the outbox cleanup once no pending or failed message references it.
+type OutboxAttachment struct {
+	ID      int64 `xorm:"pk autoincr 'id'"`
+	Content string
+	Created int64
+}
+
+func (OutboxAttachment) TableName() string {
+	return "notification_outbox_attachment"
+}
+
+type SearchOutboxMessagesQuery struct {
+	Status    string
+	Kind      string
+	Recipient string
+	Page      int
+	Limit     int
+}
+
+type SearchOutboxMessagesResult struct {
+	TotalCount int64            `json:"totalCount"`
+	Messages   []*OutboxMessage `json:"messages"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"perPage"`
+}
+
+// ResendOutboxMessagesCommand queues the failed messages with the given ids for delivery again,
+// or all the failed messages, of the recipient if set, when no id is given.
+type ResendOutboxMessagesCommand struct {
+	IDs       []int64 `json:"ids"`
+	Recipient string  `json:"recipient"`
+}
+
+type GetOutboxRecipientStatsQuery struct {
+	Recipient string
+	Limit     int
+}
+
+// OutboxRecipientStats counts the messages of the outbox queued for a recipient, by status.
+type OutboxRecipientStats struct {
+	Recipient string `xorm:"recipient" json:"recipient"`
+	Kind      string `xorm:"kind" json:"kind"`
+	Pending   int64  `xorm:"pending" json:"pending"`
+	Sent      int64  `xorm:"sent" json:"sent"`
+	Failed    int64  `xorm:"failed" json:"failed"`
+	Attempts  int64  `xorm:"attempts" json:"attempts"`
+}
@@ -9,12 +9,15 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -51,15 +54,17 @@ const (
 	tmplVerifyEmail     = "verify_email"
 )
 
-func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore) (*NotificationService, error) {
+func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore, sqlStore db.DB, secretsService secrets.Service) (*NotificationService, error) {
 	ns := &NotificationService{
-		Bus:          bus,
-		Cfg:          cfg,
-		log:          log.New("notifications"),
-		mailQueue:    make(chan *Message, 10),
-		webhookQueue: make(chan *Webhook, 10),
-		mailer:       mailer,
-		store:        store,
+		Bus:            bus,
+		Cfg:            cfg,
+		log:            log.New("notifications"),
+		outbox:         &xormOutboxStore{db: sqlStore},
+		outboxWakeup:   make(chan struct{}, 1),
+		now:            time.Now,
+		mailer:         mailer,
+		store:          store,
+		secretsService: secretsService,
 	}
 
 	ns.Bus.AddEventListener(ns.signUpStartedHandler)
@@ -97,6 +102,12 @@ func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUser
 	if cfg.EmailCodeValidMinutes == 0 {
 		cfg.EmailCodeValidMinutes = 120
 	}
+	if cfg.NotificationOutbox.MaxAttempts == 0 {
+		cfg.NotificationOutbox.MaxAttempts = 10
+	}
+	if cfg.NotificationOutbox.PollInterval == 0 {
+		cfg.NotificationOutbox.PollInterval = 10 * time.Second
+	}
 	return ns, nil
 }
 
@@ -108,33 +119,35 @@ type NotificationService struct {
 	Bus bus.Bus
 	Cfg *setting.Cfg
 
-	mailQueue    chan *Message
-	webhookQueue chan *Webhook
+	// outbox stores the emails and webhooks sent asynchronously until they are delivered
+	outbox       outboxStore
+	outboxWakeup chan struct{}
+	now          func() time.Time
 	mailer       Mailer
 	log          log.Logger
 	store        TempUserStore
+	// secretsService encrypts the queued messages, which may contain credentials or password reset links
+	secretsService secrets.Service
 }
 
 func (ns *NotificationService) Run(ctx context.Context) error {
+	// deliver the messages left over by a previous run
+	ns.processOutbox(ctx)
+	ns.cleanupOutbox(ctx)
+
+	ticker := time.NewTicker(ns.Cfg.NotificationOutbox.PollInterval)
+	defer ticker.Stop()
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer cleanupTicker.Stop()
+
 	for {
 		select {
-		case webhook := <-ns.webhookQueue:
-			err := ns.sendWebRequestSync(context.Background(), webhook)
-			if err != nil {
-				ns.log.Error("Failed to send webrequest ", "error", err)
-			}
-		case msg := <-ns.mailQueue:
-			num, err := ns.Send(ctx, msg)
-			tos := strings.Join(msg.To, "; ")
-			info := ""
-			if err != nil {
-				if len(msg.Info) > 0 {
-					info = ", info: " + msg.Info
-				}
-				ns.log.Error(fmt.Sprintf("Async sent email %d succeed, not send emails: %s%s err: %s", num, tos, info, err))
-			} else {
-				ns.log.Debug(fmt.Sprintf("Async sent email %d succeed, sent emails: %s%s", num, tos, info))
-			}
+		case <-ns.outboxWakeup:
+			ns.processOutbox(ctx)
+		case <-ticker.C:
+			ns.processOutbox(ctx)
+		case <-cleanupTicker.C:
+			ns.cleanupOutbox(ctx)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -145,6 +158,19 @@ func (ns *NotificationService) GetMailer() Mailer {
 	return ns.mailer
 }
 
+// SendWebhookCommandHandler queues the webhook in the outbox, it is delivered asynchronously.
+func (ns *NotificationService) SendWebhookCommandHandler(ctx context.Context, cmd *SendWebhookCommand) error {
+	return ns.enqueueWebhook(ctx, &Webhook{
+		Url:         cmd.Url,
+		User:        cmd.User,
+		Password:    cmd.Password,
+		Body:        cmd.Body,
+		HttpMethod:  cmd.HttpMethod,
+		HttpHeader:  cmd.HttpHeader,
+		ContentType: cmd.ContentType,
+	})
+}
+
 func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *SendWebhookSync) error {
 	return ns.sendWebRequestSync(ctx, &Webhook{
 		Url:         cmd.Url,
@@ -225,8 +251,7 @@ func (ns *NotificationService) SendEmailCommandHandler(ctx context.Context, cmd
 		return err
 	}
 
-	ns.mailQueue <- message
-	return nil
+	return ns.enqueueEmail(ctx, message)
 }
 
 func (ns *NotificationService) SendResetPasswordEmail(ctx context.Context, cmd *SendResetPasswordEmailCommand) error {
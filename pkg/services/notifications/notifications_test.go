@@ -2,18 +2,29 @@ package notifications
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
 
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
 func newBus(t *testing.T) bus.Bus {
 	t.Helper()
 	tracer := tracing.InitializeTracerForTest()
@@ -194,12 +205,15 @@ func TestSendEmailAsync(t *testing.T) {
 
 	t.Run("When sending reset email password", func(t *testing.T) {
 		sut, _ := createSut(t, bus)
+		withFakeOutbox(sut)
 		testuser := user.User{Email: "asd@asd.com", Login: "asd@asd.com"}
 		err := sut.SendResetPasswordEmail(context.Background(), &SendResetPasswordEmailCommand{User: &testuser})
 
 		require.NoError(t, err)
 
-		sentMsg := <-sut.mailQueue
+		queued := queuedEmails(t, sut)
+		require.Len(t, queued, 1)
+		sentMsg := queued[0]
 		assert.Contains(t, sentMsg.Body["text/html"], "body")
 		assert.NotContains(t, sentMsg.Body["text/plain"], "body")
 		assert.Equal(t, "Reset your Grafana password - asd@asd.com", sentMsg.Subject)
@@ -257,7 +271,7 @@ func TestSendEmailAsync(t *testing.T) {
 	})
 
 	t.Run("When SMTP dialer is disconnected", func(t *testing.T) {
-		ns := createDisconnectedSut(t, bus)
+		ns := withFakeOutbox(createDisconnectedSut(t, bus))
 		cmd := &SendEmailCommand{
 			Subject:     "subject",
 			To:          []string{"1@grafana.com", "2@grafana.com", "3@grafana.com"},
@@ -269,6 +283,7 @@ func TestSendEmailAsync(t *testing.T) {
 
 		// The async version should not surface connection errors via Bus. It should only log them.
 		require.NoError(t, err)
+		require.Len(t, queuedEmails(t, ns), 3)
 	})
 }
 
@@ -283,7 +298,7 @@ func createSut(t *testing.T, bus bus.Bus) (*NotificationService, *FakeMailer) {
 
 func createSutWithConfig(t *testing.T, bus bus.Bus, cfg *setting.Cfg) (*NotificationService, *FakeMailer, error) {
 	smtp := NewFakeMailer()
-	ns, err := ProvideService(bus, cfg, smtp, nil)
+	ns, err := ProvideService(bus, cfg, smtp, nil, nil, fakes.NewFakeSecretsService())
 	return ns, smtp, err
 }
 
@@ -292,11 +307,113 @@ func createDisconnectedSut(t *testing.T, bus bus.Bus) *NotificationService {
 
 	cfg := createSmtpConfig()
 	smtp := NewFakeDisconnectedMailer()
-	ns, err := ProvideService(bus, cfg, smtp, nil)
+	ns, err := ProvideService(bus, cfg, smtp, nil, nil, fakes.NewFakeSecretsService())
 	require.NoError(t, err)
 	return ns
 }
 
+// withTestOutbox stores the outbox of the service in a test database, for the tests sending emails asynchronously.
+func withTestOutbox(t *testing.T, ns *NotificationService) *NotificationService {
+	t.Helper()
+	sqlStore := db.InitTestDB(t)
+	ns.outbox = &xormOutboxStore{db: sqlStore}
+	ns.secretsService = secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	return ns
+}
+
+// withFakeOutbox stores the outbox of the service in memory, for the tests only checking the queued emails.
+func withFakeOutbox(ns *NotificationService) *NotificationService {
+	ns.outbox = &fakeOutboxStore{}
+	return ns
+}
+
+// fakeOutboxStore keeps the queued messages in memory, only the messages due for delivery can be read back.
+type fakeOutboxStore struct {
+	messages    []*OutboxMessage
+	attachments []*OutboxAttachment
+}
+
+func (fs *fakeOutboxStore) Insert(ctx context.Context, messages []*OutboxMessage, attachment *OutboxAttachment, now time.Time) error {
+	if attachment != nil {
+		fs.attachments = append(fs.attachments, attachment)
+		attachment.ID = int64(len(fs.attachments))
+		attachment.Created = now.Unix()
+	}
+	for _, msg := range messages {
+		fs.messages = append(fs.messages, msg)
+		msg.ID = int64(len(fs.messages))
+		if attachment != nil {
+			msg.AttachmentID = attachment.ID
+		}
+		msg.Status = OutboxStatusPending
+		msg.NextAttempt = now.Unix()
+		msg.Created = now.Unix()
+		msg.Updated = now.Unix()
+	}
+	return nil
+}
+
+func (fs *fakeOutboxStore) GetAttachment(ctx context.Context, id int64) (*OutboxAttachment, error) {
+	if id < 1 || id > int64(len(fs.attachments)) {
+		return nil, fmt.Errorf("outbox attachment %d not found", id)
+	}
+	return fs.attachments[id-1], nil
+}
+
+func (fs *fakeOutboxStore) GetDue(ctx context.Context, now time.Time, limit int) ([]*OutboxMessage, error) {
+	due := make([]*OutboxMessage, 0)
+	for _, msg := range fs.messages {
+		if len(due) < limit && msg.Status == OutboxStatusPending && msg.NextAttempt <= now.Unix() {
+			due = append(due, msg)
+		}
+	}
+	return due, nil
+}
+
+func (fs *fakeOutboxStore) Claim(ctx context.Context, msg *OutboxMessage, until time.Time, now time.Time) (bool, error) {
+	return false, nil
+}
+
+func (fs *fakeOutboxStore) Update(ctx context.Context, msg *OutboxMessage, now time.Time) error {
+	return nil
+}
+
+func (fs *fakeOutboxStore) Search(ctx context.Context, query *SearchOutboxMessagesQuery) (*SearchOutboxMessagesResult, error) {
+	return &SearchOutboxMessagesResult{}, nil
+}
+
+func (fs *fakeOutboxStore) GetRecipientStats(ctx context.Context, query *GetOutboxRecipientStatsQuery) ([]*OutboxRecipientStats, error) {
+	return nil, nil
+}
+
+func (fs *fakeOutboxStore) Resend(ctx context.Context, cmd *ResendOutboxMessagesCommand, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (fs *fakeOutboxStore) DeleteUpdatedBefore(ctx context.Context, status string, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (fs *fakeOutboxStore) DeleteUnusedAttachments(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// queuedEmails returns the emails queued in the outbox which weren't delivered yet.
+func queuedEmails(t *testing.T, ns *NotificationService) []*Message {
+	t.Helper()
+
+	due, err := ns.outbox.GetDue(context.Background(), ns.now(), 100)
+	require.NoError(t, err)
+
+	messages := make([]*Message, 0, len(due))
+	for _, msg := range due {
+		m, err := ns.decodeOutboxEmail(context.Background(), msg)
+		require.NoError(t, err)
+		messages = append(messages, m)
+	}
+	return messages
+}
+
 func createSmtpConfig() *setting.Cfg {
 	cfg := setting.NewCfg()
 	cfg.StaticRootPath = "../../../public/"
@@ -0,0 +1,345 @@
+package notifications
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+const (
+	outboxBatchSize = 50
+	// outboxLease is how long a claimed message is left to the instance delivering it before it is
+	// considered lost, e.g. because the instance was stopped, and delivered again
+	outboxLease = 5 * time.Minute
+	// outboxColumnLength is the length of the recipient and subject columns
+	outboxColumnLength = 255
+
+	outboxResultSent    = "sent"
+	outboxResultRetried = "retried"
+	outboxResultFailed  = "failed"
+)
+
+var (
+	outboxDeliveriesTotal *prometheus.CounterVec
+	outboxDeliveryDelay   *prometheus.HistogramVec
+)
+
+func init() {
+	outboxDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "notification_outbox_deliveries_total",
+		Help:      "Number of delivery attempts of queued emails and webhooks, counted once per recipient, by result",
+		Namespace: "grafana",
+	}, []string{"kind", "result"})
+
+	outboxDeliveryDelay = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "notification_outbox_delivery_delay_seconds",
+		Help:      "Time between queueing an email or a webhook and its delivery to a recipient",
+		Namespace: "grafana",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"kind"})
+}
+
+// Outbox gives access to the emails and webhooks queued for delivery.
+type Outbox interface {
+	SearchOutboxMessages(ctx context.Context, query *SearchOutboxMessagesQuery) (*SearchOutboxMessagesResult, error)
+	GetOutboxRecipientStats(ctx context.Context, query *GetOutboxRecipientStatsQuery) ([]*OutboxRecipientStats, error)
+	// ResendOutboxMessages queues failed messages for delivery again and returns how many were.
+	ResendOutboxMessages(ctx context.Context, cmd *ResendOutboxMessagesCommand) (int64, error)
+}
+
+func (ns *NotificationService) SearchOutboxMessages(ctx context.Context, query *SearchOutboxMessagesQuery) (*SearchOutboxMessagesResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return ns.outbox.Search(ctx, query)
+}
+
+func (ns *NotificationService) GetOutboxRecipientStats(ctx context.Context, query *GetOutboxRecipientStatsQuery) ([]*OutboxRecipientStats, error) {
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	return ns.outbox.GetRecipientStats(ctx, query)
+}
+
+func (ns *NotificationService) ResendOutboxMessages(ctx context.Context, cmd *ResendOutboxMessagesCommand) (int64, error) {
+	count, err := ns.outbox.Resend(ctx, cmd, ns.now())
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		ns.log.Info("Outbox messages queued for delivery again", "count", count)
+		ns.wakeUpOutbox()
+	}
+	return count, nil
+}
+
+// enqueueEmail queues the message in the outbox, once per recipient unless it is a single email.
+// The attached files are stored once for all the recipients.
+func (ns *NotificationService) enqueueEmail(ctx context.Context, msg *Message) error {
+	var attachment *OutboxAttachment
+	if len(msg.AttachedFiles) > 0 {
+		content, err := ns.encryptOutboxPayload(ctx, msg.AttachedFiles)
+		if err != nil {
+			return err
+		}
+		attachment = &OutboxAttachment{Content: content}
+	}
+
+	withoutFiles := *msg
+	withoutFiles.AttachedFiles = nil
+	messages := splitMessage(&withoutFiles)
+	entries := make([]*OutboxMessage, 0, len(messages))
+	for _, m := range messages {
+		payload, err := ns.encryptOutboxPayload(ctx, m)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &OutboxMessage{
+			Kind:      OutboxKindEmail,
+			Recipient: truncate(strings.Join(m.To, ", "), outboxColumnLength),
+			Subject:   truncate(m.Subject, outboxColumnLength),
+			Payload:   payload,
+		})
+	}
+
+	if err := ns.outbox.Insert(ctx, entries, attachment, ns.now()); err != nil {
+		return err
+	}
+	ns.wakeUpOutbox()
+	return nil
+}
+
+// enqueueWebhook queues the webhook in the outbox. The TLS configuration and the response validation can't
+// be stored with the webhook, webhooks using them must be sent with SendWebhookSync.
+func (ns *NotificationService) enqueueWebhook(ctx context.Context, webhook *Webhook) error {
+	if webhook.TLSConfig != nil || webhook.Validation != nil {
+		return errors.New("webhooks with a TLS configuration or a response validation can't be queued")
+	}
+	target, err := url.Parse(webhook.Url)
+	if err != nil {
+		return redactURL(err)
+	}
+	payload, err := ns.encryptOutboxPayload(ctx, webhook)
+	if err != nil {
+		return err
+	}
+
+	if err := ns.outbox.Insert(ctx, []*OutboxMessage{{
+		Kind:      OutboxKindWebhook,
+		Recipient: truncate(target.Redacted(), outboxColumnLength),
+		Payload:   payload,
+	}}, nil, ns.now()); err != nil {
+		return err
+	}
+	ns.wakeUpOutbox()
+	return nil
+}
+
+// encryptOutboxPayload encodes the value and encrypts it, as the queued messages may contain credentials
+// or password reset links.
+func (ns *NotificationService) encryptOutboxPayload(ctx context.Context, v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := ns.secretsService.Encrypt(ctx, payload, secrets.WithoutScope())
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt outbox message: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func (ns *NotificationService) decryptOutboxPayload(ctx context.Context, payload string, v any) error {
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return err
+	}
+	decrypted, err := ns.secretsService.Decrypt(ctx, decoded)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt outbox message: %w", err)
+	}
+	return json.Unmarshal(decrypted, v)
+}
+
+// decodeOutboxEmail returns the queued email with its attached files.
+func (ns *NotificationService) decodeOutboxEmail(ctx context.Context, msg *OutboxMessage) (*Message, error) {
+	var email Message
+	if err := ns.decryptOutboxPayload(ctx, msg.Payload, &email); err != nil {
+		return nil, fmt.Errorf("failed to decode email: %w", err)
+	}
+	if msg.AttachmentID == 0 {
+		return &email, nil
+	}
+
+	attachment, err := ns.outbox.GetAttachment(ctx, msg.AttachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ns.decryptOutboxPayload(ctx, attachment.Content, &email.AttachedFiles); err != nil {
+		return nil, fmt.Errorf("failed to decode email attachments: %w", err)
+	}
+	return &email, nil
+}
+
+func (ns *NotificationService) wakeUpOutbox() {
+	select {
+	case ns.outboxWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// processOutbox delivers the messages which are due, until there are none left.
+func (ns *NotificationService) processOutbox(ctx context.Context) {
+	for {
+		due, err := ns.outbox.GetDue(ctx, ns.now(), outboxBatchSize)
+		if err != nil {
+			ns.log.Error("Failed to get outbox messages due for delivery", "error", err)
+			return
+		}
+
+		claimed := 0
+		for _, msg := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			if ns.deliverOutboxMessage(ctx, msg) {
+				claimed++
+			}
+		}
+
+		// stop when another instance is delivering the same messages or the store fails to claim them
+		if len(due) < outboxBatchSize || claimed == 0 {
+			return
+		}
+	}
+}
+
+// deliverOutboxMessage delivers the message if no other instance claimed it, and schedules the next
+// attempt or marks the message as failed if the delivery fails. It returns whether the message was claimed.
+func (ns *NotificationService) deliverOutboxMessage(ctx context.Context, msg *OutboxMessage) bool {
+	now := ns.now()
+	claimed, err := ns.outbox.Claim(ctx, msg, now.Add(outboxLease), now)
+	if err != nil {
+		ns.log.Error("Failed to claim outbox message", "id", msg.ID, "error", err)
+		return false
+	}
+	if !claimed {
+		return false
+	}
+
+	deliveryErr := ns.deliver(ctx, msg)
+	now = ns.now()
+
+	switch {
+	case deliveryErr == nil:
+		msg.Status = OutboxStatusSent
+		msg.Payload = ""
+		msg.LastError = ""
+		outboxDeliveriesTotal.WithLabelValues(msg.Kind, outboxResultSent).Inc()
+		outboxDeliveryDelay.WithLabelValues(msg.Kind).Observe(now.Sub(time.Unix(msg.Created, 0)).Seconds())
+		ns.log.Debug("Outbox message delivered", "id", msg.ID, "kind", msg.Kind, "attempts", msg.Attempts)
+	case msg.Attempts >= ns.Cfg.NotificationOutbox.MaxAttempts:
+		msg.Status = OutboxStatusFailed
+		msg.LastError = deliveryErr.Error()
+		outboxDeliveriesTotal.WithLabelValues(msg.Kind, outboxResultFailed).Inc()
+		ns.log.Error("Failed to deliver outbox message, giving up", "id", msg.ID, "kind", msg.Kind, "recipient", msg.Recipient, "attempts", msg.Attempts, "error", deliveryErr)
+	default:
+		msg.NextAttempt = now.Add(ns.outboxBackoff(msg.Attempts)).Unix()
+		msg.LastError = deliveryErr.Error()
+		outboxDeliveriesTotal.WithLabelValues(msg.Kind, outboxResultRetried).Inc()
+		ns.log.Warn("Failed to deliver outbox message, retrying", "id", msg.ID, "kind", msg.Kind, "attempts", msg.Attempts, "nextAttempt", time.Unix(msg.NextAttempt, 0), "error", deliveryErr)
+	}
+
+	if err := ns.outbox.Update(ctx, msg, now); err != nil {
+		ns.log.Error("Failed to update outbox message", "id", msg.ID, "error", err)
+	}
+	return true
+}
+
+func (ns *NotificationService) deliver(ctx context.Context, msg *OutboxMessage) error {
+	switch msg.Kind {
+	case OutboxKindEmail:
+		email, err := ns.decodeOutboxEmail(ctx, msg)
+		if err != nil {
+			return err
+		}
+		_, err = ns.mailer.Send(ctx, email)
+		return err
+	case OutboxKindWebhook:
+		var webhook Webhook
+		if err := ns.decryptOutboxPayload(ctx, msg.Payload, &webhook); err != nil {
+			return fmt.Errorf("failed to decode webhook: %w", err)
+		}
+		return ns.sendWebRequestSync(ctx, &webhook)
+	default:
+		return fmt.Errorf("unknown outbox message kind %q", msg.Kind)
+	}
+}
+
+// outboxBackoff returns the delay before the next attempt after the given number of failed attempts.
+func (ns *NotificationService) outboxBackoff(attempts int64) time.Duration {
+	backoff := ns.Cfg.NotificationOutbox.InitialBackoff
+	for i := int64(1); i < attempts && backoff < ns.Cfg.NotificationOutbox.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > ns.Cfg.NotificationOutbox.MaxBackoff {
+		backoff = ns.Cfg.NotificationOutbox.MaxBackoff
+	}
+	return backoff
+}
+
+// cleanupOutbox deletes the delivered and failed messages older than their retention, and the attachments
+// no message needs anymore.
+func (ns *NotificationService) cleanupOutbox(ctx context.Context) {
+	retentions := map[string]time.Duration{
+		OutboxStatusSent:   ns.Cfg.NotificationOutbox.SentRetention,
+		OutboxStatusFailed: ns.Cfg.NotificationOutbox.FailedRetention,
+	}
+	for status, retention := range retentions {
+		if retention <= 0 {
+			continue
+		}
+		deleted, err := ns.outbox.DeleteUpdatedBefore(ctx, status, ns.now().Add(-retention))
+		if err != nil {
+			ns.log.Error("Failed to delete old outbox messages", "status", status, "error", err)
+			continue
+		}
+		if deleted > 0 {
+			ns.log.Debug("Deleted old outbox messages", "status", status, "count", deleted)
+		}
+	}
+
+	deleted, err := ns.outbox.DeleteUnusedAttachments(ctx)
+	if err != nil {
+		ns.log.Error("Failed to delete unused outbox attachments", "error", err)
+		return
+	}
+	if deleted > 0 {
+		ns.log.Debug("Deleted unused outbox attachments", "count", deleted)
+	}
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	s = s[:length]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,230 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type outboxStore interface {
+	// Insert queues the messages, and stores the attachment they share if it isn't nil.
+	Insert(ctx context.Context, messages []*OutboxMessage, attachment *OutboxAttachment, now time.Time) error
+	GetAttachment(ctx context.Context, id int64) (*OutboxAttachment, error)
+	// GetDue returns the pending messages due for delivery, the oldest first.
+	GetDue(ctx context.Context, now time.Time, limit int) ([]*OutboxMessage, error)
+	// Claim counts an attempt to deliver the message and postpones its next attempt until the given time,
+	// so that other instances don't deliver it meanwhile. It returns false if the message was claimed already.
+	Claim(ctx context.Context, msg *OutboxMessage, until time.Time, now time.Time) (bool, error)
+	Update(ctx context.Context, msg *OutboxMessage, now time.Time) error
+	Search(ctx context.Context, query *SearchOutboxMessagesQuery) (*SearchOutboxMessagesResult, error)
+	GetRecipientStats(ctx context.Context, query *GetOutboxRecipientStatsQuery) ([]*OutboxRecipientStats, error)
+	// Resend makes failed messages pending again and returns how many were.
+	Resend(ctx context.Context, cmd *ResendOutboxMessagesCommand, now time.Time) (int64, error)
+	DeleteUpdatedBefore(ctx context.Context, status string, before time.Time) (int64, error)
+	// DeleteUnusedAttachments deletes the attachments of the messages which were all delivered or deleted.
+	DeleteUnusedAttachments(ctx context.Context) (int64, error)
+}
+
+type xormOutboxStore struct {
+	db db.DB
+}
+
+func (xs *xormOutboxStore) Insert(ctx context.Context, messages []*OutboxMessage, attachment *OutboxAttachment, now time.Time) error {
+	return xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if attachment != nil {
+			attachment.Created = now.Unix()
+			if _, err := sess.Insert(attachment); err != nil {
+				return err
+			}
+		}
+
+		for _, msg := range messages {
+			if attachment != nil {
+				msg.AttachmentID = attachment.ID
+			}
+			msg.Status = OutboxStatusPending
+			msg.NextAttempt = now.Unix()
+			msg.Created = now.Unix()
+			msg.Updated = now.Unix()
+			if _, err := sess.Insert(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (xs *xormOutboxStore) GetAttachment(ctx context.Context, id int64) (*OutboxAttachment, error) {
+	attachment := &OutboxAttachment{}
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.ID(id).Get(attachment)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return fmt.Errorf("outbox attachment %d not found", id)
+		}
+		return nil
+	})
+	return attachment, err
+}
+
+func (xs *xormOutboxStore) GetDue(ctx context.Context, now time.Time, limit int) ([]*OutboxMessage, error) {
+	result := make([]*OutboxMessage, 0)
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("status = ? AND next_attempt <= ?", OutboxStatusPending, now.Unix()).
+			Asc("next_attempt", "id").
+			Limit(limit).
+			Find(&result)
+	})
+	return result, err
+}
+
+func (xs *xormOutboxStore) Claim(ctx context.Context, msg *OutboxMessage, until time.Time, now time.Time) (bool, error) {
+	var claimed bool
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE notification_outbox SET attempts = ?, next_attempt = ?, updated = ? WHERE id = ? AND status = ? AND attempts = ?",
+			msg.Attempts+1, until.Unix(), now.Unix(), msg.ID, OutboxStatusPending, msg.Attempts)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		claimed = affected == 1
+		return nil
+	})
+	if claimed {
+		msg.Attempts++
+		msg.NextAttempt = until.Unix()
+		msg.Updated = now.Unix()
+	}
+	return claimed, err
+}
+
+func (xs *xormOutboxStore) Update(ctx context.Context, msg *OutboxMessage, now time.Time) error {
+	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		msg.Updated = now.Unix()
+		_, err := sess.ID(msg.ID).Cols("payload", "status", "last_error", "next_attempt", "updated").Update(msg)
+		return err
+	})
+}
+
+func (xs *xormOutboxStore) Search(ctx context.Context, query *SearchOutboxMessagesQuery) (*SearchOutboxMessagesResult, error) {
+	result := &SearchOutboxMessagesResult{
+		Messages: make([]*OutboxMessage, 0),
+		Page:     query.Page,
+		PerPage:  query.Limit,
+	}
+
+	where := make([]string, 0, 3)
+	params := make([]any, 0, 3)
+	if query.Status != "" {
+		where = append(where, "status = ?")
+		params = append(params, query.Status)
+	}
+	if query.Kind != "" {
+		where = append(where, "kind = ?")
+		params = append(params, query.Kind)
+	}
+	if query.Recipient != "" {
+		where = append(where, "recipient "+xs.db.GetDialect().LikeStr()+" ?")
+		params = append(params, "%"+query.Recipient+"%")
+	}
+
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		countSess := sess.Table("notification_outbox")
+		if len(where) > 0 {
+			countSess.Where(strings.Join(where, " AND "), params...)
+		}
+		count, err := countSess.Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		findSess := sess.Table("notification_outbox")
+		if len(where) > 0 {
+			findSess.Where(strings.Join(where, " AND "), params...)
+		}
+		offset := query.Limit * (query.Page - 1)
+		return findSess.Desc("id").Limit(query.Limit, offset).Find(&result.Messages)
+	})
+	return result, err
+}
+
+func (xs *xormOutboxStore) GetRecipientStats(ctx context.Context, query *GetOutboxRecipientStatsQuery) ([]*OutboxRecipientStats, error) {
+	result := make([]*OutboxRecipientStats, 0)
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := `SELECT recipient, kind,
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS pending,
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS sent,
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS failed,
+			SUM(attempts) AS attempts
+			FROM notification_outbox`
+		params := []any{OutboxStatusPending, OutboxStatusSent, OutboxStatusFailed}
+		if query.Recipient != "" {
+			sql += " WHERE recipient " + xs.db.GetDialect().LikeStr() + " ?"
+			params = append(params, "%"+query.Recipient+"%")
+		}
+		sql += " GROUP BY recipient, kind ORDER BY failed DESC, pending DESC, recipient ASC" + xs.db.GetDialect().Limit(int64(query.Limit))
+		return sess.SQL(sql, params...).Find(&result)
+	})
+	return result, err
+}
+
+func (xs *xormOutboxStore) Resend(ctx context.Context, cmd *ResendOutboxMessagesCommand, now time.Time) (int64, error) {
+	var affected int64
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := "UPDATE notification_outbox SET status = ?, attempts = 0, last_error = '', next_attempt = ?, updated = ? WHERE status = ?"
+		params := []any{OutboxStatusPending, now.Unix(), now.Unix(), OutboxStatusFailed}
+		if len(cmd.IDs) > 0 {
+			sql += " AND id IN (?" + strings.Repeat(",?", len(cmd.IDs)-1) + ")"
+			for _, id := range cmd.IDs {
+				params = append(params, id)
+			}
+		}
+		if cmd.Recipient != "" {
+			sql += " AND recipient = ?"
+			params = append(params, cmd.Recipient)
+		}
+
+		res, err := sess.Exec(append([]any{sql}, params...)...)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
+func (xs *xormOutboxStore) DeleteUpdatedBefore(ctx context.Context, status string, before time.Time) (int64, error) {
+	var deletedRows int64
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM notification_outbox WHERE status = ? AND updated < ?", status, before.Unix())
+		if err != nil {
+			return err
+		}
+		deletedRows, err = res.RowsAffected()
+		return err
+	})
+	return deletedRows, err
+}
+
+func (xs *xormOutboxStore) DeleteUnusedAttachments(ctx context.Context) (int64, error) {
+	var deletedRows int64
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM notification_outbox_attachment WHERE id NOT IN (SELECT attachment_id FROM notification_outbox WHERE status <> ?)", OutboxStatusSent)
+		if err != nil {
+			return err
+		}
+		deletedRows, err = res.RowsAffected()
+		return err
+	})
+	return deletedRows, err
+}
@@ -0,0 +1,306 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationNotificationOutbox(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	bus := newBus(t)
+	ctx := context.Background()
+
+	createOutboxSut := func(t *testing.T) (*NotificationService, *FakeMailer, *time.Time) {
+		t.Helper()
+		ns, mailer := createSut(t, bus)
+		withTestOutbox(t, ns)
+		ns.Cfg.NotificationOutbox = setting.NotificationOutboxSettings{
+			MaxAttempts:     3,
+			InitialBackoff:  time.Minute,
+			MaxBackoff:      time.Hour,
+			PollInterval:    time.Second,
+			SentRetention:   24 * time.Hour,
+			FailedRetention: 48 * time.Hour,
+		}
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		ns.now = func() time.Time { return now }
+		return ns, mailer, &now
+	}
+
+	sendEmail := func(t *testing.T, ns *NotificationService, to ...string) {
+		t.Helper()
+		err := ns.SendEmailCommandHandler(ctx, &SendEmailCommand{
+			Subject:  "subject",
+			To:       to,
+			Template: "welcome_on_signup",
+		})
+		require.NoError(t, err)
+	}
+
+	search := func(t *testing.T, ns *NotificationService, query SearchOutboxMessagesQuery) []*OutboxMessage {
+		t.Helper()
+		result, err := ns.SearchOutboxMessages(ctx, &query)
+		require.NoError(t, err)
+		return result.Messages
+	}
+
+	t.Run("Emails are queued once per recipient and delivered", func(t *testing.T) {
+		ns, mailer, _ := createOutboxSut(t)
+		sentBefore := testutil.ToFloat64(outboxDeliveriesTotal.WithLabelValues(OutboxKindEmail, outboxResultSent))
+
+		sendEmail(t, ns, "1@grafana.com", "2@grafana.com", "3@grafana.com")
+		require.Empty(t, mailer.Sent)
+		require.Len(t, search(t, ns, SearchOutboxMessagesQuery{Status: OutboxStatusPending}), 3)
+
+		ns.processOutbox(ctx)
+
+		require.Len(t, mailer.Sent, 3)
+		assert.Equal(t, "subject", mailer.Sent[0].Subject)
+		assert.Equal(t, 3.0, testutil.ToFloat64(outboxDeliveriesTotal.WithLabelValues(OutboxKindEmail, outboxResultSent))-sentBefore)
+
+		messages := search(t, ns, SearchOutboxMessagesQuery{})
+		require.Len(t, messages, 3)
+		for _, msg := range messages {
+			assert.Equal(t, OutboxStatusSent, msg.Status)
+			assert.Equal(t, int64(1), msg.Attempts)
+			assert.Equal(t, "subject", msg.Subject)
+			assert.Empty(t, msg.Payload, "the content of delivered messages should not be kept")
+		}
+		assert.Equal(t, "3@grafana.com", messages[0].Recipient)
+
+		// nothing is delivered twice
+		ns.processOutbox(ctx)
+		require.Len(t, mailer.Sent, 3)
+	})
+
+	t.Run("Emails are encrypted and their attachments are stored once", func(t *testing.T) {
+		ns, mailer, _ := createOutboxSut(t)
+		countAttachments := func(t *testing.T) int64 {
+			t.Helper()
+			var count int64
+			err := ns.outbox.(*xormOutboxStore).db.WithDbSession(ctx, func(sess *db.Session) error {
+				var err error
+				count, err = sess.Count(&OutboxAttachment{})
+				return err
+			})
+			require.NoError(t, err)
+			return count
+		}
+
+		err := ns.SendEmailCommandHandler(ctx, &SendEmailCommand{
+			Subject:       "subject",
+			To:            []string{"1@grafana.com", "2@grafana.com"},
+			Template:      "welcome_on_signup",
+			AttachedFiles: []*SendEmailAttachFile{{Name: "report.pdf", Content: []byte("report")}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), countAttachments(t))
+
+		messages := search(t, ns, SearchOutboxMessagesQuery{Status: OutboxStatusPending})
+		require.Len(t, messages, 2)
+		for _, msg := range messages {
+			assert.NotZero(t, msg.AttachmentID)
+			assert.Equal(t, messages[0].AttachmentID, msg.AttachmentID)
+			payload, err := base64.StdEncoding.DecodeString(msg.Payload)
+			require.NoError(t, err)
+			assert.NotContains(t, string(payload), msg.Recipient)
+		}
+
+		ns.processOutbox(ctx)
+		require.Len(t, mailer.Sent, 2)
+		for _, sent := range mailer.Sent {
+			require.Len(t, sent.AttachedFiles, 1)
+			assert.Equal(t, &AttachedFile{Name: "report.pdf", Content: []byte("report")}, sent.AttachedFiles[0])
+		}
+
+		ns.cleanupOutbox(ctx)
+		assert.Zero(t, countAttachments(t))
+	})
+
+	t.Run("Failed deliveries are retried with backoff until they are marked as failed", func(t *testing.T) {
+		ns, _, now := createOutboxSut(t)
+		ns.mailer = NewFakeDisconnectedMailer()
+
+		sendEmail(t, ns, "1@grafana.com")
+
+		ns.processOutbox(ctx)
+		msg := search(t, ns, SearchOutboxMessagesQuery{})[0]
+		assert.Equal(t, OutboxStatusPending, msg.Status)
+		assert.Equal(t, int64(1), msg.Attempts)
+		assert.Equal(t, "connect: connection refused", msg.LastError)
+		assert.Equal(t, now.Add(time.Minute).Unix(), msg.NextAttempt)
+
+		// not due yet
+		ns.processOutbox(ctx)
+		assert.Equal(t, int64(1), search(t, ns, SearchOutboxMessagesQuery{})[0].Attempts)
+
+		*now = now.Add(time.Minute)
+		ns.processOutbox(ctx)
+		msg = search(t, ns, SearchOutboxMessagesQuery{})[0]
+		assert.Equal(t, int64(2), msg.Attempts)
+		assert.Equal(t, now.Add(2*time.Minute).Unix(), msg.NextAttempt)
+
+		*now = now.Add(2 * time.Minute)
+		ns.processOutbox(ctx)
+		msg = search(t, ns, SearchOutboxMessagesQuery{})[0]
+		assert.Equal(t, OutboxStatusFailed, msg.Status)
+		assert.Equal(t, int64(3), msg.Attempts)
+		assert.NotEmpty(t, msg.Payload, "the content of failed messages should be kept to resend them")
+
+		t.Run("and resent", func(t *testing.T) {
+			mailer := NewFakeMailer()
+			ns.mailer = mailer
+
+			count, err := ns.ResendOutboxMessages(ctx, &ResendOutboxMessagesCommand{IDs: []int64{msg.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+
+			ns.processOutbox(ctx)
+			require.Len(t, mailer.Sent, 1)
+			assert.Equal(t, []string{"1@grafana.com"}, mailer.Sent[0].To)
+
+			msg = search(t, ns, SearchOutboxMessagesQuery{})[0]
+			assert.Equal(t, OutboxStatusSent, msg.Status)
+			assert.Equal(t, int64(1), msg.Attempts)
+
+			// only failed messages are resent
+			count, err = ns.ResendOutboxMessages(ctx, &ResendOutboxMessagesCommand{IDs: []int64{msg.ID}})
+			require.NoError(t, err)
+			assert.Zero(t, count)
+		})
+	})
+
+	t.Run("Messages claimed by another instance are not delivered again", func(t *testing.T) {
+		ns, mailer, now := createOutboxSut(t)
+		sendEmail(t, ns, "1@grafana.com")
+
+		due, err := ns.outbox.GetDue(ctx, *now, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		other := *due[0]
+
+		claimed, err := ns.outbox.Claim(ctx, &other, now.Add(outboxLease), *now)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		assert.False(t, ns.deliverOutboxMessage(ctx, due[0]))
+		ns.processOutbox(ctx)
+		assert.Empty(t, mailer.Sent)
+
+		// the message is delivered again if the other instance doesn't report back
+		*now = now.Add(outboxLease)
+		ns.processOutbox(ctx)
+		assert.Len(t, mailer.Sent, 1)
+	})
+
+	t.Run("Webhooks are queued and delivered", func(t *testing.T) {
+		ns, _, _ := createOutboxSut(t)
+
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received = string(body)
+		}))
+		t.Cleanup(server.Close)
+
+		err := ns.SendWebhookCommandHandler(ctx, &SendWebhookCommand{
+			Url:  server.URL + "/hook",
+			Body: `{"status": "firing"}`,
+		})
+		require.NoError(t, err)
+
+		ns.processOutbox(ctx)
+		assert.Equal(t, `{"status": "firing"}`, received)
+
+		messages := search(t, ns, SearchOutboxMessagesQuery{Kind: OutboxKindWebhook})
+		require.Len(t, messages, 1)
+		assert.Equal(t, OutboxStatusSent, messages[0].Status)
+		assert.Equal(t, server.URL+"/hook", messages[0].Recipient)
+	})
+
+	t.Run("Webhooks with a TLS configuration or a response validation are not queued", func(t *testing.T) {
+		ns, _, _ := createOutboxSut(t)
+
+		err := ns.enqueueWebhook(ctx, &Webhook{Url: "https://example.com/hook", TLSConfig: &tls.Config{}})
+		require.Error(t, err)
+		err = ns.enqueueWebhook(ctx, &Webhook{Url: "https://example.com/hook", Validation: func([]byte, int) error { return nil }})
+		require.Error(t, err)
+		assert.Empty(t, search(t, ns, SearchOutboxMessagesQuery{Kind: OutboxKindWebhook}))
+	})
+
+	t.Run("Statistics are counted per recipient", func(t *testing.T) {
+		ns, _, _ := createOutboxSut(t)
+		ns.Cfg.NotificationOutbox.MaxAttempts = 1
+
+		sendEmail(t, ns, "1@grafana.com", "2@grafana.com")
+		ns.processOutbox(ctx)
+		ns.mailer = NewFakeDisconnectedMailer()
+		sendEmail(t, ns, "2@grafana.com")
+		ns.processOutbox(ctx)
+		sendEmail(t, ns, "2@grafana.com")
+
+		stats, err := ns.GetOutboxRecipientStats(ctx, &GetOutboxRecipientStatsQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, []*OutboxRecipientStats{
+			{Recipient: "2@grafana.com", Kind: OutboxKindEmail, Pending: 1, Sent: 1, Failed: 1, Attempts: 2},
+			{Recipient: "1@grafana.com", Kind: OutboxKindEmail, Sent: 1, Attempts: 1},
+		}, stats)
+
+		assert.Len(t, search(t, ns, SearchOutboxMessagesQuery{Recipient: "2@"}), 3)
+		assert.Len(t, search(t, ns, SearchOutboxMessagesQuery{Recipient: "2@", Status: OutboxStatusFailed}), 1)
+
+		count, err := ns.ResendOutboxMessages(ctx, &ResendOutboxMessagesCommand{Recipient: "2@grafana.com"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Old messages are deleted after their retention", func(t *testing.T) {
+		ns, _, now := createOutboxSut(t)
+		ns.Cfg.NotificationOutbox.MaxAttempts = 1
+
+		sendEmail(t, ns, "1@grafana.com")
+		ns.processOutbox(ctx)
+		ns.mailer = NewFakeDisconnectedMailer()
+		sendEmail(t, ns, "2@grafana.com")
+		ns.processOutbox(ctx)
+
+		*now = now.Add(25 * time.Hour)
+		ns.cleanupOutbox(ctx)
+		messages := search(t, ns, SearchOutboxMessagesQuery{})
+		require.Len(t, messages, 1)
+		assert.Equal(t, OutboxStatusFailed, messages[0].Status)
+
+		*now = now.Add(24 * time.Hour)
+		ns.cleanupOutbox(ctx)
+		assert.Empty(t, search(t, ns, SearchOutboxMessagesQuery{}))
+	})
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	ns := &NotificationService{Cfg: setting.NewCfg()}
+	ns.Cfg.NotificationOutbox.InitialBackoff = 30 * time.Second
+	ns.Cfg.NotificationOutbox.MaxBackoff = 5 * time.Minute
+
+	backoffs := make([]time.Duration, 0)
+	for attempts := int64(1); attempts <= 6; attempts++ {
+		backoffs = append(backoffs, ns.outboxBackoff(attempts))
+	}
+	assert.Equal(t, []time.Duration{
+		30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute,
+	}, backoffs)
+}
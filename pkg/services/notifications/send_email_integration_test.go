@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -21,8 +22,9 @@ func TestEmailIntegrationTest(t *testing.T) {
 		cfg.Smtp.FromAddress = "from@address.com"
 		cfg.Smtp.FromName = "Grafana Admin"
 		cfg.Smtp.ContentTypes = []string{"text/html", "text/plain"}
-		ns, err := ProvideService(newBus(t), cfg, NewFakeMailer(), nil)
+		ns, err := ProvideService(newBus(t), cfg, NewFakeMailer(), nil, nil, fakes.NewFakeSecretsService())
 		require.NoError(t, err)
+		withFakeOutbox(ns)
 
 		t.Run("When sending reset email password", func(t *testing.T) {
 			cmd := &SendEmailCommand{
@@ -56,7 +58,9 @@ func TestEmailIntegrationTest(t *testing.T) {
 			err := ns.SendEmailCommandHandler(context.Background(), cmd)
 			require.NoError(t, err)
 
-			sentMsg := <-ns.mailQueue
+			queued := queuedEmails(t, ns)
+			require.Len(t, queued, 1)
+			sentMsg := queued[0]
 			require.Equal(t, "\"Grafana Admin\" <from@address.com>", sentMsg.From)
 			require.Equal(t, "asdf@asdf.com", sentMsg.To[0])
 			require.Equal(t, "[CRITICAL] Imaginary timeseries alert", sentMsg.Subject)
@@ -23,11 +23,11 @@ type Webhook struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
-	TLSConfig   *tls.Config
+	TLSConfig   *tls.Config `json:"-"`
 
 	// Validation is a function that will validate the response body and statusCode of the webhook. Any returned error will cause the webhook request to be considered failed.
 	// This can be useful when a webhook service communicates failures in creative ways, such as using the response body instead of the status code.
-	Validation func(body []byte, statusCode int) error
+	Validation func(body []byte, statusCode int) error `json:"-"`
 }
 
 // WebhookClient exists to mock the client in tests.
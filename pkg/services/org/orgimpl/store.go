@@ -245,6 +245,7 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM team_role WHERE org_id = ?",
 			"DELETE FROM user_role WHERE org_id = ?",
 			"DELETE FROM builtin_role WHERE org_id = ?",
+			"DELETE FROM org_mfa_policy WHERE org_id = ?",
 		}
 
 		// Add registered deletes
@@ -819,6 +820,9 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_mfa_totp WHERE user_id = ?",
+		"DELETE FROM user_mfa_recovery_code WHERE user_id = ?",
+		"DELETE FROM user_mfa_webauthn WHERE user_id = ?",
 	}
 	return deletes
 }
@@ -0,0 +1,193 @@
+package accesscontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+// Provision scans a directory for provisioning config files
+// and provisions the custom roles and team assignments in those files.
+func Provision(ctx context.Context, configDirectory string, roleService ac.CustomRoleService, teamService team.Service) error {
+	logger := log.New("provisioning.accesscontrol")
+	p := AccessControlProvisioner{
+		log:         logger,
+		cfgProvider: newConfigReader(logger),
+		roleService: roleService,
+		teamService: teamService,
+	}
+	return p.applyChanges(ctx, configDirectory)
+}
+
+// AccessControlProvisioner is responsible for provisioning custom roles and their
+// assignments to teams based on configuration read by the `configReader`
+type AccessControlProvisioner struct {
+	log         log.Logger
+	cfgProvider configReader
+	roleService ac.CustomRoleService
+	teamService team.Service
+}
+
+func (p *AccessControlProvisioner) apply(ctx context.Context, cfg *accessControlAsConfig) error {
+	for _, role := range cfg.Roles {
+		if err := p.applyRole(ctx, role); err != nil {
+			return fmt.Errorf("failed to provision role %s: %w", role.UID, err)
+		}
+	}
+
+	for _, t := range cfg.Teams {
+		if err := p.applyTeam(ctx, t); err != nil {
+			return fmt.Errorf("failed to provision roles of team %s: %w", t.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *AccessControlProvisioner) applyRole(ctx context.Context, role *roleFromConfig) error {
+	if role.State == stateAbsent {
+		p.log.Info("Deleting role from configuration", "uid", role.UID, "orgId", role.OrgID)
+		err := p.roleService.DeleteCustomRole(ctx, role.OrgID, role.UID)
+		if errors.Is(err, ac.ErrCustomRoleNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	cmd := ac.SaveCustomRoleCommand{
+		UID:         role.UID,
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Group:       role.Group,
+		Permissions: role.Permissions,
+	}
+
+	stored, err := p.roleService.GetCustomRole(ctx, role.OrgID, role.UID)
+	if err != nil {
+		if !errors.Is(err, ac.ErrCustomRoleNotFound) {
+			return err
+		}
+		p.log.Info("Creating role from configuration", "uid", role.UID, "name", role.Name, "orgId", role.OrgID)
+		_, err = p.roleService.CreateCustomRole(ctx, role.OrgID, cmd)
+		return err
+	}
+
+	// Only update roles that changed, an update bumps the version and clears the permission caches
+	if sameRole(stored, cmd) {
+		return nil
+	}
+	p.log.Info("Updating role from configuration", "uid", role.UID, "name", role.Name, "orgId", role.OrgID)
+	cmd.Version = stored.Version
+	_, err = p.roleService.UpdateCustomRole(ctx, role.OrgID, cmd)
+	return err
+}
+
+func (p *AccessControlProvisioner) applyTeam(ctx context.Context, t *teamFromConfig) error {
+	teamID, err := p.getTeamID(ctx, t.OrgID, t.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range t.Roles {
+		uid, err := p.getRoleUID(ctx, role)
+		if err != nil {
+			return err
+		}
+
+		if role.State == stateAbsent {
+			p.log.Info("Removing role from team", "team", t.Name, "role", uid, "orgId", t.OrgID)
+			err = p.roleService.UnassignCustomRoleFromTeam(ctx, t.OrgID, teamID, uid)
+		} else {
+			p.log.Info("Assigning role to team", "team", t.Name, "role", uid, "orgId", t.OrgID)
+			err = p.roleService.AssignCustomRoleToTeam(ctx, t.OrgID, teamID, uid)
+		}
+		if err != nil && !errors.Is(err, ac.ErrCustomRoleNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *AccessControlProvisioner) getTeamID(ctx context.Context, orgID int64, name string) (int64, error) {
+	result, err := p.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID: orgID,
+		Name:  name,
+		Limit: 1,
+		SignedInUser: ac.BackgroundUser("access_control_provisioner", orgID, org.RoleAdmin, []ac.Permission{
+			{Action: ac.ActionTeamsRead, Scope: ac.ScopeTeamsAll},
+		}),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Teams) == 0 {
+		return 0, team.ErrTeamNotFound
+	}
+	return result.Teams[0].ID, nil
+}
+
+func (p *AccessControlProvisioner) getRoleUID(ctx context.Context, role *teamRoleFromConfig) (string, error) {
+	if role.UID != "" {
+		return role.UID, nil
+	}
+
+	roles, err := p.roleService.ListCustomRoles(ctx, role.OrgID)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range roles {
+		if r.Name == role.Name {
+			return r.UID, nil
+		}
+	}
+	return "", ac.ErrCustomRoleNotFound.Errorf("role %s not found", role.Name)
+}
+
+func (p *AccessControlProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := p.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range configs {
+		if err := p.apply(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameRole(stored *ac.RoleDTO, cmd ac.SaveCustomRoleCommand) bool {
+	if stored.Name != cmd.Name || stored.DisplayName != cmd.DisplayName ||
+		stored.Description != cmd.Description || stored.Group != cmd.Group {
+		return false
+	}
+
+	type key struct{ action, scope string }
+	toKeys := func(permissions []ac.Permission) []key {
+		keys := make([]key, 0, len(permissions))
+		for _, p := range permissions {
+			if k := (key{p.Action, p.Scope}); !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+		slices.SortFunc(keys, func(a, b key) int {
+			if a.action != b.action {
+				return strings.Compare(a.action, b.action)
+			}
+			return strings.Compare(a.scope, b.scope)
+		})
+		return keys
+	}
+	return slices.Equal(toKeys(stored.Permissions), toKeys(cmd.Permissions))
+}
@@ -0,0 +1,156 @@
+package accesscontrol
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+func TestAccessControlProvisioner(t *testing.T) {
+	editor := &roleFromConfig{
+		OrgID:       2,
+		UID:         "alerteditor",
+		Name:        "custom:alert:editor",
+		State:       statePresent,
+		Permissions: []ac.Permission{{Action: "alert.rules:write", Scope: "folders:*"}},
+	}
+
+	t.Run("Should create, update and delete roles", func(t *testing.T) {
+		roles := &fakeRoleService{roles: map[string]*ac.RoleDTO{
+			"reader": {UID: "reader", Name: "custom:reader", Version: 3},
+			"old":    {UID: "old", Name: "custom:old"},
+		}}
+		reader := &roleFromConfig{OrgID: 2, UID: "reader", Name: "custom:reader", State: statePresent,
+			Permissions: []ac.Permission{{Action: "alert.rules:read", Scope: "folders:*"}}}
+
+		p := newTestProvisioner(roles, &accessControlAsConfig{Roles: []*roleFromConfig{
+			editor,
+			reader,
+			{OrgID: 2, UID: "old", State: stateAbsent},
+			{OrgID: 2, UID: "missing", State: stateAbsent},
+		}})
+		require.NoError(t, p.applyChanges(context.Background(), ""))
+		require.Equal(t, []string{"create alerteditor", "update reader 3", "delete old", "delete missing"}, roles.calls)
+	})
+
+	t.Run("Should not update roles that did not change", func(t *testing.T) {
+		roles := &fakeRoleService{roles: map[string]*ac.RoleDTO{
+			"alerteditor": {UID: "alerteditor", Name: "custom:alert:editor", Permissions: editor.Permissions},
+		}}
+
+		p := newTestProvisioner(roles, &accessControlAsConfig{Roles: []*roleFromConfig{editor}})
+		require.NoError(t, p.applyChanges(context.Background(), ""))
+		require.Empty(t, roles.calls)
+	})
+
+	t.Run("Should assign roles to teams", func(t *testing.T) {
+		roles := &fakeRoleService{roles: map[string]*ac.RoleDTO{
+			"alerteditor": {UID: "alerteditor", Name: "custom:alert:editor", Permissions: editor.Permissions},
+		}}
+
+		p := newTestProvisioner(roles, &accessControlAsConfig{Teams: []*teamFromConfig{{
+			OrgID: 2,
+			Name:  "Alert editors",
+			Roles: []*teamRoleFromConfig{
+				{OrgID: 2, Name: "custom:alert:editor", State: statePresent},
+				{OrgID: 2, UID: "other", State: stateAbsent},
+			},
+		}}})
+		require.NoError(t, p.applyChanges(context.Background(), ""))
+		require.Equal(t, []string{"assign alerteditor to 7", "unassign other from 7"}, roles.calls)
+	})
+
+	t.Run("Should fail when the team does not exist", func(t *testing.T) {
+		p := newTestProvisioner(&fakeRoleService{}, &accessControlAsConfig{Teams: []*teamFromConfig{{
+			OrgID: 2,
+			Name:  "Unknown",
+			Roles: []*teamRoleFromConfig{{OrgID: 2, UID: "alerteditor", State: statePresent}},
+		}}})
+		require.ErrorIs(t, p.applyChanges(context.Background(), ""), team.ErrTeamNotFound)
+	})
+}
+
+func newTestProvisioner(roles *fakeRoleService, cfg *accessControlAsConfig) *AccessControlProvisioner {
+	return &AccessControlProvisioner{
+		log:         log.NewNopLogger(),
+		cfgProvider: fakeConfigReader{cfg: cfg},
+		roleService: roles,
+		teamService: fakeTeamService{teams: map[string]int64{"Alert editors": 7}},
+	}
+}
+
+type fakeConfigReader struct {
+	cfg *accessControlAsConfig
+}
+
+func (f fakeConfigReader) readConfig(string) ([]*accessControlAsConfig, error) {
+	return []*accessControlAsConfig{f.cfg}, nil
+}
+
+type fakeTeamService struct {
+	team.Service
+	teams map[string]int64
+}
+
+func (f fakeTeamService) SearchTeams(_ context.Context, query *team.SearchTeamsQuery) (team.SearchTeamQueryResult, error) {
+	result := team.SearchTeamQueryResult{Teams: []*team.TeamDTO{}}
+	if id, ok := f.teams[query.Name]; ok {
+		result.Teams = append(result.Teams, &team.TeamDTO{ID: id, Name: query.Name, OrgID: query.OrgID})
+	}
+	return result, nil
+}
+
+type fakeRoleService struct {
+	ac.CustomRoleService
+	roles map[string]*ac.RoleDTO
+	calls []string
+}
+
+func (f *fakeRoleService) ListCustomRoles(context.Context, int64) ([]*ac.RoleDTO, error) {
+	roles := make([]*ac.RoleDTO, 0, len(f.roles))
+	for _, r := range f.roles {
+		roles = append(roles, r)
+	}
+	return roles, nil
+}
+
+func (f *fakeRoleService) GetCustomRole(_ context.Context, _ int64, uid string) (*ac.RoleDTO, error) {
+	if r, ok := f.roles[uid]; ok {
+		return r, nil
+	}
+	return nil, ac.ErrCustomRoleNotFound.Errorf("role %s not found", uid)
+}
+
+func (f *fakeRoleService) CreateCustomRole(_ context.Context, _ int64, cmd ac.SaveCustomRoleCommand) (*ac.RoleDTO, error) {
+	f.calls = append(f.calls, "create "+cmd.UID)
+	return &ac.RoleDTO{UID: cmd.UID}, nil
+}
+
+func (f *fakeRoleService) UpdateCustomRole(_ context.Context, _ int64, cmd ac.SaveCustomRoleCommand) (*ac.RoleDTO, error) {
+	f.calls = append(f.calls, fmt.Sprintf("update %s %d", cmd.UID, cmd.Version))
+	return &ac.RoleDTO{UID: cmd.UID}, nil
+}
+
+func (f *fakeRoleService) DeleteCustomRole(_ context.Context, _ int64, uid string) error {
+	f.calls = append(f.calls, "delete "+uid)
+	if _, ok := f.roles[uid]; !ok {
+		return ac.ErrCustomRoleNotFound.Errorf("role %s not found", uid)
+	}
+	return nil
+}
+
+func (f *fakeRoleService) AssignCustomRoleToTeam(_ context.Context, _, teamID int64, uid string) error {
+	f.calls = append(f.calls, fmt.Sprintf("assign %s to %d", uid, teamID))
+	return nil
+}
+
+func (f *fakeRoleService) UnassignCustomRoleFromTeam(_ context.Context, _, teamID int64, uid string) error {
+	f.calls = append(f.calls, fmt.Sprintf("unassign %s from %d", uid, teamID))
+	return nil
+}
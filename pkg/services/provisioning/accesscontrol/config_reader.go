@@ -0,0 +1,141 @@
+package accesscontrol
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+type configReader interface {
+	readConfig(path string) ([]*accessControlAsConfig, error)
+}
+
+type configReaderImpl struct {
+	log log.Logger
+}
+
+func newConfigReader(logger log.Logger) configReader {
+	return &configReaderImpl{log: logger}
+}
+
+func (cr *configReaderImpl) readConfig(path string) ([]*accessControlAsConfig, error) {
+	var configs []*accessControlAsConfig
+	cr.log.Debug("Looking for access control provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read access control provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing access control provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating access control configuration")
+	if err := validateConfigs(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReaderImpl) parseConfig(path string, file fs.DirEntry) (*accessControlAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *accessControlAsConfigV2
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToAccessControlFromConfig(), nil
+}
+
+// validateConfigs checks the configuration can be applied in OSS and sets the defaults
+func validateConfigs(configs []*accessControlAsConfig) error {
+	errs := []error{}
+	for _, cfg := range configs {
+		for index, role := range cfg.Roles {
+			if role.State == "" {
+				role.State = statePresent
+			}
+			if role.OrgID < 1 {
+				role.OrgID = 1
+			}
+
+			switch {
+			case role.State != statePresent && role.State != stateAbsent:
+				errs = append(errs, fmt.Errorf("role item %d in configuration has an invalid state %q", index+1, role.State))
+			case role.Global:
+				errs = append(errs, fmt.Errorf("role item %d in configuration is global, only organization roles are supported", index+1))
+			case role.From > 0:
+				errs = append(errs, fmt.Errorf("role item %d in configuration copies permissions from other roles, which is not supported", index+1))
+			case role.Name == "" && (role.State == statePresent || role.UID == ""):
+				errs = append(errs, fmt.Errorf("role item %d in configuration doesn't contain required field name", index+1))
+			case role.Name != "" && !strings.HasPrefix(role.Name, ac.CustomRolePrefix):
+				errs = append(errs, fmt.Errorf("role item %d in configuration should be prefixed with %q", index+1, ac.CustomRolePrefix))
+			case role.UID == "":
+				role.UID = ac.PrefixedRoleUID(role.Name)
+			}
+		}
+
+		for index, team := range cfg.Teams {
+			if team.OrgID < 1 {
+				team.OrgID = 1
+			}
+			if team.Name == "" {
+				errs = append(errs, fmt.Errorf("team item %d in configuration doesn't contain required field name", index+1))
+			}
+
+			for _, role := range team.Roles {
+				if role.State == "" {
+					role.State = statePresent
+				}
+				if role.OrgID < 1 {
+					role.OrgID = team.OrgID
+				}
+
+				switch {
+				case role.State != statePresent && role.State != stateAbsent:
+					errs = append(errs, fmt.Errorf("team item %d in configuration has a role with an invalid state %q", index+1, role.State))
+				case role.Global:
+					errs = append(errs, fmt.Errorf("team item %d in configuration assigns a global role, only organization roles are supported", index+1))
+				case role.OrgID != team.OrgID:
+					errs = append(errs, fmt.Errorf("team item %d in configuration assigns a role from another organization", index+1))
+				case role.UID == "" && role.Name == "":
+					errs = append(errs, fmt.Errorf("team item %d in configuration has a role without uid or name", index+1))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,78 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	incorrectSettings = "./testdata/test-configs/incorrect-settings"
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+	correctProperties = "./testdata/test-configs/correct-properties"
+)
+
+func TestConfigReader(t *testing.T) {
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Skip invalid directory", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+
+	t.Run("Read incorrect properties", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(incorrectSettings)
+		require.Error(t, err)
+		require.Equal(t, "role item 1 in configuration should be prefixed with \"custom:\"\n"+
+			"role item 2 in configuration is global, only organization roles are supported", err.Error())
+	})
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		t.Setenv("ROLE_NAME_VAR", "dashboards:reader")
+
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		roles := cfg[0].Roles
+		require.Len(t, roles, 3)
+		require.Equal(t, &roleFromConfig{
+			OrgID:       2,
+			UID:         "alerteditor",
+			Name:        "custom:alert:editor",
+			Description: "Edit alert rules without editing dashboards",
+			State:       statePresent,
+			Permissions: []ac.Permission{
+				{Action: "alert.rules:read", Scope: "folders:*"},
+				{Action: "alert.rules:write", Scope: "folders:*"},
+			},
+		}, roles[0])
+		require.Equal(t, "custom:dashboards:reader", roles[1].Name)
+		require.Equal(t, ac.PrefixedRoleUID("custom:dashboards:reader"), roles[1].UID)
+		require.Equal(t, int64(1), roles[1].OrgID)
+		require.Equal(t, stateAbsent, roles[2].State)
+
+		teams := cfg[0].Teams
+		require.Len(t, teams, 1)
+		require.Equal(t, &teamFromConfig{
+			OrgID: 2,
+			Name:  "Alert editors",
+			Roles: []*teamRoleFromConfig{
+				{OrgID: 2, UID: "alerteditor", State: statePresent},
+				{OrgID: 2, Name: "custom:other", State: stateAbsent},
+			},
+		}, teams[0])
+	})
+}
@@ -0,0 +1,139 @@
+package accesscontrol
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+const (
+	statePresent = "present"
+	stateAbsent  = "absent"
+)
+
+// accessControlAsConfig is a normalized data object for access control config data. Any config version should be mappable
+// to this type.
+type accessControlAsConfig struct {
+	Roles []*roleFromConfig
+	Teams []*teamFromConfig
+}
+
+type roleFromConfig struct {
+	OrgID       int64
+	Global      bool
+	UID         string
+	Name        string
+	DisplayName string
+	Description string
+	Group       string
+	State       string
+	From        int
+	Permissions []ac.Permission
+}
+
+type teamFromConfig struct {
+	OrgID int64
+	Name  string
+	Roles []*teamRoleFromConfig
+}
+
+type teamRoleFromConfig struct {
+	OrgID  int64
+	Global bool
+	UID    string
+	Name   string
+	State  string
+}
+
+type permissionFromConfigV2 struct {
+	Action values.StringValue `json:"action" yaml:"action"`
+	Scope  values.StringValue `json:"scope" yaml:"scope"`
+	State  values.StringValue `json:"state" yaml:"state"`
+}
+
+type roleFromConfigV2 struct {
+	OrgID       values.Int64Value         `json:"orgId" yaml:"orgId"`
+	Global      values.BoolValue          `json:"global" yaml:"global"`
+	UID         values.StringValue        `json:"uid" yaml:"uid"`
+	Name        values.StringValue        `json:"name" yaml:"name"`
+	DisplayName values.StringValue        `json:"displayName" yaml:"displayName"`
+	Description values.StringValue        `json:"description" yaml:"description"`
+	Group       values.StringValue        `json:"group" yaml:"group"`
+	State       values.StringValue        `json:"state" yaml:"state"`
+	From        []map[string]any          `json:"from" yaml:"from"`
+	Permissions []*permissionFromConfigV2 `json:"permissions" yaml:"permissions"`
+}
+
+type teamRoleFromConfigV2 struct {
+	OrgID  values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Global values.BoolValue   `json:"global" yaml:"global"`
+	UID    values.StringValue `json:"uid" yaml:"uid"`
+	Name   values.StringValue `json:"name" yaml:"name"`
+	State  values.StringValue `json:"state" yaml:"state"`
+}
+
+type teamFromConfigV2 struct {
+	OrgID values.Int64Value       `json:"orgId" yaml:"orgId"`
+	Name  values.StringValue      `json:"name" yaml:"name"`
+	Roles []*teamRoleFromConfigV2 `json:"roles" yaml:"roles"`
+}
+
+// accessControlAsConfigV2 is a mapping for the version 2 configs. This is mapped to its normalised version.
+type accessControlAsConfigV2 struct {
+	APIVersion values.Int64Value   `json:"apiVersion" yaml:"apiVersion"`
+	Roles      []*roleFromConfigV2 `json:"roles" yaml:"roles"`
+	Teams      []*teamFromConfigV2 `json:"teams" yaml:"teams"`
+}
+
+// mapToAccessControlFromConfig maps config syntax to a normalized accessControlAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *accessControlAsConfigV2) mapToAccessControlFromConfig() *accessControlAsConfig {
+	r := &accessControlAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, role := range cfg.Roles {
+		permissions := make([]ac.Permission, 0, len(role.Permissions))
+		for _, p := range role.Permissions {
+			// Permissions marked as absent only make sense on top of copied roles
+			if p.State.Value() == stateAbsent {
+				continue
+			}
+			permissions = append(permissions, ac.Permission{Action: p.Action.Value(), Scope: p.Scope.Value()})
+		}
+
+		r.Roles = append(r.Roles, &roleFromConfig{
+			OrgID:       role.OrgID.Value(),
+			Global:      role.Global.Value(),
+			UID:         role.UID.Value(),
+			Name:        role.Name.Value(),
+			DisplayName: role.DisplayName.Value(),
+			Description: role.Description.Value(),
+			Group:       role.Group.Value(),
+			State:       role.State.Value(),
+			From:        len(role.From),
+			Permissions: permissions,
+		})
+	}
+
+	for _, team := range cfg.Teams {
+		roles := make([]*teamRoleFromConfig, 0, len(team.Roles))
+		for _, role := range team.Roles {
+			roles = append(roles, &teamRoleFromConfig{
+				OrgID:  role.OrgID.Value(),
+				Global: role.Global.Value(),
+				UID:    role.UID.Value(),
+				Name:   role.Name.Value(),
+				State:  role.State.Value(),
+			})
+		}
+
+		r.Teams = append(r.Teams, &teamFromConfig{
+			OrgID: team.OrgID.Value(),
+			Name:  team.Name.Value(),
+			Roles: roles,
+		})
+	}
+
+	return r
+}
@@ -2,9 +2,13 @@ package dashboards
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -21,10 +25,23 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	GetStatus() []ProvisionerStatus
+	WriteBackDashboard(ctx context.Context, cmd *WriteBackDashboardCommand) error
+}
+
+// WriteBackDashboardCommand is used to write a dashboard edited in the UI back to the source of its provider
+type WriteBackDashboardCommand struct {
+	// Name of the provider and ExternalID of the dashboard, as stored in the provisioning data of the dashboard
+	Name       string
+	ExternalID string
+	Dashboard  *simplejson.Json
+	// Author is the name of the user who edited the dashboard
+	Author  string
+	Message string
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
-type DashboardProvisionerFactory func(context.Context, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
+type DashboardProvisionerFactory func(context.Context, string, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
 
 // Provisioner is responsible for syncing dashboard from disk to Grafana's database.
 type Provisioner struct {
@@ -39,8 +56,8 @@ func (provider *Provisioner) HasDashboardSources() bool {
 	return len(provider.fileReaders) > 0
 }
 
-// New returns a new DashboardProvisioner
-func New(ctx context.Context, configDirectory string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
+// New returns a new DashboardProvisioner. Git providers clone their repository in dataPath.
+func New(ctx context.Context, configDirectory string, dataPath string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
 	logger := log.New("provisioning.dashboard")
 	cfgReader := &configReader{path: configDirectory, log: logger, orgService: orgService}
 	configs, err := cfgReader.readConfig(ctx)
@@ -48,7 +65,7 @@ func New(ctx context.Context, configDirectory string, provisioner dashboards.Das
 		return nil, fmt.Errorf("%v: %w", "Failed to read dashboards config", err)
 	}
 
-	fileReaders, err := getFileReaders(configs, logger, provisioner, dashboardStore, folderService)
+	fileReaders, err := getFileReaders(configs, dataPath, logger, provisioner, dashboardStore, folderService)
 	if err != nil {
 		return nil, fmt.Errorf("%v: %w", "Failed to initialize file readers", err)
 	}
@@ -71,6 +88,11 @@ func (provider *Provisioner) Provision(ctx context.Context) error {
 
 	for _, reader := range provider.fileReaders {
 		if err := reader.walkDisk(ctx); err != nil {
+			if errors.Is(err, ErrGitSyncFailed) {
+				// the repository can be unreachable for a while, the last checkout is kept and the sync is retried when polling
+				provider.log.Warn("Failed to provision config", "name", reader.Cfg.Name, "error", err)
+				continue
+			}
 			if os.IsNotExist(err) {
 				// don't stop the provisioning service in case the folder is missing. The folder can appear after the startup
 				provider.log.Warn("Failed to provision config", "name", reader.Cfg.Name, "error", err)
@@ -130,8 +152,59 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// GetStatus returns the result of the last sync of every provider
+func (provider *Provisioner) GetStatus() []ProvisionerStatus {
+	statuses := make([]ProvisionerStatus, 0, len(provider.fileReaders))
+	for _, reader := range provider.fileReaders {
+		statuses = append(statuses, reader.getStatus())
+	}
+	return statuses
+}
+
+// WriteBackDashboard commits the dashboard to the write back branch of the git provider it was provisioned by.
+// Providers without write back are ignored.
+func (provider *Provisioner) WriteBackDashboard(ctx context.Context, cmd *WriteBackDashboardCommand) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name != cmd.Name {
+			continue
+		}
+		if reader.git == nil || reader.git.writeBackBranch == "" {
+			return nil
+		}
+
+		path, err := filepath.Rel(reader.git.dir, cmd.ExternalID)
+		if err != nil || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("dashboard file %s is not part of the git repository", cmd.ExternalID)
+		}
+
+		// the ids are specific to the Grafana instance, the files are identified by uid
+		encoded, err := cmd.Dashboard.Encode()
+		if err != nil {
+			return err
+		}
+		data, err := simplejson.NewJson(encoded)
+		if err != nil {
+			return err
+		}
+		data.Del("id")
+		content, err := encodeDashboardFile(path, data)
+		if err != nil {
+			return err
+		}
+
+		commit, err := reader.git.commitFile(ctx, path, content, cmd.Message, cmd.Author)
+		if err != nil {
+			return err
+		}
+		provider.log.Info("Wrote dashboard back to git repository", "name", cmd.Name, "file", path, "branch", reader.git.writeBackBranch, "commit", commit)
+		return nil
+	}
+	return nil
+}
+
 func getFileReaders(
 	configs []*config,
+	dataPath string,
 	logger log.Logger,
 	service dashboards.DashboardProvisioningService,
 	store utils.DashboardStore,
@@ -153,6 +226,19 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(
+				config,
+				dataPath,
+				logger.New("type", config.Type, "name", config.Name),
+				service,
+				store,
+				folderService,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -8,6 +8,8 @@ type calls struct {
 	PollChanges                 []any
 	GetProvisionerResolvedPath  []any
 	GetAllowUIUpdatesFromConfig []any
+	GetStatus                   []any
+	WriteBackDashboard          []any
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
@@ -17,6 +19,8 @@ type ProvisionerMock struct {
 	PollChangesFunc                 func(ctx context.Context)
 	GetProvisionerResolvedPathFunc  func(name string) string
 	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	GetStatusFunc                   func() []ProvisionerStatus
+	WriteBackDashboardFunc          func(ctx context.Context, cmd *WriteBackDashboardCommand) error
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -67,3 +71,21 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// GetStatus is a mock implementation of `Provisioner.GetStatus`
+func (dpm *ProvisionerMock) GetStatus() []ProvisionerStatus {
+	dpm.Calls.GetStatus = append(dpm.Calls.GetStatus, nil)
+	if dpm.GetStatusFunc != nil {
+		return dpm.GetStatusFunc()
+	}
+	return nil
+}
+
+// WriteBackDashboard is a mock implementation of `Provisioner.WriteBackDashboard`
+func (dpm *ProvisionerMock) WriteBackDashboard(ctx context.Context, cmd *WriteBackDashboardCommand) error {
+	dpm.Calls.WriteBackDashboard = append(dpm.Calls.WriteBackDashboard, cmd)
+	if dpm.WriteBackDashboardFunc != nil {
+		return dpm.WriteBackDashboardFunc(ctx, cmd)
+	}
+	return nil
+}
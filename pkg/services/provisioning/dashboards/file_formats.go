@@ -0,0 +1,230 @@
+package dashboards
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/go-jsonnet"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	formatJSON    = "json"
+	formatYAML    = "yaml"
+	formatJsonnet = "jsonnet"
+)
+
+// formatExtensions are the extensions of the files provisioned as dashboards for every format. Jsonnet
+// libraries (.libsonnet) are only imported by the dashboards and are not provisioned on their own.
+var formatExtensions = map[string][]string{
+	formatJSON:    {".json"},
+	formatYAML:    {".yaml", ".yml"},
+	formatJsonnet: {".jsonnet"},
+}
+
+// newFileExtensions returns the extensions of the dashboard files of the formats enabled by the
+// provider. Only JSON dashboards are read by default, provisioning directories often contain
+// other YAML or Jsonnet files.
+func newFileExtensions(cfg *config) ([]string, error) {
+	formats, ok := cfg.Options["formats"]
+	if !ok {
+		return formatExtensions[formatJSON], nil
+	}
+
+	list, ok := formats.([]any)
+	if !ok {
+		return nil, fmt.Errorf("formats should be a list of file formats")
+	}
+
+	extensions := make([]string, 0, len(list))
+	for _, f := range list {
+		format, _ := f.(string)
+		formatExts, ok := formatExtensions[strings.ToLower(format)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported dashboard file format %v, supported formats are json, yaml and jsonnet", f)
+		}
+		extensions = append(extensions, formatExts...)
+	}
+
+	return extensions, nil
+}
+
+// jsonnetConfig holds the options used to render the Jsonnet dashboards of a provider
+type jsonnetConfig struct {
+	libraryPaths []string
+}
+
+func newJsonnetConfig(cfg *config) (*jsonnetConfig, error) {
+	jc := &jsonnetConfig{}
+
+	options, ok := cfg.Options["jsonnet"].(map[string]any)
+	if !ok {
+		return jc, nil
+	}
+
+	if paths, ok := options["libraryPaths"]; ok {
+		list, ok := paths.([]any)
+		if !ok {
+			return nil, fmt.Errorf("jsonnet libraryPaths should be a list of paths")
+		}
+		for _, p := range list {
+			path, ok := p.(string)
+			if !ok || path == "" {
+				return nil, fmt.Errorf("jsonnet libraryPaths should be a list of paths")
+			}
+			jc.libraryPaths = append(jc.libraryPaths, path)
+		}
+	}
+
+	return jc, nil
+}
+
+func isDashboardFile(name string, extensions []string) bool {
+	return slices.Contains(extensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// parseDashboardFile returns the dashboard defined by the content of the file at path, and the content the
+// checksum of the file is computed from. Jsonnet files are rendered first, so the checksum changes when
+// one of the libraries they import changes.
+func (fr *FileReader) parseDashboardFile(path string, content []byte) (*simplejson.Json, []byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err := parseYAMLDashboard(content)
+		return data, content, err
+	case ".jsonnet":
+		rendered, err := fr.renderJsonnet(path, content)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := parseJSONDashboard(rendered)
+		return data, rendered, err
+	default:
+		data, err := parseJSONDashboard(content)
+		return data, content, err
+	}
+}
+
+// parseJSONDashboard parses the dashboard, syntax and type errors are reported with their line
+func parseJSONDashboard(content []byte) (*simplejson.Json, error) {
+	data, err := simplejson.NewJson(content)
+	if err == nil {
+		return data, nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return nil, fmt.Errorf("line %d: %w", lineOfOffset(content, syntaxErr.Offset), err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return nil, fmt.Errorf("line %d: %w", lineOfOffset(content, typeErr.Offset), err)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("line %d: %w", lineOfOffset(content, int64(len(content))), err)
+	}
+	return nil, err
+}
+
+// parseYAMLDashboard converts the YAML dashboard to JSON, the errors of the YAML parser contain the line
+func parseYAMLDashboard(content []byte) (*simplejson.Json, error) {
+	var node any
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, err
+	}
+
+	if _, ok := node.(map[string]any); !ok {
+		return nil, fmt.Errorf("dashboard should be a YAML mapping")
+	}
+
+	encoded, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return simplejson.NewJson(encoded)
+}
+
+// encodeDashboardFile returns the content of the file at path for the dashboard, in the format of the file.
+// Jsonnet dashboards are generated and can't be written back.
+func encodeDashboardFile(path string, data *simplejson.Json) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Marshal(yamlValue(data.Interface()))
+	case ".jsonnet":
+		return nil, fmt.Errorf("jsonnet dashboard %s can't be written back", path)
+	default:
+		content, err := data.EncodePretty()
+		if err != nil {
+			return nil, err
+		}
+		return append(content, '\n'), nil
+	}
+}
+
+// yamlValue converts the numbers decoded by simplejson, which are json.Number strings, to
+// integers or floats so they are not written as quoted strings in YAML.
+func yamlValue(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(value))
+		for k, item := range value {
+			converted[k] = yamlValue(item)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(value))
+		for i, item := range value {
+			converted[i] = yamlValue(item)
+		}
+		return converted
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value.String()
+	default:
+		return v
+	}
+}
+
+// renderJsonnet renders the Jsonnet file, the imports are resolved from the directory of the file and
+// then from the library paths. The errors of the evaluation contain the file and the line of the error.
+// The file is rendered at every poll of the provider, since the rendered output is what changes are
+// detected on.
+func (fr *FileReader) renderJsonnet(path string, content []byte) ([]byte, error) {
+	libraryPaths := make([]string, 0, len(fr.jsonnet.libraryPaths))
+	for _, libraryPath := range fr.jsonnet.libraryPaths {
+		// relative library paths are relative to the path of the provider
+		if !filepath.IsAbs(libraryPath) {
+			libraryPath = filepath.Join(fr.resolvedPath(), libraryPath)
+		}
+		libraryPaths = append(libraryPaths, libraryPath)
+	}
+
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.FileImporter{JPaths: libraryPaths})
+	rendered, err := vm.EvaluateAnonymousSnippet(path, string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render jsonnet: %w", err)
+	}
+
+	return []byte(rendered), nil
+}
+
+func lineOfOffset(content []byte, offset int64) int {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
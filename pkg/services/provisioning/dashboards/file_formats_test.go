@@ -0,0 +1,194 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+)
+
+func TestDashboardFileFormats(t *testing.T) {
+	logger := log.New("test-logger")
+
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for path, content := range files {
+			fullPath := filepath.Join(dir, path)
+			require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o750))
+			require.NoError(t, os.WriteFile(fullPath, []byte(content), 0o600))
+		}
+		return dir
+	}
+
+	newReader := func(t *testing.T, path string, options map[string]any) (*FileReader, map[string]string) {
+		cfg := &config{
+			Name:    "Default",
+			Type:    "file",
+			OrgID:   1,
+			Options: map[string]any{"path": path},
+		}
+		for k, v := range options {
+			cfg.Options[k] = v
+		}
+
+		saved := map[string]string{}
+		fakeService := &dashboards.FakeDashboardProvisioning{}
+		fakeService.On("GetProvisionedDashboardData", mock.Anything, "Default").Return(nil, nil).Once()
+		fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				dto := args.Get(1).(*dashboards.SaveDashboardDTO)
+				saved[dto.Dashboard.UID] = dto.Dashboard.Title
+			}).
+			Return(&dashboards.Dashboard{}, nil).Maybe()
+
+		reader, err := NewDashboardFileReader(cfg, logger, fakeService, &fakeDashboardStore{}, &foldertest.FakeService{})
+		require.NoError(t, err)
+		return reader, saved
+	}
+
+	files := map[string]string{
+		"cpu.json":               `{"uid": "cpu", "title": "CPU"}`,
+		"memory.yaml":            "uid: memory\ntitle: Memory\npanels:\n  - id: 1\n    type: timeseries\n",
+		"disk.yml":               "uid: disk\ntitle: Disk\n",
+		"broken.json":            "{\n  \"uid\": \"broken\",\n  \"title\": \"Broken\"\n  \"tags\": []\n}",
+		"broken.yaml":            "uid: broken-yaml\ntitle: Broken\n  tags: []\n",
+		"network.jsonnet":        `{ uid: "network", title: "Network" }`,
+		"lib/panels.libsonnet":   `{}`,
+		"provisioning/dash.yaml": "apiVersion: 1\nproviders: []\n",
+	}
+
+	t.Run("Only JSON dashboards are provisioned by default", func(t *testing.T) {
+		reader, saved := newReader(t, writeFiles(t, files), nil)
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		assert.Equal(t, map[string]string{"cpu": "CPU"}, saved)
+		assert.Len(t, reader.getStatus().FileErrors, 1)
+	})
+
+	t.Run("YAML dashboards are provisioned when enabled", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"cpu.json":    files["cpu.json"],
+			"memory.yaml": files["memory.yaml"],
+			"disk.yml":    files["disk.yml"],
+		})
+		reader, saved := newReader(t, dir, map[string]any{"formats": []any{"json", "yaml"}})
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		assert.Equal(t, map[string]string{"cpu": "CPU", "memory": "Memory", "disk": "Disk"}, saved)
+		assert.Empty(t, reader.getStatus().FileErrors)
+	})
+
+	t.Run("Parse errors are reported with the file and the line", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"broken.json": files["broken.json"],
+			"broken.yaml": files["broken.yaml"],
+		})
+		reader, saved := newReader(t, dir, map[string]any{"formats": []any{"json", "yaml"}})
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		assert.Empty(t, saved)
+
+		fileErrors := reader.getStatus().FileErrors
+		require.Len(t, fileErrors, 2)
+		assert.Contains(t, fileErrors["broken.json"], "line 4:")
+		assert.Contains(t, fileErrors["broken.yaml"], "line 3:")
+	})
+
+	t.Run("Jsonnet dashboards are rendered with the library paths", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"network.jsonnet":      "local panels = import 'panels.libsonnet';\nlocal grafonnet = import 'grafonnet.libsonnet';\n{ uid: 'network', title: grafonnet.title + ' ' + panels.title }\n",
+			"lib/panels.libsonnet": `{ title: 'network' }`,
+		})
+		grafonnet := writeFiles(t, map[string]string{"grafonnet.libsonnet": `{ title: 'Grafonnet' }`})
+
+		reader, saved := newReader(t, dir, map[string]any{
+			"formats": []any{"jsonnet"},
+			"jsonnet": map[string]any{"libraryPaths": []any{"lib", grafonnet}},
+		})
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		assert.Empty(t, reader.getStatus().FileErrors)
+		assert.Equal(t, map[string]string{"network": "Grafonnet network"}, saved)
+	})
+
+	t.Run("Jsonnet render errors are reported", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"network.jsonnet": files["network.jsonnet"],
+			"broken.jsonnet":  "{\n  uid: 'broken',\n  title: grafana.title,\n}\n",
+			"missing.jsonnet": "import 'missing.libsonnet'\n",
+		})
+		reader, saved := newReader(t, dir, map[string]any{"formats": []any{"jsonnet"}})
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		assert.Equal(t, map[string]string{"network": "Network"}, saved)
+
+		fileErrors := reader.getStatus().FileErrors
+		require.Len(t, fileErrors, 2)
+		assert.Contains(t, fileErrors["broken.jsonnet"], "broken.jsonnet:3:10-17 Unknown variable: grafana")
+		assert.Contains(t, fileErrors["missing.jsonnet"], "couldn't open import \"missing.libsonnet\"")
+	})
+
+	t.Run("Invalid formats should return error", func(t *testing.T) {
+		_, err := NewDashboardFileReader(&config{Name: "Default", Type: "file", Options: map[string]any{
+			"path":    t.TempDir(),
+			"formats": []any{"json", "xml"},
+		}}, logger, nil, nil, nil)
+		require.ErrorContains(t, err, "unsupported dashboard file format xml")
+
+		_, err = NewDashboardFileReader(&config{Name: "Default", Type: "file", Options: map[string]any{
+			"path":    t.TempDir(),
+			"jsonnet": map[string]any{"libraryPaths": "lib"},
+		}}, logger, nil, nil, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestEncodeDashboardFile(t *testing.T) {
+	data := simplejson.NewFromAny(map[string]any{"uid": "cpu", "title": "CPU"})
+
+	content, err := encodeDashboardFile("cpu.json", data)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"title\": \"CPU\",\n  \"uid\": \"cpu\"\n}\n", string(content))
+
+	content, err = encodeDashboardFile("cpu.yaml", data)
+	require.NoError(t, err)
+	assert.Equal(t, "title: CPU\nuid: cpu\n", string(content))
+
+	_, err = encodeDashboardFile("cpu.jsonnet", data)
+	require.Error(t, err)
+}
+
+func TestEncodeDashboardFile_Numbers(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{"uid": "cpu", "version": 3, "refresh": "5s", "panels": [{"id": 1, "gridPos": {"h": 8, "w": 12}, "fieldConfig": {"defaults": {"decimals": 1.5}}}]}`))
+	require.NoError(t, err)
+
+	content, err := encodeDashboardFile("cpu.yaml", data)
+	require.NoError(t, err)
+	assert.Equal(t, `panels:
+    - fieldConfig:
+        defaults:
+            decimals: 1.5
+      gridPos:
+        h: 8
+        w: 12
+      id: 1
+refresh: 5s
+uid: cpu
+version: 3
+`, string(content))
+
+	// the written file is provisioned with the same values
+	parsed, err := parseYAMLDashboard(content)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), parsed.Get("version").MustInt64())
+	assert.Equal(t, int64(8), parsed.Get("panels").GetIndex(0).GetPath("gridPos", "h").MustInt64())
+}
@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
-	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -41,9 +40,16 @@ type FileReader struct {
 	FoldersFromFilesStructure    bool
 	folderService                folder.Service
 
+	// git is the repository the dashboards are read from, nil for file providers
+	git *gitRepository
+	// extensions are the extensions of the dashboard files, depending on the formats enabled by the provider
+	extensions []string
+	jsonnet    *jsonnetConfig
+
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+	status                  ProvisionerStatus
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -65,6 +71,61 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
 	}
 
+	extensions, err := newFileExtensions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonnet, err := newJsonnetConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileReader{
+		Cfg:                          cfg,
+		Path:                         path,
+		log:                          log,
+		dashboardProvisioningService: service,
+		dashboardStore:               dashboardStore,
+		folderService:                folderService,
+		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		usageTracker:                 newUsageTracker(),
+		extensions:                   extensions,
+		jsonnet:                      jsonnet,
+	}, nil
+}
+
+// NewDashboardGitReader returns a new filereader reading the dashboards from a clone of the git repository configured in `config`
+func NewDashboardGitReader(cfg *config, dataPath string, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service) (*FileReader, error) {
+	repo, err := newGitRepository(cfg, dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the path option is relative to the root of the repository
+	subPath, _ := cfg.Options["path"].(string)
+	path := filepath.Join(repo.dir, filepath.Clean("/"+subPath))
+
+	// directories are mapped to folders unless a folder is set for the whole provider
+	foldersFromFilesStructure := cfg.Folder == "" && cfg.FolderUID == ""
+	if v, ok := cfg.Options["foldersFromFilesStructure"].(bool); ok {
+		if v && !foldersFromFilesStructure {
+			return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
+		}
+		foldersFromFilesStructure = v
+	}
+
+	extensions, err := newFileExtensions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonnet, err := newJsonnetConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &FileReader{
 		Cfg:                          cfg,
 		Path:                         path,
@@ -74,6 +135,9 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 		folderService:                folderService,
 		FoldersFromFilesStructure:    foldersFromFilesStructure,
 		usageTracker:                 newUsageTracker(),
+		git:                          repo,
+		extensions:                   extensions,
+		jsonnet:                      jsonnet,
 	}, nil
 }
 
@@ -95,6 +159,34 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	status := ProvisionerStatus{
+		Name:       fr.Cfg.Name,
+		Type:       fr.Cfg.Type,
+		LastSync:   time.Now(),
+		FileErrors: map[string]string{},
+	}
+	err := fr.walk(ctx, &status)
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	fr.mux.Lock()
+	defer fr.mux.Unlock()
+
+	fr.status = status
+	return err
+}
+
+func (fr *FileReader) walk(ctx context.Context, status *ProvisionerStatus) error {
+	if fr.git != nil {
+		fr.log.Debug("Syncing git repository", "url", fr.git.url, "ref", fr.git.ref)
+		revision, err := fr.git.sync(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrGitSyncFailed, err)
+		}
+		status.Revision = revision
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -108,22 +200,30 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 
 	// Find relevant files
 	filesFoundOnDisk := map[string]os.FileInfo{}
-	if err := filepath.Walk(resolvedPath, createWalkFn(filesFoundOnDisk)); err != nil {
+	if err := filepath.Walk(resolvedPath, createWalkFn(filesFoundOnDisk, fr.extensions)); err != nil {
 		return err
 	}
 
 	fr.handleMissingDashboardFiles(ctx, provisionedDashboardRefs, filesFoundOnDisk)
 
 	usageTracker := newUsageTracker()
+	fileErrors := map[string]error{}
 	if fr.FoldersFromFilesStructure {
-		err = fr.storeDashboardsInFoldersFromFileStructure(ctx, filesFoundOnDisk, provisionedDashboardRefs, resolvedPath, usageTracker)
+		err = fr.storeDashboardsInFoldersFromFileStructure(ctx, filesFoundOnDisk, provisionedDashboardRefs, resolvedPath, usageTracker, fileErrors)
 	} else {
-		err = fr.storeDashboardsInFolder(ctx, filesFoundOnDisk, provisionedDashboardRefs, usageTracker)
+		err = fr.storeDashboardsInFolder(ctx, filesFoundOnDisk, provisionedDashboardRefs, usageTracker, fileErrors)
 	}
 	if err != nil {
 		return err
 	}
 
+	for path, fileErr := range fileErrors {
+		if rel, err := filepath.Rel(resolvedPath, path); err == nil {
+			path = rel
+		}
+		status.FileErrors[filepath.ToSlash(path)] = fileErr.Error()
+	}
+
 	fr.mux.Lock()
 	defer fr.mux.Unlock()
 
@@ -147,7 +247,7 @@ func (fr *FileReader) isDatabaseAccessRestricted() bool {
 
 // storeDashboardsInFolder saves dashboards from the filesystem on disk to the folder from config
 func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnDisk map[string]os.FileInfo,
-	dashboardRefs map[string]*dashboards.DashboardProvisioning, usageTracker *usageTracker) error {
+	dashboardRefs map[string]*dashboards.DashboardProvisioning, usageTracker *usageTracker, fileErrors map[string]error) error {
 	ctx, _ = identity.WithServiceIdentitiy(ctx, fr.Cfg.OrgID)
 
 	folderID, folderUID, err := fr.getOrCreateFolder(ctx, fr.Cfg, fr.dashboardProvisioningService, fr.Cfg.Folder)
@@ -160,6 +260,7 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, folderUID, fileInfo, dashboardRefs)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
+			fileErrors[path] = err
 			continue
 		}
 
@@ -171,7 +272,7 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 // storeDashboardsInFoldersFromFilesystemStructure saves dashboards from the filesystem on disk to the same folder
 // in Grafana as they are in on the filesystem.
 func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Context, filesFoundOnDisk map[string]os.FileInfo,
-	dashboardRefs map[string]*dashboards.DashboardProvisioning, resolvedPath string, usageTracker *usageTracker) error {
+	dashboardRefs map[string]*dashboards.DashboardProvisioning, resolvedPath string, usageTracker *usageTracker, fileErrors map[string]error) error {
 	for path, fileInfo := range filesFoundOnDisk {
 		folderName := ""
 
@@ -190,6 +291,7 @@ func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Cont
 		usageTracker.track(provisioningMetadata)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
+			fileErrors[path] = err
 		}
 	}
 	return nil
@@ -242,8 +344,7 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 
 	jsonFile, err := fr.readDashboardFromFile(path, resolvedFileInfo.ModTime(), folderID, folderUID)
 	if err != nil {
-		fr.log.Error("failed to load dashboard from ", "file", path, "error", err)
-		return provisioningMetadata, nil
+		return provisioningMetadata, fmt.Errorf("failed to load dashboard: %w", err)
 	}
 
 	upToDate := alreadyProvisioned
@@ -411,13 +512,13 @@ func resolveSymlink(fileinfo os.FileInfo, path string) (os.FileInfo, error) {
 	return fileinfo, err
 }
 
-func createWalkFn(filesOnDisk map[string]os.FileInfo) filepath.WalkFunc {
+func createWalkFn(filesOnDisk map[string]os.FileInfo, extensions []string) filepath.WalkFunc {
 	return func(path string, fileInfo os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		isValid, err := validateWalkablePath(fileInfo)
+		isValid, err := validateWalkablePath(fileInfo, extensions)
 		if !isValid {
 			return err
 		}
@@ -427,7 +528,7 @@ func createWalkFn(filesOnDisk map[string]os.FileInfo) filepath.WalkFunc {
 	}
 }
 
-func validateWalkablePath(fileInfo os.FileInfo) (bool, error) {
+func validateWalkablePath(fileInfo os.FileInfo, extensions []string) (bool, error) {
 	if fileInfo.IsDir() {
 		if strings.HasPrefix(fileInfo.Name(), ".") {
 			return false, filepath.SkipDir
@@ -435,7 +536,7 @@ func validateWalkablePath(fileInfo os.FileInfo) (bool, error) {
 		return false, nil
 	}
 
-	if !strings.HasSuffix(fileInfo.Name(), ".json") {
+	if !isDashboardFile(fileInfo.Name(), extensions) {
 		return false, nil
 	}
 
@@ -466,12 +567,12 @@ func (fr *FileReader) readDashboardFromFile(path string, lastModified time.Time,
 		return nil, err
 	}
 
-	checkSum, err := util.Md5SumString(string(all))
+	data, checkSumContent, err := fr.parseDashboardFile(path, all)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := simplejson.NewJson(all)
+	checkSum, err := util.Md5SumString(string(checkSumContent))
 	if err != nil {
 		return nil, err
 	}
@@ -510,6 +611,13 @@ func (fr *FileReader) resolvedPath() string {
 	return path
 }
 
+func (fr *FileReader) getStatus() ProvisionerStatus {
+	fr.mux.RLock()
+	defer fr.mux.RUnlock()
+
+	return fr.status
+}
+
 func (fr *FileReader) getUsageTracker() *usageTracker {
 	fr.mux.RLock()
 	defer fr.mux.RUnlock()
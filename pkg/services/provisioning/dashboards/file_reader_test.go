@@ -443,12 +443,12 @@ func TestDashboardFileReader(t *testing.T) {
 		noFiles := map[string]os.FileInfo{}
 
 		t.Run("should skip dirs that starts with .", func(t *testing.T) {
-			shouldSkip := createWalkFn(noFiles)("path", &FakeFileInfo{isDirectory: true, name: ".folder"}, nil)
+			shouldSkip := createWalkFn(noFiles, formatExtensions[formatJSON])("path", &FakeFileInfo{isDirectory: true, name: ".folder"}, nil)
 			require.Equal(t, shouldSkip, filepath.SkipDir)
 		})
 
 		t.Run("should keep walking if file is not .json", func(t *testing.T) {
-			shouldSkip := createWalkFn(noFiles)("path", &FakeFileInfo{isDirectory: true, name: "folder"}, nil)
+			shouldSkip := createWalkFn(noFiles, formatExtensions[formatJSON])("path", &FakeFileInfo{isDirectory: true, name: "folder"}, nil)
 			require.Nil(t, shouldSkip)
 		})
 	})
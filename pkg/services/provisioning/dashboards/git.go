@@ -0,0 +1,253 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGitRef         = "main"
+	gitCommandTimeout     = 2 * time.Minute
+	defaultGitAuthorName  = "Grafana"
+	defaultGitAuthorEmail = "grafana@localhost"
+)
+
+var (
+	// ErrGitSyncFailed is returned when the repository of a git provider cannot be fetched or checked out.
+	ErrGitSyncFailed = errors.New("failed to sync git repository")
+	// ErrGitURLMissing is returned when the url option of a git provider is missing.
+	ErrGitURLMissing = errors.New("git repository url missing")
+	// ErrGitWriteBackDisabled is returned when a dashboard is written back to a provider without a write back branch.
+	ErrGitWriteBackDisabled = errors.New("write back is not enabled for the git provider")
+
+	unsafeDirNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+)
+
+// gitRepository is a local clone of the repository of a git provider. The clone is
+// managed with the git command line, the revision pinned by the provider is checked
+// out in a detached state and UI edits are committed with plumbing commands so the
+// checked out files are never modified by Grafana.
+type gitRepository struct {
+	url             string
+	ref             string
+	dir             string
+	writeBackBranch string
+	authorName      string
+	authorEmail     string
+
+	// mux serializes the git commands run on the clone
+	mux      sync.Mutex
+	revision string
+}
+
+// newGitRepository returns the repository of a git provider, cloned by default in the
+// provisioning directory of the Grafana data path.
+func newGitRepository(cfg *config, dataPath string) (*gitRepository, error) {
+	url, _ := cfg.Options["url"].(string)
+	if url == "" {
+		return nil, ErrGitURLMissing
+	}
+
+	ref, _ := cfg.Options["ref"].(string)
+	if ref == "" {
+		ref = defaultGitRef
+	}
+
+	dir, _ := cfg.Options["cloneDir"].(string)
+	if dir == "" {
+		dir = filepath.Join(dataPath, "provisioning", "git", unsafeDirNameChars.ReplaceAllString(cfg.Name, "_"))
+	}
+
+	repo := &gitRepository{
+		url:         url,
+		ref:         ref,
+		dir:         dir,
+		authorName:  defaultGitAuthorName,
+		authorEmail: defaultGitAuthorEmail,
+	}
+
+	if writeBack, ok := cfg.Options["writeBack"].(map[string]any); ok {
+		repo.writeBackBranch, _ = writeBack["branch"].(string)
+		if repo.writeBackBranch == "" {
+			return nil, fmt.Errorf("'branch' is required to write back dashboards to the git repository")
+		}
+		if name, ok := writeBack["authorName"].(string); ok && name != "" {
+			repo.authorName = name
+		}
+		if email, ok := writeBack["authorEmail"].(string); ok && email != "" {
+			repo.authorEmail = email
+		}
+	}
+
+	return repo, nil
+}
+
+// sync fetches the repository and checks out the pinned branch or tag. It returns the checked out commit.
+func (r *gitRepository) sync(ctx context.Context) (string, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(r.dir), 0o750); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, nil, "", "clone", "--no-checkout", "--quiet", "--", r.url, r.dir); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := r.git(ctx, nil, r.dir, "remote", "set-url", "origin", r.url); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, nil, r.dir, "fetch", "--quiet", "--prune", "--tags", "--force", "origin"); err != nil {
+			return "", err
+		}
+	}
+
+	revision, err := r.resolveRef(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.git(ctx, nil, r.dir, "checkout", "--quiet", "--force", "--detach", revision); err != nil {
+		return "", err
+	}
+	// remove the files left over by a previous checkout, ignored files included
+	if _, err := r.git(ctx, nil, r.dir, "clean", "--quiet", "-d", "--force", "-x"); err != nil {
+		return "", err
+	}
+
+	r.revision = revision
+	return revision, nil
+}
+
+// resolveRef returns the commit pointed by the ref of the provider, looking for a branch first and then for a tag.
+func (r *gitRepository) resolveRef(ctx context.Context) (string, error) {
+	candidates := []string{
+		"refs/remotes/origin/" + r.ref,
+		"refs/tags/" + r.ref,
+		r.ref,
+	}
+
+	for _, candidate := range candidates {
+		out, err := r.git(ctx, nil, r.dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(out), nil
+		}
+	}
+
+	return "", fmt.Errorf("ref %q not found in git repository %s", r.ref, r.url)
+}
+
+// commitFile commits content at path, relative to the root of the repository, on the write back
+// branch and pushes the branch. The branch is created from the checked out revision if it doesn't exist.
+func (r *gitRepository) commitFile(ctx context.Context, path string, content []byte, message, author string) (string, error) {
+	if r.writeBackBranch == "" {
+		return "", ErrGitWriteBackDisabled
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.revision == "" {
+		return "", fmt.Errorf("git repository %s has not been synced", r.url)
+	}
+
+	parent := r.revision
+	if _, err := r.git(ctx, nil, r.dir, "fetch", "--quiet", "origin", "+refs/heads/"+r.writeBackBranch+":refs/remotes/origin/"+r.writeBackBranch); err == nil {
+		out, err := r.git(ctx, nil, r.dir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+r.writeBackBranch+"^{commit}")
+		if err != nil {
+			return "", err
+		}
+		parent = strings.TrimSpace(out)
+	}
+
+	// build the tree of the new commit in a temporary index, the index of the clone is left untouched
+	index, err := os.CreateTemp("", "grafana-provisioning-git-index")
+	if err != nil {
+		return "", err
+	}
+	indexPath := index.Name()
+	_ = index.Close()
+	_ = os.Remove(indexPath)
+	defer func() { _ = os.Remove(indexPath) }()
+	env := []string{"GIT_INDEX_FILE=" + indexPath}
+
+	if _, err := r.git(ctx, env, r.dir, "read-tree", parent); err != nil {
+		return "", err
+	}
+
+	blob, err := r.gitWithInput(ctx, content, r.dir, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.git(ctx, env, r.dir, "update-index", "--add", "--cacheinfo", "100644,"+strings.TrimSpace(blob)+","+filepath.ToSlash(path)); err != nil {
+		return "", err
+	}
+
+	tree, err := r.git(ctx, env, r.dir, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	env = append(env,
+		"GIT_AUTHOR_NAME="+author,
+		"GIT_AUTHOR_EMAIL="+r.authorEmail,
+		"GIT_COMMITTER_NAME="+r.authorName,
+		"GIT_COMMITTER_EMAIL="+r.authorEmail,
+	)
+	commit, err := r.git(ctx, env, r.dir, "commit-tree", strings.TrimSpace(tree), "-p", parent, "-m", message)
+	if err != nil {
+		return "", err
+	}
+	commit = strings.TrimSpace(commit)
+
+	if _, err := r.git(ctx, nil, r.dir, "push", "--quiet", "origin", commit+":refs/heads/"+r.writeBackBranch); err != nil {
+		return "", err
+	}
+
+	return commit, nil
+}
+
+func (r *gitRepository) git(ctx context.Context, env []string, dir string, args ...string) (string, error) {
+	return r.run(ctx, env, nil, dir, args...)
+}
+
+func (r *gitRepository) gitWithInput(ctx context.Context, input []byte, dir string, args ...string) (string, error) {
+	return r.run(ctx, nil, input, dir, args...)
+}
+
+func (r *gitRepository) run(ctx context.Context, env []string, input []byte, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitCommandTimeout)
+	defer cancel()
+
+	// nolint:gosec
+	// The arguments come from the provisioning configuration file.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// never prompt for credentials, they are expected to be part of the url or of the git configuration
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, env...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
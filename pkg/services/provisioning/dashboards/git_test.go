@@ -0,0 +1,247 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+)
+
+func TestDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	logger := log.New("test-logger")
+	remote := newTestGitRemote(t)
+	remote.commit(map[string]string{
+		"dashboards/team-a/cpu.json":    `{"uid": "cpu", "title": "CPU"}`,
+		"dashboards/team-b/memory.json": `{"uid": "memory", "title": "Memory"}`,
+		"dashboards/team-b/broken.json": `{"uid": "broken",`,
+		"README.md":                     "dashboards",
+	})
+	remote.run("tag", "v1")
+	remote.commit(map[string]string{
+		"dashboards/team-a/disk.json": `{"uid": "disk", "title": "Disk"}`,
+	})
+	remote.push("main", "v1")
+
+	newReader := func(t *testing.T, options map[string]any) (*FileReader, *dashboards.FakeDashboardProvisioning) {
+		cfg := &config{
+			Name:    "git",
+			Type:    "git",
+			OrgID:   1,
+			Options: map[string]any{"url": "file://" + remote.bare, "path": "dashboards", "cloneDir": filepath.Join(t.TempDir(), "clone")},
+		}
+		for k, v := range options {
+			cfg.Options[k] = v
+		}
+
+		fakeService := &dashboards.FakeDashboardProvisioning{}
+		t.Cleanup(func() { fakeService.AssertExpectations(t) })
+		folderSvc := &foldertest.FakeService{ExpectedError: dashboards.ErrFolderNotFound}
+
+		reader, err := NewDashboardGitReader(cfg, t.TempDir(), logger, fakeService, &fakeDashboardStore{}, folderSvc)
+		require.NoError(t, err)
+		return reader, fakeService
+	}
+
+	t.Run("Provisions the dashboards of the pinned tag in folders named after the directories", func(t *testing.T) {
+		reader, fakeService := newReader(t, map[string]any{"ref": "v1"})
+
+		saved := map[string]string{}
+		fakeService.On("GetProvisionedDashboardData", mock.Anything, "git").Return(nil, nil).Once()
+		fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).
+			Return(func(_ context.Context, cmd *folder.CreateFolderCommand) (*folder.Folder, error) {
+				return &folder.Folder{UID: cmd.Title}, nil
+			})
+		fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				dto := args.Get(1).(*dashboards.SaveDashboardDTO)
+				saved[dto.Dashboard.UID] = dto.Dashboard.FolderUID
+			}).
+			Return(&dashboards.Dashboard{}, nil).Times(2)
+
+		err := reader.walkDisk(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"cpu": "team-a", "memory": "team-b"}, saved)
+
+		status := reader.getStatus()
+		assert.Equal(t, "git", status.Type)
+		assert.Equal(t, remote.revParse("v1"), status.Revision)
+		assert.Empty(t, status.Error)
+		require.Len(t, status.FileErrors, 1)
+		assert.Contains(t, status.FileErrors["team-b/broken.json"], "failed to load dashboard")
+	})
+
+	t.Run("Follows the branch when syncing again", func(t *testing.T) {
+		reader, fakeService := newReader(t, map[string]any{"ref": "main", "foldersFromFilesStructure": false})
+
+		fakeService.On("GetProvisionedDashboardData", mock.Anything, "git").Return(nil, nil).Times(2)
+		fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{}, nil).Times(3 + 4)
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		assert.Equal(t, remote.revParse("main"), reader.getStatus().Revision)
+
+		remote.commit(map[string]string{
+			"dashboards/network.json": `{"uid": "network", "title": "Network"}`,
+		})
+		remote.push("main")
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		assert.Equal(t, remote.revParse("main"), reader.getStatus().Revision)
+		_, err := os.Stat(filepath.Join(reader.Path, "network.json"))
+		require.NoError(t, err)
+	})
+
+	t.Run("Reports an unknown ref", func(t *testing.T) {
+		reader, fakeService := newReader(t, map[string]any{"ref": "v9"})
+
+		err := reader.walkDisk(context.Background())
+		require.ErrorIs(t, err, ErrGitSyncFailed)
+		assert.Contains(t, reader.getStatus().Error, `ref "v9" not found`)
+		fakeService.AssertNotCalled(t, "GetProvisionedDashboardData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Writes UI edits back as commits on the write back branch", func(t *testing.T) {
+		reader, fakeService := newReader(t, map[string]any{
+			"ref":       "v1",
+			"writeBack": map[string]any{"branch": "grafana-edits", "authorEmail": "grafana@example.com"},
+		})
+		fakeService.On("GetProvisionedDashboardData", mock.Anything, "git").Return(nil, nil).Once()
+		fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).Return(&folder.Folder{}, nil)
+		fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{}, nil).Times(2)
+		require.NoError(t, reader.walkDisk(context.Background()))
+
+		provisioner := &Provisioner{log: logger, fileReaders: []*FileReader{reader}}
+		for _, title := range []string{"CPU usage", "CPU load"} {
+			err := provisioner.WriteBackDashboard(context.Background(), &WriteBackDashboardCommand{
+				Name:       "git",
+				ExternalID: filepath.Join(reader.Path, "team-a", "cpu.json"),
+				Dashboard:  simplejson.NewFromAny(map[string]any{"id": 12, "uid": "cpu", "title": title}),
+				Author:     "editor",
+				Message:    "Rename to " + title,
+			})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, "Rename to CPU load\nRename to CPU usage\n", remote.run("log", "--format=%s", "v1..grafana-edits"))
+		assert.Equal(t, "editor <grafana@example.com>\n", remote.run("log", "-1", "--format=%an <%ae>", "grafana-edits"))
+
+		data, err := simplejson.NewJson([]byte(remote.run("show", "grafana-edits:dashboards/team-a/cpu.json")))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"uid": "cpu", "title": "CPU load"}, data.Interface())
+
+		// the checked out files are not modified
+		content, err := os.ReadFile(filepath.Join(reader.Path, "team-a", "cpu.json"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"uid": "cpu", "title": "CPU"}`, string(content))
+	})
+
+	t.Run("Ignores write back for providers without write back branch", func(t *testing.T) {
+		reader, _ := newReader(t, map[string]any{"ref": "v1"})
+		provisioner := &Provisioner{log: logger, fileReaders: []*FileReader{reader}}
+
+		err := provisioner.WriteBackDashboard(context.Background(), &WriteBackDashboardCommand{
+			Name:       "git",
+			ExternalID: filepath.Join(reader.Path, "team-a", "cpu.json"),
+			Dashboard:  simplejson.NewFromAny(map[string]any{"uid": "cpu"}),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid configuration should return error", func(t *testing.T) {
+		_, err := NewDashboardGitReader(&config{Name: "git", Type: "git", Options: map[string]any{}}, t.TempDir(), logger, nil, nil, nil)
+		require.ErrorIs(t, err, ErrGitURLMissing)
+
+		_, err = NewDashboardGitReader(&config{Name: "git", Type: "git", Options: map[string]any{
+			"url":       "file:///tmp/repo.git",
+			"writeBack": map[string]any{},
+		}}, t.TempDir(), logger, nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("Clones in the data path by default", func(t *testing.T) {
+		dataPath := t.TempDir()
+		repo, err := newGitRepository(&config{Name: "team a/dashboards", Type: "git", Options: map[string]any{"url": "file://" + remote.bare}}, dataPath)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dataPath, "provisioning", "git", "team_a_dashboards"), repo.dir)
+	})
+}
+
+// testGitRemote is a bare repository and a clone used to push commits to it
+type testGitRemote struct {
+	t     *testing.T
+	bare  string
+	clone string
+}
+
+func newTestGitRemote(t *testing.T) *testGitRemote {
+	t.Helper()
+
+	dir := t.TempDir()
+	r := &testGitRemote{t: t, bare: filepath.Join(dir, "remote.git"), clone: filepath.Join(dir, "work")}
+	r.exec(dir, "init", "--quiet", "--bare", r.bare)
+	r.exec(dir, "init", "--quiet", "--initial-branch=main", r.clone)
+	r.run("remote", "add", "origin", r.bare)
+	return r
+}
+
+func (r *testGitRemote) commit(files map[string]string) {
+	r.t.Helper()
+
+	for path, content := range files {
+		fullPath := filepath.Join(r.clone, path)
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(fullPath), 0o750))
+		require.NoError(r.t, os.WriteFile(fullPath, []byte(content), 0o600))
+	}
+	r.run("add", "--all")
+	r.run("commit", "--quiet", "--message", "update dashboards")
+}
+
+func (r *testGitRemote) push(refs ...string) {
+	r.t.Helper()
+	r.run(append([]string{"push", "--quiet", "origin"}, refs...)...)
+}
+
+func (r *testGitRemote) revParse(ref string) string {
+	r.t.Helper()
+	return strings.TrimSpace(r.run("rev-parse", ref+"^{commit}"))
+}
+
+// run runs a git command in the clone, reading the branches pushed by Grafana from the bare repository
+func (r *testGitRemote) run(args ...string) string {
+	r.t.Helper()
+	if args[0] == "log" || args[0] == "show" {
+		return r.exec(r.bare, args...)
+	}
+	return r.exec(r.clone, args...)
+}
+
+func (r *testGitRemote) exec(dir string, args ...string) string {
+	r.t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(out))
+	return string(out)
+}
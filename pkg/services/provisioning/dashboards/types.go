@@ -36,6 +36,18 @@ type configV0 struct {
 	AllowUIUpdates        bool           `json:"allowUiUpdates" yaml:"allowUiUpdates"`
 }
 
+// ProvisionerStatus is the result of the last sync of a dashboard provider
+type ProvisionerStatus struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Revision is the commit checked out by git providers
+	Revision string    `json:"revision,omitempty"`
+	LastSync time.Time `json:"lastSync"`
+	// Error is set when the provider failed to sync, FileErrors are errors of single dashboard files
+	Error      string            `json:"error,omitempty"`
+	FileErrors map[string]string `json:"fileErrors"`
+}
+
 type configVersion struct {
 	APIVersion int64 `json:"apiVersion" yaml:"apiVersion"`
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	prov_accesscontrol "github.com/grafana/grafana/pkg/services/provisioning/accesscontrol"
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
@@ -34,6 +35,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -57,6 +59,8 @@ func ProvideService(
 	orgService org.Service,
 	resourcePermissions accesscontrol.ReceiverPermissionsService,
 	tracer tracing.Tracer,
+	customRoleService accesscontrol.CustomRoleService,
+	teamService team.Service,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -70,6 +74,7 @@ func ProvideService(
 		provisionDatasources:         datasources.Provision,
 		provisionPlugins:             plugins.Provision,
 		provisionAlerting:            prov_alerting.Provision,
+		provisionAccessControl:       prov_accesscontrol.Provision,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
@@ -83,6 +88,8 @@ func ProvideService(
 		folderService:                folderService,
 		resourcePermissions:          resourcePermissions,
 		tracer:                       tracer,
+		customRoleService:            customRoleService,
+		teamService:                  teamService,
 	}
 
 	if err := s.setDashboardProvisioner(); err != nil {
@@ -94,7 +101,7 @@ func ProvideService(
 
 func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
+	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.Cfg.DataPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -109,8 +116,11 @@ type ProvisioningService interface {
 	ProvisionPlugins(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	ProvisionAlerting(ctx context.Context) error
+	ProvisionAccessControl(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetDashboardProvisionersStatus() []dashboards.ProvisionerStatus
+	WriteBackDashboard(ctx context.Context, cmd *dashboards.WriteBackDashboardCommand) error
 }
 
 // Used for testing purposes
@@ -152,6 +162,7 @@ type ProvisioningServiceImpl struct {
 	provisionDatasources         func(context.Context, string, datasources.BaseDataSourceService, datasources.CorrelationsStore, org.Service) error
 	provisionPlugins             func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error
 	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
+	provisionAccessControl       func(context.Context, string, accesscontrol.CustomRoleService, team.Service) error
 	mutex                        sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
@@ -164,6 +175,8 @@ type ProvisioningServiceImpl struct {
 	folderService                folder.Service
 	resourcePermissions          accesscontrol.ReceiverPermissionsService
 	tracer                       tracing.Tracer
+	customRoleService            accesscontrol.CustomRoleService
+	teamService                  team.Service
 	onceInitProvisioners         sync.Once
 }
 
@@ -186,6 +199,12 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionAccessControl(ctx)
+	if err != nil {
+		ps.log.Error("Failed to provision access control", "error", err)
+		return err
+	}
+
 	return nil
 }
 
@@ -257,6 +276,16 @@ func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	return nil
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionAccessControl(ctx context.Context) error {
+	accessControlPath := filepath.Join(ps.Cfg.ProvisioningPath, "access-control")
+	if err := ps.provisionAccessControl(ctx, accessControlPath, ps.customRoleService, ps.teamService); err != nil {
+		err = fmt.Errorf("%v: %w", "access control provisioning error", err)
+		ps.log.Error("Failed to provision access control", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	err := ps.setDashboardProvisioner()
 	if err != nil {
@@ -333,6 +362,14 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+func (ps *ProvisioningServiceImpl) GetDashboardProvisionersStatus() []dashboards.ProvisionerStatus {
+	return ps.dashboardProvisioner.GetStatus()
+}
+
+func (ps *ProvisioningServiceImpl) WriteBackDashboard(ctx context.Context, cmd *dashboards.WriteBackDashboardCommand) error {
+	return ps.dashboardProvisioner.WriteBackDashboard(ctx, cmd)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
@@ -1,6 +1,10 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -8,8 +12,11 @@ type Calls struct {
 	ProvisionPlugins                    []any
 	ProvisionDashboards                 []any
 	ProvisionAlerting                   []any
+	ProvisionAccessControl              []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	GetDashboardProvisionersStatus      []any
+	WriteBackDashboard                  []any
 	Run                                 []any
 }
 
@@ -21,6 +28,8 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	GetDashboardProvisionersStatusFunc      func() []dashboards.ProvisionerStatus
+	WriteBackDashboardFunc                  func(ctx context.Context, cmd *dashboards.WriteBackDashboardCommand) error
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -67,6 +76,11 @@ func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) erro
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionAccessControl(ctx context.Context) error {
+	mock.Calls.ProvisionAccessControl = append(mock.Calls.ProvisionAccessControl, nil)
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
@@ -83,6 +97,22 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) GetDashboardProvisionersStatus() []dashboards.ProvisionerStatus {
+	mock.Calls.GetDashboardProvisionersStatus = append(mock.Calls.GetDashboardProvisionersStatus, nil)
+	if mock.GetDashboardProvisionersStatusFunc != nil {
+		return mock.GetDashboardProvisionersStatusFunc()
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) WriteBackDashboard(ctx context.Context, cmd *dashboards.WriteBackDashboardCommand) error {
+	mock.Calls.WriteBackDashboard = append(mock.Calls.WriteBackDashboard, cmd)
+	if mock.WriteBackDashboardFunc != nil {
+		return mock.WriteBackDashboardFunc(ctx, cmd)
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
@@ -0,0 +1,327 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// QuotaTargetSrvRate limits the number of data source queries per minute
+	QuotaTargetSrvRate quota.TargetSrv = "query_rate"
+	QuotaTargetRate    quota.Target    = "query_per_minute"
+
+	// QuotaTargetSrvConcurrency limits the number of data source queries running at the same time
+	QuotaTargetSrvConcurrency quota.TargetSrv = "query_concurrency"
+	QuotaTargetConcurrency    quota.Target    = "concurrent_query"
+
+	rateWindowSeconds = 60
+
+	// limitsCacheTTL is how long the limits of an organization and user are cached, so a
+	// change to the query quotas takes up to this long to apply.
+	limitsCacheTTL = time.Minute
+)
+
+// The tags of the query quotas, NewTag only fails for invalid scopes.
+var (
+	rateOrgTag, _        = quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.OrgScope)
+	rateUserTag, _       = quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.UserScope)
+	concurrencyOrgTag, _ = quota.NewTag(QuotaTargetSrvConcurrency, QuotaTargetConcurrency, quota.OrgScope)
+)
+
+// rateWindow counts events over the last minute in buckets of one second.
+type rateWindow struct {
+	counts [rateWindowSeconds]int64
+	stamps [rateWindowSeconds]int64
+}
+
+func (w *rateWindow) add(now time.Time) {
+	sec := now.Unix()
+	i := sec % rateWindowSeconds
+	if w.stamps[i] != sec {
+		w.stamps[i] = sec
+		w.counts[i] = 0
+	}
+	w.counts[i]++
+}
+
+func (w *rateWindow) count(now time.Time) int64 {
+	sec := now.Unix()
+	total := int64(0)
+	for i, stamp := range w.stamps {
+		if stamp > sec-rateWindowSeconds {
+			total += w.counts[i]
+		}
+	}
+	return total
+}
+
+func (w *rateWindow) idle(now time.Time) bool {
+	return w.count(now) == 0
+}
+
+type limitsKey struct {
+	orgID  int64
+	userID int64
+}
+
+type cachedLimits struct {
+	limits  *quota.Map
+	expires time.Time
+}
+
+// queryLimiter keeps track of the data source queries executed by this instance,
+// which are reported to the quota service for the query rate and concurrency quotas.
+// The usage is kept in memory, so the limits are enforced per instance.
+type queryLimiter struct {
+	quotaService quota.Service
+	now          func() time.Time
+
+	mu         sync.Mutex
+	orgRates   map[int64]*rateWindow
+	userRates  map[int64]*rateWindow
+	orgRunning map[int64]int64
+	limits     map[limitsKey]cachedLimits
+}
+
+func newQueryLimiter(quotaService quota.Service) *queryLimiter {
+	return &queryLimiter{
+		quotaService: quotaService,
+		now:          time.Now,
+		orgRates:     make(map[int64]*rateWindow),
+		userRates:    make(map[int64]*rateWindow),
+		orgRunning:   make(map[int64]int64),
+		limits:       make(map[limitsKey]cachedLimits),
+	}
+}
+
+func (l *queryLimiter) registerQuotas(cfg *setting.Cfg) error {
+	rateLimits, err := readRateQuotaConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := l.quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     QuotaTargetSrvRate,
+		DefaultLimits: rateLimits,
+		Reporter:      l.rateUsage,
+	}); err != nil {
+		return err
+	}
+
+	concurrencyLimits, err := readConcurrencyQuotaConfig(cfg)
+	if err != nil {
+		return err
+	}
+	return l.quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     QuotaTargetSrvConcurrency,
+		DefaultLimits: concurrencyLimits,
+		Reporter:      l.concurrencyUsage,
+	})
+}
+
+// acquire checks the query quotas of the user and records a new query. The returned
+// function must be called once the query is done.
+func (l *queryLimiter) acquire(ctx context.Context, user identity.Requester) (func(), error) {
+	if user == nil {
+		return func() {}, nil
+	}
+
+	params := &quota.ScopeParameters{OrgID: user.GetOrgID()}
+	if id, err := identity.UserIdentifier(user.GetID()); err == nil {
+		params.UserID = id
+	}
+
+	limits, err := l.getLimits(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// the usage is checked and incremented under the same lock, so concurrent queries
+	// cannot all pass the check before any of them is counted
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if limitReached(limits, rateOrgTag, countFor(l.orgRates, params.OrgID, now)) ||
+		(params.UserID != 0 && limitReached(limits, rateUserTag, countFor(l.userRates, params.UserID, now))) {
+		return nil, quota.NewErrTooManyRequests(QuotaTargetRate)
+	}
+	if limitReached(limits, concurrencyOrgTag, l.orgRunning[params.OrgID]) {
+		return nil, quota.NewErrTooManyRequests(QuotaTargetConcurrency)
+	}
+
+	windowFor(l.orgRates, params.OrgID).add(now)
+	if params.UserID != 0 {
+		windowFor(l.userRates, params.UserID).add(now)
+	}
+	l.orgRunning[params.OrgID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.orgRunning[params.OrgID]--
+			if l.orgRunning[params.OrgID] <= 0 {
+				delete(l.orgRunning, params.OrgID)
+			}
+		})
+	}, nil
+}
+
+// getLimits returns the query quota limits of the organization and user, which are
+// cached for limitsCacheTTL rather than looked up for every query.
+func (l *queryLimiter) getLimits(ctx context.Context, params *quota.ScopeParameters) (*quota.Map, error) {
+	key := limitsKey{orgID: params.OrgID, userID: params.UserID}
+
+	l.mu.Lock()
+	cached, ok := l.limits[key]
+	l.mu.Unlock()
+	if ok && l.now().Before(cached.expires) {
+		return cached.limits, nil
+	}
+
+	limits, err := l.quotaService.GetLimits(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[key] = cachedLimits{limits: limits, expires: l.now().Add(limitsCacheTTL)}
+	return limits, nil
+}
+
+// limitReached follows the rules of the quota service: a negative limit is unlimited
+// and a limit of 0 rejects every query.
+func limitReached(limits *quota.Map, tag quota.Tag, used int64) bool {
+	limit, ok := limits.Get(tag)
+	if !ok || limit < 0 {
+		return false
+	}
+	return used >= limit
+}
+
+// cleanup removes the rate windows without queries in the last minute and the expired limits.
+func (l *queryLimiter) cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, cached := range l.limits {
+		if !now.Before(cached.expires) {
+			delete(l.limits, key)
+		}
+	}
+	for _, rates := range []map[int64]*rateWindow{l.orgRates, l.userRates} {
+		for id, w := range rates {
+			if w.idle(now) {
+				delete(rates, id)
+			}
+		}
+	}
+}
+
+func (l *queryLimiter) rateUsage(_ context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u := &quota.Map{}
+	if scopeParams == nil {
+		return u, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if scopeParams.OrgID != 0 {
+		tag, err := quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.OrgScope)
+		if err != nil {
+			return nil, err
+		}
+		u.Set(tag, countFor(l.orgRates, scopeParams.OrgID, now))
+	}
+	if scopeParams.UserID != 0 {
+		tag, err := quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.UserScope)
+		if err != nil {
+			return nil, err
+		}
+		u.Set(tag, countFor(l.userRates, scopeParams.UserID, now))
+	}
+
+	return u, nil
+}
+
+func (l *queryLimiter) concurrencyUsage(_ context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u := &quota.Map{}
+	if scopeParams == nil || scopeParams.OrgID == 0 {
+		return u, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tag, err := quota.NewTag(QuotaTargetSrvConcurrency, QuotaTargetConcurrency, quota.OrgScope)
+	if err != nil {
+		return nil, err
+	}
+	u.Set(tag, l.orgRunning[scopeParams.OrgID])
+
+	return u, nil
+}
+
+func windowFor(rates map[int64]*rateWindow, id int64) *rateWindow {
+	w, ok := rates[id]
+	if !ok {
+		w = &rateWindow{}
+		rates[id] = w
+	}
+	return w
+}
+
+func countFor(rates map[int64]*rateWindow, id int64, now time.Time) int64 {
+	if w, ok := rates[id]; ok {
+		return w.count(now)
+	}
+	return 0
+}
+
+func readRateQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	limits := &quota.Map{}
+
+	if cfg == nil {
+		return limits, nil
+	}
+
+	orgQuotaTag, err := quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+	userQuotaTag, err := quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.UserScope)
+	if err != nil {
+		return limits, err
+	}
+
+	limits.Set(orgQuotaTag, cfg.Quota.Org.QueryPerMinute)
+	limits.Set(userQuotaTag, cfg.Quota.User.QueryPerMinute)
+	return limits, nil
+}
+
+func readConcurrencyQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	limits := &quota.Map{}
+
+	if cfg == nil {
+		return limits, nil
+	}
+
+	orgQuotaTag, err := quota.NewTag(QuotaTargetSrvConcurrency, QuotaTargetConcurrency, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+
+	limits.Set(orgQuotaTag, cfg.Quota.Org.ConcurrentQuery)
+	return limits, nil
+}
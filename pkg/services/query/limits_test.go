@@ -0,0 +1,133 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestQueryLimiter(t *testing.T) {
+	signedInUser := &user.SignedInUser{UserID: 2, OrgID: 1}
+	rateOrgTag, err := quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.OrgScope)
+	require.NoError(t, err)
+	rateUserTag, err := quota.NewTag(QuotaTargetSrvRate, QuotaTargetRate, quota.UserScope)
+	require.NoError(t, err)
+	concurrencyTag, err := quota.NewTag(QuotaTargetSrvConcurrency, QuotaTargetConcurrency, quota.OrgScope)
+	require.NoError(t, err)
+
+	usage := func(t *testing.T, reporter quota.UsageReporterFunc, tag quota.Tag) int64 {
+		t.Helper()
+		u, err := reporter(context.Background(), &quota.ScopeParameters{OrgID: 1, UserID: 2})
+		require.NoError(t, err)
+		v, ok := u.Get(tag)
+		require.True(t, ok)
+		return v
+	}
+
+	t.Run("should report the queries of the last minute", func(t *testing.T) {
+		now := time.Now()
+		l := newQueryLimiter(quotatest.New(false, nil))
+		l.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			release, err := l.acquire(context.Background(), signedInUser)
+			require.NoError(t, err)
+			release()
+		}
+
+		assert.Equal(t, int64(3), usage(t, l.rateUsage, rateOrgTag))
+		assert.Equal(t, int64(3), usage(t, l.rateUsage, rateUserTag))
+
+		now = now.Add(61 * time.Second)
+		assert.Equal(t, int64(0), usage(t, l.rateUsage, rateOrgTag))
+
+		l.cleanup()
+		assert.Empty(t, l.orgRates)
+		assert.Empty(t, l.userRates)
+	})
+
+	t.Run("should report the running queries", func(t *testing.T) {
+		l := newQueryLimiter(quotatest.New(false, nil))
+
+		release1, err := l.acquire(context.Background(), signedInUser)
+		require.NoError(t, err)
+		release2, err := l.acquire(context.Background(), signedInUser)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), usage(t, l.concurrencyUsage, concurrencyTag))
+
+		release1()
+		release1()
+		assert.Equal(t, int64(1), usage(t, l.concurrencyUsage, concurrencyTag))
+
+		release2()
+		assert.Equal(t, int64(0), usage(t, l.concurrencyUsage, concurrencyTag))
+	})
+
+	t.Run("should reject the query when the quota is reached", func(t *testing.T) {
+		limits := &quota.Map{}
+		limits.Set(rateOrgTag, 0)
+		quotaService := quotatest.New(false, nil)
+		quotaService.ExpectedLimits = limits
+		l := newQueryLimiter(quotaService)
+
+		_, err := l.acquire(context.Background(), signedInUser)
+		require.ErrorIs(t, err, quota.ErrTooManyRequests)
+		assert.Empty(t, l.orgRates)
+		assert.Empty(t, l.orgRunning)
+	})
+
+	t.Run("should count the running queries towards the concurrency quota", func(t *testing.T) {
+		limits := &quota.Map{}
+		limits.Set(concurrencyTag, 1)
+		quotaService := quotatest.New(false, nil)
+		quotaService.ExpectedLimits = limits
+		l := newQueryLimiter(quotaService)
+
+		release, err := l.acquire(context.Background(), signedInUser)
+		require.NoError(t, err)
+		_, err = l.acquire(context.Background(), signedInUser)
+		require.ErrorIs(t, err, quota.ErrTooManyRequests)
+
+		release()
+		release, err = l.acquire(context.Background(), signedInUser)
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("should cache the limits", func(t *testing.T) {
+		now := time.Now()
+		quotaService := &countingQuotaService{FakeQuotaService: quotatest.New(false, nil)}
+		l := newQueryLimiter(quotaService)
+		l.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			release, err := l.acquire(context.Background(), signedInUser)
+			require.NoError(t, err)
+			release()
+		}
+		assert.Equal(t, 1, quotaService.getLimitsCalls)
+
+		now = now.Add(limitsCacheTTL)
+		release, err := l.acquire(context.Background(), signedInUser)
+		require.NoError(t, err)
+		release()
+		assert.Equal(t, 2, quotaService.getLimitsCalls)
+	})
+}
+
+type countingQuotaService struct {
+	*quotatest.FakeQuotaService
+	getLimitsCalls int
+}
+
+func (s *countingQuotaService) GetLimits(ctx context.Context, params *quota.ScopeParameters) (*quota.Map, error) {
+	s.getLimitsCalls++
+	return s.FakeQuotaService.GetLimits(ctx, params)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -45,6 +46,7 @@ func ProvideService(
 	dataSourceRequestValidator validations.DataSourceRequestValidator,
 	pluginClient plugins.Client,
 	pCtxProvider *plugincontext.Provider,
+	quotaService quota.Service,
 ) *ServiceImpl {
 	g := &ServiceImpl{
 		cfg:                        cfg,
@@ -55,6 +57,10 @@ func ProvideService(
 		pCtxProvider:               pCtxProvider,
 		log:                        log.New("query_data"),
 		concurrentQueryLimit:       cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU()),
+		limiter:                    newQueryLimiter(quotaService),
+	}
+	if err := g.limiter.registerQuotas(cfg); err != nil {
+		g.log.Error("Failed to register query quotas", "error", err)
 	}
 	g.log.Info("Query Service initialization")
 	return g
@@ -78,16 +84,33 @@ type ServiceImpl struct {
 	pCtxProvider               *plugincontext.Provider
 	log                        log.Logger
 	concurrentQueryLimit       int
+	limiter                    *queryLimiter
 }
 
 // Run ServiceImpl.
 func (s *ServiceImpl) Run(ctx context.Context) error {
-	<-ctx.Done()
-	return ctx.Err()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.limiter.cleanup()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
 // QueryData processes queries and returns query responses. It handles queries to single or mixed datasources, as well as expressions.
 func (s *ServiceImpl) QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	// Check the query rate and concurrency quotas
+	release, err := s.limiter.acquire(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// Parse the request into parsed queries grouped by datasource uid
 	parsedReq, err := s.parseMetricRequest(ctx, user, skipDSCache, reqDTO)
 	if err != nil {
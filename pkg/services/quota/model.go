@@ -17,10 +17,31 @@ var ErrUsageFoundForTarget = errutil.NotFound("quota.missing-target-usage")
 var ErrTargetSrvConflict = errutil.BadRequest("quota.target-srv-conflict")
 var ErrDisabled = errutil.Forbidden("quota.disabled", errutil.WithPublicMessage("Quotas not enabled"))
 var ErrInvalidTagFormat = errutil.Internal("quota.invalid-invalid-tag-format")
+var ErrQuotaReached = errutil.Forbidden("quota.reached").MustTemplate(
+	"quota reached for target {{ .Public.Target }}",
+	errutil.WithPublic("Quota reached for {{ .Public.Target }}"),
+)
+var ErrTooManyRequests = errutil.TooManyRequests("quota.too-many-requests").MustTemplate(
+	"quota reached for target {{ .Public.Target }}",
+	errutil.WithPublic("Quota reached for {{ .Public.Target }}, try again later"),
+)
+
+// NewErrQuotaReached returns an ErrQuotaReached error for the target.
+func NewErrQuotaReached(target Target) error {
+	return ErrQuotaReached.Build(errutil.TemplateData{Public: map[string]any{"Target": target}})
+}
+
+// NewErrTooManyRequests returns an ErrTooManyRequests error for the target.
+// It is used for the rate and concurrency targets, where the usage goes down over time.
+func NewErrTooManyRequests(target Target) error {
+	return ErrTooManyRequests.Build(errutil.TemplateData{Public: map[string]any{"Target": target}})
+}
 
 type ScopeParameters struct {
 	OrgID  int64
 	UserID int64
+	// FolderUID is only used by the folder scope, together with OrgID.
+	FolderUID string
 }
 
 type Scope string
@@ -29,11 +50,13 @@ const (
 	GlobalScope Scope = "global"
 	OrgScope    Scope = "org"
 	UserScope   Scope = "user"
+	// FolderScope limits apply to every folder of an organization.
+	FolderScope Scope = "folder"
 )
 
 func (s Scope) Validate() error {
 	switch s {
-	case GlobalScope, OrgScope, UserScope:
+	case GlobalScope, OrgScope, UserScope, FolderScope:
 		return nil
 	default:
 		return ErrInvalidScope.Errorf("bad scope: %s", s)
@@ -191,13 +214,14 @@ type Quota struct {
 }
 
 type QuotaDTO struct {
-	OrgId   int64  `json:"org_id,omitempty"`
-	UserId  int64  `json:"user_id,omitempty"`
-	Target  string `json:"target"`
-	Limit   int64  `json:"limit"`
-	Used    int64  `json:"used"`
-	Service string `json:"-"`
-	Scope   string `json:"-"`
+	OrgId     int64  `json:"org_id,omitempty"`
+	UserId    int64  `json:"user_id,omitempty"`
+	FolderUID string `json:"folder_uid,omitempty"`
+	Target    string `json:"target"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+	Service   string `json:"-"`
+	Scope     string `json:"-"`
 }
 
 func (dto QuotaDTO) Tag() (Tag, error) {
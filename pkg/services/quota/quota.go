@@ -11,6 +11,8 @@ type Service interface {
 	// If the scope is organization, the ID is expected to be the organisation ID.
 	// If the scope is user, the id is expected to be the user ID.
 	GetQuotasByScope(ctx context.Context, scope Scope, ID int64) ([]QuotaDTO, error)
+	// GetFolderQuotas returns the folder scope quota for a folder of the organization.
+	GetFolderQuotas(ctx context.Context, orgID int64, folderUID string) ([]QuotaDTO, error)
 	// Update overrides the quota for a specific scope (global, organization, user).
 	// If the cmd.OrgID is set, then the organization quota are updated.
 	// If the cmd.UseID is set, then the user quota are updated.
@@ -19,6 +21,9 @@ type Service interface {
 	QuotaReached(c *contextmodel.ReqContext, targetSrv TargetSrv) (bool, error)
 	// CheckQuotaReached checks if the quota limitations have been reached for a specific service
 	CheckQuotaReached(ctx context.Context, targetSrv TargetSrv, scopeParams *ScopeParameters) (bool, error)
+	// GetLimits returns the limits of every target for the scope parameters, with the custom
+	// limits of the organization and the user applied. The usage is not counted.
+	GetLimits(ctx context.Context, scopeParams *ScopeParameters) (*Map, error)
 	// DeleteQuotaForUser deletes custom quota limitations for the user
 	DeleteQuotaForUser(ctx context.Context, userID int64) error
 	// DeleteByOrg(ctx context.Context, orgID int64) error
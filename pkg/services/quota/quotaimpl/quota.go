@@ -28,6 +28,10 @@ func (s *serviceDisabled) GetQuotasByScope(ctx context.Context, scope quota.Scop
 	return nil, quota.ErrDisabled
 }
 
+func (s *serviceDisabled) GetFolderQuotas(ctx context.Context, orgID int64, folderUID string) ([]quota.QuotaDTO, error) {
+	return nil, quota.ErrDisabled
+}
+
 func (s *serviceDisabled) Update(ctx context.Context, cmd *quota.UpdateQuotaCmd) error {
 	return quota.ErrDisabled
 }
@@ -36,6 +40,10 @@ func (s *serviceDisabled) CheckQuotaReached(ctx context.Context, targetSrv quota
 	return false, nil
 }
 
+func (s *serviceDisabled) GetLimits(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	return &quota.Map{}, nil
+}
+
 func (s *serviceDisabled) DeleteQuotaForUser(ctx context.Context, userID int64) error {
 	return nil
 }
@@ -103,8 +111,6 @@ func (s *service) GetQuotasByScope(ctx context.Context, scope quota.Scope, id in
 		return nil, err
 	}
 
-	q := make([]quota.QuotaDTO, 0)
-
 	scopeParams := quota.ScopeParameters{}
 	if scope == quota.OrgScope {
 		scopeParams.OrgID = id
@@ -112,13 +118,29 @@ func (s *service) GetQuotasByScope(ctx context.Context, scope quota.Scope, id in
 		scopeParams.UserID = id
 	}
 
+	return s.getQuotas(ctx, scope, &scopeParams)
+}
+
+func (s *service) GetFolderQuotas(ctx context.Context, orgID int64, folderUID string) ([]quota.QuotaDTO, error) {
+	ctx, span := tracer.Start(ctx, "quota-service.GetFolderQuotas")
+	defer span.End()
+	if orgID == 0 || folderUID == "" {
+		return nil, quota.ErrBadRequest.Errorf("folder quota requires an organization and a folder")
+	}
+
+	return s.getQuotas(ctx, quota.FolderScope, &quota.ScopeParameters{OrgID: orgID, FolderUID: folderUID})
+}
+
+func (s *service) getQuotas(ctx context.Context, scope quota.Scope, scopeParams *quota.ScopeParameters) ([]quota.QuotaDTO, error) {
+	q := make([]quota.QuotaDTO, 0)
+
 	c := quota.FromContext(ctx, s.targetToSrv)
-	customLimits, err := s.store.Get(c, &scopeParams)
+	customLimits, err := s.store.Get(c, scopeParams)
 	if err != nil {
 		return nil, err
 	}
 
-	u, err := s.getUsage(ctx, &scopeParams)
+	u, err := s.getUsage(ctx, scopeParams)
 	if err != nil {
 		return nil, err
 	}
@@ -151,13 +173,14 @@ func (s *service) GetQuotasByScope(ctx context.Context, scope quota.Scope, id in
 
 		used, _ := u.Get(item.Tag)
 		q = append(q, quota.QuotaDTO{
-			Target:  string(target),
-			Limit:   limit,
-			OrgId:   scopeParams.OrgID,
-			UserId:  scopeParams.UserID,
-			Used:    used,
-			Service: string(srv),
-			Scope:   string(scope),
+			Target:    string(target),
+			Limit:     limit,
+			OrgId:     scopeParams.OrgID,
+			UserId:    scopeParams.UserID,
+			FolderUID: scopeParams.FolderUID,
+			Used:      used,
+			Service:   string(srv),
+			Scope:     string(scope),
 		})
 	}
 
@@ -226,6 +249,11 @@ func (s *service) CheckQuotaReached(ctx context.Context, targetSrv quota.TargetS
 				continue
 			}
 
+			// do not check folder quota if the folder information is not available (eg the General folder)
+			if scope == quota.FolderScope && (scopeParams == nil || scopeParams.OrgID == 0 || scopeParams.FolderUID == "") {
+				continue
+			}
+
 			u, ok := targetUsage.Get(t)
 			if !ok {
 				return false, quota.ErrUsageFoundForTarget.Errorf("no usage for target:%s", t)
@@ -238,6 +266,27 @@ func (s *service) CheckQuotaReached(ctx context.Context, targetSrv quota.TargetS
 	return false, nil
 }
 
+func (s *service) GetLimits(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	ctx, span := tracer.Start(ctx, "quota-service.GetLimits")
+	defer span.End()
+
+	c := quota.FromContext(ctx, s.targetToSrv)
+	customLimits, err := s.store.Get(c, scopeParams)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := &quota.Map{}
+	for item := range s.defaultLimits.Iter() {
+		limit := item.Value
+		if customLimit, ok := customLimits.Get(item.Tag); ok {
+			limit = customLimit
+		}
+		limits.Set(item.Tag, limit)
+	}
+	return limits, nil
+}
+
 func (s *service) DeleteQuotaForUser(ctx context.Context, userID int64) error {
 	ctx, span := tracer.Start(ctx, "quota-service.DeleteQuotaForUser")
 	defer span.End()
@@ -482,6 +482,8 @@ func getQuotaBySrvTargetScope(t *testing.T, quotaService quota.Service, srv quot
 }
 
 func setupEnv(t *testing.T, sqlStore db.DB, cfg *setting.Cfg, b bus.Bus, quotaService quota.Service) {
+	// the storage service writes its config to the data path
+	cfg.DataPath = t.TempDir()
 	tracer := tracing.InitializeTracerForTest()
 	_, err := apikeyimpl.ProvideService(sqlStore, cfg, quotaService)
 	require.NoError(t, err)
@@ -519,3 +521,123 @@ func setupEnv(t *testing.T, sqlStore db.DB, cfg *setting.Cfg, b bus.Bus, quotaSe
 	_, err = storesrv.ProvideService(sqlStore, featuremgmt.WithFeatures(), cfg, quotaService, storesrv.ProvideSystemUsersService())
 	require.NoError(t, err)
 }
+
+type emptyQuotaStore struct {
+	quotatest.FakeQuotaStore
+}
+
+func (f *emptyQuotaStore) Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	return &quota.Map{}, nil
+}
+
+func TestQuotaService_FolderScope(t *testing.T) {
+	const srv = quota.TargetSrv("test")
+	const target = quota.Target("folder_item")
+
+	folderTag, err := quota.NewTag(srv, target, quota.FolderScope)
+	require.NoError(t, err)
+
+	quotaService := &service{
+		store:         &emptyQuotaStore{},
+		reporters:     make(map[quota.TargetSrv]quota.UsageReporterFunc),
+		defaultLimits: &quota.Map{},
+		targetToSrv:   quota.NewTargetToSrv(),
+	}
+
+	limits := &quota.Map{}
+	limits.Set(folderTag, 2)
+	usage := map[string]int64{"full": 2, "empty": 0}
+	require.NoError(t, quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     srv,
+		DefaultLimits: limits,
+		Reporter: func(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+			u := &quota.Map{}
+			if scopeParams != nil && scopeParams.FolderUID != "" {
+				u.Set(folderTag, usage[scopeParams.FolderUID])
+			}
+			return u, nil
+		},
+	}))
+
+	t.Run("should check the usage of the folder", func(t *testing.T) {
+		reached, err := quotaService.CheckQuotaReached(context.Background(), srv, &quota.ScopeParameters{OrgID: 1, FolderUID: "full"})
+		require.NoError(t, err)
+		require.True(t, reached)
+
+		reached, err = quotaService.CheckQuotaReached(context.Background(), srv, &quota.ScopeParameters{OrgID: 1, FolderUID: "empty"})
+		require.NoError(t, err)
+		require.False(t, reached)
+	})
+
+	t.Run("should skip the folder quota without a folder", func(t *testing.T) {
+		reached, err := quotaService.CheckQuotaReached(context.Background(), srv, &quota.ScopeParameters{OrgID: 1})
+		require.NoError(t, err)
+		require.False(t, reached)
+	})
+
+	t.Run("should return the folder quota", func(t *testing.T) {
+		result, err := quotaService.GetFolderQuotas(context.Background(), 1, "full")
+		require.NoError(t, err)
+		require.Equal(t, []quota.QuotaDTO{{
+			OrgId:     1,
+			FolderUID: "full",
+			Target:    string(target),
+			Limit:     2,
+			Used:      2,
+			Service:   string(srv),
+			Scope:     string(quota.FolderScope),
+		}}, result)
+
+		_, err = quotaService.GetFolderQuotas(context.Background(), 1, "")
+		require.ErrorIs(t, err, quota.ErrBadRequest)
+	})
+}
+
+type customLimitQuotaStore struct {
+	quotatest.FakeQuotaStore
+	limits *quota.Map
+}
+
+func (f *customLimitQuotaStore) Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	return f.limits, nil
+}
+
+func TestQuotaService_GetLimits(t *testing.T) {
+	const srv = quota.TargetSrv("test")
+
+	orgTag, err := quota.NewTag(srv, "item", quota.OrgScope)
+	require.NoError(t, err)
+	userTag, err := quota.NewTag(srv, "item", quota.UserScope)
+	require.NoError(t, err)
+
+	custom := &quota.Map{}
+	custom.Set(orgTag, 5)
+	quotaService := &service{
+		store:         &customLimitQuotaStore{limits: custom},
+		reporters:     make(map[quota.TargetSrv]quota.UsageReporterFunc),
+		defaultLimits: &quota.Map{},
+		targetToSrv:   quota.NewTargetToSrv(),
+	}
+
+	defaults := &quota.Map{}
+	defaults.Set(orgTag, 10)
+	defaults.Set(userTag, 20)
+	require.NoError(t, quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     srv,
+		DefaultLimits: defaults,
+		Reporter: func(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+			t.Fatal("the usage should not be counted")
+			return nil, nil
+		},
+	}))
+
+	limits, err := quotaService.GetLimits(context.Background(), &quota.ScopeParameters{OrgID: 1, UserID: 2})
+	require.NoError(t, err)
+
+	limit, ok := limits.Get(orgTag)
+	require.True(t, ok)
+	require.Equal(t, int64(5), limit)
+	limit, ok = limits.Get(userTag)
+	require.True(t, ok)
+	require.Equal(t, int64(20), limit)
+}
@@ -10,16 +10,22 @@ import (
 type FakeQuotaService struct {
 	reached bool
 	err     error
+
+	ExpectedLimits *quota.Map
 }
 
 func New(reached bool, err error) *FakeQuotaService {
-	return &FakeQuotaService{reached, err}
+	return &FakeQuotaService{reached: reached, err: err}
 }
 
 func (f *FakeQuotaService) GetQuotasByScope(ctx context.Context, scope quota.Scope, id int64) ([]quota.QuotaDTO, error) {
 	return []quota.QuotaDTO{}, nil
 }
 
+func (f *FakeQuotaService) GetFolderQuotas(ctx context.Context, orgID int64, folderUID string) ([]quota.QuotaDTO, error) {
+	return []quota.QuotaDTO{}, nil
+}
+
 func (f *FakeQuotaService) Update(ctx context.Context, cmd *quota.UpdateQuotaCmd) error {
 	return nil
 }
@@ -32,6 +38,13 @@ func (f *FakeQuotaService) CheckQuotaReached(c context.Context, target quota.Tar
 	return f.reached, f.err
 }
 
+func (f *FakeQuotaService) GetLimits(c context.Context, params *quota.ScopeParameters) (*quota.Map, error) {
+	if f.ExpectedLimits == nil {
+		return &quota.Map{}, f.err
+	}
+	return f.ExpectedLimits, f.err
+}
+
 func (f *FakeQuotaService) DeleteQuotaForUser(c context.Context, userID int64) error {
 	return f.err
 }
@@ -0,0 +1,150 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	ActionRead  = "reports:read"
+	ActionWrite = "reports:write"
+	// ActionSend sends a report immediately, outside of its schedule
+	ActionSend = "reports:send"
+)
+
+const (
+	// FormatPDF sends a single document with a page per panel
+	FormatPDF = "pdf"
+	// FormatPNG sends an image per panel
+	FormatPNG = "png"
+	// FormatCSV sends the data of each panel with queries
+	FormatCSV = "csv"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	// DeliveryStatusQueued is the status of the deliveries whose emails were queued in the notification outbox
+	DeliveryStatusQueued = "queued"
+	DeliveryStatusFailed = "failed"
+)
+
+var (
+	ErrReportNotFound    = errutil.NotFound("reports.not-found", errutil.WithPublicMessage("Report not found"))
+	ErrReportingDisabled = errutil.BadRequest("reports.disabled", errutil.WithPublicMessage("Reporting is disabled"))
+	ErrDashboardAccess   = errutil.Forbidden("reports.dashboard-access", errutil.WithPublicMessage("You do not have access to the dashboard of the report"))
+	ErrInvalidReport     = errutil.BadRequest("reports.invalid").MustTemplate(
+		"invalid report: {{ .Public.Reason }}",
+		errutil.WithPublic("Invalid report: {{ .Public.Reason }}"),
+	)
+)
+
+func errInvalidReport(reason string) error {
+	return ErrInvalidReport.Build(errutil.TemplateData{Public: map[string]any{"Reason": reason}})
+}
+
+// Service manages the reports of the organizations, dashboards rendered and sent by email on a schedule.
+type Service interface {
+	Get(ctx context.Context, orgID int64, uid string) (*Report, error)
+	List(ctx context.Context, orgID int64) ([]*Report, error)
+	Create(ctx context.Context, cmd *CreateReportCommand) (*Report, error)
+	Update(ctx context.Context, cmd *UpdateReportCommand) (*Report, error)
+	// Delete removes the report and its delivery history.
+	Delete(ctx context.Context, orgID int64, uid string) error
+	// Send renders and sends the report immediately, outside of its schedule.
+	Send(ctx context.Context, orgID int64, uid string) (*Delivery, error)
+	GetDeliveries(ctx context.Context, query *GetDeliveriesQuery) ([]*Delivery, error)
+}
+
+// Report is a dashboard rendered and sent by email on a schedule. It is rendered with the permissions
+// of the user who last saved it.
+type Report struct {
+	ID     int64  `xorm:"pk autoincr 'id'" json:"id"`
+	UID    string `xorm:"uid" json:"uid"`
+	OrgID  int64  `xorm:"org_id" json:"orgId"`
+	UserID int64  `xorm:"user_id" json:"userId"`
+	Name   string `xorm:"name" json:"name"`
+
+	DashboardUID string `xorm:"dashboard_uid" json:"dashboardUid"`
+	// Variables are the values of the template variables of the dashboard, by variable name
+	Variables map[string][]string `xorm:"variables" json:"variables"`
+	// TimeFrom and TimeTo default to the time range of the dashboard
+	TimeFrom string `xorm:"time_from" json:"timeFrom"`
+	TimeTo   string `xorm:"time_to" json:"timeTo"`
+
+	// Schedule is a cron expression evaluated in the time zone of the report
+	Schedule string `xorm:"schedule" json:"schedule"`
+	// Timezone is a location of the IANA time zone database, used for the schedule and the rendering
+	Timezone   string   `xorm:"timezone" json:"timezone"`
+	Recipients []string `xorm:"recipients" json:"recipients"`
+	Format     string   `xorm:"format" json:"format"`
+	Message    string   `xorm:"message" json:"message"`
+	Enabled    bool     `xorm:"enabled" json:"enabled"`
+	// NextRun is when the report is sent next, in seconds since epoch
+	NextRun int64 `xorm:"next_run" json:"-"`
+
+	Created time.Time `xorm:"created" json:"created"`
+	Updated time.Time `xorm:"updated" json:"updated"`
+}
+
+func (Report) TableName() string {
+	return "dashboard_report"
+}
+
+// Delivery is a sending of a report.
+type Delivery struct {
+	ID       int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID    int64  `xorm:"org_id" json:"-"`
+	ReportID int64  `xorm:"report_id" json:"reportId"`
+	Trigger  string `xorm:"trigger_type" json:"trigger"`
+	Status   string `xorm:"status" json:"status"`
+	Error    string `xorm:"error" json:"error,omitempty"`
+	// Recipients are the ones the report was sent to, which may have changed since
+	Recipients  []string  `xorm:"recipients" json:"recipients"`
+	Attachments int       `xorm:"attachments" json:"attachments"`
+	Started     time.Time `xorm:"started" json:"started"`
+	Finished    time.Time `xorm:"finished" json:"finished"`
+}
+
+func (Delivery) TableName() string {
+	return "dashboard_report_delivery"
+}
+
+// ReportSettings are the settings of a report set by the users.
+type ReportSettings struct {
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	Variables    map[string][]string `json:"variables"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Schedule     string              `json:"schedule"`
+	Timezone     string              `json:"timezone"`
+	Recipients   []string            `json:"recipients"`
+	Format       string              `json:"format"`
+	Message      string              `json:"message"`
+	Enabled      bool                `json:"enabled"`
+}
+
+type CreateReportCommand struct {
+	ReportSettings
+	OrgID int64 `json:"-"`
+	// User saving the report, whose permissions are used to render it
+	User *user.SignedInUser `json:"-"`
+}
+
+type UpdateReportCommand struct {
+	ReportSettings
+	UID   string             `json:"-"`
+	OrgID int64              `json:"-"`
+	User  *user.SignedInUser `json:"-"`
+}
+
+type GetDeliveriesQuery struct {
+	OrgID     int64
+	ReportUID string
+	Limit     int
+}
@@ -0,0 +1,135 @@
+package reportsimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type reportsAPI struct {
+	service       *Service
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+}
+
+func newAPI(service *Service, accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *reportsAPI {
+	return &reportsAPI{
+		service:       service,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+	}
+}
+
+func (api *reportsAPI) registerAPIEndpoints() {
+	authorize := accesscontrol.Middleware(api.accessControl)
+
+	api.routeRegister.Group("/api/reports", func(router routing.RouteRegister) {
+		router.Get("/", authorize(accesscontrol.EvalPermission(reports.ActionRead)), routing.Wrap(api.listReports))
+		router.Post("/", authorize(accesscontrol.EvalPermission(reports.ActionWrite)), routing.Wrap(api.createReport))
+		router.Get("/:uid", authorize(accesscontrol.EvalPermission(reports.ActionRead)), routing.Wrap(api.getReport))
+		router.Put("/:uid", authorize(accesscontrol.EvalPermission(reports.ActionWrite)), routing.Wrap(api.updateReport))
+		router.Delete("/:uid", authorize(accesscontrol.EvalPermission(reports.ActionWrite)), routing.Wrap(api.deleteReport))
+		router.Post("/:uid/send", authorize(accesscontrol.EvalPermission(reports.ActionSend)), routing.Wrap(api.sendReport))
+		router.Get("/:uid/deliveries", authorize(accesscontrol.EvalPermission(reports.ActionRead)), routing.Wrap(api.getDeliveries))
+	}, middleware.ReqSignedIn)
+}
+
+// reportDTO is a report with the time it is sent next, which is not set if the report is disabled.
+type reportDTO struct {
+	*reports.Report
+	NextRun *time.Time `json:"nextRun,omitempty"`
+}
+
+func toDTO(report *reports.Report) reportDTO {
+	dto := reportDTO{Report: report}
+	if report.Enabled {
+		next := time.Unix(report.NextRun, 0).UTC()
+		dto.NextRun = &next
+	}
+	return dto
+}
+
+func (api *reportsAPI) listReports(c *contextmodel.ReqContext) response.Response {
+	list, err := api.service.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list reports", err)
+	}
+	result := make([]reportDTO, 0, len(list))
+	for _, report := range list {
+		result = append(result, toDTO(report))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (api *reportsAPI) getReport(c *contextmodel.ReqContext) response.Response {
+	report, err := api.service.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get report", err)
+	}
+	return response.JSON(http.StatusOK, toDTO(report))
+}
+
+func (api *reportsAPI) createReport(c *contextmodel.ReqContext) response.Response {
+	cmd := reports.CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.ErrOrFallback(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.User = c.SignedInUser
+
+	report, err := api.service.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create report", err)
+	}
+	return response.JSON(http.StatusOK, toDTO(report))
+}
+
+func (api *reportsAPI) updateReport(c *contextmodel.ReqContext) response.Response {
+	cmd := reports.UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.ErrOrFallback(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.User = c.SignedInUser
+
+	report, err := api.service.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update report", err)
+	}
+	return response.JSON(http.StatusOK, toDTO(report))
+}
+
+func (api *reportsAPI) deleteReport(c *contextmodel.ReqContext) response.Response {
+	if err := api.service.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete report", err)
+	}
+	return response.Success("Report deleted")
+}
+
+func (api *reportsAPI) sendReport(c *contextmodel.ReqContext) response.Response {
+	delivery, err := api.service.Send(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to send report", err)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
+
+func (api *reportsAPI) getDeliveries(c *contextmodel.ReqContext) response.Response {
+	deliveries, err := api.service.GetDeliveries(c.Req.Context(), &reports.GetDeliveriesQuery{
+		OrgID:     c.SignedInUser.GetOrgID(),
+		ReportUID: web.Params(c.Req)[":uid"],
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get report deliveries", err)
+	}
+	return response.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,70 @@
+package reportsimpl
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/grafana/gofpdf"
+)
+
+const (
+	pdfMargin     = 10.0
+	pdfTitleSize  = 14.0
+	pdfTextSize   = 9.0
+	pdfLineHeight = 7.0
+)
+
+// pdfPage is a panel image with its title, printed on its own page.
+type pdfPage struct {
+	Title string
+	Image []byte
+}
+
+// buildPDF assembles the images of the panels in a landscape A4 document with a page per panel. Each
+// page starts with the title of the dashboard, the description of the time range and the title of the panel.
+func buildPDF(title, description string, pages []pdfPage) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Grafana", true)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	// the core fonts only cover code page 1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pdfMargin
+
+	for i, page := range pages {
+		pdf.AddPage()
+
+		pdf.SetFont("Helvetica", "B", pdfTitleSize)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(contentWidth, pdfLineHeight, tr(title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", pdfTextSize)
+		pdf.SetTextColor(96, 96, 96)
+		pdf.CellFormat(contentWidth, pdfLineHeight, tr(description), "", 1, "L", false, 0, "")
+		if page.Title != "" {
+			pdf.SetFont("Helvetica", "B", pdfTextSize+2)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.CellFormat(contentWidth, pdfLineHeight, tr(page.Title), "", 1, "L", false, 0, "")
+		}
+
+		name := fmt.Sprintf("panel-%d", i)
+		info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(page.Image))
+		if pdf.Err() {
+			return nil, fmt.Errorf("failed to add the image of panel %q: %w", page.Title, pdf.Error())
+		}
+
+		// the image is scaled down to the space left on the page, keeping its aspect ratio
+		top := pdf.GetY() + 2
+		width, height := info.Width(), info.Height()
+		scale := min(contentWidth/width, (pageHeight-pdfMargin-top)/height, 1)
+		pdf.ImageOptions(name, pdfMargin, top, width*scale, height*scale, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
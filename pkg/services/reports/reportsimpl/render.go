@@ -0,0 +1,202 @@
+package reportsimpl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	defaultTimeFrom = "now-6h"
+	defaultTimeTo   = "now"
+)
+
+// reportPanel is a panel of the dashboard of a report.
+type reportPanel struct {
+	ID         int64
+	Title      string
+	HasQueries bool
+}
+
+// reportPanels returns the panels of the dashboard in their order, including the ones of the collapsed rows
+// and excluding the rows themselves.
+func reportPanels(dashboard *simplejson.Json) []reportPanel {
+	result := make([]reportPanel, 0)
+	var add func(panels []any)
+	add = func(panels []any) {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			if panel.Get("type").MustString() == "row" {
+				add(panel.Get("panels").MustArray())
+				continue
+			}
+			result = append(result, reportPanel{
+				ID:         panel.Get("id").MustInt64(),
+				Title:      panel.Get("title").MustString(),
+				HasQueries: len(panel.Get("targets").MustArray()) > 0,
+			})
+		}
+	}
+	add(dashboard.Get("panels").MustArray())
+	return result
+}
+
+// timeRange returns the time range of the report, the one of the dashboard if the report does not set one.
+func timeRange(report *reports.Report, dashboard *simplejson.Json) (string, string) {
+	if report.TimeFrom != "" {
+		return report.TimeFrom, report.TimeTo
+	}
+	from, to := dashboard.GetPath("time", "from").MustString(), dashboard.GetPath("time", "to").MustString()
+	if from == "" || to == "" {
+		return defaultTimeFrom, defaultTimeTo
+	}
+	return from, to
+}
+
+// dashboardQuery returns the query parameters setting the variables and the time range of the report
+// on the dashboard.
+func dashboardQuery(report *reports.Report, dashboard *dashboards.Dashboard, from, to string) url.Values {
+	q := url.Values{}
+	q.Add("orgId", strconv.FormatInt(dashboard.OrgID, 10))
+	q.Add("from", from)
+	q.Add("to", to)
+
+	names := make([]string, 0, len(report.Variables))
+	for name := range report.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range report.Variables[name] {
+			q.Add("var-"+name, value)
+		}
+	}
+	return q
+}
+
+// panelPath returns the path of the page rendering a single panel of the dashboard.
+func panelPath(report *reports.Report, dashboard *dashboards.Dashboard, panelID int64, from, to string) string {
+	q := dashboardQuery(report, dashboard, from, to)
+	q.Add("panelId", strconv.FormatInt(panelID, 10))
+	u := url.URL{Path: path.Join("d-solo", dashboard.UID, dashboard.Slug), RawQuery: q.Encode()}
+	return u.String()
+}
+
+// render renders the panels of the dashboard of the report in its format, as the user the report is
+// rendered as.
+func (s *Service) render(ctx context.Context, report *reports.Report, dashboard *dashboards.Dashboard, renderUser *user.SignedInUser) ([]*notifications.SendEmailAttachFile, error) {
+	panels := reportPanels(dashboard.Data)
+	if report.Format == reports.FormatCSV {
+		withQueries := make([]reportPanel, 0, len(panels))
+		for _, p := range panels {
+			if p.HasQueries {
+				withQueries = append(withQueries, p)
+			}
+		}
+		panels = withQueries
+	}
+	if len(panels) == 0 {
+		return nil, fmt.Errorf("dashboard %s has no panels to render", dashboard.UID)
+	}
+
+	from, to := timeRange(report, dashboard.Data)
+	opts := rendering.CommonOpts{
+		TimeoutOpts: rendering.TimeoutOpts{Timeout: s.cfg.Reporting.RenderTimeout},
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   report.OrgID,
+			UserID:  renderUser.UserID,
+			OrgRole: renderUser.OrgRole,
+		},
+		Timezone:        report.Timezone,
+		ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+	}
+
+	files := make([]*notifications.SendEmailAttachFile, 0, len(panels))
+	pages := make([]pdfPage, 0, len(panels))
+	for _, p := range panels {
+		opts.Path = panelPath(report, dashboard, p.ID, from, to)
+		name := fmt.Sprintf("%s-%s", dashboard.Slug, panelFileName(p))
+
+		if report.Format == reports.FormatCSV {
+			result, err := s.renderService.RenderCSV(ctx, rendering.CSVOpts{CommonOpts: opts}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render the data of panel %d: %w", p.ID, err)
+			}
+			content, err := readRenderedFile(result.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, &notifications.SendEmailAttachFile{Name: name + ".csv", Content: content})
+			continue
+		}
+
+		result, err := s.renderService.Render(ctx, rendering.RenderPNG, rendering.Opts{
+			CommonOpts: opts,
+			ErrorOpts: rendering.ErrorOpts{
+				ErrorConcurrentLimitReached: true,
+				ErrorRenderUnavailable:      true,
+			},
+			Width:             s.cfg.RendererDefaultImageWidth,
+			Height:            s.cfg.RendererDefaultImageHeight,
+			DeviceScaleFactor: s.cfg.RendererDefaultImageScale,
+			Theme:             models.ThemeLight,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render panel %d: %w", p.ID, err)
+		}
+		content, err := readRenderedFile(result.FilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if report.Format == reports.FormatPDF {
+			pages = append(pages, pdfPage{Title: p.Title, Image: content})
+			continue
+		}
+		files = append(files, &notifications.SendEmailAttachFile{Name: name + ".png", Content: content})
+	}
+
+	if report.Format == reports.FormatPDF {
+		description := fmt.Sprintf("From %s to %s, %s", from, to, report.Timezone)
+		content, err := buildPDF(dashboard.Title, description, pages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build the PDF: %w", err)
+		}
+		files = append(files, &notifications.SendEmailAttachFile{Name: dashboard.Slug + ".pdf", Content: content})
+	}
+
+	return files, nil
+}
+
+// panelFileName includes the id of the panel, as panels may share their title.
+func panelFileName(p reportPanel) string {
+	if p.Title == "" {
+		return fmt.Sprintf("panel-%d", p.ID)
+	}
+	return fmt.Sprintf("%s-%d", slugify.Slugify(p.Title), p.ID)
+}
+
+// readRenderedFile returns the content of a file created by the rendering service, and removes it.
+func readRenderedFile(filePath string) ([]byte, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered file: %w", err)
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove rendered file: %w", err)
+	}
+	return content, nil
+}
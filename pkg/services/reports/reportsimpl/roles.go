@@ -0,0 +1,44 @@
+package reportsimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/reports"
+)
+
+var (
+	readerRole = accesscontrol.RoleDTO{
+		Name:        "fixed:reports:reader",
+		DisplayName: "Report reader",
+		Description: "Read the reports of the organization and their delivery history.",
+		Group:       "Reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: reports.ActionRead},
+		},
+	}
+
+	writerRole = accesscontrol.RoleDTO{
+		Name:        "fixed:reports:writer",
+		DisplayName: "Report writer",
+		Description: "Create, update, delete and send the reports of the organization.",
+		Group:       "Reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: reports.ActionRead},
+			{Action: reports.ActionWrite},
+			{Action: reports.ActionSend},
+		},
+	}
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	return service.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{
+			Role:   readerRole,
+			Grants: []string{string(org.RoleAdmin)},
+		},
+		accesscontrol.RoleRegistration{
+			Role:   writerRole,
+			Grants: []string{string(org.RoleAdmin)},
+		},
+	)
+}
@@ -0,0 +1,360 @@
+package reportsimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	tmplReport = "dashboard_report"
+
+	// dueBatchSize is the number of reports due to be sent read at once
+	dueBatchSize = 20
+	// defaultDeliveriesLimit is the number of deliveries returned when the query sets no limit
+	defaultDeliveriesLimit = 50
+)
+
+var _ reports.Service = (*Service)(nil)
+
+// Service sends the reports on their schedule, and manages them.
+type Service struct {
+	cfg                 *setting.Cfg
+	log                 log.Logger
+	store               store
+	dashboardService    dashboards.DashboardService
+	userService         user.Service
+	renderService       rendering.Service
+	notificationService notifications.Service
+	accessControl       accesscontrol.AccessControl
+	now                 func() time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	sqlStore db.DB,
+	dashboardService dashboards.DashboardService,
+	userService user.Service,
+	renderService rendering.Service,
+	notificationService notifications.Service,
+	routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl,
+	accessControlService accesscontrol.Service,
+) (*Service, error) {
+	s := &Service{
+		cfg:                 cfg,
+		log:                 log.New("reports"),
+		store:               &dbStore{db: sqlStore},
+		dashboardService:    dashboardService,
+		userService:         userService,
+		renderService:       renderService,
+		notificationService: notificationService,
+		accessControl:       accessControl,
+		now:                 time.Now,
+	}
+
+	if !cfg.Reporting.Enabled {
+		return s, nil
+	}
+
+	if err := declareFixedRoles(accessControlService); err != nil {
+		return nil, err
+	}
+	newAPI(s, accessControl, routeRegister).registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Reporting.Enabled
+}
+
+// Run sends the reports due to be sent. A report whose runs were missed while Grafana was stopped is sent
+// once when it starts again.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Reporting.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.sendDue(ctx)
+			s.deleteOldDeliveries(ctx)
+		}
+	}
+}
+
+// sendDue sends the reports due to be sent, until there are none left. Each run of a report is claimed
+// before it is sent, so that only one instance sends it.
+func (s *Service) sendDue(ctx context.Context) {
+	for {
+		now := s.now()
+		due, err := s.store.GetDue(ctx, now, dueBatchSize)
+		if err != nil {
+			s.log.Error("Failed to get the reports due to be sent", "error", err)
+			return
+		}
+
+		claimed := 0
+		for _, report := range due {
+			if ctx.Err() != nil {
+				return
+			}
+
+			next, err := reports.NextRun(report.Schedule, report.Timezone, now)
+			if err != nil {
+				s.log.Error("Failed to schedule report", "uid", report.UID, "org", report.OrgID, "error", err)
+				continue
+			}
+			ok, err := s.store.Claim(ctx, report, next.Unix())
+			if err != nil {
+				s.log.Error("Failed to claim report", "uid", report.UID, "org", report.OrgID, "error", err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			claimed++
+
+			if _, err := s.deliver(ctx, report, reports.TriggerSchedule); err != nil {
+				s.log.Error("Failed to send report", "uid", report.UID, "org", report.OrgID, "error", err)
+				continue
+			}
+			s.log.Info("Report sent", "uid", report.UID, "org", report.OrgID, "nextRun", next)
+		}
+
+		// stop when another instance is sending the same reports or they could not be claimed
+		if len(due) < dueBatchSize || claimed == 0 {
+			return
+		}
+	}
+}
+
+func (s *Service) deleteOldDeliveries(ctx context.Context) {
+	if s.cfg.Reporting.DeliveryRetention <= 0 {
+		return
+	}
+	deleted, err := s.store.DeleteDeliveriesBefore(ctx, s.now().Add(-s.cfg.Reporting.DeliveryRetention))
+	if err != nil {
+		s.log.Error("Failed to delete old report deliveries", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted old report deliveries", "count", deleted)
+	}
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (*reports.Report, error) {
+	return s.store.Get(ctx, orgID, uid)
+}
+
+func (s *Service) List(ctx context.Context, orgID int64) ([]*reports.Report, error) {
+	return s.store.List(ctx, orgID)
+}
+
+func (s *Service) Create(ctx context.Context, cmd *reports.CreateReportCommand) (*reports.Report, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkDashboard(ctx, cmd.OrgID, cmd.DashboardUID, cmd.User); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	report := &reports.Report{
+		UID:     util.GenerateShortUID(),
+		OrgID:   cmd.OrgID,
+		Created: now,
+	}
+	if err := s.apply(report, &cmd.ReportSettings, cmd.User, now); err != nil {
+		return nil, err
+	}
+	if err := s.store.Insert(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *reports.UpdateReportCommand) (*reports.Report, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	report, err := s.store.Get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDashboard(ctx, cmd.OrgID, cmd.DashboardUID, cmd.User); err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(report, &cmd.ReportSettings, cmd.User, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.store.Update(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// apply sets the settings on the report, which is then rendered as the user saving it and next sent on
+// its schedule from now on.
+func (s *Service) apply(report *reports.Report, settings *reports.ReportSettings, u *user.SignedInUser, now time.Time) error {
+	next, err := reports.NextRun(settings.Schedule, settings.Timezone, now)
+	if err != nil {
+		return err
+	}
+
+	report.UserID = u.UserID
+	report.Name = settings.Name
+	report.DashboardUID = settings.DashboardUID
+	report.Variables = settings.Variables
+	if report.Variables == nil {
+		report.Variables = map[string][]string{}
+	}
+	report.TimeFrom = settings.TimeFrom
+	report.TimeTo = settings.TimeTo
+	report.Schedule = settings.Schedule
+	report.Timezone = settings.Timezone
+	report.Recipients = settings.Recipients
+	report.Format = settings.Format
+	report.Message = settings.Message
+	report.Enabled = settings.Enabled
+	report.NextRun = next.Unix()
+	report.Updated = now
+	return nil
+}
+
+// checkDashboard checks that the dashboard exists and that the user saving the report can read it, as the
+// report is rendered with the permissions of the user.
+func (s *Service) checkDashboard(ctx context.Context, orgID int64, dashboardUID string, u *user.SignedInUser) error {
+	_, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return reports.ErrInvalidReport.Build(errutil.TemplateData{Public: map[string]any{"Reason": fmt.Sprintf("dashboard %s not found", dashboardUID)}})
+		}
+		return err
+	}
+
+	canRead, err := s.accessControl.Evaluate(ctx, u, accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID)))
+	if err != nil {
+		return err
+	}
+	if !canRead {
+		return reports.ErrDashboardAccess.Errorf("user %d cannot read dashboard %s", u.UserID, dashboardUID)
+	}
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.Delete(ctx, orgID, uid)
+}
+
+func (s *Service) Send(ctx context.Context, orgID int64, uid string) (*reports.Delivery, error) {
+	if !s.cfg.Reporting.Enabled {
+		return nil, reports.ErrReportingDisabled.Errorf("reporting is disabled")
+	}
+	report, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(ctx, report, reports.TriggerManual)
+}
+
+func (s *Service) GetDeliveries(ctx context.Context, query *reports.GetDeliveriesQuery) ([]*reports.Delivery, error) {
+	report, err := s.store.Get(ctx, query.OrgID, query.ReportUID)
+	if err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultDeliveriesLimit
+	}
+	return s.store.GetDeliveries(ctx, query.OrgID, report.ID, limit)
+}
+
+// deliver renders the report and queues its emails, and records the delivery. The delivery is returned
+// along with the error when it failed.
+func (s *Service) deliver(ctx context.Context, report *reports.Report, trigger string) (*reports.Delivery, error) {
+	delivery := &reports.Delivery{
+		OrgID:      report.OrgID,
+		ReportID:   report.ID,
+		Trigger:    trigger,
+		Status:     reports.DeliveryStatusQueued,
+		Recipients: report.Recipients,
+		Started:    s.now(),
+	}
+
+	attachments, sendErr := s.send(ctx, report)
+	delivery.Attachments = attachments
+	delivery.Finished = s.now()
+	if sendErr != nil {
+		delivery.Status = reports.DeliveryStatusFailed
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := s.store.InsertDelivery(ctx, delivery); err != nil {
+		s.log.Error("Failed to record report delivery", "uid", report.UID, "org", report.OrgID, "error", err)
+	}
+	return delivery, sendErr
+}
+
+// send renders the report as the user who last saved it and queues an email to each recipient, it returns
+// the number of files attached to the emails.
+func (s *Service) send(ctx context.Context, report *reports.Report) (int, error) {
+	dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: report.DashboardUID, OrgID: report.OrgID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get dashboard %s: %w", report.DashboardUID, err)
+	}
+	renderUser, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: report.UserID, OrgID: report.OrgID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the user the report is rendered as: %w", err)
+	}
+
+	files, err := s.render(ctx, report, dashboard, renderUser)
+	if err != nil {
+		return 0, err
+	}
+
+	from, to := timeRange(report, dashboard.Data)
+	err = s.notificationService.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       report.Recipients,
+		Template: tmplReport,
+		Data: map[string]any{
+			"ReportName":     report.Name,
+			"DashboardTitle": dashboard.Title,
+			"DashboardURL":   s.dashboardURL(report, dashboard, from, to),
+			"TimeFrom":       from,
+			"TimeTo":         to,
+			"Message":        report.Message,
+		},
+		AttachedFiles: files,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue the emails: %w", err)
+	}
+	return len(files), nil
+}
+
+// dashboardURL returns the link to the dashboard with the variables and the time range of the report.
+func (s *Service) dashboardURL(report *reports.Report, dashboard *dashboards.Dashboard, from, to string) string {
+	q := dashboardQuery(report, dashboard, from, to)
+	return strings.TrimSuffix(s.cfg.AppURL, "/") + "/" + path.Join("d", dashboard.UID, dashboard.Slug) + "?" + q.Encode()
+}
@@ -0,0 +1,258 @@
+package reportsimpl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationReports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.Reporting = setting.ReportingSettings{
+		Enabled:           true,
+		RenderTimeout:     time.Minute,
+		PollInterval:      time.Minute,
+		DeliveryRetention: 24 * time.Hour,
+	}
+
+	dashboard := &dashboards.Dashboard{
+		UID:   "ops",
+		OrgID: 1,
+		Title: "Ops overview",
+		Slug:  "ops-overview",
+		Data: simplejson.MustJson([]byte(`{
+			"title": "Ops overview",
+			"time": {"from": "now-24h", "to": "now"},
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "Latency", "targets": [{"refId": "A"}]},
+				{"id": 2, "type": "row", "title": "Details", "collapsed": true, "panels": [
+					{"id": 3, "type": "text", "title": "Notes"}
+				]}
+			]
+		}`)),
+	}
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+		return q.UID == "ops"
+	})).Return(dashboard, nil)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound)
+
+	renderer := &fakeRenderer{dir: t.TempDir()}
+	notificationService := &notifications.NotificationServiceMock{}
+	editor := &user.SignedInUser{UserID: 7, OrgID: 1, OrgRole: org.RoleEditor}
+
+	// a Sunday
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	s := &Service{
+		cfg:                 cfg,
+		log:                 log.NewNopLogger(),
+		store:               &dbStore{db: db.InitTestDB(t)},
+		dashboardService:    dashboardService,
+		userService:         &usertest.FakeUserService{ExpectedSignedInUser: editor},
+		renderService:       renderer,
+		notificationService: notificationService,
+		accessControl:       actest.FakeAccessControl{ExpectedEvaluate: true},
+		now:                 func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	settings := reports.ReportSettings{
+		Name:         "Monday morning",
+		DashboardUID: "ops",
+		Variables:    map[string][]string{"env": {"prod"}},
+		TimeFrom:     "now-7d",
+		TimeTo:       "now",
+		Schedule:     "0 8 * * 1",
+		Timezone:     "Europe/Paris",
+		Recipients:   []string{"management@example.com"},
+		Enabled:      true,
+	}
+
+	var report *reports.Report
+	t.Run("reports are scheduled in their time zone", func(t *testing.T) {
+		var err error
+		report, err = s.Create(ctx, &reports.CreateReportCommand{ReportSettings: settings, OrgID: 1, User: editor})
+		require.NoError(t, err)
+		assert.Equal(t, reports.FormatPDF, report.Format)
+		assert.Equal(t, int64(7), report.UserID)
+		assert.Equal(t, time.Date(2026, time.October, 19, 6, 0, 0, 0, time.UTC).Unix(), report.NextRun)
+	})
+
+	t.Run("reports require access to their dashboard", func(t *testing.T) {
+		s.accessControl = actest.FakeAccessControl{ExpectedEvaluate: false}
+		defer func() { s.accessControl = actest.FakeAccessControl{ExpectedEvaluate: true} }()
+
+		_, err := s.Create(ctx, &reports.CreateReportCommand{ReportSettings: settings, OrgID: 1, User: editor})
+		assert.ErrorIs(t, err, reports.ErrDashboardAccess)
+
+		missing := settings
+		missing.DashboardUID = "missing"
+		_, err = s.Create(ctx, &reports.CreateReportCommand{ReportSettings: missing, OrgID: 1, User: &user.SignedInUser{UserID: 7}})
+		assert.ErrorIs(t, err, reports.ErrInvalidReport)
+	})
+
+	t.Run("scheduled reports are sent once per run", func(t *testing.T) {
+		s.sendDue(ctx)
+		assert.Empty(t, notificationService.Email.To, "the report is not due yet")
+
+		now = time.Date(2026, time.October, 19, 6, 0, 30, 0, time.UTC)
+		s.sendDue(ctx)
+
+		email := notificationService.Email
+		assert.Equal(t, []string{"management@example.com"}, email.To)
+		assert.Equal(t, tmplReport, email.Template)
+		assert.Equal(t, "http://localhost:3000/d/ops/ops-overview?from=now-7d&orgId=1&to=now&var-env=prod", email.Data["DashboardURL"])
+		require.Len(t, email.AttachedFiles, 1)
+		assert.Equal(t, "ops-overview.pdf", email.AttachedFiles[0].Name)
+		assert.True(t, bytes.HasPrefix(email.AttachedFiles[0].Content, []byte("%PDF")))
+
+		assert.Equal(t, []string{
+			"d-solo/ops/ops-overview?from=now-7d&orgId=1&panelId=1&to=now&var-env=prod",
+			"d-solo/ops/ops-overview?from=now-7d&orgId=1&panelId=3&to=now&var-env=prod",
+		}, renderer.paths)
+		assert.Equal(t, rendering.AuthOpts{OrgID: 1, UserID: 7, OrgRole: org.RoleEditor}, renderer.auth)
+
+		report, err := s.Get(ctx, 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, time.October, 26, 7, 0, 0, 0, time.UTC).Unix(), report.NextRun, "the next run is after the end of summer time")
+
+		notificationService.Email = notifications.SendEmailCommand{}
+		s.sendDue(ctx)
+		assert.Empty(t, notificationService.Email.To)
+
+		deliveries, err := s.GetDeliveries(ctx, &reports.GetDeliveriesQuery{OrgID: 1, ReportUID: report.UID})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, reports.TriggerSchedule, deliveries[0].Trigger)
+		assert.Equal(t, reports.DeliveryStatusQueued, deliveries[0].Status)
+		assert.Equal(t, 1, deliveries[0].Attachments)
+	})
+
+	t.Run("csv reports attach the data of the panels with queries", func(t *testing.T) {
+		update := settings
+		update.Format = reports.FormatCSV
+		update.TimeFrom, update.TimeTo = "", ""
+		updated, err := s.Update(ctx, &reports.UpdateReportCommand{ReportSettings: update, UID: report.UID, OrgID: 1, User: editor})
+		require.NoError(t, err)
+
+		renderer.paths = nil
+		delivery, err := s.Send(ctx, 1, updated.UID)
+		require.NoError(t, err)
+		assert.Equal(t, reports.TriggerManual, delivery.Trigger)
+
+		require.Len(t, notificationService.Email.AttachedFiles, 1)
+		assert.Equal(t, "ops-overview-latency-1.csv", notificationService.Email.AttachedFiles[0].Name)
+		assert.Equal(t, []string{"d-solo/ops/ops-overview?from=now-24h&orgId=1&panelId=1&to=now&var-env=prod"}, renderer.paths)
+	})
+
+	t.Run("failed deliveries are recorded", func(t *testing.T) {
+		renderer.err = rendering.ErrRenderUnavailable
+		defer func() { renderer.err = nil }()
+
+		_, err := s.Send(ctx, 1, report.UID)
+		require.ErrorIs(t, err, rendering.ErrRenderUnavailable)
+
+		deliveries, err := s.GetDeliveries(ctx, &reports.GetDeliveriesQuery{OrgID: 1, ReportUID: report.UID, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, reports.DeliveryStatusFailed, deliveries[0].Status)
+		assert.Contains(t, deliveries[0].Error, "rendering plugin not available")
+	})
+
+	t.Run("old deliveries are deleted", func(t *testing.T) {
+		now = now.Add(25 * time.Hour)
+		s.deleteOldDeliveries(ctx)
+
+		deliveries, err := s.GetDeliveries(ctx, &reports.GetDeliveriesQuery{OrgID: 1, ReportUID: report.UID})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+
+	t.Run("reports are deleted", func(t *testing.T) {
+		require.NoError(t, s.Delete(ctx, 1, report.UID))
+		_, err := s.Get(ctx, 1, report.UID)
+		assert.ErrorIs(t, err, reports.ErrReportNotFound)
+		assert.ErrorIs(t, s.Delete(ctx, 1, report.UID), reports.ErrReportNotFound)
+	})
+}
+
+type fakeRenderer struct {
+	rendering.Service
+	dir   string
+	err   error
+	paths []string
+	auth  rendering.AuthOpts
+}
+
+func (r *fakeRenderer) Render(_ context.Context, _ rendering.RenderType, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.paths = append(r.paths, opts.Path)
+	r.auth = opts.AuthOpts
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 20, 10))); err != nil {
+		return nil, err
+	}
+	filePath, err := r.write(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: filePath}, nil
+}
+
+func (r *fakeRenderer) RenderCSV(_ context.Context, opts rendering.CSVOpts, _ rendering.Session) (*rendering.RenderCSVResult, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.paths = append(r.paths, opts.Path)
+
+	filePath, err := r.write([]byte("time,value\n"))
+	if err != nil {
+		return nil, err
+	}
+	return &rendering.RenderCSVResult{FilePath: filePath}, nil
+}
+
+func (r *fakeRenderer) write(content []byte) (string, error) {
+	filePath := filepath.Join(r.dir, fmt.Sprintf("%d", len(r.paths)))
+	if err := os.WriteFile(filePath, content, 0600); err != nil {
+		return "", errors.Join(errors.New("failed to write rendered file"), err)
+	}
+	return filePath, nil
+}
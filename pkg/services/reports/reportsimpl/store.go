@@ -0,0 +1,152 @@
+package reportsimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/reports"
+)
+
+type store interface {
+	Get(ctx context.Context, orgID int64, uid string) (*reports.Report, error)
+	List(ctx context.Context, orgID int64) ([]*reports.Report, error)
+	Insert(ctx context.Context, report *reports.Report) error
+	Update(ctx context.Context, report *reports.Report) error
+	// Delete removes the report and its deliveries.
+	Delete(ctx context.Context, orgID int64, uid string) error
+	// GetDue returns the enabled reports whose next run is at or before the time.
+	GetDue(ctx context.Context, now time.Time, limit int) ([]*reports.Report, error)
+	// Claim moves the next run of the report from the one it was read with to the next one, it returns false
+	// if another instance claimed the run in the meantime.
+	Claim(ctx context.Context, report *reports.Report, nextRun int64) (bool, error)
+
+	InsertDelivery(ctx context.Context, delivery *reports.Delivery) error
+	GetDeliveries(ctx context.Context, orgID, reportID int64, limit int) ([]*reports.Delivery, error)
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// dbStore keeps the reports and their delivery history.
+type dbStore struct {
+	db db.DB
+}
+
+var _ store = (*dbStore)(nil)
+
+func (s *dbStore) Get(ctx context.Context, orgID int64, uid string) (*reports.Report, error) {
+	var report reports.Report
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&report)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, reports.ErrReportNotFound.Errorf("report %s not found", uid)
+	}
+	return &report, nil
+}
+
+func (s *dbStore) List(ctx context.Context, orgID int64) ([]*reports.Report, error) {
+	result := make([]*reports.Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&result)
+	})
+	return result, err
+}
+
+func (s *dbStore) Insert(ctx context.Context, report *reports.Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(report)
+		return err
+	})
+}
+
+func (s *dbStore) Update(ctx context.Context, report *reports.Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(report.ID).AllCols().Omit("uid", "org_id", "created").Update(report)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return reports.ErrReportNotFound.Errorf("report %s not found", report.UID)
+		}
+		return nil
+	})
+}
+
+func (s *dbStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			var report reports.Report
+			has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&report)
+			if err != nil {
+				return err
+			}
+			if !has {
+				return reports.ErrReportNotFound.Errorf("report %s not found", uid)
+			}
+			if _, err := sess.Exec("DELETE FROM dashboard_report_delivery WHERE report_id = ?", report.ID); err != nil {
+				return err
+			}
+			_, err = sess.Exec("DELETE FROM dashboard_report WHERE id = ?", report.ID)
+			return err
+		})
+	})
+}
+
+func (s *dbStore) GetDue(ctx context.Context, now time.Time, limit int) ([]*reports.Report, error) {
+	result := make([]*reports.Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ? AND next_run <= ?", true, now.Unix()).Asc("next_run").Limit(limit).Find(&result)
+	})
+	return result, err
+}
+
+func (s *dbStore) Claim(ctx context.Context, report *reports.Report, nextRun int64) (bool, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE dashboard_report SET next_run = ? WHERE id = ? AND next_run = ?", nextRun, report.ID, report.NextRun)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	report.NextRun = nextRun
+	return true, nil
+}
+
+func (s *dbStore) InsertDelivery(ctx context.Context, delivery *reports.Delivery) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(delivery)
+		return err
+	})
+}
+
+func (s *dbStore) GetDeliveries(ctx context.Context, orgID, reportID int64, limit int) ([]*reports.Delivery, error) {
+	result := make([]*reports.Delivery, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND report_id = ?", orgID, reportID).Desc("started").Desc("id").Limit(limit).Find(&result)
+	})
+	return result, err
+}
+
+func (s *dbStore) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM dashboard_report_delivery WHERE started < ?", before)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,93 @@
+package reports
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const maxNameLength = 190
+
+// Validate checks the settings and normalizes them: the format defaults to PDF, the time zone to UTC,
+// and the blank and duplicated recipients are removed.
+func (s *ReportSettings) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return errInvalidReport("name is required")
+	}
+	if len(s.Name) > maxNameLength {
+		return errInvalidReport(fmt.Sprintf("name is longer than %d characters", maxNameLength))
+	}
+
+	if s.DashboardUID == "" {
+		return errInvalidReport("dashboardUid is required")
+	}
+
+	switch s.Format {
+	case "":
+		s.Format = FormatPDF
+	case FormatPDF, FormatPNG, FormatCSV:
+	default:
+		return errInvalidReport(fmt.Sprintf("unknown format %q, must be pdf, png or csv", s.Format))
+	}
+
+	if (s.TimeFrom == "") != (s.TimeTo == "") {
+		return errInvalidReport("timeFrom and timeTo must be set together")
+	}
+
+	for name := range s.Variables {
+		if strings.TrimSpace(name) == "" {
+			return errInvalidReport("variable names must not be empty")
+		}
+	}
+
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if _, err := NextRun(s.Schedule, s.Timezone, time.Now()); err != nil {
+		return errInvalidReport(err.Error())
+	}
+
+	recipients := make([]string, 0, len(s.Recipients))
+	for _, r := range s.Recipients {
+		r = strings.TrimSpace(r)
+		if r == "" || slices.Contains(recipients, r) {
+			continue
+		}
+		if _, err := mail.ParseAddress(r); err != nil {
+			return errInvalidReport(fmt.Sprintf("invalid recipient %q", r))
+		}
+		recipients = append(recipients, r)
+	}
+	if len(recipients) == 0 {
+		return errInvalidReport("at least one recipient is required")
+	}
+	s.Recipients = recipients
+
+	return nil
+}
+
+// NextRun returns the first time after the given time matching the schedule, a standard cron expression
+// evaluated in the time zone.
+func NextRun(schedule, timezone string, after time.Time) (time.Time, error) {
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		return time.Time{}, fmt.Errorf("schedule must not set a time zone, use timezone instead")
+	}
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown timezone %q", timezone)
+	}
+	next := sched.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("schedule %q never runs", schedule)
+	}
+	return next, nil
+}
@@ -0,0 +1,61 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSettings_Validate(t *testing.T) {
+	valid := func() ReportSettings {
+		return ReportSettings{
+			Name:         " Weekly ",
+			DashboardUID: "ops",
+			Schedule:     "0 8 * * 1",
+			Recipients:   []string{"ops@example.com", " ", "ops@example.com", "Boss <boss@example.com>"},
+		}
+	}
+
+	t.Run("normalizes the settings", func(t *testing.T) {
+		s := valid()
+		require.NoError(t, s.Validate())
+		assert.Equal(t, "Weekly", s.Name)
+		assert.Equal(t, FormatPDF, s.Format)
+		assert.Equal(t, "UTC", s.Timezone)
+		assert.Equal(t, []string{"ops@example.com", "Boss <boss@example.com>"}, s.Recipients)
+	})
+
+	for name, change := range map[string]func(s *ReportSettings){
+		"missing name":          func(s *ReportSettings) { s.Name = "" },
+		"missing dashboard":     func(s *ReportSettings) { s.DashboardUID = "" },
+		"unknown format":        func(s *ReportSettings) { s.Format = "xlsx" },
+		"partial time range":    func(s *ReportSettings) { s.TimeFrom = "now-7d" },
+		"invalid schedule":      func(s *ReportSettings) { s.Schedule = "every monday" },
+		"time zone in schedule": func(s *ReportSettings) { s.Schedule = "CRON_TZ=Europe/Paris 0 8 * * 1" },
+		"unknown time zone":     func(s *ReportSettings) { s.Timezone = "Mars/Olympus" },
+		"invalid recipient":     func(s *ReportSettings) { s.Recipients = []string{"ops"} },
+		"no recipients":         func(s *ReportSettings) { s.Recipients = []string{" "} },
+		"empty variable name":   func(s *ReportSettings) { s.Variables = map[string][]string{"": {"a"}} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := valid()
+			change(&s)
+			assert.ErrorIs(t, s.Validate(), ErrInvalidReport)
+		})
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	// a Sunday
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	next, err := NextRun("0 8 * * 1", "Europe/Paris", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.October, 19, 6, 0, 0, 0, time.UTC), next.UTC())
+
+	next, err = NextRun("@daily", "UTC", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), next.UTC())
+}
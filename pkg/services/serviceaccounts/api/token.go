@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/web"
@@ -48,6 +50,39 @@ func hasExpired(expiration *int64) bool {
 
 const sevenDaysAhead = 7 * 24 * time.Hour
 
+// recordTokens records the tokens of the service account, by name, as the before or
+// after state of an audited request. The token keys are never recorded.
+func (api *ServiceAccountsAPI) recordTokens(ctx context.Context, orgID, saID int64, record func(context.Context, any)) {
+	if !auditlog.Recording(ctx) {
+		return
+	}
+	tokens, err := api.service.ListTokens(ctx, &serviceaccounts.GetSATokensQuery{
+		OrgID:            &orgID,
+		ServiceAccountID: &saID,
+	})
+	if err != nil {
+		return
+	}
+
+	state := make(map[string]TokenDTO, len(tokens))
+	for _, t := range tokens {
+		var expiration *time.Time
+		if t.Expires != nil {
+			v := time.Unix(*t.Expires, 0)
+			expiration = &v
+		}
+		state[t.Name] = TokenDTO{
+			Id:         t.ID,
+			Name:       t.Name,
+			Created:    &t.Created,
+			Expiration: expiration,
+			HasExpired: hasExpired(t.Expires),
+			IsRevoked:  t.IsRevoked,
+		}
+	}
+	record(ctx, state)
+}
+
 // swagger:route GET /serviceaccounts/{serviceAccountId}/tokens service_accounts listTokens
 //
 // # Get service account tokens
@@ -147,21 +182,8 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	// Force affected service account to be the one referenced in the URL
 	cmd.OrgId = c.SignedInUser.GetOrgID()
 
-	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
-		if cmd.SecondsToLive == 0 {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
-		}
-		if cmd.SecondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
-		}
-	}
-
-	if api.cfg.SATokenExpirationDayLimit > 0 {
-		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
-		expirationDate := time.Now().Add(time.Duration(cmd.SecondsToLive) * time.Second).Truncate(24 * time.Hour)
-		if expirationDate.After(dayExpireLimit) {
-			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
-		}
+	if resp := api.validateTokenExpiration(cmd.SecondsToLive); resp != nil {
+		return resp
 	}
 
 	newKeyInfo, err := satokengen.New(ServiceID)
@@ -171,10 +193,12 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 
 	cmd.Key = newKeyInfo.HashedKey
 
+	api.recordTokens(c.Req.Context(), cmd.OrgId, saID, auditlog.RecordBefore)
 	apiKey, err := api.service.AddServiceAccountToken(c.Req.Context(), saID, &cmd)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to add service account token", err)
 	}
+	api.recordTokens(c.Req.Context(), cmd.OrgId, saID, auditlog.RecordAfter)
 
 	result := &dtos.NewApiKeyResult{
 		ID:   apiKey.ID,
@@ -220,13 +244,162 @@ func (api *ServiceAccountsAPI) DeleteToken(c *contextmodel.ReqContext) response.
 		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
 	}
 
+	api.recordTokens(c.Req.Context(), c.SignedInUser.GetOrgID(), saID, auditlog.RecordBefore)
 	if err = api.service.DeleteServiceAccountToken(c.Req.Context(), c.SignedInUser.GetOrgID(), saID, tokenID); err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, failedToDeleteMsg, err)
 	}
+	api.recordTokens(c.Req.Context(), c.SignedInUser.GetOrgID(), saID, auditlog.RecordAfter)
 
 	return response.Success("Service account token deleted")
 }
 
+// swagger:route POST /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/rotate service_accounts rotateToken
+//
+// # RotateToken issues a successor for a service account token
+//
+// The rotated token keeps working during the overlap window, so that clients can switch to the successor.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: createTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) RotateToken(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	// confirm service account exists
+	if _, err = api.service.RetrieveServiceAccount(c.Req.Context(), &serviceaccounts.GetServiceAccountQuery{
+		OrgID: c.SignedInUser.GetOrgID(),
+		ID:    saID,
+	}); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to retrieve service account", err)
+	}
+
+	cmd := serviceaccounts.RotateServiceAccountTokenCommand{}
+	if err = web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+
+	cmd.OrgId = c.SignedInUser.GetOrgID()
+
+	// the successor inherits the lifetime of the rotated token unless it is set explicitly
+	if cmd.SecondsToLive != 0 {
+		if resp := api.validateTokenExpiration(cmd.SecondsToLive); resp != nil {
+			return resp
+		}
+	}
+
+	newKeyInfo, err := satokengen.New(ServiceID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+
+	cmd.Key = newKeyInfo.HashedKey
+
+	api.recordTokens(c.Req.Context(), cmd.OrgId, saID, auditlog.RecordBefore)
+	apiKey, err := api.service.RotateServiceAccountToken(c.Req.Context(), cmd.OrgId, saID, tokenID, &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to rotate service account token", err)
+	}
+	api.recordTokens(c.Req.Context(), cmd.OrgId, saID, auditlog.RecordAfter)
+
+	result := &dtos.NewApiKeyResult{
+		ID:   apiKey.ID,
+		Name: apiKey.Name,
+		Key:  newKeyInfo.ClientSecret,
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /serviceaccounts/token-policy service_accounts getTokenPolicy
+//
+// # Get the service account token policy of the organization
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:read` scope: `serviceaccounts:*`
+//
+// Responses:
+// 200: tokenPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) GetTokenPolicy(c *contextmodel.ReqContext) response.Response {
+	policy, err := api.service.GetTokenPolicy(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get token policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /serviceaccounts/token-policy service_accounts updateTokenPolicy
+//
+// # Update the service account token policy of the organization
+//
+// The policy applies to tokens created or rotated after the update.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:*`
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) UpdateTokenPolicy(c *contextmodel.ReqContext) response.Response {
+	policy := serviceaccounts.TokenPolicy{}
+	if err := web.Bind(c.Req, &policy); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+
+	if api.cfg.ApiKeyMaxSecondsToLive != -1 && policy.MaxSecondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
+		return response.Error(http.StatusBadRequest, "Maximum token lifetime is greater than the global limit", nil)
+	}
+
+	if err := api.service.UpdateTokenPolicy(c.Req.Context(), c.SignedInUser.GetOrgID(), &policy); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update token policy", err)
+	}
+
+	return response.Success("Token policy updated")
+}
+
+// validateTokenExpiration checks the lifetime of a new token against the global limits.
+func (api *ServiceAccountsAPI) validateTokenExpiration(secondsToLive int64) response.Response {
+	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
+		if secondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
+		}
+		if secondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+
+	if api.cfg.SATokenExpirationDayLimit > 0 {
+		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
+		expirationDate := time.Now().Add(time.Duration(secondsToLive) * time.Second).Truncate(24 * time.Hour)
+		if expirationDate.After(dayExpireLimit) {
+			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
+		}
+	}
+
+	return nil
+}
+
 // swagger:parameters listTokens
 type ListTokensParams struct {
 	// in:path
@@ -249,6 +422,28 @@ type DeleteTokenParams struct {
 	ServiceAccountId int64 `json:"serviceAccountId"`
 }
 
+// swagger:parameters rotateToken
+type RotateTokenParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	Body serviceaccounts.RotateServiceAccountTokenCommand
+}
+
+// swagger:parameters updateTokenPolicy
+type UpdateTokenPolicyParams struct {
+	// in:body
+	Body serviceaccounts.TokenPolicy
+}
+
+// swagger:response tokenPolicyResponse
+type TokenPolicyResponse struct {
+	// in:body
+	Body *serviceaccounts.TokenPolicy
+}
+
 // swagger:response listTokensResponse
 type ListTokensResponse struct {
 	// in:body
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -109,6 +110,70 @@ func (s *ServiceAccountsStoreImpl) RevokeServiceAccountToken(ctx context.Context
 	})
 }
 
+func (s *ServiceAccountsStoreImpl) GetServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) (*apikey.APIKey, error) {
+	var token apikey.APIKey
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("id=? AND org_id=? AND service_account_id=?", tokenID, orgID, serviceAccountID).Get(&token)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenID, serviceAccountID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UpdateServiceAccountTokenExpiry sets the expiration of a service account token to the given unix timestamp.
+func (s *ServiceAccountsStoreImpl) UpdateServiceAccountTokenExpiry(ctx context.Context, orgID, serviceAccountID, tokenID, expires int64) error {
+	rawSQL := "UPDATE api_key SET expires = ?, updated = ? WHERE id=? and org_id=? and service_account_id=?"
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec(rawSQL, expires, time.Now(), tokenID, orgID, serviceAccountID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if affected == 0 {
+			return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenID, serviceAccountID)
+		}
+
+		return err
+	})
+}
+
+// ListExpiringTokens returns the tokens of enabled service accounts that have not been revoked
+// and expire between now and query.ExpiresBefore.
+func (s *ServiceAccountsStoreImpl) ListExpiringTokens(ctx context.Context, query *serviceaccounts.ListExpiringTokensQuery) ([]serviceaccounts.ExpiringToken, error) {
+	result := make([]serviceaccounts.ExpiringToken, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		quotedUser := s.sqlStore.GetDialect().Quote("user")
+		rawSQL := `SELECT
+			api_key.id,
+			api_key.org_id,
+			api_key.name,
+			api_key.service_account_id,
+			` + quotedUser + `.name AS service_account_name,
+			api_key.created,
+			api_key.expires
+		FROM api_key
+		INNER JOIN ` + quotedUser + ` ON ` + quotedUser + `.id = api_key.service_account_id
+		WHERE api_key.expires > ? AND api_key.expires <= ?
+			AND (api_key.is_revoked IS NULL OR api_key.is_revoked = ?)
+			AND ` + quotedUser + `.is_disabled = ?
+		ORDER BY api_key.expires ASC`
+
+		dialect := s.sqlStore.GetDialect()
+		return sess.SQL(rawSQL, time.Now().Unix(), query.ExpiresBefore.Unix(),
+			dialect.BooleanStr(false), dialect.BooleanStr(false)).Find(&result)
+	})
+	return result, err
+}
+
 // assignApiKeyToServiceAccount sets the API key service account ID
 func (s *ServiceAccountsStoreImpl) assignApiKeyToServiceAccount(ctx context.Context, apiKeyId int64, serviceAccountId int64) error {
 	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
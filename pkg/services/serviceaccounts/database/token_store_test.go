@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -176,3 +177,75 @@ func TestStore_DeleteServiceAccountToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStore_UpdateServiceAccountTokenExpiry(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	key, err := apikeygen.New(sa.OrgID, t.Name())
+	require.NoError(t, err)
+
+	newKey, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+		Name:  t.Name(),
+		OrgId: sa.OrgID,
+		Key:   key.HashedKey,
+	})
+	require.NoError(t, err)
+
+	expires := time.Now().Add(time.Hour).Unix()
+
+	// Update key from wrong service account
+	err = store.UpdateServiceAccountTokenExpiry(context.Background(), sa.OrgID, sa.ID+2, newKey.ID, expires)
+	require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+
+	err = store.UpdateServiceAccountTokenExpiry(context.Background(), sa.OrgID, sa.ID, newKey.ID, expires)
+	require.NoError(t, err)
+
+	token, err := store.GetServiceAccountToken(context.Background(), sa.OrgID, sa.ID, newKey.ID)
+	require.NoError(t, err)
+	require.NotNil(t, token.Expires)
+	require.Equal(t, expires, *token.Expires)
+
+	_, err = store.GetServiceAccountToken(context.Background(), sa.OrgID+2, sa.ID, newKey.ID)
+	require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+}
+
+func TestStore_ListExpiringTokens(t *testing.T) {
+	userToCreate := tests.TestUser{Name: "deployer", Login: "sa-deployer", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	addToken := func(name string, secondsToLive int64) int64 {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+		token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          name,
+			OrgId:         sa.OrgID,
+			Key:           key.HashedKey,
+			SecondsToLive: secondsToLive,
+		})
+		require.NoError(t, err)
+		return token.ID
+	}
+
+	expiringID := addToken("expiring", 24*60*60)
+	revokedID := addToken("revoked", 24*60*60)
+	addToken("later", 30*24*60*60)
+	addToken("never", 0)
+	expiredID := addToken("expired", 60)
+
+	require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, revokedID))
+	require.NoError(t, store.UpdateServiceAccountTokenExpiry(context.Background(), sa.OrgID, sa.ID, expiredID, time.Now().Add(-time.Minute).Unix()))
+
+	tokens, err := store.ListExpiringTokens(context.Background(), &serviceaccounts.ListExpiringTokensQuery{
+		ExpiresBefore: time.Now().Add(7 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, expiringID, tokens[0].ID)
+	require.Equal(t, "expiring", tokens[0].Name)
+	require.Equal(t, sa.ID, tokens[0].ServiceAccountID)
+	require.Equal(t, sa.OrgID, tokens[0].OrgID)
+	require.Equal(t, "deployer", tokens[0].ServiceAccountName)
+}
@@ -2,8 +2,10 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -13,35 +15,47 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/database"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/secretscan"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/tokenexpiry"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
-	metricsCollectionInterval = time.Minute * 30
-	defaultSecretScanInterval = time.Minute * 5
+	metricsCollectionInterval  = time.Minute * 30
+	defaultSecretScanInterval  = time.Minute * 5
+	defaultTokenExpiryInterval = time.Hour
+
+	tokenPolicyNamespace = "serviceaccounts.tokenpolicy"
+	tokenPolicyKey       = "policy"
 )
 
 type ServiceAccountsService struct {
 	acService   accesscontrol.Service
 	permissions accesscontrol.ServiceAccountPermissionsService
 
-	cfg               *setting.Cfg
-	db                db.DB
-	store             store
-	log               log.Logger
-	backgroundLog     log.Logger
-	secretScanService secretscan.Checker
-
-	secretScanEnabled  bool
-	secretScanInterval time.Duration
+	cfg                *setting.Cfg
+	db                 db.DB
+	store              store
+	log                log.Logger
+	backgroundLog      log.Logger
+	secretScanService  secretscan.Checker
+	kvStore            kvstore.KVStore
+	serverLock         *serverlock.ServerLockService
+	tokenExpiryService tokenexpiry.Checker
+
+	secretScanEnabled   bool
+	secretScanInterval  time.Duration
+	tokenExpiryEnabled  bool
+	tokenExpiryInterval time.Duration
 }
 
 func ProvideServiceAccountsService(
@@ -54,6 +68,8 @@ func ProvideServiceAccountsService(
 	orgService org.Service,
 	acService accesscontrol.Service,
 	permissions accesscontrol.ServiceAccountPermissionsService,
+	serverLock *serverlock.ServerLockService,
+	notificationService notifications.Service,
 ) (*ServiceAccountsService, error) {
 	serviceAccountsStore := database.ProvideServiceAccountsStore(
 		cfg,
@@ -69,6 +85,8 @@ func ProvideServiceAccountsService(
 		acService:     acService,
 		permissions:   permissions,
 		store:         serviceAccountsStore,
+		kvStore:       kvStore,
+		serverLock:    serverLock,
 		log:           log.New("serviceaccounts"),
 		backgroundLog: log.New("serviceaccounts.background"),
 	}
@@ -84,7 +102,7 @@ func ProvideServiceAccountsService(
 		Key("interval").MustDuration(defaultSecretScanInterval)
 	if s.secretScanEnabled {
 		var errSecret error
-		s.secretScanService, errSecret = secretscan.NewService(s.store, cfg)
+		s.secretScanService, errSecret = secretscan.NewService(s.store, apiKeyService, cfg)
 		if errSecret != nil {
 			s.secretScanEnabled = false
 			s.log.Warn("Failed to initialize secret scan service. secret scan is disabled",
@@ -92,6 +110,18 @@ func ProvideServiceAccountsService(
 		}
 	}
 
+	s.tokenExpiryEnabled = cfg.SATokenExpiryNotificationDays > 0
+	s.tokenExpiryInterval = cfg.SATokenExpiryCheckInterval
+	if s.tokenExpiryEnabled {
+		var errExpiry error
+		s.tokenExpiryService, errExpiry = tokenexpiry.NewService(s.store, cfg, notificationService, orgService, kvStore)
+		if errExpiry != nil {
+			s.tokenExpiryEnabled = false
+			s.log.Warn("Failed to initialize token expiry service. token expiry notifications are disabled",
+				"error", errExpiry.Error())
+		}
+	}
+
 	return s, nil
 }
 
@@ -126,6 +156,25 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 		defer tokenCheckTicker.Stop()
 	}
 
+	// Enforce a minimum interval of 1 minute.
+	if sa.tokenExpiryEnabled && sa.tokenExpiryInterval < time.Minute {
+		sa.backgroundLog.Warn("Token expiry check interval is too low, increasing to " +
+			defaultTokenExpiryInterval.String())
+
+		sa.tokenExpiryInterval = defaultTokenExpiryInterval
+	}
+
+	tokenExpiryTicker := time.NewTicker(sa.tokenExpiryInterval)
+
+	if !sa.tokenExpiryEnabled {
+		tokenExpiryTicker.Stop()
+	} else {
+		sa.backgroundLog.Debug("Enabled token expiry notifications and executing first check")
+		sa.checkExpiringTokens(ctx)
+
+		defer tokenExpiryTicker.Stop()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -148,8 +197,30 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if err := sa.secretScanService.CheckTokens(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to check for leaked tokens", "error", err.Error())
 			}
+		case <-tokenExpiryTicker.C:
+			sa.backgroundLog.Debug("Checking for expiring tokens")
+
+			sa.checkExpiringTokens(ctx)
+		}
+	}
+}
+
+// checkExpiringTokens notifies about expiring tokens, only one instance runs the check per interval.
+func (sa *ServiceAccountsService) checkExpiringTokens(ctx context.Context) {
+	check := func(ctx context.Context) {
+		if err := sa.tokenExpiryService.CheckTokens(ctx); err != nil {
+			sa.backgroundLog.Warn("Failed to check for expiring tokens", "error", err.Error())
 		}
 	}
+
+	if sa.serverLock == nil {
+		check(ctx)
+		return
+	}
+
+	if err := sa.serverLock.LockAndExecute(ctx, "check expiring service account tokens", sa.tokenExpiryInterval/2, check); err != nil {
+		sa.backgroundLog.Warn("Failed to lock and execute check for expiring tokens", "error", err.Error())
+	}
 }
 
 var _ serviceaccounts.Service = (*ServiceAccountsService)(nil)
@@ -267,9 +338,146 @@ func (sa *ServiceAccountsService) AddServiceAccountToken(ctx context.Context, se
 	if err := validServiceAccountID(serviceAccountID); err != nil {
 		return nil, err
 	}
+
+	policy, err := sa.GetTokenPolicy(ctx, query.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxSecondsToLive > 0 && (query.SecondsToLive <= 0 || query.SecondsToLive > policy.MaxSecondsToLive) {
+		return nil, serviceaccounts.ErrTokenLifetimeExceedsPolicy.Errorf("token lifetime of %d seconds exceeds the maximum of %d seconds", query.SecondsToLive, policy.MaxSecondsToLive)
+	}
+
 	return sa.store.AddServiceAccountToken(ctx, serviceAccountID, query)
 }
 
+// RotateServiceAccountToken issues a successor for a token and shortens the lifetime of the
+// rotated token to the overlap window, so that clients can switch to the successor before it stops working.
+func (sa *ServiceAccountsService) RotateServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if err := validOrgID(orgID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountID(serviceAccountID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountTokenID(tokenID); err != nil {
+		return nil, err
+	}
+
+	token, err := sa.store.GetServiceAccountToken(ctx, orgID, serviceAccountID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if token.IsRevoked != nil && *token.IsRevoked {
+		return nil, serviceaccounts.ErrServiceAccountTokenRevoked.Errorf("service account token with id %d has been revoked", tokenID)
+	}
+
+	now := time.Now()
+
+	secondsToLive := cmd.SecondsToLive
+	if secondsToLive == 0 {
+		// the successor inherits the lifetime of the rotated token, within the limits of the org
+		// policy and the global limits, which the API only checks for lifetimes set explicitly
+		if token.Expires != nil {
+			secondsToLive = *token.Expires - token.Created.Unix()
+		}
+
+		policy, err := sa.GetTokenPolicy(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		secondsToLive = clampSecondsToLive(secondsToLive, policy.MaxSecondsToLive)
+		secondsToLive = clampSecondsToLive(secondsToLive, sa.cfg.ApiKeyMaxSecondsToLive)
+		secondsToLive = clampSecondsToLive(secondsToLive, int64(sa.cfg.SATokenExpirationDayLimit)*24*60*60)
+	}
+
+	overlap := sa.cfg.SATokenRotationOverlap
+	if cmd.OverlapSeconds != nil {
+		if *cmd.OverlapSeconds < 0 {
+			return nil, serviceaccounts.ErrInvalidTokenExpiration.Errorf("invalid rotation overlap of %d seconds", *cmd.OverlapSeconds)
+		}
+		overlap = time.Duration(*cmd.OverlapSeconds) * time.Second
+	}
+
+	// never extend the lifetime of the rotated token
+	expires := now.Add(overlap).Unix()
+	if token.Expires != nil && *token.Expires < expires {
+		expires = *token.Expires
+	}
+
+	name := cmd.Name
+	if name == "" {
+		name = rotatedTokenName(token.Name, now)
+	}
+
+	var successor *apikey.APIKey
+	err = sa.db.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		successor, err = sa.AddServiceAccountToken(ctx, serviceAccountID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          name,
+			OrgId:         orgID,
+			Key:           cmd.Key,
+			SecondsToLive: secondsToLive,
+		})
+		if err != nil {
+			return err
+		}
+
+		return sa.store.UpdateServiceAccountTokenExpiry(ctx, orgID, serviceAccountID, tokenID, expires)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return successor, nil
+}
+
+var rotatedSuffix = regexp.MustCompile(`-rotated-\d+$`)
+
+// rotatedTokenName returns the name of the successor of a token, replacing the suffix of previous rotations.
+func rotatedTokenName(name string, now time.Time) string {
+	return fmt.Sprintf("%s-rotated-%d", rotatedSuffix.ReplaceAllString(name, ""), now.Unix())
+}
+
+// clampSecondsToLive limits a token lifetime to max, a lifetime of 0 never expires. A max of 0
+// or less is no limit.
+func clampSecondsToLive(secondsToLive, max int64) int64 {
+	if max > 0 && (secondsToLive <= 0 || secondsToLive > max) {
+		return max
+	}
+	return secondsToLive
+}
+
+func (sa *ServiceAccountsService) GetTokenPolicy(ctx context.Context, orgID int64) (*serviceaccounts.TokenPolicy, error) {
+	policy := &serviceaccounts.TokenPolicy{}
+
+	value, ok, err := kvstore.WithNamespace(sa.kvStore, orgID, tokenPolicyNamespace).Get(ctx, tokenPolicyKey)
+	if err != nil || !ok {
+		return policy, err
+	}
+
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, fmt.Errorf("failed to decode token policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (sa *ServiceAccountsService) UpdateTokenPolicy(ctx context.Context, orgID int64, policy *serviceaccounts.TokenPolicy) error {
+	if err := validOrgID(orgID); err != nil {
+		return err
+	}
+	if policy.MaxSecondsToLive < 0 {
+		return serviceaccounts.ErrInvalidTokenPolicy.Errorf("invalid maximum token lifetime of %d seconds", policy.MaxSecondsToLive)
+	}
+
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	return kvstore.WithNamespace(sa.kvStore, orgID, tokenPolicyNamespace).Set(ctx, tokenPolicyKey, string(value))
+}
+
 func (sa *ServiceAccountsService) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID int64, tokenID int64) error {
 	if err := validOrgID(orgID); err != nil {
 		return err
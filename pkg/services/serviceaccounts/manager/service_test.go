@@ -3,15 +3,19 @@ package manager
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
 
@@ -24,8 +28,13 @@ type FakeServiceAccountStore struct {
 	expectedMigratedResults                 *serviceaccounts.MigrationResult
 	ExpectedAPIKeys                         []apikey.APIKey
 	ExpectedAPIKey                          *apikey.APIKey
+	ExpectedToken                           *apikey.APIKey
+	ExpectedExpiringTokens                  []serviceaccounts.ExpiringToken
 	ExpectedBoolean                         bool
 	ExpectedError                           error
+
+	AddedTokens    []serviceaccounts.AddServiceAccountTokenCommand
+	UpdatedExpires map[int64]int64
 }
 
 var _ store = (*FakeServiceAccountStore)(nil)
@@ -97,9 +106,31 @@ func (f *FakeServiceAccountStore) RevokeServiceAccountToken(ctx context.Context,
 
 // AddServiceAccountToken is a fake adding a service account token.
 func (f *FakeServiceAccountStore) AddServiceAccountToken(ctx context.Context, serviceAccountID int64, cmd *serviceaccounts.AddServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if f.ExpectedError == nil {
+		f.AddedTokens = append(f.AddedTokens, *cmd)
+	}
 	return f.ExpectedAPIKey, f.ExpectedError
 }
 
+// GetServiceAccountToken is a fake getting a service account token.
+func (f *FakeServiceAccountStore) GetServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) (*apikey.APIKey, error) {
+	return f.ExpectedToken, f.ExpectedError
+}
+
+// UpdateServiceAccountTokenExpiry is a fake updating the expiration of a service account token.
+func (f *FakeServiceAccountStore) UpdateServiceAccountTokenExpiry(ctx context.Context, orgID, serviceAccountID, tokenID, expires int64) error {
+	if f.UpdatedExpires == nil {
+		f.UpdatedExpires = map[int64]int64{}
+	}
+	f.UpdatedExpires[tokenID] = expires
+	return f.ExpectedError
+}
+
+// ListExpiringTokens is a fake listing expiring tokens.
+func (f *FakeServiceAccountStore) ListExpiringTokens(ctx context.Context, query *serviceaccounts.ListExpiringTokensQuery) ([]serviceaccounts.ExpiringToken, error) {
+	return f.ExpectedExpiringTokens, f.ExpectedError
+}
+
 // DeleteServiceAccountToken is a fake deleting a service account token.
 func (f *FakeServiceAccountStore) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error {
 	return f.ExpectedError
@@ -160,3 +191,133 @@ func TestProvideServiceAccount_DeleteServiceAccount(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestProvideServiceAccount_RotateServiceAccountToken(t *testing.T) {
+	setup := func(t *testing.T, token *apikey.APIKey) (*ServiceAccountsService, *FakeServiceAccountStore) {
+		storeMock := newServiceAccountStoreFake()
+		storeMock.ExpectedToken = token
+		storeMock.ExpectedAPIKey = &apikey.APIKey{ID: 2, Name: "successor"}
+
+		cfg := setting.NewCfg()
+		cfg.SATokenRotationOverlap = time.Hour
+
+		return &ServiceAccountsService{
+			cfg:     cfg,
+			store:   storeMock,
+			db:      db.InitTestDB(t),
+			kvStore: kvstore.NewFakeKVStore(),
+			log:     log.NewNopLogger(),
+		}, storeMock
+	}
+
+	created := time.Now().Add(-24 * time.Hour)
+	expires := created.Add(30 * 24 * time.Hour).Unix()
+
+	t.Run("should issue a successor and shorten the lifetime of the rotated token", func(t *testing.T) {
+		svc, storeMock := setup(t, &apikey.APIKey{ID: 1, OrgID: 1, Name: "ci", Created: created, Expires: &expires})
+
+		successor, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{Key: "hashed"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), successor.ID)
+
+		require.Len(t, storeMock.AddedTokens, 1)
+		added := storeMock.AddedTokens[0]
+		assert.Regexp(t, `^ci-rotated-\d+$`, added.Name)
+		assert.Equal(t, "hashed", added.Key)
+		assert.Equal(t, int64(30*24*60*60), added.SecondsToLive)
+
+		assert.InDelta(t, time.Now().Add(time.Hour).Unix(), storeMock.UpdatedExpires[1], 5)
+	})
+
+	t.Run("should use the requested overlap and never extend the rotated token", func(t *testing.T) {
+		soon := time.Now().Add(10 * time.Minute).Unix()
+		svc, storeMock := setup(t, &apikey.APIKey{ID: 1, OrgID: 1, Name: "ci-rotated-1700000000", Created: created, Expires: &soon})
+
+		overlap := int64(2 * 60 * 60)
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{OverlapSeconds: &overlap})
+		require.NoError(t, err)
+
+		assert.Regexp(t, `^ci-rotated-\d+$`, storeMock.AddedTokens[0].Name)
+		assert.NotEqual(t, "ci-rotated-1700000000", storeMock.AddedTokens[0].Name)
+		assert.Equal(t, soon, storeMock.UpdatedExpires[1])
+	})
+
+	t.Run("should fail for revoked tokens", func(t *testing.T) {
+		revoked := true
+		svc, storeMock := setup(t, &apikey.APIKey{ID: 1, OrgID: 1, Name: "ci", Created: created, IsRevoked: &revoked})
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{})
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenRevoked)
+		assert.Empty(t, storeMock.AddedTokens)
+	})
+
+	t.Run("should cap the inherited lifetime to the org policy", func(t *testing.T) {
+		svc, storeMock := setup(t, &apikey.APIKey{ID: 1, OrgID: 1, Name: "ci", Created: created})
+		require.NoError(t, svc.UpdateTokenPolicy(context.Background(), 1, &serviceaccounts.TokenPolicy{MaxSecondsToLive: 3600}))
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3600), storeMock.AddedTokens[0].SecondsToLive)
+	})
+
+	t.Run("should cap the inherited lifetime to the global limits", func(t *testing.T) {
+		svc, storeMock := setup(t, &apikey.APIKey{ID: 1, OrgID: 1, Name: "ci", Created: created})
+		svc.cfg.ApiKeyMaxSecondsToLive = 7200
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7200), storeMock.AddedTokens[0].SecondsToLive)
+
+		svc, storeMock = setup(t, &apikey.APIKey{ID: 1, OrgID: 1, Name: "ci", Created: created, Expires: &expires})
+		svc.cfg.SATokenExpirationDayLimit = 7
+
+		_, err = svc.RotateServiceAccountToken(context.Background(), 1, 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7*24*60*60), storeMock.AddedTokens[0].SecondsToLive)
+	})
+}
+
+func TestProvideServiceAccount_TokenPolicy(t *testing.T) {
+	storeMock := newServiceAccountStoreFake()
+	storeMock.ExpectedAPIKey = &apikey.APIKey{ID: 1}
+	svc := &ServiceAccountsService{
+		cfg:     setting.NewCfg(),
+		store:   storeMock,
+		kvStore: kvstore.NewFakeKVStore(),
+		log:     log.NewNopLogger(),
+	}
+	ctx := context.Background()
+
+	policy, err := svc.GetTokenPolicy(ctx, 1)
+	require.NoError(t, err)
+	assert.Zero(t, policy.MaxSecondsToLive)
+
+	err = svc.UpdateTokenPolicy(ctx, 1, &serviceaccounts.TokenPolicy{MaxSecondsToLive: -1})
+	require.ErrorIs(t, err, serviceaccounts.ErrInvalidTokenPolicy)
+
+	require.NoError(t, svc.UpdateTokenPolicy(ctx, 1, &serviceaccounts.TokenPolicy{MaxSecondsToLive: 3600}))
+
+	policy, err = svc.GetTokenPolicy(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3600), policy.MaxSecondsToLive)
+
+	t.Run("should reject tokens without expiry", func(t *testing.T) {
+		_, err := svc.AddServiceAccountToken(ctx, 1, &serviceaccounts.AddServiceAccountTokenCommand{Name: "never", OrgId: 1})
+		require.ErrorIs(t, err, serviceaccounts.ErrTokenLifetimeExceedsPolicy)
+	})
+
+	t.Run("should reject tokens living longer than the policy", func(t *testing.T) {
+		_, err := svc.AddServiceAccountToken(ctx, 1, &serviceaccounts.AddServiceAccountTokenCommand{Name: "long", OrgId: 1, SecondsToLive: 7200})
+		require.ErrorIs(t, err, serviceaccounts.ErrTokenLifetimeExceedsPolicy)
+	})
+
+	t.Run("should accept tokens within the policy", func(t *testing.T) {
+		_, err := svc.AddServiceAccountToken(ctx, 1, &serviceaccounts.AddServiceAccountTokenCommand{Name: "short", OrgId: 1, SecondsToLive: 600})
+		require.NoError(t, err)
+	})
+
+	t.Run("should not apply to other organizations", func(t *testing.T) {
+		_, err := svc.AddServiceAccountToken(ctx, 1, &serviceaccounts.AddServiceAccountTokenCommand{Name: "never", OrgId: 2})
+		require.NoError(t, err)
+	})
+}
@@ -13,7 +13,9 @@ type store interface {
 	DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID int64) error
 	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
 	EnableServiceAccount(ctx context.Context, orgID, serviceAccountID int64, enable bool) error
+	GetServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) (*apikey.APIKey, error)
 	GetUsageMetrics(ctx context.Context) (*serviceaccounts.Stats, error)
+	ListExpiringTokens(ctx context.Context, query *serviceaccounts.ListExpiringTokensQuery) ([]serviceaccounts.ExpiringToken, error)
 	ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error)
 	MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error
 	MigrateApiKeysToServiceAccounts(ctx context.Context, orgID int64) (*serviceaccounts.MigrationResult, error)
@@ -23,4 +25,5 @@ type store interface {
 	SearchOrgServiceAccounts(ctx context.Context, query *serviceaccounts.SearchOrgServiceAccountsQuery) (*serviceaccounts.SearchOrgServiceAccountsResult, error)
 	UpdateServiceAccount(ctx context.Context, orgID, serviceAccountID int64,
 		saForm *serviceaccounts.UpdateServiceAccountForm) (*serviceaccounts.ServiceAccountProfileDTO, error)
+	UpdateServiceAccountTokenExpiry(ctx context.Context, orgID, serviceAccountID, tokenID, expires int64) error
 }
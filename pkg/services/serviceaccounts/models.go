@@ -42,6 +42,9 @@ var (
 	ErrServiceAccountTokenNotFound       = errutil.NotFound("serviceaccounts.ErrTokenNotFound", errutil.WithPublicMessage("service account token not found"))
 	ErrInvalidTokenExpiration            = errutil.ValidationFailed("serviceaccounts.ErrInvalidInput", errutil.WithPublicMessage("invalid SecondsToLive value"))
 	ErrDuplicateToken                    = errutil.BadRequest("serviceaccounts.ErrTokenAlreadyExists", errutil.WithPublicMessage("service account token with given name already exists in the organization"))
+	ErrServiceAccountTokenRevoked        = errutil.BadRequest("serviceaccounts.ErrTokenRevoked", errutil.WithPublicMessage("service account token has been revoked"))
+	ErrTokenLifetimeExceedsPolicy        = errutil.BadRequest("serviceaccounts.ErrTokenLifetimeExceedsPolicy", errutil.WithPublicMessage("token lifetime exceeds the maximum allowed by the organization token policy"))
+	ErrInvalidTokenPolicy                = errutil.ValidationFailed("serviceaccounts.ErrInvalidTokenPolicy", errutil.WithPublicMessage("invalid token policy"))
 )
 
 type MigrationResult struct {
@@ -113,6 +116,43 @@ type AddServiceAccountTokenCommand struct {
 	SecondsToLive int64  `json:"secondsToLive"`
 }
 
+// swagger:model
+type RotateServiceAccountTokenCommand struct {
+	// Name of the successor token, defaults to the name of the rotated token with a timestamp suffix
+	Name string `json:"name"`
+	// SecondsToLive of the successor token, defaults to the lifetime of the rotated token
+	SecondsToLive int64 `json:"secondsToLive"`
+	// OverlapSeconds is how long the rotated token keeps working after the rotation,
+	// defaults to the token_rotation_overlap setting
+	OverlapSeconds *int64 `json:"overlapSeconds"`
+	OrgId          int64  `json:"-"`
+	Key            string `json:"-"`
+}
+
+// TokenPolicy holds the restrictions applied to the service account tokens of an organization.
+// swagger:model
+type TokenPolicy struct {
+	// MaxSecondsToLive is the maximum lifetime of new tokens, 0 means tokens are not required to expire
+	// example: 2592000
+	MaxSecondsToLive int64 `json:"maxSecondsToLive"`
+}
+
+// ExpiringToken is a service account token that will expire soon.
+type ExpiringToken struct {
+	ID                 int64  `xorm:"id"`
+	OrgID              int64  `xorm:"org_id"`
+	Name               string `xorm:"name"`
+	ServiceAccountID   int64  `xorm:"service_account_id"`
+	ServiceAccountName string `xorm:"service_account_name"`
+	Created            time.Time
+	Expires            int64
+}
+
+type ListExpiringTokensQuery struct {
+	// ExpiresBefore is the upper bound of the token expiration, tokens that already expired are ignored
+	ExpiresBefore time.Time
+}
+
 type SearchOrgServiceAccountsQuery struct {
 	OrgID        int64
 	Query        string
@@ -0,0 +1,220 @@
+package secretscan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	tokenTypeServiceAccount = "grafana_service_account_token"
+	tokenTypeAPIKey         = "grafana_api_key"
+
+	maxLineSize = 1024 * 1024
+)
+
+var (
+	serviceAccountTokenPattern = regexp.MustCompile(`gl[a-z]{2}_[A-Za-z0-9]{32}_[0-9a-f]{8}`)
+	// legacy API keys are base64 encoded JSON objects starting with {"k":"
+	apiKeyPattern = regexp.MustCompile(`eyJrIjoi[A-Za-z0-9+/]+={0,2}`)
+
+	// directories that never contain leaked tokens, or only as git objects which can't be read as text
+	skippedDirs = map[string]bool{".git": true, "node_modules": true}
+)
+
+// fileState is used to skip the files that did not change since the last scan.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// localClient finds leaked tokens by scanning files on disk, like git repositories,
+// log files or CI artifacts, for Grafana token patterns. It doesn't need network access.
+type localClient struct {
+	sources     []string
+	maxFileSize int64
+	logger      log.Logger
+	now         func() time.Time
+
+	mu      sync.Mutex
+	scanned map[string]fileState
+}
+
+func newLocalClient(sources []string, maxFileSize int64) (*localClient, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("local secret scan requires at least one source")
+	}
+
+	return &localClient{
+		sources:     sources,
+		maxFileSize: maxFileSize,
+		logger:      log.New("secretscan.local"),
+		now:         time.Now,
+		scanned:     make(map[string]fileState),
+	}, nil
+}
+
+// CheckTokens scans the sources for tokens and returns the ones matching keyHashes.
+// Files are only scanned again after they changed.
+func (c *localClient) CheckTokens(ctx context.Context, keyHashes []string) ([]Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wanted := make(map[string]bool, len(keyHashes))
+	for _, hash := range keyHashes {
+		wanted[hash] = true
+	}
+
+	found := make(map[string]Token)
+	for _, source := range c.sources {
+		err := filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				c.logger.Warn("Failed to read secret scan source", "path", path, "error", err)
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+
+			if d.IsDir() {
+				if path != source && skippedDirs[d.Name()] {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if c.maxFileSize > 0 && info.Size() > c.maxFileSize {
+				return nil
+			}
+
+			state := fileState{modTime: info.ModTime(), size: info.Size()}
+			if previous, ok := c.scanned[path]; ok && previous == state {
+				return nil
+			}
+
+			// files that can't be read are not retried until they change
+			c.scanned[path] = state
+			if err := c.scanFile(path, wanted, found); err != nil {
+				c.logger.Warn("Failed to scan file for leaked tokens", "path", path, "error", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", source, err)
+		}
+	}
+
+	tokens := make([]Token, 0, len(found))
+	for _, token := range found {
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func (c *localClient) scanFile(path string, wanted map[string]bool, found map[string]Token) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	reader := bufio.NewReader(f)
+
+	// skip binary files
+	head, err := reader.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return err
+	}
+	if bytes.IndexByte(head, 0) != -1 {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+
+		for _, match := range serviceAccountTokenPattern.FindAllString(line, -1) {
+			c.report(tokenTypeServiceAccount, serviceAccountTokenHash(match), path, lineNumber, wanted, found)
+		}
+		for _, match := range apiKeyPattern.FindAllString(line, -1) {
+			c.report(tokenTypeAPIKey, apiKeyHash(match), path, lineNumber, wanted, found)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (c *localClient) report(tokenType, hash, path string, line int, wanted map[string]bool, found map[string]Token) {
+	if hash == "" || !wanted[hash] {
+		return
+	}
+	if _, ok := found[hash]; ok {
+		return
+	}
+
+	found[hash] = Token{
+		Type:       tokenType,
+		URL:        fmt.Sprintf("file://%s#L%d", path, line),
+		Hash:       hash,
+		ReportedAt: c.now().UTC().Format(time.RFC3339),
+	}
+}
+
+// serviceAccountTokenHash returns the hash stored for a service account token, or an
+// empty string if the token is invalid.
+func serviceAccountTokenHash(token string) string {
+	key, err := satokengen.Decode(token)
+	if err != nil {
+		return ""
+	}
+
+	hash, err := key.Hash()
+	if err != nil {
+		return ""
+	}
+
+	return hash
+}
+
+// apiKeyHash returns the hash stored for a legacy API key, or an empty string if the key is invalid.
+func apiKeyHash(token string) string {
+	key, err := apikeygen.Decode(token)
+	if err != nil || key.Key == "" {
+		return ""
+	}
+
+	hash, err := util.EncodePassword(key.Key, key.Name)
+	if err != nil {
+		return ""
+	}
+
+	return hash
+}
@@ -0,0 +1,98 @@
+package secretscan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/components/satokengen"
+)
+
+func TestLocalClient_CheckTokens(t *testing.T) {
+	ctx := context.Background()
+
+	leaked, err := satokengen.New("sa")
+	require.NoError(t, err)
+	other, err := satokengen.New("sa")
+	require.NoError(t, err)
+	legacy, err := apikeygen.New(1, "ci")
+	require.NoError(t, err)
+	inGit, err := satokengen.New("sa")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	buildLog := writeFile("builds/42/build.log", strings.Join([]string{
+		"step 1: checkout",
+		"export GRAFANA_TOKEN=" + leaked.ClientSecret,
+		"curl -H 'Authorization: Bearer " + legacy.ClientSecret + "' https://grafana.example.org/api/health",
+	}, "\n"))
+	writeFile("builds/42/artifact.bin", "\x00\x01"+other.ClientSecret)
+	writeFile("repo/.git/objects/ab/cdef", inGit.ClientSecret)
+	writeFile("repo/README.md", "glsa_notarealtoken_00000000")
+
+	client, err := newLocalClient([]string{dir}, 1024*1024)
+	require.NoError(t, err)
+
+	wanted := []string{leaked.HashedKey, other.HashedKey, legacy.HashedKey, inGit.HashedKey}
+
+	tokens, err := client.CheckTokens(ctx, wanted)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+
+	byHash := map[string]Token{}
+	for _, token := range tokens {
+		byHash[token.Hash] = token
+	}
+
+	require.Contains(t, byHash, leaked.HashedKey)
+	assert.Equal(t, tokenTypeServiceAccount, byHash[leaked.HashedKey].Type)
+	assert.Equal(t, "file://"+buildLog+"#L2", byHash[leaked.HashedKey].URL)
+
+	require.Contains(t, byHash, legacy.HashedKey)
+	assert.Equal(t, tokenTypeAPIKey, byHash[legacy.HashedKey].Type)
+	assert.Equal(t, "file://"+buildLog+"#L3", byHash[legacy.HashedKey].URL)
+
+	t.Run("should not scan unchanged files again", func(t *testing.T) {
+		tokens, err := client.CheckTokens(ctx, wanted)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("should scan files again after they changed", func(t *testing.T) {
+		writeFile("builds/42/build.log", "token: "+leaked.ClientSecret+"\n")
+		require.NoError(t, os.Chtimes(buildLog, time.Now(), time.Now().Add(time.Minute)))
+
+		tokens, err := client.CheckTokens(ctx, wanted)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, leaked.HashedKey, tokens[0].Hash)
+	})
+
+	t.Run("should only report tokens that are checked", func(t *testing.T) {
+		client, err := newLocalClient([]string{dir}, 1024*1024)
+		require.NoError(t, err)
+
+		tokens, err := client.CheckTokens(ctx, []string{legacy.HashedKey})
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
+
+func TestLocalClient_RequiresSources(t *testing.T) {
+	_, err := newLocalClient(nil, 0)
+	require.Error(t, err)
+}
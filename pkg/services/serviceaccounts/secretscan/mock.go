@@ -32,6 +32,24 @@ func (m *MockTokenRetriever) RevokeServiceAccountToken(
 	return m.errRevoke
 }
 
+type MockAPIKeyRetriever struct {
+	keys      []*apikey.APIKey
+	errList   error
+	errDelete error
+
+	deleteCalls []*apikey.DeleteCommand
+}
+
+func (m *MockAPIKeyRetriever) GetAllAPIKeys(ctx context.Context, orgID int64) ([]*apikey.APIKey, error) {
+	return m.keys, m.errList
+}
+
+func (m *MockAPIKeyRetriever) DeleteApiKey(ctx context.Context, cmd *apikey.DeleteCommand) error {
+	m.deleteCalls = append(m.deleteCalls, cmd)
+
+	return m.errDelete
+}
+
 type MockSecretScaner struct{}
 
 func (m *MockSecretScaner) CheckTokens(ctx context.Context) error {
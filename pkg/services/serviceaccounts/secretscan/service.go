@@ -9,9 +9,17 @@ import (
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
-const defaultURL = "https://secret-scanning.grafana.net"
+const (
+	defaultURL = "https://secret-scanning.grafana.net"
+
+	// ModeRemote checks token hashes against the grafana.com secret scanning service.
+	ModeRemote = "remote"
+	// ModeLocal scans files on disk for leaked tokens, without network access.
+	ModeLocal = "local"
+)
 
 type Checker interface {
 	CheckTokens(ctx context.Context) error
@@ -30,9 +38,15 @@ type SATokenRetriever interface {
 	RevokeServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
 }
 
+type APIKeyRetriever interface {
+	GetAllAPIKeys(ctx context.Context, orgID int64) ([]*apikey.APIKey, error)
+	DeleteApiKey(ctx context.Context, cmd *apikey.DeleteCommand) error
+}
+
 // Secret Scan Service is grafana's service for checking leaked keys.
 type Service struct {
 	store         SATokenRetriever
+	apiKeys       APIKeyRetriever // only set in local mode, the remote service doesn't know legacy API keys
 	client        CheckerClient
 	webHookClient WebHookClient
 	logger        log.Logger
@@ -40,15 +54,36 @@ type Service struct {
 	revoke        bool // whether to revoke leaked tokens
 }
 
-func NewService(store SATokenRetriever, cfg *setting.Cfg) (*Service, error) {
-	secretscanBaseURL := cfg.SectionWithEnvOverrides("secretscan").Key("base_url").MustString(defaultURL)
+func NewService(store SATokenRetriever, apiKeyService APIKeyRetriever, cfg *setting.Cfg) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("secretscan")
+	mode := section.Key("mode").In(ModeRemote, []string{ModeRemote, ModeLocal})
 	// URL to send outgoing webhook when a token is leaked.
-	oncallURL := cfg.SectionWithEnvOverrides("secretscan").Key("oncall_url").MustString("")
-	revoke := cfg.SectionWithEnvOverrides("secretscan").Key("revoke").MustBool(true)
-
-	client, err := newClient(secretscanBaseURL, cfg.BuildVersion, cfg.Env == setting.Dev)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create secretscan client: %w", err)
+	oncallURL := section.Key("oncall_url").MustString("")
+	revoke := section.Key("revoke").MustBool(true)
+
+	var (
+		client  CheckerClient
+		apiKeys APIKeyRetriever
+	)
+	switch mode {
+	case ModeLocal:
+		sources := util.SplitString(section.Key("local_sources").MustString(""))
+		maxFileSize := section.Key("local_max_file_size_mb").MustInt64(10) * 1024 * 1024
+
+		var err error
+		client, err = newLocalClient(sources, maxFileSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create local secretscan client: %w", err)
+		}
+		apiKeys = apiKeyService
+	default:
+		secretscanBaseURL := section.Key("base_url").MustString(defaultURL)
+
+		var err error
+		client, err = newClient(secretscanBaseURL, cfg.BuildVersion, cfg.Env == setting.Dev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create secretscan client: %w", err)
+		}
 	}
 
 	var webHookClient WebHookClient
@@ -62,6 +97,7 @@ func NewService(store SATokenRetriever, cfg *setting.Cfg) (*Service, error) {
 
 	return &Service{
 		store:         store,
+		apiKeys:       apiKeys,
 		client:        client,
 		webHookClient: webHookClient,
 		logger:        log.New("secretscan"),
@@ -76,6 +112,19 @@ func (s *Service) RetrieveActiveTokens(ctx context.Context) ([]apikey.APIKey, er
 		return nil, fmt.Errorf("failed to retrieve service account tokens: %w", err)
 	}
 
+	if s.apiKeys == nil {
+		return saTokens, nil
+	}
+
+	apiKeys, err := s.apiKeys.GetAllAPIKeys(ctx, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve api keys: %w", err)
+	}
+
+	for _, key := range apiKeys {
+		saTokens = append(saTokens, *key)
+	}
+
 	return saTokens, nil
 }
 
@@ -119,12 +168,11 @@ func (s *Service) CheckTokens(ctx context.Context) error {
 		leakedToken := hashMap[secretscanToken.Hash]
 
 		if s.revoke {
-			if err := s.store.RevokeServiceAccountToken(
-				ctx, leakedToken.OrgID, *leakedToken.ServiceAccountId, leakedToken.ID); err != nil {
+			if err := s.revokeToken(ctx, &leakedToken); err != nil {
 				s.logger.Error("Failed to delete leaked token. Revoke manually.",
 					"error", err, "url", secretscanToken.URL, "reported_at", secretscanToken.ReportedAt,
 					"token_id", leakedToken.ID, "token", leakedToken.Name, "org", leakedToken.OrgID,
-					"serviceAccount", *leakedToken.ServiceAccountId)
+					"serviceAccount", serviceAccountID(&leakedToken))
 			}
 		}
 
@@ -137,12 +185,28 @@ func (s *Service) CheckTokens(ctx context.Context) error {
 		s.logger.Warn("Found leaked token",
 			"url", secretscanToken.URL, "reported_at", secretscanToken.ReportedAt,
 			"token_id", leakedToken.ID, "token", leakedToken.Name, "org", leakedToken.OrgID,
-			"serviceAccount", *leakedToken.ServiceAccountId, "revoked", s.revoke)
+			"serviceAccount", serviceAccountID(&leakedToken), "revoked", s.revoke)
 	}
 
 	return nil
 }
 
+// revokeToken revokes a leaked service account token, legacy API keys can't be revoked and are deleted.
+func (s *Service) revokeToken(ctx context.Context, token *apikey.APIKey) error {
+	if token.ServiceAccountId == nil {
+		return s.apiKeys.DeleteApiKey(ctx, &apikey.DeleteCommand{ID: token.ID, OrgID: token.OrgID})
+	}
+
+	return s.store.RevokeServiceAccountToken(ctx, token.OrgID, *token.ServiceAccountId, token.ID)
+}
+
+func serviceAccountID(token *apikey.APIKey) int64 {
+	if token.ServiceAccountId == nil {
+		return 0
+	}
+	return *token.ServiceAccountId
+}
+
 // filterCheckableTokens returns a list of tokens that can be checked and a map of tokens to their hashes.
 func (*Service) filterCheckableTokens(tokens []apikey.APIKey) ([]string, map[string]apikey.APIKey) {
 	hashes := make([]string, 0, len(tokens))
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestService_CheckTokens(t *testing.T) {
@@ -170,3 +171,61 @@ func TestService_CheckTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestService_CheckTokens_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	falseBool := false
+	saID := int64(3)
+
+	tokenStore := &MockTokenRetriever{keys: []apikey.APIKey{{
+		ID: 1, OrgID: 2, Name: "sa-token", Key: "test-hash-1", ServiceAccountId: &saID, IsRevoked: &falseBool,
+	}}}
+	apiKeys := &MockAPIKeyRetriever{keys: []*apikey.APIKey{{
+		ID: 2, OrgID: 2, Name: "legacy", Key: "test-hash-2",
+	}}}
+	client := &MockSecretScanClient{tokens: []Token{{Hash: "test-hash-1"}, {Hash: "test-hash-2"}}}
+
+	service := &Service{
+		store:   tokenStore,
+		apiKeys: apiKeys,
+		client:  client,
+		logger:  log.New("secretscan"),
+		revoke:  true,
+	}
+
+	require.NoError(t, service.CheckTokens(ctx))
+
+	assert.Equal(t, []string{"test-hash-1", "test-hash-2"}, client.checkCalls[0].([]string))
+	assert.Equal(t, [][]any{{int64(2), int64(3), int64(1)}}, tokenStore.revokeCalls)
+	assert.Equal(t, []*apikey.DeleteCommand{{ID: 2, OrgID: 2}}, apiKeys.deleteCalls)
+}
+
+func TestNewService_Mode(t *testing.T) {
+	t.Run("should use the local client in local mode", func(t *testing.T) {
+		cfg, err := setting.NewCfgFromBytes([]byte("[secretscan]\nmode = local\nlocal_sources = " + t.TempDir() + "\n"))
+		require.NoError(t, err)
+
+		service, err := NewService(&MockTokenRetriever{}, &MockAPIKeyRetriever{}, cfg)
+		require.NoError(t, err)
+		assert.IsType(t, &localClient{}, service.client)
+		assert.NotNil(t, service.apiKeys)
+	})
+
+	t.Run("should fail in local mode without sources", func(t *testing.T) {
+		cfg, err := setting.NewCfgFromBytes([]byte("[secretscan]\nmode = local\n"))
+		require.NoError(t, err)
+
+		_, err = NewService(&MockTokenRetriever{}, &MockAPIKeyRetriever{}, cfg)
+		require.Error(t, err)
+	})
+
+	t.Run("should use the remote client by default", func(t *testing.T) {
+		cfg, err := setting.NewCfgFromBytes([]byte("[secretscan]\n"))
+		require.NoError(t, err)
+
+		service, err := NewService(&MockTokenRetriever{}, &MockAPIKeyRetriever{}, cfg)
+		require.NoError(t, err)
+		assert.IsType(t, &client{}, service.client)
+		assert.Nil(t, service.apiKeys)
+	})
+}
@@ -40,6 +40,10 @@ type Service interface {
 		cmd *AddServiceAccountTokenCommand) (*apikey.APIKey, error)
 	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
 	ListTokens(ctx context.Context, query *GetSATokensQuery) ([]apikey.APIKey, error)
+	RotateServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64,
+		cmd *RotateServiceAccountTokenCommand) (*apikey.APIKey, error)
+	GetTokenPolicy(ctx context.Context, orgID int64) (*TokenPolicy, error)
+	UpdateTokenPolicy(ctx context.Context, orgID int64, policy *TokenPolicy) error
 
 	// API specific functions
 	MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error
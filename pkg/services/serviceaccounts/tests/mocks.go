@@ -111,6 +111,32 @@ func (_m *MockServiceAccountService) EnableServiceAccount(ctx context.Context, o
 	return r0
 }
 
+// GetTokenPolicy provides a mock function with given fields: ctx, orgID
+func (_m *MockServiceAccountService) GetTokenPolicy(ctx context.Context, orgID int64) (*serviceaccounts.TokenPolicy, error) {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 *serviceaccounts.TokenPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*serviceaccounts.TokenPolicy, error)); ok {
+		return rf(ctx, orgID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *serviceaccounts.TokenPolicy); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*serviceaccounts.TokenPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListTokens provides a mock function with given fields: ctx, query
 func (_m *MockServiceAccountService) ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error) {
 	ret := _m.Called(ctx, query)
@@ -227,6 +253,32 @@ func (_m *MockServiceAccountService) RetrieveServiceAccountIdByName(ctx context.
 	return r0, r1
 }
 
+// RotateServiceAccountToken provides a mock function with given fields: ctx, orgID, serviceAccountID, tokenID, cmd
+func (_m *MockServiceAccountService) RotateServiceAccountToken(ctx context.Context, orgID int64, serviceAccountID int64, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	ret := _m.Called(ctx, orgID, serviceAccountID, tokenID, cmd)
+
+	var r0 *apikey.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64, *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error)); ok {
+		return rf(ctx, orgID, serviceAccountID, tokenID, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64, *serviceaccounts.RotateServiceAccountTokenCommand) *apikey.APIKey); ok {
+		r0 = rf(ctx, orgID, serviceAccountID, tokenID, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikey.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64, *serviceaccounts.RotateServiceAccountTokenCommand) error); ok {
+		r1 = rf(ctx, orgID, serviceAccountID, tokenID, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SearchOrgServiceAccounts provides a mock function with given fields: ctx, query
 func (_m *MockServiceAccountService) SearchOrgServiceAccounts(ctx context.Context, query *serviceaccounts.SearchOrgServiceAccountsQuery) (*serviceaccounts.SearchOrgServiceAccountsResult, error) {
 	ret := _m.Called(ctx, query)
@@ -279,6 +331,20 @@ func (_m *MockServiceAccountService) UpdateServiceAccount(ctx context.Context, o
 	return r0, r1
 }
 
+// UpdateTokenPolicy provides a mock function with given fields: ctx, orgID, policy
+func (_m *MockServiceAccountService) UpdateTokenPolicy(ctx context.Context, orgID int64, policy *serviceaccounts.TokenPolicy) error {
+	ret := _m.Called(ctx, orgID, policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *serviceaccounts.TokenPolicy) error); ok {
+		r0 = rf(ctx, orgID, policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewMockServiceAccountService creates a new instance of MockServiceAccountService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockServiceAccountService(t interface {
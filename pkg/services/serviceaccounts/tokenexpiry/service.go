@@ -0,0 +1,221 @@
+package tokenexpiry
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace = "serviceaccounts.tokenexpiry"
+
+	tmplTokenExpiring = "service_account_token_expiring"
+
+	orgUsersPageSize = 500
+)
+
+type Checker interface {
+	CheckTokens(ctx context.Context) error
+}
+
+type WebHookClient interface {
+	Notify(ctx context.Context, token *serviceaccounts.ExpiringToken) error
+}
+
+type SATokenRetriever interface {
+	ListExpiringTokens(ctx context.Context, query *serviceaccounts.ListExpiringTokensQuery) ([]serviceaccounts.ExpiringToken, error)
+	ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error)
+}
+
+// Service notifies the admins of an organization, and optionally a webhook, when a service
+// account token is about to expire. Every token is notified once per expiration date.
+type Service struct {
+	store               SATokenRetriever
+	notificationService notifications.Service
+	orgService          org.Service
+	kvStore             kvstore.KVStore
+	webHookClient       WebHookClient
+	logger              log.Logger
+
+	notifyBefore time.Duration
+	emailEnabled bool
+	appURL       string
+	now          func() time.Time
+}
+
+func NewService(store SATokenRetriever, cfg *setting.Cfg, notificationService notifications.Service,
+	orgService org.Service, kvStore kvstore.KVStore) (*Service, error) {
+	var webHookClient WebHookClient
+	if cfg.SATokenExpiryWebhookURL != "" {
+		var err error
+		webHookClient, err = newWebHookClient(cfg.SATokenExpiryWebhookURL, cfg.BuildVersion, cfg.Env == setting.Dev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token expiry webhook client: %w", err)
+		}
+	}
+
+	return &Service{
+		store:               store,
+		notificationService: notificationService,
+		orgService:          orgService,
+		kvStore:             kvStore,
+		webHookClient:       webHookClient,
+		logger:              log.New("serviceaccounts.tokenexpiry"),
+		notifyBefore:        time.Duration(cfg.SATokenExpiryNotificationDays) * 24 * time.Hour,
+		emailEnabled:        cfg.Smtp.Enabled,
+		appURL:              cfg.AppURL,
+		now:                 time.Now,
+	}, nil
+}
+
+// CheckTokens sends notifications for the tokens expiring within the notification period.
+func (s *Service) CheckTokens(ctx context.Context) error {
+	tokens, err := s.store.ListExpiringTokens(ctx, &serviceaccounts.ListExpiringTokensQuery{
+		ExpiresBefore: s.now().Add(s.notifyBefore),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve expiring tokens: %w", err)
+	}
+
+	for i := range tokens {
+		token := &tokens[i]
+		kv := kvstore.WithNamespace(s.kvStore, token.OrgID, kvNamespace)
+		key := strconv.FormatInt(token.ID, 10)
+		expires := strconv.FormatInt(token.Expires, 10)
+
+		notified, ok, err := kv.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if ok && notified == expires {
+			continue
+		}
+
+		rotated, err := s.hasSuccessor(ctx, token)
+		if err != nil {
+			return err
+		}
+		if rotated {
+			s.logger.Debug("Skipping expiring token that has a successor", "token_id", token.ID, "org", token.OrgID)
+			continue
+		}
+
+		s.notify(ctx, token)
+
+		if err := kv.Set(ctx, key, expires); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasSuccessor returns true if the service account has a newer token that outlives token,
+// which is the case once it has been rotated.
+func (s *Service) hasSuccessor(ctx context.Context, token *serviceaccounts.ExpiringToken) (bool, error) {
+	saTokens, err := s.store.ListTokens(ctx, &serviceaccounts.GetSATokensQuery{
+		OrgID:            &token.OrgID,
+		ServiceAccountID: &token.ServiceAccountID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range saTokens {
+		if t.ID == token.ID || !t.Created.After(token.Created) || (t.IsRevoked != nil && *t.IsRevoked) {
+			continue
+		}
+		if t.Expires == nil || *t.Expires > token.Expires {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *Service) notify(ctx context.Context, token *serviceaccounts.ExpiringToken) {
+	s.logger.Info("Service account token is about to expire",
+		"token_id", token.ID, "token", token.Name, "org", token.OrgID,
+		"serviceAccount", token.ServiceAccountID, "expires", time.Unix(token.Expires, 0))
+
+	if s.webHookClient != nil {
+		if err := s.webHookClient.Notify(ctx, token); err != nil {
+			s.logger.Warn("Failed to call token expiry webhook", "token_id", token.ID, "error", err)
+		}
+	}
+
+	if !s.emailEnabled {
+		return
+	}
+
+	recipients, err := s.orgAdminEmails(ctx, token.OrgID)
+	if err != nil {
+		s.logger.Warn("Failed to find organization admins to notify about expiring token", "org", token.OrgID, "error", err)
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	err = s.notificationService.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       recipients,
+		Template: tmplTokenExpiring,
+		Data: map[string]any{
+			"TokenName":          token.Name,
+			"ServiceAccountName": token.ServiceAccountName,
+			"ExpiresAt":          time.Unix(token.Expires, 0).UTC().Format(time.RFC1123),
+			"ServiceAccountURL":  fmt.Sprintf("%sorg/serviceaccounts/%d", s.appURL, token.ServiceAccountID),
+		},
+	})
+	if err != nil {
+		s.logger.Warn("Failed to send token expiry notification", "token_id", token.ID, "error", err)
+	}
+}
+
+func (s *Service) orgAdminEmails(ctx context.Context, orgID int64) ([]string, error) {
+	// background identity used to list the members of the organization
+	requester := &user.SignedInUser{
+		OrgID:   orgID,
+		Login:   "sa-tokenexpiry",
+		OrgRole: org.RoleAdmin,
+		Permissions: map[int64]map[string][]string{
+			orgID: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}},
+		},
+		IsServiceAccount: true,
+	}
+
+	emails := make([]string, 0)
+	for page := 1; ; page++ {
+		result, err := s.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{
+			OrgID:                    orgID,
+			Page:                     page,
+			Limit:                    orgUsersPageSize,
+			DontEnforceAccessControl: true,
+			User:                     requester,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range result.OrgUsers {
+			if u.Role == string(org.RoleAdmin) && !u.IsDisabled && u.Email != "" {
+				emails = append(emails, u.Email)
+			}
+		}
+
+		if len(result.OrgUsers) < orgUsersPageSize {
+			return emails, nil
+		}
+	}
+}
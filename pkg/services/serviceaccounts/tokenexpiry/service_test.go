@@ -0,0 +1,157 @@
+package tokenexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+type fakeTokenStore struct {
+	expiring []serviceaccounts.ExpiringToken
+	tokens   []apikey.APIKey
+}
+
+func (f *fakeTokenStore) ListExpiringTokens(ctx context.Context, query *serviceaccounts.ListExpiringTokensQuery) ([]serviceaccounts.ExpiringToken, error) {
+	return f.expiring, nil
+}
+
+func (f *fakeTokenStore) ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error) {
+	return f.tokens, nil
+}
+
+type fakeWebHookClient struct {
+	notified []int64
+}
+
+func (f *fakeWebHookClient) Notify(ctx context.Context, token *serviceaccounts.ExpiringToken) error {
+	f.notified = append(f.notified, token.ID)
+	return nil
+}
+
+func setupService(store *fakeTokenStore) (*Service, *notifications.NotificationServiceMock, *fakeWebHookClient) {
+	notificationService := notifications.MockNotificationService()
+	webHookClient := &fakeWebHookClient{}
+	orgService := &orgtest.FakeOrgService{
+		ExpectedSearchOrgUsersResult: &org.SearchOrgUsersQueryResult{
+			OrgUsers: []*org.OrgUserDTO{
+				{UserID: 1, Email: "admin@example.org", Role: string(org.RoleAdmin)},
+				{UserID: 2, Email: "disabled@example.org", Role: string(org.RoleAdmin), IsDisabled: true},
+				{UserID: 3, Email: "editor@example.org", Role: string(org.RoleEditor)},
+			},
+		},
+	}
+
+	return &Service{
+		store:               store,
+		notificationService: notificationService,
+		orgService:          orgService,
+		kvStore:             kvstore.NewFakeKVStore(),
+		webHookClient:       webHookClient,
+		logger:              log.NewNopLogger(),
+		notifyBefore:        7 * 24 * time.Hour,
+		emailEnabled:        true,
+		appURL:              "https://grafana.example.org/",
+		now:                 time.Now,
+	}, notificationService, webHookClient
+}
+
+func TestService_CheckTokens(t *testing.T) {
+	created := time.Now().Add(-30 * 24 * time.Hour)
+	expires := time.Now().Add(48 * time.Hour).Unix()
+	expiring := serviceaccounts.ExpiringToken{
+		ID:                 1,
+		OrgID:              1,
+		Name:               "ci",
+		ServiceAccountID:   10,
+		ServiceAccountName: "deployer",
+		Created:            created,
+		Expires:            expires,
+	}
+
+	t.Run("should notify org admins and webhook once per token", func(t *testing.T) {
+		store := &fakeTokenStore{expiring: []serviceaccounts.ExpiringToken{expiring}}
+		s, notificationService, webHookClient := setupService(store)
+		sent := 0
+		notificationService.EmailHandler = func(ctx context.Context, cmd *notifications.SendEmailCommand) error {
+			sent++
+			return nil
+		}
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, []string{"admin@example.org"}, notificationService.Email.To)
+		assert.Equal(t, tmplTokenExpiring, notificationService.Email.Template)
+		assert.Equal(t, "ci", notificationService.Email.Data["TokenName"])
+		assert.Equal(t, "https://grafana.example.org/org/serviceaccounts/10", notificationService.Email.Data["ServiceAccountURL"])
+		assert.Equal(t, []int64{1}, webHookClient.notified)
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, []int64{1}, webHookClient.notified)
+	})
+
+	t.Run("should notify again when the expiration changed", func(t *testing.T) {
+		store := &fakeTokenStore{expiring: []serviceaccounts.ExpiringToken{expiring}}
+		s, _, webHookClient := setupService(store)
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+
+		store.expiring[0].Expires = expires + 3600
+		require.NoError(t, s.CheckTokens(context.Background()))
+		assert.Equal(t, []int64{1, 1}, webHookClient.notified)
+	})
+
+	t.Run("should skip tokens that have been rotated", func(t *testing.T) {
+		store := &fakeTokenStore{
+			expiring: []serviceaccounts.ExpiringToken{expiring},
+			tokens: []apikey.APIKey{
+				{ID: 1, Name: "ci", Created: created, Expires: &expires},
+				{ID: 2, Name: "ci-rotated-1", Created: time.Now()},
+			},
+		}
+		s, notificationService, webHookClient := setupService(store)
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+		assert.Empty(t, notificationService.Email.To)
+		assert.Empty(t, webHookClient.notified)
+	})
+
+	t.Run("should not consider older or revoked tokens as successors", func(t *testing.T) {
+		revoked := true
+		later := expires + 3600
+		store := &fakeTokenStore{
+			expiring: []serviceaccounts.ExpiringToken{expiring},
+			tokens: []apikey.APIKey{
+				{ID: 1, Name: "ci", Created: created, Expires: &expires},
+				{ID: 2, Name: "old", Created: created.Add(-time.Hour), Expires: &later},
+				{ID: 3, Name: "revoked", Created: time.Now(), IsRevoked: &revoked},
+			},
+		}
+		s, _, webHookClient := setupService(store)
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+		assert.Equal(t, []int64{1}, webHookClient.notified)
+	})
+
+	t.Run("should not send emails when smtp is disabled", func(t *testing.T) {
+		store := &fakeTokenStore{expiring: []serviceaccounts.ExpiringToken{expiring}}
+		s, notificationService, webHookClient := setupService(store)
+		s.emailEnabled = false
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+		assert.Empty(t, notificationService.Email.To)
+		assert.Equal(t, []int64{1}, webHookClient.notified)
+	})
+}
@@ -0,0 +1,98 @@
+package tokenexpiry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+const timeout = 4 * time.Second
+
+var (
+	errWebHookURL               = errors.New("webhook url must be https")
+	ErrInvalidWebHookStatusCode = errors.New("invalid webhook status code")
+)
+
+// webHookClient is a client for sending token expiry notifications.
+type webHookClient struct {
+	httpClient *http.Client
+	version    string
+	url        string
+}
+
+func newWebHookClient(url, version string, dev bool) (*webHookClient, error) {
+	if !strings.HasPrefix(url, "https://") && !dev {
+		return nil, errWebHookURL
+	}
+
+	return &webHookClient{
+		version: version,
+		url:     url,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   timeout,
+					KeepAlive: 15 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			Timeout: time.Second * 30,
+		},
+	}, nil
+}
+
+func (wClient *webHookClient) Notify(ctx context.Context, token *serviceaccounts.ExpiringToken) error {
+	expires := time.Unix(token.Expires, 0).UTC()
+
+	values := map[string]any{
+		"title":              "Grafana service account token expiring",
+		"state":              "alerting",
+		"orgId":              token.OrgID,
+		"serviceAccountId":   token.ServiceAccountID,
+		"serviceAccountName": token.ServiceAccountName,
+		"tokenId":            token.ID,
+		"tokenName":          token.Name,
+		"expires":            expires.Format(time.RFC3339),
+		"message": "Token " + token.Name + " of service account " + token.ServiceAccountName +
+			" expires on " + expires.Format(time.RFC1123) + ".",
+	}
+
+	jsonValue, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to marshal webhook request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wClient.url, bytes.NewReader(jsonValue))
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to make http request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "grafana-tokenexpiry-webhook-client/"+wClient.version)
+
+	resp, err := wClient.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to webhook request", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w. status code %s", ErrInvalidWebHookStatusCode, resp.Status)
+	}
+
+	return nil
+}
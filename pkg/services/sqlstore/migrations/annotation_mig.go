@@ -191,6 +191,25 @@ func addAnnotationMig(mg *Migrator) {
 	mg.AddMigration("Increase new_state column to length 40 not null", NewRawSQLMigration("").
 		Postgres("ALTER TABLE annotation ALTER COLUMN new_state TYPE VARCHAR(40);"). // Does not modify nullability.
 		Mysql("ALTER TABLE annotation MODIFY new_state VARCHAR(40) NOT NULL;"))
+
+	// keys of the imported annotations, so that importing them again doesn't duplicate them
+	annotationImportTable := Table{
+		Name: "annotation_import",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "import_key", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "annotation_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "import_key"}, Type: UniqueIndex},
+			{Cols: []string{"annotation_id"}, Type: IndexType},
+		},
+	}
+
+	mg.AddMigration("create annotation_import table", NewAddTableMigration(annotationImportTable))
+	addTableIndicesMigrations(mg, "v1", annotationImportTable)
 }
 
 type AddMakeRegionSingleRowMigration struct {
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAuditLogMigrations(mg *Migrator) {
+	auditLogV1 := Table{
+		Name: "audit_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "method", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "path", Type: DB_Text, Nullable: false},
+			{Name: "status_code", Type: DB_Int, Nullable: false},
+			{Name: "result", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "user_agent", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "state_before", Type: DB_MediumText, Nullable: true},
+			{Name: "state_after", Type: DB_MediumText, Nullable: true},
+			{Name: "diff", Type: DB_MediumText, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"created"}},
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"actor_login"}},
+			{Cols: []string{"action"}},
+		},
+	}
+
+	mg.AddMigration("create audit_log table v1", NewAddTableMigration(auditLogV1))
+	addTableIndicesMigrations(mg, "v1", auditLogV1)
+}
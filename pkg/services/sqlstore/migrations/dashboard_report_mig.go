@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardReportMigrations(mg *Migrator) {
+	reportV1 := Table{
+		Name: "dashboard_report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "variables", Type: DB_Text, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "schedule", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "format", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "next_run", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"enabled", "next_run"}},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+		},
+	}
+	mg.AddMigration("create dashboard_report table v1", NewAddTableMigration(reportV1))
+	addTableIndicesMigrations(mg, "v1", reportV1)
+
+	deliveryV1 := Table{
+		Name: "dashboard_report_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "report_id", Type: DB_BigInt, Nullable: false},
+			{Name: "trigger_type", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "attachments", Type: DB_Int, Nullable: false},
+			{Name: "started", Type: DB_DateTime, Nullable: false},
+			{Name: "finished", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"report_id", "started"}},
+			{Cols: []string{"started"}},
+		},
+	}
+	mg.AddMigration("create dashboard_report_delivery table v1", NewAddTableMigration(deliveryV1))
+	addTableIndicesMigrations(mg, "v1", deliveryV1)
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addFolderDefaultsMigrations(mg *Migrator) {
+	folderDefaultsV1 := Table{
+		Name: "folder_defaults",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "folder_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "min_refresh_interval", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "default_datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "required_tags", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "folder_uid"}, Type: UniqueIndex},
+		},
+	}
+	mg.AddMigration("create folder_defaults table v1", NewAddTableMigration(folderDefaultsV1))
+	addTableIndicesMigrations(mg, "v1", folderDefaultsV1)
+}
@@ -83,4 +83,26 @@ func addLibraryElementsMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("populate library_element folder_uid", migrator.NewRawSQLMigration(q))
 
 	mg.AddMigration("add index library_element org_id-folder_uid-name-kind", migrator.NewAddIndexMigration(libraryElementsV1, &migrator.Index{Cols: []string{"org_id", "folder_uid", "name", "kind"}, Type: migrator.UniqueIndex}))
+
+	libraryElementVersionV1 := migrator.Table{
+		Name: model.LibraryElementVersionTableName,
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "element_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "restored_from", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 150, Nullable: false},
+			{Name: "model", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"element_id", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create "+model.LibraryElementVersionTableName+" table v1", migrator.NewAddTableMigration(libraryElementVersionV1))
+	mg.AddMigration("add unique index "+model.LibraryElementVersionTableName+" element_id-version", migrator.NewAddIndexMigration(libraryElementVersionV1, libraryElementVersionV1.Indices[0]))
 }
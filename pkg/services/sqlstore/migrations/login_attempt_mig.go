@@ -40,3 +40,35 @@ func addLoginAttemptMigrations(mg *Migrator) {
 		"ip_address": "ip_address",
 	})
 }
+
+func addLoginLockoutMigrations(mg *Migrator) {
+	loginAttemptV2 := Table{Name: "login_attempt"}
+
+	mg.AddMigration("add column subnet to login_attempt", NewAddColumnMigration(loginAttemptV2, &Column{
+		Name: "subnet", Type: DB_NVarchar, Length: 50, Nullable: true,
+	}))
+	mg.AddMigration("add index login_attempt.ip_address", NewAddIndexMigration(loginAttemptV2, &Index{
+		Cols: []string{"ip_address"},
+	}))
+	mg.AddMigration("add index login_attempt.subnet", NewAddIndexMigration(loginAttemptV2, &Index{
+		Cols: []string{"subnet"},
+	}))
+
+	loginLockoutV1 := Table{
+		Name: "login_lockout",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "username", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "attempts", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "expires", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"username"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create login_lockout table", NewAddTableMigration(loginLockoutV1))
+	mg.AddMigration("add unique index login_lockout.username", NewAddIndexMigration(loginLockoutV1, loginLockoutV1.Indices[0]))
+}
@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addMFAMigrations(mg *Migrator) {
+	totpV1 := Table{
+		Name: "user_mfa_totp",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "confirmed", Type: DB_Bool, Nullable: false},
+			{Name: "last_step", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: UniqueIndex},
+		},
+	}
+	mg.AddMigration("create user_mfa_totp table v1", NewAddTableMigration(totpV1))
+	addTableIndicesMigrations(mg, "v1", totpV1)
+
+	recoveryCodeV1 := Table{
+		Name: "user_mfa_recovery_code",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "code_hash", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "salt", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}},
+		},
+	}
+	mg.AddMigration("create user_mfa_recovery_code table v1", NewAddTableMigration(recoveryCodeV1))
+	addTableIndicesMigrations(mg, "v1", recoveryCodeV1)
+
+	webAuthnV1 := Table{
+		Name: "user_mfa_webauthn",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "credential_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "public_key", Type: DB_Text, Nullable: false},
+			{Name: "sign_count", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "last_used", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"credential_id"}, Type: UniqueIndex},
+			{Cols: []string{"user_id"}},
+		},
+	}
+	mg.AddMigration("create user_mfa_webauthn table v1", NewAddTableMigration(webAuthnV1))
+	addTableIndicesMigrations(mg, "v1", webAuthnV1)
+	mg.AddMigration("add backup_eligible column to user_mfa_webauthn", NewAddColumnMigration(webAuthnV1, &Column{
+		Name: "backup_eligible", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	orgPolicyV1 := Table{
+		Name: "org_mfa_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "required", Type: DB_Bool, Nullable: false},
+			{Name: "roles", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+	mg.AddMigration("create org_mfa_policy table v1", NewAddTableMigration(orgPolicyV1))
+	addTableIndicesMigrations(mg, "v1", orgPolicyV1)
+}
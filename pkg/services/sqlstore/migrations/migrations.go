@@ -145,4 +145,16 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.AddAlertRuleUpdatedByMigration(mg)
 
 	ualert.AddAlertRuleStateTable(mg)
+
+	addAuditLogMigrations(mg)
+
+	addMFAMigrations(mg)
+
+	addLoginLockoutMigrations(mg)
+
+	addNotificationOutboxMigrations(mg)
+
+	addFolderDefaultsMigrations(mg)
+
+	addDashboardReportMigrations(mg)
 }
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addNotificationOutboxMigrations(mg *Migrator) {
+	outboxV1 := Table{
+		Name: "notification_outbox",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "recipient", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "subject", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "attachment_id", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "attempts", Type: DB_BigInt, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+			{Name: "next_attempt", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"status", "next_attempt"}},
+			{Cols: []string{"recipient"}},
+			{Cols: []string{"attachment_id"}},
+		},
+	}
+	mg.AddMigration("create notification_outbox table v1", NewAddTableMigration(outboxV1))
+	addTableIndicesMigrations(mg, "v1", outboxV1)
+
+	attachmentV1 := Table{
+		Name: "notification_outbox_attachment",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "content", Type: DB_MediumText, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+	}
+	mg.AddMigration("create notification_outbox_attachment table v1", NewAddTableMigration(attachmentV1))
+}
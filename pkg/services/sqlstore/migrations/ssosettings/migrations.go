@@ -16,4 +16,23 @@ func AddMigration(mg *migrator.Migrator) {
 	}
 
 	mg.AddMigration("create sso_setting table", migrator.NewAddTableMigration(ssoSettingV1))
+
+	var ssoSettingVersionV1 = migrator.Table{
+		Name: "sso_setting_version",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "provider", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "settings", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "restored_from", Type: migrator.DB_BigInt, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"provider", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create sso_setting_version table", migrator.NewAddTableMigration(ssoSettingVersionV1))
+	mg.AddMigration("add unique index sso_setting_version.provider_version", migrator.NewAddIndexMigration(ssoSettingVersionV1, ssoSettingVersionV1.Indices[0]))
 }
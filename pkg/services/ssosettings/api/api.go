@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"hash/fnv"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ssosettings"
@@ -72,6 +74,9 @@ func (api *Api) RegisterAPIEndpoints() {
 		router.Get("/:key", auth(ac.EvalPermission(ac.ActionSettingsRead, settingsScope)), routing.Wrap(api.getProviderSettings))
 		router.Put("/:key", reqWriteAccess, routing.Wrap(api.updateProviderSettings))
 		router.Delete("/:key", reqWriteAccess, routing.Wrap(api.removeProviderSettings))
+		router.Post("/:key/test", reqWriteAccess, routing.Wrap(api.testProviderSettings))
+		router.Get("/:key/versions", auth(ac.EvalPermission(ac.ActionSettingsRead, settingsScope)), routing.Wrap(api.listProviderSettingsVersions))
+		router.Post("/:key/versions/:version/restore", reqWriteAccess, routing.Wrap(api.restoreProviderSettingsVersion))
 	})
 }
 
@@ -178,10 +183,12 @@ func (api *Api) updateProviderSettings(c *contextmodel.ReqContext) response.Resp
 
 	settings.Provider = key
 
+	api.recordProviderSettings(c.Req.Context(), key, auditlog.RecordBefore)
 	err := api.SSOSettingsService.Upsert(c.Req.Context(), &settings, c.SignedInUser)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update provider settings", err)
 	}
+	api.recordProviderSettings(c.Req.Context(), key, auditlog.RecordAfter)
 
 	return response.Empty(http.StatusNoContent)
 }
@@ -207,14 +214,129 @@ func (api *Api) removeProviderSettings(c *contextmodel.ReqContext) response.Resp
 		return response.Error(http.StatusBadRequest, "Missing key", nil)
 	}
 
+	api.recordProviderSettings(c.Req.Context(), key, auditlog.RecordBefore)
 	err := api.SSOSettingsService.Delete(c.Req.Context(), key)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete provider settings", err)
 	}
+	api.recordProviderSettings(c.Req.Context(), key, auditlog.RecordAfter)
 
 	return response.Empty(http.StatusNoContent)
 }
 
+// swagger:route POST /v1/sso-settings/{key}/test sso_settings testProviderSettings
+//
+// # Test SSO Settings
+//
+// Validates the SSO Settings for a provider and checks that the identity provider can be reached with them.
+// The settings are not saved.
+//
+// You need to have a permission with action `settings:write` and scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *Api) testProviderSettings(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	var settings models.SSOSettings
+	if err := web.Bind(c.Req, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to parse request body", err)
+	}
+
+	settings.Provider = key
+
+	err := api.SSOSettingsService.TestConnection(c.Req.Context(), &settings, c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to test provider settings", err)
+	}
+
+	return response.Success("Connection test succeeded")
+}
+
+// swagger:route GET /v1/sso-settings/{key}/versions sso_settings listProviderSettingsVersions
+//
+// # List SSO Settings versions
+//
+// Lists the saved versions of the SSO Settings for a provider, newest first.
+//
+// You need to have a permission with action `settings:read` with scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 200: listSSOSettingsVersionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (api *Api) listProviderSettingsVersions(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	versions, err := api.SSOSettingsService.ListVersions(c.Req.Context(), key)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list provider settings versions", err)
+	}
+
+	return response.JSON(http.StatusOK, versions)
+}
+
+// swagger:route POST /v1/sso-settings/{key}/versions/{version}/restore sso_settings restoreProviderSettingsVersion
+//
+// # Restore SSO Settings version
+//
+// Saves a previous version of the SSO Settings for a provider as the current settings.
+//
+// You need to have a permission with action `settings:write` and scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 204: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *Api) restoreProviderSettingsVersion(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid version", err)
+	}
+
+	api.recordProviderSettings(c.Req.Context(), key, auditlog.RecordBefore)
+	err = api.SSOSettingsService.Restore(c.Req.Context(), key, version, c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to restore provider settings version", err)
+	}
+	api.recordProviderSettings(c.Req.Context(), key, auditlog.RecordAfter)
+
+	return response.Empty(http.StatusNoContent)
+}
+
+// recordProviderSettings records the settings of the provider, with their secrets redacted,
+// as the before or after state of an audited request.
+func (api *Api) recordProviderSettings(ctx context.Context, key string, record func(context.Context, any)) {
+	if !auditlog.Recording(ctx) {
+		return
+	}
+	provider, err := api.SSOSettingsService.GetForProviderWithRedactedSecrets(ctx, key)
+	if err != nil {
+		return
+	}
+	record(ctx, provider)
+}
+
 // swagger:parameters listAllProvidersSettings
 type ListAllProvidersSettingsParams struct {
 }
@@ -247,6 +369,37 @@ type RemoveProviderSettingsParams struct {
 	Provider string `json:"key"`
 }
 
+// swagger:parameters testProviderSettings
+type TestProviderSettingsParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+	// in:body
+	// required:true
+	Body struct {
+		ID       string         `json:"id"`
+		Provider string         `json:"provider"`
+		Settings map[string]any `json:"settings"`
+	} `json:"body"`
+}
+
+// swagger:parameters listProviderSettingsVersions
+type ListProviderSettingsVersionsParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+}
+
+// swagger:parameters restoreProviderSettingsVersion
+type RestoreProviderSettingsVersionParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+	// in:path
+	// required:true
+	Version int64 `json:"version"`
+}
+
 // swagger:response listSSOSettingsResponse
 type ListSSOSettingsResponse struct {
 	// in: body
@@ -268,3 +421,16 @@ type GetSSOSettingsResponse struct {
 		Source   string         `json:"source"`
 	} `json:"body"`
 }
+
+// swagger:response listSSOSettingsVersionsResponse
+type ListSSOSettingsVersionsResponse struct {
+	// in: body
+	Body []struct {
+		Provider     string         `json:"provider"`
+		Version      int64          `json:"version"`
+		Settings     map[string]any `json:"settings"`
+		Created      string         `json:"created"`
+		CreatedBy    string         `json:"createdBy"`
+		RestoredFrom int64          `json:"restoredFrom,omitempty"`
+	} `json:"body"`
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -19,12 +20,15 @@ import (
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/ssosettings"
 	"github.com/grafana/grafana/pkg/services/ssosettings/models"
 	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
 
@@ -549,6 +553,282 @@ func TestSSOSettingsAPI_List(t *testing.T) {
 	}
 }
 
+func TestSSOSettingsAPI_TestConnection(t *testing.T) {
+	type TestCase struct {
+		desc                string
+		body                string
+		action              string
+		scope               string
+		expectedError       error
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}
+
+	tests := []TestCase{
+		{
+			desc:                "successfully tests SSO settings",
+			body:                `{"settings": {"enabled": true}}`,
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusOK,
+		},
+		{
+			desc:                "fails when action doesn't match",
+			body:                `{"settings": {"enabled": true}}`,
+			action:              "settings:read",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails with bad request when body contains invalid json",
+			body:                `{ invalid json }`,
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusBadRequest,
+		},
+		{
+			desc:                "fails with bad request when the connection test fails",
+			body:                `{"settings": {"enabled": true}}`,
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedError:       ssosettings.ErrConnectionTestFailed(errors.New("token URL returned 404")),
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var input models.SSOSettings
+			_ = json.Unmarshal([]byte(tt.body), &input)
+
+			settings := models.SSOSettings{
+				Provider: social.GitHubProviderName,
+				Settings: input.Settings,
+			}
+
+			signedInUser := &user.SignedInUser{
+				OrgRole:     org.RoleAdmin,
+				OrgID:       1,
+				Permissions: getPermissionsForActionAndScope(tt.action, tt.scope),
+			}
+
+			service := ssosettingstests.NewMockService(t)
+			if tt.expectedServiceCall {
+				service.On("TestConnection", mock.Anything, &settings, signedInUser).Return(tt.expectedError).Once()
+			}
+			server := setupTests(t, service)
+
+			req := server.NewRequest(http.MethodPost, "/api/v1/sso-settings/github/test", bytes.NewBufferString(tt.body))
+			webtest.RequestWithSignedInUser(req, signedInUser)
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestSSOSettingsAPI_ListVersions(t *testing.T) {
+	type TestCase struct {
+		desc                string
+		action              string
+		scope               string
+		expectedError       error
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}
+
+	tests := []TestCase{
+		{
+			desc:                "successfully lists the versions",
+			action:              "settings:read",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusOK,
+		},
+		{
+			desc:                "fails when scope doesn't match",
+			action:              "settings:read",
+			scope:               "settings:auth.okta:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails with not found when the provider is not configurable",
+			action:              "settings:read",
+			scope:               "settings:auth.github:*",
+			expectedError:       ssosettings.ErrNotConfigurable,
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			versions := []*models.SSOSettingsVersion{
+				{
+					Provider:  social.GitHubProviderName,
+					Version:   2,
+					Settings:  map[string]any{"client_id": "new-client"},
+					CreatedBy: "admin",
+				},
+				{
+					Provider:  social.GitHubProviderName,
+					Version:   1,
+					Settings:  map[string]any{"client_id": "client"},
+					CreatedBy: "admin",
+				},
+			}
+
+			service := ssosettingstests.NewMockService(t)
+			if tt.expectedServiceCall {
+				if tt.expectedError != nil {
+					versions = nil
+				}
+				service.On("ListVersions", mock.Anything, social.GitHubProviderName).Return(versions, tt.expectedError).Once()
+			}
+			server := setupTests(t, service)
+
+			req := server.NewRequest(http.MethodGet, "/api/v1/sso-settings/github/versions", nil)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgRole:     org.RoleAdmin,
+				OrgID:       1,
+				Permissions: getPermissionsForActionAndScope(tt.action, tt.scope),
+			})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedStatusCode == http.StatusOK {
+				var result []map[string]any
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+				require.Len(t, result, 2)
+				require.EqualValues(t, 2, result[0]["version"])
+				require.Equal(t, "admin", result[0]["createdBy"])
+				require.Equal(t, map[string]any{"clientId": "new-client"}, result[0]["settings"])
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestSSOSettingsAPI_Restore(t *testing.T) {
+	type TestCase struct {
+		desc                string
+		version             string
+		action              string
+		scope               string
+		expectedError       error
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}
+
+	tests := []TestCase{
+		{
+			desc:                "successfully restores a version",
+			version:             "3",
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNoContent,
+		},
+		{
+			desc:                "fails when action doesn't match",
+			version:             "3",
+			action:              "settings:read",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails with bad request when the version is invalid",
+			version:             "latest",
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusBadRequest,
+		},
+		{
+			desc:                "fails with not found when the version doesn't exist",
+			version:             "3",
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedError:       ssosettings.ErrVersionNotFound.Errorf("not found"),
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNotFound,
+		},
+		{
+			desc:                "fails with bad request when the restored settings are invalid",
+			version:             "3",
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedError:       ssosettings.ErrInvalidOAuthConfig("invalid"),
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			signedInUser := &user.SignedInUser{
+				OrgRole:     org.RoleAdmin,
+				OrgID:       1,
+				Permissions: getPermissionsForActionAndScope(tt.action, tt.scope),
+			}
+
+			service := ssosettingstests.NewMockService(t)
+			if tt.expectedServiceCall {
+				service.On("Restore", mock.Anything, social.GitHubProviderName, int64(3), signedInUser).Return(tt.expectedError).Once()
+			}
+			server := setupTests(t, service)
+
+			path := fmt.Sprintf("/api/v1/sso-settings/github/versions/%s/restore", tt.version)
+			req := server.NewRequest(http.MethodPost, path, nil)
+			webtest.RequestWithSignedInUser(req, signedInUser)
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestSSOSettingsAPI_UpdateRecordsState(t *testing.T) {
+	signedInUser := &user.SignedInUser{
+		OrgRole:     org.RoleAdmin,
+		OrgID:       1,
+		Permissions: getPermissionsForActionAndScope("settings:write", "settings:auth.github:*"),
+	}
+
+	before := &models.SSOSettings{Provider: social.GitHubProviderName, Settings: map[string]any{"enabled": false}}
+	after := &models.SSOSettings{Provider: social.GitHubProviderName, Settings: map[string]any{"enabled": true}}
+
+	service := ssosettingstests.NewMockService(t)
+	service.On("GetForProviderWithRedactedSecrets", mock.Anything, social.GitHubProviderName).Return(before, nil).Once()
+	service.On("Upsert", mock.Anything, mock.Anything, signedInUser).Return(nil).Once()
+	service.On("GetForProviderWithRedactedSecrets", mock.Anything, social.GitHubProviderName).Return(after, nil).Once()
+	api := &Api{Log: log.NewNopLogger(), SSOSettingsService: service}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/sso-settings/github", bytes.NewBufferString(`{"settings": {"enabled": true}}`))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, recorder := auditlog.WithRecorder(req.Context())
+	req = web.SetURLParams(req.WithContext(ctx), map[string]string{":key": social.GitHubProviderName})
+	c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: signedInUser}
+
+	res := api.updateProviderSettings(c)
+	require.Equal(t, http.StatusNoContent, res.Status())
+
+	recordedBefore, recordedAfter, _ := recorder.State()
+	require.Equal(t, before, recordedBefore)
+	require.Equal(t, after, recordedAfter)
+}
+
 func getPermissionsForActionAndScope(action, scope string) map[int64]map[string][]string {
 	return map[int64]map[string][]string{
 		1: accesscontrol.GroupScopesByActionContext(context.Background(), []accesscontrol.Permission{{
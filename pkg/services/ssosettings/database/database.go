@@ -15,6 +15,9 @@ import (
 const (
 	isDeletedColumn = "is_deleted"
 	updatedColumn   = "updated"
+
+	// versionsToKeep is the number of settings versions kept for each provider
+	versionsToKeep = 30
 )
 
 type SSOSettingsStore struct {
@@ -89,7 +92,7 @@ func (s *SSOSettingsStore) Upsert(ctx context.Context, settings *models.SSOSetti
 		return ssosettings.ErrNotFound
 	}
 
-	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		existing := &models.SSOSettings{
 			Provider:  settings.Provider,
 			IsDeleted: false,
@@ -115,9 +118,73 @@ func (s *SSOSettingsStore) Upsert(ctx context.Context, settings *models.SSOSetti
 			settings.Updated = now
 			_, err = sess.Insert(settings)
 		}
+		if err != nil {
+			return err
+		}
+
+		return s.addVersion(sess, settings, now)
+	})
+}
+
+// addVersion records a snapshot of the saved settings and removes the oldest versions of the provider
+func (s *SSOSettingsStore) addVersion(sess *db.Session, settings *models.SSOSettings, now time.Time) error {
+	latest := &models.SSOSettingsVersion{}
+	_, err := sess.Cols("version").Where("provider = ?", settings.Provider).Desc("version").Limit(1).Get(latest)
+	if err != nil {
+		return err
+	}
 
+	version := &models.SSOSettingsVersion{
+		Provider:     settings.Provider,
+		Version:      latest.Version + 1,
+		Settings:     settings.Settings,
+		Created:      now,
+		CreatedBy:    settings.UpdatedBy,
+		RestoredFrom: settings.RestoredFrom,
+	}
+	if _, err := sess.Insert(version); err != nil {
 		return err
+	}
+
+	_, err = sess.Where("provider = ? AND version <= ?", settings.Provider, version.Version-versionsToKeep).Delete(&models.SSOSettingsVersion{})
+	return err
+}
+
+func (s *SSOSettingsStore) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	result := make([]*models.SSOSettingsVersion, 0)
+
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("provider = ?", provider).Desc("version").Find(&result)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *SSOSettingsStore) GetVersion(ctx context.Context, provider string, version int64) (*models.SSOSettingsVersion, error) {
+	result := models.SSOSettingsVersion{}
+
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		found, err := sess.Where("provider = ? AND version = ?", provider, version).Get(&result)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return ssosettings.ErrVersionNotFound.Errorf("version %d not found for provider %s", version, provider)
+		}
+
+		return nil
 	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
 
 func (s *SSOSettingsStore) Delete(ctx context.Context, provider string) error {
@@ -457,6 +457,93 @@ func TestIntegrationDeleteSSOSettings(t *testing.T) {
 	})
 }
 
+func TestIntegrationSSOSettingsVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var sqlStore db.DB
+	var ssoSettingsStore *SSOSettingsStore
+
+	setup := func() {
+		sqlStore = db.InitTestDB(t)
+		ssoSettingsStore = ProvideStore(sqlStore)
+	}
+
+	t.Run("records a version for every upsert", func(t *testing.T) {
+		setup()
+
+		for i, clientID := range []string{"client-1", "client-2", "client-3"} {
+			err := ssoSettingsStore.Upsert(context.Background(), &models.SSOSettings{
+				Provider:     "github",
+				Settings:     map[string]any{"client_id": clientID},
+				UpdatedBy:    "admin",
+				RestoredFrom: int64(i),
+			})
+			require.NoError(t, err)
+		}
+		err := ssoSettingsStore.Upsert(context.Background(), &models.SSOSettings{
+			Provider: "okta",
+			Settings: map[string]any{"client_id": "okta-client"},
+		})
+		require.NoError(t, err)
+
+		versions, err := ssoSettingsStore.ListVersions(context.Background(), "github")
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+
+		require.Equal(t, int64(3), versions[0].Version)
+		require.Equal(t, "admin", versions[0].CreatedBy)
+		require.Equal(t, int64(2), versions[0].RestoredFrom)
+		require.EqualValues(t, map[string]any{"client_id": "client-3"}, versions[0].Settings)
+		require.WithinDuration(t, time.Now().UTC(), versions[0].Created, withinDuration)
+		require.Equal(t, int64(1), versions[2].Version)
+
+		version, err := ssoSettingsStore.GetVersion(context.Background(), "github", 2)
+		require.NoError(t, err)
+		require.EqualValues(t, map[string]any{"client_id": "client-2"}, version.Settings)
+
+		versions, err = ssoSettingsStore.ListVersions(context.Background(), "okta")
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, int64(1), versions[0].Version)
+	})
+
+	t.Run("returns not found for a missing version", func(t *testing.T) {
+		setup()
+
+		err := ssoSettingsStore.Upsert(context.Background(), &models.SSOSettings{
+			Provider: "github",
+			Settings: map[string]any{"client_id": "client"},
+		})
+		require.NoError(t, err)
+
+		_, err = ssoSettingsStore.GetVersion(context.Background(), "github", 2)
+		require.ErrorIs(t, err, ssosettings.ErrVersionNotFound)
+
+		_, err = ssoSettingsStore.GetVersion(context.Background(), "okta", 1)
+		require.ErrorIs(t, err, ssosettings.ErrVersionNotFound)
+	})
+
+	t.Run("keeps only the latest versions", func(t *testing.T) {
+		setup()
+
+		for i := 0; i < versionsToKeep+5; i++ {
+			err := ssoSettingsStore.Upsert(context.Background(), &models.SSOSettings{
+				Provider: "github",
+				Settings: map[string]any{"client_id": "client"},
+			})
+			require.NoError(t, err)
+		}
+
+		versions, err := ssoSettingsStore.ListVersions(context.Background(), "github")
+		require.NoError(t, err)
+		require.Len(t, versions, versionsToKeep)
+		require.Equal(t, int64(versionsToKeep+5), versions[0].Version)
+		require.Equal(t, int64(6), versions[len(versions)-1].Version)
+	})
+}
+
 func populateSSOSettings(sqlStore db.DB, template models.SSOSettings, providers ...string) error {
 	return sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
 		for _, provider := range providers {
@@ -10,6 +10,8 @@ var (
 
 	ErrNotConfigurable = errNotFoundBase.Errorf("not configurable")
 
+	ErrVersionNotFound = errutil.NotFound("sso.versionNotFound", errutil.WithPublicMessage("The settings version was not found."))
+
 	ErrBaseInvalidOAuthConfig = errutil.ValidationFailed("sso.invalidOauthConfig")
 
 	ErrInvalidOAuthConfig = func(msg string) error {
@@ -18,6 +20,14 @@ var (
 		return base
 	}
 
+	ErrBaseConnectionTestFailed = errutil.ValidationFailed("sso.connectionTestFailed")
+
+	ErrConnectionTestFailed = func(err error) error {
+		base := ErrBaseConnectionTestFailed.Errorf("connection test failed: %w", err)
+		base.PublicMessage = "Connection test failed: " + err.Error()
+		return base
+	}
+
 	ErrInvalidProvider = errutil.ValidationFailed("sso.invalidProvider", errutil.WithPublicMessage("Provider is invalid"))
 	ErrInvalidSettings = errutil.ValidationFailed("sso.settings", errutil.WithPublicMessage("Settings field is invalid"))
 	ErrEmptyClientId   = errutil.ValidationFailed("sso.emptyClientId", errutil.WithPublicMessage("ClientId cannot be empty"))
@@ -51,6 +51,10 @@ type SSOSettings struct {
 	Updated   time.Time      `xorm:"updated" json:"-"`
 	IsDeleted bool           `xorm:"is_deleted" json:"-"`
 	Source    SettingsSource `xorm:"-" json:"source"`
+
+	// UpdatedBy and RestoredFrom are recorded in the version created when the settings are saved
+	UpdatedBy    string `xorm:"-" json:"-"`
+	RestoredFrom int64  `xorm:"-" json:"-"`
 }
 
 // TableName returns the table name (needed for Xorm)
@@ -97,3 +101,38 @@ func (s *SSOSettings) UnmarshalJSON(data []byte) error {
 	s.Settings = settings
 	return nil
 }
+
+// SSOSettingsVersion is a snapshot of the settings saved for a provider. A new version
+// is recorded every time the settings of a provider are updated.
+type SSOSettingsVersion struct {
+	ID           int64          `xorm:"pk autoincr 'id'" json:"-"`
+	Provider     string         `xorm:"provider" json:"provider"`
+	Version      int64          `xorm:"'version'" json:"version"`
+	Settings     map[string]any `xorm:"settings" json:"settings"`
+	Created      time.Time      `xorm:"created" json:"created"`
+	CreatedBy    string         `xorm:"created_by" json:"createdBy"`
+	RestoredFrom int64          `xorm:"restored_from" json:"restoredFrom,omitempty"`
+}
+
+// TableName returns the table name (needed for Xorm)
+func (v SSOSettingsVersion) TableName() string {
+	return "sso_setting_version"
+}
+
+// MarshalJSON implements the json.Marshaler interface and converts the v.Settings from map[string]any in snake_case to map[string]any in camelCase
+func (v SSOSettingsVersion) MarshalJSON() ([]byte, error) {
+	type Alias SSOSettingsVersion
+	aux := &struct {
+		*Alias
+	}{
+		Alias: (*Alias)(&v),
+	}
+
+	settings := make(map[string]any)
+	for k, val := range aux.Settings {
+		settings[strcase.ToLowerCamel(k)] = val
+	}
+
+	aux.Settings = settings
+	return json.Marshal(aux)
+}
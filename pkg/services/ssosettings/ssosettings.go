@@ -30,6 +30,13 @@ type Service interface {
 	Delete(ctx context.Context, provider string) error
 	// Patch updates the specified SSO settings (key-value pairs) for a given provider
 	Patch(ctx context.Context, provider string, data map[string]any) error
+	// ListVersions returns the saved versions of the SSO settings for a given provider, newest first, with secret values redacted
+	ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error)
+	// Restore saves a previous version of the SSO settings for a given provider as the current settings
+	Restore(ctx context.Context, provider string, version int64, requester identity.Requester) error
+	// TestConnection validates the SSO settings for a given provider and checks that the identity provider
+	// can be reached with them, without saving the settings
+	TestConnection(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) error
 	// RegisterReloadable registers a reloadable for a given provider
 	RegisterReloadable(provider string, reloadable Reloadable)
 	// Reload reloads the settings for a given provider
@@ -44,6 +51,12 @@ type Reloadable interface {
 	Validate(ctx context.Context, settings models.SSOSettings, oldSettings models.SSOSettings, requester identity.Requester) error
 }
 
+// ConnectionTester can be implemented by a Reloadable to check that the identity provider can be reached
+// with the given settings before they are saved
+type ConnectionTester interface {
+	TestConnection(ctx context.Context, settings models.SSOSettings) error
+}
+
 // FallbackStrategy is an interface that can be implemented to allow a provider to load settings from a different source
 // than the database. This is useful for providers that are not configured in the database, but instead are configured
 // using the config file and/or environment variables. Used mostly for backwards compatibility.
@@ -61,6 +74,8 @@ type Store interface {
 	List(ctx context.Context) ([]*models.SSOSettings, error)
 	Upsert(ctx context.Context, settings *models.SSOSettings) error
 	Delete(ctx context.Context, provider string) error
+	ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error)
+	GetVersion(ctx context.Context, provider string, version int64) (*models.SSOSettingsVersion, error)
 }
 
 type ValidateFunc[T any] func(input *T, requester identity.Requester) error
@@ -0,0 +1,84 @@
+package ssosettingsimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+)
+
+const (
+	connectionTestTimeout = 10 * time.Second
+	maxMetadataSize       = 5 * 1024 * 1024
+)
+
+// testSAMLMetadata fetches the identity provider metadata configured in the SAML settings
+// and checks that it contains an entity descriptor.
+func testSAMLMetadata(ctx context.Context, settings models.SSOSettings) error {
+	if metadataURL, ok := settings.Settings["idp_metadata_url"].(string); ok && metadataURL != "" {
+		metadata, err := fetchMetadata(ctx, metadataURL)
+		if err != nil {
+			return err
+		}
+		return checkSAMLMetadata(metadata)
+	}
+
+	if encoded, ok := settings.Settings["idp_metadata"].(string); ok && encoded != "" {
+		metadata, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode the IdP metadata: %w", err)
+		}
+		return checkSAMLMetadata(metadata)
+	}
+
+	return nil
+}
+
+func fetchMetadata(ctx context.Context, metadataURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, connectionTestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IdP metadata URL: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the IdP metadata: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the IdP metadata: unexpected status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize))
+}
+
+func checkSAMLMetadata(metadata []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(metadata))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("the IdP metadata is empty")
+			}
+			return fmt.Errorf("the IdP metadata is not valid XML: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "EntityDescriptor" && start.Name.Local != "EntitiesDescriptor" {
+				return fmt.Errorf("the IdP metadata does not contain an entity descriptor, found %s", start.Name.Local)
+			}
+			return nil
+		}
+	}
+}
@@ -189,50 +189,129 @@ func (s *Service) ListWithRedactedSecrets(ctx context.Context) ([]*models.SSOSet
 }
 
 func (s *Service) Upsert(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) error {
-	if !s.isProviderConfigurable(settings.Provider) {
-		return ssosettings.ErrNotConfigurable
+	reloadable, storedSettings, err := s.validate(ctx, settings, requester)
+	if err != nil {
+		return err
 	}
+	settingsWithSecrets := settings.Settings
 
-	reloadable, ok := s.reloadables[settings.Provider]
-	if !ok {
-		return ssosettings.ErrInvalidProvider.Errorf("provider %s not found in reloadables", settings.Provider)
+	settings.Settings, err = s.encryptSecrets(ctx, settings.Settings)
+	if err != nil {
+		return err
 	}
 
-	storedSettings, err := s.GetForProvider(ctx, settings.Provider)
+	if requester != nil {
+		settings.UpdatedBy = requester.GetLogin()
+	}
+
+	err = s.store.Upsert(ctx, settings)
 	if err != nil {
 		return err
 	}
 
-	settingsWithSecrets, err := mergeSecrets(settings.Settings, storedSettings.Settings)
+	// make a copy of current settings for reload operation and apply overrides
+	reloadSettings := *settings
+	reloadSettings.Settings = overrideMaps(storedSettings.Settings, settingsWithSecrets)
+
+	go s.reload(reloadable, settings.Provider, reloadSettings)
+
+	return nil
+}
+
+func (s *Service) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	if !s.isProviderConfigurable(provider) {
+		return nil, ssosettings.ErrNotConfigurable
+	}
+
+	versions, err := s.store.ListVersions(ctx, provider)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	settings.Settings = settingsWithSecrets
 
-	err = reloadable.Validate(ctx, *settings, *storedSettings, requester)
+	for _, version := range versions {
+		version.Settings = removeSecrets(version.Settings)
+	}
+
+	return versions, nil
+}
+
+func (s *Service) Restore(ctx context.Context, provider string, version int64, requester identity.Requester) error {
+	if !s.isProviderConfigurable(provider) {
+		return ssosettings.ErrNotConfigurable
+	}
+
+	storedVersion, err := s.store.GetVersion(ctx, provider, version)
 	if err != nil {
 		return err
 	}
 
-	settings.Settings, err = s.encryptSecrets(ctx, settings.Settings)
+	// Versions are coming from the database thus secrets are encrypted
+	settings, err := s.decryptSecrets(ctx, storedVersion.Settings)
 	if err != nil {
 		return err
 	}
 
-	err = s.store.Upsert(ctx, settings)
+	// the restored settings go through the same validation as any other update
+	return s.Upsert(ctx, &models.SSOSettings{
+		Provider:     provider,
+		Settings:     settings,
+		RestoredFrom: version,
+	}, requester)
+}
+
+func (s *Service) TestConnection(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) error {
+	reloadable, storedSettings, err := s.validate(ctx, settings, requester)
 	if err != nil {
 		return err
 	}
 
-	// make a copy of current settings for reload operation and apply overrides
-	reloadSettings := *settings
-	reloadSettings.Settings = overrideMaps(storedSettings.Settings, settingsWithSecrets)
+	testSettings := *settings
+	testSettings.Settings = overrideMaps(storedSettings.Settings, settings.Settings)
 
-	go s.reload(reloadable, settings.Provider, reloadSettings)
+	if tester, ok := reloadable.(ssosettings.ConnectionTester); ok {
+		err = tester.TestConnection(ctx, testSettings)
+	} else if settings.Provider == social.SAMLProviderName {
+		err = testSAMLMetadata(ctx, testSettings)
+	}
+
+	if err != nil {
+		s.logger.FromContext(ctx).Warn("SSO settings connection test failed", "provider", settings.Provider, "error", err)
+		return ssosettings.ErrConnectionTestFailed(err)
+	}
 
 	return nil
 }
 
+// validate merges the secrets of the stored settings into the given settings and validates the result
+func (s *Service) validate(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) (ssosettings.Reloadable, *models.SSOSettings, error) {
+	if !s.isProviderConfigurable(settings.Provider) {
+		return nil, nil, ssosettings.ErrNotConfigurable
+	}
+
+	reloadable, ok := s.reloadables[settings.Provider]
+	if !ok {
+		return nil, nil, ssosettings.ErrInvalidProvider.Errorf("provider %s not found in reloadables", settings.Provider)
+	}
+
+	storedSettings, err := s.GetForProvider(ctx, settings.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settingsWithSecrets, err := mergeSecrets(settings.Settings, storedSettings.Settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings.Settings = settingsWithSecrets
+
+	err = reloadable.Validate(ctx, *settings, *storedSettings, requester)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reloadable, storedSettings, nil
+}
+
 func (s *Service) Patch(ctx context.Context, provider string, data map[string]any) error {
 	panic("not implemented") // TODO: Implement
 }
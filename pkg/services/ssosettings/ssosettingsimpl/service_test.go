@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
@@ -1420,6 +1422,283 @@ func TestService_Upsert(t *testing.T) {
 	})
 }
 
+func TestService_ListVersions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns the versions with redacted secrets", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+
+		env.store.ExpectedSSOSettingsVersions = []*models.SSOSettingsVersion{
+			{
+				Provider:  social.GitHubProviderName,
+				Version:   2,
+				CreatedBy: "admin",
+				Settings: map[string]any{
+					"client_id":     "client-id",
+					"client_secret": "encrypted-client-secret",
+				},
+			},
+		}
+
+		versions, err := env.service.ListVersions(context.Background(), social.GitHubProviderName)
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, int64(2), versions[0].Version)
+		require.Equal(t, "admin", versions[0].CreatedBy)
+		require.Equal(t, map[string]any{
+			"client_id":     "client-id",
+			"client_secret": setting.RedactedPassword,
+		}, versions[0].Settings)
+	})
+
+	t.Run("returns error if the provider is not configurable", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+
+		_, err := env.service.ListVersions(context.Background(), social.GrafanaComProviderName)
+		require.ErrorIs(t, err, ssosettings.ErrNotConfigurable)
+	})
+}
+
+func TestService_Restore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successfully restores a previous version", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+
+		provider := social.AzureADProviderName
+		env.store.ExpectedSSOSettingsVersion = &models.SSOSettingsVersion{
+			Provider: provider,
+			Version:  3,
+			Settings: map[string]any{
+				"client_id":     "old-client-id",
+				"client_secret": base64.RawStdEncoding.EncodeToString([]byte("encrypted-old-client-secret")),
+				"enabled":       true,
+			},
+		}
+		env.store.GetFn = func(ctx context.Context, provider string) (*models.SSOSettings, error) {
+			return nil, ssosettings.ErrNotFound
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		restoredSettings := map[string]any{
+			"client_id":     "old-client-id",
+			"client_secret": "old-client-secret",
+			"enabled":       true,
+		}
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.MatchedBy(func(settings models.SSOSettings) bool {
+			return maps.Equal(settings.Settings, restoredSettings)
+		}), mock.Anything, mock.Anything).Return(nil).Once()
+		reloadable.On("Reload", mock.Anything, mock.MatchedBy(func(settings models.SSOSettings) bool {
+			defer wg.Done()
+			return maps.Equal(settings.Settings, restoredSettings)
+		})).Return(nil).Once()
+		env.reloadables[provider] = reloadable
+
+		env.secrets.On("Decrypt", mock.Anything, []byte("encrypted-old-client-secret")).Return([]byte("old-client-secret"), nil).Once()
+		env.secrets.On("Encrypt", mock.Anything, []byte("old-client-secret"), mock.Anything).Return([]byte("encrypted-old-client-secret"), nil).Once()
+
+		err := env.service.Restore(context.Background(), provider, 3, &user.SignedInUser{Login: "admin"})
+		require.NoError(t, err)
+
+		wg.Wait()
+
+		require.Equal(t, provider, env.store.ActualSSOSettings.Provider)
+		require.Equal(t, "admin", env.store.ActualSSOSettings.UpdatedBy)
+		require.Equal(t, int64(3), env.store.ActualSSOSettings.RestoredFrom)
+		require.Equal(t, base64.RawStdEncoding.EncodeToString([]byte("encrypted-old-client-secret")), env.store.ActualSSOSettings.Settings["client_secret"])
+	})
+
+	t.Run("does not restore a version that fails validation", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+
+		provider := social.AzureADProviderName
+		env.store.ExpectedSSOSettingsVersion = &models.SSOSettingsVersion{
+			Provider: provider,
+			Version:  1,
+			Settings: map[string]any{"client_id": ""},
+		}
+		env.store.GetFn = func(ctx context.Context, provider string) (*models.SSOSettings, error) {
+			return nil, ssosettings.ErrNotFound
+		}
+		env.store.UpsertFn = func(ctx context.Context, settings *models.SSOSettings) error {
+			require.Fail(t, "the settings should not be saved")
+			return nil
+		}
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(ssosettings.ErrEmptyClientId.Errorf("empty client id")).Once()
+		env.reloadables[provider] = reloadable
+
+		err := env.service.Restore(context.Background(), provider, 1, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrEmptyClientId)
+	})
+
+	t.Run("returns error if the version was not found", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+		env.store.ExpectedError = ssosettings.ErrVersionNotFound.Errorf("not found")
+
+		err := env.service.Restore(context.Background(), social.AzureADProviderName, 5, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrVersionNotFound)
+	})
+}
+
+type fakeConnectionTester struct {
+	*ssosettingstests.MockReloadable
+	testedSettings models.SSOSettings
+	err            error
+}
+
+func (f *fakeConnectionTester) TestConnection(ctx context.Context, settings models.SSOSettings) error {
+	f.testedSettings = settings
+	return f.err
+}
+
+func TestService_TestConnection(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tests the connection with the stored secrets without saving the settings", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+
+		provider := social.GenericOAuthProviderName
+		env.store.GetFn = func(ctx context.Context, provider string) (*models.SSOSettings, error) {
+			return &models.SSOSettings{
+				Provider: provider,
+				Settings: map[string]any{
+					"client_secret": base64.RawStdEncoding.EncodeToString([]byte("encrypted-client-secret")),
+					"api_url":       "https://idp.example.com/userinfo",
+				},
+			}, nil
+		}
+		env.store.UpsertFn = func(ctx context.Context, settings *models.SSOSettings) error {
+			require.Fail(t, "the settings should not be saved")
+			return nil
+		}
+		env.secrets.On("Decrypt", mock.Anything, []byte("encrypted-client-secret")).Return([]byte("client-secret"), nil).Once()
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		tester := &fakeConnectionTester{MockReloadable: reloadable}
+		env.reloadables[provider] = tester
+
+		err := env.service.TestConnection(context.Background(), &models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"client_id":     "client-id",
+				"client_secret": setting.RedactedPassword,
+				"token_url":     "https://idp.example.com/token",
+			},
+		}, &user.SignedInUser{})
+		require.NoError(t, err)
+
+		require.Equal(t, "client-secret", tester.testedSettings.Settings["client_secret"])
+		require.Equal(t, "https://idp.example.com/token", tester.testedSettings.Settings["token_url"])
+		require.Equal(t, "https://idp.example.com/userinfo", tester.testedSettings.Settings["api_url"])
+	})
+
+	t.Run("returns a validation error if the connection test fails", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+
+		provider := social.GenericOAuthProviderName
+		env.store.ExpectedError = ssosettings.ErrNotFound
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		env.reloadables[provider] = &fakeConnectionTester{MockReloadable: reloadable, err: errors.New("token URL returned status 404")}
+
+		err := env.service.TestConnection(context.Background(), &models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{"client_id": "client-id"},
+		}, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrBaseConnectionTestFailed)
+		require.Contains(t, err.Error(), "token URL returned status 404")
+	})
+
+	t.Run("does not test the connection if the settings are invalid", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false, false)
+
+		provider := social.GenericOAuthProviderName
+		env.store.ExpectedError = ssosettings.ErrNotFound
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(ssosettings.ErrEmptyClientId.Errorf("empty client id")).Once()
+		tester := &fakeConnectionTester{MockReloadable: reloadable}
+		env.reloadables[provider] = tester
+
+		err := env.service.TestConnection(context.Background(), &models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{},
+		}, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrEmptyClientId)
+		require.Nil(t, tester.testedSettings.Settings)
+	})
+
+	t.Run("fetches the SAML metadata", func(t *testing.T) {
+		t.Parallel()
+
+		metadataServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/metadata":
+				_, _ = w.Write([]byte(`<?xml version="1.0"?><md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com"></md:EntityDescriptor>`))
+			case "/login":
+				_, _ = w.Write([]byte(`<html><body>Login</body></html>`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(metadataServer.Close)
+
+		testCases := []struct {
+			desc          string
+			metadataURL   string
+			expectedError string
+		}{
+			{desc: "valid metadata", metadataURL: metadataServer.URL + "/metadata"},
+			{desc: "missing metadata", metadataURL: metadataServer.URL + "/metdata", expectedError: "unexpected status code 404"},
+			{desc: "not a metadata document", metadataURL: metadataServer.URL + "/login", expectedError: "does not contain an entity descriptor"},
+		}
+
+		for _, tc := range testCases {
+			env := setupTestEnv(t, true, false, true, false)
+			env.store.ExpectedError = ssosettings.ErrNotFound
+
+			reloadable := ssosettingstests.NewMockReloadable(t)
+			reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			env.reloadables[social.SAMLProviderName] = reloadable
+
+			err := env.service.TestConnection(context.Background(), &models.SSOSettings{
+				Provider: social.SAMLProviderName,
+				Settings: map[string]any{"idp_metadata_url": tc.metadataURL},
+			}, &user.SignedInUser{})
+			if tc.expectedError == "" {
+				require.NoError(t, err, tc.desc)
+			} else {
+				require.ErrorIs(t, err, ssosettings.ErrBaseConnectionTestFailed, tc.desc)
+				require.Contains(t, err.Error(), tc.expectedError, tc.desc)
+			}
+		}
+	})
+}
+
 func TestService_Delete(t *testing.T) {
 	t.Parallel()
 
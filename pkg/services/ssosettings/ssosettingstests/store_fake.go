@@ -14,6 +14,9 @@ type FakeStore struct {
 	ExpectedSSOSettings []*models.SSOSettings
 	ExpectedError       error
 
+	ExpectedSSOSettingsVersion  *models.SSOSettingsVersion
+	ExpectedSSOSettingsVersions []*models.SSOSettingsVersion
+
 	ActualSSOSettings models.SSOSettings
 
 	GetFn    func(ctx context.Context, provider string) (*models.SSOSettings, error)
@@ -52,3 +55,11 @@ func (f *FakeStore) Patch(ctx context.Context, provider string, data map[string]
 func (f *FakeStore) Delete(ctx context.Context, provider string) error {
 	return f.ExpectedError
 }
+
+func (f *FakeStore) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	return f.ExpectedSSOSettingsVersions, f.ExpectedError
+}
+
+func (f *FakeStore) GetVersion(ctx context.Context, provider string, version int64) (*models.SSOSettingsVersion, error) {
+	return f.ExpectedSSOSettingsVersion, f.ExpectedError
+}
@@ -1,6 +1,7 @@
 package teamapi
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
 	"github.com/grafana/grafana/pkg/services/preference/prefapi"
@@ -45,6 +47,7 @@ func (tapi *TeamAPI) createTeam(c *contextmodel.ReqContext) response.Response {
 	// Clear permission cache for the user who's created the team, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	tapi.ac.ClearUserPermissionCache(c.SignedInUser)
+	auditlog.RecordResourceID(c.Req.Context(), strconv.FormatInt(t.ID, 10))
 
 	// if the request is authenticated using API tokens
 	// the SignedInUser is an empty struct therefore
@@ -56,6 +59,7 @@ func (tapi *TeamAPI) createTeam(c *contextmodel.ReqContext) response.Response {
 			c.Logger.Error("Could not add creator to team", "error", err)
 		}
 	}
+	tapi.recordTeamState(c, t.ID, auditlog.RecordAfter)
 
 	return response.JSON(http.StatusOK, &util.DynMap{
 		"teamId":  t.ID,
@@ -87,12 +91,14 @@ func (tapi *TeamAPI) updateTeam(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
 	}
 
+	tapi.recordTeamState(c, cmd.ID, auditlog.RecordBefore)
 	if err := tapi.teamService.UpdateTeam(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, team.ErrTeamNameTaken) {
 			return response.Error(http.StatusBadRequest, "Team name taken", err)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to update Team", err)
 	}
+	tapi.recordTeamState(c, cmd.ID, auditlog.RecordAfter)
 
 	return response.Success("Team updated")
 }
@@ -114,6 +120,7 @@ func (tapi *TeamAPI) deleteTeamByID(c *contextmodel.ReqContext) response.Respons
 		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
 	}
 
+	tapi.recordTeamState(c, teamID, auditlog.RecordBefore)
 	if err := tapi.teamService.DeleteTeam(c.Req.Context(), &team.DeleteTeamCommand{OrgID: orgID, ID: teamID}); err != nil {
 		if errors.Is(err, team.ErrTeamNotFound) {
 			return response.Error(http.StatusNotFound, "Failed to delete Team. ID not found", nil)
@@ -129,6 +136,36 @@ func (tapi *TeamAPI) deleteTeamByID(c *contextmodel.ReqContext) response.Respons
 	return response.Success("Team deleted")
 }
 
+// teamState is the state of a team recorded in the audit log, members are keyed by login.
+type teamState struct {
+	Name    string            `json:"name"`
+	Email   string            `json:"email"`
+	Members map[string]string `json:"members"`
+}
+
+// recordTeamState records the team and its members as the before or after state of an audited request.
+func (tapi *TeamAPI) recordTeamState(c *contextmodel.ReqContext, teamID int64, record func(context.Context, any)) {
+	ctx := c.Req.Context()
+	if !auditlog.Recording(ctx) {
+		return
+	}
+	orgID := c.SignedInUser.GetOrgID()
+	t, err := tapi.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: orgID, ID: teamID, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return
+	}
+	members, err := tapi.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: orgID, TeamID: teamID, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return
+	}
+
+	state := teamState{Name: t.Name, Email: t.Email, Members: make(map[string]string, len(members))}
+	for _, m := range members {
+		state.Members[m.Login] = m.Permission.String()
+	}
+	record(ctx, state)
+}
+
 // swagger:route GET /teams/search teams searchTeams
 //
 // Team Search With Paging.
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/team"
@@ -91,6 +92,7 @@ func (tapi *TeamAPI) addTeamMember(c *contextmodel.ReqContext) response.Response
 		return response.Error(http.StatusBadRequest, "User is already added to this team", nil)
 	}
 
+	tapi.recordTeamState(c, teamID, auditlog.RecordBefore)
 	err = addOrUpdateTeamMember(
 		c.Req.Context(), tapi.teamPermissionsService,
 		cmd.UserID, c.SignedInUser.GetOrgID(), teamID, team.PermissionTypeMember.String(),
@@ -98,6 +100,7 @@ func (tapi *TeamAPI) addTeamMember(c *contextmodel.ReqContext) response.Response
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to add Member to Team", err)
 	}
+	tapi.recordTeamState(c, teamID, auditlog.RecordAfter)
 
 	return response.JSON(http.StatusOK, &util.DynMap{
 		"message": "Member added to Team",
@@ -137,10 +140,12 @@ func (tapi *TeamAPI) updateTeamMember(c *contextmodel.ReqContext) response.Respo
 		return response.Error(http.StatusNotFound, "Team member not found.", nil)
 	}
 
+	tapi.recordTeamState(c, teamId, auditlog.RecordBefore)
 	err = addOrUpdateTeamMember(c.Req.Context(), tapi.teamPermissionsService, userId, orgId, teamId, cmd.Permission.String())
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update team member.", err)
 	}
+	tapi.recordTeamState(c, teamId, auditlog.RecordAfter)
 	return response.Success("Team member updated")
 }
 
@@ -176,6 +181,7 @@ func (tapi *TeamAPI) setTeamMemberships(c *contextmodel.ReqContext) response.Res
 		return response.Error(http.StatusInternalServerError, "Failed to parse team membership updates", err)
 	}
 
+	tapi.recordTeamState(c, teamId, auditlog.RecordBefore)
 	_, err = tapi.teamPermissionsService.SetPermissions(c.Req.Context(), orgId, strconv.FormatInt(teamId, 10), teamMemberships...)
 	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, team.ErrTeamNotFound) {
@@ -183,6 +189,7 @@ func (tapi *TeamAPI) setTeamMemberships(c *contextmodel.ReqContext) response.Res
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to update team memberships", err)
 	}
+	tapi.recordTeamState(c, teamId, auditlog.RecordAfter)
 
 	return response.Success("Team memberships have been updated")
 }
@@ -276,6 +283,7 @@ func (tapi *TeamAPI) removeTeamMember(c *contextmodel.ReqContext) response.Respo
 	}
 
 	teamIDString := strconv.FormatInt(teamId, 10)
+	tapi.recordTeamState(c, teamId, auditlog.RecordBefore)
 	if _, err := tapi.teamPermissionsService.SetUserPermission(c.Req.Context(), orgId, accesscontrol.User{ID: userId}, teamIDString, ""); err != nil {
 		if errors.Is(err, team.ErrTeamNotFound) {
 			return response.Error(http.StatusNotFound, "Team not found", nil)
@@ -287,6 +295,7 @@ func (tapi *TeamAPI) removeTeamMember(c *contextmodel.ReqContext) response.Respo
 
 		return response.Error(http.StatusInternalServerError, "Failed to remove Member from Team", err)
 	}
+	tapi.recordTeamState(c, teamId, auditlog.RecordAfter)
 	return response.Success("Team Member removed")
 }
 
@@ -137,7 +137,8 @@ type Cfg struct {
 	EnterpriseLicensePath string
 
 	// SMTP email settings
-	Smtp SmtpSettings
+	Smtp               SmtpSettings
+	NotificationOutbox NotificationOutboxSettings
 
 	// Rendering
 	ImagesDir                      string
@@ -152,10 +153,14 @@ type Cfg struct {
 	RendererDefaultImageHeight     int
 	RendererDefaultImageScale      float64
 
+	// Scheduled dashboard reports
+	Reporting ReportingSettings
+
 	// Security
 	DisableInitAdminCreation             bool
 	DisableBruteForceLoginProtection     bool
 	BruteForceLoginProtectionMaxAttempts int64
+	BruteForceLoginProtection            BruteForceLoginProtectionSettings
 	CookieSecure                         bool
 	CookieSameSiteDisabled               bool
 	CookieSameSiteMode                   http.SameSite
@@ -222,6 +227,7 @@ type Cfg struct {
 	MinRefreshInterval          string
 	DefaultHomeDashboardPath    string
 	DashboardPerformanceMetrics []string
+	DashboardLintRules          []DashboardLintRule
 
 	// Auth
 	LoginCookieName               string
@@ -271,6 +277,12 @@ type Cfg struct {
 
 	PasswordlessMagicLinkAuth AuthPasswordlessMagicLinkSettings
 
+	// Multi-factor authentication
+	AuthMFA AuthMFASettings
+
+	// Mutual TLS client certificate auth
+	AuthMTLS AuthMTLSSettings
+
 	// SSO Settings Auth
 	SSOSettingsReloadInterval        time.Duration
 	SSOSettingsConfigurableProviders map[string]bool
@@ -315,6 +327,12 @@ type Cfg struct {
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	// SATokenRotationOverlap is how long a rotated token keeps working next to its successor
+	SATokenRotationOverlap time.Duration
+	// SATokenExpiryNotificationDays is how many days before expiry owners are notified, 0 disables notifications
+	SATokenExpiryNotificationDays int
+	SATokenExpiryCheckInterval    time.Duration
+	SATokenExpiryWebhookURL       string
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -322,6 +340,7 @@ type Cfg struct {
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	AnnotationRetentionRules           []AnnotationRetentionRule
 
 	// GrafanaJavascriptAgent config
 	GrafanaJavascriptAgent GrafanaJavascriptAgent
@@ -390,6 +409,9 @@ type Cfg struct {
 	LDAPAllowSignup       bool
 	LDAPActiveSyncEnabled bool
 	LDAPSyncCron          string
+	// LDAPBackgroundSyncCron schedules the OSS LDAP user sync, it is disabled when empty
+	LDAPBackgroundSyncCron           string
+	LDAPBackgroundSyncDisableMissing bool
 
 	DefaultTheme    string
 	DefaultLanguage string
@@ -463,6 +485,8 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	AuditLog AuditLogSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -773,7 +797,7 @@ func (cfg *Cfg) readAnnotationSettings() error {
 	cfg.DashboardAnnotationCleanupSettings = newAnnotationCleanupSettings(dashboardAnnotation, "max_age")
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
 
-	return nil
+	return cfg.readAnnotationRetentionRules()
 }
 
 func (cfg *Cfg) readExpressionsSettings() {
@@ -1139,6 +1163,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
 	cfg.DashboardPerformanceMetrics = util.SplitString(dashboards.Key("dashboard_performance_metrics").MustString(""))
+	if err := cfg.readDashboardLintRules(); err != nil {
+		return err
+	}
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -1270,9 +1297,13 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readAuthProxySettings()
 	cfg.readSessionConfig()
 	cfg.readPasswordlessMagicLinkSettings()
+	cfg.readMFASettings()
+	cfg.readAuthMTLSSettings()
 	if err := cfg.readSmtpSettings(); err != nil {
 		return err
 	}
+	cfg.readNotificationOutboxSettings()
+	cfg.readReportingSettings()
 	if err := cfg.readAnnotationSettings(); err != nil {
 		return err
 	}
@@ -1290,6 +1321,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.readAuditLogSettings()
 
 	var err error
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
@@ -1379,6 +1411,8 @@ func (cfg *Cfg) readLDAPConfig() {
 	cfg.LDAPSkipOrgRoleSync = ldapSec.Key("skip_org_role_sync").MustBool(false)
 	cfg.LDAPActiveSyncEnabled = ldapSec.Key("active_sync_enabled").MustBool(false)
 	cfg.LDAPAllowSignup = ldapSec.Key("allow_sign_up").MustBool(true)
+	cfg.LDAPBackgroundSyncCron = ldapSec.Key("background_sync_cron").String()
+	cfg.LDAPBackgroundSyncDisableMissing = ldapSec.Key("background_sync_disable_missing").MustBool(false)
 }
 
 func (cfg *Cfg) handleAWSConfig() {
@@ -1532,6 +1566,7 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	if cfg.BruteForceLoginProtectionMaxAttempts <= 0 {
 		cfg.BruteForceLoginProtectionMaxAttempts = 1
 	}
+	cfg.readBruteForceLoginProtectionSettings(security)
 
 	CookieSecure = security.Key("cookie_secure").MustBool(false)
 	cfg.CookieSecure = CookieSecure
@@ -1776,6 +1811,10 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.SATokenRotationOverlap = serviceAccount.Key("token_rotation_overlap").MustDuration(24 * time.Hour)
+	cfg.SATokenExpiryNotificationDays = serviceAccount.Key("token_expiry_notification_days").MustInt(7)
+	cfg.SATokenExpiryCheckInterval = serviceAccount.Key("token_expiry_check_interval").MustDuration(time.Hour)
+	cfg.SATokenExpiryWebhookURL = serviceAccount.Key("token_expiry_webhook_url").MustString("")
 	return nil
 }
 
@@ -0,0 +1,82 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	AnnotationSourceAlert     = "alert"
+	AnnotationSourceAPI       = "api"
+	AnnotationSourceDashboard = "dashboard"
+
+	annotationRetentionSectionPrefix = "annotations.retention."
+)
+
+// AnnotationRetentionRule keeps the annotations it matches for its own max age and count instead of
+// the ones of their type. An annotation matches a rule when it matches all the criteria set on it,
+// and it is governed by the first rule it matches only.
+type AnnotationRetentionRule struct {
+	Name  string
+	OrgID int64
+	// Tags matches the annotations with any of the tags, either "key" or "key:value"
+	Tags          []string
+	DashboardUIDs []string
+	// FolderUIDs matches the annotations of the dashboards stored directly in the folders
+	FolderUIDs []string
+	// Source is one of alert, api or dashboard
+	Source string
+	AnnotationCleanupSettings
+	// ArchivePath is the directory the annotations are written to before they are deleted, if set
+	ArchivePath string
+}
+
+// read annotation retention rules from ini file, in the order they are defined. They look like:
+// [annotations.retention.<name>]
+// tags = deploy
+// max_age = 30d
+func (cfg *Cfg) readAnnotationRetentionRules() error {
+	rules := make([]AnnotationRetentionRule, 0)
+	for _, section := range cfg.Raw.Sections() {
+		if !strings.HasPrefix(section.Name(), annotationRetentionSectionPrefix) {
+			continue
+		}
+
+		rule := AnnotationRetentionRule{
+			Name:          strings.TrimPrefix(section.Name(), annotationRetentionSectionPrefix),
+			OrgID:         section.Key("org_id").MustInt64(0),
+			Tags:          util.SplitString(section.Key("tags").String()),
+			DashboardUIDs: util.SplitString(section.Key("dashboards").String()),
+			FolderUIDs:    util.SplitString(section.Key("folders").String()),
+			Source:        section.Key("source").String(),
+			ArchivePath:   section.Key("archive_path").String(),
+		}
+
+		switch rule.Source {
+		case "", AnnotationSourceAlert, AnnotationSourceAPI, AnnotationSourceDashboard:
+		default:
+			return fmt.Errorf("[%s] invalid source %q, must be one of alert, api or dashboard", section.Name(), rule.Source)
+		}
+		if rule.OrgID == 0 && len(rule.Tags) == 0 && len(rule.DashboardUIDs) == 0 && len(rule.FolderUIDs) == 0 && rule.Source == "" {
+			return fmt.Errorf("[%s] retention rule must match annotations by org_id, tags, dashboards, folders or source", section.Name())
+		}
+
+		if maxAge := section.Key("max_age").String(); maxAge != "" {
+			d, err := gtime.ParseDuration(maxAge)
+			if err != nil {
+				return fmt.Errorf("[%s] invalid max_age: %w", section.Name(), err)
+			}
+			rule.MaxAge = d
+		}
+		rule.MaxCount = section.Key("max_annotations_to_keep").MustInt64(0)
+
+		rules = append(rules, rule)
+	}
+
+	cfg.AnnotationRetentionRules = rules
+	return nil
+}
@@ -0,0 +1,52 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type AuditLogSettings struct {
+	Enabled      bool
+	Sinks        []string
+	Retention    time.Duration
+	ExcludePaths []string
+	MaxBodySize  int64
+
+	// File sink
+	FilePath         string
+	FileMaxSizeShift int
+	FileDailyRotate  bool
+	FileMaxDays      int64
+
+	// Syslog sink
+	SyslogNetwork  string
+	SyslogAddress  string
+	SyslogFacility string
+	SyslogTag      string
+}
+
+func (cfg *Cfg) readAuditLogSettings() {
+	section := cfg.Raw.Section("audit_log")
+
+	s := AuditLogSettings{}
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.Sinks = util.SplitString(valueAsString(section, "sinks", "database"))
+	s.Retention = section.Key("retention").MustDuration(90 * 24 * time.Hour)
+	s.ExcludePaths = util.SplitString(valueAsString(section, "exclude_paths", ""))
+	s.MaxBodySize = section.Key("max_body_size").MustInt64(64 * 1024)
+
+	fileSection := cfg.Raw.Section("audit_log.file")
+	s.FilePath = valueAsString(fileSection, "path", "audit.log")
+	s.FileMaxSizeShift = fileSection.Key("max_size_shift").MustInt(28)
+	s.FileDailyRotate = fileSection.Key("daily_rotate").MustBool(true)
+	s.FileMaxDays = fileSection.Key("max_days").MustInt64(90)
+
+	syslogSection := cfg.Raw.Section("audit_log.syslog")
+	s.SyslogNetwork = valueAsString(syslogSection, "network", "")
+	s.SyslogAddress = valueAsString(syslogSection, "address", "")
+	s.SyslogFacility = valueAsString(syslogSection, "facility", "local7")
+	s.SyslogTag = valueAsString(syslogSection, "tag", "grafana-audit")
+
+	cfg.AuditLog = s
+}
@@ -0,0 +1,58 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// BruteForceLoginProtectionSettings holds the policies applied to failed logins on top of
+// the per user limit configured by brute_force_login_protection_max_attempts.
+type BruteForceLoginProtectionSettings struct {
+	// Window is the period failed attempts are counted over for the user and IP address limits
+	Window time.Duration
+
+	DisableIPAddressProtection bool
+	// IPAddressMaxAttempts limits failed attempts from a single address, 0 disables it
+	IPAddressMaxAttempts int64
+	// SubnetMaxAttempts limits failed attempts from all addresses sharing the same subnet, 0 disables it
+	SubnetMaxAttempts int64
+	SubnetIPv4Prefix  int
+	SubnetIPv6Prefix  int
+
+	// Backoff is the delay required after the first failed attempt of a user, doubled with every
+	// further failed attempt up to BackoffMax. 0 disables it.
+	Backoff    time.Duration
+	BackoffMax time.Duration
+
+	// LockoutThreshold is the number of failed attempts within LockoutWindow that locks the account, 0 disables it
+	LockoutThreshold int64
+	LockoutWindow    time.Duration
+	// LockoutDuration is how long an account stays locked, 0 keeps it locked until an admin unlocks it
+	LockoutDuration time.Duration
+	LockoutNotify   bool
+}
+
+func (cfg *Cfg) readBruteForceLoginProtectionSettings(security *ini.Section) {
+	s := BruteForceLoginProtectionSettings{}
+	s.Window = security.Key("brute_force_login_protection_window").MustDuration(5 * time.Minute)
+	if s.Window <= 0 {
+		s.Window = 5 * time.Minute
+	}
+
+	s.DisableIPAddressProtection = security.Key("disable_ip_address_login_protection").MustBool(true)
+	s.IPAddressMaxAttempts = security.Key("brute_force_login_protection_ip_max_attempts").MustInt64(50)
+	s.SubnetMaxAttempts = security.Key("brute_force_login_protection_subnet_max_attempts").MustInt64(0)
+	s.SubnetIPv4Prefix = security.Key("brute_force_login_protection_subnet_ipv4_prefix").MustInt(24)
+	s.SubnetIPv6Prefix = security.Key("brute_force_login_protection_subnet_ipv6_prefix").MustInt(64)
+
+	s.Backoff = security.Key("brute_force_login_protection_backoff").MustDuration(0)
+	s.BackoffMax = security.Key("brute_force_login_protection_backoff_max").MustDuration(5 * time.Minute)
+
+	s.LockoutThreshold = security.Key("brute_force_login_protection_lockout_threshold").MustInt64(0)
+	s.LockoutWindow = security.Key("brute_force_login_protection_lockout_window").MustDuration(time.Hour)
+	s.LockoutDuration = security.Key("brute_force_login_protection_lockout_duration").MustDuration(30 * time.Minute)
+	s.LockoutNotify = security.Key("brute_force_login_protection_lockout_notify").MustBool(true)
+
+	cfg.BruteForceLoginProtection = s
+}
@@ -0,0 +1,71 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	DashboardLintModeWarn  = "warn"
+	DashboardLintModeBlock = "block"
+
+	dashboardLintSectionPrefix = "dashboards.lint."
+)
+
+// DashboardLintRule configures a lint rule checked when dashboards are saved.
+type DashboardLintRule struct {
+	Name string
+	// Rule is the type of the rule, e.g. required_tags
+	Rule string
+	// Mode is either warn, to only report the findings, or block, to reject the dashboard
+	Mode string
+	// FolderUIDs limits the rule to the dashboards stored directly in the folders, all dashboards if empty.
+	// The dashboards at the root are in the "general" folder.
+	FolderUIDs []string
+	// Params are the other keys of the section, specific to the type of the rule
+	Params map[string]string
+}
+
+// read dashboard lint rules from ini file. They look like:
+// [dashboards.lint.<name>]
+// rule = min_refresh_interval
+// mode = block
+// min_interval = 1m
+func (cfg *Cfg) readDashboardLintRules() error {
+	rules := make([]DashboardLintRule, 0)
+	for _, section := range cfg.Raw.Sections() {
+		if !strings.HasPrefix(section.Name(), dashboardLintSectionPrefix) {
+			continue
+		}
+
+		rule := DashboardLintRule{
+			Name:       strings.TrimPrefix(section.Name(), dashboardLintSectionPrefix),
+			Rule:       section.Key("rule").String(),
+			Mode:       section.Key("mode").MustString(DashboardLintModeWarn),
+			FolderUIDs: util.SplitString(section.Key("folders").String()),
+			Params:     make(map[string]string),
+		}
+
+		if rule.Rule == "" {
+			return fmt.Errorf("[%s] lint rule must set the rule", section.Name())
+		}
+		if rule.Mode != DashboardLintModeWarn && rule.Mode != DashboardLintModeBlock {
+			return fmt.Errorf("[%s] invalid mode %q, must be one of warn or block", section.Name(), rule.Mode)
+		}
+
+		for _, key := range section.Keys() {
+			switch key.Name() {
+			case "rule", "mode", "folders":
+			default:
+				rule.Params[key.Name()] = key.String()
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	cfg.DashboardLintRules = rules
+	return nil
+}
@@ -0,0 +1,35 @@
+package setting
+
+import "time"
+
+type AuthMFASettings struct {
+	Enabled bool
+	// Issuer is the name shown by authenticator apps next to the account
+	Issuer string
+	// AllowBasicAuth lets users with a second factor keep using basic auth, which cannot carry one
+	AllowBasicAuth bool
+	// RequireServerAdmins requires every Grafana server admin to enroll
+	RequireServerAdmins bool
+	RecoveryCodes       int
+
+	WebAuthnEnabled bool
+	// WebAuthnRPID and WebAuthnOrigin default to the host and origin of root_url
+	WebAuthnRPID        string
+	WebAuthnOrigin      string
+	ChallengeExpiration time.Duration
+}
+
+func (cfg *Cfg) readMFASettings() {
+	section := cfg.SectionWithEnvOverrides("auth.mfa")
+	s := AuthMFASettings{}
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.Issuer = section.Key("issuer").MustString("Grafana")
+	s.AllowBasicAuth = section.Key("allow_basic_auth").MustBool(false)
+	s.RequireServerAdmins = section.Key("require_server_admins").MustBool(false)
+	s.RecoveryCodes = section.Key("recovery_codes").MustInt(10)
+	s.WebAuthnEnabled = section.Key("webauthn_enabled").MustBool(true)
+	s.WebAuthnRPID = section.Key("webauthn_rp_id").MustString("")
+	s.WebAuthnOrigin = section.Key("webauthn_origin").MustString("")
+	s.ChallengeExpiration = section.Key("challenge_expiration").MustDuration(5 * time.Minute)
+	cfg.AuthMFA = s
+}
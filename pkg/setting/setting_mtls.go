@@ -0,0 +1,26 @@
+package setting
+
+type AuthMTLSSettings struct {
+	Enabled bool
+	// CACertPath is the PEM bundle of the certificate authorities client certificates have to chain to
+	CACertPath string
+	// MappingFile holds the rules mapping certificates to users and service accounts
+	MappingFile string
+	AutoSignUp  bool
+	// HeaderName carries the URL encoded client certificate when TLS is terminated by a proxy
+	HeaderName string
+	// HeaderTrustedProxies lists the addresses allowed to send HeaderName
+	HeaderTrustedProxies string
+}
+
+func (cfg *Cfg) readAuthMTLSSettings() {
+	section := cfg.SectionWithEnvOverrides("auth.mtls")
+	s := AuthMTLSSettings{}
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.CACertPath = section.Key("ca_cert_path").MustString("")
+	s.MappingFile = section.Key("mapping_file").MustString("")
+	s.AutoSignUp = section.Key("auto_sign_up").MustBool(false)
+	s.HeaderName = section.Key("header_name").MustString("")
+	s.HeaderTrustedProxies = section.Key("header_trusted_proxies").MustString("")
+	cfg.AuthMTLS = s
+}
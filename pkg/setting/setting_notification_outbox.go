@@ -0,0 +1,42 @@
+package setting
+
+import (
+	"time"
+)
+
+// NotificationOutboxSettings configures the delivery of queued emails and webhooks.
+type NotificationOutboxSettings struct {
+	// MaxAttempts is the number of delivery attempts before a message is marked as failed
+	MaxAttempts int64
+	// InitialBackoff is the delay before the first retry, doubled after every failed attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// PollInterval is how often the outbox is checked for messages due for delivery
+	PollInterval time.Duration
+	// SentRetention and FailedRetention are how long delivered and failed messages are kept
+	SentRetention   time.Duration
+	FailedRetention time.Duration
+}
+
+func (cfg *Cfg) readNotificationOutboxSettings() {
+	sec := cfg.Raw.Section("notification_outbox")
+
+	s := NotificationOutboxSettings{}
+	s.MaxAttempts = sec.Key("max_attempts").MustInt64(10)
+	if s.MaxAttempts <= 0 {
+		s.MaxAttempts = 1
+	}
+	s.InitialBackoff = sec.Key("initial_backoff").MustDuration(30 * time.Second)
+	s.MaxBackoff = sec.Key("max_backoff").MustDuration(time.Hour)
+	if s.MaxBackoff < s.InitialBackoff {
+		s.MaxBackoff = s.InitialBackoff
+	}
+	s.PollInterval = sec.Key("poll_interval").MustDuration(10 * time.Second)
+	if s.PollInterval <= 0 {
+		s.PollInterval = 10 * time.Second
+	}
+	s.SentRetention = sec.Key("sent_retention").MustDuration(7 * 24 * time.Hour)
+	s.FailedRetention = sec.Key("failed_retention").MustDuration(30 * 24 * time.Hour)
+
+	cfg.NotificationOutbox = s
+}
@@ -6,10 +6,20 @@ type OrgQuota struct {
 	Dashboard  int64 `target:"dashboard"`
 	ApiKey     int64 `target:"api_key"`
 	AlertRule  int64 `target:"alert_rule"`
+	// QueryPerMinute and ConcurrentQuery limit the data source queries of the organization
+	QueryPerMinute  int64 `target:"query_per_minute"`
+	ConcurrentQuery int64 `target:"concurrent_query"`
 }
 
 type UserQuota struct {
-	Org int64 `target:"org_user"`
+	Org            int64 `target:"org_user"`
+	QueryPerMinute int64 `target:"query_per_minute"`
+}
+
+// FolderQuota limits apply to each folder of an organization.
+type FolderQuota struct {
+	Dashboard int64 `target:"dashboard"`
+	AlertRule int64 `target:"alert_rule"`
 }
 
 type GlobalQuota struct {
@@ -28,6 +38,7 @@ type QuotaSettings struct {
 	Enabled bool
 	Org     OrgQuota
 	User    UserQuota
+	Folder  FolderQuota
 	Global  GlobalQuota
 }
 
@@ -43,11 +54,21 @@ func (cfg *Cfg) readQuotaSettings() {
 		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
 		ApiKey:     quota.Key("org_api_key").MustInt64(10),
 		AlertRule:  quota.Key("org_alert_rule").MustInt64(100),
+
+		QueryPerMinute:  quota.Key("org_query_per_minute").MustInt64(-1),
+		ConcurrentQuery: quota.Key("org_concurrent_query").MustInt64(-1),
 	}
 
 	// per User limits
 	cfg.Quota.User = UserQuota{
-		Org: quota.Key("user_org").MustInt64(10),
+		Org:            quota.Key("user_org").MustInt64(10),
+		QueryPerMinute: quota.Key("user_query_per_minute").MustInt64(-1),
+	}
+
+	// per Folder limits
+	cfg.Quota.Folder = FolderQuota{
+		Dashboard: quota.Key("folder_dashboard").MustInt64(-1),
+		AlertRule: quota.Key("folder_alert_rule").MustInt64(-1),
 	}
 
 	// Global Limits
@@ -0,0 +1,34 @@
+package setting
+
+import (
+	"time"
+)
+
+// ReportingSettings configures the scheduled dashboard reports delivered by email.
+type ReportingSettings struct {
+	Enabled bool
+	// RenderTimeout is the timeout of the rendering of each panel of a report
+	RenderTimeout time.Duration
+	// PollInterval is how often the reports are checked for the ones due to be sent
+	PollInterval time.Duration
+	// DeliveryRetention is how long the delivery history of the reports is kept
+	DeliveryRetention time.Duration
+}
+
+func (cfg *Cfg) readReportingSettings() {
+	sec := cfg.Raw.Section("reporting")
+
+	s := ReportingSettings{}
+	s.Enabled = sec.Key("enabled").MustBool(false)
+	s.RenderTimeout = sec.Key("render_timeout").MustDuration(time.Minute)
+	if s.RenderTimeout <= 0 {
+		s.RenderTimeout = time.Minute
+	}
+	s.PollInterval = sec.Key("poll_interval").MustDuration(time.Minute)
+	if s.PollInterval <= 0 {
+		s.PollInterval = time.Minute
+	}
+	s.DeliveryRetention = sec.Key("delivery_retention").MustDuration(90 * 24 * time.Hour)
+
+	cfg.Reporting = s
+}
@@ -687,3 +687,95 @@ func TestDynamicSection(t *testing.T) {
 		assert.Equal(t, value, ds.section.Key(key).String())
 	})
 }
+
+func TestAnnotationRetentionRules(t *testing.T) {
+	t.Run("rules are read in the order they are defined", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[annotations.retention.incidents]
+tags = severity:critical incident
+
+[annotations.retention.deploys]
+org_id = 2
+tags = deploy
+source = api
+max_age = 30d
+max_annotations_to_keep = 1000
+archive_path = /var/lib/grafana/archive
+
+[annotations.retention.ops]
+dashboards = abc, def
+folders = ops
+`))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.Raw = f
+
+		require.NoError(t, cfg.readAnnotationRetentionRules())
+		assert.Equal(t, []AnnotationRetentionRule{
+			{Name: "incidents", Tags: []string{"severity:critical", "incident"}, DashboardUIDs: []string{}, FolderUIDs: []string{}},
+			{
+				Name:                      "deploys",
+				OrgID:                     2,
+				Tags:                      []string{"deploy"},
+				DashboardUIDs:             []string{},
+				FolderUIDs:                []string{},
+				Source:                    AnnotationSourceAPI,
+				AnnotationCleanupSettings: AnnotationCleanupSettings{MaxAge: 30 * 24 * time.Hour, MaxCount: 1000},
+				ArchivePath:               "/var/lib/grafana/archive",
+			},
+			{Name: "ops", Tags: []string{}, DashboardUIDs: []string{"abc", "def"}, FolderUIDs: []string{"ops"}},
+		}, cfg.AnnotationRetentionRules)
+	})
+
+	t.Run("invalid rules fail", func(t *testing.T) {
+		for _, rule := range []string{
+			"[annotations.retention.all]\nmax_age = 1d",
+			"[annotations.retention.users]\nsource = user",
+			"[annotations.retention.deploys]\ntags = deploy\nmax_age = forever",
+		} {
+			f, err := ini.Load([]byte(rule))
+			require.NoError(t, err)
+			cfg := NewCfg()
+			cfg.Raw = f
+			assert.Error(t, cfg.readAnnotationRetentionRules(), rule)
+		}
+	})
+}
+
+func TestDashboardLintRules(t *testing.T) {
+	t.Run("rules are read with their params", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[dashboards.lint.refresh]
+rule = min_refresh_interval
+mode = block
+min_interval = 1m
+
+[dashboards.lint.tags]
+rule = required_tags
+folders = ops, general
+tags = team
+`))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.Raw = f
+
+		require.NoError(t, cfg.readDashboardLintRules())
+		assert.Equal(t, []DashboardLintRule{
+			{Name: "refresh", Rule: "min_refresh_interval", Mode: DashboardLintModeBlock, FolderUIDs: []string{}, Params: map[string]string{"min_interval": "1m"}},
+			{Name: "tags", Rule: "required_tags", Mode: DashboardLintModeWarn, FolderUIDs: []string{"ops", "general"}, Params: map[string]string{"tags": "team"}},
+		}, cfg.DashboardLintRules)
+	})
+
+	t.Run("invalid rules fail", func(t *testing.T) {
+		for _, rule := range []string{
+			"[dashboards.lint.tags]\ntags = team",
+			"[dashboards.lint.tags]\nrule = required_tags\nmode = error",
+		} {
+			f, err := ini.Load([]byte(rule))
+			require.NoError(t, err)
+			cfg := NewCfg()
+			cfg.Raw = f
+			assert.Error(t, cfg.readDashboardLintRules(), rule)
+		}
+	})
+}
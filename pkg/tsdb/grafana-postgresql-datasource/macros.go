@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqlmacros"
 )
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
@@ -89,29 +90,44 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *
 		return fmt.Sprintf("'%s'", timeRange.From.UTC().Format(time.RFC3339Nano)), nil
 	case "__timeTo":
 		return fmt.Sprintf("'%s'", timeRange.To.UTC().Format(time.RFC3339Nano)), nil
-	case "__timeGroup":
-		if len(args) < 2 {
-			return "", fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
+	case "__timeFilterShifted":
+		if len(args) != 2 {
+			return "", fmt.Errorf("macro %v needs time column and time shift", name)
 		}
-		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'`))
+		shifted, err := sqlmacros.ShiftTimeRange(timeRange, args[1])
 		if err != nil {
-			return "", fmt.Errorf("error parsing interval %v", args[1])
+			return "", err
 		}
-		if len(args) == 3 {
-			err := sqleng.SetupFillmode(query, interval, args[2])
+		return m.evaluateMacro(shifted, query, "__timeFilter", args[:1])
+	case "__timeGroup":
+		tg, err := sqlmacros.ParseTimeGroup(name, args)
+		if err != nil {
+			return "", err
+		}
+		if tg.Fill != "" {
+			err := sqleng.SetupFillmode(query, tg.Interval, tg.Fill)
 			if err != nil {
 				return "", err
 			}
 		}
 
+		if tg.HasTimeZone() {
+			// Buckets are computed on the local wall clock time and converted back to an
+			// epoch, so that e.g. daily buckets start at local midnight across DST changes.
+			return fmt.Sprintf(
+				"extract(epoch from (to_timestamp(floor(extract(epoch from (to_timestamp(extract(epoch from %s)) AT TIME ZONE '%s'))/%v)*%v) AT TIME ZONE 'UTC' AT TIME ZONE '%s'))",
+				tg.Column, tg.TimeZone, tg.Interval.Seconds(), tg.Interval.Seconds(), tg.TimeZone,
+			), nil
+		}
+
 		if m.timescaledb {
-			return fmt.Sprintf("time_bucket('%.3fs',%s)", interval.Seconds(), args[0]), nil
+			return fmt.Sprintf("time_bucket('%.3fs',%s)", tg.Interval.Seconds(), tg.Column), nil
 		}
 
 		return fmt.Sprintf(
-			"floor(extract(epoch from %s)/%v)*%v", args[0],
-			interval.Seconds(),
-			interval.Seconds(),
+			"floor(extract(epoch from %s)/%v)*%v", tg.Column,
+			tg.Interval.Seconds(),
+			tg.Interval.Seconds(),
 		), nil
 	case "__timeGroupAlias":
 		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
@@ -154,6 +170,14 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *
 			return tg + " AS \"time\"", nil
 		}
 		return "", err
+	case "__quoteList":
+		return sqlmacros.QuoteList(args, sqlmacros.EscapeStringLiteral), nil
+	case "__rangeBucket":
+		b, err := sqlmacros.ParseRangeBucket(name, args)
+		if err != nil {
+			return "", err
+		}
+		return b.SQL(), nil
 	default:
 		return "", fmt.Errorf("unknown macro %q", name)
 	}
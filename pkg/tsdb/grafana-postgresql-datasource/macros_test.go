@@ -78,6 +78,39 @@ func TestMacroEngine(t *testing.T) {
 			require.Equal(t, sql2, sql+" AS \"time\"")
 		})
 
+		t.Run("interpolate __timeGroup function with time zone", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "SELECT $__timeGroup(time_column, 1d, 'Europe/Berlin')")
+			require.NoError(t, err)
+
+			require.Equal(t, "SELECT extract(epoch from (to_timestamp(floor(extract(epoch from (to_timestamp(extract(epoch from time_column)) AT TIME ZONE 'Europe/Berlin'))/86400)*86400) AT TIME ZONE 'UTC' AT TIME ZONE 'Europe/Berlin'))", sql)
+		})
+
+		t.Run("interpolate __timeGroup function with invalid time zone", func(t *testing.T) {
+			_, err := engine.Interpolate(query, timeRange, "SELECT $__timeGroup(time_column, 1d, 'Europe/Berlin'';--')")
+			require.Error(t, err)
+		})
+
+		t.Run("interpolate __timeFilterShifted function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilterShifted(time_column, 1w)")
+			require.NoError(t, err)
+
+			require.Equal(t, "WHERE time_column BETWEEN '2018-04-05T18:00:00Z' AND '2018-04-05T18:05:00Z'", sql)
+		})
+
+		t.Run("interpolate __quoteList function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "WHERE host IN ($__quoteList(a, b'c))")
+			require.NoError(t, err)
+
+			require.Equal(t, "WHERE host IN ('a','b''c')", sql)
+		})
+
+		t.Run("interpolate __rangeBucket function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "SELECT $__rangeBucket(latency, 0, 100, 4)")
+			require.NoError(t, err)
+
+			require.Equal(t, "SELECT CASE WHEN latency < 0 THEN 0 WHEN latency >= 100 THEN 75 ELSE 0 + FLOOR((latency - 0) / 25) * 25 END", sql)
+		})
+
 		t.Run("interpolate __timeGroup function with spaces between args", func(t *testing.T) {
 			sql, err := engine.Interpolate(query, timeRange, "$__timeGroup(time_column , '5m')")
 			require.NoError(t, err)
@@ -224,6 +224,12 @@ func (s *Service) generateConnectionString(dsInfo sqleng.DataSourceInfo) (string
 
 	connStr += fmt.Sprintf(" sslmode='%s'", escape(tlsSettings.Mode))
 
+	// statement_timeout is sent as a run-time parameter, so the server aborts
+	// long-running queries on its own even if the client goes away.
+	if dsInfo.JsonData.QueryTimeout > 0 {
+		connStr += fmt.Sprintf(" statement_timeout=%d", dsInfo.JsonData.QueryTimeout*1000)
+	}
+
 	// there is an issue with the lib/pq module, the `verify-ca` tls mode
 	// does not work correctly. ( see https://github.com/lib/pq/issues/1106 )
 	// to workaround the problem, if the `verify-ca` mode is chosen,
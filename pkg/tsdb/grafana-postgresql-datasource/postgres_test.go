@@ -29,15 +29,16 @@ func TestIntegrationGenerateConnectionString(t *testing.T) {
 	cfg.DataPath = t.TempDir()
 
 	testCases := []struct {
-		desc        string
-		host        string
-		user        string
-		password    string
-		database    string
-		tlsSettings tlsSettings
-		expConnStr  string
-		expErr      string
-		uid         string
+		desc         string
+		host         string
+		user         string
+		password     string
+		database     string
+		tlsSettings  tlsSettings
+		queryTimeout int
+		expConnStr   string
+		expErr       string
+		uid          string
 	}{
 		{
 			desc:        "Unix socket host",
@@ -119,6 +120,16 @@ func TestIntegrationGenerateConnectionString(t *testing.T) {
 			tlsSettings: tlsSettings{Mode: "verify-full"},
 			expConnStr:  `user='u\'\\ser' password='password' host='host' dbname='d\'\\atabase' sslmode='verify-full'`,
 		},
+		{
+			desc:         "Query timeout",
+			host:         "host",
+			user:         "user",
+			password:     "password",
+			database:     "database",
+			tlsSettings:  tlsSettings{Mode: "verify-full"},
+			queryTimeout: 30,
+			expConnStr:   "user='user' password='password' host='host' dbname='database' sslmode='verify-full' statement_timeout=30000",
+		},
 		{
 			desc:        "Custom TLS mode disabled",
 			host:        "host",
@@ -157,6 +168,7 @@ func TestIntegrationGenerateConnectionString(t *testing.T) {
 				DecryptedSecureJSONData: map[string]string{"password": tt.password},
 				Database:                tt.database,
 				UID:                     tt.uid,
+				JsonData:                sqleng.JsonData{QueryTimeout: tt.queryTimeout},
 			}
 
 			connStr, err := svc.generateConnectionString(ds)
@@ -19,6 +19,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqlframe"
 )
 
 // MetaKeyExecutedQueryString is the key where the executed query should get stored
@@ -58,6 +60,9 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	RowLimit                int64  `json:"rowLimit"`
+	ByteLimit               int64  `json:"byteLimit"`
+	QueryTimeout            int    `json:"queryTimeout"`
 }
 
 type DataSourceInfo struct {
@@ -87,6 +92,7 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	byteLimit              int64
 	userError              string
 }
 
@@ -122,9 +128,15 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		byteLimit:              config.DSInfo.JsonData.ByteLimit,
 		userError:              userFacingDefaultError,
 	}
 
+	// A data source can only tighten the instance wide row limit, never raise it.
+	if dsRowLimit := config.DSInfo.JsonData.RowLimit; dsRowLimit > 0 && (queryDataHandler.rowLimit <= 0 || dsRowLimit < queryDataHandler.rowLimit) {
+		queryDataHandler.rowLimit = dsRowLimit
+	}
+
 	if len(config.TimeColumnNames) > 0 {
 		queryDataHandler.timeColumnNames = config.TimeColumnNames
 	}
@@ -254,6 +266,8 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	// lib/pq sends a cancel request to the server when queryContext is done, so the
+	// query is also stopped in the database when the originating request is cancelled.
 	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream)
@@ -273,7 +287,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := sqlframe.FromRows(rows, e.rowLimit, e.byteLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery, backend.ErrorSourcePlugin)
 		return
@@ -437,3 +437,20 @@ func (t *testQueryResultTransformer) TransformQueryError(_ log.Logger, err error
 func (t *testQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	return nil
 }
+
+func TestNewQueryDataHandlerRowLimit(t *testing.T) {
+	newHandler := func(t *testing.T, instanceLimit int64, dsLimit int64) *DataSourceHandler {
+		t.Helper()
+		config := DataPluginConfiguration{
+			DSInfo:   DataSourceInfo{JsonData: JsonData{RowLimit: dsLimit}},
+			RowLimit: instanceLimit,
+		}
+		handler, err := NewQueryDataHandler("", nil, config, nil, nil, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+		return handler
+	}
+
+	require.Equal(t, int64(1000), newHandler(t, 1000, 0).rowLimit)
+	require.Equal(t, int64(10), newHandler(t, 1000, 10).rowLimit)
+	require.Equal(t, int64(1000), newHandler(t, 1000, 5000).rowLimit)
+}
@@ -1,6 +1,8 @@
 package pyroscope
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,6 +10,7 @@ import (
 	"net/url"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -35,6 +38,8 @@ type ProfilingClient interface {
 	GetSeries(ctx context.Context, profileTypeID string, labelSelector string, start int64, end int64, groupBy []string, limit *int64, step float64) (*SeriesResponse, error)
 	GetProfile(ctx context.Context, profileTypeID string, labelSelector string, start int64, end int64, maxNodes *int64) (*ProfileResponse, error)
 	GetSpanProfile(ctx context.Context, profileTypeID string, labelSelector string, spanSelector []string, start int64, end int64, maxNodes *int64) (*ProfileResponse, error)
+	GetDiffProfile(ctx context.Context, profileTypeID string, baseline, comparison ProfileSelection, maxNodes *int64) (*DiffProfileResponse, error)
+	GetPprof(ctx context.Context, profileTypeID string, labelSelector string, start int64, end int64, maxNodes *int64) ([]byte, error)
 }
 
 // PyroscopeDatasource is a datasource for querying application performance profiles.
@@ -79,6 +84,9 @@ func (d *PyroscopeDatasource) CallResource(ctx context.Context, req *backend.Cal
 	if req.Path == "labelValues" {
 		return d.labelValues(ctx, req, sender)
 	}
+	if req.Path == "export" {
+		return d.export(ctx, req, sender)
+	}
 	return sender.Send(&backend.CallResourceResponse{
 		Status: 404,
 	})
@@ -216,6 +224,87 @@ func (d *PyroscopeDatasource) labelValues(ctx context.Context, req *backend.Call
 	return nil
 }
 
+// export returns the merged profile matching the query as a gzip compressed pprof file, so it can be
+// downloaded and analysed with `go tool pprof`.
+func (d *PyroscopeDatasource) export(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	ctxLogger := logger.FromContext(ctx)
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		ctxLogger.Error("Failed to parse URL", "error", err, "function", logEntrypoint())
+		return err
+	}
+	query := u.Query()
+
+	profileTypeID := query.Get("profileTypeId")
+	if profileTypeID == "" {
+		return sendBadRequest(sender, "profileTypeId is required")
+	}
+	start, err := strconv.ParseInt(query.Get("start"), 10, 64)
+	if err != nil {
+		return sendBadRequest(sender, "start must be a unix timestamp in milliseconds")
+	}
+	end, err := strconv.ParseInt(query.Get("end"), 10, 64)
+	if err != nil {
+		return sendBadRequest(sender, "end must be a unix timestamp in milliseconds")
+	}
+	if end < start {
+		return sendBadRequest(sender, "end must not be before start")
+	}
+	var maxNodes *int64
+	if query.Has("maxNodes") {
+		v, err := strconv.ParseInt(query.Get("maxNodes"), 10, 64)
+		if err != nil || v <= 0 {
+			return sendBadRequest(sender, "maxNodes must be a positive integer")
+		}
+		maxNodes = &v
+	}
+	labelSelector := query.Get("query")
+	if labelSelector == "" {
+		labelSelector = "{}"
+	}
+
+	profile, err := d.client.GetPprof(ctx, profileTypeID, labelSelector, start, end, maxNodes)
+	if err != nil {
+		ctxLogger.Error("Received error from client", "error", err, "function", logEntrypoint())
+		return fmt.Errorf("error calling SelectMergeProfile: %v", err)
+	}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(profile); err != nil {
+		ctxLogger.Error("Failed to compress profile", "error", err, "function", logEntrypoint())
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		ctxLogger.Error("Failed to compress profile", "error", err, "function", logEntrypoint())
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s_%d_%d.pb.gz", strings.ReplaceAll(profileTypeID, ":", "_"), start, end)
+	err = sender.Send(&backend.CallResourceResponse{
+		Body: buf.Bytes(),
+		Headers: map[string][]string{
+			"Content-Type":        {"application/octet-stream"},
+			"Content-Disposition": {fmt.Sprintf("attachment; filename=%q", fileName)},
+		},
+		Status: http.StatusOK,
+	})
+	if err != nil {
+		ctxLogger.Error("Failed to send response", "error", err, "function", logEntrypoint())
+		return err
+	}
+	return nil
+}
+
+// sendBadRequest responds with a 400 and a JSON body carrying the message.
+func sendBadRequest(sender backend.CallResourceResponseSender, message string) error {
+	body, err := json.Marshal(map[string]string{"message": message})
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{Status: http.StatusBadRequest, Body: body})
+}
+
 // QueryData handles multiple queries and returns multiple responses.
 // req contains the queries []DataQuery (where each query contains RefID as a unique identifier).
 // The QueryDataResponse contains a map of RefID to the response for each query, and each response
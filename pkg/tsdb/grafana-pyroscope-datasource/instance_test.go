@@ -1,7 +1,10 @@
 package pyroscope
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"io"
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -52,6 +55,59 @@ func Test_CallResource(t *testing.T) {
 		require.Equal(t, 200, sender.Resp.Status)
 		require.Equal(t, `[{"id":"type:1","label":"cpu"},{"id":"type:2","label":"memory"}]`, string(sender.Resp.Body))
 	})
+
+	t.Run("export resource", func(t *testing.T) {
+		sender := &FakeSender{}
+		err := ds.CallResource(
+			context.Background(),
+			&backend.CallResourceRequest{
+				PluginContext: backend.PluginContext{},
+				Path:          "export",
+				Method:        "GET",
+				URL:           "export?profileTypeId=process_cpu:cpu:nanoseconds:cpu:nanoseconds&query=%7Bapp%3D%22foo%22%7D&start=1000&end=2000",
+			},
+			sender,
+		)
+		require.NoError(t, err)
+		require.Equal(t, 200, sender.Resp.Status)
+		require.Equal(t, []string{`attachment; filename="process_cpu_cpu_nanoseconds_cpu_nanoseconds_1000_2000.pb.gz"`}, sender.Resp.Headers["Content-Disposition"])
+
+		gr, err := gzip.NewReader(bytes.NewReader(sender.Resp.Body))
+		require.NoError(t, err)
+		body, err := io.ReadAll(gr)
+		require.NoError(t, err)
+		require.Equal(t, "pprof", string(body))
+	})
+
+	t.Run("export resource requires a profile type", func(t *testing.T) {
+		sender := &FakeSender{}
+		err := ds.CallResource(context.Background(), &backend.CallResourceRequest{Path: "export", Method: "GET", URL: "export"}, sender)
+		require.NoError(t, err)
+		require.Equal(t, 400, sender.Resp.Status)
+	})
+
+	t.Run("export resource validates the query parameters", func(t *testing.T) {
+		for _, tc := range []struct {
+			query   string
+			message string
+		}{
+			{query: "start=foo&end=2000", message: "start must be a unix timestamp in milliseconds"},
+			{query: "start=1000", message: "end must be a unix timestamp in milliseconds"},
+			{query: "start=2000&end=1000", message: "end must not be before start"},
+			{query: "start=1000&end=2000&maxNodes=foo", message: "maxNodes must be a positive integer"},
+			{query: "start=1000&end=2000&maxNodes=0", message: "maxNodes must be a positive integer"},
+		} {
+			sender := &FakeSender{}
+			err := ds.CallResource(context.Background(), &backend.CallResourceRequest{
+				Path:   "export",
+				Method: "GET",
+				URL:    "export?profileTypeId=process_cpu:cpu:nanoseconds:cpu:nanoseconds&" + tc.query,
+			}, sender)
+			require.NoError(t, err)
+			require.Equal(t, 400, sender.Resp.Status, tc.query)
+			require.JSONEq(t, `{"message":"`+tc.message+`"}`, string(sender.Resp.Body), tc.query)
+		}
+	})
 }
 
 type FakeSender struct {
@@ -16,6 +16,7 @@ type PyroscopeQueryType string
 const (
 	PyroscopeQueryTypeMetrics PyroscopeQueryType = "metrics"
 	PyroscopeQueryTypeProfile PyroscopeQueryType = "profile"
+	PyroscopeQueryTypeDiff    PyroscopeQueryType = "diff"
 	PyroscopeQueryTypeBoth    PyroscopeQueryType = "both"
 )
 
@@ -32,6 +33,14 @@ type GrafanaPyroscopeDataQuery struct {
 	Limit *int64 `json:"limit,omitempty"`
 	// Sets the maximum number of nodes in the flamegraph.
 	MaxNodes *int64 `json:"maxNodes,omitempty"`
+	// Specifies the label selectors of the baseline profile in diff queries. Defaults to labelSelector.
+	BaselineLabelSelector *string `json:"baselineLabelSelector,omitempty"`
+	// Shifts the time range of the baseline profile in diff queries back by the given duration, e.g. 1d.
+	BaselineTimeShift *string `json:"baselineTimeShift,omitempty"`
+	// Start of the baseline time range in diff queries, in milliseconds since epoch. Takes precedence over baselineTimeShift.
+	BaselineFrom *int64 `json:"baselineFrom,omitempty"`
+	// End of the baseline time range in diff queries, in milliseconds since epoch. Takes precedence over baselineTimeShift.
+	BaselineTo *int64 `json:"baselineTo,omitempty"`
 	// A unique identifier for the query within the list of targets.
 	// In server side expressions, the refId is used as a variable name to identify results.
 	// By default, the UI will assign A->Z; however setting meaningful names may be useful.
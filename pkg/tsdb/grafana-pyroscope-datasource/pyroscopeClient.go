@@ -15,6 +15,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 )
 
 type ProfileType struct {
@@ -54,6 +55,24 @@ type ProfileResponse struct {
 	Units       string
 }
 
+// DiffProfileResponse holds a diff flame graph. Every bar in the levels is encoded by 7 numbers:
+// baseline offset, total and self, comparison offset, total and self, and the index into names.
+type DiffProfileResponse struct {
+	Flamebearer *Flamebearer
+	Units       string
+	// BaselineTotal and ComparisonTotal are the total number of ticks of each side.
+	BaselineTotal   int64
+	ComparisonTotal int64
+}
+
+// ProfileSelection identifies a profile merged from all samples matching a selector in a time range.
+type ProfileSelection struct {
+	LabelSelector string
+	// Milliseconds unix timestamps
+	Start int64
+	End   int64
+}
+
 type SeriesResponse struct {
 	Series []*Series
 	Units  string
@@ -211,6 +230,88 @@ func (c *PyroscopeClient) GetSpanProfile(ctx context.Context, profileTypeID, lab
 	return profileQuery(ctx, err, span, resp.Msg.Flamegraph, profileTypeID)
 }
 
+func (c *PyroscopeClient) GetDiffProfile(ctx context.Context, profileTypeID string, baseline, comparison ProfileSelection, maxNodes *int64) (*DiffProfileResponse, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.pyroscope.GetDiffProfile", trace.WithAttributes(attribute.String("profileTypeID", profileTypeID), attribute.String("baselineLabelSelector", baseline.LabelSelector), attribute.String("comparisonLabelSelector", comparison.LabelSelector)))
+	defer span.End()
+	req := connect.NewRequest(&querierv1.DiffRequest{
+		Left: &querierv1.SelectMergeStacktracesRequest{
+			ProfileTypeID: profileTypeID,
+			LabelSelector: baseline.LabelSelector,
+			Start:         baseline.Start,
+			End:           baseline.End,
+			MaxNodes:      maxNodes,
+		},
+		Right: &querierv1.SelectMergeStacktracesRequest{
+			ProfileTypeID: profileTypeID,
+			LabelSelector: comparison.LabelSelector,
+			Start:         comparison.Start,
+			End:           comparison.End,
+			MaxNodes:      maxNodes,
+		},
+	})
+
+	resp, err := c.connectClient.Diff(ctx, req)
+	if err != nil {
+		logger.Error("Received error from client", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if resp.Msg.Flamegraph == nil {
+		// Not an error, can happen when querying data out of range.
+		return nil, nil
+	}
+
+	levels := make([]*Level, len(resp.Msg.Flamegraph.Levels))
+	for i, level := range resp.Msg.Flamegraph.Levels {
+		levels[i] = &Level{
+			Values: level.Values,
+		}
+	}
+
+	return &DiffProfileResponse{
+		Flamebearer: &Flamebearer{
+			Names:   resp.Msg.Flamegraph.Names,
+			Levels:  levels,
+			Total:   resp.Msg.Flamegraph.Total,
+			MaxSelf: resp.Msg.Flamegraph.MaxSelf,
+		},
+		Units:           getUnits(profileTypeID),
+		BaselineTotal:   resp.Msg.Flamegraph.LeftTicks,
+		ComparisonTotal: resp.Msg.Flamegraph.RightTicks,
+	}, nil
+}
+
+// GetPprof returns the merged profile encoded as pprof protobuf.
+func (c *PyroscopeClient) GetPprof(ctx context.Context, profileTypeID, labelSelector string, start, end int64, maxNodes *int64) ([]byte, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.pyroscope.GetPprof", trace.WithAttributes(attribute.String("profileTypeID", profileTypeID), attribute.String("labelSelector", labelSelector)))
+	defer span.End()
+	req := connect.NewRequest(&querierv1.SelectMergeProfileRequest{
+		ProfileTypeID: profileTypeID,
+		LabelSelector: labelSelector,
+		Start:         start,
+		End:           end,
+		MaxNodes:      maxNodes,
+	})
+
+	resp, err := c.connectClient.SelectMergeProfile(ctx, req)
+	if err != nil {
+		logger.Error("Received error from client", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	b, err := proto.Marshal(resp.Msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("error marshaling profile: %v", err)
+	}
+	return b, nil
+}
+
 func profileQuery(ctx context.Context, err error, span trace.Span, flamegraph *querierv1.FlameGraph, profileTypeID string) (*ProfileResponse, error) {
 	levels := make([]*Level, len(flamegraph.Levels))
 	for i, level := range flamegraph.Levels {
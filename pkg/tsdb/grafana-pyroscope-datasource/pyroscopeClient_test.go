@@ -53,6 +53,22 @@ func Test_PyroscopeClient(t *testing.T) {
 		require.Equal(t, series, resp)
 	})
 
+	t.Run("GetDiffProfile", func(t *testing.T) {
+		resp, err := client.GetDiffProfile(context.Background(), "memory:alloc_objects:count:space:bytes",
+			ProfileSelection{LabelSelector: `{app="foo"}`, Start: 0, End: 100},
+			ProfileSelection{LabelSelector: `{app="bar"}`, Start: 100, End: 200},
+			nil,
+		)
+		require.Nil(t, err)
+		require.Equal(t, int64(10), resp.BaselineTotal)
+		require.Equal(t, int64(12), resp.ComparisonTotal)
+		require.Equal(t, "short", resp.Units)
+
+		req := connectClient.Req.(*connect.Request[querierv1.DiffRequest])
+		require.Equal(t, `{app="foo"}`, req.Msg.Left.LabelSelector)
+		require.Equal(t, int64(200), req.Msg.Right.End)
+	})
+
 	t.Run("GetProfile with empty response", func(t *testing.T) {
 		connectClient.SendEmptyProfileResponse = true
 		maxNodes := int64(-1)
@@ -80,8 +96,22 @@ func (f *FakePyroscopeConnectClient) LabelNames(ctx context.Context, c *connect.
 }
 
 func (f *FakePyroscopeConnectClient) Diff(ctx context.Context, c *connect.Request[querierv1.DiffRequest]) (*connect.Response[querierv1.DiffResponse], error) {
-	//TODO implement me
-	panic("implement me")
+	f.Req = c
+	return &connect.Response[querierv1.DiffResponse]{
+		Msg: &querierv1.DiffResponse{
+			Flamegraph: &querierv1.FlameGraphDiff{
+				Names: []string{"foo", "bar"},
+				Levels: []*querierv1.Level{
+					{Values: []int64{0, 10, 0, 0, 12, 0, 0}},
+					{Values: []int64{0, 10, 10, 0, 12, 12, 1}},
+				},
+				Total:      22,
+				MaxSelf:    12,
+				LeftTicks:  10,
+				RightTicks: 12,
+			},
+		},
+	}, nil
 }
 
 func (f *FakePyroscopeConnectClient) ProfileTypes(ctx context.Context, c *connect.Request[querierv1.ProfileTypesRequest]) (*connect.Response[querierv1.ProfileTypesResponse], error) {
@@ -34,6 +34,7 @@ const (
 	queryTypeProfile = string(dataquery.PyroscopeQueryTypeProfile)
 	queryTypeMetrics = string(dataquery.PyroscopeQueryTypeMetrics)
 	queryTypeBoth    = string(dataquery.PyroscopeQueryTypeBoth)
+	queryTypeDiff    = string(dataquery.PyroscopeQueryTypeDiff)
 )
 
 // query processes single Pyroscope query transforming the response to data.Frame packaged in DataResponse
@@ -152,6 +153,40 @@ func (d *PyroscopeDatasource) query(ctx context.Context, pCtx backend.PluginCont
 		})
 	}
 
+	if query.QueryType == queryTypeDiff {
+		g.Go(func() error {
+			baseline, err := baselineSelection(qm, query.TimeRange)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return err
+			}
+			comparison := ProfileSelection{
+				LabelSelector: labelSelector,
+				Start:         query.TimeRange.From.UnixMilli(),
+				End:           query.TimeRange.To.UnixMilli(),
+			}
+
+			logger.Debug("Calling GetDiffProfile", "queryModel", qm, "function", logEntrypoint())
+			diffResp, err := d.client.GetDiffProfile(gCtx, profileTypeId, baseline, comparison, qm.MaxNodes)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				logger.Error("Error GetDiffProfile()", "err", err, "function", logEntrypoint())
+				return err
+			}
+
+			frame := getEmptyDataFrame()
+			if diffResp != nil {
+				frame = diffResponseToDataFrame(diffResp)
+			}
+			responseMutex.Lock()
+			response.Frames = append(response.Frames, frame)
+			responseMutex.Unlock()
+			return nil
+		})
+	}
+
 	if err := g.Wait(); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -169,6 +204,113 @@ func responseToDataFrames(resp *ProfileResponse) *data.Frame {
 	return treeToNestedSetDataFrame(tree, resp.Units)
 }
 
+// baselineSelection returns the profile a diff query compares against. The baseline uses the
+// query's label selector and time range unless they are overridden in the query model.
+func baselineSelection(qm queryModel, timeRange backend.TimeRange) (ProfileSelection, error) {
+	baseline := ProfileSelection{
+		LabelSelector: qm.LabelSelector,
+		Start:         timeRange.From.UnixMilli(),
+		End:           timeRange.To.UnixMilli(),
+	}
+	if qm.BaselineLabelSelector != nil && *qm.BaselineLabelSelector != "" {
+		baseline.LabelSelector = *qm.BaselineLabelSelector
+	}
+
+	switch {
+	case qm.BaselineFrom != nil && qm.BaselineTo != nil:
+		if *qm.BaselineFrom >= *qm.BaselineTo {
+			return ProfileSelection{}, fmt.Errorf("baseline time range is invalid: baselineFrom must be before baselineTo")
+		}
+		baseline.Start = *qm.BaselineFrom
+		baseline.End = *qm.BaselineTo
+	case qm.BaselineTimeShift != nil && *qm.BaselineTimeShift != "":
+		shift, err := gtime.ParseDuration(*qm.BaselineTimeShift)
+		if err != nil {
+			return ProfileSelection{}, fmt.Errorf("error parsing baseline time shift: %v", err)
+		}
+		baseline.Start = timeRange.From.Add(-shift).UnixMilli()
+		baseline.End = timeRange.To.Add(-shift).UnixMilli()
+	}
+
+	return baseline, nil
+}
+
+// diffResponseToDataFrame turns a Pyroscope diff response into a nested set data.Frame. The value and self columns hold
+// the sum of both sides, while valueRight and selfRight hold the comparison side, which is the format the flame graph
+// visualisation expects for diff profiles.
+func diffResponseToDataFrame(resp *DiffProfileResponse) *data.Frame {
+	levels := make([]*Level, len(resp.Flamebearer.Levels))
+	rightValues := make([][]int64, len(resp.Flamebearer.Levels))
+	for i, level := range resp.Flamebearer.Levels {
+		values := make([]int64, 0, len(level.Values)/DIFF_ITEM_OFFSET*ITEM_OFFSET)
+		for j := 0; j+DIFF_ITEM_OFFSET <= len(level.Values); j += DIFF_ITEM_OFFSET {
+			item := level.Values[j : j+DIFF_ITEM_OFFSET]
+			values = append(values,
+				item[DIFF_LEFT_START_OFFSET]+item[DIFF_RIGHT_START_OFFSET],
+				item[DIFF_LEFT_VALUE_OFFSET]+item[DIFF_RIGHT_VALUE_OFFSET],
+				item[DIFF_LEFT_SELF_OFFSET]+item[DIFF_RIGHT_SELF_OFFSET],
+				item[DIFF_NAME_OFFSET],
+			)
+			rightValues[i] = append(rightValues[i], item[DIFF_RIGHT_VALUE_OFFSET], item[DIFF_RIGHT_SELF_OFFSET])
+		}
+		levels[i] = &Level{Values: values}
+	}
+
+	tree := levelsToTree(levels, resp.Flamebearer.Names)
+	frame := treeToNestedSetDataFrame(tree, resp.Units)
+
+	valueRightField := data.NewField("valueRight", nil, []int64{})
+	selfRightField := data.NewField("selfRight", nil, []int64{})
+	valueRightField.Config = &data.FieldConfig{Unit: resp.Units}
+	selfRightField.Config = &data.FieldConfig{Unit: resp.Units}
+
+	if tree != nil {
+		// Bars of each level are stored left to right, which is also the order in which the children of a level
+		// are visited level by level, so we can look up the comparison values by position.
+		rights := make(map[*ProfileTree][2]int64)
+		current := []*ProfileTree{tree}
+		for level := 0; len(current) > 0 && level < len(rightValues); level++ {
+			var next []*ProfileTree
+			for i, node := range current {
+				if 2*i+1 < len(rightValues[level]) {
+					rights[node] = [2]int64{rightValues[level][2*i], rightValues[level][2*i+1]}
+				}
+				next = append(next, node.Nodes...)
+			}
+			current = next
+		}
+		walkTree(tree, func(tree *ProfileTree) {
+			valueRightField.Append(rights[tree][0])
+			selfRightField.Append(rights[tree][1])
+		})
+	}
+
+	frame.Fields = append(frame.Fields, valueRightField, selfRightField)
+	frame.Meta.Custom = DiffMeta{
+		BaselineTotal:   resp.BaselineTotal,
+		ComparisonTotal: resp.ComparisonTotal,
+	}
+	return frame
+}
+
+// DiffMeta is added to diff profile frames so consumers can normalise both sides.
+type DiffMeta struct {
+	BaselineTotal   int64 `json:"baselineTotal"`
+	ComparisonTotal int64 `json:"comparisonTotal"`
+}
+
+// Offsets of the 7 numbers encoding each bar of a diff flame graph. Left is the baseline and right the comparison.
+const (
+	DIFF_LEFT_START_OFFSET  = 0
+	DIFF_LEFT_VALUE_OFFSET  = 1
+	DIFF_LEFT_SELF_OFFSET   = 2
+	DIFF_RIGHT_START_OFFSET = 3
+	DIFF_RIGHT_VALUE_OFFSET = 4
+	DIFF_RIGHT_SELF_OFFSET  = 5
+	DIFF_NAME_OFFSET        = 6
+	DIFF_ITEM_OFFSET        = 7
+)
+
 // START_OFFSET is offset of the bar relative to previous sibling
 const START_OFFSET = 0
 
@@ -91,6 +91,35 @@ func Test_query(t *testing.T) {
 		require.True(t, ok)
 		require.Equal(t, []string{"app", "instance"}, groupBy)
 	})
+
+	t.Run("query diff", func(t *testing.T) {
+		dataQuery := makeDataQuery()
+		dataQuery.QueryType = queryTypeDiff
+		dataQuery.JSON = []byte(`{"profileTypeId":"memory:alloc_objects:count:space:bytes","labelSelector":"{app=\"baz\"}","baselineLabelSelector":"{app=\"foo\"}","baselineTimeShift":"5s"}`)
+		resp := ds.query(context.Background(), pCtx, *dataQuery)
+		require.Nil(t, resp.Error)
+		require.Equal(t, 1, len(resp.Frames))
+		require.Equal(t, "valueRight", resp.Frames[0].Fields[4].Name)
+
+		baseline, ok := client.Args[1].(ProfileSelection)
+		require.True(t, ok)
+		require.Equal(t, ProfileSelection{LabelSelector: `{app="foo"}`, Start: 5000, End: 15000}, baseline)
+		comparison, ok := client.Args[2].(ProfileSelection)
+		require.True(t, ok)
+		require.Equal(t, ProfileSelection{LabelSelector: `{app="baz"}`, Start: 10000, End: 20000}, comparison)
+	})
+
+	t.Run("query diff with absolute baseline time range", func(t *testing.T) {
+		dataQuery := makeDataQuery()
+		dataQuery.QueryType = queryTypeDiff
+		dataQuery.JSON = []byte(`{"profileTypeId":"memory:alloc_objects:count:space:bytes","labelSelector":"{}","baselineFrom":1000,"baselineTo":2000,"baselineTimeShift":"5s"}`)
+		resp := ds.query(context.Background(), pCtx, *dataQuery)
+		require.Nil(t, resp.Error)
+
+		baseline, ok := client.Args[1].(ProfileSelection)
+		require.True(t, ok)
+		require.Equal(t, ProfileSelection{LabelSelector: "{}", Start: 1000, End: 2000}, baseline)
+	})
 }
 
 func makeDataQuery() *backend.DataQuery {
@@ -139,6 +168,29 @@ func Test_profileToDataFrame(t *testing.T) {
 	require.Equal(t, []string{"func1", "func2", "func3"}, frame.Fields[3].Config.TypeConfig.Enum.Text)
 }
 
+func Test_diffResponseToDataFrame(t *testing.T) {
+	profile := &DiffProfileResponse{
+		Flamebearer: &Flamebearer{
+			Names: []string{"root", "func1", "func2"},
+			Levels: []*Level{
+				{Values: []int64{0, 20, 0, 0, 30, 0, 0}},
+				{Values: []int64{0, 15, 15, 0, 10, 10, 1, 0, 5, 5, 0, 20, 20, 2}},
+			},
+		},
+		Units:           "short",
+		BaselineTotal:   20,
+		ComparisonTotal: 30,
+	}
+	frame := diffResponseToDataFrame(profile)
+	require.Equal(t, 6, len(frame.Fields))
+	require.Equal(t, data.NewField("level", nil, []int64{0, 1, 1}), frame.Fields[0])
+	require.Equal(t, data.NewField("value", nil, []int64{50, 25, 25}).SetConfig(&data.FieldConfig{Unit: "short"}), frame.Fields[1])
+	require.Equal(t, data.NewField("self", nil, []int64{0, 25, 25}).SetConfig(&data.FieldConfig{Unit: "short"}), frame.Fields[2])
+	require.Equal(t, data.NewField("valueRight", nil, []int64{30, 10, 20}).SetConfig(&data.FieldConfig{Unit: "short"}), frame.Fields[4])
+	require.Equal(t, data.NewField("selfRight", nil, []int64{0, 10, 20}).SetConfig(&data.FieldConfig{Unit: "short"}), frame.Fields[5])
+	require.Equal(t, DiffMeta{BaselineTotal: 20, ComparisonTotal: 30}, frame.Meta.Custom)
+}
+
 // This is where the tests for the datasource backend live.
 func Test_levelsToTree(t *testing.T) {
 	t.Run("simple", func(t *testing.T) {
@@ -328,6 +380,27 @@ func (f *FakeClient) GetSpanProfile(ctx context.Context, profileTypeID, labelSel
 	}, nil
 }
 
+func (f *FakeClient) GetDiffProfile(ctx context.Context, profileTypeID string, baseline, comparison ProfileSelection, maxNodes *int64) (*DiffProfileResponse, error) {
+	f.Args = []any{profileTypeID, baseline, comparison, maxNodes}
+	return &DiffProfileResponse{
+		Flamebearer: &Flamebearer{
+			Names: []string{"foo", "bar"},
+			Levels: []*Level{
+				{Values: []int64{0, 10, 0, 0, 12, 0, 0}},
+				{Values: []int64{0, 10, 10, 0, 12, 12, 1}},
+			},
+		},
+		Units:           "count",
+		BaselineTotal:   10,
+		ComparisonTotal: 12,
+	}, nil
+}
+
+func (f *FakeClient) GetPprof(ctx context.Context, profileTypeID, labelSelector string, start, end int64, maxNodes *int64) ([]byte, error) {
+	f.Args = []any{profileTypeID, labelSelector, start, end, maxNodes}
+	return []byte("pprof"), nil
+}
+
 func (f *FakeClient) GetSeries(ctx context.Context, profileTypeID, labelSelector string, start, end int64, groupBy []string, limit *int64, step float64) (*SeriesResponse, error) {
 	f.Args = []any{profileTypeID, labelSelector, start, end, groupBy, step}
 	return &SeriesResponse{
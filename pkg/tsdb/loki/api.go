@@ -234,6 +234,85 @@ func (api *LokiAPI) DataQuery(ctx context.Context, query lokiQuery, responseOpts
 	return &res, nil
 }
 
+func makePatternsRequest(ctx context.Context, lokiDsUrl string, query lokiQuery) (*http.Request, error) {
+	lokiUrl, err := url.Parse(lokiDsUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	qs := url.Values{}
+	qs.Set("query", query.Expr)
+	qs.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+	qs.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+	qs.Set("step", fmt.Sprintf("%dms", query.Step.Milliseconds()))
+
+	lokiUrl.Path = path.Join(lokiUrl.Path, "/loki/api/v1/patterns")
+	lokiUrl.RawQuery = qs.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", lokiUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	setXScopeOrgIDHeader(req, ctx)
+
+	return req, nil
+}
+
+// PatternsQuery returns the log patterns detected by Loki for the stream selector of the
+// query, as one time series of occurrences per pattern.
+func (api *LokiAPI) PatternsQuery(ctx context.Context, query lokiQuery) (*backend.DataResponse, error) {
+	req, err := makePatternsRequest(ctx, api.url, query)
+	if err != nil {
+		return nil, err
+	}
+
+	queryAttrs := []any{"start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr, "queryType", query.QueryType, "lokiHost", req.URL.Host, "lokiPath", req.URL.Path}
+	api.log.Debug("Sending patterns query to loki", queryAttrs...)
+	start := time.Now()
+	resp, err := api.client.Do(req)
+	if err != nil {
+		lp := []any{"error", err, "duration", time.Since(start), "stage", stageDatabaseRequest}
+		lp = append(lp, queryAttrs...)
+		api.log.Error("Error received from Loki", lp...)
+		res := backend.DataResponse{
+			Error: err,
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			res.ErrorSource = backend.ErrorSourceDownstream
+		}
+		return &res, nil
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			api.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		err := readLokiError(resp.Body)
+		res := backend.DataResponse{
+			Error:       err,
+			ErrorSource: backend.ErrorSourceFromHTTPStatus(resp.StatusCode),
+		}
+		api.log.Error("Error received from Loki", "error", err, "statusCode", resp.StatusCode, "duration", time.Since(start), "stage", stageDatabaseRequest)
+		return &res, nil
+	}
+	api.log.Info("Response received from loki", "status", "ok", "statusCode", resp.StatusCode, "duration", time.Since(start), "stage", stageDatabaseRequest)
+
+	start = time.Now()
+	var patterns patternsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&patterns); err != nil {
+		instrumentation.UpdatePluginParsingResponseDurationSeconds(ctx, time.Since(start), "error")
+		api.log.Error("Error parsing patterns response from loki", "error", err, "duration", time.Since(start), "stage", stageParseResponse)
+		return nil, err
+	}
+	instrumentation.UpdatePluginParsingResponseDurationSeconds(ctx, time.Since(start), "ok")
+
+	return &backend.DataResponse{Frames: patternsToFrames(patterns, &query)}, nil
+}
+
 func makeRawRequest(ctx context.Context, lokiDsUrl string, resourcePath string) (*http.Request, error) {
 	lokiUrl, err := url.Parse(lokiDsUrl)
 	if err != nil {
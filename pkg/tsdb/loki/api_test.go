@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
 	"github.com/stretchr/testify/require"
 )
@@ -280,3 +282,55 @@ func TestApiReturnValues(t *testing.T) {
 		require.ErrorContains(t, err, "foo")
 	})
 }
+
+func TestApiPatterns(t *testing.T) {
+	response := []byte(`
+	{
+		"status": "success",
+		"data": [
+			{"pattern": "<_> level=error msg=<_>", "level": "error", "samples": [[1711839270, 3], [1711839260, 1]]},
+			{"pattern": "<_> level=info msg=\"done\"", "samples": [[1711839260, 7]]}
+		]
+	}
+	`)
+
+	t.Run("patterns queries should call the patterns endpoint", func(t *testing.T) {
+		called := false
+		api := makeMockedAPI(200, "application/json", response, func(req *http.Request) {
+			called = true
+			require.Equal(t, "/loki/api/v1/patterns", req.URL.Path)
+			require.Equal(t, `{app="grafana"}`, req.URL.Query().Get("query"))
+			require.Equal(t, "10000ms", req.URL.Query().Get("step"))
+		}, false)
+
+		res, err := api.PatternsQuery(context.Background(), lokiQuery{Expr: `{app="grafana"}`, QueryType: QueryTypePatterns, Step: 10 * time.Second})
+		require.NoError(t, err)
+		require.True(t, called)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+
+		frame := res.Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		require.Equal(t, data.Labels{"pattern": "<_> level=error msg=<_>", "level": "error"}, frame.Fields[1].Labels)
+		require.Equal(t, time.Unix(1711839260, 0).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, []float64{1, 3}, []float64{frame.Fields[1].At(0).(float64), frame.Fields[1].At(1).(float64)})
+		require.Equal(t, data.Labels{"pattern": `<_> level=info msg="done"`}, res.Frames[1].Fields[1].Labels)
+	})
+
+	t.Run("patterns queries without results should return an empty frame", func(t *testing.T) {
+		api := makeMockedAPI(200, "application/json", []byte(`{"status": "success", "data": []}`), nil, false)
+
+		res, err := api.PatternsQuery(context.Background(), lokiQuery{Expr: `{app="grafana"}`, QueryType: QueryTypePatterns})
+		require.NoError(t, err)
+		require.Len(t, res.Frames, 1)
+		require.Empty(t, res.Frames[0].Fields)
+	})
+
+	t.Run("patterns queries should return the loki error", func(t *testing.T) {
+		api := makeMockedAPI(400, "application/json", []byte(`{"message": "only stream selectors are supported"}`), nil, false)
+
+		res, err := api.PatternsQuery(context.Background(), lokiQuery{Expr: `rate({app="grafana"}[1m])`, QueryType: QueryTypePatterns})
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Error, "only stream selectors are supported")
+	})
+}
@@ -21,9 +21,11 @@ const (
 type LokiQueryType string
 
 const (
-	LokiQueryTypeRange   LokiQueryType = "range"
-	LokiQueryTypeInstant LokiQueryType = "instant"
-	LokiQueryTypeStream  LokiQueryType = "stream"
+	LokiQueryTypeRange     LokiQueryType = "range"
+	LokiQueryTypeInstant   LokiQueryType = "instant"
+	LokiQueryTypeStream    LokiQueryType = "stream"
+	LokiQueryTypeLogVolume LokiQueryType = "logVolume"
+	LokiQueryTypePatterns  LokiQueryType = "patterns"
 )
 
 type SupportingQueryType string
@@ -282,6 +282,14 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 // we extracted this part of the functionality to make it easy to unit-test it
 func runQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	if query.QueryType == QueryTypePatterns {
+		res, err := api.PatternsQuery(ctx, *query)
+		if err != nil {
+			plog.Error("Error querying loki patterns", "error", err)
+		}
+		return res, err
+	}
+
 	res, err := api.DataQuery(ctx, *query, responseOpts)
 	if err != nil {
 		plog.Error("Error querying loki", "error", err)
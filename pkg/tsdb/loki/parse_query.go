@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 )
 
 const (
@@ -76,6 +77,10 @@ func parseQueryType(jsonPointerValue *string) (QueryType, error) {
 			return QueryTypeInstant, nil
 		case "range":
 			return QueryTypeRange, nil
+		case "logVolume":
+			return QueryTypeLogVolume, nil
+		case "patterns":
+			return QueryTypePatterns, nil
 		default:
 			return QueryTypeRange, fmt.Errorf("invalid queryType: %s", jsonValue)
 		}
@@ -156,6 +161,21 @@ func parseQuery(queryContext *backend.QueryDataRequest, logqlScopesEnabled bool)
 
 		expr := interpolateVariables(model.Expr, interval, timeRange, queryType, step)
 
+		supportingQueryType := parseSupportingQueryType(model.SupportingQueryType)
+
+		// log volume queries are executed as range queries counting the log lines per level,
+		// which returns time series that server-side expressions and alerting can work with.
+		if queryType == QueryTypeLogVolume {
+			expr, err = logVolumeExpr(expr, step)
+			if err != nil {
+				return nil, err
+			}
+			queryType = QueryTypeRange
+			if supportingQueryType == SupportingQueryNone {
+				supportingQueryType = SupportingQueryLogsVolume
+			}
+		}
+
 		direction, err := parseDirection(model.Direction)
 		if err != nil {
 			return nil, err
@@ -178,8 +198,6 @@ func parseQuery(queryContext *backend.QueryDataRequest, logqlScopesEnabled bool)
 			}
 		}
 
-		supportingQueryType := parseSupportingQueryType(model.SupportingQueryType)
-
 		qs = append(qs, &lokiQuery{
 			Expr:                expr,
 			QueryType:           queryType,
@@ -197,3 +215,12 @@ func parseQuery(queryContext *backend.QueryDataRequest, logqlScopesEnabled bool)
 
 	return qs, nil
 }
+
+// logVolumeExpr turns a log query into a metric query returning the number of log lines
+// per level, the same query the frontend uses for the logs volume histogram.
+func logVolumeExpr(expr string, step time.Duration) (string, error) {
+	if _, err := syntax.ParseLogSelector(expr, true); err != nil {
+		return "", fmt.Errorf("log volume queries require a log query: %w", err)
+	}
+	return fmt.Sprintf("sum by (level, detected_level) (count_over_time(%s | drop __error__ [%dms]))", expr, step.Milliseconds()), nil
+}
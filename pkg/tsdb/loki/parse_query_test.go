@@ -145,6 +145,83 @@ func TestParseQuery(t *testing.T) {
 		require.Equal(t, `{namespace="logish"} |= "problems"`, models[0].Expr)
 	})
 
+	t.Run("parsing query model with logVolume query type", func(t *testing.T) {
+		queryContext := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`
+					{
+						"expr": "{app=\"grafana\"} |= \"error\"",
+						"queryType": "logVolume",
+						"refId": "A"
+					}`,
+					),
+					TimeRange: backend.TimeRange{
+						From: time.Now().Add(-3000 * time.Second),
+						To:   time.Now(),
+					},
+					Interval:      time.Second * 15,
+					MaxDataPoints: 200,
+				},
+			},
+		}
+		models, err := parseQuery(queryContext, false)
+		require.NoError(t, err)
+		require.Equal(t, QueryTypeRange, models[0].QueryType)
+		require.Equal(t, SupportingQueryLogsVolume, models[0].SupportingQueryType)
+		require.Equal(t, `sum by (level, detected_level) (count_over_time({app="grafana"} |= "error" | drop __error__ [15000ms]))`, models[0].Expr)
+	})
+
+	t.Run("parsing query model with logVolume query type and a metric query", func(t *testing.T) {
+		queryContext := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`
+					{
+						"expr": "rate({app=\"grafana\"}[5m])",
+						"queryType": "logVolume",
+						"refId": "A"
+					}`,
+					),
+					TimeRange: backend.TimeRange{
+						From: time.Now().Add(-3000 * time.Second),
+						To:   time.Now(),
+					},
+					Interval:      time.Second * 15,
+					MaxDataPoints: 200,
+				},
+			},
+		}
+		_, err := parseQuery(queryContext, false)
+		require.ErrorContains(t, err, "log volume queries require a log query")
+	})
+
+	t.Run("parsing query model with patterns query type", func(t *testing.T) {
+		queryContext := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`
+					{
+						"expr": "{app=\"grafana\"}",
+						"queryType": "patterns",
+						"refId": "A"
+					}`,
+					),
+					TimeRange: backend.TimeRange{
+						From: time.Now().Add(-3000 * time.Second),
+						To:   time.Now(),
+					},
+					Interval:      time.Second * 15,
+					MaxDataPoints: 200,
+				},
+			},
+		}
+		models, err := parseQuery(queryContext, false)
+		require.NoError(t, err)
+		require.Equal(t, QueryTypePatterns, models[0].QueryType)
+		require.Equal(t, `{app="grafana"}`, models[0].Expr)
+	})
+
 	t.Run("interpolate variables, range between 1s and 0.5s", func(t *testing.T) {
 		expr := "go_goroutines $__interval $__interval_ms $__range $__range_s $__range_ms"
 		queryType := dataquery.LokiQueryTypeRange
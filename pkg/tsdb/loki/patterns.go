@@ -0,0 +1,64 @@
+package loki
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// patternsResponse is the body returned by Loki's /loki/api/v1/patterns endpoint.
+type patternsResponse struct {
+	Status string           `json:"status"`
+	Data   []patternSamples `json:"data"`
+}
+
+type patternSamples struct {
+	Pattern string `json:"pattern"`
+	Level   string `json:"level,omitempty"`
+	// Samples are pairs of unix timestamp in seconds and the number of matching log lines.
+	Samples [][2]float64 `json:"samples"`
+}
+
+// patternsToFrames converts the detected patterns to a multi-frame time series, with the
+// pattern (and its level, when Loki detected one) as labels. Server-side expressions can
+// reduce them like any other time series, e.g. to alert when a new pattern shows up.
+func patternsToFrames(res patternsResponse, query *lokiQuery) data.Frames {
+	meta := func() *data.FrameMeta {
+		return &data.FrameMeta{
+			Type:                data.FrameTypeTimeSeriesMulti,
+			TypeVersion:         data.FrameTypeVersion{0, 1},
+			ExecutedQueryString: "Expr: " + query.Expr + "\n" + "Step: " + query.Step.String(),
+		}
+	}
+
+	if len(res.Data) == 0 {
+		return data.Frames{data.NewFrame("").SetMeta(meta())}
+	}
+
+	frames := make(data.Frames, 0, len(res.Data))
+	for _, p := range res.Data {
+		sort.Slice(p.Samples, func(i, j int) bool { return p.Samples[i][0] < p.Samples[j][0] })
+
+		times := make([]time.Time, 0, len(p.Samples))
+		values := make([]float64, 0, len(p.Samples))
+		for _, sample := range p.Samples {
+			times = append(times, time.Unix(int64(sample[0]), 0).UTC())
+			values = append(values, sample[1])
+		}
+
+		labels := data.Labels{"pattern": p.Pattern}
+		if p.Level != "" {
+			labels["level"] = p.Level
+		}
+
+		timeField := data.NewField(data.TimeSeriesTimeFieldName, nil, times)
+		timeField.Config = &data.FieldConfig{Interval: float64(query.Step.Milliseconds())}
+		valueField := data.NewField(data.TimeSeriesValueFieldName, labels, values)
+		valueField.Config = &data.FieldConfig{DisplayNameFromDS: p.Pattern}
+
+		frames = append(frames, data.NewFrame("", timeField, valueField).SetMeta(meta()))
+	}
+
+	return frames
+}
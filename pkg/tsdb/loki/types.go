@@ -12,8 +12,10 @@ type SupportingQueryType = dataquery.SupportingQueryType
 type Direction = dataquery.LokiQueryDirection
 
 const (
-	QueryTypeRange   = dataquery.LokiQueryTypeRange
-	QueryTypeInstant = dataquery.LokiQueryTypeInstant
+	QueryTypeRange     = dataquery.LokiQueryTypeRange
+	QueryTypeInstant   = dataquery.LokiQueryTypeInstant
+	QueryTypeLogVolume = dataquery.LokiQueryTypeLogVolume
+	QueryTypePatterns  = dataquery.LokiQueryTypePatterns
 )
 
 const (
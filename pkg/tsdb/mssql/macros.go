@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqlmacros"
 )
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
@@ -70,21 +71,39 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		return fmt.Sprintf("'%s'", timeRange.From.UTC().Format(time.RFC3339)), nil
 	case "__timeTo":
 		return fmt.Sprintf("'%s'", timeRange.To.UTC().Format(time.RFC3339)), nil
-	case "__timeGroup":
-		if len(args) < 2 {
-			return "", fmt.Errorf("macro %v needs time column and interval", name)
+	case "__timeFilterShifted":
+		if len(args) != 2 {
+			return "", fmt.Errorf("macro %v needs time column and time shift", name)
 		}
-		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'"`))
+		shifted, err := sqlmacros.ShiftTimeRange(timeRange, args[1])
 		if err != nil {
-			return "", fmt.Errorf("error parsing interval %v", args[1])
+			return "", err
 		}
-		if len(args) == 3 {
-			err := sqleng.SetupFillmode(query, interval, args[2])
+		return m.evaluateMacro(shifted, query, "__timeFilter", args[:1])
+	case "__timeGroup":
+		tg, err := sqlmacros.ParseTimeGroup(name, args)
+		if err != nil {
+			return "", err
+		}
+		if tg.Fill != "" {
+			err := sqleng.SetupFillmode(query, tg.Interval, tg.Fill)
 			if err != nil {
 				return "", err
 			}
 		}
-		return fmt.Sprintf("FLOOR(DATEDIFF(second, '1970-01-01', %s)/%.0f)*%.0f", args[0], interval.Seconds(), interval.Seconds()), nil
+		if tg.HasTimeZone() {
+			// AT TIME ZONE expects Windows time zone names, e.g. 'W. Europe Standard Time'.
+			// IANA names such as 'Europe/Berlin' are rejected by the server, so fail early
+			// with a message that tells the user which names to use.
+			if strings.Contains(tg.TimeZone, "/") {
+				return "", fmt.Errorf("time zone %v is not a Windows time zone name, macro %v expects names such as 'W. Europe Standard Time'", tg.TimeZone, name)
+			}
+			// Buckets are computed on the local wall clock time and converted back to UTC.
+			local := fmt.Sprintf("DATEDIFF(second, '1970-01-01', CAST(%s AT TIME ZONE 'UTC' AT TIME ZONE '%s' AS datetime2))", tg.Column, tg.TimeZone)
+			return fmt.Sprintf("DATEDIFF(second, '1970-01-01', CAST(CAST(DATEADD(second, FLOOR(%s/%.0f)*%.0f, '1970-01-01') AS datetime2) AT TIME ZONE '%s' AT TIME ZONE 'UTC' AS datetime2))",
+				local, tg.Interval.Seconds(), tg.Interval.Seconds(), tg.TimeZone), nil
+		}
+		return fmt.Sprintf("FLOOR(DATEDIFF(second, '1970-01-01', %s)/%.0f)*%.0f", tg.Column, tg.Interval.Seconds(), tg.Interval.Seconds()), nil
 	case "__timeGroupAlias":
 		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
 		if err == nil {
@@ -126,6 +145,14 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 			return tg + " AS [time]", nil
 		}
 		return "", err
+	case "__quoteList":
+		return sqlmacros.QuoteList(args, sqlmacros.EscapeStringLiteral), nil
+	case "__rangeBucket":
+		b, err := sqlmacros.ParseRangeBucket(name, args)
+		if err != nil {
+			return "", err
+		}
+		return b.SQL(), nil
 	default:
 		return "", fmt.Errorf("unknown macro %q", name)
 	}
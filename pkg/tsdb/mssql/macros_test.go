@@ -77,6 +77,23 @@ func TestMacroEngine(t *testing.T) {
 			require.Equal(t, sql+" AS [time]", sql2)
 		})
 
+		t.Run("interpolate __timeGroup function with time zone", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, 1d, 'W. Europe Standard Time')")
+			require.Nil(t, err)
+
+			require.Equal(t, "GROUP BY DATEDIFF(second, '1970-01-01', CAST(CAST(DATEADD(second, FLOOR(DATEDIFF(second, '1970-01-01', CAST(time_column AT TIME ZONE 'UTC' AT TIME ZONE 'W. Europe Standard Time' AS datetime2))/86400)*86400, '1970-01-01') AS datetime2) AT TIME ZONE 'W. Europe Standard Time' AT TIME ZONE 'UTC' AS datetime2))", sql)
+		})
+
+		t.Run("interpolate __timeGroup function with IANA time zone", func(t *testing.T) {
+			_, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, 1d, 'Europe/Berlin')")
+			require.ErrorContains(t, err, "not a Windows time zone name")
+		})
+
+		t.Run("interpolate __timeGroup function with fill and time zone", func(t *testing.T) {
+			_, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, 1h, NULL, 'UTC')")
+			require.Nil(t, err)
+		})
+
 		t.Run("interpolate __timeGroup function with spaces around arguments", func(t *testing.T) {
 			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column , '5m')")
 			require.Nil(t, err)
@@ -9,11 +9,16 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqlmacros"
 )
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
 const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
 
+// stringLiteralReplacer escapes backslashes as well as quotes, since MySQL treats the
+// backslash as an escape character in string literals by default.
+var stringLiteralReplacer = strings.NewReplacer(`\`, `\\`, `'`, `''`)
+
 var restrictedRegExp = regexp.MustCompile(`(?im)([\s]*show[\s]+grants|[\s,]session_user\([^\)]*\)|[\s,]current_user(\([^\)]*\))?|[\s,]system_user\([^\)]*\)|[\s,]user\([^\)]*\))([\s,;]|$)`)
 
 type mySQLMacroEngine struct {
@@ -80,21 +85,34 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		return fmt.Sprintf("FROM_UNIXTIME(%d)", timeRange.From.UTC().Unix()), nil
 	case "__timeTo":
 		return fmt.Sprintf("FROM_UNIXTIME(%d)", timeRange.To.UTC().Unix()), nil
-	case "__timeGroup":
-		if len(args) < 2 {
-			return "", fmt.Errorf("macro %v needs time column and interval", name)
+	case "__timeFilterShifted":
+		if len(args) != 2 {
+			return "", fmt.Errorf("macro %v needs time column and time shift", name)
 		}
-		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'"`))
+		shifted, err := sqlmacros.ShiftTimeRange(timeRange, args[1])
 		if err != nil {
-			return "", fmt.Errorf("error parsing interval %v", args[1])
+			return "", err
 		}
-		if len(args) == 3 {
-			err := sqleng.SetupFillmode(query, interval, args[2])
+		return m.evaluateMacro(shifted, query, "__timeFilter", args[:1])
+	case "__timeGroup":
+		tg, err := sqlmacros.ParseTimeGroup(name, args)
+		if err != nil {
+			return "", err
+		}
+		if tg.Fill != "" {
+			err := sqleng.SetupFillmode(query, tg.Interval, tg.Fill)
 			if err != nil {
 				return "", err
 			}
 		}
-		return fmt.Sprintf("UNIX_TIMESTAMP(%s) DIV %.0f * %.0f", args[0], interval.Seconds(), interval.Seconds()), nil
+		if tg.HasTimeZone() {
+			// The column is expected to hold UTC values. Buckets are computed on the local
+			// wall clock time and converted back, which requires the server's time zone tables.
+			local := fmt.Sprintf("TIMESTAMPDIFF(SECOND, '1970-01-01', CONVERT_TZ(%s, '+00:00', '%s'))", tg.Column, tg.TimeZone)
+			return fmt.Sprintf("TIMESTAMPDIFF(SECOND, '1970-01-01', CONVERT_TZ(DATE_ADD('1970-01-01', INTERVAL %s DIV %.0f * %.0f SECOND), '%s', '+00:00'))",
+				local, tg.Interval.Seconds(), tg.Interval.Seconds(), tg.TimeZone), nil
+		}
+		return fmt.Sprintf("UNIX_TIMESTAMP(%s) DIV %.0f * %.0f", tg.Column, tg.Interval.Seconds(), tg.Interval.Seconds()), nil
 	case "__timeGroupAlias":
 		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
 		if err == nil {
@@ -136,7 +154,19 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 			return tg + " AS \"time\"", nil
 		}
 		return "", err
+	case "__quoteList":
+		return sqlmacros.QuoteList(args, escapeStringLiteral), nil
+	case "__rangeBucket":
+		b, err := sqlmacros.ParseRangeBucket(name, args)
+		if err != nil {
+			return "", err
+		}
+		return b.SQL(), nil
 	default:
 		return "", fmt.Errorf("unknown macro %v", name)
 	}
 }
+
+func escapeStringLiteral(value string) string {
+	return stringLiteralReplacer.Replace(value)
+}
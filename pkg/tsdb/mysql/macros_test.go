@@ -47,6 +47,20 @@ func TestMacroEngine(t *testing.T) {
 			require.Equal(t, sql+" AS \"time\"", sql2)
 		})
 
+		t.Run("interpolate __timeGroup function with time zone", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, 1d, 'Europe/Berlin')")
+			require.Nil(t, err)
+
+			require.Equal(t, "GROUP BY TIMESTAMPDIFF(SECOND, '1970-01-01', CONVERT_TZ(DATE_ADD('1970-01-01', INTERVAL TIMESTAMPDIFF(SECOND, '1970-01-01', CONVERT_TZ(time_column, '+00:00', 'Europe/Berlin')) DIV 86400 * 86400 SECOND), 'Europe/Berlin', '+00:00'))", sql)
+		})
+
+		t.Run("interpolate __quoteList function", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, `WHERE host IN ($__quoteList(a, b'c\))`)
+			require.Nil(t, err)
+
+			require.Equal(t, `WHERE host IN ('a','b''c\\')`, sql)
+		})
+
 		t.Run("interpolate __timeGroup function with spaces around arguments", func(t *testing.T) {
 			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column , '5m')")
 			require.Nil(t, err)
@@ -156,7 +156,25 @@ func NewInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 		db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
 		db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
 
-		return sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &rowTransformer, newMysqlMacroEngine(logger, userFacingDefaultError), logger)
+		// Cancelled queries are killed from a connection of their own, so a kill never
+		// waits behind the queries that hold every connection of the pool.
+		killDB, err := sql.Open("mysql", cnnstr)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		killDB.SetMaxOpenConns(1)
+		killDB.SetMaxIdleConns(1)
+		killDB.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
+		config.KillDB = killDB
+
+		handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &rowTransformer, newMysqlMacroEngine(logger, userFacingDefaultError), logger)
+		if err != nil {
+			_ = killDB.Close()
+			_ = db.Close()
+			return nil, err
+		}
+		return handler, nil
 	}
 }
 
@@ -168,7 +186,8 @@ func (t *mysqlQueryResultTransformer) TransformQueryError(logger log.Logger, err
 	var driverErr *mysql.MySQLError
 	if errors.As(err, &driverErr) {
 		if driverErr.Number != mysqlerr.ER_PARSE_ERROR && driverErr.Number != mysqlerr.ER_BAD_FIELD_ERROR &&
-			driverErr.Number != mysqlerr.ER_NO_SUCH_TABLE {
+			driverErr.Number != mysqlerr.ER_NO_SUCH_TABLE && driverErr.Number != mysqlerr.ER_QUERY_TIMEOUT &&
+			driverErr.Number != mysqlerr.ER_QUERY_INTERRUPTED {
 			logger.Error("Query error", "error", err)
 			return fmt.Errorf(("query failed - %s"), t.userError)
 		}
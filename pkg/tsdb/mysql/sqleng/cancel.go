@@ -0,0 +1,159 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+)
+
+// killQueryTimeout bounds how long we wait for the server to acknowledge a KILL QUERY.
+const killQueryTimeout = 5 * time.Second
+
+// maxCachedConnectionIDs bounds the connection id cache. The pool closes connections
+// without telling us, so the cache is cleared once it grows past this size.
+const maxCachedConnectionIDs = 1000
+
+// connectionIDs caches the server side id of the connections of the pool, so it is
+// only looked up once per connection rather than once per query.
+type connectionIDs struct {
+	mu  sync.Mutex
+	ids map[any]int64
+}
+
+func (c *connectionIDs) get(driverConn any) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.ids[driverConn]
+	return id, ok
+}
+
+func (c *connectionIDs) set(driverConn any, id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ids == nil || len(c.ids) >= maxCachedConnectionIDs {
+		c.ids = make(map[any]int64)
+	}
+	c.ids[driverConn] = id
+}
+
+func (c *connectionIDs) remove(driverConn any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.ids, driverConn)
+}
+
+// cancellableConn reserves a connection from the pool for a single query and watches
+// ctx while the query runs. The MySQL driver only closes the client side of the
+// connection when a context is cancelled, which leaves the statement running on the
+// server, so when ctx is done we issue a KILL QUERY for the reserved connection from
+// the dedicated kill connection. The returned function stops the watcher and releases
+// the connection, a connection whose query was killed is discarded rather than
+// returned to the pool.
+func (e *DataSourceHandler) cancellableConn(ctx context.Context, logger log.Logger) (*sql.Conn, func(), error) {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	connectionID, driverConn, err := e.connectionID(ctx, conn, logger)
+	if err != nil {
+		if closeErr := conn.Close(); closeErr != nil {
+			logger.Warn("Failed to release connection", "err", closeErr)
+		}
+		return nil, nil, err
+	}
+
+	done := make(chan struct{})
+	killed := make(chan bool, 1)
+	go func() {
+		select {
+		case <-done:
+			killed <- false
+		case <-ctx.Done():
+			e.killQuery(logger, connectionID)
+			killed <- true
+		}
+	}()
+
+	release := func() {
+		close(done)
+		// wait for the watcher, so a KILL QUERY never reaches a connection that is back in the pool
+		if <-killed {
+			e.connectionIDs.remove(driverConn)
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+			return
+		}
+		if err := conn.Close(); err != nil {
+			logger.Warn("Failed to release connection", "err", err)
+		}
+	}
+	return conn, release, nil
+}
+
+// connectionID returns the server side id of the connection and the driver connection it belongs to. The
+// query timeout of the data source is set on the connection the first time it is used.
+func (e *DataSourceHandler) connectionID(ctx context.Context, conn *sql.Conn, logger log.Logger) (int64, any, error) {
+	var driverConn any
+	if err := conn.Raw(func(dc any) error {
+		driverConn = dc
+		return nil
+	}); err != nil {
+		return 0, nil, err
+	}
+
+	if id, ok := e.connectionIDs.get(driverConn); ok {
+		return id, driverConn, nil
+	}
+
+	var id int64
+	var version string
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID(), VERSION()").Scan(&id, &version); err != nil {
+		return 0, nil, err
+	}
+
+	if timeout := e.dsInfo.JsonData.QueryTimeout; timeout > 0 {
+		// Servers without the variable, such as MySQL before 5.7.8, run the queries without a server side
+		// timeout, they are still killed when the request is cancelled.
+		if _, err := conn.ExecContext(ctx, queryTimeoutStatement(version, timeout)); err != nil {
+			logger.Warn("Failed to set the query timeout of the connection", "version", version, "err", err)
+		}
+	}
+
+	e.connectionIDs.set(driverConn, id)
+	return id, driverConn, nil
+}
+
+// queryTimeoutStatement returns the statement setting the timeout of the SELECT statements of the session.
+// MySQL aborts them after max_execution_time milliseconds, MariaDB has no such variable and aborts them
+// after max_statement_time seconds instead.
+func queryTimeoutStatement(version string, timeoutSeconds int) string {
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return "SET SESSION max_statement_time = " + strconv.Itoa(timeoutSeconds)
+	}
+	return "SET SESSION max_execution_time = " + strconv.Itoa(timeoutSeconds*1000)
+}
+
+func (e *DataSourceHandler) killQuery(logger log.Logger, connectionID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+	defer cancel()
+
+	// Without a dedicated connection the kill has to wait for a free connection of the
+	// query pool, which is what handlers built without a KillDB do.
+	db := e.killDB
+	if db == nil {
+		db = e.db
+	}
+
+	// KILL does not accept placeholders, the connection id is an integer we read from the server.
+	if _, err := db.ExecContext(ctx, "KILL QUERY "+strconv.FormatInt(connectionID, 10)); err != nil {
+		logger.Debug("Failed to kill cancelled query", "connectionId", connectionID, "err", err)
+		return
+	}
+	logger.Debug("Killed cancelled query", "connectionId", connectionID)
+}
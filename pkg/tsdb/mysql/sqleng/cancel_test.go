@@ -0,0 +1,116 @@
+package sqleng
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancellableConn(t *testing.T) {
+	logger := backend.NewLoggerWith("logger", "test")
+
+	t.Run("looks the connection id up once per connection", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+		db.SetMaxOpenConns(1)
+		handler := &DataSourceHandler{db: db}
+
+		mock.ExpectQuery("SELECT CONNECTION_ID(), VERSION()").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(42, "8.0.36"))
+		for i := 0; i < 2; i++ {
+			_, release, err := handler.cancellableConn(context.Background(), logger)
+			require.NoError(t, err)
+			release()
+		}
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("kills the query when the context is cancelled and discards the connection", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+		handler := &DataSourceHandler{db: db}
+
+		mock.ExpectQuery("SELECT CONNECTION_ID(), VERSION()").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(42, "8.0.36"))
+		mock.ExpectExec("KILL QUERY 42").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, release, err := handler.cancellableConn(ctx, logger)
+		require.NoError(t, err)
+		cancel()
+		require.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 10*time.Millisecond)
+		release()
+
+		require.Empty(t, handler.connectionIDs.ids)
+		require.Equal(t, 1, db.Stats().OpenConnections, "only the connection used to kill the query is kept")
+	})
+
+	t.Run("kills the query from the dedicated connection when the pool is saturated", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+		db.SetMaxOpenConns(1)
+		killDB, killMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer func() { _ = killDB.Close() }()
+		handler := &DataSourceHandler{db: db, killDB: killDB}
+
+		mock.ExpectQuery("SELECT CONNECTION_ID(), VERSION()").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(42, "8.0.36"))
+		killMock.ExpectExec("KILL QUERY 42").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, release, err := handler.cancellableConn(ctx, logger)
+		require.NoError(t, err)
+		cancel()
+		require.Eventually(t, func() bool { return killMock.ExpectationsWereMet() == nil }, time.Second, 10*time.Millisecond)
+		release()
+
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("sets the query timeout once per connection", func(t *testing.T) {
+		testCases := []struct {
+			version   string
+			statement string
+		}{
+			{version: "8.0.36", statement: "SET SESSION max_execution_time = 30000"},
+			{version: "10.11.6-MariaDB-log", statement: "SET SESSION max_statement_time = 30"},
+		}
+		for _, tc := range testCases {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			db.SetMaxOpenConns(1)
+			handler := &DataSourceHandler{db: db, dsInfo: DataSourceInfo{JsonData: JsonData{QueryTimeout: 30}}}
+
+			mock.ExpectQuery("SELECT CONNECTION_ID(), VERSION()").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(42, tc.version))
+			mock.ExpectExec(tc.statement).WillReturnResult(sqlmock.NewResult(0, 0))
+			for i := 0; i < 2; i++ {
+				_, release, err := handler.cancellableConn(context.Background(), logger)
+				require.NoError(t, err)
+				release()
+			}
+			require.NoError(t, mock.ExpectationsWereMet(), tc.version)
+			_ = db.Close()
+		}
+	})
+
+	t.Run("runs the queries without a server side timeout when it can't be set", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+		handler := &DataSourceHandler{db: db, dsInfo: DataSourceInfo{JsonData: JsonData{QueryTimeout: 30}}}
+
+		mock.ExpectQuery("SELECT CONNECTION_ID(), VERSION()").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(42, "5.6.51"))
+		mock.ExpectExec("SET SESSION max_execution_time = 30000").WillReturnError(errors.New("Unknown system variable 'max_execution_time'"))
+
+		_, release, err := handler.cancellableConn(context.Background(), logger)
+		require.NoError(t, err)
+		release()
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
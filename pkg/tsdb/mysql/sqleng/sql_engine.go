@@ -19,6 +19,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqlframe"
 )
 
 // MetaKeyExecutedQueryString is the key where the executed query should get stored
@@ -58,6 +60,9 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	RowLimit                int64  `json:"rowLimit"`
+	ByteLimit               int64  `json:"byteLimit"`
+	QueryTimeout            int    `json:"queryTimeout"`
 }
 
 type DataSourceInfo struct {
@@ -76,18 +81,25 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// KillDB is a pool of its own, limited to a single connection, used to kill
+	// cancelled queries. It must not share connections with the query pool, which
+	// is usually saturated when queries time out.
+	KillDB *sql.DB
 }
 
 type DataSourceHandler struct {
 	macroEngine            SQLMacroEngine
 	queryResultTransformer SqlQueryResultTransformer
 	db                     *sql.DB
+	killDB                 *sql.DB
 	timeColumnNames        []string
 	metricColumnTypes      []string
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	byteLimit              int64
 	userError              string
+	connectionIDs          connectionIDs
 }
 
 type QueryJson struct {
@@ -122,9 +134,15 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		byteLimit:              config.DSInfo.JsonData.ByteLimit,
 		userError:              userFacingDefaultError,
 	}
 
+	// A data source can only tighten the instance wide row limit, never raise it.
+	if dsRowLimit := config.DSInfo.JsonData.RowLimit; dsRowLimit > 0 && (queryDataHandler.rowLimit <= 0 || dsRowLimit < queryDataHandler.rowLimit) {
+		queryDataHandler.rowLimit = dsRowLimit
+	}
+
 	if len(config.TimeColumnNames) > 0 {
 		queryDataHandler.timeColumnNames = config.TimeColumnNames
 	}
@@ -134,6 +152,7 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 	}
 
 	queryDataHandler.db = db
+	queryDataHandler.killDB = config.KillDB
 	return &queryDataHandler, nil
 }
 
@@ -149,6 +168,11 @@ func (e *DataSourceHandler) Dispose() {
 			e.log.Error("Failed to dispose db", "error", err)
 		}
 	}
+	if e.killDB != nil {
+		if err := e.killDB.Close(); err != nil {
+			e.log.Error("Failed to dispose kill query db", "error", err)
+		}
+	}
 	e.log.Debug("DB disposed")
 }
 
@@ -250,7 +274,14 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
+	conn, release, err := e.cancellableConn(queryContext, logger)
+	if err != nil {
+		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream)
+		return
+	}
+	defer release()
+
+	rows, err := conn.QueryContext(queryContext, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream)
 		return
@@ -269,7 +300,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := sqlframe.FromRows(rows, e.rowLimit, e.byteLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery, backend.ErrorSourcePlugin)
 		return
@@ -0,0 +1,130 @@
+// Package sqlframe converts the rows of the SQL data sources (PostgreSQL and MySQL) to
+// data frames within a row and byte limit.
+package sqlframe
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// FromRows is like sqlutil.FrameFromRows, but it also stops reading once the
+// estimated in-memory size of the frame exceeds byteLimit. A byteLimit <= 0 disables
+// the check. When either limit is reached the partial frame is returned together
+// with a warning notice, so that a runaway query cannot exhaust the server's memory.
+func FromRows(rows *sql.Rows, rowLimit int64, byteLimit int64, converters ...sqlutil.Converter) (*data.Frame, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	scanRow, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, scanRow.Converters...)
+
+	var i, size int64
+	limited := false
+	for !limited {
+		// first iterate over rows may be nop if not switched result set to next
+		for rows.Next() {
+			if i == rowLimit {
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+				})
+				limited = true
+				break
+			}
+
+			if byteLimit > 0 && size >= byteLimit {
+				frame.AppendNotices(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Results have been limited to %v rows because the SQL byte limit of %v bytes was reached", i, byteLimit),
+				})
+				limited = true
+				break
+			}
+
+			r := scanRow.NewScannableRow()
+			if err := rows.Scan(r...); err != nil {
+				return nil, err
+			}
+
+			if err := sqlutil.Append(frame, r, scanRow.Converters...); err != nil {
+				return nil, err
+			}
+
+			if byteLimit > 0 {
+				size += lastRowSize(frame)
+			}
+
+			i++
+		}
+		if limited || !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return frame, backend.DownstreamError(err)
+	}
+
+	return frame, nil
+}
+
+// lastRowSize estimates the number of bytes used by the last row of the frame.
+func lastRowSize(frame *data.Frame) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		n := field.Len()
+		if n == 0 {
+			continue
+		}
+		size += valueSize(field.At(n - 1))
+	}
+	return size
+}
+
+// valueSize estimates the number of bytes used by a single field value.
+func valueSize(v any) int64 {
+	switch val := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(val))
+	case *string:
+		if val == nil {
+			return 0
+		}
+		return int64(len(*val))
+	case []byte:
+		return int64(len(val))
+	case *[]byte:
+		if val == nil {
+			return 0
+		}
+		return int64(len(*val))
+	case bool, *bool, int8, *int8, uint8, *uint8:
+		return 1
+	case int16, *int16, uint16, *uint16:
+		return 2
+	case int32, *int32, uint32, *uint32, float32, *float32:
+		return 4
+	case time.Time, *time.Time:
+		return 24
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,63 @@
+package sqlframe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromRows(t *testing.T) {
+	queryRows := func(t *testing.T, n int, value string) *sqlmock.Rows {
+		t.Helper()
+		rows := sqlmock.NewRows([]string{"value"})
+		for i := 0; i < n; i++ {
+			rows.AddRow(value)
+		}
+		return rows
+	}
+
+	run := func(t *testing.T, mockRows *sqlmock.Rows, rowLimit int64, byteLimit int64) (int, []string) {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectQuery("SELECT").WillReturnRows(mockRows)
+		rows, err := db.Query("SELECT value FROM t")
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+
+		frame, err := FromRows(rows, rowLimit, byteLimit)
+		require.NoError(t, err)
+
+		var notices []string
+		if frame.Meta != nil {
+			for _, n := range frame.Meta.Notices {
+				notices = append(notices, n.Text)
+			}
+		}
+		return frame.Rows(), notices
+	}
+
+	t.Run("returns all rows when no limit is reached", func(t *testing.T) {
+		n, notices := run(t, queryRows(t, 5, "abc"), 100, 0)
+		require.Equal(t, 5, n)
+		require.Empty(t, notices)
+	})
+
+	t.Run("stops at the row limit", func(t *testing.T) {
+		n, notices := run(t, queryRows(t, 5, "abc"), 2, 0)
+		require.Equal(t, 2, n)
+		require.Len(t, notices, 1)
+		require.Contains(t, notices[0], "row limit")
+	})
+
+	t.Run("stops at the byte limit", func(t *testing.T) {
+		n, notices := run(t, queryRows(t, 10, strings.Repeat("x", 100)), 100, 250)
+		require.Equal(t, 3, n)
+		require.Len(t, notices, 1)
+		require.Contains(t, notices[0], "byte limit")
+	})
+}
@@ -0,0 +1,172 @@
+// Package sqlmacros contains the dialect independent parts of the macros shared by
+// the SQL data sources (PostgreSQL, MySQL and Microsoft SQL Server). Argument parsing
+// and validation lives here, while every data source renders the final SQL for its
+// own dialect.
+package sqlmacros
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// timeZoneRegExp restricts time zone arguments to the characters used by IANA and
+// Windows time zone names, so they can be embedded in SQL string literals safely.
+var timeZoneRegExp = regexp.MustCompile(`^[A-Za-z0-9_+\-/ .:]+$`)
+
+// TimeGroup holds the parsed arguments of
+// $__timeGroup(column, interval[, fill][, 'time zone']).
+type TimeGroup struct {
+	Column   string
+	Interval time.Duration
+	// Fill is the fill mode argument, empty when none was given.
+	Fill string
+	// TimeZone is the time zone the buckets are aligned to, empty for UTC.
+	TimeZone string
+}
+
+// ParseTimeGroup parses the arguments of a $__timeGroup-like macro. The optional
+// arguments are told apart by quoting: a quoted argument is a time zone, an unquoted
+// one a fill mode (NULL, previous or a value).
+func ParseTimeGroup(name string, args []string) (TimeGroup, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return TimeGroup{}, fmt.Errorf("macro %v needs time column and interval and optional fill value and time zone", name)
+	}
+
+	interval, err := gtime.ParseInterval(strings.Trim(args[1], `'"`))
+	if err != nil {
+		return TimeGroup{}, fmt.Errorf("error parsing interval %v", args[1])
+	}
+	if interval <= 0 {
+		return TimeGroup{}, fmt.Errorf("interval %v of macro %v must be positive", args[1], name)
+	}
+
+	tg := TimeGroup{Column: args[0], Interval: interval}
+	for _, arg := range args[2:] {
+		if isQuoted(arg) {
+			if tg.TimeZone != "" {
+				return TimeGroup{}, fmt.Errorf("macro %v accepts only one time zone", name)
+			}
+			tz, err := ParseTimeZone(arg)
+			if err != nil {
+				return TimeGroup{}, err
+			}
+			tg.TimeZone = tz
+			continue
+		}
+		if tg.Fill != "" {
+			return TimeGroup{}, fmt.Errorf("macro %v accepts only one fill value", name)
+		}
+		tg.Fill = arg
+	}
+
+	return tg, nil
+}
+
+// HasTimeZone reports whether the buckets should be aligned to a time zone other than UTC.
+func (tg TimeGroup) HasTimeZone() bool {
+	return tg.TimeZone != "" && !strings.EqualFold(tg.TimeZone, "UTC")
+}
+
+// ParseTimeZone strips the quotes from a time zone argument and validates it.
+func ParseTimeZone(arg string) (string, error) {
+	tz := strings.Trim(arg, `'"`)
+	if !timeZoneRegExp.MatchString(tz) {
+		return "", fmt.Errorf("invalid time zone %v", arg)
+	}
+	return tz, nil
+}
+
+// ShiftTimeRange moves the time range back by the given interval, e.g. "1w" returns the
+// same range one week earlier. A leading minus sign moves the range forward instead.
+func ShiftTimeRange(timeRange backend.TimeRange, shift string) (backend.TimeRange, error) {
+	shift = strings.Trim(shift, `'"`)
+	forward := strings.HasPrefix(shift, "-")
+	d, err := gtime.ParseInterval(strings.TrimPrefix(shift, "-"))
+	if err != nil {
+		return backend.TimeRange{}, fmt.Errorf("error parsing time shift %v", shift)
+	}
+	if forward {
+		d = -d
+	}
+	return backend.TimeRange{From: timeRange.From.Add(-d), To: timeRange.To.Add(-d)}, nil
+}
+
+// EscapeStringLiteral escapes a value for use inside a single-quoted SQL string literal
+// following the SQL standard, i.e. by doubling single quotes.
+func EscapeStringLiteral(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// QuoteList renders the values as a comma separated list of string literals, suitable
+// for an IN clause. It backs $__quoteList(${var:csv}) and escapes single quotes in the
+// values. The macro is expanded after the variables are interpolated into the raw SQL,
+// so values containing a comma or a closing parenthesis change the arguments the macro
+// receives, and QuoteList is not a safeguard against untrusted variable values.
+func QuoteList(values []string, escape func(string) string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, "'"+escape(strings.TrimSpace(v))+"'")
+	}
+	return strings.Join(quoted, ",")
+}
+
+// RangeBucket holds the parsed arguments of $__rangeBucket(column, min, max, count).
+type RangeBucket struct {
+	Column string
+	Min    float64
+	Max    float64
+	Count  int
+}
+
+// ParseRangeBucket parses the arguments of a $__rangeBucket macro.
+func ParseRangeBucket(name string, args []string) (RangeBucket, error) {
+	if len(args) != 4 {
+		return RangeBucket{}, fmt.Errorf("macro %v needs column, min, max and bucket count", name)
+	}
+	lower, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return RangeBucket{}, fmt.Errorf("error parsing min value %v", args[1])
+	}
+	upper, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return RangeBucket{}, fmt.Errorf("error parsing max value %v", args[2])
+	}
+	if upper <= lower {
+		return RangeBucket{}, fmt.Errorf("max value of macro %v must be greater than min value", name)
+	}
+	count, err := strconv.Atoi(args[3])
+	if err != nil || count <= 0 {
+		return RangeBucket{}, fmt.Errorf("bucket count %v of macro %v must be a positive integer", args[3], name)
+	}
+	return RangeBucket{Column: args[0], Min: lower, Max: upper, Count: count}, nil
+}
+
+// Width returns the width of a single bucket.
+func (b RangeBucket) Width() float64 {
+	return (b.Max - b.Min) / float64(b.Count)
+}
+
+// SQL returns an expression evaluating to the lower bound of the bucket the column value
+// falls into. Values outside [min, max) are put into the first or last bucket. The
+// expression only uses CASE and FLOOR, so it is valid in every supported dialect.
+func (b RangeBucket) SQL() string {
+	lower := formatFloat(b.Min)
+	width := formatFloat(b.Width())
+	last := formatFloat(b.Max - b.Width())
+	return fmt.Sprintf("CASE WHEN %[1]s < %[2]s THEN %[2]s WHEN %[1]s >= %[3]s THEN %[4]s ELSE %[2]s + FLOOR((%[1]s - %[2]s) / %[5]s) * %[5]s END",
+		b.Column, lower, formatFloat(b.Max), last, width)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func isQuoted(arg string) bool {
+	return len(arg) >= 2 && (arg[0] == '\'' && arg[len(arg)-1] == '\'' || arg[0] == '"' && arg[len(arg)-1] == '"')
+}
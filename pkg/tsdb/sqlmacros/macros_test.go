@@ -0,0 +1,79 @@
+package sqlmacros
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeGroup(t *testing.T) {
+	t.Run("column and interval", func(t *testing.T) {
+		tg, err := ParseTimeGroup("__timeGroup", []string{"time", "'5m'"})
+		require.NoError(t, err)
+		require.Equal(t, TimeGroup{Column: "time", Interval: 5 * time.Minute}, tg)
+		require.False(t, tg.HasTimeZone())
+	})
+
+	t.Run("fill value and time zone", func(t *testing.T) {
+		tg, err := ParseTimeGroup("__timeGroup", []string{"time", "1d", "NULL", "'Europe/Berlin'"})
+		require.NoError(t, err)
+		require.Equal(t, "NULL", tg.Fill)
+		require.Equal(t, "Europe/Berlin", tg.TimeZone)
+		require.True(t, tg.HasTimeZone())
+	})
+
+	t.Run("UTC time zone is the default", func(t *testing.T) {
+		tg, err := ParseTimeGroup("__timeGroup", []string{"time", "1d", "'UTC'"})
+		require.NoError(t, err)
+		require.Empty(t, tg.Fill)
+		require.False(t, tg.HasTimeZone())
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"time"},
+			{"time", "abc"},
+			{"time", "1d", "'Europe/Berlin'", "'UTC'"},
+			{"time", "1d", "NULL", "previous"},
+			{"time", "1d", "'Europe/Berlin'';DROP TABLE x;--'"},
+		} {
+			_, err := ParseTimeGroup("__timeGroup", args)
+			require.Error(t, err, args)
+		}
+	})
+}
+
+func TestShiftTimeRange(t *testing.T) {
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	tr := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+
+	shifted, err := ShiftTimeRange(tr, "1d")
+	require.NoError(t, err)
+	require.Equal(t, from.Add(-24*time.Hour), shifted.From)
+	require.Equal(t, from.Add(-23*time.Hour), shifted.To)
+
+	shifted, err = ShiftTimeRange(tr, "'-1h'")
+	require.NoError(t, err)
+	require.Equal(t, from.Add(time.Hour), shifted.From)
+
+	_, err = ShiftTimeRange(tr, "yesterday")
+	require.Error(t, err)
+}
+
+func TestQuoteList(t *testing.T) {
+	require.Equal(t, "'a','O''Brien'", QuoteList([]string{"a", " O'Brien"}, EscapeStringLiteral))
+}
+
+func TestRangeBucket(t *testing.T) {
+	b, err := ParseRangeBucket("__rangeBucket", []string{"v", "0", "1", "4"})
+	require.NoError(t, err)
+	require.Equal(t, 0.25, b.Width())
+	require.Equal(t, "CASE WHEN v < 0 THEN 0 WHEN v >= 1 THEN 0.75 ELSE 0 + FLOOR((v - 0) / 0.25) * 0.25 END", b.SQL())
+
+	_, err = ParseRangeBucket("__rangeBucket", []string{"v", "1", "0", "4"})
+	require.Error(t, err)
+	_, err = ParseRangeBucket("__rangeBucket", []string{"v", "0", "1", "0"})
+	require.Error(t, err)
+}
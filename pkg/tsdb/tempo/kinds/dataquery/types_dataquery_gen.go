@@ -63,6 +63,8 @@ type TempoQuery struct {
 	Datasource any `json:"datasource,omitempty"`
 	// For metric queries, whether to run instant or range queries
 	MetricsQueryType *MetricsQueryType `json:"metricsQueryType,omitempty"`
+	// For span metrics queries, the RED metric to return
+	SpanMetric *SpanMetric `json:"spanMetric,omitempty"`
 }
 
 // NewTempoQuery creates a new TempoQuery object.
@@ -80,6 +82,7 @@ const (
 	TempoQueryTypeNativeSearch  TempoQueryType = "nativeSearch"
 	TempoQueryTypeTraceId       TempoQueryType = "traceId"
 	TempoQueryTypeClear         TempoQueryType = "clear"
+	TempoQueryTypeSpanMetrics   TempoQueryType = "spanMetrics"
 )
 
 type MetricsQueryType string
@@ -89,6 +92,14 @@ const (
 	MetricsQueryTypeInstant MetricsQueryType = "instant"
 )
 
+type SpanMetric string
+
+const (
+	SpanMetricRate      SpanMetric = "rate"
+	SpanMetricErrorRate SpanMetric = "errorRate"
+	SpanMetricDuration  SpanMetric = "duration"
+)
+
 // The state of the TraceQL streaming search query
 type SearchStreamingState string
 
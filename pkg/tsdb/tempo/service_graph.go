@@ -0,0 +1,420 @@
+package tempo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	//nolint:all
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The service graph and span metrics queries are computed from TraceQL metrics instead of the
+// metrics generator series in Prometheus, so they only need Tempo to be configured.
+const (
+	serviceNameAttribute = "resource.service.name"
+	peerServiceAttribute = "span.peer.service"
+
+	serverSpans = "kind=server"
+	clientSpans = "kind=client && " + peerServiceAttribute + " != nil"
+	errorSpans  = "status=error"
+
+	p90Duration = "quantile_over_time(duration, .9)"
+)
+
+// runServiceMapQuery returns the node graph frames of the service map followed by a time series
+// frame per service and edge metric. Alert queries only get the time series frames, as the node
+// graph frames can't be converted to alerting results.
+func (s *Service) runServiceMapQuery(ctx context.Context, pCtx backend.PluginContext, backendQuery backend.DataQuery, fromAlert bool) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Running service map query", "function", logEntrypoint())
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.runServiceMapQuery", trace.WithAttributes(
+		attribute.String("queryType", backendQuery.QueryType),
+	))
+	defer span.End()
+
+	tempoQuery := &dataquery.TempoQuery{}
+	if err := json.Unmarshal(backendQuery.JSON, tempoQuery); err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	nodeGroupBy := serviceNameAttribute
+	edgeGroupBy := serviceNameAttribute + ", " + peerServiceAttribute
+	queries := []struct {
+		name       string
+		conditions string
+		function   string
+		groupBy    string
+		unit       string
+	}{
+		{"rate", serverSpans, "rate()", nodeGroupBy, "reqps"},
+		{"errors", serverSpans + " && " + errorSpans, "rate()", nodeGroupBy, "reqps"},
+		{"duration", serverSpans, p90Duration, nodeGroupBy, "s"},
+		{"edgeRate", clientSpans, "rate()", edgeGroupBy, "reqps"},
+		{"edgeErrors", clientSpans + " && " + errorSpans, "rate()", edgeGroupBy, "reqps"},
+		{"edgeDuration", clientSpans, p90Duration, edgeGroupBy, "s"},
+	}
+
+	values := map[string]map[string]float64{}
+	var series data.Frames
+	for _, q := range queries {
+		selector, err := spanSelector(tempoQuery.Query, q.conditions)
+		if err != nil {
+			return &backend.DataResponse{Error: err, ErrorSource: backend.ErrorSourcePlugin}, nil
+		}
+		res, err := s.queryMetricsRange(ctx, dsInfo, tempoQuery, fmt.Sprintf("%s | %s by (%s)", selector, q.function, q.groupBy), backendQuery)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			resp := backend.ErrorResponseWithErrorSource(err)
+			return &resp, nil
+		}
+		values[q.name] = averageByService(res)
+		series = append(series, seriesFrames(q.name, q.unit, res)...)
+	}
+
+	ctxLogger.Debug("Successfully performed service map query", "function", logEntrypoint())
+	if fromAlert {
+		return &backend.DataResponse{Frames: series}, nil
+	}
+	return &backend.DataResponse{Frames: append(serviceMapFrames(values), series...)}, nil
+}
+
+func (s *Service) runSpanMetricsQuery(ctx context.Context, pCtx backend.PluginContext, backendQuery backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Running span metrics query", "function", logEntrypoint())
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.runSpanMetricsQuery", trace.WithAttributes(
+		attribute.String("queryType", backendQuery.QueryType),
+	))
+	defer span.End()
+
+	tempoQuery := &dataquery.TempoQuery{}
+	if err := json.Unmarshal(backendQuery.JSON, tempoQuery); err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	metric := dataquery.SpanMetricRate
+	if tempoQuery.SpanMetric != nil {
+		metric = *tempoQuery.SpanMetric
+	}
+	query, err := spanMetricsQuery(tempoQuery)
+	if err != nil {
+		return &backend.DataResponse{Error: err, ErrorSource: backend.ErrorSourcePlugin}, nil
+	}
+
+	res, err := s.queryMetricsRange(ctx, dsInfo, tempoQuery, query, backendQuery)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		resp := backend.ErrorResponseWithErrorSource(err)
+		return &resp, nil
+	}
+
+	ctxLogger.Debug("Successfully performed span metrics query", "function", logEntrypoint())
+	return &backend.DataResponse{Frames: seriesFrames(string(metric), spanMetricUnit(metric), res)}, nil
+}
+
+// queryMetricsRange runs a TraceQL metrics range query over the time range of the backend query.
+// Failures to reach Tempo and error responses from Tempo are returned as downstream errors.
+func (s *Service) queryMetricsRange(ctx context.Context, dsInfo *Datasource, model *dataquery.TempoQuery, query string, backendQuery backend.DataQuery) (*tempopb.QueryRangeResponse, error) {
+	metricsQuery := &dataquery.TempoQuery{Query: &query, Step: model.Step}
+	resp, body, err := s.performMetricsQuery(ctx, dsInfo, metricsQuery, backendQuery, trace.SpanFromContext(ctx))
+	if err != nil {
+		return nil, backend.DownstreamError(err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.logger.FromContext(ctx).Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, backend.DownstreamErrorf("failed to execute TraceQL query: %s Status: %s Body: %s", query, resp.Status, string(body))
+	}
+
+	var queryResponse tempopb.QueryRangeResponse
+	if err := jsonpb.Unmarshal(bytes.NewReader(body), &queryResponse); err != nil {
+		return nil, fmt.Errorf("failed to convert response to type: %w", err)
+	}
+	return &queryResponse, nil
+}
+
+// spanMetricsQuery builds the TraceQL metrics query returning the requested RED metric,
+// grouped by service and the attributes in groupBy. Without a query, server spans are used.
+func spanMetricsQuery(model *dataquery.TempoQuery) (string, error) {
+	filter := model.Query
+	if filter == nil || strings.TrimSpace(*filter) == "" {
+		defaultFilter := "{ " + serverSpans + " }"
+		filter = &defaultFilter
+	}
+
+	groupBy := []string{serviceNameAttribute}
+	for _, f := range model.GroupBy {
+		if attr := filterAttribute(f); attr != "" && attr != serviceNameAttribute {
+			groupBy = append(groupBy, attr)
+		}
+	}
+
+	metric := dataquery.SpanMetricRate
+	if model.SpanMetric != nil {
+		metric = *model.SpanMetric
+	}
+
+	var selector, function string
+	var err error
+	switch metric {
+	case dataquery.SpanMetricRate:
+		selector, err = spanSelector(filter, "")
+		function = "rate()"
+	case dataquery.SpanMetricErrorRate:
+		selector, err = spanSelector(filter, errorSpans)
+		function = "rate()"
+	case dataquery.SpanMetricDuration:
+		selector, err = spanSelector(filter, "")
+		function = p90Duration
+	default:
+		return "", fmt.Errorf("unsupported span metric: '%s'", metric)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s | %s by (%s)", selector, function, strings.Join(groupBy, ", ")), nil
+}
+
+// spanSelector adds conditions to the span selector in filter, so that for example
+// `{ resource.env="prod" }` and `kind=server` become `{ (resource.env="prod") && kind=server }`.
+func spanSelector(filter *string, conditions string) (string, error) {
+	inner := ""
+	if filter != nil {
+		f := strings.TrimSpace(*filter)
+		if f != "" {
+			if !strings.HasPrefix(f, "{") || !strings.HasSuffix(f, "}") {
+				return "", fmt.Errorf("query must be a single TraceQL span selector, got: %s", f)
+			}
+			inner = strings.TrimSpace(f[1 : len(f)-1])
+			if !isSingleSelector(inner) {
+				return "", fmt.Errorf("query must be a single TraceQL span selector, got: %s", f)
+			}
+		}
+	}
+
+	switch {
+	case inner == "" && conditions == "":
+		return "{}", nil
+	case inner == "":
+		return "{ " + conditions + " }", nil
+	case conditions == "":
+		return "{ " + inner + " }", nil
+	default:
+		return fmt.Sprintf("{ (%s) && %s }", inner, conditions), nil
+	}
+}
+
+// isSingleSelector reports whether the body of a span selector contains neither nested selectors
+// nor a pipeline other than the || operator. String literals are skipped, so that values such as
+// `"a|b"` are accepted.
+func isSingleSelector(inner string) bool {
+	for i := 0; i < len(inner); i++ {
+		switch c := inner[i]; c {
+		case '"', '`':
+			// skip to the closing quote, double quoted strings may contain escaped quotes
+			for i++; i < len(inner) && inner[i] != c; i++ {
+				if c == '"' && inner[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(inner) {
+				return false
+			}
+		case '{', '}':
+			return false
+		case '|':
+			if i+1 >= len(inner) || inner[i+1] != '|' {
+				return false
+			}
+			i++
+		}
+	}
+	return true
+}
+
+// filterAttribute returns the TraceQL attribute name of a group by filter.
+func filterAttribute(f dataquery.TraceqlFilter) string {
+	if f.Tag == nil || *f.Tag == "" {
+		return ""
+	}
+	if f.Scope == nil {
+		return "." + *f.Tag
+	}
+	switch *f.Scope {
+	case dataquery.TraceqlSearchScopeIntrinsic:
+		return *f.Tag
+	case dataquery.TraceqlSearchScopeUnscoped:
+		return "." + *f.Tag
+	default:
+		return string(*f.Scope) + "." + *f.Tag
+	}
+}
+
+// averageByService averages every series over the time range. Series are keyed by their service,
+// or by "<service>\x00<peer service>" for edges.
+func averageByService(resp *tempopb.QueryRangeResponse) map[string]float64 {
+	averages := map[string]float64{}
+	for _, series := range resp.Series {
+		var service, peer string
+		for _, label := range series.Labels {
+			switch label.GetKey() {
+			case serviceNameAttribute:
+				service = label.GetValue().GetStringValue()
+			case peerServiceAttribute:
+				peer = label.GetValue().GetStringValue()
+			}
+		}
+		if service == "" || len(series.Samples) == 0 {
+			continue
+		}
+
+		var sum float64
+		for _, sample := range series.Samples {
+			sum += sample.GetValue()
+		}
+
+		key := service
+		if peer != "" {
+			key = edgeID(service, peer)
+		}
+		averages[key] = sum / float64(len(series.Samples))
+	}
+	return averages
+}
+
+// seriesFrames converts the series of a TraceQL metrics response to time series frames, one per
+// series, with the labels of the series on the value field. Unlike the frames of TraceQL metrics
+// queries they carry the data plane type, so alert rules can use them.
+func seriesFrames(name, unit string, resp *tempopb.QueryRangeResponse) data.Frames {
+	frames := make(data.Frames, 0, len(resp.Series))
+	for _, series := range resp.Series {
+		labels := data.Labels{}
+		for _, label := range series.Labels {
+			labels[label.GetKey()] = label.GetValue().GetStringValue()
+		}
+
+		times := make([]time.Time, 0, len(series.Samples))
+		values := make([]float64, 0, len(series.Samples))
+		for _, sample := range series.Samples {
+			times = append(times, time.UnixMilli(sample.GetTimestampMs()))
+			values = append(values, sample.GetValue())
+		}
+
+		frame := data.NewFrame(name,
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(name, labels, values).SetConfig(&data.FieldConfig{Unit: unit}),
+		)
+		frame.Meta = &data.FrameMeta{
+			Type:                   data.FrameTypeTimeSeriesMulti,
+			TypeVersion:            data.FrameTypeVersion{0, 1},
+			PreferredVisualization: data.VisTypeGraph,
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// spanMetricUnit returns the unit of the values of a span metric.
+func spanMetricUnit(metric dataquery.SpanMetric) string {
+	if metric == dataquery.SpanMetricDuration {
+		return "s"
+	}
+	return "reqps"
+}
+
+func edgeID(source, target string) string {
+	return source + "\x00" + target
+}
+
+// serviceMapFrames builds the nodes and edges frames of the node graph panel, using the same
+// fields as the service graph built in the frontend from the metrics generator series.
+func serviceMapFrames(values map[string]map[string]float64) data.Frames {
+	nodeIDs := map[string]struct{}{}
+	for id := range values["rate"] {
+		nodeIDs[id] = struct{}{}
+	}
+	edgeIDs := make([]string, 0, len(values["edgeRate"]))
+	for id := range values["edgeRate"] {
+		edgeIDs = append(edgeIDs, id)
+		source, target, _ := strings.Cut(id, "\x00")
+		nodeIDs[source] = struct{}{}
+		nodeIDs[target] = struct{}{}
+	}
+	sort.Strings(edgeIDs)
+
+	ids := make([]string, 0, len(nodeIDs))
+	for id := range nodeIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	nodes := data.NewFrame("Nodes",
+		data.NewField("id", nil, []string{}),
+		data.NewField("title", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Service name"}),
+		data.NewField("mainstat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "p90 response time", Unit: "ms/r"}),
+		data.NewField("secondarystat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "r/sec"}),
+		data.NewField("arc__success", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Success", Color: map[string]any{"mode": "fixed", "fixedColor": "green"}}),
+		data.NewField("arc__failed", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Failed", Color: map[string]any{"mode": "fixed", "fixedColor": "red"}}),
+	)
+	nodes.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+	for _, id := range ids {
+		rate := values["rate"][id]
+		failed := 0.0
+		if rate > 0 {
+			failed = values["errors"][id] / rate
+		}
+		nodes.AppendRow(id, id, values["duration"][id]*1000, rate, 1-failed, failed)
+	}
+
+	edges := data.NewFrame("Edges",
+		data.NewField("id", nil, []string{}),
+		data.NewField("source", nil, []string{}),
+		data.NewField("target", nil, []string{}),
+		data.NewField("mainstat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "p90 response time", Unit: "ms/r"}),
+		data.NewField("secondarystat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "r/sec"}),
+		data.NewField("detail__errors", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Errors per second", Unit: "r/sec"}),
+	)
+	edges.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+	for _, id := range edgeIDs {
+		source, target, _ := strings.Cut(id, "\x00")
+		edges.AppendRow(source+"_"+target, source, target, values["edgeDuration"][id]*1000, values["edgeRate"][id], values["edgeErrors"][id])
+	}
+
+	return data.Frames{nodes, edges}
+}
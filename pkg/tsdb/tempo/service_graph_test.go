@@ -0,0 +1,147 @@
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpanSelector(t *testing.T) {
+	filter := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		filter     *string
+		conditions string
+		expected   string
+		err        bool
+	}{
+		{name: "no filter", conditions: "kind=server", expected: "{ kind=server }"},
+		{name: "empty filter", filter: filter(" {} "), conditions: "kind=server", expected: "{ kind=server }"},
+		{name: "no conditions", filter: filter(`{ resource.env="prod" }`), expected: `{ resource.env="prod" }`},
+		{name: "filter and conditions", filter: filter(`{resource.env="prod" || resource.env="dev"}`), conditions: "kind=server", expected: `{ (resource.env="prod" || resource.env="dev") && kind=server }`},
+		{name: "nothing", expected: "{}"},
+		{name: "pipeline", filter: filter(`{ resource.env="prod" } | count() > 1`), err: true},
+		{name: "structural query", filter: filter(`{ kind=client } >> { kind=server }`), err: true},
+		{name: "pipe in string literal", filter: filter(`{ span.http.route="/a|b" }`), conditions: "kind=server", expected: `{ (span.http.route="/a|b") && kind=server }`},
+		{name: "braces in string literal", filter: filter("{ name=`{id}` }"), expected: "{ name=`{id}` }"},
+		{name: "escaped quote in string literal", filter: filter(`{ name="a\"|" }`), expected: `{ name="a\"|" }`},
+		{name: "unterminated string literal", filter: filter(`{ name="a }`), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := spanSelector(tt.filter, tt.conditions)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, selector)
+		})
+	}
+}
+
+func TestSpanMetricsQuery(t *testing.T) {
+	metric := func(m dataquery.SpanMetric) *dataquery.SpanMetric { return &m }
+	str := func(s string) *string { return &s }
+	spanScope := dataquery.TraceqlSearchScopeSpan
+
+	query, err := spanMetricsQuery(&dataquery.TempoQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, "{ kind=server } | rate() by (resource.service.name)", query)
+
+	query, err = spanMetricsQuery(&dataquery.TempoQuery{
+		Query:      str("{ kind=client }"),
+		SpanMetric: metric(dataquery.SpanMetricErrorRate),
+		GroupBy:    []dataquery.TraceqlFilter{{Id: "peer", Tag: str("peer.service"), Scope: &spanScope}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "{ (kind=client) && status=error } | rate() by (resource.service.name, span.peer.service)", query)
+
+	query, err = spanMetricsQuery(&dataquery.TempoQuery{SpanMetric: metric(dataquery.SpanMetricDuration)})
+	require.NoError(t, err)
+	assert.Equal(t, "{ kind=server } | quantile_over_time(duration, .9) by (resource.service.name)", query)
+
+	_, err = spanMetricsQuery(&dataquery.TempoQuery{SpanMetric: metric("saturation")})
+	assert.Error(t, err)
+}
+
+func TestQueryMetricsRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/metrics/query_range", r.URL.Path)
+		assert.Equal(t, "{ kind=server } | rate() by (resource.service.name)", r.URL.Query().Get("q"))
+		_, _ = w.Write([]byte(`{"series": [{"labels": [{"key": "resource.service.name", "value": {"stringValue": "api"}}], "samples": [{"timestampMs": "1000", "value": 2}, {"timestampMs": "2000", "value": 4}]}]}`))
+	}))
+	defer srv.Close()
+
+	service := &Service{logger: backend.NewLoggerWith("logger", "tsdb.tempo.test")}
+	dsInfo := &Datasource{HTTPClient: srv.Client(), URL: srv.URL}
+	backendQuery := backend.DataQuery{TimeRange: backend.TimeRange{From: time.Unix(1, 0), To: time.Unix(2, 0)}}
+
+	res, err := service.queryMetricsRange(context.Background(), dsInfo, &dataquery.TempoQuery{}, "{ kind=server } | rate() by (resource.service.name)", backendQuery)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"api": 3}, averageByService(res))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	_, err = service.queryMetricsRange(context.Background(), &Datasource{HTTPClient: failing.Client(), URL: failing.URL}, &dataquery.TempoQuery{}, "{ kind=server } | rate()", backendQuery)
+	require.Error(t, err)
+	assert.True(t, backend.IsDownstreamError(err))
+}
+
+func TestServiceMapFrames(t *testing.T) {
+	frames := serviceMapFrames(map[string]map[string]float64{
+		"rate":         {"api": 10, "db": 5},
+		"errors":       {"api": 1},
+		"duration":     {"api": 0.25, "db": 0.1},
+		"edgeRate":     {edgeID("api", "db"): 5, edgeID("api", "cache"): 2},
+		"edgeErrors":   {edgeID("api", "db"): 0.5},
+		"edgeDuration": {edgeID("api", "db"): 0.2, edgeID("api", "cache"): 0.01},
+	})
+	require.Len(t, frames, 2)
+
+	nodes := frames[0]
+	require.Equal(t, "Nodes", nodes.Name)
+	require.Equal(t, 3, nodes.Rows())
+	// nodes are sorted by id, services only seen as a peer get empty stats
+	assert.Equal(t, []any{"api", "api", 250.0, 10.0, 0.9, 0.1}, nodes.RowCopy(0))
+	assert.Equal(t, []any{"cache", "cache", 0.0, 0.0, 1.0, 0.0}, nodes.RowCopy(1))
+
+	edges := frames[1]
+	require.Equal(t, "Edges", edges.Name)
+	require.Equal(t, 2, edges.Rows())
+	assert.Equal(t, []any{"api_cache", "api", "cache", 10.0, 2.0, 0.0}, edges.RowCopy(0))
+	assert.Equal(t, []any{"api_db", "api", "db", 200.0, 5.0, 0.5}, edges.RowCopy(1))
+}
+
+func TestSeriesFrames(t *testing.T) {
+	frames := seriesFrames("edgeErrors", "reqps", &tempopb.QueryRangeResponse{Series: []*tempopb.TimeSeries{{
+		Labels: []v1.KeyValue{
+			{Key: "resource.service.name", Value: &v1.AnyValue{Value: &v1.AnyValue_StringValue{StringValue: "api"}}},
+			{Key: "span.peer.service", Value: &v1.AnyValue{Value: &v1.AnyValue_StringValue{StringValue: "db"}}},
+		},
+		Samples: []tempopb.Sample{{TimestampMs: 1000, Value: 0.5}, {TimestampMs: 2000, Value: 1}},
+	}}})
+	require.Len(t, frames, 1)
+
+	frame := frames[0]
+	assert.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+	schema := frame.TimeSeriesSchema()
+	require.Equal(t, data.TimeSeriesTypeWide, schema.Type)
+	assert.Equal(t, []any{time.UnixMilli(1000), 0.5}, frame.RowCopy(0))
+	assert.Equal(t, data.Labels{"resource.service.name": "api", "span.peer.service": "db"}, frame.Fields[1].Labels)
+	assert.Equal(t, "reqps", frame.Fields[1].Config.Unit)
+}
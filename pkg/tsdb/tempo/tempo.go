@@ -16,6 +16,9 @@ import (
 	"github.com/grafana/tempo/pkg/tempopb"
 )
 
+// headerFromAlert is used by datasources to identify alert queries
+const headerFromAlert = "FromAlert"
+
 type Service struct {
 	im     instancemgmt.InstanceManager
 	logger log.Logger
@@ -87,11 +90,12 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 
 	// create response struct
 	response := backend.NewQueryDataResponse()
+	_, fromAlert := req.Headers[headerFromAlert]
 
 	// loop over queries and execute them individually.
 	for i, q := range req.Queries {
 		ctxLogger.Debug("Processing query", "counter", i, "function", logEntrypoint())
-		if res, err := s.query(ctx, req.PluginContext, q); err != nil {
+		if res, err := s.query(ctx, req.PluginContext, q, fromAlert); err != nil {
 			ctxLogger.Error("Error processing query", "error", err)
 			return response, err
 		} else {
@@ -108,12 +112,16 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return response, nil
 }
 
-func (s *Service) query(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+func (s *Service) query(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery, fromAlert bool) (*backend.DataResponse, error) {
 	switch query.QueryType {
 	case string(dataquery.TempoQueryTypeTraceId):
 		return s.getTrace(ctx, pCtx, query)
 	case string(dataquery.TempoQueryTypeTraceql):
 		return s.runTraceQlQuery(ctx, pCtx, query)
+	case string(dataquery.TempoQueryTypeServiceMap):
+		return s.runServiceMapQuery(ctx, pCtx, query, fromAlert)
+	case string(dataquery.TempoQueryTypeSpanMetrics):
+		return s.runSpanMetricsQuery(ctx, pCtx, query)
 	}
 	return nil, fmt.Errorf("unsupported query type: '%s' for query with refID '%s'", query.QueryType, query.RefID)
 }
@@ -40,8 +40,16 @@ composableKinds: DataQuery: {
 				// Sets the maximum number of time series.
 				limit?: int64
 				// Sets the maximum number of nodes in the flamegraph.
-				maxNodes?:           int64
-				#PyroscopeQueryType: "metrics" | "profile" | *"both" @cuetsy(kind="type")
+				maxNodes?: int64
+				// Specifies the label selectors of the baseline profile in diff queries. Defaults to labelSelector.
+				baselineLabelSelector?: string
+				// Shifts the time range of the baseline profile in diff queries back by the given duration, e.g. 1d.
+				baselineTimeShift?: string
+				// Start of the baseline time range in diff queries, in milliseconds since epoch. Takes precedence over baselineTimeShift.
+				baselineFrom?: int64
+				// End of the baseline time range in diff queries, in milliseconds since epoch. Takes precedence over baselineTimeShift.
+				baselineTo?:         int64
+				#PyroscopeQueryType: "metrics" | "profile" | "diff" | *"both" @cuetsy(kind="type")
 			}
 		}]
 		lenses: []
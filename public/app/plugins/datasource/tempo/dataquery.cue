@@ -57,12 +57,16 @@ composableKinds: DataQuery: {
 					exemplars?: int64
 					// For metric queries, whether to run instant or range queries
 					metricsQueryType?: #MetricsQueryType
+					// For span metrics queries, the RED metric to return
+					spanMetric?: #SpanMetric
 				} @cuetsy(kind="interface") @grafana(TSVeneer="type")
 
-				#TempoQueryType: "traceql" | "traceqlSearch" | "serviceMap" | "upload" | "nativeSearch" | "traceId" | "clear" @cuetsy(kind="type")
+				#TempoQueryType: "traceql" | "traceqlSearch" | "serviceMap" | "upload" | "nativeSearch" | "traceId" | "clear" | "spanMetrics" @cuetsy(kind="type")
 
 				#MetricsQueryType: "range" | "instant" @cuetsy(kind="enum")
 
+				#SpanMetric: "rate" | "errorRate" | "duration" @cuetsy(kind="enum")
+
 				// The state of the TraceQL streaming search query
 				#SearchStreamingState: "pending" | "streaming" | "done" | "error" @cuetsy(kind="enum")
 
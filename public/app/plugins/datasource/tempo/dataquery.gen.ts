@@ -56,6 +56,10 @@ export interface TempoQuery extends common.DataQuery {
    * @deprecated Query traces by service name
    */
   serviceName?: string;
+  /**
+   * For span metrics queries, the RED metric to return
+   */
+  spanMetric?: SpanMetric;
   /**
    * @deprecated Query traces by span name
    */
@@ -79,13 +83,19 @@ export const defaultTempoQuery: Partial<TempoQuery> = {
   groupBy: [],
 };
 
-export type TempoQueryType = ('traceql' | 'traceqlSearch' | 'serviceMap' | 'upload' | 'nativeSearch' | 'traceId' | 'clear');
+export type TempoQueryType = ('traceql' | 'traceqlSearch' | 'serviceMap' | 'upload' | 'nativeSearch' | 'traceId' | 'clear' | 'spanMetrics');
 
 export enum MetricsQueryType {
   Instant = 'instant',
   Range = 'range',
 }
 
+export enum SpanMetric {
+  Duration = 'duration',
+  ErrorRate = 'errorRate',
+  Rate = 'rate',
+}
+
 /**
  * The state of the TraceQL streaming search query
  */